@@ -91,3 +91,52 @@ type TrackingAccumulator interface {
 	// Delivered returns a channel that will contain the tracking results.
 	Delivered() <-chan DeliveryInfo
 }
+
+// ErrorCategory classifies errors reported to an accumulator.
+type ErrorCategory string
+
+const (
+	// ErrorCategoryUnknown is used for errors without a category
+	ErrorCategoryUnknown ErrorCategory = "unknown"
+	// ErrorCategoryTransient denotes temporary errors such as timeouts or
+	// unavailable remote services that are expected to resolve by themselves
+	ErrorCategoryTransient ErrorCategory = "transient"
+	// ErrorCategoryConfig denotes errors caused by an invalid configuration
+	ErrorCategoryConfig ErrorCategory = "config"
+	// ErrorCategoryAuth denotes authentication or authorization failures
+	ErrorCategoryAuth ErrorCategory = "auth"
+)
+
+// CategorizedError is an error carrying a category and additional key/value
+// context. Plugins can pass it to Accumulator.AddError() to allow the agent
+// to report the error in a structured way.
+type CategorizedError struct {
+	Err      error
+	Category ErrorCategory
+	Context  map[string]string
+}
+
+// NewCategorizedError creates an error of the given category with the context
+// specified as alternating key and value strings. A trailing key without value
+// is ignored.
+func NewCategorizedError(category ErrorCategory, err error, keyvals ...string) *CategorizedError {
+	e := &CategorizedError{
+		Err:      err,
+		Category: category,
+	}
+	if len(keyvals) > 1 {
+		e.Context = make(map[string]string, len(keyvals)/2)
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			e.Context[keyvals[i]] = keyvals[i+1]
+		}
+	}
+	return e
+}
+
+func (e *CategorizedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CategorizedError) Unwrap() error {
+	return e.Err
+}
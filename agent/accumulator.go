@@ -1,10 +1,14 @@
 package agent
 
 import (
+	"errors"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 type MetricMaker interface {
@@ -98,11 +102,48 @@ func (ac *accumulator) addMeasurement(
 
 // AddError passes a runtime error to the accumulator.
 // The error will be tagged with the plugin name and written to the log.
+// Errors are counted per plugin and category in the "internal_errors"
+// statistics, using the category and context of a telegraf.CategorizedError
+// if available.
 func (ac *accumulator) AddError(err error) {
 	if err == nil {
 		return
 	}
-	ac.maker.Log().Errorf("Error in plugin: %v", err)
+
+	category := telegraf.ErrorCategoryUnknown
+	var cerr *telegraf.CategorizedError
+	if errors.As(err, &cerr) {
+		if cerr.Category != "" {
+			category = cerr.Category
+		}
+		ac.maker.Log().Errorf("Error in plugin [%s]: %v", formatErrorContext(category, cerr.Context), err)
+	} else {
+		ac.maker.Log().Errorf("Error in plugin: %v", err)
+	}
+
+	tags := map[string]string{
+		"plugin":   ac.maker.LogName(),
+		"category": string(category),
+	}
+	if p, ok := ac.maker.(interface{ ID() string }); ok {
+		tags["_id"] = p.ID()
+	}
+	selfstat.Register("errors", "count", tags).Incr(1)
+}
+
+func formatErrorContext(category telegraf.ErrorCategory, context map[string]string) string {
+	keys := make([]string, 0, len(context))
+	for k := range context {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys)+1)
+	parts = append(parts, "category="+string(category))
+	for _, k := range keys {
+		parts = append(parts, k+"="+context[k])
+	}
+	return strings.Join(parts, " ")
 }
 
 func (ac *accumulator) SetPrecision(precision time.Duration) {
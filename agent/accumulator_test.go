@@ -3,6 +3,7 @@ package agent
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -69,6 +71,33 @@ func TestAccAddError(t *testing.T) {
 	require.Contains(t, string(errs[2]), "baz")
 }
 
+func TestAccAddCategorizedError(t *testing.T) {
+	errBuf := bytes.NewBuffer(nil)
+	logger.RedirectLogging(errBuf)
+	defer logger.RedirectLogging(os.Stderr)
+
+	metrics := make(chan telegraf.Metric, 10)
+	defer close(metrics)
+	a := NewAccumulator(&TestMetricMaker{}, metrics)
+
+	auth := selfstat.Register("errors", "count", map[string]string{"plugin": "TestPlugin", "category": "auth"})
+	unknown := selfstat.Register("errors", "count", map[string]string{"plugin": "TestPlugin", "category": "unknown"})
+	authBefore, unknownBefore := auth.Get(), unknown.Get()
+
+	err := telegraf.NewCategorizedError(telegraf.ErrorCategoryAuth, errors.New("access denied"), "url", "http://localhost", "user", "admin")
+	a.AddError(fmt.Errorf("querying failed: %w", err))
+	a.AddError(err)
+	a.AddError(errors.New("foo"))
+
+	errs := bytes.Split(errBuf.Bytes(), []byte{'\n'})
+	require.Len(t, errs, 4) // 4 because of trailing newline
+	require.Contains(t, string(errs[0]), "[category=auth url=http://localhost user=admin]: querying failed: access denied")
+	require.Contains(t, string(errs[1]), "[category=auth url=http://localhost user=admin]: access denied")
+	require.Contains(t, string(errs[2]), "Error in plugin: foo")
+	require.Equal(t, authBefore+2, auth.Get())
+	require.Equal(t, unknownBefore+1, unknown.Get())
+}
+
 func TestSetPrecision(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Consecutive collections required to back off or recover the interval and
+// the factor the interval is changed by
+const (
+	adaptiveBackoffAfter  = 3
+	adaptiveRecoverAfter  = 5
+	adaptiveBackoffFactor = 2
+)
+
+// adaptiveInterval computes the effective collection interval of an input in
+// adaptive scheduling mode. The interval is doubled, up to the maximum, if
+// the collections consistently take longer than the current interval or the
+// pipeline is congested. Once the collections are fast again, the interval is
+// halved until the configured interval is reached.
+type adaptiveInterval struct {
+	base    time.Duration
+	max     time.Duration
+	current time.Duration
+
+	overloaded int
+	healthy    int
+}
+
+func newAdaptiveInterval(interval, maxInterval time.Duration) *adaptiveInterval {
+	return &adaptiveInterval{
+		base:    interval,
+		max:     maxInterval,
+		current: interval,
+	}
+}
+
+// update records a completed collection taking the given time and returns
+// the new effective interval and whether the interval changed.
+func (a *adaptiveInterval) update(elapsed time.Duration, congested bool) (time.Duration, bool) {
+	switch {
+	case elapsed > a.current || congested:
+		a.overloaded++
+		a.healthy = 0
+	case elapsed < a.current/adaptiveBackoffFactor:
+		a.healthy++
+		a.overloaded = 0
+	default:
+		// Collections fitting the current interval but not allowing to
+		// shorten it keep the interval as-is.
+		a.overloaded = 0
+		a.healthy = 0
+	}
+
+	previous := a.current
+	if a.overloaded >= adaptiveBackoffAfter && a.current < a.max {
+		a.current = min(a.current*adaptiveBackoffFactor, a.max)
+		a.overloaded = 0
+	}
+	if a.healthy >= adaptiveRecoverAfter && a.current > a.base {
+		// Healthy collections took less than half of the interval and thus
+		// fit into the shortened one
+		a.current = max(a.current/adaptiveBackoffFactor, a.base)
+		a.healthy = 0
+	}
+	return a.current, a.current != previous
+}
+
+// isCongested checks if the channel the input writes to is full, i.e. the
+// downstream processors or outputs cannot keep up with the input.
+func isCongested(dst chan<- telegraf.Metric) bool {
+	return cap(dst) > 0 && len(dst) >= cap(dst)
+}
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+func TestAdaptiveIntervalBackoff(t *testing.T) {
+	a := newAdaptiveInterval(10*time.Second, 30*time.Second)
+
+	// Single slow collections do not change the interval
+	for range adaptiveBackoffAfter - 1 {
+		interval, changed := a.update(15*time.Second, false)
+		require.False(t, changed)
+		require.Equal(t, 10*time.Second, interval)
+	}
+	_, changed := a.update(7*time.Second, false)
+	require.False(t, changed)
+
+	// Consistently slow collections back off the interval
+	var interval time.Duration
+	for range adaptiveBackoffAfter {
+		interval, changed = a.update(15*time.Second, false)
+	}
+	require.True(t, changed)
+	require.Equal(t, 20*time.Second, interval)
+
+	// The interval is limited to the maximum
+	for range adaptiveBackoffAfter {
+		interval, changed = a.update(25*time.Second, false)
+	}
+	require.True(t, changed)
+	require.Equal(t, 30*time.Second, interval)
+
+	for range adaptiveBackoffAfter {
+		interval, changed = a.update(35*time.Second, false)
+	}
+	require.False(t, changed)
+	require.Equal(t, 30*time.Second, interval)
+}
+
+func TestAdaptiveIntervalCongestion(t *testing.T) {
+	a := newAdaptiveInterval(10*time.Second, time.Minute)
+
+	var interval time.Duration
+	var changed bool
+	for range adaptiveBackoffAfter {
+		interval, changed = a.update(time.Second, true)
+	}
+	require.True(t, changed)
+	require.Equal(t, 20*time.Second, interval)
+}
+
+func TestAdaptiveIntervalRecover(t *testing.T) {
+	a := newAdaptiveInterval(10*time.Second, time.Minute)
+	for range 2 * adaptiveBackoffAfter {
+		a.update(time.Minute, false)
+	}
+	require.Equal(t, 40*time.Second, a.current)
+
+	// Collections fitting the interval but not half of it keep the interval
+	for range adaptiveRecoverAfter {
+		_, changed := a.update(30*time.Second, false)
+		require.False(t, changed)
+	}
+
+	// Fast collections shorten the interval down to the configured one
+	var interval time.Duration
+	var changed bool
+	for range adaptiveRecoverAfter {
+		interval, changed = a.update(time.Second, false)
+	}
+	require.True(t, changed)
+	require.Equal(t, 20*time.Second, interval)
+
+	for range 2 * adaptiveRecoverAfter {
+		interval, _ = a.update(time.Second, false)
+	}
+	require.Equal(t, 10*time.Second, interval)
+}
+
+func TestIsCongested(t *testing.T) {
+	dst := make(chan telegraf.Metric, 2)
+	require.False(t, isCongested(dst))
+	dst <- nil
+	require.False(t, isCongested(dst))
+	dst <- nil
+	require.True(t, isCongested(dst))
+
+	require.False(t, isCongested(make(chan telegraf.Metric)))
+}
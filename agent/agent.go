@@ -8,6 +8,7 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fatih/color"
@@ -25,6 +26,10 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	// Start time of the agent in nanoseconds since epoch, zero if the agent
+	// is not running
+	started atomic.Int64
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -136,76 +141,48 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}
 
-	startTime := time.Now()
-
-	log.Printf("D! [agent] Connecting outputs")
-	next, ou, err := a.startOutputs(ctx, a.Config.Outputs)
+	pipelines, err := a.pipelines()
 	if err != nil {
 		return err
 	}
 
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		aggC := next
-		if len(a.Config.AggProcessors) != 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
-			aggC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
-			}
-		}
-
-		next, au = a.startAggregators(aggC, next, a.Config.Aggregators)
-	}
+	startTime := time.Now()
+	a.started.Store(startTime.UnixNano())
+	defer a.started.Store(0)
 
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
+	units := make([]*pipelineUnit, 0, len(pipelines))
+	for _, p := range pipelines {
+		next, unit, err := a.startPipeline(ctx, p)
 		if err != nil {
+			stopPipelines(units)
 			return err
 		}
-	}
+		units = append(units, unit)
 
-	iu, err := a.startInputs(next, a.Config.Inputs)
-	if err != nil {
-		return err
+		unit.iu, err = a.startInputs(next, p.inputs)
+		if err != nil {
+			stopPipelines(units)
+			return err
+		}
 	}
 
 	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.runOutputs(ou)
-	}()
-
-	if au != nil {
+	for _, unit := range units {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(apu)
+			a.runOutputs(unit.ou)
 		}()
 
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runAggregators(startTime, au)
-		}()
-	}
+		a.runChain(&wg, startTime, unit.chain)
 
-	if pu != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(pu)
+			a.runInputs(ctx, startTime, unit.iu)
 		}()
 	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.runInputs(ctx, startTime, iu)
-	}()
-
 	wg.Wait()
 
 	if a.Config.Persister != nil {
@@ -216,7 +193,7 @@ func (a *Agent) Run(ctx context.Context) error {
 	}
 
 	log.Printf("D! [agent] Stopped Successfully")
-	return err
+	return nil
 }
 
 // InitPlugins runs the Init function on plugins.
@@ -260,6 +237,29 @@ func (a *Agent) InitPlugins() error {
 	return nil
 }
 
+// startPipeline connects the outputs and starts the processors and aggregators
+// of the given pipeline. It returns the channel the inputs of the pipeline
+// should write to.
+func (a *Agent) startPipeline(ctx context.Context, p *pipeline) (chan<- telegraf.Metric, *pipelineUnit, error) {
+	if p.name != "" && len(p.outputs) == 0 {
+		return nil, nil, fmt.Errorf("pipeline %q has no outputs", p.name)
+	}
+
+	log.Printf("D! [agent] Connecting outputs of pipeline %q", p)
+	next, ou, err := a.startOutputs(ctx, p.outputs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next, chain, err := a.startChain(next, p)
+	if err != nil {
+		stopRunningOutputs(ou.outputs)
+		return nil, nil, err
+	}
+
+	return next, &pipelineUnit{chain: chain, ou: ou}, nil
+}
+
 // initPersister initializes the persister and registers the plugins.
 func (a *Agent) initPersister() error {
 	if err := a.Config.Persister.Init(); err != nil {
@@ -428,16 +428,28 @@ func (a *Agent) runInputs(ctx context.Context, startTime time.Time, unit *inputU
 			offset = input.Config.CollectionOffset
 		}
 
+		// Overwrite agent max_interval if this plugin has its own.
+		maxInterval := time.Duration(a.Config.Agent.MaxInterval)
+		if input.Config.MaxInterval != 0 {
+			maxInterval = input.Config.MaxInterval
+		}
+
 		ticker := clock.NewTicker(interval, jitter, offset, options...)
 		tickers = append(tickers, ticker)
 
 		acc := NewAccumulator(input, unit.dst)
 		acc.SetPrecision(getPrecision(precision, interval))
 
+		var adaptive *adaptiveInterval
+		if maxInterval > interval {
+			adaptive = newAdaptiveInterval(interval, maxInterval)
+			input.SetGatherInterval(interval)
+		}
+
 		wg.Add(1)
 		go func(input *models.RunningInput) {
 			defer wg.Done()
-			a.gatherLoop(ctx, acc, input, ticker, interval)
+			a.gatherLoop(ctx, acc, input, ticker, interval, adaptive, unit.dst)
 		}(input)
 	}
 	defer stopTickers(tickers)
@@ -567,14 +579,35 @@ func (a *Agent) gatherLoop(
 	input *models.RunningInput,
 	ticker *clock.Ticker,
 	interval time.Duration,
+	adaptive *adaptiveInterval,
+	dst chan<- telegraf.Metric,
 ) {
 	for {
 		select {
 		case <-ticker.C:
+			start := time.Now()
 			err := a.gatherOnce(acc, input, ticker, interval)
 			if err != nil {
 				acc.AddError(err)
 			}
+			if adaptive == nil {
+				continue
+			}
+
+			// Back off or recover the interval in adaptive scheduling mode
+			current, changed := adaptive.update(time.Since(start), isCongested(dst))
+			if changed {
+				if current > interval {
+					log.Printf("W! [%s] Collection cannot keep up; changing interval from %s to %s",
+						input.LogName(), interval, current)
+				} else {
+					log.Printf("I! [%s] Collection recovered; changing interval from %s to %s",
+						input.LogName(), interval, current)
+				}
+				interval = current
+				ticker.SetInterval(interval)
+				input.SetGatherInterval(interval)
+			}
 		case <-ctx.Done():
 			return
 		}
@@ -647,7 +680,7 @@ func (*Agent) startProcessors(dst chan<- telegraf.Metric, runningProcessors mode
 }
 
 // runProcessors begins processing metrics and runs until the source channel is closed and all metrics have been written.
-func (*Agent) runProcessors(units []*processorUnit) {
+func (a *Agent) runProcessors(units []*processorUnit) {
 	var wg sync.WaitGroup
 	for _, unit := range units {
 		wg.Add(1)
@@ -655,10 +688,14 @@ func (*Agent) runProcessors(units []*processorUnit) {
 			defer wg.Done()
 
 			acc := NewAccumulator(unit.processor, unit.dst)
-			for m := range unit.src {
-				if err := unit.processor.Add(m, acc); err != nil {
-					acc.AddError(err)
-					m.Drop()
+			if unit.processor.IsFlushing() {
+				a.processWithFlush(unit, acc)
+			} else {
+				for m := range unit.src {
+					if err := unit.processor.Add(m, acc); err != nil {
+						acc.AddError(err)
+						m.Drop()
+					}
 				}
 			}
 			unit.processor.Stop()
@@ -669,6 +706,40 @@ func (*Agent) runProcessors(units []*processorUnit) {
 	wg.Wait()
 }
 
+// processWithFlush processes metrics of the given unit and periodically
+// flushes the processor until the source channel is closed. Metrics are added
+// and flushed in the same goroutine so the processor doesn't need to protect
+// its state against concurrent access.
+func (a *Agent) processWithFlush(unit *processorUnit, acc telegraf.Accumulator) {
+	interval := unit.processor.Config.FlushInterval
+	if interval <= 0 {
+		interval = time.Duration(a.Config.Agent.Interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case m, ok := <-unit.src:
+			if !ok {
+				if err := unit.processor.Flush(acc); err != nil {
+					acc.AddError(err)
+				}
+				return
+			}
+			if err := unit.processor.Add(m, acc); err != nil {
+				acc.AddError(err)
+				m.Drop()
+			}
+		case <-ticker.C:
+			if err := unit.processor.Flush(acc); err != nil {
+				acc.AddError(err)
+			}
+		}
+	}
+}
+
 // startAggregators sets up the aggregator unit and returns the source channel.
 func (*Agent) startAggregators(aggC, outputC chan<- telegraf.Metric, aggregators []*models.RunningAggregator) (chan<- telegraf.Metric, *aggregatorUnit) {
 	src := make(chan telegraf.Metric, 100)
@@ -789,6 +860,12 @@ func (a *Agent) startOutputs(
 				// If the model tells us to remove the plugin we do so without error
 				log.Printf("I! [agent] Failed to connect to [%s], error was %q;  shutting down plugin...", output.LogName(), err)
 				output.Close()
+
+				// Do not divert metrics to a fallback not running
+				if primary := output.Primary(); primary != nil {
+					log.Printf("W! [agent] Output [%s] is running without fallback", primary.LogName())
+					primary.SetFallback(nil)
+				}
 				continue
 			}
 
@@ -828,14 +905,15 @@ func (*Agent) connectOutput(ctx context.Context, output *models.RunningOutput) e
 func (a *Agent) runOutputs(
 	unit *outputUnit,
 ) {
-	var wg sync.WaitGroup
-
 	// Start flush loop
 	interval := time.Duration(a.Config.Agent.FlushInterval)
 	jitter := time.Duration(a.Config.Agent.FlushJitter)
 
-	ctx, cancel := context.WithCancel(context.Background())
-
+	// Group the flush loops by the position of the output in the fallback
+	// chain so primary outputs can be stopped before their fallbacks. This
+	// way metrics diverted during the final flush are still written.
+	var stages []*flushStage
+	receivers := make([]*models.RunningOutput, 0, len(unit.outputs))
 	for _, output := range unit.outputs {
 		interval := interval
 		// Overwrite agent flush_interval if this plugin has its own.
@@ -849,20 +927,32 @@ func (a *Agent) runOutputs(
 			jitter = output.Config.FlushJitter
 		}
 
-		wg.Add(1)
+		depth := output.FallbackDepth()
+		for len(stages) <= depth {
+			ctx, cancel := context.WithCancel(context.Background())
+			stages = append(stages, &flushStage{ctx: ctx, cancel: cancel})
+		}
+		stage := stages[depth]
+
+		stage.wg.Add(1)
 		go func(output *models.RunningOutput) {
-			defer wg.Done()
+			defer stage.wg.Done()
 
 			timer := clock.NewTimer(interval, jitter)
 			defer timer.Stop()
 
-			a.flushLoop(ctx, output, timer)
+			a.flushLoop(stage.ctx, output, timer)
 		}(output)
+
+		// Fallback outputs only receive the metrics diverted by their primary
+		if output.Primary() == nil {
+			receivers = append(receivers, output)
+		}
 	}
 
 	for metric := range unit.src {
-		for i, output := range unit.outputs {
-			if i == len(unit.outputs)-1 {
+		for i, output := range receivers {
+			if i == len(receivers)-1 {
 				output.AddMetricNoCopy(metric)
 			} else {
 				output.AddMetric(metric)
@@ -871,13 +961,23 @@ func (a *Agent) runOutputs(
 	}
 
 	log.Println("I! [agent] Hang on, flushing any cached metrics before shutdown")
-	cancel()
-	wg.Wait()
+	for _, stage := range stages {
+		stage.cancel()
+		stage.wg.Wait()
+	}
 
 	log.Println("I! [agent] Stopping running outputs")
 	stopRunningOutputs(unit.outputs)
 }
 
+// flushStage holds the flush loops of the outputs at the same position in
+// the fallback chain
+type flushStage struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
 // flushLoop runs an output's flush function periodically until the context is
 // done.
 func (a *Agent) flushLoop(ctx context.Context, output *models.RunningOutput, timer *clock.Timer) {
@@ -992,65 +1092,42 @@ func (a *Agent) runTest(ctx context.Context, wait time.Duration, outputC chan<-
 		return err
 	}
 
-	startTime := time.Now()
-
-	next := outputC
-
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		procC := next
-		if len(a.Config.AggProcessors) != 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
-			var err error
-			procC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
-			}
-		}
-
-		next, au = a.startAggregators(procC, next, a.Config.Aggregators)
+	pipelines, err := a.pipelines()
+	if err != nil {
+		return err
 	}
 
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		var err error
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
+	startTime := time.Now()
+
+	// Each pipeline gets its own channel to not close the output channel
+	// multiple times.
+	sources := make([]chan telegraf.Metric, 0, len(pipelines))
+	units := make([]*pipelineUnit, 0, len(pipelines))
+	for _, p := range pipelines {
+		src := make(chan telegraf.Metric, 100)
+		next, chain, err := a.startChain(src, p)
 		if err != nil {
 			return err
 		}
+		sources = append(sources, src)
+		units = append(units, &pipelineUnit{
+			iu:    a.testStartInputs(next, p.inputs),
+			chain: chain,
+		})
 	}
-
-	iu := a.testStartInputs(next, a.Config.Inputs)
+	go forwardMetrics(outputC, sources)
 
 	var wg sync.WaitGroup
-	if au != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runProcessors(apu)
-		}()
-
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runAggregators(startTime, au)
-		}()
-	}
+	for _, unit := range units {
+		a.runChain(&wg, startTime, unit.chain)
 
-	if pu != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(pu)
+			a.testRunInputs(ctx, wait, unit.iu)
 		}()
 	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.testRunInputs(ctx, wait, iu)
-	}()
-
 	wg.Wait()
 
 	log.Printf("D! [agent] Stopped Successfully")
@@ -1097,73 +1174,40 @@ func (a *Agent) runOnce(ctx context.Context, wait time.Duration) error {
 		return err
 	}
 
-	startTime := time.Now()
-
-	log.Printf("D! [agent] Connecting outputs")
-	next, ou, err := a.startOutputs(ctx, a.Config.Outputs)
+	pipelines, err := a.pipelines()
 	if err != nil {
 		return err
 	}
 
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		procC := next
-		if len(a.Config.AggProcessors) != 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
-			procC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
-			}
-		}
-
-		next, au = a.startAggregators(procC, next, a.Config.Aggregators)
-	}
+	startTime := time.Now()
 
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
+	units := make([]*pipelineUnit, 0, len(pipelines))
+	for _, p := range pipelines {
+		next, unit, err := a.startPipeline(ctx, p)
 		if err != nil {
 			return err
 		}
+		unit.iu = a.testStartInputs(next, p.inputs)
+		units = append(units, unit)
 	}
 
-	iu := a.testStartInputs(next, a.Config.Inputs)
-
 	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.runOutputs(ou)
-	}()
-
-	if au != nil {
+	for _, unit := range units {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(apu)
+			a.runOutputs(unit.ou)
 		}()
 
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runAggregators(startTime, au)
-		}()
-	}
+		a.runChain(&wg, startTime, unit.chain)
 
-	if pu != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(pu)
+			a.testRunInputs(ctx, wait, unit.iu)
 		}()
 	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.testRunInputs(ctx, wait, iu)
-	}()
-
 	wg.Wait()
 
 	log.Printf("D! [agent] Stopped Successfully")
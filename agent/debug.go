@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+// DebugStatus contains the debugging information of all plugin instances
+// implementing the telegraf.DebugInfoPlugin interface
+type DebugStatus struct {
+	Inputs        []PluginDebugInfo `json:"inputs"`
+	Processors    []PluginDebugInfo `json:"processors"`
+	Aggregators   []PluginDebugInfo `json:"aggregators"`
+	AggProcessors []PluginDebugInfo `json:"aggregator_processors"`
+	Outputs       []PluginDebugInfo `json:"outputs"`
+}
+
+// PluginDebugInfo describes the runtime state of a plugin instance
+type PluginDebugInfo struct {
+	Name  string      `json:"name"`
+	Alias string      `json:"alias,omitempty"`
+	ID    string      `json:"id"`
+	Info  interface{} `json:"info"`
+}
+
+// Debug returns the debugging information of the agent's plugins
+func (a *Agent) Debug() *DebugStatus {
+	status := &DebugStatus{
+		Inputs:        make([]PluginDebugInfo, 0),
+		Processors:    make([]PluginDebugInfo, 0),
+		Aggregators:   make([]PluginDebugInfo, 0),
+		AggProcessors: make([]PluginDebugInfo, 0),
+		Outputs:       make([]PluginDebugInfo, 0),
+	}
+
+	for _, input := range a.Config.Inputs {
+		if plugin, ok := input.Input.(telegraf.DebugInfoPlugin); ok {
+			status.Inputs = append(status.Inputs, PluginDebugInfo{
+				Name:  input.Config.Name,
+				Alias: input.Config.Alias,
+				ID:    input.ID(),
+				Info:  plugin.DebugInfo(),
+			})
+		}
+	}
+
+	for _, processor := range a.Config.Processors {
+		if plugin, ok := unwrapDebugInfo(processor.Processor); ok {
+			status.Processors = append(status.Processors, PluginDebugInfo{
+				Name:  processor.Config.Name,
+				Alias: processor.Config.Alias,
+				ID:    processor.ID(),
+				Info:  plugin.DebugInfo(),
+			})
+		}
+	}
+
+	for _, aggregator := range a.Config.Aggregators {
+		if plugin, ok := aggregator.Aggregator.(telegraf.DebugInfoPlugin); ok {
+			status.Aggregators = append(status.Aggregators, PluginDebugInfo{
+				Name:  aggregator.Config.Name,
+				Alias: aggregator.Config.Alias,
+				ID:    aggregator.ID(),
+				Info:  plugin.DebugInfo(),
+			})
+		}
+	}
+
+	for _, processor := range a.Config.AggProcessors {
+		if plugin, ok := unwrapDebugInfo(processor.Processor); ok {
+			status.AggProcessors = append(status.AggProcessors, PluginDebugInfo{
+				Name:  processor.Config.Name,
+				Alias: processor.Config.Alias,
+				ID:    processor.ID(),
+				Info:  plugin.DebugInfo(),
+			})
+		}
+	}
+
+	for _, output := range a.Config.Outputs {
+		if plugin, ok := output.Output.(telegraf.DebugInfoPlugin); ok {
+			status.Outputs = append(status.Outputs, PluginDebugInfo{
+				Name:  output.Config.Name,
+				Alias: output.Config.Alias,
+				ID:    output.ID(),
+				Info:  plugin.DebugInfo(),
+			})
+		}
+	}
+
+	return status
+}
+
+func unwrapDebugInfo(processor telegraf.StreamingProcessor) (telegraf.DebugInfoPlugin, bool) {
+	if p, ok := processor.(processors.HasUnwrap); ok {
+		plugin, ok := p.Unwrap().(telegraf.DebugInfoPlugin)
+		return plugin, ok
+	}
+	plugin, ok := processor.(telegraf.DebugInfoPlugin)
+	return plugin, ok
+}
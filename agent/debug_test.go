@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+type debugProcessor struct{}
+
+func (*debugProcessor) SampleConfig() string {
+	return ""
+}
+
+func (*debugProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	return in
+}
+
+func (*debugProcessor) DebugInfo() interface{} {
+	return map[string]int{"mappings": 2}
+}
+
+func TestDebug(t *testing.T) {
+	processor := models.NewRunningProcessor(
+		processors.NewStreamingProcessorFromProcessor(&debugProcessor{}),
+		&models.ProcessorConfig{Name: "test", Alias: "debug", ID: "debug-processor"},
+	)
+
+	// Plugins not implementing the interface are skipped
+	input := models.NewRunningInput(&healthInput{}, &models.InputConfig{Name: "test"})
+
+	c := config.NewConfig()
+	c.Inputs = append(c.Inputs, input)
+	c.Processors = append(c.Processors, processor)
+	a := NewAgent(c)
+
+	status := a.Debug()
+	require.Empty(t, status.Inputs)
+	require.Empty(t, status.Outputs)
+	require.Equal(t, []PluginDebugInfo{{
+		Name:  "test",
+		Alias: "debug",
+		ID:    processor.ID(),
+		Info:  map[string]int{"mappings": 2},
+	}}, status.Processors)
+}
@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"time"
+)
+
+// HealthStatus describes the readiness of the agent and its plugins
+type HealthStatus struct {
+	Ready   bool           `json:"ready"`
+	Inputs  []InputHealth  `json:"inputs"`
+	Outputs []OutputHealth `json:"outputs"`
+}
+
+// InputHealth describes the readiness of an input plugin. An input is ready
+// if it gathered successfully within the last two intervals or, before the
+// first gather completed, if the agent runs for less than two intervals.
+type InputHealth struct {
+	Name              string     `json:"name"`
+	Alias             string     `json:"alias,omitempty"`
+	ID                string     `json:"id"`
+	Ready             bool       `json:"ready"`
+	LastGather        *time.Time `json:"last_gather,omitempty"`
+	LastGatherSuccess *time.Time `json:"last_gather_success,omitempty"`
+}
+
+// OutputHealth describes the readiness and buffer fill level of an output
+// plugin. An output is ready if it is connected.
+type OutputHealth struct {
+	Name            string  `json:"name"`
+	Alias           string  `json:"alias,omitempty"`
+	ID              string  `json:"id"`
+	Ready           bool    `json:"ready"`
+	Connected       bool    `json:"connected"`
+	LastWriteFailed bool    `json:"last_write_failed"`
+	BufferSize      int     `json:"buffer_size"`
+	BufferLimit     int     `json:"buffer_limit"`
+	BufferFullness  float64 `json:"buffer_fullness"`
+}
+
+// Health returns the readiness of the agent and its plugins at the given time
+func (a *Agent) Health(now time.Time) *HealthStatus {
+	var started time.Time
+	if ts := a.started.Load(); ts > 0 {
+		started = time.Unix(0, ts)
+	}
+
+	status := &HealthStatus{
+		Ready:   !started.IsZero(),
+		Inputs:  make([]InputHealth, 0, len(a.Config.Inputs)),
+		Outputs: make([]OutputHealth, 0, len(a.Config.Outputs)),
+	}
+
+	for _, input := range a.Config.Inputs {
+		interval := time.Duration(a.Config.Agent.Interval)
+		if input.Config.Interval != 0 {
+			interval = input.Config.Interval
+		}
+
+		last, success := input.LastGather()
+		h := InputHealth{
+			Name:  input.Config.Name,
+			Alias: input.Config.Alias,
+			ID:    input.ID(),
+		}
+		if !last.IsZero() {
+			h.LastGather = &last
+		}
+		if !success.IsZero() {
+			h.LastGatherSuccess = &success
+		}
+
+		switch {
+		case started.IsZero():
+		case !success.IsZero():
+			h.Ready = now.Sub(success) <= 2*interval
+		case last.IsZero():
+			h.Ready = now.Sub(started) <= 2*interval
+		}
+		status.Ready = status.Ready && h.Ready
+		status.Inputs = append(status.Inputs, h)
+	}
+
+	for _, output := range a.Config.Outputs {
+		h := OutputHealth{
+			Name:            output.Config.Name,
+			Alias:           output.Config.Alias,
+			ID:              output.ID(),
+			Connected:       output.Connected(),
+			LastWriteFailed: output.LastWriteFailed(),
+			BufferSize:      output.BufferLength(),
+			BufferLimit:     output.MetricBufferLimit,
+		}
+		h.Ready = h.Connected
+		if h.BufferLimit > 0 {
+			h.BufferFullness = float64(h.BufferSize) / float64(h.BufferLimit)
+		}
+		status.Ready = status.Ready && h.Ready
+		status.Outputs = append(status.Outputs, h)
+	}
+
+	return status
+}
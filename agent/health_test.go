@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type healthInput struct {
+	err error
+}
+
+func (*healthInput) SampleConfig() string {
+	return ""
+}
+
+func (h *healthInput) Gather(telegraf.Accumulator) error {
+	return h.err
+}
+
+type healthOutput struct{}
+
+func (*healthOutput) SampleConfig() string {
+	return ""
+}
+
+func (*healthOutput) Connect() error {
+	return nil
+}
+
+func (*healthOutput) Close() error {
+	return nil
+}
+
+func (*healthOutput) Write([]telegraf.Metric) error {
+	return nil
+}
+
+func TestHealth(t *testing.T) {
+	plugin := &healthInput{}
+	input := models.NewRunningInput(plugin, &models.InputConfig{
+		Name:     "test",
+		ID:       "health-input",
+		Interval: 10 * time.Second,
+	})
+	require.NoError(t, input.Init())
+
+	output, err := models.NewRunningOutput(&healthOutput{}, &models.OutputConfig{
+		Name: "test",
+		ID:   "health-output",
+	}, 10, 100)
+	require.NoError(t, err)
+	require.NoError(t, output.Init())
+
+	c := config.NewConfig()
+	c.Agent.Interval = config.Duration(time.Minute)
+	c.Inputs = append(c.Inputs, input)
+	c.Outputs = append(c.Outputs, output)
+	a := NewAgent(c)
+
+	// The agent is not ready before running
+	now := time.Now()
+	status := a.Health(now)
+	require.False(t, status.Ready)
+	require.Len(t, status.Inputs, 1)
+	require.Len(t, status.Outputs, 1)
+	require.False(t, status.Inputs[0].Ready)
+	require.False(t, status.Outputs[0].Ready)
+
+	// Inputs are ready within the grace period before the first gather but
+	// the output is not connected yet
+	a.started.Store(now.UnixNano())
+	status = a.Health(now)
+	require.False(t, status.Ready)
+	require.True(t, status.Inputs[0].Ready)
+	require.Nil(t, status.Inputs[0].LastGather)
+	require.False(t, status.Outputs[0].Connected)
+
+	require.NoError(t, output.Connect())
+	output.AddMetric(testutil.TestMetric(1))
+	status = a.Health(now)
+	require.True(t, status.Ready)
+	require.True(t, status.Outputs[0].Connected)
+	require.Equal(t, 1, status.Outputs[0].BufferSize)
+	require.Equal(t, 100, status.Outputs[0].BufferLimit)
+	require.InDelta(t, 0.01, status.Outputs[0].BufferFullness, 1e-9)
+
+	// The input interval takes precedence over the agent interval
+	status = a.Health(now.Add(30 * time.Second))
+	require.False(t, status.Ready)
+	require.False(t, status.Inputs[0].Ready)
+
+	// A successful gather makes the input ready again
+	var acc testutil.Accumulator
+	require.NoError(t, input.Gather(&acc))
+	status = a.Health(time.Now())
+	require.True(t, status.Ready)
+	require.NotNil(t, status.Inputs[0].LastGather)
+	require.NotNil(t, status.Inputs[0].LastGatherSuccess)
+
+	// A failing gather keeps the input ready until the last successful
+	// gather is outdated
+	plugin.err = errors.New("failed")
+	require.Error(t, input.Gather(&acc))
+	status = a.Health(time.Now())
+	require.True(t, status.Inputs[0].Ready)
+	require.True(t, status.Inputs[0].LastGather.After(*status.Inputs[0].LastGatherSuccess) ||
+		status.Inputs[0].LastGather.Equal(*status.Inputs[0].LastGatherSuccess))
+	status = a.Health(time.Now().Add(time.Minute))
+	require.False(t, status.Ready)
+	require.False(t, status.Inputs[0].Ready)
+
+	// Closing the output disconnects it
+	output.Close()
+	status = a.Health(time.Now())
+	require.False(t, status.Outputs[0].Ready)
+	require.False(t, status.Outputs[0].Connected)
+}
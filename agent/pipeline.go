@@ -0,0 +1,253 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// pipeline is a group of plugins forming an independent chain from the inputs
+// to the outputs. Metrics never leave the pipeline they were created in and
+// each pipeline uses its own channels, so a busy pipeline cannot delay
+// another one.
+type pipeline struct {
+	name          string
+	inputs        []*models.RunningInput
+	processors    models.RunningProcessors
+	aggProcessors models.RunningProcessors
+	aggregators   []*models.RunningAggregator
+	outputs       []*models.RunningOutput
+}
+
+// String returns the name of the pipeline for logging.
+func (p *pipeline) String() string {
+	if p.name == "" {
+		return "default"
+	}
+	return p.name
+}
+
+// chainUnit holds the processors and aggregators of a pipeline running
+// between the inputs and the outputs.
+type chainUnit struct {
+	pu  []*processorUnit
+	au  *aggregatorUnit
+	apu []*processorUnit
+}
+
+// pipelineUnit holds the running units of a pipeline.
+type pipelineUnit struct {
+	iu    *inputUnit
+	chain *chainUnit
+	ou    *outputUnit
+}
+
+// pipelines groups the configured plugins by their 'pipeline' setting. The
+// default pipeline, containing all plugins without a pipeline setting, comes
+// first followed by the named pipelines in order of their first appearance.
+// Pipelines without any plugin are omitted.
+func (a *Agent) pipelines() ([]*pipeline, error) {
+	if err := linkFallbacks(a.Config.Outputs); err != nil {
+		return nil, err
+	}
+
+	defaultPipeline := &pipeline{}
+	pipelines := []*pipeline{defaultPipeline}
+	lookup := map[string]*pipeline{"": defaultPipeline}
+	get := func(name string) *pipeline {
+		p, found := lookup[name]
+		if !found {
+			p = &pipeline{name: name}
+			lookup[name] = p
+			pipelines = append(pipelines, p)
+		}
+		return p
+	}
+
+	for _, input := range a.Config.Inputs {
+		p := get(input.Config.Pipeline)
+		p.inputs = append(p.inputs, input)
+	}
+	for _, processor := range a.Config.Processors {
+		p := get(processor.Config.Pipeline)
+		p.processors = append(p.processors, processor)
+	}
+	for _, processor := range a.Config.AggProcessors {
+		p := get(processor.Config.Pipeline)
+		p.aggProcessors = append(p.aggProcessors, processor)
+	}
+	for _, aggregator := range a.Config.Aggregators {
+		p := get(aggregator.Config.Pipeline)
+		p.aggregators = append(p.aggregators, aggregator)
+	}
+	for _, output := range a.Config.Outputs {
+		p := get(output.Config.Pipeline)
+		p.outputs = append(p.outputs, output)
+	}
+
+	result := make([]*pipeline, 0, len(pipelines))
+	for _, p := range pipelines {
+		if p.name == "" {
+			// Keep the previous behavior for configurations not using
+			// pipelines at all.
+			if len(pipelines) == 1 || len(p.inputs)+len(p.processors)+len(p.aggregators)+len(p.outputs) > 0 {
+				result = append(result, p)
+			}
+			continue
+		}
+		if len(p.inputs) == 0 {
+			return nil, fmt.Errorf("pipeline %q has no inputs", p.name)
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// linkFallbacks resolves the 'fallback_for' setting of the outputs and links
+// the fallback outputs to their primary. The primary can be referenced by its
+// instance ID, e.g. "outputs.influxdb_v2#0", or by "outputs.<name>" if there
+// is only a single instance of the plugin.
+func linkFallbacks(outputs []*models.RunningOutput) error {
+	for _, fallback := range outputs {
+		ref := fallback.Config.FallbackFor
+		if ref == "" {
+			continue
+		}
+
+		var primary *models.RunningOutput
+		for _, output := range outputs {
+			if output.Config.InstanceID != ref && "outputs."+output.Config.Name != ref {
+				continue
+			}
+			if primary != nil {
+				return fmt.Errorf("fallback %s: reference %q is ambiguous, use the instance ID", fallback.LogName(), ref)
+			}
+			primary = output
+		}
+
+		switch {
+		case primary == nil:
+			return fmt.Errorf("fallback %s: output %q not found", fallback.LogName(), ref)
+		case primary == fallback:
+			return fmt.Errorf("fallback %s: output cannot be its own fallback", fallback.LogName())
+		case primary.Config.Pipeline != fallback.Config.Pipeline:
+			return fmt.Errorf("fallback %s: output %q belongs to a different pipeline", fallback.LogName(), ref)
+		}
+		if f := primary.Fallback(); f == fallback {
+			continue
+		} else if f != nil {
+			return fmt.Errorf("fallback %s: output %q already has fallback %s", fallback.LogName(), ref, f.LogName())
+		}
+
+		// Walk up the chain to detect cycles
+		for p := primary; p != nil; p = p.Primary() {
+			if p == fallback {
+				return fmt.Errorf("fallback %s: cycle in fallback chain", fallback.LogName())
+			}
+		}
+
+		primary.SetFallback(fallback)
+	}
+	return nil
+}
+
+// startChain starts the processors and aggregators of the pipeline sending
+// the metrics to dst and returns the channel the inputs should write to.
+func (a *Agent) startChain(dst chan<- telegraf.Metric, p *pipeline) (chan<- telegraf.Metric, *chainUnit, error) {
+	next := dst
+	unit := &chainUnit{}
+
+	if len(p.aggregators) != 0 {
+		aggC := next
+		if len(p.aggProcessors) != 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
+			var err error
+			aggC, unit.apu, err = a.startProcessors(next, p.aggProcessors)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		next, unit.au = a.startAggregators(aggC, next, p.aggregators)
+	}
+
+	if len(p.processors) != 0 {
+		var err error
+		next, unit.pu, err = a.startProcessors(next, p.processors)
+		if err != nil {
+			stopProcessors(unit.apu)
+			return nil, nil, err
+		}
+	}
+
+	return next, unit, nil
+}
+
+// runChain runs the processors and aggregators of the unit in the background
+// until their source channels are closed.
+func (a *Agent) runChain(wg *sync.WaitGroup, startTime time.Time, unit *chainUnit) {
+	if unit.au != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(unit.apu)
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runAggregators(startTime, unit.au)
+		}()
+	}
+
+	if unit.pu != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(unit.pu)
+		}()
+	}
+}
+
+// stopPipelines stops the plugins of the given started but not yet running
+// pipelines in reverse order.
+func stopPipelines(units []*pipelineUnit) {
+	for i := len(units) - 1; i >= 0; i-- {
+		unit := units[i]
+		if unit.iu != nil {
+			stopRunningInputs(unit.iu.inputs)
+		}
+		stopProcessors(unit.chain.pu)
+		stopProcessors(unit.chain.apu)
+		stopRunningOutputs(unit.ou.outputs)
+	}
+}
+
+// stopProcessors stops the given started but not yet running processors from
+// the input to the output side, i.e. in reverse order of starting them.
+func stopProcessors(units []*processorUnit) {
+	for i := len(units) - 1; i >= 0; i-- {
+		units[i].processor.Stop()
+	}
+}
+
+// forwardMetrics passes all metrics of the source channels to dst and closes
+// dst once all sources are closed.
+func forwardMetrics(dst chan<- telegraf.Metric, sources []chan telegraf.Metric) {
+	var wg sync.WaitGroup
+	for _, src := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range src {
+				dst <- m
+			}
+		}()
+	}
+	wg.Wait()
+	close(dst)
+	log.Printf("D! [agent] Pipeline channels closed")
+}
@@ -0,0 +1,278 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestPipelines(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadAll("testdata/pipelines/telegraf.conf"))
+
+	a := NewAgent(cfg)
+	pipelines, err := a.pipelines()
+	require.NoError(t, err)
+	require.Len(t, pipelines, 2)
+	require.Equal(t, "default", pipelines[0].String())
+	require.Len(t, pipelines[0].inputs, 1)
+	require.Len(t, pipelines[0].processors, 1)
+	require.Equal(t, "logs", pipelines[1].String())
+	require.Len(t, pipelines[1].inputs, 1)
+	require.Len(t, pipelines[1].processors, 1)
+
+	// Metrics must only pass the processors of their pipeline
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	actual, err := collect(ctx, a, 0)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"pipeline": "default"},
+			map[string]interface{}{"usage_idle": float64(90)},
+			time.Unix(0, 1689253834000000000),
+		),
+		metric.New(
+			"syslog",
+			map[string]string{"pipeline": "logs"},
+			map[string]interface{}{"message": "service started"},
+			time.Unix(0, 1689253834000000000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTags("host"), testutil.SortMetrics())
+}
+
+func TestPipelinesWithoutInputs(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadAll("testdata/pipelines/no_inputs.conf"))
+
+	a := NewAgent(cfg)
+	_, err := a.pipelines()
+	require.ErrorContains(t, err, `pipeline "logs" has no inputs`)
+}
+
+func TestFallbackOutputs(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadAll("testdata/fallback/telegraf.conf"))
+	require.Len(t, cfg.Outputs, 2)
+
+	a := NewAgent(cfg)
+	_, err := a.pipelines()
+	require.NoError(t, err)
+
+	primary, fallback := cfg.Outputs[0], cfg.Outputs[1]
+	require.Same(t, fallback, primary.Fallback())
+	require.Same(t, primary, fallback.Primary())
+	require.Equal(t, 0, primary.FallbackDepth())
+	require.Equal(t, 1, fallback.FallbackDepth())
+	require.Equal(t, 5, fallback.Config.FallbackAfter)
+	require.Equal(t, "replay", fallback.Config.FallbackTag)
+
+	// Resolving the fallbacks again must not fail
+	_, err = a.pipelines()
+	require.NoError(t, err)
+}
+
+func TestFallbackOutputsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      string
+		expected string
+	}{
+		{
+			name: "not found",
+			cfg: `
+[[outputs.discard]]
+  fallback_for = "outputs.file"
+`,
+			expected: `output "outputs.file" not found`,
+		},
+		{
+			name: "self",
+			cfg: `
+[[outputs.discard]]
+  fallback_for = "outputs.discard"
+`,
+			expected: "output cannot be its own fallback",
+		},
+		{
+			name: "ambiguous",
+			cfg: `
+[[outputs.discard]]
+[[outputs.discard]]
+[[outputs.file]]
+  fallback_for = "outputs.discard"
+`,
+			expected: "is ambiguous, use the instance ID",
+		},
+		{
+			name: "multiple fallbacks",
+			cfg: `
+[[outputs.discard]]
+[[outputs.file]]
+  fallback_for = "outputs.discard"
+[[outputs.file]]
+  fallback_for = "outputs.discard#0"
+`,
+			expected: "already has fallback",
+		},
+		{
+			name: "cycle",
+			cfg: `
+[[outputs.discard]]
+  fallback_for = "outputs.discard#1"
+[[outputs.discard]]
+  fallback_for = "outputs.discard#0"
+`,
+			expected: "cycle in fallback chain",
+		},
+		{
+			name: "different pipeline",
+			cfg: `
+[[outputs.discard]]
+[[outputs.file]]
+  pipeline = "logs"
+  fallback_for = "outputs.discard"
+`,
+			expected: "belongs to a different pipeline",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig()
+			require.NoError(t, cfg.LoadConfigData([]byte(tt.cfg), config.EmptySourcePath))
+
+			a := NewAgent(cfg)
+			_, err := a.pipelines()
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+// pipelineEvents records the order of stopping the plugins
+type pipelineEvents struct {
+	events []string
+}
+
+type pipelineInput struct {
+	name   string
+	events *pipelineEvents
+}
+
+func (*pipelineInput) SampleConfig() string {
+	return ""
+}
+
+func (*pipelineInput) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (*pipelineInput) Start(telegraf.Accumulator) error {
+	return nil
+}
+
+func (p *pipelineInput) Stop() {
+	p.events.events = append(p.events.events, "stop input "+p.name)
+}
+
+type pipelineProcessor struct {
+	name   string
+	err    error
+	events *pipelineEvents
+}
+
+func (*pipelineProcessor) SampleConfig() string {
+	return ""
+}
+
+func (p *pipelineProcessor) Start(telegraf.Accumulator) error {
+	return p.err
+}
+
+func (*pipelineProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	acc.AddMetric(m)
+	return nil
+}
+
+func (p *pipelineProcessor) Stop() {
+	p.events.events = append(p.events.events, "stop processor "+p.name)
+}
+
+type pipelineOutput struct {
+	name   string
+	events *pipelineEvents
+}
+
+func (*pipelineOutput) SampleConfig() string {
+	return ""
+}
+
+func (*pipelineOutput) Connect() error {
+	return nil
+}
+
+func (p *pipelineOutput) Close() error {
+	p.events.events = append(p.events.events, "close output "+p.name)
+	return nil
+}
+
+func (*pipelineOutput) Write([]telegraf.Metric) error {
+	return nil
+}
+
+func TestPipelinesStartFailure(t *testing.T) {
+	events := &pipelineEvents{}
+
+	cfg := config.NewConfig()
+	for _, name := range []string{"", "logs"} {
+		input := models.NewRunningInput(
+			&pipelineInput{name: name, events: events},
+			&models.InputConfig{Name: "test", Pipeline: name},
+		)
+		cfg.Inputs = append(cfg.Inputs, input)
+
+		output, err := models.NewRunningOutput(
+			&pipelineOutput{name: name, events: events},
+			&models.OutputConfig{Name: "test", Pipeline: name},
+			10, 100,
+		)
+		require.NoError(t, err)
+		cfg.Outputs = append(cfg.Outputs, output)
+	}
+
+	// The processor of the second pipeline fails to start
+	cfg.Processors = models.RunningProcessors{
+		models.NewRunningProcessor(
+			&pipelineProcessor{name: "", events: events},
+			&models.ProcessorConfig{Name: "test"},
+		),
+		models.NewRunningProcessor(
+			&pipelineProcessor{name: "logs", err: errors.New("failed"), events: events},
+			&models.ProcessorConfig{Name: "test", Pipeline: "logs"},
+		),
+	}
+
+	a := NewAgent(cfg)
+	require.ErrorContains(t, a.Run(t.Context()), "failed")
+
+	// All started plugins must be stopped in reverse order
+	expected := []string{
+		"close output logs",
+		"stop input ",
+		"stop processor ",
+		"close output ",
+	}
+	require.Equal(t, expected, events.events)
+}
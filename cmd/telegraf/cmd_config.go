@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
@@ -51,45 +52,11 @@ func getConfigCommands(configHandlingFlags []cli.Flag, outputBuffer io.Writer) [
 		`,
 					Flags: configHandlingFlags,
 					Action: func(cCtx *cli.Context) error {
-						// Setup logging
-						logConfig := &logger.Config{Debug: cCtx.Bool("debug")}
-						if err := logger.SetupLogging(logConfig); err != nil {
+						configFiles, err := prepareConfigLoading(cCtx)
+						if err != nil {
 							return err
 						}
 
-						// Set the environment variables handling mode
-						if cCtx.Bool("strict-env-handling") && cCtx.Bool("non-strict-env-handling") {
-							return errors.New("flags --strict-env-handling and --non-strict-env-handling cannot be used together")
-						}
-						if !cCtx.Bool("strict-env-handling") && !cCtx.Bool("non-strict-env-handling") {
-							msg := "Strict environment variable handling will be the new default starting with v1.38.0! " +
-								"If your configuration works with strict handling or you don't use environment variables it is safe " +
-								"to ignore this warning. Otherwise please explicitly add the --non-strict-env-handling flag!"
-							log.Println("W! " + color.YellowString(msg))
-						}
-						config.NonStrictEnvVarHandling = !cCtx.Bool("strict-env-handling")
-
-						// Collect the given configuration files
-						configFiles := cCtx.StringSlice("config")
-						configDir := cCtx.StringSlice("config-directory")
-						for _, fConfigDirectory := range configDir {
-							files, err := config.WalkDirectory(fConfigDirectory)
-							if err != nil {
-								return err
-							}
-							configFiles = append(configFiles, files...)
-						}
-
-						// If no "config" or "config-directory" flag(s) was
-						// provided we should load default configuration files
-						if len(configFiles) == 0 {
-							paths, err := config.GetDefaultConfigPath()
-							if err != nil {
-								return err
-							}
-							configFiles = paths
-						}
-
 						// Load the config and try to initialize the plugins
 						c := config.NewConfig()
 						c.Agent.Quiet = cCtx.Bool("quiet")
@@ -111,6 +78,72 @@ func getConfigCommands(configHandlingFlags []cli.Flag, outputBuffer io.Writer) [
 						return ag.InitPlugins()
 					},
 				},
+				{
+					Name:  "validate",
+					Usage: "validate configuration file(s) by initializing and optionally running the plugins",
+					Description: `
+The 'validate' command reads the configuration files specified via '--config' or
+'--config-directory' and initializes all plugins reporting the result of each
+plugin instead of stopping at the first error. With '--connect' the outputs
+additionally connect to their services and disconnect without writing any
+metric. With '--gather' the inputs collect a single sample which is discarded,
+service inputs are started and stopped immediately.
+The command fails if any of the checks fails.
+
+To check the file 'mysettings.conf' including connections to the outputs use
+
+> telegraf config validate --config mysettings.conf --connect
+`,
+					Flags: append(
+						[]cli.Flag{
+							&cli.BoolFlag{
+								Name:  "connect",
+								Usage: "connect the outputs and disconnect immediately",
+							},
+							&cli.BoolFlag{
+								Name:  "gather",
+								Usage: "gather a single sample of the inputs and discard it",
+							},
+							&cli.DurationFlag{
+								Name:  "timeout",
+								Usage: "maximum time for connecting a single output or gathering a single input",
+								Value: 10 * time.Second,
+							},
+						},
+						configHandlingFlags...,
+					),
+					Action: func(cCtx *cli.Context) error {
+						configFiles, err := prepareConfigLoading(cCtx)
+						if err != nil {
+							return err
+						}
+
+						settings := validateSettings{
+							connect: cCtx.Bool("connect"),
+							gather:  cCtx.Bool("gather"),
+							timeout: cCtx.Duration("timeout"),
+						}
+						if settings.timeout <= 0 {
+							return errors.New("timeout must be positive")
+						}
+
+						c := config.NewConfig()
+						c.Agent.Quiet = cCtx.Bool("quiet")
+						if err := c.LoadAll(configFiles...); err != nil {
+							return err
+						}
+						if c.Agent.SkipProcessorsAfterAggregators == nil {
+							skipProcessorsAfterAggregators := false
+							c.Agent.SkipProcessorsAfterAggregators = &skipProcessorsAfterAggregators
+						}
+
+						results := validatePlugins(c, settings)
+						if failed := printValidationResults(outputBuffer, results); failed > 0 {
+							return fmt.Errorf("validation failed for %d check(s)", failed)
+						}
+						return nil
+					},
+				},
 				{
 					Name:  "create",
 					Usage: "create a full sample configuration and show it",
@@ -256,3 +289,47 @@ To migrate the file 'mysettings.conf' use
 		},
 	}
 }
+
+// prepareConfigLoading sets up logging and environment variable handling from
+// the given flags and returns the configuration files to load
+func prepareConfigLoading(cCtx *cli.Context) ([]string, error) {
+	// Setup logging
+	logConfig := &logger.Config{Debug: cCtx.Bool("debug")}
+	if err := logger.SetupLogging(logConfig); err != nil {
+		return nil, err
+	}
+
+	// Set the environment variables handling mode
+	if cCtx.Bool("strict-env-handling") && cCtx.Bool("non-strict-env-handling") {
+		return nil, errors.New("flags --strict-env-handling and --non-strict-env-handling cannot be used together")
+	}
+	if !cCtx.Bool("strict-env-handling") && !cCtx.Bool("non-strict-env-handling") {
+		msg := "Strict environment variable handling will be the new default starting with v1.38.0! " +
+			"If your configuration works with strict handling or you don't use environment variables it is safe " +
+			"to ignore this warning. Otherwise please explicitly add the --non-strict-env-handling flag!"
+		log.Println("W! " + color.YellowString(msg))
+	}
+	config.NonStrictEnvVarHandling = !cCtx.Bool("strict-env-handling")
+
+	// Collect the given configuration files
+	configFiles := cCtx.StringSlice("config")
+	configDir := cCtx.StringSlice("config-directory")
+	for _, fConfigDirectory := range configDir {
+		files, err := config.WalkDirectory(fConfigDirectory)
+		if err != nil {
+			return nil, err
+		}
+		configFiles = append(configFiles, files...)
+	}
+
+	// If no "config" or "config-directory" flag(s) was
+	// provided we should load default configuration files
+	if len(configFiles) == 0 {
+		paths, err := config.GetDefaultConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		configFiles = paths
+	}
+	return configFiles, nil
+}
@@ -0,0 +1,150 @@
+// Command handling for the "grok" command
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/plugins/parsers/grok"
+)
+
+// patternStats holds the number of lines matched by a grok pattern
+type patternStats struct {
+	pattern string
+	matched int
+}
+
+func getGrokCommands(outputBuffer io.Writer) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "grok",
+			Usage: "commands for working with grok patterns",
+			Subcommands: []*cli.Command{
+				{
+					Name:  "validate",
+					Usage: "report the match rate of grok patterns against a log file",
+					Description: `
+The validate command parses each line of the given file with every
+pattern separately and reports the number and percentage of lines
+matched by each pattern as well as by any of the patterns.
+
+To check the built-in nginx error-log pattern against a file use
+
+> telegraf grok validate --pattern '%{NGINX_ERROR_LOG}' --file /var/log/nginx/error.log
+`,
+					Flags: []cli.Flag{
+						&cli.StringSliceFlag{
+							Name:     "pattern",
+							Usage:    "grok pattern to validate, can be specified multiple times",
+							Required: true,
+						},
+						&cli.StringFlag{
+							Name:     "file",
+							Usage:    "log file to match the patterns against",
+							Required: true,
+						},
+						&cli.StringSliceFlag{
+							Name:  "custom-pattern-file",
+							Usage: "file containing custom pattern definitions, can be specified multiple times",
+						},
+						&cli.StringFlag{
+							Name:  "timezone",
+							Usage: "timezone used to parse timestamps without zone information",
+						},
+					},
+					Action: func(cCtx *cli.Context) error {
+						return validateGrokPatterns(
+							outputBuffer,
+							cCtx.String("file"),
+							cCtx.StringSlice("pattern"),
+							cCtx.StringSlice("custom-pattern-file"),
+							cCtx.String("timezone"),
+						)
+					},
+				},
+			},
+		},
+	}
+}
+
+func validateGrokPatterns(w io.Writer, filename string, patterns, customPatternFiles []string, timezone string) error {
+	if len(patterns) == 0 {
+		return errors.New("at least one pattern is required")
+	}
+
+	// Use a separate parser per pattern as the parser stops at the first
+	// matching pattern and we want to count the matches independently.
+	parsers := make([]*grok.Parser, 0, len(patterns))
+	for _, pattern := range patterns {
+		p := &grok.Parser{
+			Patterns:           []string{pattern},
+			CustomPatternFiles: customPatternFiles,
+			Timezone:           timezone,
+			Measurement:        "grok",
+			Log:                logger.New("parsers", "grok", ""),
+		}
+		if err := p.Init(); err != nil {
+			return fmt.Errorf("initializing pattern %q failed: %w", pattern, err)
+		}
+		parsers = append(parsers, p)
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stats := make([]patternStats, 0, len(patterns))
+	for _, pattern := range patterns {
+		stats = append(stats, patternStats{pattern: pattern})
+	}
+
+	var total, matchedAny int
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		total++
+
+		var matched bool
+		for i, p := range parsers {
+			m, err := p.ParseLine(line)
+			if err != nil || m == nil {
+				continue
+			}
+			stats[i].matched++
+			matched = true
+		}
+		if matched {
+			matchedAny++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading %q failed: %w", filename, err)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATTERN\tMATCHED\tTOTAL\tRATE")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", s.pattern, s.matched, total, matchRate(s.matched, total))
+	}
+	if len(stats) > 1 {
+		fmt.Fprintf(tw, "<any>\t%d\t%d\t%s\n", matchedAny, total, matchRate(matchedAny, total))
+	}
+	return tw.Flush()
+}
+
+func matchRate(matched, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(matched)/float64(total))
+}
@@ -0,0 +1,352 @@
+// Command handling for the "replay" command
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/tidwall/wal"
+	"github.com/urfave/cli/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+)
+
+// Segment files of the write-ahead-log used by the disk buffer
+var walSegmentRe = regexp.MustCompile(`^\d{20}(?:\.START|\.END)?$`)
+
+// replaySettings holds the options of the replay command
+type replaySettings struct {
+	batchSize     int
+	rate          float64
+	retryInterval time.Duration
+	maxRetries    int
+}
+
+func getReplayCommands(configHandlingFlags []cli.Flag, outputBuffer io.Writer) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "replay",
+			Usage: "replay buffered or line-protocol metrics to the configured outputs",
+			Description: `
+The replay command reads the metrics stored in the given directory and writes
+them to the outputs configured via '--config' or '--config-directory'. Inputs,
+processors and aggregators are not started. The directory may contain the
+write-ahead-log directories created by the disk buffer strategy, i.e. copies of
+the 'buffer_directory', or files in InfluxDB line-protocol format.
+
+To replay the metrics spooled during an output outage at a limited rate use
+
+> telegraf replay --config telegraf.conf --dir /backup/telegraf-buffer --rate 5000
+`,
+			Flags: append(
+				[]cli.Flag{
+					&cli.StringFlag{
+						Name:     "dir",
+						Usage:    "directory containing the disk buffer or line-protocol files to replay",
+						Required: true,
+					},
+					&cli.Float64Flag{
+						Name:  "rate",
+						Usage: "maximum number of metrics per second written to each output, zero for unlimited",
+					},
+					&cli.IntFlag{
+						Name:  "batch-size",
+						Usage: "number of metrics written to the outputs at once",
+						Value: models.DefaultMetricBatchSize,
+					},
+					&cli.DurationFlag{
+						Name:  "retry-interval",
+						Usage: "time to wait before retrying a failed write",
+						Value: 5 * time.Second,
+					},
+					&cli.IntFlag{
+						Name:  "max-retries",
+						Usage: "number of retries for a failed write before giving up",
+						Value: 3,
+					},
+				},
+				configHandlingFlags...,
+			),
+			Action: func(cCtx *cli.Context) error {
+				configFiles, err := prepareConfigLoading(cCtx)
+				if err != nil {
+					return err
+				}
+
+				settings := replaySettings{
+					batchSize:     cCtx.Int("batch-size"),
+					rate:          cCtx.Float64("rate"),
+					retryInterval: cCtx.Duration("retry-interval"),
+					maxRetries:    cCtx.Int("max-retries"),
+				}
+				if settings.batchSize < 1 {
+					return errors.New("batch size must be positive")
+				}
+				if settings.rate < 0 {
+					return errors.New("rate must not be negative")
+				}
+
+				// Load the config using memory buffers for the outputs
+				c := config.NewConfig()
+				c.ReplayMode = true
+				c.OutputFilters = processFilterFlags(cCtx).output
+				if err := c.LoadAll(configFiles...); err != nil {
+					return err
+				}
+				if len(c.Outputs) == 0 {
+					return errors.New("no outputs configured")
+				}
+
+				dir := cCtx.String("dir")
+				switch c.Agent.BufferStrategy {
+				case "disk", "disk_write_through":
+					if sameDirectory(dir, c.Agent.BufferDirectory) {
+						return fmt.Errorf("%q is the buffer directory of the configured outputs, "+
+							"please copy the files to a separate directory to avoid sending the metrics twice", dir)
+					}
+				}
+
+				sources, err := collectReplaySources(dir)
+				if err != nil {
+					return err
+				}
+				if len(sources) == 0 {
+					return fmt.Errorf("no files to replay found in %q", dir)
+				}
+
+				for _, output := range c.Outputs {
+					if settings.batchSize > output.MetricBufferLimit {
+						return fmt.Errorf("batch size %d exceeds the metric buffer limit %d of output %s",
+							settings.batchSize, output.MetricBufferLimit, output.LogName())
+					}
+					if err := output.Init(); err != nil {
+						return fmt.Errorf("initializing output %s failed: %w", output.LogName(), err)
+					}
+					if err := output.Connect(); err != nil {
+						return fmt.Errorf("connecting output %s failed: %w", output.LogName(), err)
+					}
+					defer output.Close()
+				}
+
+				return replayMetrics(outputBuffer, c.Outputs, sources, settings)
+			},
+		},
+	}
+}
+
+// collectReplaySources returns the disk buffers and files found in the given
+// directory in lexical order
+func collectReplaySources(dir string) ([]string, error) {
+	if isWALDirectory(dir) {
+		return []string{dir}, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.IsDir():
+			if !isWALDirectory(path) {
+				log.Printf("W! Skipping directory %q not containing a disk buffer", path)
+				continue
+			}
+		case !entry.Type().IsRegular():
+			continue
+		}
+		sources = append(sources, path)
+	}
+	slices.Sort(sources)
+	return sources, nil
+}
+
+// isWALDirectory checks if the directory only contains segment files of a
+// disk buffer
+func isWALDirectory(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) == 0 {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !walSegmentRe.MatchString(entry.Name()) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameDirectory(a, b string) bool {
+	if b == "" {
+		return false
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return filepath.Clean(absA) == filepath.Clean(absB)
+}
+
+// replayMetrics reads the given sources and writes the contained metrics to
+// all outputs in batches, limiting the overall rate if requested
+func replayMetrics(w io.Writer, outputs []*models.RunningOutput, sources []string, settings replaySettings) error {
+	start := time.Now()
+	var total int
+
+	batch := make([]telegraf.Metric, 0, settings.batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		for _, output := range outputs {
+			if err := writeReplayBatch(output, batch, settings); err != nil {
+				return fmt.Errorf("writing to output %s failed after %d metrics: %w", output.LogName(), total, err)
+			}
+		}
+		total += len(batch)
+		batch = batch[:0]
+
+		// Throttle the writes if the rate is exceeded
+		if settings.rate > 0 {
+			expected := time.Duration(float64(total) / settings.rate * float64(time.Second))
+			if wait := expected - time.Since(start); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+		return nil
+	}
+
+	add := func(m telegraf.Metric) error {
+		batch = append(batch, m)
+		if len(batch) < settings.batchSize {
+			return nil
+		}
+		return flush()
+	}
+
+	for _, source := range sources {
+		before := total + len(batch)
+
+		var err error
+		if isWALDirectory(source) {
+			err = readWAL(source, add)
+		} else {
+			err = readLineProtocol(source, add)
+		}
+		if err != nil {
+			return fmt.Errorf("replaying %q failed: %w", source, err)
+		}
+		fmt.Fprintf(w, "Read %d metrics from %q\n", total+len(batch)-before, source)
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Replayed %d metrics to %d output(s) in %s\n", total, len(outputs), time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// writeReplayBatch writes the batch to the output retrying failed writes
+func writeReplayBatch(output *models.RunningOutput, batch []telegraf.Metric, settings replaySettings) error {
+	for _, m := range batch {
+		output.AddMetric(m)
+	}
+
+	var retries int
+	for output.BufferLength() > 0 {
+		err := output.WriteBatch()
+		if err == nil {
+			continue
+		}
+		if retries >= settings.maxRetries {
+			return err
+		}
+		retries++
+		output.Log().Warnf("Writing batch failed: %v; retrying in %s (%d/%d)", err, settings.retryInterval, retries, settings.maxRetries)
+		time.Sleep(settings.retryInterval)
+	}
+	return nil
+}
+
+// readWAL reads all metrics stored in the write-ahead-log of a disk buffer
+func readWAL(path string, fn func(telegraf.Metric) error) error {
+	file, err := wal.Open(path, &wal.Options{AllowEmpty: true, NoSync: true})
+	if err != nil {
+		return fmt.Errorf("opening disk buffer failed: %w", err)
+	}
+	defer file.Close()
+
+	first, err := file.FirstIndex()
+	if err != nil {
+		return err
+	}
+	last, err := file.LastIndex()
+	if err != nil {
+		return err
+	}
+	if first == 0 {
+		// Empty log
+		return nil
+	}
+
+	for idx := first; idx <= last; idx++ {
+		data, err := file.Read(idx)
+		if err != nil {
+			return fmt.Errorf("reading entry %d failed: %w", idx, err)
+		}
+		// Tracking information of the agent that wrote the buffer is lost,
+		// so replay the underlying metric
+		m, err := metric.FromBytes(data)
+		if err != nil && !errors.Is(err, metric.ErrSkipTracking) {
+			return fmt.Errorf("decoding entry %d failed: %w", idx, err)
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readLineProtocol reads all metrics of the given line-protocol file skipping
+// invalid lines
+func readLineProtocol(path string, fn func(telegraf.Metric) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	parser := influx.NewStreamParser(f)
+	for {
+		m, err := parser.Next()
+		if err != nil {
+			if errors.Is(err, influx.EOF) {
+				return nil
+			}
+			var perr *influx.ParseError
+			if errors.As(err, &perr) {
+				log.Printf("W! Skipping invalid line %d of %q: %v", parser.LineNumber(), path, err)
+				continue
+			}
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Command handling for the configuration "validate" command
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/agent"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/common/snmp"
+)
+
+// validateSettings holds the options of the validate command
+type validateSettings struct {
+	connect bool
+	gather  bool
+	timeout time.Duration
+}
+
+// validationResult is the outcome of a single check of a plugin
+type validationResult struct {
+	plugin string
+	check  string
+	detail string
+	err    error
+}
+
+// validationAccumulator records the errors and counts the metrics of a
+// sample gather
+type validationAccumulator struct {
+	telegraf.Accumulator
+
+	errs []error
+	sync.Mutex
+}
+
+func (a *validationAccumulator) AddError(err error) {
+	if err == nil {
+		return
+	}
+	a.Lock()
+	a.errs = append(a.errs, err)
+	a.Unlock()
+	a.Accumulator.AddError(err)
+}
+
+// validatePlugins initializes the plugins of the given configuration and,
+// depending on the settings, connects the outputs and gathers the inputs
+// once. All checks are executed even if some fail.
+func validatePlugins(c *config.Config, settings validateSettings) []validationResult {
+	results := make([]validationResult, 0, len(c.Inputs)+len(c.Processors)+len(c.Aggregators)+len(c.Outputs))
+
+	for _, input := range c.Inputs {
+		// Share the snmp translator setting with plugins that need it.
+		if tp, ok := input.Input.(snmp.TranslatorPlugin); ok {
+			tp.SetTranslator(c.Agent.SnmpTranslator)
+		}
+		err := input.Init()
+		results = append(results, validationResult{plugin: input.LogName(), check: "init", err: err})
+		if err != nil || !settings.gather {
+			continue
+		}
+		results = append(results, validateGather(input, settings.timeout))
+	}
+
+	processors := c.Processors
+	if !*c.Agent.SkipProcessorsAfterAggregators {
+		processors = slices.Concat(processors, c.AggProcessors)
+	}
+	for _, processor := range processors {
+		err := processor.Init()
+		results = append(results, validationResult{plugin: processor.LogName(), check: "init", err: err})
+	}
+
+	for _, aggregator := range c.Aggregators {
+		err := aggregator.Init()
+		results = append(results, validationResult{plugin: aggregator.LogName(), check: "init", err: err})
+	}
+
+	for _, output := range c.Outputs {
+		err := output.Init()
+		results = append(results, validationResult{plugin: output.LogName(), check: "init", err: err})
+		if err != nil || !settings.connect {
+			continue
+		}
+		results = append(results, validateConnect(output, settings.timeout))
+	}
+
+	return results
+}
+
+// validateGather collects a single sample of the input discarding the
+// metrics. Service inputs are started and stopped immediately.
+func validateGather(input *models.RunningInput, timeout time.Duration) validationResult {
+	metrics := make(chan telegraf.Metric)
+	var count int
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range metrics {
+			count++
+		}
+	}()
+	acc := &validationAccumulator{Accumulator: agent.NewAccumulator(input, metrics)}
+
+	result := validationResult{plugin: input.LogName(), check: "gather"}
+	si, isService := input.Input.(telegraf.ServiceInput)
+	if isService {
+		result.check = "start"
+	}
+
+	// Use the plugin directly for service inputs to not hide failures by the
+	// configured startup-error behavior
+	done := make(chan error, 1)
+	go func() {
+		if isService {
+			err := si.Start(acc)
+			if err == nil {
+				si.Stop()
+			}
+			done <- err
+			return
+		}
+		done <- input.Gather(acc)
+	}()
+
+	select {
+	case err := <-done:
+		result.err = err
+	case <-time.After(timeout):
+		// The goroutine gathering the input is abandoned, so do not close the
+		// metric channel it might still write to.
+		result.err = fmt.Errorf("not finished after %s", timeout)
+		return result
+	}
+	close(metrics)
+	wg.Wait()
+
+	if result.err == nil && len(acc.errs) > 0 {
+		result.err = errors.Join(acc.errs...)
+	}
+	if result.check == "gather" {
+		result.detail = fmt.Sprintf("%d metric(s)", count)
+	}
+	return result
+}
+
+// validateConnect connects the output and closes the connection right away
+// without writing any metric.
+func validateConnect(output *models.RunningOutput, timeout time.Duration) validationResult {
+	result := validationResult{plugin: output.LogName(), check: "connect"}
+
+	// Use the plugin directly to not hide failures by the configured
+	// startup-error behavior
+	done := make(chan error, 1)
+	go func() {
+		err := output.Output.Connect()
+		if err == nil {
+			output.Close()
+		}
+		done <- err
+	}()
+
+	select {
+	case result.err = <-done:
+	case <-time.After(timeout):
+		result.err = fmt.Errorf("not finished after %s", timeout)
+	}
+	return result
+}
+
+// printValidationResults writes the results as table and returns the number
+// of failed checks
+func printValidationResults(w io.Writer, results []validationResult) int {
+	var failed int
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESULT\tPLUGIN\tCHECK\tDETAILS")
+	for _, r := range results {
+		status, detail := "PASS", r.detail
+		if r.err != nil {
+			status, detail = "FAIL", strings.ReplaceAll(r.err.Error(), "\n", "; ")
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", status, r.plugin, r.check, detail)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "%d of %d check(s) passed\n", len(results)-failed, len(results))
+	return failed
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf/agent"
+)
+
+// healthServer exposes the liveness and readiness of the running agent via
+// HTTP for use with e.g. Kubernetes probes or load balancer checks as well as
+// debugging information of the plugins
+type healthServer struct {
+	agent  atomic.Pointer[agent.Agent]
+	server *http.Server
+	err    chan error
+}
+
+func newHealthServer() *healthServer {
+	return &healthServer{
+		err: make(chan error, 1),
+	}
+}
+
+func (h *healthServer) start(address string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", h.serveLiveness)
+	mux.HandleFunc("/ready", h.serveReadiness)
+	mux.HandleFunc("/debug/plugins", h.serveDebug)
+
+	h.server = &http.Server{
+		Addr:         address,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Printf("I! Starting health HTTP server at: %s", address)
+		if err := h.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			h.err <- err
+		}
+	}()
+}
+
+// stop shuts down the HTTP server
+func (h *healthServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := h.server.Shutdown(ctx); err != nil {
+		log.Printf("E! Stopping health HTTP server failed: %v", err)
+	}
+}
+
+// setAgent sets the agent to report the status for, replacing the previous
+// agent on configuration reloads
+func (h *healthServer) setAgent(a *agent.Agent) {
+	h.agent.Store(a)
+}
+
+func (*healthServer) serveLiveness(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(`{"status":"ok"}`)); err != nil {
+		log.Printf("E! Writing health reply failed: %v", err)
+	}
+}
+
+func (h *healthServer) serveReadiness(w http.ResponseWriter, _ *http.Request) {
+	status := &agent.HealthStatus{}
+	if a := h.agent.Load(); a != nil {
+		status = a.Health(time.Now())
+	}
+
+	body, err := json.Marshal(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if status.Ready {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if _, err := w.Write(body); err != nil {
+		log.Printf("E! Writing readiness reply failed: %v", err)
+	}
+}
+
+func (h *healthServer) serveDebug(w http.ResponseWriter, _ *http.Request) {
+	status := &agent.DebugStatus{}
+	if a := h.agent.Load(); a != nil {
+		status = a.Debug()
+	}
+
+	body, err := json.Marshal(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("E! Writing debug reply failed: %v", err)
+	}
+}
@@ -258,6 +258,7 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 			watchInterval:           cCtx.Duration("watch-interval"),
 			watchDebounceInterval:   cCtx.Duration("watch-debounce-interval"),
 			pidFile:                 cCtx.String("pidfile"),
+			healthListen:            cCtx.String("health-listen"),
 			plugindDir:              cCtx.String("plugin-directory"),
 			password:                cCtx.String("password"),
 			oldEnvBehavior:          cCtx.Bool("old-env-behavior"),
@@ -288,6 +289,8 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 		getSecretStoreCommands(m)...,
 	)
 	commands = append(commands, getPluginCommands(outputBuffer)...)
+	commands = append(commands, getGrokCommands(outputBuffer)...)
+	commands = append(commands, getReplayCommands(configHandlingFlags, outputBuffer)...)
 	commands = append(commands, getServiceCommands(outputBuffer)...)
 
 	app := &cli.App{
@@ -328,6 +331,10 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 					DefaultText: "0s",
 					Value:       0,
 				},
+				&cli.StringFlag{
+					Name:  "health-listen",
+					Usage: "host/IP and port to serve the health and readiness endpoints on (e.g. ':8888')",
+				},
 				&cli.StringFlag{
 					Name:  "pidfile",
 					Usage: "file to write our pid to",
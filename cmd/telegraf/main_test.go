@@ -6,15 +6,19 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -575,3 +579,132 @@ func TestFlagsAreSet(t *testing.T) {
 	require.Equal(t, expectedString, m.watchConfig)
 	require.Equal(t, expectedString, m.pidFile)
 }
+
+func TestCommandGrokValidate(t *testing.T) {
+	buf := new(bytes.Buffer)
+	args := []string{
+		"telegraf", "grok", "validate",
+		"--pattern", "%{NGINX_ERROR_LOG}",
+		"--pattern", "%{COMBINED_LOG_FORMAT}",
+		"--file", "testdata/grok/nginx_error.log",
+	}
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, []string{"PATTERN", "MATCHED", "TOTAL", "RATE"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"%{NGINX_ERROR_LOG}", "3", "4", "75.0%"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"%{COMBINED_LOG_FORMAT}", "0", "4", "0.0%"}, strings.Fields(lines[2]))
+	require.Equal(t, []string{"<any>", "3", "4", "75.0%"}, strings.Fields(lines[3]))
+}
+
+func TestCommandGrokValidateInvalidPattern(t *testing.T) {
+	buf := new(bytes.Buffer)
+	args := []string{
+		"telegraf", "grok", "validate",
+		"--pattern", "%{DOES_NOT_EXIST}",
+		"--file", "testdata/grok/nginx_error.log",
+	}
+	require.ErrorContains(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()), "DOES_NOT_EXIST")
+}
+
+func TestCommandReplay(t *testing.T) {
+	savedVersion := internal.Version
+	internal.Version = "0.0.0"
+	defer func() { internal.Version = savedVersion }()
+
+	dir := t.TempDir()
+	outfile := filepath.Join(t.TempDir(), "out.lp")
+	t.Setenv("REPLAY_OUTPUT_FILE", outfile)
+
+	// Create a disk buffer containing spooled metrics
+	buffer, err := models.NewBuffer("file", "abc", "", 100, "disk_write_through", dir, true)
+	require.NoError(t, err)
+	buffer.Add(
+		metric.New("cpu", map[string]string{"host": "server01"}, map[string]interface{}{"usage_idle": 90.0}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{"host": "server02"}, map[string]interface{}{"usage_idle": 80.0}, time.Unix(1700000001, 0)),
+	)
+	require.NoError(t, buffer.Close())
+
+	// Add a line-protocol file
+	lp, err := os.ReadFile("testdata/replay/metrics.lp")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "metrics.lp"), lp, 0600))
+
+	buf := new(bytes.Buffer)
+	args := []string{
+		"telegraf", "replay",
+		"--config", "testdata/replay/file.conf",
+		"--strict-env-handling",
+		"--dir", dir,
+		"--batch-size", "3",
+		"--rate", "1000",
+	}
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+	require.Contains(t, buf.String(), "Read 2 metrics from")
+	require.Contains(t, buf.String(), "Replayed 4 metrics to 1 output(s)")
+
+	actual, err := os.ReadFile(outfile)
+	require.NoError(t, err)
+	expected := "cpu,host=server01 usage_idle=90 1700000000000000000\n" +
+		"cpu,host=server02 usage_idle=80 1700000001000000000\n" +
+		"cpu,host=server01 usage_idle=91.5 1700000002000000000\n" +
+		"cpu,host=server02 usage_idle=88.1 1700000003000000000\n"
+	require.Equal(t, expected, string(actual))
+}
+
+func TestCommandReplayBufferDirectory(t *testing.T) {
+	savedVersion := internal.Version
+	internal.Version = "0.0.0"
+	defer func() { internal.Version = savedVersion }()
+
+	buf := new(bytes.Buffer)
+	args := []string{
+		"telegraf", "replay",
+		"--config", "testdata/test_mode_disk_buffer.conf",
+		"--strict-env-handling",
+		"--dir", "testdata/buffer-not-directory",
+	}
+	err := runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf())
+	require.ErrorContains(t, err, "is the buffer directory of the configured outputs")
+}
+
+func TestCommandConfigValidate(t *testing.T) {
+	buf := new(bytes.Buffer)
+	args := []string{
+		"telegraf", "config", "validate",
+		"--config", "testdata/validate/valid.conf",
+		"--strict-env-handling",
+		"--connect",
+		"--gather",
+	}
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 6)
+	require.Equal(t, []string{"RESULT", "PLUGIN", "CHECK", "DETAILS"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"PASS", "inputs.mem", "init"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"PASS", "inputs.mem", "gather", "1", "metric(s)"}, strings.Fields(lines[2]))
+	require.Equal(t, []string{"PASS", "outputs.discard", "init"}, strings.Fields(lines[3]))
+	require.Equal(t, []string{"PASS", "outputs.discard", "connect"}, strings.Fields(lines[4]))
+	require.Equal(t, "4 of 4 check(s) passed", lines[5])
+}
+
+func TestCommandConfigValidateConnectError(t *testing.T) {
+	buf := new(bytes.Buffer)
+	args := []string{
+		"telegraf", "config", "validate",
+		"--config", "testdata/validate/invalid.conf",
+		"--strict-env-handling",
+		"--connect",
+	}
+	err := runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf())
+	require.ErrorContains(t, err, "validation failed for 1 check(s)")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 5)
+	require.Equal(t, []string{"PASS", "inputs.mem", "init"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"PASS", "outputs.file", "init"}, strings.Fields(lines[2]))
+	require.Equal(t, []string{"FAIL", "outputs.file", "connect"}, strings.Fields(lines[3])[:3])
+	require.Equal(t, "2 of 3 check(s) passed", lines[4])
+}
@@ -43,6 +43,7 @@ type GlobalFlags struct {
 	watchInterval           time.Duration
 	watchDebounceInterval   time.Duration
 	pidFile                 string
+	healthListen            string
 	plugindDir              string
 	password                string
 	oldEnvBehavior          bool
@@ -75,6 +76,7 @@ type App interface {
 
 type Telegraf struct {
 	pprofErr <-chan error
+	health   *healthServer
 
 	inputFilters       []string
 	outputFilters      []string
@@ -141,6 +143,14 @@ func (t *Telegraf) GetSecretStore(id string) (telegraf.SecretStore, error) {
 }
 
 func (t *Telegraf) reloadLoop() error {
+	var healthErr <-chan error
+	if t.healthListen != "" {
+		t.health = newHealthServer()
+		t.health.start(t.healthListen)
+		defer t.health.stop()
+		healthErr = t.health.err
+	}
+
 	reloadConfig := false
 	reload := make(chan bool, 1)
 	reload <- true
@@ -200,6 +210,9 @@ func (t *Telegraf) reloadLoop() error {
 			case err := <-t.pprofErr:
 				log.Printf("E! pprof server failed: %v", err)
 				cancel()
+			case err := <-healthErr:
+				log.Printf("E! health server failed: %v", err)
+				cancel()
 			case <-stop:
 				cancel()
 			}
@@ -569,6 +582,10 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		}
 	}
 
+	if t.health != nil {
+		t.health.setAgent(ag)
+	}
+
 	return ag.Run(ctx)
 }
 
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/toml/ast"
+)
+
+// evaluateCondition evaluates the expression given in the `enable_if` plugin
+// setting. Environment variables are substituted when parsing the config, so
+// the expression only compares literal values. An expression is either a
+// boolean literal such as "true", or a single comparison `<lhs> == <rhs>` or
+// `<lhs> != <rhs>`. Operands may be enclosed in single or double quotes and
+// are compared as plain strings.
+func evaluateCondition(expr string) (bool, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return false, errors.New("empty expression")
+	}
+
+	operands, ops, err := tokenizeCondition(expr)
+	if err != nil {
+		return false, err
+	}
+
+	switch len(ops) {
+	case 0:
+		v, err := strconv.ParseBool(operands[0])
+		if err != nil {
+			return false, fmt.Errorf("invalid expression %q, expecting boolean or comparison", expr)
+		}
+		return v, nil
+	case 1:
+		if ops[0] == "==" {
+			return operands[0] == operands[1], nil
+		}
+		return operands[0] != operands[1], nil
+	}
+	return false, fmt.Errorf("invalid expression %q, only a single comparison is supported", expr)
+}
+
+// tokenizeCondition splits the expression into operands and comparison
+// operators while respecting quoted strings.
+func tokenizeCondition(expr string) (operands, ops []string, err error) {
+	var current strings.Builder
+	var quoted bool
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, nil, fmt.Errorf("unbalanced quotes in expression %q", expr)
+			}
+			if quoted || strings.TrimSpace(current.String()) != "" {
+				return nil, nil, fmt.Errorf("unexpected quote in operand of expression %q", expr)
+			}
+			current.Reset()
+			current.WriteString(expr[i+1 : i+1+end])
+			quoted = true
+			i += end + 1
+		case (c == '=' || c == '!') && i+1 < len(expr) && expr[i+1] == '=':
+			operand := current.String()
+			if !quoted {
+				operand = strings.TrimSpace(operand)
+			}
+			if !quoted && operand == "" {
+				return nil, nil, fmt.Errorf("missing operand in expression %q", expr)
+			}
+			operands = append(operands, operand)
+			ops = append(ops, expr[i:i+2])
+			current.Reset()
+			quoted = false
+			i++
+		case quoted:
+			if c != ' ' && c != '\t' {
+				return nil, nil, fmt.Errorf("unexpected character after quoted operand in expression %q", expr)
+			}
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	operand := current.String()
+	if !quoted {
+		operand = strings.TrimSpace(operand)
+	}
+	if !quoted && operand == "" {
+		return nil, nil, fmt.Errorf("missing operand in expression %q", expr)
+	}
+	operands = append(operands, operand)
+
+	return operands, ops, nil
+}
+
+func (*Config) matchesCondition(tbl *ast.Table) (bool, error) {
+	node, found := tbl.Fields["enable_if"]
+	if !found {
+		return true, nil
+	}
+	kv, ok := node.(*ast.KeyValue)
+	if !ok {
+		return false, errors.New("\"enable_if\" must be a boolean or a string expression")
+	}
+
+	switch v := kv.Value.(type) {
+	case *ast.Boolean:
+		return v.Boolean()
+	case *ast.String:
+		return evaluateCondition(v.Value)
+	}
+	return false, fmt.Errorf("\"enable_if\" must be a boolean or a string expression, got %q", kv.Value.Source())
+}
@@ -91,6 +91,9 @@ type Config struct {
 	// TestMode keeps output parsing in place while avoiding resources only
 	// needed when outputs are actually used.
 	TestMode bool
+	// ReplayMode uses memory buffers for all outputs to not interfere with
+	// the disk buffers of a running agent.
+	ReplayMode bool
 
 	SecretStores      map[string]telegraf.SecretStore
 	secretStoreSource map[string][]string
@@ -116,6 +119,14 @@ type Config struct {
 
 	seenAgentTable     bool
 	seenAgentTableOnce sync.Once
+
+	// includedFiles keeps track of the files loaded so far to avoid loading
+	// files twice via include statements and to break include cycles
+	includedFiles map[string]bool
+
+	// instanceCounts keeps track of the number of instances per plugin to
+	// assign instance IDs in the order of loading
+	instanceCounts map[string]int
 }
 
 // OrderedPlugin is used to keep the order in which they appear in a file
@@ -158,6 +169,8 @@ func NewConfig() *Config {
 		OutputFilters:      make([]string, 0),
 		SecretStoreFilters: make([]string, 0),
 		Deprecations:       make(map[string][]int64),
+		includedFiles:      make(map[string]bool),
+		instanceCounts:     make(map[string]int),
 	}
 
 	// Handle unknown version
@@ -213,6 +226,12 @@ type AgentConfig struct {
 	// at the same time by manually scheduling them in time.
 	CollectionOffset Duration
 
+	// MaxInterval enables adaptive scheduling of the inputs if larger than the
+	// collection interval. The interval of an input is backed off up to the
+	// given value while its collections take longer than the interval or the
+	// pipeline is congested.
+	MaxInterval Duration
+
 	// FlushInterval is the Interval at which to flush data
 	FlushInterval Duration
 
@@ -313,6 +332,11 @@ type AgentConfig struct {
 	// metrics buffered in the last `flush_interval` in the event of a power
 	// cut.
 	BufferDiskSync *bool `toml:"buffer_disk_sync"`
+
+	// PluginAccountingSampleRate enables estimating the CPU time and memory
+	// allocations of every n-th input gather and processor operation. The
+	// estimates are exposed via the internal plugin. Zero disables accounting.
+	PluginAccountingSampleRate int `toml:"plugin_accounting_sample_rate"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -556,6 +580,16 @@ func isURL(str string) bool {
 
 // LoadConfig loads the given config files and applies it to c
 func (c *Config) LoadConfig(path string) error {
+	// Skip files already loaded e.g. via an include statement of another file
+	if !fetchURLRe.MatchString(path) {
+		if abs, err := filepath.Abs(path); err == nil && c.includedFiles[abs] {
+			if !c.Agent.Quiet {
+				log.Printf("I! Skipping already loaded config: %s", path)
+			}
+			return nil
+		}
+	}
+
 	if !c.Agent.Quiet {
 		log.Printf("I! Loading config: %s", path)
 	}
@@ -620,6 +654,12 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 		return fmt.Errorf("error parsing data: %w", err)
 	}
 
+	// Collect the files to include after this file is processed
+	includes, err := c.resolveIncludes(tbl, path)
+	if err != nil {
+		return err
+	}
+
 	// Parse tags tables first:
 	for _, tableName := range []string{"tags", "global_tags"} {
 		if val, ok := tbl.Fields[tableName]; ok {
@@ -652,6 +692,9 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 		if c.Agent.CollectionOffset < 0 {
 			return fmt.Errorf("agent collection_offset must not be negative, found %v", c.Agent.CollectionOffset)
 		}
+		if c.Agent.MaxInterval < 0 {
+			return fmt.Errorf("agent max_interval must not be negative, found %v", c.Agent.MaxInterval)
+		}
 	}
 
 	if !c.Agent.OmitHostname {
@@ -837,9 +880,68 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 		c.AggProcessors = append(c.AggProcessors, op.plugin.(*models.RunningProcessor))
 	}
 
+	// Load the included files in the order they were specified
+	for _, fn := range includes {
+		if err := c.LoadConfig(fn); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// resolveIncludes removes the top-level "include" setting from the given
+// table and returns the list of files matching the specified glob patterns.
+// Relative patterns are resolved against the directory of the including
+// file. Matches of each pattern are sorted lexically while the order of the
+// patterns is kept.
+func (c *Config) resolveIncludes(tbl *ast.Table, path string) ([]string, error) {
+	if path != "" && !fetchURLRe.MatchString(path) {
+		if abs, err := filepath.Abs(path); err == nil {
+			c.includedFiles[abs] = true
+		}
+	}
+
+	node, found := tbl.Fields["include"]
+	if !found {
+		return nil, nil
+	}
+	if _, ok := node.(*ast.KeyValue); !ok {
+		return nil, errors.New("invalid configuration, \"include\" must be a list of file patterns")
+	}
+	patterns := c.getFieldStringSlice(tbl, "include")
+	delete(tbl.Fields, "include")
+	if c.hasErrs() {
+		return nil, c.firstErr()
+	}
+	if len(patterns) > 0 && fetchURLRe.MatchString(path) {
+		return nil, errors.New("include statements are not supported for remote configurations")
+	}
+
+	var files []string
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) && path != "" {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+		for _, m := range matches {
+			abs, err := filepath.Abs(m)
+			if err != nil {
+				return nil, fmt.Errorf("resolving include %q failed: %w", m, err)
+			}
+			if info, err := os.Stat(abs); err != nil || info.IsDir() {
+				continue
+			}
+			files = append(files, abs)
+		}
+	}
+
+	return files, nil
+}
+
 // trimBOM trims the Byte-Order-Marks from the beginning of the file.
 // this is for Windows compatibility only.
 // see https://github.com/influxdata/telegraf/issues/1378
@@ -995,6 +1097,14 @@ func (c *Config) addAggregator(name, source string, table *ast.Table) error {
 		return nil
 	}
 
+	enabled, err = c.matchesCondition(table)
+	if err != nil {
+		return fmt.Errorf("invalid condition in plugin aggregators.%s: %w", name, err)
+	}
+	if !enabled {
+		return nil
+	}
+
 	creator, ok := aggregators.Aggregators[name]
 	if !ok {
 		// Handle removed, deprecated plugins
@@ -1036,6 +1146,14 @@ func (c *Config) addSecretStore(name, source string, table *ast.Table) error {
 		return nil
 	}
 
+	enabled, err = c.matchesCondition(table)
+	if err != nil {
+		return fmt.Errorf("invalid condition in plugin secretstores.%s: %w", name, err)
+	}
+	if !enabled {
+		return nil
+	}
+
 	storeID := c.getFieldString(table, "id")
 	if storeID == "" {
 		return fmt.Errorf("%q secret store without ID", name)
@@ -1198,6 +1316,17 @@ func (c *Config) addSerializer(parentname string, table *ast.Table) (*models.Run
 	}
 	conf.LogLevel = c.getFieldString(table, "log_level")
 
+	framing, err := c.getSerializerFraming(table)
+	if err != nil {
+		return nil, err
+	}
+	conf.Framing = framing
+	conf.Workers = c.getFieldInt(table, "serialization_workers")
+	conf.ChunkSize = c.getFieldInt(table, "serialization_chunk_size")
+	if conf.Workers < 0 || conf.ChunkSize < 0 {
+		return nil, errors.New("serialization_workers and serialization_chunk_size must not be negative")
+	}
+
 	creator, ok := serializers.Serializers[conf.DataFormat]
 	if !ok {
 		return nil, fmt.Errorf("undefined but requested serializer: %s", conf.DataFormat)
@@ -1209,10 +1338,60 @@ func (c *Config) addSerializer(parentname string, table *ast.Table) (*models.Run
 	}
 
 	running := models.NewRunningSerializer(serializer, conf)
-	err := running.Init()
+
+	// Create independent serializer instances for parallel serialization as
+	// serializers are not required to be thread-safe. The options were
+	// already checked for the main instance so do not track missing fields.
+	if conf.Workers > 1 {
+		tomlCfg := &toml.Config{
+			NormFieldName: c.toml.NormFieldName,
+			FieldToKey:    c.toml.FieldToKey,
+			MissingField:  func(reflect.Type, string) error { return nil },
+		}
+		workers := make([]telegraf.Serializer, 0, conf.Workers)
+		for range conf.Workers {
+			w := creator()
+			if err := tomlCfg.UnmarshalTable(table, w); err != nil {
+				return nil, err
+			}
+			workers = append(workers, w)
+		}
+		running.SetWorkers(workers)
+	}
+
+	err = running.Init()
 	return running, err
 }
 
+// getSerializerFraming returns the batch framing configured for the
+// serializer or nil if no framing option is set
+func (c *Config) getSerializerFraming(table *ast.Table) (*telegraf.Framing, error) {
+	var found bool
+	for _, key := range []string{"framing_header", "framing_footer", "framing_separator", "framing_length_prefix"} {
+		if _, ok := table.Fields[key]; ok {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	framing := &telegraf.Framing{
+		Header:       []byte(c.getFieldString(table, "framing_header")),
+		Footer:       []byte(c.getFieldString(table, "framing_footer")),
+		Separator:    []byte(c.getFieldString(table, "framing_separator")),
+		LengthPrefix: c.getFieldInt(table, "framing_length_prefix"),
+	}
+	switch framing.LengthPrefix {
+	case 0, 1, 2, 4, 8:
+	default:
+		return nil, fmt.Errorf("invalid framing_length_prefix %d, must be one of 0, 1, 2, 4 or 8", framing.LengthPrefix)
+	}
+
+	return framing, nil
+}
+
 func (c *Config) addProcessor(name, source string, table *ast.Table) error {
 	enabled, err := c.matchesLabelSelection(table)
 	if err != nil {
@@ -1221,6 +1400,15 @@ func (c *Config) addProcessor(name, source string, table *ast.Table) error {
 	if !enabled {
 		return nil
 	}
+
+	enabled, err = c.matchesCondition(table)
+	if err != nil {
+		return fmt.Errorf("invalid condition in plugin processors.%s: %w", name, err)
+	}
+	if !enabled {
+		return nil
+	}
+
 	creator, ok := processors.Processors[name]
 	if !ok {
 		// Handle removed, deprecated plugins
@@ -1356,6 +1544,14 @@ func (c *Config) addOutput(name, source string, table *ast.Table) error {
 		return nil
 	}
 
+	enabled, err = c.matchesCondition(table)
+	if err != nil {
+		return fmt.Errorf("invalid condition in plugin outputs.%s: %w", name, err)
+	}
+	if !enabled {
+		return nil
+	}
+
 	// For outputs with serializers we need to compute the set of
 	// options that is not covered by both, the serializer and the input.
 	// We achieve this by keeping a local book of missing entries
@@ -1450,6 +1646,14 @@ func (c *Config) addInput(name, source string, table *ast.Table) error {
 		return nil
 	}
 
+	enabled, err = c.matchesCondition(table)
+	if err != nil {
+		return fmt.Errorf("invalid condition in plugin inputs.%s: %w", name, err)
+	}
+	if !enabled {
+		return nil
+	}
+
 	// For inputs with parsers we need to compute the set of
 	// options that is not covered by both, the parser and the input.
 	// We achieve this by keeping a local book of missing entries
@@ -1558,6 +1762,7 @@ func (c *Config) buildAggregator(name, source string, tbl *ast.Table) (*models.A
 	conf.NameOverride = c.getFieldString(tbl, "name_override")
 	conf.Alias = c.getFieldString(tbl, "alias")
 	conf.LogLevel = c.getFieldString(tbl, "log_level")
+	conf.Pipeline = c.getFieldString(tbl, "pipeline")
 
 	conf.Tags = make(map[string]string)
 	if node, ok := tbl.Fields["tags"]; ok {
@@ -1579,6 +1784,7 @@ func (c *Config) buildAggregator(name, source string, tbl *ast.Table) (*models.A
 	}
 
 	// Generate an ID for the plugin
+	conf.InstanceID = c.nextInstanceID("aggregators", name)
 	conf.ID, err = generatePluginID("aggregators."+name, tbl)
 	return conf, err
 }
@@ -1588,13 +1794,16 @@ func (c *Config) buildAggregator(name, source string, tbl *ast.Table) (*models.A
 // models.ProcessorConfig to be inserted into models.RunningProcessor
 func (c *Config) buildProcessor(category, name, source string, tbl *ast.Table) (*models.ProcessorConfig, error) {
 	conf := &models.ProcessorConfig{
-		Name:   name,
-		Source: source,
+		Name:                 name,
+		Source:               source,
+		AccountingSampleRate: c.Agent.PluginAccountingSampleRate,
 	}
 
 	conf.Order = c.getFieldInt64(tbl, "order")
 	conf.Alias = c.getFieldString(tbl, "alias")
 	conf.LogLevel = c.getFieldString(tbl, "log_level")
+	conf.Pipeline = c.getFieldString(tbl, "pipeline")
+	conf.FlushInterval, _ = c.getFieldDuration(tbl, "flush_interval")
 
 	if c.hasErrs() {
 		return nil, c.firstErr()
@@ -1606,11 +1815,33 @@ func (c *Config) buildProcessor(category, name, source string, tbl *ast.Table) (
 		return conf, err
 	}
 
-	// Generate an ID for the plugin
+	// Generate an ID for the plugin, the instance used after the aggregators
+	// shares the instance ID with the one running before
+	if category == "aggprocessors" {
+		conf.InstanceID = c.currentInstanceID("processors", name)
+	} else {
+		conf.InstanceID = c.nextInstanceID(category, name)
+	}
 	conf.ID, err = generatePluginID(category+"."+name, tbl)
 	return conf, err
 }
 
+// nextInstanceID returns a new instance ID for the plugin of the given
+// category and name, e.g. "inputs.cpu#0" for the first cpu input
+func (c *Config) nextInstanceID(category, name string) string {
+	key := category + "." + name
+	id := fmt.Sprintf("%s#%d", key, c.instanceCounts[key])
+	c.instanceCounts[key]++
+	return id
+}
+
+// currentInstanceID returns the last instance ID assigned to the plugin of the
+// given category and name
+func (c *Config) currentInstanceID(category, name string) string {
+	key := category + "." + name
+	return fmt.Sprintf("%s#%d", key, c.instanceCounts[key]-1)
+}
+
 // buildFilter builds a Filter
 // (tags, fields, namepass, namedrop, metricpass) to
 // be inserted into the models.OutputConfig/models.InputConfig
@@ -1701,6 +1932,7 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 		Source:                  source,
 		AlwaysIncludeLocalTags:  c.Agent.AlwaysIncludeLocalTags,
 		AlwaysIncludeGlobalTags: c.Agent.AlwaysIncludeGlobalTags,
+		AccountingSampleRate:    c.Agent.PluginAccountingSampleRate,
 	}
 	cp.Interval, _ = c.getFieldDuration(tbl, "interval")
 	cp.Precision, _ = c.getFieldDuration(tbl, "precision")
@@ -1709,6 +1941,10 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 	if cp.CollectionOffset < 0 {
 		return nil, fmt.Errorf("negative collection_offset %q is not allowed", cp.CollectionOffset)
 	}
+	cp.MaxInterval, _ = c.getFieldDuration(tbl, "max_interval")
+	if cp.MaxInterval < 0 {
+		return nil, fmt.Errorf("negative max_interval %q is not allowed", cp.MaxInterval)
+	}
 	cp.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	cp.TimeSource = c.getFieldString(tbl, "time_source")
 
@@ -1717,6 +1953,7 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 	cp.NameOverride = c.getFieldString(tbl, "name_override")
 	cp.Alias = c.getFieldString(tbl, "alias")
 	cp.LogLevel = c.getFieldString(tbl, "log_level")
+	cp.Pipeline = c.getFieldString(tbl, "pipeline")
 
 	cp.Tags = make(map[string]string)
 	if node, ok := tbl.Fields["tags"]; ok {
@@ -1727,6 +1964,15 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 		}
 	}
 
+	cp.Metadata = make(map[string]string)
+	if node, ok := tbl.Fields["metadata"]; ok {
+		if subtbl, ok := node.(*ast.Table); ok {
+			if err := c.toml.UnmarshalTable(subtbl, cp.Metadata); err != nil {
+				return nil, fmt.Errorf("could not parse metadata for input %s", name)
+			}
+		}
+	}
+
 	if c.hasErrs() {
 		return nil, c.firstErr()
 	}
@@ -1738,6 +1984,7 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 	}
 
 	// Generate an ID for the plugin
+	cp.InstanceID = c.nextInstanceID("inputs", name)
 	cp.ID, err = generatePluginID("inputs."+name, tbl)
 	return cp, err
 }
@@ -1782,6 +2029,13 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	oc.NamePrefix = c.getFieldString(tbl, "name_prefix")
 	oc.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	oc.LogLevel = c.getFieldString(tbl, "log_level")
+	oc.Pipeline = c.getFieldString(tbl, "pipeline")
+	oc.FallbackFor = c.getFieldString(tbl, "fallback_for")
+	oc.FallbackAfter = c.getFieldInt(tbl, "fallback_after")
+	oc.FallbackTag = c.getFieldString(tbl, "fallback_tag")
+	if _, found := tbl.Fields["fallback_after"]; !found {
+		oc.FallbackAfter = 3
+	}
 
 	if c.hasErrs() {
 		return nil, c.firstErr()
@@ -1792,11 +2046,14 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	}
 	if c.TestMode {
 		oc.BufferStrategy = "discard"
+	} else if c.ReplayMode {
+		oc.BufferStrategy = "memory"
 	} else if oc.BufferStrategy == "disk_write_through" {
 		log.Printf("W! Using disk-write-through buffer strategy for plugin outputs.%s, this is an experimental feature", name)
 	}
 
 	// Generate an ID for the plugin
+	oc.InstanceID = c.nextInstanceID("outputs", name)
 	oc.ID, err = generatePluginID("outputs."+name, tbl)
 	return oc, err
 }
@@ -1808,15 +2065,19 @@ func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 		"buffer_strategy", "buffer_directory", "buffer_disk_sync",
 		"collection_jitter", "collection_offset",
 		"data_format", "delay", "drop", "drop_original",
+		"fallback_after", "fallback_for", "fallback_tag",
 		"fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
+		"framing_footer", "framing_header", "framing_length_prefix", "framing_separator",
 		"grace",
 		"interval",
 		"log_level", "lvm", // What is this used for?
-		"metric_batch_size", "metric_buffer_limit", "metricpass",
+		"max_interval", "metadata", "metric_batch_size", "metric_buffer_limit", "metricpass",
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
 		"order",
-		"pass", "period", "precision",
-		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior", "labels":
+		"pass", "period", "pipeline", "precision",
+		"serialization_chunk_size", "serialization_workers",
+		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior", "labels",
+		"enable_if":
 
 	// secret store options to ignore
 	case "id":
@@ -595,6 +595,154 @@ func TestConfig_URLLikeFileName(t *testing.T) {
 	}
 }
 
+func TestConfig_Include(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll(filepath.Join("testdata", "include", "main.toml")))
+
+	// Matches of a pattern are sorted while the pattern order is kept and
+	// included files are loaded after the including file
+	expected := []string{"main", "b_1", "b_2", "a_1", "a_2"}
+	require.Equal(t, expected, memcachedServers(c))
+}
+
+func TestConfig_IncludeSkipsLoadedFiles(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll(
+		filepath.Join("testdata", "include", "main.toml"),
+		filepath.Join("testdata", "include", "conf.d", "a_1.conf"),
+		filepath.Join("testdata", "include", "conf.d", "b_2.conf"),
+	))
+
+	expected := []string{"main", "b_1", "b_2", "a_1", "a_2"}
+	require.Equal(t, expected, memcachedServers(c))
+}
+
+func TestConfig_IncludeCycle(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll(filepath.Join("testdata", "include", "cycle_a.toml")))
+	require.Equal(t, []string{"cycle_a", "cycle_b"}, memcachedServers(c))
+}
+
+func TestConfig_IncludeRemote(t *testing.T) {
+	c := config.NewConfig()
+	data := []byte("include = [\"conf.d/*.conf\"]\n")
+	err := c.LoadConfigData(data, "http://www.example.com/telegraf.conf")
+	require.ErrorContains(t, err, "include statements are not supported for remote configurations")
+}
+
+func TestConfig_InstanceIDs(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll(filepath.Join("testdata", "include", "main.toml")))
+
+	ids := make([]string, 0, len(c.Inputs))
+	for _, input := range c.Inputs {
+		ids = append(ids, input.Config.InstanceID)
+	}
+	expected := []string{
+		"inputs.memcached#0",
+		"inputs.memcached#1",
+		"inputs.memcached#2",
+		"inputs.memcached#3",
+		"inputs.memcached#4",
+	}
+	require.Equal(t, expected, ids)
+
+	// Check the ID is attached to the created metrics
+	m := metric.New("test", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	m = c.Inputs[1].MakeMetric(m)
+	require.NotNil(t, m)
+	require.Equal(t, "inputs.memcached#1", metric.Source(m))
+}
+
+func TestConfig_InputMetadata(t *testing.T) {
+	c := config.NewConfig()
+	cfg := []byte(`
+[[inputs.memcached]]
+  servers = ["localhost"]
+
+  [inputs.memcached.metadata]
+    route = "primary"
+`)
+	require.NoError(t, c.LoadConfigData(cfg, config.EmptySourcePath))
+	require.Len(t, c.Inputs, 1)
+	require.Equal(t, map[string]string{"route": "primary"}, c.Inputs[0].Config.Metadata)
+
+	// Check the metadata is attached to the created metrics but not as tags
+	m := metric.New("test", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	m = c.Inputs[0].MakeMetric(m)
+	require.NotNil(t, m)
+	route, found := m.GetMetadata("route")
+	require.True(t, found)
+	require.Equal(t, "primary", route)
+	require.Empty(t, m.TagList())
+}
+
+func TestConfig_EnableIf(t *testing.T) {
+	t.Setenv("TELEGRAF_TEST_ENV", "prod")
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig(filepath.Join("testdata", "enable_if.toml")))
+
+	expected := []string{"equal", "quoted_operator", "unset_variable", "unconditional"}
+	require.Equal(t, expected, memcachedServers(c))
+}
+
+func TestConfig_EnableIfInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		setting  string
+		expected string
+	}{
+		{
+			name:     "no boolean",
+			setting:  `"maybe"`,
+			expected: `invalid expression "maybe", expecting boolean or comparison`,
+		},
+		{
+			name:     "empty",
+			setting:  `""`,
+			expected: "empty expression",
+		},
+		{
+			name:     "multiple comparisons",
+			setting:  `"a == b == c"`,
+			expected: "only a single comparison is supported",
+		},
+		{
+			name:     "missing operand",
+			setting:  `"== prod"`,
+			expected: "missing operand",
+		},
+		{
+			name:     "unbalanced quotes",
+			setting:  `"'prod == prod"`,
+			expected: "unbalanced quotes",
+		},
+		{
+			name:     "integer",
+			setting:  "42",
+			expected: `"enable_if" must be a boolean or a string expression`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := "[[inputs.memcached]]\n  enable_if = " + tt.setting + "\n"
+			c := config.NewConfig()
+			err := c.LoadConfigData([]byte(data), config.EmptySourcePath)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func memcachedServers(c *config.Config) []string {
+	servers := make([]string, 0, len(c.Inputs))
+	for _, input := range c.Inputs {
+		servers = append(servers, input.Input.(*MockupInputPlugin).Servers...)
+	}
+	return servers
+}
+
 func TestConfig_Filtering(t *testing.T) {
 	c := config.NewConfig()
 	require.NoError(t, c.LoadAll("./testdata/filter_metricpass.toml"))
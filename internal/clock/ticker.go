@@ -14,6 +14,8 @@ type Ticker struct {
 	C chan time.Time
 
 	clk      clock.Clock
+	timer    *clock.Timer
+	mu       sync.Mutex
 	schedule time.Time
 	interval time.Duration
 	jitter   time.Duration
@@ -73,9 +75,25 @@ func (t *Ticker) Stop() {
 	t.wg.Wait()
 }
 
+// SetInterval changes the interval of the ticker. The next tick is
+// rescheduled to occur the new interval after the previous tick.
+func (t *Ticker) SetInterval(interval time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.schedule = t.schedule.Add(interval - t.interval)
+	t.interval = interval
+	if t.timer != nil {
+		t.timer.Reset(t.clk.Until(t.schedule) + internal.RandomDuration(t.jitter))
+	}
+}
+
 func (t *Ticker) run(ctx context.Context) {
 	// Start with the first scheduled tick
+	t.mu.Lock()
 	timer := t.clk.Timer(t.clk.Until(t.schedule) + internal.RandomDuration(t.jitter))
+	t.timer = timer
+	t.mu.Unlock()
 	defer timer.Stop()
 
 	if t.cfg.notifier != nil {
@@ -89,8 +107,10 @@ func (t *Ticker) run(ctx context.Context) {
 			// randomizing the timing with the given jitter (if any). Note, we
 			// need to remember the next scheduling without adding the ticker
 			// to avoid drifting of the ticks by jitter/2 on average!
+			t.mu.Lock()
 			t.schedule = t.schedule.Add(t.interval)
 			timer.Reset(t.clk.Until(t.schedule) + internal.RandomDuration(t.jitter))
+			t.mu.Unlock()
 
 			// Fire our event in a non-blocking fashion to avoid blocking the
 			// ticker if the agent code did not read the channel yet
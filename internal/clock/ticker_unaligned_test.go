@@ -50,6 +50,45 @@ func TestUnalignedTicker(t *testing.T) {
 	require.Equal(t, expected, actual)
 }
 
+func TestUnalignedTickerSetInterval(t *testing.T) {
+	interval := 10 * time.Second
+	jitter := 0 * time.Second
+	offset := 0 * time.Second
+
+	clk := clock.NewMock()
+	clk.Add(1 * time.Second)
+
+	startup := make(chan bool, 1)
+
+	ticker := NewTicker(interval, jitter, offset, WithClock(clk), WithStartupNotification(startup))
+	defer ticker.Stop()
+
+	// Wait for the ticker to startup
+	<-startup
+
+	actual := []time.Time{(<-ticker.C).UTC()}
+
+	// Back off the interval, a tick at 11s would show up first
+	ticker.SetInterval(2 * interval)
+	clk.Add(2 * interval)
+	actual = append(actual, (<-ticker.C).UTC())
+	clk.Add(2 * interval)
+	actual = append(actual, (<-ticker.C).UTC())
+
+	// Return to the original interval
+	ticker.SetInterval(interval)
+	clk.Add(interval)
+	actual = append(actual, (<-ticker.C).UTC())
+
+	expected := []time.Time{
+		time.Unix(1, 0).UTC(),
+		time.Unix(21, 0).UTC(),
+		time.Unix(41, 0).UTC(),
+		time.Unix(51, 0).UTC(),
+	}
+	require.Equal(t, expected, actual)
+}
+
 // TestUnalignedTickerJitterBehavior shows UnalignedTicker behavior with jitter.
 // UnalignedTicker uses a fixed interval ticker internally, so jitter only adds
 // delay but doesn't cause cumulative drift.
@@ -0,0 +1,181 @@
+package compress
+
+import (
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+var levelsDefault = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+
+// The zstd levels are mapped to the nearest levels supported by the encoder
+var levelsZstd = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22}
+
+var levelsLz4 = map[int]lz4.CompressionLevel{
+	0: lz4.Fast,
+	1: lz4.Level1,
+	2: lz4.Level2,
+	3: lz4.Level3,
+	4: lz4.Level4,
+	5: lz4.Level5,
+	6: lz4.Level6,
+	7: lz4.Level7,
+	8: lz4.Level8,
+	9: lz4.Level9,
+}
+
+func init() {
+	Add("identity", Codec{
+		NewWriter: func(w io.Writer, level int) (Writer, error) {
+			if err := checkLevel("identity", level, nil); err != nil {
+				return nil, err
+			}
+			return &identityWriter{w: w}, nil
+		},
+		NewReader: func(r io.Reader) (Reader, error) {
+			return &identityReader{r: r}, nil
+		},
+	})
+
+	Add("gzip", Codec{
+		NewWriter: func(w io.Writer, level int) (Writer, error) {
+			if err := checkLevel("gzip", level, levelsDefault); err != nil {
+				return nil, err
+			}
+			if level == DefaultLevel {
+				level = gzip.DefaultCompression
+			}
+			return gzip.NewWriterLevel(w, level)
+		},
+		NewReader: func(r io.Reader) (Reader, error) {
+			return gzip.NewReader(r)
+		},
+	})
+
+	Add("zlib", Codec{
+		NewWriter: func(w io.Writer, level int) (Writer, error) {
+			if err := checkLevel("zlib", level, levelsDefault); err != nil {
+				return nil, err
+			}
+			if level == DefaultLevel {
+				level = zlib.DefaultCompression
+			}
+			return zlib.NewWriterLevel(w, level)
+		},
+		NewReader: func(r io.Reader) (Reader, error) {
+			zr, err := zlib.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return &zlibReader{zr}, nil
+		},
+	})
+
+	Add("zstd", Codec{
+		NewWriter: func(w io.Writer, level int) (Writer, error) {
+			if err := checkLevel("zstd", level, levelsZstd); err != nil {
+				return nil, err
+			}
+			options := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+			if level != DefaultLevel {
+				options = append(options, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+			}
+			return zstd.NewWriter(w, options...)
+		},
+		NewReader: func(r io.Reader) (Reader, error) {
+			return zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		},
+	})
+
+	Add("lz4", Codec{
+		NewWriter: func(w io.Writer, level int) (Writer, error) {
+			if err := checkLevel("lz4", level, levelsDefault); err != nil {
+				return nil, err
+			}
+			lw := lz4.NewWriter(w)
+			if level == DefaultLevel {
+				return lw, nil
+			}
+			if err := lw.Apply(lz4.CompressionLevelOption(levelsLz4[level])); err != nil {
+				return nil, err
+			}
+			return lw, nil
+		},
+		NewReader: func(r io.Reader) (Reader, error) {
+			return &lz4Reader{lz4.NewReader(r)}, nil
+		},
+	})
+
+	// Snappy uses the framing format as the block format is not suited for
+	// streaming
+	Add("snappy", Codec{
+		NewWriter: func(w io.Writer, level int) (Writer, error) {
+			if err := checkLevel("snappy", level, nil); err != nil {
+				return nil, err
+			}
+			return snappy.NewBufferedWriter(w), nil
+		},
+		NewReader: func(r io.Reader) (Reader, error) {
+			return &snappyReader{snappy.NewReader(r)}, nil
+		},
+	})
+}
+
+type identityWriter struct {
+	w io.Writer
+}
+
+func (w *identityWriter) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
+
+func (*identityWriter) Close() error {
+	return nil
+}
+
+func (w *identityWriter) Reset(dst io.Writer) {
+	w.w = dst
+}
+
+type identityReader struct {
+	r io.Reader
+}
+
+func (r *identityReader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func (r *identityReader) Reset(src io.Reader) error {
+	r.r = src
+	return nil
+}
+
+type zlibReader struct {
+	io.ReadCloser
+}
+
+func (r *zlibReader) Reset(src io.Reader) error {
+	return r.ReadCloser.(zlib.Resetter).Reset(src, nil)
+}
+
+type lz4Reader struct {
+	*lz4.Reader
+}
+
+func (r *lz4Reader) Reset(src io.Reader) error {
+	r.Reader.Reset(src)
+	return nil
+}
+
+type snappyReader struct {
+	*snappy.Reader
+}
+
+func (r *snappyReader) Reset(src io.Reader) error {
+	r.Reader.Reset(src)
+	return nil
+}
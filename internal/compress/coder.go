@@ -0,0 +1,181 @@
+package compress
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Encoder compresses buffers with a codec reusing the writers between calls.
+// It is safe for concurrent use.
+type Encoder struct {
+	name  string
+	codec Codec
+	level int
+	pool  sync.Pool
+}
+
+// NewEncoder returns an encoder for the given content encoding and level.
+func NewEncoder(name string, level int) (*Encoder, error) {
+	codec, found := Get(name)
+	if !found {
+		return nil, unknownEncoding(name)
+	}
+
+	// Create the first writer to validate the level
+	w, err := codec.NewWriter(io.Discard, level)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Encoder{name: name, codec: codec, level: level}
+	e.pool.Put(w)
+	return e, nil
+}
+
+// Encode returns the compressed data.
+func (e *Encoder) Encode(data []byte) ([]byte, error) {
+	if IsIdentity(e.name) {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	w, err := e.writer(&buf)
+	if err != nil {
+		return nil, err
+	}
+	defer e.release(w)
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NewWriter returns a writer compressing into w. The writer must be closed
+// to flush the data and to return it for reuse.
+func (e *Encoder) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	cw, err := e.writer(w)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledWriter{Writer: cw, encoder: e}, nil
+}
+
+func (e *Encoder) writer(w io.Writer) (Writer, error) {
+	if cw, ok := e.pool.Get().(Writer); ok {
+		cw.Reset(w)
+		return cw, nil
+	}
+	return e.codec.NewWriter(w, e.level)
+}
+
+func (e *Encoder) release(w Writer) {
+	// Do not keep a reference to the output
+	w.Reset(io.Discard)
+	e.pool.Put(w)
+}
+
+type pooledWriter struct {
+	Writer
+	encoder *Encoder
+	closed  bool
+}
+
+func (w *pooledWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.Writer.Close()
+	w.encoder.release(w.Writer)
+	return err
+}
+
+// Decoder decompresses buffers with a codec reusing the readers between
+// calls. It is safe for concurrent use.
+type Decoder struct {
+	name    string
+	codec   Codec
+	maxSize int64
+	pool    sync.Pool
+}
+
+// NewDecoder returns a decoder for the given content encoding limiting the
+// size of the decompressed buffers to maxSize bytes.
+func NewDecoder(name string, maxSize int64) (*Decoder, error) {
+	codec, found := Get(name)
+	if !found {
+		return nil, unknownEncoding(name)
+	}
+	if maxSize <= 0 {
+		return nil, errors.New("maximum decompression size must be positive")
+	}
+	return &Decoder{name: name, codec: codec, maxSize: maxSize}, nil
+}
+
+// Decode returns the decompressed data.
+func (d *Decoder) Decode(data []byte) ([]byte, error) {
+	if IsIdentity(d.name) {
+		return data, nil
+	}
+
+	r, err := d.reader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer d.release(r)
+
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, r, d.maxSize+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if n > d.maxSize {
+		return nil, fmt.Errorf("size of decoded data exceeds allowed size %d", d.maxSize)
+	}
+	return buf.Bytes(), nil
+}
+
+// NewReader returns a reader decompressing the data read from r. The reader
+// should be closed to return it for reuse.
+func (d *Decoder) NewReader(r io.Reader) (io.ReadCloser, error) {
+	cr, err := d.reader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledReader{Reader: cr, decoder: d}, nil
+}
+
+func (d *Decoder) reader(r io.Reader) (Reader, error) {
+	if cr, ok := d.pool.Get().(Reader); ok {
+		if err := cr.Reset(r); err != nil {
+			return nil, err
+		}
+		return cr, nil
+	}
+	return d.codec.NewReader(r)
+}
+
+func (d *Decoder) release(r Reader) {
+	d.pool.Put(r)
+}
+
+type pooledReader struct {
+	Reader
+	decoder *Decoder
+	closed  bool
+}
+
+func (r *pooledReader) Close() error {
+	if !r.closed {
+		r.closed = true
+		r.decoder.release(r.Reader)
+	}
+	return nil
+}
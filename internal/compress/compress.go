@@ -0,0 +1,87 @@
+// Package compress provides a registry of compression codecs shared by the
+// plugins for encoding and decoding payloads, e.g. via a "content_encoding"
+// setting.
+package compress
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+)
+
+// DefaultLevel selects the default compression level of a codec
+const DefaultLevel = -1
+
+// Writer compresses the data written to it into the underlying writer. The
+// data is only completely flushed when closing the writer.
+type Writer interface {
+	io.WriteCloser
+
+	// Reset discards the state of the writer and switches to the given
+	// underlying writer, allowing to reuse the writer after closing it.
+	Reset(w io.Writer)
+}
+
+// Reader decompresses the data read from the underlying reader.
+type Reader interface {
+	io.Reader
+
+	// Reset discards the state of the reader and switches to the given
+	// underlying reader.
+	Reset(r io.Reader) error
+}
+
+// Codec creates compressing writers and decompressing readers for one
+// content encoding.
+type Codec struct {
+	// NewWriter returns a writer compressing into w with the given level
+	// where DefaultLevel selects the default level of the codec.
+	NewWriter func(w io.Writer, level int) (Writer, error)
+
+	// NewReader returns a reader decompressing the data read from r.
+	NewReader func(r io.Reader) (Reader, error)
+}
+
+var registry = make(map[string]Codec)
+
+// Add registers the codec for the given content encoding name.
+func Add(name string, codec Codec) {
+	registry[name] = codec
+}
+
+// Get returns the codec registered for the given content encoding name.
+// The empty name is an alias for "identity".
+func Get(name string) (Codec, bool) {
+	if name == "" {
+		name = "identity"
+	}
+	codec, found := registry[name]
+	return codec, found
+}
+
+// Names returns the sorted names of all registered content encodings.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsIdentity checks if the content encoding does not alter the data.
+func IsIdentity(name string) bool {
+	return name == "" || name == "identity"
+}
+
+func unknownEncoding(name string) error {
+	return fmt.Errorf("unknown content encoding %q, available are %v", name, Names())
+}
+
+func checkLevel(name string, level int, valid []int) error {
+	if level == DefaultLevel || slices.Contains(valid, level) {
+		return nil
+	}
+	return fmt.Errorf("invalid compression level %d for %q, supported are %v", level, name, valid)
+}
@@ -0,0 +1,118 @@
+package compress
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundtrip(t *testing.T) {
+	data := []byte(strings.Repeat("cpu,host=localhost usage_idle=99.5 1700000000000000000\n", 100))
+
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			enc, err := NewEncoder(name, DefaultLevel)
+			require.NoError(t, err)
+			dec, err := NewDecoder(name, 1024*1024)
+			require.NoError(t, err)
+
+			// Run multiple times to reuse the pooled writers and readers
+			for range 3 {
+				encoded, err := enc.Encode(data)
+				require.NoError(t, err)
+				if name != "identity" {
+					require.Less(t, len(encoded), len(data))
+				}
+
+				decoded, err := dec.Decode(encoded)
+				require.NoError(t, err)
+				require.Equal(t, data, decoded)
+			}
+		})
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	data := []byte(strings.Repeat("mem,host=localhost used=1234i 1700000000000000000\n", 100))
+
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			enc, err := NewEncoder(name, DefaultLevel)
+			require.NoError(t, err)
+			dec, err := NewDecoder(name, 1024*1024)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			w, err := enc.NewWriter(&buf)
+			require.NoError(t, err)
+			_, err = w.Write(data[:100])
+			require.NoError(t, err)
+			_, err = w.Write(data[100:])
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			r, err := dec.NewReader(&buf)
+			require.NoError(t, err)
+			defer r.Close()
+			decoded, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, data, decoded)
+		})
+	}
+}
+
+func TestLevels(t *testing.T) {
+	tests := []struct {
+		name  string
+		level int
+		valid bool
+	}{
+		{name: "gzip", level: 9, valid: true},
+		{name: "gzip", level: 10},
+		{name: "zlib", level: 0, valid: true},
+		{name: "zstd", level: 19, valid: true},
+		{name: "zstd", level: 0},
+		{name: "lz4", level: 9, valid: true},
+		{name: "lz4", level: 12},
+		{name: "snappy", level: 1},
+		{name: "identity", level: 1},
+	}
+
+	for _, tt := range tests {
+		_, err := NewEncoder(tt.name, tt.level)
+		if tt.valid {
+			require.NoError(t, err, "%s level %d", tt.name, tt.level)
+		} else {
+			require.ErrorContains(t, err, "invalid compression level", "%s level %d", tt.name, tt.level)
+		}
+	}
+}
+
+func TestUnknownEncoding(t *testing.T) {
+	_, err := NewEncoder("brotli", DefaultLevel)
+	require.ErrorContains(t, err, `unknown content encoding "brotli"`)
+
+	_, err = NewDecoder("brotli", 1024)
+	require.ErrorContains(t, err, `unknown content encoding "brotli"`)
+}
+
+func TestDecodeMaxSize(t *testing.T) {
+	enc, err := NewEncoder("zstd", DefaultLevel)
+	require.NoError(t, err)
+	encoded, err := enc.Encode(make([]byte, 1025))
+	require.NoError(t, err)
+
+	dec, err := NewDecoder("zstd", 1024)
+	require.NoError(t, err)
+	_, err = dec.Decode(encoded)
+	require.ErrorContains(t, err, "size of decoded data exceeds allowed size 1024")
+
+	dec, err = NewDecoder("zstd", 1025)
+	require.NoError(t, err)
+	decoded, err := dec.Decode(encoded)
+	require.NoError(t, err)
+	require.Len(t, decoded, 1025)
+}
@@ -9,8 +9,9 @@ import (
 
 	"github.com/klauspost/compress/gzip"
 	"github.com/klauspost/compress/zlib"
-	"github.com/klauspost/compress/zstd"
 	"github.com/klauspost/pgzip"
+
+	"github.com/influxdata/telegraf/internal/compress"
 )
 
 const defaultMaxDecompressionSize int64 = 500 * 1024 * 1024 // 500MB
@@ -52,8 +53,37 @@ func NewStreamContentDecoder(encoding string, r io.Reader) (io.Reader, error) {
 	case "identity", "":
 		return r, nil
 	default:
+		codec, found := compress.Get(encoding)
+		if !found {
+			return nil, errors.New("invalid value for content_encoding")
+		}
+		return codec.NewReader(r)
+	}
+}
+
+// StreamDecoder creates readers decompressing streams with the given content
+// encoding. Closed readers are reused for the following streams.
+type StreamDecoder struct {
+	decoder *compress.Decoder
+}
+
+func NewStreamDecoder(encoding string) (*StreamDecoder, error) {
+	if _, found := compress.Get(encoding); !found {
 		return nil, errors.New("invalid value for content_encoding")
 	}
+
+	// The size limit only applies to decoding buffers
+	d, err := compress.NewDecoder(encoding, defaultMaxDecompressionSize)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamDecoder{decoder: d}, nil
+}
+
+// NewReader returns a reader decompressing the data read from r. The reader
+// must be closed to release it for reuse.
+func (d *StreamDecoder) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return d.decoder.NewReader(r)
 }
 
 // GzipReader is similar to gzip.Reader but reads only a single gzip stream per read.
@@ -114,7 +144,7 @@ func NewContentEncoder(encoding string, options ...EncodingOption) (ContentEncod
 	case "zstd":
 		return NewZstdEncoder(options...)
 	default:
-		return nil, errors.New("invalid value for content_encoding")
+		return newCodecEncoder(encoding, options...)
 	}
 }
 
@@ -122,6 +152,8 @@ type AutoDecoder struct {
 	encoding string
 	gzip     *GzipDecoder
 	identity *IdentityDecoder
+	codecs   map[string]*codecDecoder
+	options  []DecodingOption
 }
 
 func (a *AutoDecoder) SetEncoding(encoding string) {
@@ -132,7 +164,21 @@ func (a *AutoDecoder) Decode(data []byte) ([]byte, error) {
 	if a.encoding == "gzip" {
 		return a.gzip.Decode(data)
 	}
-	return a.identity.Decode(data)
+	if _, found := compress.Get(a.encoding); !found || compress.IsIdentity(a.encoding) {
+		return a.identity.Decode(data)
+	}
+
+	// Create decoders for the other registered encodings on first use
+	d, found := a.codecs[a.encoding]
+	if !found {
+		cd, err := newCodecDecoder(a.encoding, a.options...)
+		if err != nil {
+			return nil, err
+		}
+		d = cd
+		a.codecs[a.encoding] = d
+	}
+	return d.Decode(data)
 }
 
 func NewAutoContentDecoder(options ...DecodingOption) *AutoDecoder {
@@ -140,6 +186,8 @@ func NewAutoContentDecoder(options ...DecodingOption) *AutoDecoder {
 
 	a.identity = NewIdentityDecoder(options...)
 	a.gzip = NewGzipDecoder(options...)
+	a.codecs = make(map[string]*codecDecoder)
+	a.options = options
 	return &a
 }
 
@@ -157,7 +205,7 @@ func NewContentDecoder(encoding string, options ...DecodingOption) (ContentDecod
 	case "zstd":
 		return NewZstdDecoder(options...)
 	default:
-		return nil, errors.New("invalid value for content_encoding")
+		return newCodecDecoder(encoding, options...)
 	}
 }
 
@@ -169,12 +217,12 @@ type ContentEncoder interface {
 // GzipEncoder compresses the buffer using gzip at the default level.
 type GzipEncoder struct {
 	pwriter *pgzip.Writer
-	writer  *gzip.Writer
+	encoder *compress.Encoder
 	buf     *bytes.Buffer
 }
 
 func NewGzipEncoder(options ...EncodingOption) (*GzipEncoder, error) {
-	cfg := encoderConfig{level: gzip.DefaultCompression}
+	cfg := encoderConfig{level: compress.DefaultLevel}
 	for _, o := range options {
 		o(&cfg)
 	}
@@ -193,38 +241,26 @@ func NewGzipEncoder(options ...EncodingOption) (*GzipEncoder, error) {
 		return nil, err
 	}
 
-	w, err := gzip.NewWriterLevel(&buf, cfg.level)
+	e, err := compress.NewEncoder("gzip", cfg.level)
+	if err != nil {
+		return nil, err
+	}
 	return &GzipEncoder{
 		pwriter: pw,
-		writer:  w,
+		encoder: e,
 		buf:     &buf,
-	}, err
+	}, nil
 }
 
 func (e *GzipEncoder) Encode(data []byte) ([]byte, error) {
 	// Parallel Gzip is only faster for larger data chunks. According to the
 	// project's documentation the trade-off size is at about 1MB, so we switch
-	// to parallel Gzip if the data is larger and run the built-in version
+	// to parallel Gzip if the data is larger and run the registered codec
 	// otherwise.
 	if len(data) > 1024*1024 {
 		return e.encodeBig(data)
 	}
-	return e.encodeSmall(data)
-}
-
-func (e *GzipEncoder) encodeSmall(data []byte) ([]byte, error) {
-	e.buf.Reset()
-	e.writer.Reset(e.buf)
-
-	_, err := e.writer.Write(data)
-	if err != nil {
-		return nil, err
-	}
-	err = e.writer.Close()
-	if err != nil {
-		return nil, err
-	}
-	return e.buf.Bytes(), nil
+	return e.encoder.Encode(data)
 }
 
 func (e *GzipEncoder) encodeBig(data []byte) ([]byte, error) {
@@ -243,12 +279,11 @@ func (e *GzipEncoder) encodeBig(data []byte) ([]byte, error) {
 }
 
 type ZlibEncoder struct {
-	writer *zlib.Writer
-	buf    *bytes.Buffer
+	*compress.Encoder
 }
 
 func NewZlibEncoder(options ...EncodingOption) (*ZlibEncoder, error) {
-	cfg := encoderConfig{level: zlib.DefaultCompression}
+	cfg := encoderConfig{level: compress.DefaultLevel}
 	for _, o := range options {
 		o(&cfg)
 	}
@@ -260,31 +295,15 @@ func NewZlibEncoder(options ...EncodingOption) (*ZlibEncoder, error) {
 		return nil, errors.New("invalid compression level, only 0, 1 and 9 are supported")
 	}
 
-	var buf bytes.Buffer
-	w, err := zlib.NewWriterLevel(&buf, cfg.level)
-	return &ZlibEncoder{
-		writer: w,
-		buf:    &buf,
-	}, err
-}
-
-func (e *ZlibEncoder) Encode(data []byte) ([]byte, error) {
-	e.buf.Reset()
-	e.writer.Reset(e.buf)
-
-	_, err := e.writer.Write(data)
-	if err != nil {
-		return nil, err
-	}
-	err = e.writer.Close()
+	e, err := compress.NewEncoder("zlib", cfg.level)
 	if err != nil {
 		return nil, err
 	}
-	return e.buf.Bytes(), nil
+	return &ZlibEncoder{e}, nil
 }
 
 type ZstdEncoder struct {
-	encoder *zstd.Encoder
+	*compress.Encoder
 }
 
 func NewZstdEncoder(options ...EncodingOption) (*ZstdEncoder, error) {
@@ -293,29 +312,43 @@ func NewZstdEncoder(options ...EncodingOption) (*ZstdEncoder, error) {
 		o(&cfg)
 	}
 
-	// Map the levels
-	var level zstd.EncoderLevel
+	// Only accept the levels corresponding to the speed settings of the
+	// encoder, those are mapped by the registered codec
 	switch cfg.level {
-	case 1:
-		level = zstd.SpeedFastest
-	case 3:
-		level = zstd.SpeedDefault
-	case 7:
-		level = zstd.SpeedBetterCompression
-	case 11:
-		level = zstd.SpeedBestCompression
+	case 1, 3, 7, 11:
+		// Do nothing as those are valid levels
 	default:
 		return nil, errors.New("invalid compression level, only 1, 3, 7 and 11 are supported")
 	}
 
-	e, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level))
-	return &ZstdEncoder{
-		encoder: e,
-	}, err
+	e, err := compress.NewEncoder("zstd", cfg.level)
+	if err != nil {
+		return nil, err
+	}
+	return &ZstdEncoder{e}, nil
 }
 
-func (e *ZstdEncoder) Encode(data []byte) ([]byte, error) {
-	return e.encoder.EncodeAll(data, make([]byte, 0, len(data))), nil
+// codecEncoder compresses the buffer using a codec of the compress registry
+// without specific level restrictions, e.g. lz4 or snappy.
+type codecEncoder struct {
+	*compress.Encoder
+}
+
+func newCodecEncoder(encoding string, options ...EncodingOption) (*codecEncoder, error) {
+	if _, found := compress.Get(encoding); !found {
+		return nil, errors.New("invalid value for content_encoding")
+	}
+
+	cfg := encoderConfig{level: compress.DefaultLevel}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	e, err := compress.NewEncoder(encoding, cfg.level)
+	if err != nil {
+		return nil, err
+	}
+	return &codecEncoder{e}, nil
 }
 
 // IdentityEncoder is a null encoder that applies no transformation.
@@ -342,9 +375,9 @@ type ContentDecoder interface {
 // GzipDecoder decompresses buffers with gzip compression.
 type GzipDecoder struct {
 	preader              *pgzip.Reader
-	reader               *gzip.Reader
 	buf                  *bytes.Buffer
 	maxDecompressionSize int64
+	*codecDecoder
 }
 
 func NewGzipDecoder(options ...DecodingOption) *GzipDecoder {
@@ -355,44 +388,21 @@ func NewGzipDecoder(options ...DecodingOption) *GzipDecoder {
 
 	return &GzipDecoder{
 		preader:              new(pgzip.Reader),
-		reader:               new(gzip.Reader),
 		buf:                  new(bytes.Buffer),
 		maxDecompressionSize: cfg.maxDecompressionSize,
+		codecDecoder:         mustCodecDecoder("gzip", cfg),
 	}
 }
 
-func (*GzipDecoder) SetEncoding(string) {}
-
 func (d *GzipDecoder) Decode(data []byte) ([]byte, error) {
 	// Parallel Gzip is only faster for larger data chunks. According to the
 	// project's documentation the trade-off size is at about 1MB, so we switch
-	// to parallel Gzip if the data is larger and run the built-in version
+	// to parallel Gzip if the data is larger and run the registered codec
 	// otherwise.
 	if len(data) > 1024*1024 {
 		return d.decodeBig(data)
 	}
-	return d.decodeSmall(data)
-}
-
-func (d *GzipDecoder) decodeSmall(data []byte) ([]byte, error) {
-	err := d.reader.Reset(bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
-	}
-	d.buf.Reset()
-
-	n, err := io.CopyN(d.buf, d.reader, d.maxDecompressionSize)
-	if err != nil && !errors.Is(err, io.EOF) {
-		return nil, err
-	} else if n == d.maxDecompressionSize {
-		return nil, fmt.Errorf("size of decoded data exceeds allowed size %d", d.maxDecompressionSize)
-	}
-
-	err = d.reader.Close()
-	if err != nil {
-		return nil, err
-	}
-	return d.buf.Bytes(), nil
+	return d.codecDecoder.Decode(data)
 }
 
 func (d *GzipDecoder) decodeBig(data []byte) ([]byte, error) {
@@ -417,8 +427,7 @@ func (d *GzipDecoder) decodeBig(data []byte) ([]byte, error) {
 }
 
 type ZlibDecoder struct {
-	buf                  *bytes.Buffer
-	maxDecompressionSize int64
+	*codecDecoder
 }
 
 func NewZlibDecoder(options ...DecodingOption) *ZlibDecoder {
@@ -427,57 +436,58 @@ func NewZlibDecoder(options ...DecodingOption) *ZlibDecoder {
 		o(&cfg)
 	}
 
-	return &ZlibDecoder{
-		buf:                  new(bytes.Buffer),
-		maxDecompressionSize: cfg.maxDecompressionSize,
-	}
+	return &ZlibDecoder{mustCodecDecoder("zlib", cfg)}
 }
 
-func (*ZlibDecoder) SetEncoding(string) {}
-
-func (d *ZlibDecoder) Decode(data []byte) ([]byte, error) {
-	d.buf.Reset()
-
-	b := bytes.NewBuffer(data)
-	r, err := zlib.NewReader(b)
-	if err != nil {
-		return nil, err
-	}
-
-	n, err := io.CopyN(d.buf, r, d.maxDecompressionSize)
-	if err != nil && !errors.Is(err, io.EOF) {
-		return nil, err
-	} else if n == d.maxDecompressionSize {
-		return nil, fmt.Errorf("size of decoded data exceeds allowed size %d", d.maxDecompressionSize)
-	}
+type ZstdDecoder struct {
+	*codecDecoder
+}
 
-	err = r.Close()
+func NewZstdDecoder(options ...DecodingOption) (*ZstdDecoder, error) {
+	d, err := newCodecDecoder("zstd", options...)
 	if err != nil {
 		return nil, err
 	}
-	return d.buf.Bytes(), nil
+	return &ZstdDecoder{d}, nil
 }
 
-type ZstdDecoder struct {
-	decoder *zstd.Decoder
+// codecDecoder decompresses buffers using a codec of the compress registry.
+type codecDecoder struct {
+	decoder *compress.Decoder
+	err     error
 }
 
-func NewZstdDecoder(options ...DecodingOption) (*ZstdDecoder, error) {
+func newCodecDecoder(encoding string, options ...DecodingOption) (*codecDecoder, error) {
+	if _, found := compress.Get(encoding); !found {
+		return nil, errors.New("invalid value for content_encoding")
+	}
+
 	cfg := decoderConfig{maxDecompressionSize: defaultMaxDecompressionSize}
 	for _, o := range options {
 		o(&cfg)
 	}
 
-	d, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0), zstd.WithDecoderMaxWindow(uint64(cfg.maxDecompressionSize)))
-	return &ZstdDecoder{
-		decoder: d,
-	}, err
+	d, err := compress.NewDecoder(encoding, cfg.maxDecompressionSize)
+	if err != nil {
+		return nil, err
+	}
+	return &codecDecoder{decoder: d}, nil
 }
 
-func (*ZstdDecoder) SetEncoding(string) {}
+// mustCodecDecoder creates a decoder for the constructors not returning an
+// error, invalid settings are reported when decoding instead.
+func mustCodecDecoder(encoding string, cfg decoderConfig) *codecDecoder {
+	d, err := compress.NewDecoder(encoding, cfg.maxDecompressionSize)
+	return &codecDecoder{decoder: d, err: err}
+}
+
+func (*codecDecoder) SetEncoding(string) {}
 
-func (d *ZstdDecoder) Decode(data []byte) ([]byte, error) {
-	return d.decoder.DecodeAll(data, nil)
+func (d *codecDecoder) Decode(data []byte) ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d.decoder.Decode(data)
 }
 
 // IdentityDecoder is a null decoder that returns the input.
@@ -160,6 +160,73 @@ func TestStreamGzipDecode(t *testing.T) {
 	require.Equal(t, []byte("howdy"), b[:n])
 }
 
+func TestCodecEncodeDecode(t *testing.T) {
+	for _, encoding := range []string{"lz4", "snappy"} {
+		t.Run(encoding, func(t *testing.T) {
+			enc, err := NewContentEncoder(encoding)
+			require.NoError(t, err)
+			dec, err := NewContentDecoder(encoding, WithMaxDecompressionSize(maxDecompressionSize))
+			require.NoError(t, err)
+
+			payload, err := enc.Encode([]byte("howdy"))
+			require.NoError(t, err)
+
+			actual, err := dec.Decode(payload)
+			require.NoError(t, err)
+			require.Equal(t, "howdy", string(actual))
+
+			// The auto decoder picks the codec by the given encoding
+			auto, err := NewContentDecoder("auto")
+			require.NoError(t, err)
+			auto.SetEncoding(encoding)
+			actual, err = auto.Decode(payload)
+			require.NoError(t, err)
+			require.Equal(t, "howdy", string(actual))
+
+			// Stream decoding
+			r, err := NewStreamContentDecoder(encoding, bytes.NewReader(payload))
+			require.NoError(t, err)
+			actual, err = io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, "howdy", string(actual))
+		})
+	}
+}
+
+func TestStreamDecoderReuse(t *testing.T) {
+	for _, encoding := range []string{"identity", "gzip", "zlib", "zstd", "lz4", "snappy"} {
+		t.Run(encoding, func(t *testing.T) {
+			enc, err := NewContentEncoder(encoding)
+			require.NoError(t, err)
+			dec, err := NewStreamDecoder(encoding)
+			require.NoError(t, err)
+
+			for _, data := range []string{"howdy", "doody"} {
+				payload, err := enc.Encode([]byte(data))
+				require.NoError(t, err)
+
+				r, err := dec.NewReader(bytes.NewReader(payload))
+				require.NoError(t, err)
+				actual, err := io.ReadAll(r)
+				require.NoError(t, err)
+				require.NoError(t, r.Close())
+				require.Equal(t, data, string(actual))
+			}
+		})
+	}
+}
+
+func TestUnknownContentEncoding(t *testing.T) {
+	_, err := NewContentEncoder("brotli")
+	require.ErrorContains(t, err, "invalid value for content_encoding")
+
+	_, err = NewContentDecoder("brotli")
+	require.ErrorContains(t, err, "invalid value for content_encoding")
+
+	_, err = NewStreamDecoder("brotli")
+	require.ErrorContains(t, err, "invalid value for content_encoding")
+}
+
 func TestCompressionLevel(t *testing.T) {
 	tests := []struct {
 		algorithm   string
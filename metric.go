@@ -109,6 +109,24 @@ type Metric interface {
 	// SetType sets the value-type of the Metric.
 	SetType(t ValueType)
 
+	// Metadata returns the metadata entries as a map.  Metadata are hints
+	// attached to the metric, e.g. routing keys or trace IDs, which are
+	// neither part of the series identity nor serialized by outputs.  The
+	// returned value should not be modified, use the SetMetadata or
+	// RemoveMetadata methods instead.
+	Metadata() map[string]string
+
+	// GetMetadata returns the value of a metadata entry and a boolean to
+	// indicate if it was set.
+	GetMetadata(key string) (string, bool)
+
+	// SetMetadata sets the metadata entry on the Metric.  If the Metric
+	// already has the entry set then the current value is replaced.
+	SetMetadata(key, value string)
+
+	// RemoveMetadata removes the metadata entry if it is set.
+	RemoveMetadata(key string)
+
 	// HashID returns a unique identifier for the series.
 	HashID() uint64
 
@@ -145,6 +163,17 @@ type TemplateMetric interface {
 	String() string
 }
 
+// SourceMetric is implemented by metrics recording the plugin instance that
+// created them. Use metric.Source to get the source of any metric.
+type SourceMetric interface {
+	// Source returns the instance identifier of the plugin that created the
+	// metric, e.g. "inputs.cpu#0", or an empty string if unknown.
+	Source() string
+
+	// SetSource sets the instance identifier of the plugin creating the metric.
+	SetSource(source string)
+}
+
 type UnwrappableMetric interface {
 	// Unwrap allows to access the underlying raw metric if an implementation
 	// wraps it in the first place.
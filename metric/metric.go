@@ -3,6 +3,7 @@ package metric
 import (
 	"fmt"
 	"hash/fnv"
+	"maps"
 	"slices"
 	"sort"
 	"strings"
@@ -17,7 +18,9 @@ type metric struct {
 	MetricFields []*telegraf.Field
 	MetricTime   time.Time
 
-	MetricType telegraf.ValueType
+	MetricType     telegraf.ValueType
+	MetricSource   string
+	MetricMetadata map[string]string
 }
 
 func New(
@@ -75,6 +78,11 @@ func FromMetric(other telegraf.Metric) telegraf.Metric {
 		MetricFields: make([]*telegraf.Field, len(other.FieldList())),
 		MetricTime:   other.Time(),
 		MetricType:   other.Type(),
+		MetricSource: Source(other),
+	}
+
+	if md := other.Metadata(); len(md) > 0 {
+		m.MetricMetadata = maps.Clone(md)
 	}
 
 	for i, tag := range other.TagList() {
@@ -248,6 +256,46 @@ func (m *metric) SetType(t telegraf.ValueType) {
 	m.MetricType = t
 }
 
+// Source returns the instance identifier of the plugin that created the given
+// metric or an empty string if unknown or not recorded by the metric.
+func Source(m telegraf.Metric) string {
+	if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	if sm, ok := m.(telegraf.SourceMetric); ok {
+		return sm.Source()
+	}
+	return ""
+}
+
+func (m *metric) Source() string {
+	return m.MetricSource
+}
+
+func (m *metric) SetSource(source string) {
+	m.MetricSource = source
+}
+
+func (m *metric) Metadata() map[string]string {
+	return m.MetricMetadata
+}
+
+func (m *metric) GetMetadata(key string) (string, bool) {
+	v, found := m.MetricMetadata[key]
+	return v, found
+}
+
+func (m *metric) SetMetadata(key, value string) {
+	if m.MetricMetadata == nil {
+		m.MetricMetadata = make(map[string]string)
+	}
+	m.MetricMetadata[key] = value
+}
+
+func (m *metric) RemoveMetadata(key string) {
+	delete(m.MetricMetadata, key)
+}
+
 func (m *metric) Copy() telegraf.Metric {
 	m2 := &metric{
 		MetricName:   m.MetricName,
@@ -255,6 +303,11 @@ func (m *metric) Copy() telegraf.Metric {
 		MetricFields: make([]*telegraf.Field, len(m.MetricFields)),
 		MetricTime:   m.MetricTime,
 		MetricType:   m.MetricType,
+		MetricSource: m.MetricSource,
+	}
+
+	if len(m.MetricMetadata) > 0 {
+		m2.MetricMetadata = maps.Clone(m.MetricMetadata)
 	}
 
 	for i, tag := range m.MetricTags {
@@ -326,3 +326,54 @@ func TestValueType(t *testing.T) {
 
 	require.Equal(t, telegraf.Gauge, m.Type())
 }
+
+func TestMetadata(t *testing.T) {
+	m := baseMetric()
+	require.Empty(t, m.Metadata())
+
+	m.SetMetadata("trace_id", "abc")
+	m.SetMetadata("route", "foo")
+	m.SetMetadata("route", "bar")
+	v, found := m.GetMetadata("route")
+	require.True(t, found)
+	require.Equal(t, "bar", v)
+	require.Equal(t, map[string]string{"trace_id": "abc", "route": "bar"}, m.Metadata())
+
+	m.RemoveMetadata("trace_id")
+	m.RemoveMetadata("missing")
+	_, found = m.GetMetadata("trace_id")
+	require.False(t, found)
+
+	// Metadata must not change the series identity
+	require.Equal(t, baseMetric().HashID(), m.HashID())
+}
+
+func TestMetadataCopy(t *testing.T) {
+	m := baseMetric()
+	m.SetMetadata("route", "foo")
+
+	for _, c := range []telegraf.Metric{m.Copy(), FromMetric(m)} {
+		v, found := c.GetMetadata("route")
+		require.True(t, found)
+		require.Equal(t, "foo", v)
+
+		// Copies must not share the metadata
+		c.SetMetadata("route", "bar")
+		v, _ = m.GetMetadata("route")
+		require.Equal(t, "foo", v)
+	}
+}
+
+func TestSource(t *testing.T) {
+	m := baseMetric()
+	require.Empty(t, Source(m))
+
+	m.(telegraf.SourceMetric).SetSource("inputs.cpu#0")
+	require.Equal(t, "inputs.cpu#0", Source(m))
+	require.Equal(t, "inputs.cpu#0", Source(m.Copy()))
+	require.Equal(t, "inputs.cpu#0", Source(FromMetric(m)))
+
+	// The source of tracking metrics is taken from the wrapped metric
+	tm, _ := WithTracking(m, func(telegraf.DeliveryInfo) {})
+	require.Equal(t, "inputs.cpu#0", Source(tm))
+}
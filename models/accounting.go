@@ -0,0 +1,89 @@
+package models
+
+import (
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const allocBytesMetric = "/gc/heap/allocs:bytes"
+
+// resourceSampler estimates the resources used by plugin operations by
+// measuring the wall time, the CPU time and the heap allocations of the
+// process for every n-th operation. As other plugins might run concurrently,
+// the CPU time and allocations are upper bounds for the resources used by the
+// plugin itself.
+type resourceSampler struct {
+	rate  uint64
+	count atomic.Uint64
+
+	wallTime   selfstat.Stat
+	cpuTime    selfstat.Stat
+	allocBytes selfstat.Stat
+}
+
+// resourceSample holds the state at the start of a sampled operation
+type resourceSample struct {
+	sampler *resourceSampler
+	start   time.Time
+	cpu     time.Duration
+	alloc   []metrics.Sample
+}
+
+// newResourceSampler registers the statistics for sampling every rate-th
+// operation. The wall time is only recorded if a field name is given.
+func newResourceSampler(measurement, wallTimeField string, rate int, tags map[string]string) *resourceSampler {
+	if rate < 1 {
+		return nil
+	}
+
+	s := &resourceSampler{
+		rate:       uint64(rate),
+		cpuTime:    selfstat.RegisterTiming(measurement, "cpu_time_ns", tags),
+		allocBytes: selfstat.RegisterTiming(measurement, "alloc_bytes", tags),
+	}
+	if wallTimeField != "" {
+		s.wallTime = selfstat.RegisterTiming(measurement, wallTimeField, tags)
+	}
+	return s
+}
+
+// start returns a sample if the current operation should be measured or nil
+// otherwise. The function is safe to call on a nil sampler.
+func (s *resourceSampler) start() *resourceSample {
+	if s == nil || (s.count.Add(1)-1)%s.rate != 0 {
+		return nil
+	}
+
+	sample := &resourceSample{
+		sampler: s,
+		alloc:   []metrics.Sample{{Name: allocBytesMetric}},
+	}
+	metrics.Read(sample.alloc)
+	sample.cpu = processCPUTime()
+	sample.start = time.Now()
+	return sample
+}
+
+// stop records the resources used since starting the sample. The function
+// is safe to call on a nil sample.
+func (sample *resourceSample) stop() {
+	if sample == nil {
+		return
+	}
+	elapsed := time.Since(sample.start)
+	cpu := processCPUTime() - sample.cpu
+	allocStart := sample.alloc[0].Value
+	metrics.Read(sample.alloc)
+
+	s := sample.sampler
+	if s.wallTime != nil {
+		s.wallTime.Incr(elapsed.Nanoseconds())
+	}
+	s.cpuTime.Incr(cpu.Nanoseconds())
+	if allocStart.Kind() == metrics.KindUint64 && sample.alloc[0].Value.Kind() == metrics.KindUint64 {
+		s.allocBytes.Incr(int64(sample.alloc[0].Value.Uint64() - allocStart.Uint64()))
+	}
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package models
+
+import (
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// processCPUTime returns the user and kernel CPU time consumed by the process
+func processCPUTime() time.Duration {
+	var creation, exit, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(windows.CurrentProcess(), &creation, &exit, &kernel, &user); err != nil {
+		return 0
+	}
+	// Filetime values are in units of 100 nanoseconds
+	ticks := int64(kernel.HighDateTime)<<32 | int64(kernel.LowDateTime)
+	ticks += int64(user.HighDateTime)<<32 | int64(user.LowDateTime)
+	return time.Duration(ticks * 100)
+}
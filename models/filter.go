@@ -143,10 +143,11 @@ func (f *Filter) Select(metric telegraf.Metric) (bool, error) {
 
 	if f.metricFilter != nil {
 		result, _, err := f.metricFilter.Eval(map[string]interface{}{
-			"name":   metric.Name(),
-			"tags":   metric.Tags(),
-			"fields": metric.Fields(),
-			"time":   metric.Time(),
+			"name":     metric.Name(),
+			"tags":     metric.Tags(),
+			"fields":   metric.Fields(),
+			"time":     metric.Time(),
+			"metadata": metric.Metadata(),
 		})
 		if err != nil {
 			return true, err
@@ -252,6 +253,7 @@ func (f *Filter) compileMetricFilter() error {
 			decls.NewVariable("tags", types.NewMapType(types.StringType, types.StringType)),
 			decls.NewVariable("fields", types.NewMapType(types.StringType, types.DynType)),
 			decls.NewVariable("time", types.TimestampType),
+			decls.NewVariable("metadata", types.NewMapType(types.StringType, types.StringType)),
 		),
 		cel.Function(
 			"now",
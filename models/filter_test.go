@@ -645,6 +645,34 @@ func TestFilterMetricPass(t *testing.T) {
 	}
 }
 
+func TestFilterMetricPassMetadata(t *testing.T) {
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+
+	f := Filter{MetricPass: `metadata.route == "primary"`}
+	require.NoError(t, f.Compile())
+
+	// Accessing missing metadata fails and lets the metric pass
+	selected, err := f.Select(m)
+	require.Error(t, err)
+	require.True(t, selected)
+
+	m.SetMetadata("route", "secondary")
+	selected, err = f.Select(m)
+	require.NoError(t, err)
+	require.False(t, selected)
+
+	m.SetMetadata("route", "primary")
+	selected, err = f.Select(m)
+	require.NoError(t, err)
+	require.True(t, selected)
+
+	f = Filter{MetricPass: `"route" in metadata`}
+	require.NoError(t, f.Compile())
+	selected, err = f.Select(metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)))
+	require.NoError(t, err)
+	require.False(t, selected)
+}
+
 func BenchmarkFilter(b *testing.B) {
 	tests := []struct {
 		name   string
@@ -77,6 +77,8 @@ type AggregatorConfig struct {
 	Source       string
 	Alias        string
 	ID           string
+	InstanceID   string
+	Pipeline     string
 	DropOriginal bool
 	Period       time.Duration
 	Delay        time.Duration
@@ -134,6 +136,10 @@ func (r *RunningAggregator) MakeMetric(telegrafMetric telegraf.Metric) telegraf.
 		r.Config.Tags,
 		nil)
 
+	if sm, ok := m.(telegraf.SourceMetric); ok && r.Config.InstanceID != "" {
+		sm.SetSource(r.Config.InstanceID)
+	}
+
 	r.MetricsPushed.Incr(1)
 
 	return m
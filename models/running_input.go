@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -30,21 +31,23 @@ type RunningInput struct {
 	gatherStart time.Time
 	gatherEnd   time.Time
 
+	// Completion time of the last gather and the last successful gather in
+	// nanoseconds since epoch, zero if none happened yet
+	lastGather        atomic.Int64
+	lastGatherSuccess atomic.Int64
+
 	MetricsGathered selfstat.Stat
 	GatherTime      selfstat.Stat
 	GatherTimeouts  selfstat.Stat
 	GatherErrors    selfstat.Stat
 	StartupErrors   selfstat.Stat
+	GatherInterval  selfstat.Stat
+
+	resources *resourceSampler
 }
 
 func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
-	tags := map[string]string{
-		"_id":   config.ID,
-		"input": config.Name,
-	}
-	if config.Alias != "" {
-		tags["alias"] = config.Alias
-	}
+	tags := inputStatsTags(config)
 
 	errorLogRegister := selfstat.Register("gather", "errors", tags)
 	logger := logging.New("inputs", config.Name, config.Alias)
@@ -85,8 +88,21 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 			"startup_errors",
 			tags,
 		),
-		log: logger,
+		resources: newResourceSampler("gather", "", config.AccountingSampleRate, tags),
+		log:       logger,
+	}
+}
+
+// inputStatsTags returns the tags of the internal statistics of the input
+func inputStatsTags(config *InputConfig) map[string]string {
+	tags := map[string]string{
+		"_id":   config.ID,
+		"input": config.Name,
 	}
+	if config.Alias != "" {
+		tags["alias"] = config.Alias
+	}
+	return tags
 }
 
 // InputConfig is the common config for all inputs.
@@ -95,19 +111,24 @@ type InputConfig struct {
 	Source               string
 	Alias                string
 	ID                   string
+	InstanceID           string
+	Pipeline             string
 	Interval             time.Duration
 	CollectionJitter     time.Duration
 	CollectionJitterSet  bool
 	CollectionOffset     time.Duration
+	MaxInterval          time.Duration
 	Precision            time.Duration
 	TimeSource           string
 	StartupErrorBehavior string
 	LogLevel             string
+	AccountingSampleRate int
 
 	NameOverride            string
 	MeasurementPrefix       string
 	MeasurementSuffix       string
 	Tags                    map[string]string
+	Metadata                map[string]string
 	Filter                  Filter
 	AlwaysIncludeLocalTags  bool
 	AlwaysIncludeGlobalTags bool
@@ -225,6 +246,17 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 		return nil
 	}
 
+	if sm, ok := metric.(telegraf.SourceMetric); ok && r.Config.InstanceID != "" {
+		sm.SetSource(r.Config.InstanceID)
+	}
+
+	// Apply plugin-wide metadata without overriding entries set by the plugin
+	for k, v := range r.Config.Metadata {
+		if _, ok := metric.GetMetadata(k); !ok {
+			metric.SetMetadata(k, v)
+		}
+	}
+
 	if r.Config.AlwaysIncludeLocalTags || r.Config.AlwaysIncludeGlobalTags {
 		var local, global map[string]string
 		if r.Config.AlwaysIncludeLocalTags {
@@ -266,20 +298,36 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	sample := r.resources.start()
 	r.gatherStart = time.Now()
 	err := r.Input.Gather(acc)
 	r.gatherEnd = time.Now()
+	sample.stop()
 
 	r.GatherTime.Incr(r.gatherEnd.Sub(r.gatherStart).Nanoseconds())
+	r.lastGather.Store(r.gatherEnd.UnixNano())
 
 	if err != nil {
 		r.GatherErrors.Incr(1)
 		GlobalGatherErrors.Incr(1)
 		return err
 	}
+	r.lastGatherSuccess.Store(r.gatherEnd.UnixNano())
 	return nil
 }
 
+// LastGather returns the completion time of the last gather and of the last
+// gather without error. Zero times are returned if no such gather happened.
+func (r *RunningInput) LastGather() (last, success time.Time) {
+	if ts := r.lastGather.Load(); ts > 0 {
+		last = time.Unix(0, ts)
+	}
+	if ts := r.lastGatherSuccess.Load(); ts > 0 {
+		success = time.Unix(0, ts)
+	}
+	return last, success
+}
+
 func (r *RunningInput) SetDefaultTags(tags map[string]string) {
 	r.defaultTags = tags
 }
@@ -288,6 +336,16 @@ func (r *RunningInput) Log() telegraf.Logger {
 	return r.log
 }
 
+// SetGatherInterval reports the effective collection interval of the input
+// in adaptive scheduling mode. The statistic is only registered on first use
+// to not report it for inputs using a fixed interval.
+func (r *RunningInput) SetGatherInterval(interval time.Duration) {
+	if r.GatherInterval == nil {
+		r.GatherInterval = selfstat.Register("gather", "gather_interval_ns", inputStatsTags(r.Config))
+	}
+	r.GatherInterval.Set(interval.Nanoseconds())
+}
+
 func (r *RunningInput) IncrGatherTimeouts() {
 	GlobalGatherTimeouts.Incr(1)
 	r.GatherTimeouts.Incr(1)
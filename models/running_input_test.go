@@ -630,3 +630,27 @@ func (m *mockInput) Probe() error {
 func (m *mockInput) Gather(telegraf.Accumulator) error {
 	return m.gatherReturn
 }
+
+func TestRunningInputMakeMetricWithMetadata(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name: "TestRunningInput",
+		Metadata: map[string]string{
+			"route":  "primary",
+			"tenant": "default",
+		},
+	})
+
+	m := metric.New("RITest",
+		map[string]string{},
+		map[string]interface{}{
+			"value": int64(101),
+		},
+		time.Now(),
+		telegraf.Untyped)
+	m.SetMetadata("tenant", "plugin")
+	actual := ri.MakeMetric(m)
+
+	// Metadata set by the plugin take precedence
+	require.Equal(t, map[string]string{"route": "primary", "tenant": "plugin"}, actual.Metadata())
+	require.Empty(t, actual.TagList())
+}
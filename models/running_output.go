@@ -29,6 +29,8 @@ type OutputConfig struct {
 	Source               string
 	Alias                string
 	ID                   string
+	InstanceID           string
+	Pipeline             string
 	StartupErrorBehavior string
 	Filter               Filter
 
@@ -45,6 +47,10 @@ type OutputConfig struct {
 	BufferDirectory string
 	BufferDiskSync  bool
 
+	FallbackFor   string
+	FallbackAfter int
+	FallbackTag   string
+
 	LogLevel string
 }
 
@@ -54,6 +60,8 @@ type RunningOutput struct {
 	droppedMetrics  atomic.Int64
 	writeInFlight   atomic.Bool
 	lastWriteFailed atomic.Bool
+	connected       atomic.Bool
+	failedWrites    atomic.Int64
 
 	Output            telegraf.Output
 	Config            *OutputConfig
@@ -64,12 +72,18 @@ type RunningOutput struct {
 	WriteTime       selfstat.Stat
 	WriteErrors     selfstat.Stat
 	StartupErrors   selfstat.Stat
+	MetricsDiverted selfstat.Stat
 
 	BatchReady chan time.Time
 
 	buffer Buffer
 	log    telegraf.Logger
 
+	// Output receiving the batches failing to be written and the output
+	// this one is the fallback for
+	fallback *RunningOutput
+	primary  *RunningOutput
+
 	started bool
 	retries uint64
 
@@ -141,6 +155,11 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 			"startup_errors",
 			tags,
 		),
+		MetricsDiverted: selfstat.Register(
+			"write",
+			"metrics_diverted",
+			tags,
+		),
 		log: logger,
 	}
 
@@ -170,6 +189,10 @@ func (r *RunningOutput) Init() error {
 		return fmt.Errorf("invalid 'startup_error_behavior' setting %q", r.Config.StartupErrorBehavior)
 	}
 
+	if r.Config.FallbackFor != "" && r.Config.FallbackAfter < 1 {
+		return fmt.Errorf("invalid 'fallback_after' setting %d, must be at least one", r.Config.FallbackAfter)
+	}
+
 	if p, ok := r.Output.(telegraf.Initializer); ok {
 		err := p.Init()
 		if err != nil {
@@ -184,6 +207,7 @@ func (r *RunningOutput) Connect() error {
 	err := r.Output.Connect()
 	if err == nil {
 		r.started = true
+		r.connected.Store(true)
 		return nil
 	}
 	r.StartupErrors.Incr(1)
@@ -211,6 +235,7 @@ func (r *RunningOutput) Connect() error {
 
 // Close closes the output
 func (r *RunningOutput) Close() {
+	r.connected.Store(false)
 	if err := r.Output.Close(); err != nil {
 		r.log.Errorf("Error closing output: %v", err)
 	}
@@ -307,11 +332,13 @@ func (r *RunningOutput) Write() error {
 			var serr *internal.StartupError
 			if !errors.As(err, &serr) || !serr.Retry || !serr.Partial {
 				r.StartupErrors.Incr(1)
+				r.divertBuffer()
 				return internal.ErrNotConnected
 			}
 			r.log.Debugf("Partially connected after %d attempts", r.retries)
 		} else {
 			r.started = true
+			r.connected.Store(true)
 			r.log.Debugf("Successfully connected after %d attempts", r.retries)
 		}
 	}
@@ -352,9 +379,11 @@ func (r *RunningOutput) WriteBatch() error {
 		r.retries++
 		if err := r.Output.Connect(); err != nil {
 			r.StartupErrors.Incr(1)
+			r.divertBuffer()
 			return internal.ErrNotConnected
 		}
 		r.started = true
+		r.connected.Store(true)
 		r.log.Debugf("Successfully connected after %d attempts", r.retries)
 	}
 
@@ -376,11 +405,16 @@ func (r *RunningOutput) doTransaction() error {
 	}
 	err := r.writeMetrics(tx.Batch)
 	r.updateTransaction(tx, err)
+	diverted := r.divertTransaction(tx, err)
 	r.buffer.EndTransaction(tx)
 
 	if err != nil {
 		r.WriteErrors.Incr(1)
 		GlobalWriteErrors.Incr(1)
+		if diverted {
+			r.log.Warnf("Writing batch failed: %v; diverted %d metrics to %s", err, len(tx.Batch), r.fallback.LogName())
+			return nil
+		}
 		return err
 	}
 
@@ -429,6 +463,112 @@ func (r *RunningOutput) updateTransaction(tx *Transaction, err error) {
 	tx.Reject = writeErr.MetricsReject
 }
 
+// SetFallback sets the output receiving the batches this output fails to
+// write after the number of consecutive failures configured for the fallback.
+// Passing nil removes the current fallback.
+func (r *RunningOutput) SetFallback(fallback *RunningOutput) {
+	if r.fallback != nil {
+		r.fallback.primary = nil
+	}
+	r.fallback = fallback
+	if fallback != nil {
+		fallback.primary = r
+	}
+}
+
+// Fallback returns the fallback output or nil if none is set
+func (r *RunningOutput) Fallback() *RunningOutput {
+	return r.fallback
+}
+
+// Primary returns the output this output is the fallback for or nil if the
+// output is not a fallback. Fallback outputs only receive the metrics their
+// primary failed to write.
+func (r *RunningOutput) Primary() *RunningOutput {
+	return r.primary
+}
+
+// FallbackDepth returns the number of primary outputs in front of the output
+// in the fallback chain, i.e. zero for outputs not being a fallback
+func (r *RunningOutput) FallbackDepth() int {
+	var depth int
+	for p := r.primary; p != nil; p = p.primary {
+		depth++
+	}
+	return depth
+}
+
+// divertTransaction hands the metrics of a failed write over to the fallback
+// output if the output failed often enough in a row. Returns true if the
+// metrics were diverted.
+func (r *RunningOutput) divertTransaction(tx *Transaction, err error) bool {
+	if r.fallback == nil {
+		return false
+	}
+
+	// Only divert batches not written at all, partially written batches
+	// indicate a working output and the kept metrics are retried
+	var writeErr *internal.PartialWriteError
+	if err == nil || errors.As(err, &writeErr) {
+		if err == nil || len(writeErr.MetricsAccept) > 0 {
+			r.failedWrites.Store(0)
+		}
+		return false
+	}
+
+	if r.failedWrites.Add(1) < int64(r.fallback.Config.FallbackAfter) {
+		return false
+	}
+
+	r.fallback.addDiverted(tx.Batch, r.Config.InstanceID)
+	r.MetricsDiverted.Incr(int64(len(tx.Batch)))
+	tx.AcceptAll()
+
+	// Allow the remaining metrics to be diverted without waiting for the
+	// next flush interval
+	r.lastWriteFailed.Store(false)
+
+	return true
+}
+
+// divertBuffer hands all buffered metrics over to the fallback output if the
+// output could not be connected often enough in a row.
+func (r *RunningOutput) divertBuffer() {
+	if r.fallback == nil || r.failedWrites.Add(1) < int64(r.fallback.Config.FallbackAfter) {
+		return
+	}
+
+	var count int
+	for r.buffer.Len() > 0 {
+		tx := r.buffer.BeginTransaction(r.MetricBatchSize)
+		if len(tx.Batch) == 0 {
+			break
+		}
+		r.fallback.addDiverted(tx.Batch, r.Config.InstanceID)
+		tx.AcceptAll()
+		r.buffer.EndTransaction(tx)
+		count += len(tx.Batch)
+	}
+	if count > 0 {
+		r.MetricsDiverted.Incr(int64(count))
+		r.log.Warnf("Output not connected; diverted %d metrics to %s", count, r.fallback.LogName())
+	}
+}
+
+// addDiverted adds copies of the metrics the given primary output failed to
+// write. The metrics are marked with the instance ID of the primary output
+// for a later replay.
+func (r *RunningOutput) addDiverted(metrics []telegraf.Metric, primary string) {
+	for _, m := range metrics {
+		m = m.Copy()
+		m.SetMetadata("fallback_for", primary)
+		if r.Config.FallbackTag != "" {
+			m.AddTag(r.Config.FallbackTag, primary)
+		}
+		r.AddMetricNoCopy(m)
+	}
+}
+
 func (r *RunningOutput) LogBufferStatus() {
 	nBuffer := r.buffer.Len()
 	if r.Config.BufferStrategy == "disk_write_through" {
@@ -445,3 +585,15 @@ func (r *RunningOutput) Log() telegraf.Logger {
 func (r *RunningOutput) BufferLength() int {
 	return r.buffer.Len()
 }
+
+// Connected returns true if the output successfully connected and was not
+// closed since
+func (r *RunningOutput) Connected() bool {
+	return r.connected.Load()
+}
+
+// LastWriteFailed returns true if the last write did not succeed for any
+// metric of the batch
+func (r *RunningOutput) LastWriteFailed() bool {
+	return r.lastWriteFailed.Load()
+}
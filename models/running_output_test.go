@@ -1338,6 +1338,99 @@ func TestRunningOutputStatisticsWriteErrorsCount(t *testing.T) {
 }
 
 // Benchmark adding metrics.
+func TestRunningOutputFallback(t *testing.T) {
+	m := &mockOutput{batchAcceptSize: -1}
+	ro, err := NewRunningOutput(m, &OutputConfig{Name: "primary", InstanceID: "outputs.primary#0"}, 4, 12)
+	require.NoError(t, err)
+
+	fm := &mockOutput{}
+	fallback, err := NewRunningOutput(fm, &OutputConfig{
+		Name:          "fallback",
+		FallbackFor:   "outputs.primary",
+		FallbackAfter: 2,
+		FallbackTag:   "replay",
+	}, 4, 12)
+	require.NoError(t, err)
+	require.NoError(t, fallback.Init())
+	ro.SetFallback(fallback)
+
+	for _, mt := range first5 {
+		ro.AddMetric(mt)
+	}
+
+	// The first failure keeps the metrics for the next write
+	require.Error(t, ro.Write())
+	require.Equal(t, 5, ro.BufferLength())
+	require.Zero(t, fallback.BufferLength())
+
+	// Afterwards the failing batches are diverted to the fallback
+	require.NoError(t, ro.Write())
+	require.Zero(t, ro.BufferLength())
+	require.NoError(t, fallback.Write())
+	require.Empty(t, m.Metrics())
+	require.Len(t, fm.Metrics(), 5)
+	for _, mt := range fm.Metrics() {
+		primary, found := mt.GetMetadata("fallback_for")
+		require.True(t, found)
+		require.Equal(t, "outputs.primary#0", primary)
+		require.Equal(t, "outputs.primary#0", mt.Tags()["replay"])
+	}
+
+	// A successful write resets the failure count
+	m.batchAcceptSize = 0
+	for _, mt := range next5 {
+		ro.AddMetric(mt)
+	}
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 5)
+
+	m.batchAcceptSize = -1
+	ro.AddMetric(first5[0])
+	require.Error(t, ro.Write())
+	require.Equal(t, 1, ro.BufferLength())
+}
+
+func TestRunningOutputFallbackNotConnected(t *testing.T) {
+	m := &mockOutput{
+		startupErrorCount: -1,
+		startupError:      &internal.StartupError{Err: errors.New("connection refused"), Retry: true},
+	}
+	ro, err := NewRunningOutput(m, &OutputConfig{
+		Name:                 "primary",
+		InstanceID:           "outputs.primary#0",
+		StartupErrorBehavior: "retry",
+	}, 4, 12)
+	require.NoError(t, err)
+	require.NoError(t, ro.Init())
+	require.NoError(t, ro.Connect())
+
+	fm := &mockOutput{}
+	fallback, err := NewRunningOutput(fm, &OutputConfig{
+		Name:          "fallback",
+		FallbackFor:   "outputs.primary",
+		FallbackAfter: 1,
+	}, 4, 12)
+	require.NoError(t, err)
+	ro.SetFallback(fallback)
+
+	for _, mt := range first5 {
+		ro.AddMetric(mt)
+	}
+	require.ErrorIs(t, ro.Write(), internal.ErrNotConnected)
+	require.Zero(t, ro.BufferLength())
+	require.NoError(t, fallback.Write())
+	require.Len(t, fm.Metrics(), 5)
+}
+
+func TestRunningOutputFallbackInvalid(t *testing.T) {
+	ro, err := NewRunningOutput(&mockOutput{}, &OutputConfig{
+		Name:        "fallback",
+		FallbackFor: "outputs.primary",
+	}, 4, 12)
+	require.NoError(t, err)
+	require.ErrorContains(t, ro.Init(), "invalid 'fallback_after' setting")
+}
+
 func BenchmarkRunningOutputAddWrite(b *testing.B) {
 	conf := &OutputConfig{
 		Filter: Filter{},
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"io"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -14,7 +15,11 @@ type RunningParser struct {
 	log    telegraf.Logger
 
 	MetricsParsed selfstat.Stat
+	BytesParsed   selfstat.Stat
 	ParseTime     selfstat.Stat
+
+	// Callback for parsers not supporting size accounting themselves
+	sizeCallback func([]telegraf.Metric, int)
 }
 
 func NewRunningParser(parser telegraf.Parser, config *ParserConfig) *RunningParser {
@@ -42,6 +47,11 @@ func NewRunningParser(parser telegraf.Parser, config *ParserConfig) *RunningPars
 			"metrics_parsed",
 			tags,
 		),
+		BytesParsed: selfstat.Register(
+			"parser",
+			"bytes_parsed",
+			tags,
+		),
 		ParseTime: selfstat.Register(
 			"parser",
 			"parse_time_ns",
@@ -80,6 +90,34 @@ func (r *RunningParser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	elapsed := time.Since(start)
 	r.ParseTime.Incr(elapsed.Nanoseconds())
 	r.MetricsParsed.Incr(int64(len(m)))
+	r.BytesParsed.Incr(int64(len(buf)))
+	r.reportSize(m, len(buf))
+
+	return m, err
+}
+
+// ParseReaderAt parses the first size bytes of the given reader. If the
+// underlying parser does not implement telegraf.ReaderAtParser the data is
+// read into memory and parsed at once.
+func (r *RunningParser) ParseReaderAt(reader io.ReaderAt, size int64) ([]telegraf.Metric, error) {
+	start := time.Now()
+	var m []telegraf.Metric
+	var err error
+	if p, ok := r.Parser.(telegraf.ReaderAtParser); ok {
+		m, err = p.ParseReaderAt(reader, size)
+	} else {
+		var buf []byte
+		buf, err = io.ReadAll(io.NewSectionReader(reader, 0, size))
+		if err != nil {
+			return nil, err
+		}
+		m, err = r.Parser.Parse(buf)
+	}
+	elapsed := time.Since(start)
+	r.ParseTime.Incr(elapsed.Nanoseconds())
+	r.MetricsParsed.Incr(int64(len(m)))
+	r.BytesParsed.Incr(size)
+	r.reportSize(m, int(size))
 
 	return m, err
 }
@@ -90,6 +128,10 @@ func (r *RunningParser) ParseLine(line string) (telegraf.Metric, error) {
 	elapsed := time.Since(start)
 	r.ParseTime.Incr(elapsed.Nanoseconds())
 	r.MetricsParsed.Incr(1)
+	r.BytesParsed.Incr(int64(len(line)))
+	if m != nil {
+		r.reportSize([]telegraf.Metric{m}, len(line))
+	}
 
 	return m, err
 }
@@ -98,6 +140,24 @@ func (r *RunningParser) SetDefaultTags(tags map[string]string) {
 	r.Parser.SetDefaultTags(tags)
 }
 
+// SetSizeCallback sets the function called with the parsed metrics and the
+// number of input bytes they originate from. If the underlying parser does not
+// implement telegraf.SizeAccountingParser, the function is called once per
+// parsed buffer with all metrics and the size of the buffer.
+func (r *RunningParser) SetSizeCallback(fn func([]telegraf.Metric, int)) {
+	if p, ok := r.Parser.(telegraf.SizeAccountingParser); ok {
+		p.SetSizeCallback(fn)
+		return
+	}
+	r.sizeCallback = fn
+}
+
+func (r *RunningParser) reportSize(metrics []telegraf.Metric, size int) {
+	if r.sizeCallback != nil && len(metrics) > 0 {
+		r.sizeCallback(metrics, size)
+	}
+}
+
 func (r *RunningParser) Log() telegraf.Logger {
 	return r.log
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	logging "github.com/influxdata/telegraf/logger"
@@ -13,6 +14,8 @@ type RunningProcessor struct {
 	log       telegraf.Logger
 	Processor telegraf.StreamingProcessor
 	Config    *ProcessorConfig
+
+	resources *resourceSampler
 }
 
 type RunningProcessors []*RunningProcessor
@@ -23,13 +26,19 @@ func (rp RunningProcessors) Less(i, j int) bool { return rp[i].Config.Order < rp
 
 // ProcessorConfig containing a name and filter
 type ProcessorConfig struct {
-	Name     string
-	Source   string
-	Alias    string
-	ID       string
-	Order    int64
-	Filter   Filter
-	LogLevel string
+	Name       string
+	Source     string
+	Alias      string
+	ID         string
+	InstanceID string
+	Pipeline   string
+	Order      int64
+	Filter     Filter
+	LogLevel   string
+
+	AccountingSampleRate int
+
+	FlushInterval time.Duration
 }
 
 func NewRunningProcessor(processor telegraf.StreamingProcessor, config *ProcessorConfig) *RunningProcessor {
@@ -55,6 +64,7 @@ func NewRunningProcessor(processor telegraf.StreamingProcessor, config *Processo
 	return &RunningProcessor{
 		Processor: processor,
 		Config:    config,
+		resources: newResourceSampler("process", "process_time_ns", config.AccountingSampleRate, tags),
 		log:       logger,
 	}
 }
@@ -113,9 +123,25 @@ func (rp *RunningProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) err
 		return nil
 	}
 
+	sample := rp.resources.start()
+	defer sample.stop()
 	return rp.Processor.Add(m, acc)
 }
 
+// IsFlushing returns true if the processor wants to be flushed periodically.
+func (rp *RunningProcessor) IsFlushing() bool {
+	_, ok := rp.Processor.(telegraf.FlushingProcessor)
+	return ok
+}
+
+// Flush triggers emitting of timer-based metrics for flushing processors.
+func (rp *RunningProcessor) Flush(acc telegraf.Accumulator) error {
+	if p, ok := rp.Processor.(telegraf.FlushingProcessor); ok {
+		return p.Flush(acc)
+	}
+	return nil
+}
+
 func (rp *RunningProcessor) Stop() {
 	rp.Processor.Stop()
 }
@@ -2,6 +2,7 @@ package models_test
 
 import (
 	"sort"
+	"strconv"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -207,6 +209,82 @@ func TestRunningProcessorOrder(t *testing.T) {
 		procs)
 }
 
+func TestRunningProcessorFlush(t *testing.T) {
+	plain := &models.RunningProcessor{
+		Processor: processors.NewStreamingProcessorFromProcessor(&mockProcessor{}),
+		Config:    &models.ProcessorConfig{},
+	}
+	require.False(t, plain.IsFlushing())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plain.Flush(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	flushing := &models.RunningProcessor{
+		Processor: &mockFlushingProcessor{},
+		Config:    &models.ProcessorConfig{},
+	}
+	require.True(t, flushing.IsFlushing())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	require.NoError(t, flushing.Add(m, &acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	require.NoError(t, flushing.Flush(&acc))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{m}, acc.GetTelegrafMetrics())
+}
+
+func TestRunningProcessorAccounting(t *testing.T) {
+	var sink [][]byte
+	mock := &mockProcessor{
+		applyF: func(in ...telegraf.Metric) []telegraf.Metric {
+			sink = append(sink, make([]byte, 1024*1024))
+			return in
+		},
+	}
+
+	for _, rate := range []int{0, 2} {
+		name := "TestRunningProcessorAccounting" + strconv.Itoa(rate)
+		rp := models.NewRunningProcessor(
+			processors.NewStreamingProcessorFromProcessor(mock),
+			&models.ProcessorConfig{Name: name, AccountingSampleRate: rate},
+		)
+		require.NoError(t, rp.Init())
+
+		var acc testutil.Accumulator
+		for range 4 {
+			m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+			require.NoError(t, rp.Add(m, &acc))
+		}
+		require.Len(t, acc.GetTelegrafMetrics(), 4)
+
+		var stats telegraf.Metric
+		for _, m := range selfstat.Metrics() {
+			if tag, found := m.GetTag("processor"); m.Name() == "internal_process" && found && tag == name {
+				stats = m
+				break
+			}
+		}
+
+		if rate == 0 {
+			// Accounting is disabled so there should not be any resource statistics
+			if stats != nil {
+				require.False(t, stats.HasField("cpu_time_ns"))
+			}
+			continue
+		}
+		require.NotNil(t, stats)
+		require.True(t, stats.HasField("cpu_time_ns"))
+		wallTime, found := stats.GetField("process_time_ns")
+		require.True(t, found)
+		require.Positive(t, wallTime)
+		allocBytes, found := stats.GetField("alloc_bytes")
+		require.True(t, found)
+		require.GreaterOrEqual(t, allocBytes, int64(1024*1024))
+	}
+	require.Len(t, sink, 8)
+}
+
 // mockProcessor is a processor with an overridable apply implementation.
 type mockProcessor struct {
 	applyF      func(in ...telegraf.Metric) []telegraf.Metric
@@ -225,3 +303,31 @@ func (p *mockProcessor) Init() error {
 func (p *mockProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	return p.applyF(in...)
 }
+
+// mockFlushingProcessor buffers all metrics until it is flushed.
+type mockFlushingProcessor struct {
+	buffer []telegraf.Metric
+}
+
+func (*mockFlushingProcessor) SampleConfig() string {
+	return ""
+}
+
+func (*mockFlushingProcessor) Start(telegraf.Accumulator) error {
+	return nil
+}
+
+func (p *mockFlushingProcessor) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
+	p.buffer = append(p.buffer, m)
+	return nil
+}
+
+func (p *mockFlushingProcessor) Flush(acc telegraf.Accumulator) error {
+	for _, m := range p.buffer {
+		acc.AddMetric(m)
+	}
+	p.buffer = nil
+	return nil
+}
+
+func (*mockFlushingProcessor) Stop() {}
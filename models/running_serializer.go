@@ -1,6 +1,10 @@
 package models
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -8,6 +12,10 @@ import (
 	"github.com/influxdata/telegraf/selfstat"
 )
 
+// defaultSerializationChunkSize is the number of metrics serialized by a
+// worker at once if parallel serialization is enabled
+const defaultSerializationChunkSize = 1000
+
 // SerializerConfig is the common config for all serializers.
 type SerializerConfig struct {
 	Parent      string
@@ -15,12 +23,23 @@ type SerializerConfig struct {
 	DataFormat  string
 	DefaultTags map[string]string
 	LogLevel    string
+	Framing     *telegraf.Framing
+
+	// Workers is the number of serializer instances used to serialize large
+	// batches in parallel, ChunkSize the number of metrics per instance and
+	// call. Batches are serialized sequentially if Workers is less than two.
+	Workers   int
+	ChunkSize int
 }
 
 type RunningSerializer struct {
 	Serializer telegraf.Serializer
 	Config     *SerializerConfig
 	log        telegraf.Logger
+	tags       map[string]string
+
+	workers []telegraf.Serializer
+	pool    chan telegraf.Serializer
 
 	MetricsSerialized selfstat.Stat
 	BytesSerialized   selfstat.Stat
@@ -62,8 +81,20 @@ func NewRunningSerializer(serializer telegraf.Serializer, config *SerializerConf
 			"serialization_time_ns",
 			tags,
 		),
-		log: logger,
+		log:  logger,
+		tags: tags,
+	}
+}
+
+// SetWorkers sets the serializer instances used for serializing large
+// batches in parallel. The instances must be configured like the main
+// serializer and are initialized with it.
+func (r *RunningSerializer) SetWorkers(serializers []telegraf.Serializer) {
+	for _, s := range serializers {
+		SetLoggerOnPlugin(s, r.log)
+		SetStatisticsOnPlugin(s, r.log, r.tags)
 	}
+	r.workers = serializers
 }
 
 func (r *RunningSerializer) LogName() string {
@@ -77,9 +108,44 @@ func (r *RunningSerializer) Init() error {
 			return err
 		}
 	}
+
+	if len(r.workers) < 2 {
+		return nil
+	}
+	if !r.parallelizable() {
+		r.log.Warnf("Serializer does not support splitting batches, serializing batches sequentially")
+		return nil
+	}
+	for _, s := range r.workers {
+		if p, ok := s.(telegraf.Initializer); ok {
+			if err := p.Init(); err != nil {
+				return err
+			}
+		}
+	}
+	if r.Config.ChunkSize <= 0 {
+		r.Config.ChunkSize = defaultSerializationChunkSize
+	}
+	r.pool = make(chan telegraf.Serializer, len(r.workers))
+	for _, s := range r.workers {
+		r.pool <- s
+	}
 	return nil
 }
 
+// parallelizable checks if batches can be split into chunks serialized
+// independently without changing the result
+func (r *RunningSerializer) parallelizable() bool {
+	// Framed batches are split at metric boundaries unless the serializer
+	// handles the framing on its own
+	if r.Config.Framing != nil {
+		_, ok := r.Serializer.(telegraf.FramingSerializer)
+		return !ok
+	}
+	s, ok := r.Serializer.(telegraf.ConcatenatingSerializer)
+	return ok && s.ConcatenatesBatches()
+}
+
 func (r *RunningSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 	start := time.Now()
 	buf, err := r.Serializer.Serialize(metric)
@@ -93,7 +159,15 @@ func (r *RunningSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 
 func (r *RunningSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	start := time.Now()
-	buf, err := r.Serializer.SerializeBatch(metrics)
+	var buf []byte
+	var err error
+	if r.pool != nil && len(metrics) > r.Config.ChunkSize {
+		buf, err = r.serializeBatchParallel(metrics)
+	} else if r.Config.Framing != nil {
+		buf, err = r.serializeBatchWithFraming(metrics, *r.Config.Framing)
+	} else {
+		buf, err = r.Serializer.SerializeBatch(metrics)
+	}
 	elapsed := time.Since(start)
 	r.SerializationTime.Incr(elapsed.Nanoseconds())
 	r.MetricsSerialized.Incr(int64(len(metrics)))
@@ -102,6 +176,110 @@ func (r *RunningSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, e
 	return buf, err
 }
 
+func (r *RunningSerializer) serializeBatchWithFraming(metrics []telegraf.Metric, framing telegraf.Framing) ([]byte, error) {
+	if s, ok := r.Serializer.(telegraf.FramingSerializer); ok {
+		return s.SerializeBatchWithFraming(metrics, framing)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(framing.Header)
+	if err := writeRecords(&buf, r.Serializer, metrics, framing); err != nil {
+		return nil, err
+	}
+	buf.Write(framing.Footer)
+
+	return buf.Bytes(), nil
+}
+
+// serializeBatchParallel splits the batch into chunks serialized by the
+// workers concurrently and joins the results in the order of the batch
+func (r *RunningSerializer) serializeBatchParallel(metrics []telegraf.Metric) ([]byte, error) {
+	size := r.Config.ChunkSize
+	chunks := make([][]byte, (len(metrics)+size-1)/size)
+	errs := make([]error, len(chunks))
+
+	var wg sync.WaitGroup
+	for i := range chunks {
+		part := metrics[i*size : min((i+1)*size, len(metrics))]
+		serializer := <-r.pool
+		wg.Go(func() {
+			defer func() { r.pool <- serializer }()
+			chunks[i], errs[i] = r.serializeChunk(serializer, part)
+		})
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	framing := r.Config.Framing
+	var buf bytes.Buffer
+	if framing != nil {
+		buf.Write(framing.Header)
+	}
+	for i, chunk := range chunks {
+		if i > 0 && framing != nil {
+			buf.Write(framing.Separator)
+		}
+		buf.Write(chunk)
+	}
+	if framing != nil {
+		buf.Write(framing.Footer)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (r *RunningSerializer) serializeChunk(serializer telegraf.Serializer, metrics []telegraf.Metric) ([]byte, error) {
+	if r.Config.Framing == nil {
+		return serializer.SerializeBatch(metrics)
+	}
+
+	var buf bytes.Buffer
+	if err := writeRecords(&buf, serializer, metrics, *r.Config.Framing); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeRecords writes the individually serialized metrics including the
+// separators and length prefixes of the framing
+func writeRecords(buf *bytes.Buffer, serializer telegraf.Serializer, metrics []telegraf.Metric, framing telegraf.Framing) error {
+	for i, m := range metrics {
+		record, err := serializer.Serialize(m)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			buf.Write(framing.Separator)
+		}
+		if err := writeLengthPrefix(buf, len(record), framing.LengthPrefix); err != nil {
+			return err
+		}
+		buf.Write(record)
+	}
+	return nil
+}
+
+// writeLengthPrefix writes the given length as big-endian number of the given
+// size in bytes
+func writeLengthPrefix(buf *bytes.Buffer, length, size int) error {
+	if size == 0 {
+		return nil
+	}
+	if size < 8 && uint64(length) >= 1<<(8*size) {
+		return fmt.Errorf("serialized metric of %d bytes exceeds %d byte length prefix", length, size)
+	}
+
+	var prefix [8]byte
+	binary.BigEndian.PutUint64(prefix[:], uint64(length))
+	buf.Write(prefix[8-size:])
+	return nil
+}
+
 func (r *RunningSerializer) Log() telegraf.Logger {
 	return r.log
 }
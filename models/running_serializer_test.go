@@ -0,0 +1,248 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestRunningSerializerFraming(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("a", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("bb", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+	}
+
+	tests := []struct {
+		name     string
+		framing  *telegraf.Framing
+		expected string
+	}{
+		{
+			name:     "no framing",
+			expected: "a\nbb\n",
+		},
+		{
+			name: "header and footer",
+			framing: &telegraf.Framing{
+				Header:    []byte("["),
+				Footer:    []byte("]"),
+				Separator: []byte(","),
+			},
+			expected: "[a\n,bb\n]",
+		},
+		{
+			name:     "length prefix",
+			framing:  &telegraf.Framing{LengthPrefix: 2},
+			expected: "\x00\x02a\n\x00\x03bb\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serializer := NewRunningSerializer(&mockSerializer{}, &SerializerConfig{
+				Parent:     "test",
+				DataFormat: "mock",
+				Framing:    tt.framing,
+			})
+			actual, err := serializer.SerializeBatch(metrics)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(actual))
+		})
+	}
+}
+
+func TestRunningSerializerFramingCustom(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("a", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+	}
+
+	serializer := NewRunningSerializer(&mockFramingSerializer{}, &SerializerConfig{
+		Parent:     "test",
+		DataFormat: "mock",
+		Framing:    &telegraf.Framing{Header: []byte("header")},
+	})
+	actual, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+	require.Equal(t, "custom header a\n", string(actual))
+}
+
+func TestRunningSerializerFramingLengthOverflow(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New(strings.Repeat("x", 256), map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+	}
+
+	serializer := NewRunningSerializer(&mockSerializer{}, &SerializerConfig{
+		Parent:     "test",
+		DataFormat: "mock",
+		Framing:    &telegraf.Framing{LengthPrefix: 1},
+	})
+	_, err := serializer.SerializeBatch(metrics)
+	require.ErrorContains(t, err, "exceeds 1 byte length prefix")
+}
+
+func TestRunningSerializerParallel(t *testing.T) {
+	metrics := make([]telegraf.Metric, 0, 7)
+	for i := range 7 {
+		name := strings.Repeat("x", i+1)
+		metrics = append(metrics, metric.New(name, map[string]string{}, map[string]interface{}{"value": i}, time.Unix(0, 0)))
+	}
+
+	tests := []struct {
+		name     string
+		framing  *telegraf.Framing
+		expected string
+	}{
+		{
+			name:     "no framing",
+			expected: "x\nxx\nxxx\nxxxx\nxxxxx\nxxxxxx\nxxxxxxx\n",
+		},
+		{
+			name: "framing",
+			framing: &telegraf.Framing{
+				Header:    []byte("["),
+				Footer:    []byte("]"),
+				Separator: []byte(","),
+			},
+			expected: "[x\n,xx\n,xxx\n,xxxx\n,xxxxx\n,xxxxxx\n,xxxxxxx\n]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serializer := NewRunningSerializer(&mockConcatenatingSerializer{}, &SerializerConfig{
+				Parent:     "test",
+				DataFormat: "mock",
+				Framing:    tt.framing,
+				Workers:    3,
+				ChunkSize:  2,
+			})
+			workers := make([]telegraf.Serializer, 0, 3)
+			for range 3 {
+				workers = append(workers, &mockConcatenatingSerializer{})
+			}
+			serializer.SetWorkers(workers)
+			require.NoError(t, serializer.Init())
+			require.NotNil(t, serializer.pool)
+
+			// Serialize multiple times to check the workers are returned
+			for range 3 {
+				actual, err := serializer.SerializeBatch(metrics)
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, string(actual))
+			}
+		})
+	}
+}
+
+func TestRunningSerializerParallelUnsupported(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("a", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("bb", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+	}
+
+	serializer := NewRunningSerializer(&mockSerializer{}, &SerializerConfig{
+		Parent:     "test",
+		DataFormat: "mock",
+		Workers:    2,
+		ChunkSize:  1,
+	})
+	serializer.SetWorkers([]telegraf.Serializer{&mockSerializer{}, &mockSerializer{}})
+	require.NoError(t, serializer.Init())
+	require.Nil(t, serializer.pool)
+
+	actual, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+	require.Equal(t, "a\nbb\n", string(actual))
+}
+
+func TestRunningSerializerParallelError(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("a", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New(strings.Repeat("x", 256), map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("b", map[string]string{}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+	}
+
+	serializer := NewRunningSerializer(&mockConcatenatingSerializer{}, &SerializerConfig{
+		Parent:     "test",
+		DataFormat: "mock",
+		Framing:    &telegraf.Framing{LengthPrefix: 1},
+		Workers:    2,
+		ChunkSize:  1,
+	})
+	serializer.SetWorkers([]telegraf.Serializer{&mockConcatenatingSerializer{}, &mockConcatenatingSerializer{}})
+	require.NoError(t, serializer.Init())
+
+	_, err := serializer.SerializeBatch(metrics)
+	require.ErrorContains(t, err, "exceeds 1 byte length prefix")
+}
+
+// mockSerializer serializes the metric name followed by a newline
+type mockSerializer struct{}
+
+func (*mockSerializer) Serialize(m telegraf.Metric) ([]byte, error) {
+	return []byte(m.Name() + "\n"), nil
+}
+
+func (s *mockSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf []byte
+	for _, m := range metrics {
+		b, err := s.Serialize(m)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+type mockFramingSerializer struct {
+	mockSerializer
+}
+
+func (s *mockFramingSerializer) SerializeBatchWithFraming(metrics []telegraf.Metric, framing telegraf.Framing) ([]byte, error) {
+	buf, err := s.SerializeBatch(metrics)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("custom "+string(framing.Header)+" "), buf...), nil
+}
+
+// mockConcatenatingSerializer fails if used concurrently to check the workers
+// are not shared between goroutines
+type mockConcatenatingSerializer struct {
+	mockSerializer
+	inUse atomic.Bool
+}
+
+func (s *mockConcatenatingSerializer) Serialize(m telegraf.Metric) ([]byte, error) {
+	if !s.inUse.CompareAndSwap(false, true) {
+		return nil, errors.New("concurrent use of serializer")
+	}
+	defer s.inUse.Store(false)
+
+	time.Sleep(time.Millisecond)
+	return s.mockSerializer.Serialize(m)
+}
+
+func (s *mockConcatenatingSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf []byte
+	for _, m := range metrics {
+		b, err := s.Serialize(m)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+func (*mockConcatenatingSerializer) ConcatenatesBatches() bool {
+	return true
+}
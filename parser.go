@@ -1,6 +1,9 @@
 package telegraf
 
-import "time"
+import (
+	"io"
+	"time"
+)
 
 // Parser is an interface defining functions that a parser plugin must satisfy.
 type Parser interface {
@@ -24,6 +27,25 @@ type Parser interface {
 	SetDefaultTags(tags map[string]string)
 }
 
+// ReaderAtParser is an optional interface for parsers able to parse random
+// access data, e.g. memory-mapped files, without reading the whole data into
+// memory first.
+type ReaderAtParser interface {
+	// ParseReaderAt parses the first size bytes of the given reader.
+	//
+	// Must be thread-safe.
+	ParseReaderAt(r io.ReaderAt, size int64) ([]Metric, error)
+}
+
+// SizeAccountingParser is an optional interface for parsers reporting the
+// number of input bytes the parsed metrics originate from, e.g. to enforce byte
+// quotas or to compute throughput without serializing the metrics again.
+type SizeAccountingParser interface {
+	// SetSizeCallback sets the function called during parsing with the
+	// parsed metrics and the number of input bytes they were parsed from.
+	SetSizeCallback(fn func(metrics []Metric, size int))
+}
+
 // ParserFunc is a function to create a new instance of a parser
 type ParserFunc func() (Parser, error)
 
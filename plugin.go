@@ -57,6 +57,17 @@ type StatefulPlugin interface {
 	SetState(state interface{}) error
 }
 
+// DebugInfoPlugin is an interface that plugins can optionally implement to
+// expose their effective runtime configuration, e.g. resolved tables, via the
+// agent's debug endpoint.
+type DebugInfoPlugin interface {
+	// DebugInfo returns information about the plugin instance. The returned
+	// value can be of any type as long as it can be serialized to JSON.
+	// Note: This function must be safe to call concurrently to the plugin's
+	// processing functions.
+	DebugInfo() interface{}
+}
+
 // ProbePlugin is an interface that all input/output plugins need to
 // implement in order to support the `probe` value of `startup_error_behavior`
 type ProbePlugin interface {
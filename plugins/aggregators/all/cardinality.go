@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.cardinality
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/cardinality" // register plugin
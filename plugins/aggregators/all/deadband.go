@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.deadband
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/deadband" // register plugin
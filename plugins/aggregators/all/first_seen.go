@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.first_seen
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/first_seen" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.ratio
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/ratio" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.rolling_window
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/rolling_window" // register plugin
@@ -0,0 +1,104 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cardinality
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const measurement = "telegraf_cardinality"
+
+type Cardinality struct {
+	Precision uint8    `toml:"precision"`
+	TagKeys   []string `toml:"tag_keys"`
+
+	tagFilter filter.Filter
+	cache     map[string]*aggregate
+}
+
+type aggregate struct {
+	metrics int64
+	series  *sketch
+	tags    map[string]*sketch
+}
+
+func (*Cardinality) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *Cardinality) Init() error {
+	if c.Precision < 4 || c.Precision > 18 {
+		return fmt.Errorf("precision %d out of range [4, 18]", c.Precision)
+	}
+
+	var err error
+	c.tagFilter, err = filter.Compile(c.TagKeys)
+	if err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+
+	c.Reset()
+	return nil
+}
+
+func (c *Cardinality) Add(in telegraf.Metric) {
+	agg, found := c.cache[in.Name()]
+	if !found {
+		agg = &aggregate{
+			series: newSketch(c.Precision),
+			tags:   make(map[string]*sketch),
+		}
+		c.cache[in.Name()] = agg
+	}
+
+	agg.metrics++
+	agg.series.insert(mix(in.HashID()))
+
+	if c.tagFilter == nil {
+		return
+	}
+	for _, tag := range in.TagList() {
+		if !c.tagFilter.Match(tag.Key) {
+			continue
+		}
+		s, found := agg.tags[tag.Key]
+		if !found {
+			s = newSketch(c.Precision)
+			agg.tags[tag.Key] = s
+		}
+		s.insertString(tag.Value)
+	}
+}
+
+func (c *Cardinality) Push(acc telegraf.Accumulator) {
+	for name, agg := range c.cache {
+		fields := map[string]interface{}{
+			"metrics": agg.metrics,
+			"series":  agg.series.estimate(),
+		}
+		for key, s := range agg.tags {
+			fields["tag_"+key] = s.estimate()
+		}
+		acc.AddFields(measurement, fields, map[string]string{"measurement": name})
+	}
+}
+
+func (c *Cardinality) Reset() {
+	c.cache = make(map[string]*aggregate)
+}
+
+func init() {
+	aggregators.Add("cardinality", func() telegraf.Aggregator {
+		return &Cardinality{
+			Precision: 12,
+			TagKeys:   []string{"*"},
+		}
+	})
+}
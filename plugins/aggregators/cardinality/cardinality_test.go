@@ -0,0 +1,87 @@
+package cardinality
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSketchEstimate(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 100000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			s := newSketch(14)
+			for i := range n {
+				s.insertString("value-" + strconv.Itoa(i))
+				// Duplicates must not change the estimate
+				s.insertString("value-" + strconv.Itoa(i))
+			}
+			require.InEpsilon(t, float64(n)+1, float64(s.estimate())+1, 0.03)
+		})
+	}
+}
+
+func TestCardinality(t *testing.T) {
+	plugin := &Cardinality{
+		Precision: 12,
+		TagKeys:   []string{"host", "id"},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	for i := range 3 {
+		for _, host := range []string{"a", "b"} {
+			plugin.Add(metric.New(
+				"requests",
+				map[string]string{"host": host, "id": strconv.Itoa(i), "region": "eu"},
+				map[string]interface{}{"value": 1},
+				now,
+			))
+		}
+	}
+	plugin.Add(metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, now))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"telegraf_cardinality",
+			map[string]string{"measurement": "requests"},
+			map[string]interface{}{
+				"metrics":  int64(6),
+				"series":   uint64(6),
+				"tag_host": uint64(2),
+				"tag_id":   uint64(3),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"telegraf_cardinality",
+			map[string]string{"measurement": "cpu"},
+			map[string]interface{}{
+				"metrics":  int64(1),
+				"series":   uint64(1),
+				"tag_host": uint64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The estimates are reset after each period
+	plugin.Reset()
+	acc.ClearMetrics()
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestCardinalityInvalidPrecision(t *testing.T) {
+	plugin := &Cardinality{Precision: 20}
+	require.ErrorContains(t, plugin.Init(), "precision 20 out of range")
+}
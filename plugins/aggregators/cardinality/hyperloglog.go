@@ -0,0 +1,82 @@
+package cardinality
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// sketch is a HyperLogLog estimator of the number of distinct values with a
+// relative standard error of about 1.04/sqrt(2^precision)
+type sketch struct {
+	precision uint8
+	registers []uint8
+}
+
+func newSketch(precision uint8) *sketch {
+	return &sketch{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}
+}
+
+// insert adds the given hash to the sketch
+func (s *sketch) insert(hash uint64) {
+	idx := hash >> (64 - s.precision)
+	// Set the lowest bit of the remaining bits to bound the rank
+	rank := uint8(bits.LeadingZeros64(hash<<s.precision|1<<(s.precision-1))) + 1
+	if rank > s.registers[idx] {
+		s.registers[idx] = rank
+	}
+}
+
+// insertString adds the given value to the sketch
+func (s *sketch) insertString(value string) {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	s.insert(mix(h.Sum64()))
+}
+
+// estimate returns the estimated number of distinct values added
+func (s *sketch) estimate() uint64 {
+	m := float64(len(s.registers))
+
+	var sum float64
+	var zeros int
+	for _, r := range s.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	e := alpha(len(s.registers)) * m * m / sum
+
+	// Use linear counting for small cardinalities
+	if e <= 2.5*m && zeros > 0 {
+		e = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(e))
+}
+
+func alpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/float64(m))
+}
+
+// mix spreads the bits of the given hash using the splitmix64 finalizer as
+// HyperLogLog requires uniformly distributed hashes
+func mix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
@@ -0,0 +1,153 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package deadband
+
+import (
+	_ "embed"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Deadband struct {
+	AbsoluteDelta float64         `toml:"absolute_delta"`
+	RelativeDelta float64         `toml:"relative_delta"`
+	Heartbeat     config.Duration `toml:"heartbeat"`
+
+	// The last forwarded metric for each series
+	cache map[uint64]telegraf.Metric
+	// Metrics to be forwarded on the next push
+	pending []telegraf.Metric
+}
+
+func (*Deadband) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Deadband) Init() error {
+	if d.AbsoluteDelta < 0 {
+		return errors.New("absolute delta must not be negative")
+	}
+	if d.RelativeDelta < 0 {
+		return errors.New("relative delta must not be negative")
+	}
+	if d.Heartbeat < 0 {
+		return errors.New("heartbeat must not be negative")
+	}
+
+	d.cache = make(map[uint64]telegraf.Metric)
+
+	return nil
+}
+
+func (d *Deadband) Add(in telegraf.Metric) {
+	id := in.HashID()
+	if last, found := d.cache[id]; found && !d.heartbeatDue(last, in) && !d.changed(last, in) {
+		return
+	}
+	d.cache[id] = in
+	d.pending = append(d.pending, in)
+}
+
+func (d *Deadband) Push(acc telegraf.Accumulator) {
+	// Preserve timestamp of original metric
+	acc.SetPrecision(time.Nanosecond)
+
+	// The cached metrics serve as reference for the series, so forward
+	// copies to not get them modified down the line.
+	for _, m := range d.pending {
+		acc.AddMetric(m.Copy())
+	}
+	d.pending = nil
+
+	// Series not forwarded within the heartbeat interval are forwarded with
+	// the next metric anyway, so we can forget about them.
+	if d.Heartbeat > 0 {
+		for id, m := range d.cache {
+			if time.Since(m.Time()) >= time.Duration(d.Heartbeat) {
+				delete(d.cache, id)
+			}
+		}
+	}
+}
+
+func (*Deadband) Reset() {
+}
+
+// heartbeatDue returns true if the heartbeat interval passed since the last
+// forwarded metric of the series.
+func (d *Deadband) heartbeatDue(last, in telegraf.Metric) bool {
+	return d.Heartbeat > 0 && in.Time().Sub(last.Time()) >= time.Duration(d.Heartbeat)
+}
+
+// changed returns true if any field of the metric differs significantly from
+// the last forwarded metric of the series. New fields, fields changing their
+// type and non-numeric fields with a different value are always significant.
+func (d *Deadband) changed(last, in telegraf.Metric) bool {
+	for _, field := range in.FieldList() {
+		previous, found := last.GetField(field.Key)
+		if !found {
+			return true
+		}
+
+		current, ok := toFloat(field.Value)
+		if !ok {
+			if field.Value != previous {
+				return true
+			}
+			continue
+		}
+		reference, ok := toFloat(previous)
+		if !ok {
+			return true
+		}
+
+		if d.exceeds(reference, current) {
+			return true
+		}
+	}
+	return false
+}
+
+// exceeds checks if the difference between the values is larger than the
+// configured deltas. Any difference is significant without deltas.
+func (d *Deadband) exceeds(reference, current float64) bool {
+	delta := math.Abs(current - reference)
+	if d.AbsoluteDelta == 0 && d.RelativeDelta == 0 {
+		return delta > 0
+	}
+	if d.AbsoluteDelta > 0 && delta > d.AbsoluteDelta {
+		return true
+	}
+	return d.RelativeDelta > 0 && delta > d.RelativeDelta*math.Abs(reference)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func newDeadband() *Deadband {
+	return &Deadband{
+		Heartbeat: config.Duration(5 * time.Minute),
+	}
+}
+
+func init() {
+	aggregators.Add("deadband", func() telegraf.Aggregator {
+		return newDeadband()
+	})
+}
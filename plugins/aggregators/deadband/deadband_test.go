@@ -0,0 +1,176 @@
+package deadband
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &Deadband{AbsoluteDelta: -1}
+	require.ErrorContains(t, plugin.Init(), "absolute delta must not be negative")
+
+	plugin = &Deadband{RelativeDelta: -0.1}
+	require.ErrorContains(t, plugin.Init(), "relative delta must not be negative")
+}
+
+func TestDeltas(t *testing.T) {
+	tests := []struct {
+		name     string
+		absolute float64
+		relative float64
+		values   []interface{}
+		expected []interface{}
+	}{
+		{
+			name:     "any change",
+			values:   []interface{}{1.0, 1.0, 1.1, 1.1, 1.0},
+			expected: []interface{}{1.0, 1.1, 1.0},
+		},
+		{
+			name:     "absolute",
+			absolute: 0.5,
+			values:   []interface{}{21.0, 21.2, 21.4, 21.6, 21.7, 21.0},
+			expected: []interface{}{21.0, 21.6, 21.0},
+		},
+		{
+			name:     "relative",
+			relative: 0.1,
+			values:   []interface{}{int64(100), int64(105), int64(109), int64(111), int64(99)},
+			expected: []interface{}{int64(100), int64(111), int64(99)},
+		},
+		{
+			name:     "absolute or relative",
+			absolute: 10,
+			relative: 0.5,
+			values:   []interface{}{uint64(1), uint64(2), uint64(14), uint64(20), uint64(31)},
+			expected: []interface{}{uint64(1), uint64(2), uint64(14), uint64(31)},
+		},
+		{
+			name:     "strings",
+			absolute: 1,
+			values:   []interface{}{"ok", "ok", "failed", "failed", "ok"},
+			expected: []interface{}{"ok", "failed", "ok"},
+		},
+		{
+			name:     "type change",
+			absolute: 1,
+			values:   []interface{}{int64(1), int64(1), "1", "1"},
+			expected: []interface{}{int64(1), "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Deadband{
+				AbsoluteDelta: tt.absolute,
+				RelativeDelta: tt.relative,
+			}
+			require.NoError(t, plugin.Init())
+
+			start := time.Now()
+			for i, v := range tt.values {
+				plugin.Add(metric.New(
+					"test",
+					map[string]string{"source": "a"},
+					map[string]interface{}{"value": v},
+					start.Add(time.Duration(i)*time.Second),
+				))
+			}
+
+			var acc testutil.Accumulator
+			plugin.Push(&acc)
+
+			actual := make([]interface{}, 0, len(tt.expected))
+			for _, m := range acc.GetTelegrafMetrics() {
+				v, found := m.GetField("value")
+				require.True(t, found)
+				actual = append(actual, v)
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestSeries(t *testing.T) {
+	plugin := &Deadband{AbsoluteDelta: 1}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{"source": "a"}, map[string]interface{}{"value": 1.0}, now),
+		metric.New("test", map[string]string{"source": "b"}, map[string]interface{}{"value": 1.5}, now),
+		metric.New("test", map[string]string{"source": "a"}, map[string]interface{}{"value": 1.5}, now.Add(time.Second)),
+		metric.New("test", map[string]string{"source": "b"}, map[string]interface{}{"value": 3.0}, now.Add(time.Second)),
+		metric.New("test", map[string]string{"source": "a"}, map[string]interface{}{"value": 1.5, "extra": 0.0}, now.Add(2*time.Second)),
+	}
+	for _, m := range input {
+		plugin.Add(m)
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"source": "a"}, map[string]interface{}{"value": 1.0}, now),
+		metric.New("test", map[string]string{"source": "b"}, map[string]interface{}{"value": 1.5}, now),
+		metric.New("test", map[string]string{"source": "b"}, map[string]interface{}{"value": 3.0}, now.Add(time.Second)),
+		metric.New("test", map[string]string{"source": "a"}, map[string]interface{}{"value": 1.5, "extra": 0.0}, now.Add(2*time.Second)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Pushing again must not forward the metrics twice
+	acc.ClearMetrics()
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestHeartbeat(t *testing.T) {
+	plugin := &Deadband{
+		AbsoluteDelta: 10,
+		Heartbeat:     config.Duration(time.Minute),
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	for i := range 5 {
+		plugin.Add(metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{"value": int64(i)},
+			now.Add(time.Duration(i)*30*time.Second),
+		))
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(0)}, now),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(2)}, now.Add(time.Minute)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(4)}, now.Add(2*time.Minute)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestHeartbeatExpiresSeries(t *testing.T) {
+	plugin := &Deadband{
+		AbsoluteDelta: 10,
+		Heartbeat:     config.Duration(time.Minute),
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(metric.New("test", map[string]string{"source": "old"}, map[string]interface{}{"value": 1.0}, time.Now().Add(-time.Hour)))
+	plugin.Add(metric.New("test", map[string]string{"source": "new"}, map[string]interface{}{"value": 1.0}, time.Now()))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	require.Len(t, plugin.cache, 1)
+}
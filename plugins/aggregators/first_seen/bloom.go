@@ -0,0 +1,61 @@
+package first_seen
+
+import (
+	"math"
+)
+
+// bloomFilter is a set of hashes answering membership queries without false
+// negatives but with a bounded probability of false positives
+type bloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint64
+}
+
+// newBloomFilter creates a filter sized for n elements with the given false
+// positive probability p
+func newBloomFilter(n int, p float64) *bloomFilter {
+	size := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	size = max(size, 64)
+	hashes := uint64(math.Round(float64(size) / float64(n) * math.Ln2))
+	hashes = max(hashes, 1)
+
+	return &bloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// add inserts the given hash into the filter
+func (b *bloomFilter) add(hash uint64) {
+	h1, h2 := split(hash)
+	for i := range b.hashes {
+		idx := (h1 + i*h2) % b.size
+		b.bits[idx/64] |= 1 << (idx % 64)
+	}
+}
+
+// contains returns true if the given hash was probably added before
+func (b *bloomFilter) contains(hash uint64) bool {
+	h1, h2 := split(hash)
+	for i := range b.hashes {
+		idx := (h1 + i*h2) % b.size
+		if b.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// clear removes all elements from the filter
+func (b *bloomFilter) clear() {
+	clear(b.bits)
+}
+
+// split derives the two hashes for the double hashing scheme of Kirsch and
+// Mitzenmacher from the halves of the given hash, forcing the second one to
+// be odd so the probe sequence does not collapse
+func split(hash uint64) (h1, h2 uint64) {
+	return hash & 0xffffffff, hash>>32 | 1
+}
@@ -0,0 +1,145 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package first_seen
+
+import (
+	_ "embed"
+	"errors"
+	"hash/maphash"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const measurement = "first_seen"
+
+type FirstSeen struct {
+	Window            config.Duration `toml:"window"`
+	TagKeys           []string        `toml:"tag_keys"`
+	ExpectedSeries    int             `toml:"expected_series"`
+	FalsePositiveRate float64         `toml:"false_positive_rate"`
+
+	tagFilter filter.Filter
+	seed      maphash.Seed
+
+	// Series seen since the last rotation and during the generation before,
+	// each generation spanning one window
+	current  *bloomFilter
+	previous *bloomFilter
+	rotated  time.Time
+
+	events []telegraf.Metric
+	now    func() time.Time
+}
+
+func (*FirstSeen) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *FirstSeen) Init() error {
+	if f.Window <= 0 {
+		return errors.New("window must be positive")
+	}
+	if f.ExpectedSeries < 1 {
+		return errors.New("expected_series must be positive")
+	}
+	if f.FalsePositiveRate <= 0 || f.FalsePositiveRate >= 1 {
+		return errors.New("false_positive_rate must be in the range (0, 1)")
+	}
+
+	var err error
+	f.tagFilter, err = filter.Compile(f.TagKeys)
+	if err != nil {
+		return err
+	}
+
+	f.seed = maphash.MakeSeed()
+	f.current = newBloomFilter(f.ExpectedSeries, f.FalsePositiveRate)
+	f.previous = newBloomFilter(f.ExpectedSeries, f.FalsePositiveRate)
+	if f.now == nil {
+		f.now = time.Now
+	}
+	f.rotated = f.now()
+
+	return nil
+}
+
+func (f *FirstSeen) Add(in telegraf.Metric) {
+	f.rotate()
+
+	tags := make(map[string]string, len(in.TagList())+1)
+	var h maphash.Hash
+	h.SetSeed(f.seed)
+	h.WriteString(in.Name())
+	for _, tag := range in.TagList() {
+		if f.tagFilter == nil || !f.tagFilter.Match(tag.Key) {
+			continue
+		}
+		h.WriteByte(0)
+		h.WriteString(tag.Key)
+		h.WriteByte(0)
+		h.WriteString(tag.Value)
+		tags[tag.Key] = tag.Value
+	}
+	id := h.Sum64()
+
+	if f.current.contains(id) {
+		return
+	}
+	// Keep series seen in the previous generation known while they are
+	// still active
+	known := f.previous.contains(id)
+	f.current.add(id)
+	if known {
+		return
+	}
+
+	tags["measurement"] = in.Name()
+	f.events = append(f.events, metric.New(measurement, tags, map[string]interface{}{"seen": int64(1)}, in.Time()))
+}
+
+func (f *FirstSeen) Push(acc telegraf.Accumulator) {
+	for _, m := range f.events {
+		acc.AddMetric(m)
+	}
+}
+
+func (f *FirstSeen) Reset() {
+	f.events = nil
+}
+
+// rotate starts a new generation once the current one spans the window,
+// forgetting the series only seen in the previous generation
+func (f *FirstSeen) rotate() {
+	now := f.now()
+	elapsed := now.Sub(f.rotated)
+	if elapsed < time.Duration(f.Window) {
+		return
+	}
+
+	f.previous, f.current = f.current, f.previous
+	f.current.clear()
+	// Series of the current generation expired as well if no metric arrived
+	// for more than another window
+	if elapsed >= 2*time.Duration(f.Window) {
+		f.previous.clear()
+	}
+	f.rotated = now
+}
+
+func init() {
+	aggregators.Add("first_seen", func() telegraf.Aggregator {
+		return &FirstSeen{
+			Window:            config.Duration(24 * time.Hour),
+			TagKeys:           []string{"*"},
+			ExpectedSeries:    100000,
+			FalsePositiveRate: 0.001,
+		}
+	})
+}
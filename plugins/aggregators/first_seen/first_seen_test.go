@@ -0,0 +1,159 @@
+package first_seen
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestBloomFilter(t *testing.T) {
+	b := newBloomFilter(10000, 0.01)
+	for i := range uint64(10000) {
+		b.add(i * 0x9e3779b97f4a7c15)
+	}
+	for i := range uint64(10000) {
+		require.True(t, b.contains(i*0x9e3779b97f4a7c15))
+	}
+
+	var positives int
+	for i := range uint64(10000) {
+		if b.contains((i + 10000) * 0x9e3779b97f4a7c15) {
+			positives++
+		}
+	}
+	require.Less(t, positives, 200)
+
+	b.clear()
+	require.False(t, b.contains(0x9e3779b97f4a7c15))
+}
+
+func TestFirstSeen(t *testing.T) {
+	now := time.Now()
+	plugin := &FirstSeen{
+		Window:            config.Duration(time.Hour),
+		TagKeys:           []string{"host"},
+		ExpectedSeries:    1000,
+		FalsePositiveRate: 0.001,
+		now:               func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	for i := range 3 {
+		for _, host := range []string{"a", "b"} {
+			plugin.Add(metric.New(
+				"cpu",
+				map[string]string{"host": host, "cpu": "cpu" + strconv.Itoa(i)},
+				map[string]interface{}{"value": 1},
+				now.Add(time.Duration(i)*time.Second),
+			))
+		}
+	}
+	plugin.Add(metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, now))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"first_seen",
+			map[string]string{"measurement": "cpu", "host": "a"},
+			map[string]interface{}{"seen": int64(1)},
+			now,
+		),
+		metric.New(
+			"first_seen",
+			map[string]string{"measurement": "cpu", "host": "b"},
+			map[string]interface{}{"seen": int64(1)},
+			now,
+		),
+		metric.New(
+			"first_seen",
+			map[string]string{"measurement": "mem", "host": "a"},
+			map[string]interface{}{"seen": int64(1)},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Known series are not reported again in later periods
+	acc.ClearMetrics()
+	plugin.Add(metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, now))
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestFirstSeenWindow(t *testing.T) {
+	now := time.Now()
+	plugin := &FirstSeen{
+		Window:            config.Duration(time.Hour),
+		TagKeys:           []string{"*"},
+		ExpectedSeries:    1000,
+		FalsePositiveRate: 0.001,
+		now:               func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	add := func(host string) int {
+		var acc testutil.Accumulator
+		plugin.Add(metric.New("cpu", map[string]string{"host": host}, map[string]interface{}{"value": 1}, now))
+		plugin.Push(&acc)
+		plugin.Reset()
+		return len(acc.GetTelegrafMetrics())
+	}
+
+	require.Equal(t, 1, add("a"))
+	require.Equal(t, 1, add("b"))
+
+	// Series seen in the previous generation are still known and stay known
+	// when seen again
+	now = now.Add(90 * time.Minute)
+	require.Equal(t, 0, add("a"))
+
+	// Series not seen for more than the window are forgotten after the next
+	// rotation
+	now = now.Add(time.Hour)
+	require.Equal(t, 0, add("a"))
+	require.Equal(t, 1, add("b"))
+
+	// All series are forgotten if nothing was seen for twice the window
+	now = now.Add(2 * time.Hour)
+	require.Equal(t, 1, add("a"))
+}
+
+func TestFirstSeenInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *FirstSeen
+		expected string
+	}{
+		{
+			name:     "window",
+			plugin:   &FirstSeen{ExpectedSeries: 1, FalsePositiveRate: 0.1},
+			expected: "window must be positive",
+		},
+		{
+			name:     "expected series",
+			plugin:   &FirstSeen{Window: config.Duration(time.Hour), FalsePositiveRate: 0.1},
+			expected: "expected_series must be positive",
+		},
+		{
+			name:     "false positive rate",
+			plugin:   &FirstSeen{Window: config.Duration(time.Hour), ExpectedSeries: 1, FalsePositiveRate: 1},
+			expected: "false_positive_rate must be in the range (0, 1)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
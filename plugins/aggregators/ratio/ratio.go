@@ -0,0 +1,207 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package ratio
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Ratio struct {
+	Ratios []*ratio        `toml:"ratio"`
+	Log    telegraf.Logger `toml:"-"`
+}
+
+// ratio is the definition of a ratio between two fields of metrics joined by
+// the given tags together with the values collected in the current period
+type ratio struct {
+	Measurement            string   `toml:"measurement"`
+	Field                  string   `toml:"field"`
+	NumeratorMeasurement   string   `toml:"numerator_measurement"`
+	NumeratorField         string   `toml:"numerator_field"`
+	DenominatorMeasurement string   `toml:"denominator_measurement"`
+	DenominatorField       string   `toml:"denominator_field"`
+	JoinTags               []string `toml:"join_tags"`
+	Aggregation            string   `toml:"aggregation"`
+	Scale                  float64  `toml:"scale"`
+
+	groups map[string]*group
+}
+
+// group holds the values of the numerator and denominator of metrics with
+// the same values of the join tags
+type group struct {
+	tags        map[string]string
+	numerator   operand
+	denominator operand
+}
+
+// operand aggregates the values of a field within a period
+type operand struct {
+	sum   float64
+	last  float64
+	count int
+}
+
+func (o *operand) add(v float64) {
+	o.sum += v
+	o.last = v
+	o.count++
+}
+
+func (o *operand) value(aggregation string) float64 {
+	switch aggregation {
+	case "mean":
+		return o.sum / float64(o.count)
+	case "last":
+		return o.last
+	}
+	return o.sum
+}
+
+func (*Ratio) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Ratio) Init() error {
+	if len(r.Ratios) == 0 {
+		return errors.New("no ratio defined")
+	}
+
+	for i, rt := range r.Ratios {
+		if err := rt.init(); err != nil {
+			return fmt.Errorf("ratio %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (rt *ratio) init() error {
+	if rt.Measurement == "" {
+		return errors.New("measurement required")
+	}
+	if rt.NumeratorMeasurement == "" || rt.NumeratorField == "" {
+		return errors.New("numerator measurement and field required")
+	}
+	if rt.DenominatorMeasurement == "" || rt.DenominatorField == "" {
+		return errors.New("denominator measurement and field required")
+	}
+	if rt.Field == "" {
+		rt.Field = "ratio"
+	}
+	switch rt.Aggregation {
+	case "":
+		rt.Aggregation = "sum"
+	case "sum", "mean", "last":
+	default:
+		return fmt.Errorf("invalid aggregation %q", rt.Aggregation)
+	}
+	if rt.Scale == 0 {
+		rt.Scale = 1
+	}
+
+	rt.groups = make(map[string]*group)
+
+	return nil
+}
+
+func (r *Ratio) Add(in telegraf.Metric) {
+	for _, rt := range r.Ratios {
+		rt.add(in)
+	}
+}
+
+func (rt *ratio) add(in telegraf.Metric) {
+	name := in.Name()
+	isNumerator := name == rt.NumeratorMeasurement
+	isDenominator := name == rt.DenominatorMeasurement
+	if !isNumerator && !isDenominator {
+		return
+	}
+
+	// Metrics without all join tags cannot be matched
+	values := make([]string, 0, len(rt.JoinTags))
+	for _, key := range rt.JoinTags {
+		v, found := in.GetTag(key)
+		if !found {
+			return
+		}
+		values = append(values, v)
+	}
+	id := strings.Join(values, "\x00")
+
+	g, found := rt.groups[id]
+	if !found {
+		g = &group{tags: make(map[string]string, len(rt.JoinTags))}
+		for i, key := range rt.JoinTags {
+			g.tags[key] = values[i]
+		}
+		rt.groups[id] = g
+	}
+
+	// Numerator and denominator might be fields of the same measurement
+	if isNumerator {
+		if v, ok := fieldValue(in, rt.NumeratorField); ok {
+			g.numerator.add(v)
+		}
+	}
+	if isDenominator {
+		if v, ok := fieldValue(in, rt.DenominatorField); ok {
+			g.denominator.add(v)
+		}
+	}
+}
+
+func (r *Ratio) Push(acc telegraf.Accumulator) {
+	for _, rt := range r.Ratios {
+		for _, g := range rt.groups {
+			// Only groups with both operands result in a ratio
+			if g.numerator.count == 0 || g.denominator.count == 0 {
+				continue
+			}
+			denominator := g.denominator.value(rt.Aggregation)
+			if denominator == 0 {
+				r.Log.Debugf("Skipping ratio %q for tags %v due to zero denominator", rt.Measurement, g.tags)
+				continue
+			}
+			value := g.numerator.value(rt.Aggregation) / denominator * rt.Scale
+			acc.AddFields(rt.Measurement, map[string]interface{}{rt.Field: value}, g.tags)
+		}
+	}
+}
+
+func (r *Ratio) Reset() {
+	for _, rt := range r.Ratios {
+		rt.groups = make(map[string]*group)
+	}
+}
+
+func fieldValue(m telegraf.Metric, key string) (float64, bool) {
+	value, found := m.GetField(key)
+	if !found {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func init() {
+	aggregators.Add("ratio", func() telegraf.Aggregator {
+		return &Ratio{}
+	})
+}
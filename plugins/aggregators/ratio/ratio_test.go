@@ -0,0 +1,157 @@
+package ratio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		ratio    *ratio
+		expected string
+	}{
+		{
+			name:     "no measurement",
+			ratio:    &ratio{NumeratorMeasurement: "a", NumeratorField: "x", DenominatorMeasurement: "b", DenominatorField: "y"},
+			expected: "ratio 1: measurement required",
+		},
+		{
+			name:     "no numerator field",
+			ratio:    &ratio{Measurement: "r", NumeratorMeasurement: "a", DenominatorMeasurement: "b", DenominatorField: "y"},
+			expected: "ratio 1: numerator measurement and field required",
+		},
+		{
+			name:     "no denominator",
+			ratio:    &ratio{Measurement: "r", NumeratorMeasurement: "a", NumeratorField: "x"},
+			expected: "ratio 1: denominator measurement and field required",
+		},
+		{
+			name: "invalid aggregation",
+			ratio: &ratio{
+				Measurement:            "r",
+				NumeratorMeasurement:   "a",
+				NumeratorField:         "x",
+				DenominatorMeasurement: "b",
+				DenominatorField:       "y",
+				Aggregation:            "max",
+			},
+			expected: `ratio 1: invalid aggregation "max"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Ratio{Ratios: []*ratio{tt.ratio}}
+			require.EqualError(t, plugin.Init(), tt.expected)
+		})
+	}
+
+	require.EqualError(t, (&Ratio{}).Init(), "no ratio defined")
+}
+
+func TestJoin(t *testing.T) {
+	plugin := &Ratio{
+		Ratios: []*ratio{
+			{
+				Measurement:            "http_error_ratio",
+				NumeratorMeasurement:   "http_errors",
+				NumeratorField:         "count",
+				DenominatorMeasurement: "http_requests",
+				DenominatorField:       "count",
+				JoinTags:               []string{"service"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("http_requests", map[string]string{"service": "api", "host": "a"}, map[string]interface{}{"count": int64(600)}, now),
+		metric.New("http_requests", map[string]string{"service": "api", "host": "b"}, map[string]interface{}{"count": uint64(400)}, now),
+		metric.New("http_errors", map[string]string{"service": "api", "host": "a"}, map[string]interface{}{"count": int64(8)}, now),
+		metric.New("http_errors", map[string]string{"service": "api", "host": "b"}, map[string]interface{}{"count": 2.0}, now),
+		// No errors for this service
+		metric.New("http_requests", map[string]string{"service": "web"}, map[string]interface{}{"count": int64(50)}, now),
+		// Missing join tag
+		metric.New("http_errors", map[string]string{"host": "c"}, map[string]interface{}{"count": int64(5)}, now),
+		// Zero denominator
+		metric.New("http_requests", map[string]string{"service": "db"}, map[string]interface{}{"count": int64(0)}, now),
+		metric.New("http_errors", map[string]string{"service": "db"}, map[string]interface{}{"count": int64(1)}, now),
+		// Non-numeric value
+		metric.New("http_errors", map[string]string{"service": "api"}, map[string]interface{}{"count": "many"}, now),
+		// Unrelated measurement
+		metric.New("cpu", map[string]string{"service": "api"}, map[string]interface{}{"count": int64(1000)}, now),
+	}
+	for _, m := range input {
+		plugin.Add(m)
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("http_error_ratio", map[string]string{"service": "api"}, map[string]interface{}{"ratio": 0.01}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// The values must not be carried over to the next period
+	plugin.Reset()
+	acc.ClearMetrics()
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestAggregation(t *testing.T) {
+	tests := []struct {
+		aggregation string
+		scale       float64
+		expected    float64
+	}{
+		{aggregation: "sum", expected: 15.0 / 30.0},
+		{aggregation: "mean", expected: 7.5 / 15.0},
+		{aggregation: "last", expected: 10.0 / 5.0},
+		{aggregation: "last", scale: 100, expected: 200.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.aggregation, func(t *testing.T) {
+			// Numerator and denominator are fields of the same measurement
+			plugin := &Ratio{
+				Ratios: []*ratio{
+					{
+						Measurement:            "disk_usage",
+						Field:                  "used_ratio",
+						NumeratorMeasurement:   "disk",
+						NumeratorField:         "used",
+						DenominatorMeasurement: "disk",
+						DenominatorField:       "total",
+						Aggregation:            tt.aggregation,
+						Scale:                  tt.scale,
+					},
+				},
+				Log: testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			now := time.Now()
+			plugin.Add(metric.New("disk", map[string]string{}, map[string]interface{}{"used": 5.0, "total": 25.0}, now))
+			plugin.Add(metric.New("disk", map[string]string{}, map[string]interface{}{"used": 10.0, "total": 5.0}, now))
+
+			var acc testutil.Accumulator
+			plugin.Push(&acc)
+
+			expected := []telegraf.Metric{
+				metric.New("disk_usage", map[string]string{}, map[string]interface{}{"used_ratio": tt.expected}, time.Unix(0, 0)),
+			}
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
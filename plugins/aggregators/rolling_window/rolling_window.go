@@ -0,0 +1,204 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package rolling_window
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type RollingWindow struct {
+	WindowSize    int             `toml:"window_size"`
+	Alpha         float64         `toml:"alpha"`
+	Stats         []string        `toml:"stats"`
+	SeriesTimeout config.Duration `toml:"series_timeout"`
+	Log           telegraf.Logger `toml:"-"`
+
+	movingAvg   bool
+	movingStdev bool
+	ewma        bool
+
+	cache map[uint64]*series
+	now   func() time.Time
+}
+
+// series keeps the windows of all fields of a series across periods
+type series struct {
+	name     string
+	tags     map[string]string
+	fields   map[string]*window
+	updated  bool
+	lastSeen time.Time
+}
+
+// window is a ring-buffer of the latest values of a field together with the
+// exponentially weighted moving average of all values
+type window struct {
+	values []float64
+	next   int
+	count  int
+	ewma   float64
+}
+
+func (*RollingWindow) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *RollingWindow) Init() error {
+	if r.WindowSize < 1 {
+		return errors.New("window_size must be positive")
+	}
+	if r.Alpha <= 0 || r.Alpha > 1 {
+		return errors.New("alpha must be in the range (0, 1]")
+	}
+
+	if len(r.Stats) == 0 {
+		r.Stats = []string{"moving_avg", "moving_stdev", "ewma"}
+	}
+	for _, s := range r.Stats {
+		switch s {
+		case "moving_avg":
+			r.movingAvg = true
+		case "moving_stdev":
+			r.movingStdev = true
+		case "ewma":
+			r.ewma = true
+		default:
+			return fmt.Errorf("unknown statistic %q", s)
+		}
+	}
+
+	r.cache = make(map[uint64]*series)
+	if r.now == nil {
+		r.now = time.Now
+	}
+
+	return nil
+}
+
+func (r *RollingWindow) Add(in telegraf.Metric) {
+	id := in.HashID()
+	s, found := r.cache[id]
+	if !found {
+		s = &series{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]*window),
+		}
+		r.cache[id] = s
+	}
+	s.updated = true
+	s.lastSeen = r.now()
+
+	for _, field := range in.FieldList() {
+		fv, ok := convert(field.Value)
+		if !ok {
+			continue
+		}
+		w, found := s.fields[field.Key]
+		if !found {
+			w = &window{values: make([]float64, r.WindowSize)}
+			s.fields[field.Key] = w
+		}
+		w.add(fv, r.Alpha)
+	}
+}
+
+func (r *RollingWindow) Push(acc telegraf.Accumulator) {
+	for _, s := range r.cache {
+		// Only emit series that received new values in this period
+		if !s.updated {
+			continue
+		}
+
+		fields := make(map[string]interface{}, len(s.fields)*len(r.Stats))
+		for k, w := range s.fields {
+			mean := w.mean()
+			if r.movingAvg {
+				fields[k+"_moving_avg"] = mean
+			}
+			if r.movingStdev && w.count > 1 {
+				fields[k+"_moving_stdev"] = w.stdev(mean)
+			}
+			if r.ewma {
+				fields[k+"_ewma"] = w.ewma
+			}
+		}
+		if len(fields) > 0 {
+			acc.AddFields(s.name, fields, s.tags)
+		}
+	}
+}
+
+func (r *RollingWindow) Reset() {
+	// Keep the windows across periods but forget series not seen for longer
+	// than the timeout to not accumulate stale series
+	now := r.now()
+	for id, s := range r.cache {
+		s.updated = false
+		if r.SeriesTimeout > 0 && now.Sub(s.lastSeen) > time.Duration(r.SeriesTimeout) {
+			delete(r.cache, id)
+		}
+	}
+}
+
+func (w *window) add(v, alpha float64) {
+	if w.count == 0 {
+		w.ewma = v
+	} else {
+		w.ewma = alpha*v + (1-alpha)*w.ewma
+	}
+
+	w.values[w.next] = v
+	w.next = (w.next + 1) % len(w.values)
+	w.count = min(w.count+1, len(w.values))
+}
+
+func (w *window) mean() float64 {
+	var sum float64
+	for _, v := range w.values[:w.count] {
+		sum += v
+	}
+	return sum / float64(w.count)
+}
+
+// stdev computes the sample standard deviation of the values in the window
+func (w *window) stdev(mean float64) float64 {
+	var sum float64
+	for _, v := range w.values[:w.count] {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(w.count-1))
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("rolling_window", func() telegraf.Aggregator {
+		return &RollingWindow{
+			WindowSize:    10,
+			Alpha:         0.3,
+			SeriesTimeout: config.Duration(10 * time.Minute),
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package rolling_window
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *RollingWindow
+		expected string
+	}{
+		{
+			name:     "invalid window size",
+			plugin:   &RollingWindow{Alpha: 0.3},
+			expected: "window_size must be positive",
+		},
+		{
+			name:     "invalid alpha",
+			plugin:   &RollingWindow{WindowSize: 3, Alpha: 1.5},
+			expected: "alpha must be in the range (0, 1]",
+		},
+		{
+			name:     "unknown statistic",
+			plugin:   &RollingWindow{WindowSize: 3, Alpha: 0.3, Stats: []string{"foo"}},
+			expected: `unknown statistic "foo"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWindowAcrossPeriods(t *testing.T) {
+	plugin := &RollingWindow{
+		WindowSize: 3,
+		Alpha:      0.3,
+	}
+	require.NoError(t, plugin.Init())
+
+	tags := map[string]string{"host": "tars"}
+	acc := &testutil.Accumulator{}
+
+	// First period
+	for _, v := range []float64{1.2, 1.8, 1.5} {
+		plugin.Add(metric.New("system", tags, map[string]interface{}{"load1": v, "status": "ok"}, time.Unix(0, 0)))
+	}
+	plugin.Push(acc)
+	plugin.Reset()
+
+	// Second period with the oldest value dropping out of the window
+	plugin.Add(metric.New("system", tags, map[string]interface{}{"load1": int64(3)}, time.Unix(0, 0)))
+	plugin.Push(acc)
+	plugin.Reset()
+
+	// Third period without any value
+	plugin.Push(acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"system",
+			tags,
+			map[string]interface{}{
+				"load1_moving_avg":   1.5,
+				"load1_moving_stdev": 0.3,
+				"load1_ewma":         1.416,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"system",
+			tags,
+			map[string]interface{}{
+				"load1_moving_avg":   2.1,
+				"load1_moving_stdev": 0.7937253933193772,
+				"load1_ewma":         1.8912,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		cmpopts.EquateApprox(0, 1e-9),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+}
+
+func TestSelectedStats(t *testing.T) {
+	plugin := &RollingWindow{
+		WindowSize: 5,
+		Alpha:      0.5,
+		Stats:      []string{"moving_stdev"},
+	}
+	require.NoError(t, plugin.Init())
+
+	// A single value does not produce a standard deviation and thus no metric
+	acc := &testutil.Accumulator{}
+	plugin.Add(metric.New("m", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)))
+	plugin.Push(acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	plugin.Add(metric.New("m", map[string]string{}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)))
+	plugin.Push(acc)
+
+	expected := []telegraf.Metric{
+		metric.New("m", map[string]string{}, map[string]interface{}{"value_moving_stdev": 1.4142135623730951}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestSeriesTimeout(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	plugin := &RollingWindow{
+		WindowSize:    3,
+		Alpha:         0.3,
+		SeriesTimeout: config.Duration(time.Minute),
+		now:           func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(metric.New("m1", map[string]string{}, map[string]interface{}{"value": 1.0}, now))
+	plugin.Add(metric.New("m2", map[string]string{}, map[string]interface{}{"value": 1.0}, now))
+	plugin.Reset()
+	require.Len(t, plugin.cache, 2)
+
+	now = now.Add(50 * time.Second)
+	plugin.Add(metric.New("m2", map[string]string{}, map[string]interface{}{"value": 1.0}, now))
+	now = now.Add(20 * time.Second)
+	plugin.Reset()
+	require.Len(t, plugin.cache, 1)
+}
@@ -0,0 +1,145 @@
+// Package ackwal provides a write-ahead log persisting the keys of messages
+// whose tracking metrics were delivered. Queue-consumer inputs use it to
+// detect messages re-delivered by the broker after the agent stopped before
+// the acknowledgement reached the broker.
+package ackwal
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tidwall/wal"
+)
+
+// WAL records delivered message keys keeping at most limit entries
+type WAL struct {
+	file  *wal.Log
+	path  string
+	limit uint64
+
+	delivered map[string]uint64 // Index of the last entry for each key
+	first     uint64
+	last      uint64
+
+	sync.Mutex
+}
+
+// Open opens or creates the log at the given path and restores the keys
+// recorded during previous runs
+func Open(path string, limit int) (*WAL, error) {
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+
+	file, err := wal.Open(path, &wal.Options{AllowEmpty: true})
+	if err != nil {
+		if errors.Is(err, wal.ErrCorrupt) {
+			return nil, fmt.Errorf("wal file is corrupt, you have to manually delete the wal at %q and restart Telegraf", path)
+		}
+		return nil, fmt.Errorf("opening wal file failed: %w", err)
+	}
+
+	w := &WAL{
+		file:      file,
+		path:      path,
+		limit:     uint64(limit),
+		delivered: make(map[string]uint64),
+	}
+
+	if w.first, err = file.FirstIndex(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("reading first index failed: %w", err)
+	}
+	if w.last, err = file.LastIndex(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("reading last index failed: %w", err)
+	}
+	if w.first == 0 {
+		// The log is empty, the first entry written will get index one
+		w.first = 1
+		return w, nil
+	}
+	for idx := w.first; idx <= w.last; idx++ {
+		data, err := file.Read(idx)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("reading entry %d failed: %w", idx, err)
+		}
+		w.delivered[string(data)] = idx
+	}
+
+	return w, nil
+}
+
+// Delivered persists the keys of messages whose metrics were delivered. This
+// has to be called before acknowledging the messages to the broker. The keys
+// are synced to disk at once, so all keys available should be passed in a
+// single call.
+func (w *WAL) Delivered(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	var batch wal.Batch
+	for i, key := range keys {
+		batch.Write(w.last+uint64(i)+1, []byte(key))
+	}
+	if err := w.file.WriteBatch(&batch); err != nil {
+		return fmt.Errorf("writing entries failed: %w", err)
+	}
+	for _, key := range keys {
+		w.last++
+		w.delivered[key] = w.last
+	}
+
+	// Drop the oldest entries once the log grew to twice the limit to avoid
+	// truncating on every write
+	if w.last-w.first+1 < 2*w.limit {
+		return nil
+	}
+	first := w.last - w.limit + 1
+	if err := w.file.TruncateFront(first); err != nil {
+		return fmt.Errorf("truncating wal failed: %w", err)
+	}
+	w.first = first
+	for k, i := range w.delivered {
+		if i < first {
+			delete(w.delivered, k)
+		}
+	}
+	return nil
+}
+
+// Seen checks if the message with the given key was delivered before and
+// forgets the key, i.e. the message should only be acknowledged to the
+// broker but not processed again.
+func (w *WAL) Seen(key string) bool {
+	w.Lock()
+	defer w.Unlock()
+
+	if _, found := w.delivered[key]; !found {
+		return false
+	}
+	delete(w.delivered, key)
+	return true
+}
+
+// Len returns the number of keys currently tracked
+func (w *WAL) Len() int {
+	w.Lock()
+	defer w.Unlock()
+
+	return len(w.delivered)
+}
+
+// Close syncs and closes the underlying log
+func (w *WAL) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	return w.file.Close()
+}
@@ -0,0 +1,83 @@
+package ackwal
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acks")
+
+	w, err := Open(path, 100)
+	require.NoError(t, err)
+	require.NoError(t, w.Delivered("topic/0/1"))
+	require.NoError(t, w.Delivered("topic/0/2"))
+	require.NoError(t, w.Close())
+
+	// Reopen the log as it would happen on agent restart
+	w, err = Open(path, 100)
+	require.NoError(t, err)
+	defer w.Close()
+
+	require.Equal(t, 2, w.Len())
+	require.True(t, w.Seen("topic/0/1"))
+	require.False(t, w.Seen("topic/0/1"))
+	require.False(t, w.Seen("topic/0/3"))
+	require.True(t, w.Seen("topic/0/2"))
+	require.Zero(t, w.Len())
+
+	// Writing must continue after the restored entries
+	require.NoError(t, w.Delivered("topic/0/3"))
+	require.True(t, w.Seen("topic/0/3"))
+}
+
+func TestLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acks")
+
+	w, err := Open(path, 5)
+	require.NoError(t, err)
+	for i := range 10 {
+		require.NoError(t, w.Delivered(strconv.Itoa(i)))
+	}
+	require.Equal(t, 5, w.Len())
+	require.NoError(t, w.Close())
+
+	w, err = Open(path, 5)
+	require.NoError(t, err)
+	defer w.Close()
+
+	require.Equal(t, 5, w.Len())
+	require.False(t, w.Seen("4"))
+	for i := 5; i < 10; i++ {
+		require.True(t, w.Seen(strconv.Itoa(i)))
+	}
+}
+
+func TestBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acks")
+	w, err := Open(path, 5)
+	require.NoError(t, err)
+	require.NoError(t, w.Delivered())
+	require.NoError(t, w.Delivered("0", "1", "2"))
+	require.NoError(t, w.Delivered("3", "4", "5", "6", "7", "8", "9"))
+	require.Equal(t, 5, w.Len())
+	require.NoError(t, w.Close())
+
+	w, err = Open(path, 5)
+	require.NoError(t, err)
+	defer w.Close()
+
+	require.Equal(t, 5, w.Len())
+	require.False(t, w.Seen("4"))
+	for i := 5; i < 10; i++ {
+		require.True(t, w.Seen(strconv.Itoa(i)))
+	}
+}
+
+func TestInvalidLimit(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "acks"), 0)
+	require.ErrorContains(t, err, "limit must be positive")
+}
@@ -26,6 +26,9 @@ const (
 	maxBuffers       = 5
 	ManagedIngestion = "managed"
 	QueuedIngestion  = "queued"
+	// Authentication methods
+	DefaultAuth         = "default"
+	ManagedIdentityAuth = "managed_identity"
 )
 
 type Config struct {
@@ -38,6 +41,12 @@ type Config struct {
 	IngestionType   string          `toml:"ingestion_type"`
 }
 
+// AuthConfig selects the credentials used by the client
+type AuthConfig struct {
+	AuthMethod string `toml:"auth_method"`
+	ClientID   string `toml:"managed_identity_client_id"`
+}
+
 type Client struct {
 	cfg       *Config
 	conn      *azkustodata.ConnectionStringBuilder
@@ -46,7 +55,9 @@ type Client struct {
 	logger    telegraf.Logger
 }
 
-func (cfg *Config) NewClient(app string, log telegraf.Logger) (*Client, error) {
+// NewClient creates a client using the given authentication settings or, if
+// nil, the default Azure credentials
+func (cfg *Config) NewClient(app string, auth *AuthConfig, log telegraf.Logger) (*Client, error) {
 	if cfg.Endpoint == "" {
 		return nil, errors.New("endpoint configuration cannot be empty")
 	}
@@ -80,7 +91,22 @@ func (cfg *Config) NewClient(app string, log telegraf.Logger) (*Client, error) {
 		return nil, fmt.Errorf("unknown ingestion type %q", cfg.IngestionType)
 	}
 
-	conn := azkustodata.NewConnectionStringBuilder(cfg.Endpoint).WithDefaultAzureCredential()
+	if auth == nil {
+		auth = &AuthConfig{}
+	}
+	conn := azkustodata.NewConnectionStringBuilder(cfg.Endpoint)
+	switch auth.AuthMethod {
+	case "", DefaultAuth:
+		conn = conn.WithDefaultAzureCredential()
+	case ManagedIdentityAuth:
+		if auth.ClientID != "" {
+			conn = conn.WithUserManagedIdentity(auth.ClientID)
+		} else {
+			conn = conn.WithSystemManagedIdentity()
+		}
+	default:
+		return nil, fmt.Errorf("unknown authentication method %q", auth.AuthMethod)
+	}
 	conn.SetConnectorDetails("Telegraf", internal.ProductToken(), app, "", false, "")
 	client, err := azkustodata.New(conn)
 	if err != nil {
@@ -23,11 +23,21 @@ func TestInitBlankEndpointData(t *testing.T) {
 		Database: "mydb",
 	}
 
-	_, err := plugin.NewClient("TestKusto.Telegraf", nil)
+	_, err := plugin.NewClient("TestKusto.Telegraf", nil, nil)
 	require.Error(t, err)
 	require.Equal(t, "endpoint configuration cannot be empty", err.Error())
 }
 
+func TestInitInvalidAuthMethod(t *testing.T) {
+	plugin := Config{
+		Endpoint: "https://someendpoint.kusto.net",
+		Database: "mydb",
+	}
+
+	_, err := plugin.NewClient("TestKusto.Telegraf", &AuthConfig{AuthMethod: "foo"}, nil)
+	require.ErrorContains(t, err, `unknown authentication method "foo"`)
+}
+
 func TestQueryConstruction(t *testing.T) {
 	const tableName = "mytable"
 	const expectedCreate = `.create-merge table ['mytable'] (['fields']:dynamic, ['name']:string, ['tags']:dynamic, ['timestamp']:datetime);`
@@ -148,7 +158,7 @@ func TestPushMetricsOutputs(t *testing.T) {
 				IngestionType:   ingestionType,
 				Timeout:         config.Duration(20 * time.Second),
 			}
-			client, err := cfg.NewClient("telegraf", &testutil.Logger{})
+			client, err := cfg.NewClient("telegraf", nil, &testutil.Logger{})
 			require.NoError(t, err)
 
 			// Inject the ingestor
@@ -186,7 +196,7 @@ func TestAlreadyClosed(t *testing.T) {
 		Database:      "test",
 		IngestionType: QueuedIngestion,
 	}
-	plugin, err := cfg.NewClient("test", testutil.Logger{})
+	plugin, err := cfg.NewClient("test", nil, testutil.Logger{})
 	require.NoError(t, err)
 	require.NoError(t, plugin.Close())
 	require.NoError(t, plugin.Close())
@@ -0,0 +1,18 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+)
+
+// RegionFromIMDS determines the region of the EC2 instance Telegraf is
+// running on using the instance metadata service
+func RegionFromIMDS(ctx context.Context) (string, error) {
+	client := imds.New(imds.Options{})
+	out, err := client.GetRegion(ctx, &imds.GetRegionInput{})
+	if err != nil {
+		return "", err
+	}
+	return out.Region, nil
+}
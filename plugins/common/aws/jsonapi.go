@@ -0,0 +1,124 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// JSONAPIClient calls actions of AWS services using the JSON protocol, e.g.
+// of Secrets Manager or the SSM Parameter Store, without requiring the
+// service specific SDK. Requests are signed using the credentials of the
+// given configuration.
+type JSONAPIClient struct {
+	// Name of the service used for signing and the default endpoint,
+	// e.g. "secretsmanager"
+	Service string
+	// Prefix of the action in the target header, e.g. "secretsmanager"
+	TargetPrefix string
+	// Endpoint of the service, defaults to the regional endpoint
+	Endpoint string
+
+	config aws.Config
+	client *http.Client
+	signer *v4.Signer
+}
+
+// APIError is an error returned by the AWS service
+type APIError struct {
+	StatusCode int
+	Type       string `json:"__type"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	// The type might be prefixed by a namespace, e.g. for Secrets Manager
+	name := e.Type
+	if idx := strings.LastIndex(name, "#"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	if e.Message == "" {
+		return fmt.Sprintf("%s (status %d)", name, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s (status %d)", name, e.Message, e.StatusCode)
+}
+
+// NewJSONAPIClient creates a client for the given service
+func NewJSONAPIClient(cfg aws.Config, service, targetPrefix, endpoint string, timeout time.Duration) *JSONAPIClient {
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", service, cfg.Region)
+		if strings.HasPrefix(cfg.Region, "cn-") {
+			endpoint += ".cn"
+		}
+	}
+	return &JSONAPIClient{
+		Service:      service,
+		TargetPrefix: targetPrefix,
+		Endpoint:     strings.TrimSuffix(endpoint, "/"),
+		config:       cfg,
+		client:       &http.Client{Timeout: timeout},
+		signer:       v4.NewSigner(),
+	}
+}
+
+// Call executes the given action with the input serialized as JSON and
+// decodes the response into the output
+func (c *JSONAPIClient) Call(ctx context.Context, action string, input, output interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("encoding request failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", c.TargetPrefix+"."+action)
+
+	if c.config.Credentials == nil {
+		return fmt.Errorf("no credentials available for %s", c.Service)
+	}
+	creds, err := c.config.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieving credentials failed: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), c.Service, c.config.Region, time.Now()); err != nil {
+		return fmt.Errorf("signing request failed: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(data, apiErr); err != nil {
+			apiErr.Message = string(data)
+		}
+		return apiErr
+	}
+
+	if err := json.Unmarshal(data, output); err != nil {
+		return fmt.Errorf("decoding response failed: %w", err)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	paho "github.com/eclipse/paho.mqtt.golang"
 
@@ -15,11 +16,29 @@ import (
 // PublishProperties for mqtt v5-specific properties.
 // See https://docs.oasis-open.org/mqtt/mqtt/v5.0/os/mqtt-v5.0-os.html#_Toc3901109
 type PublishProperties struct {
-	ContentType    string            `toml:"content_type"`
-	ResponseTopic  string            `toml:"response_topic"`
-	MessageExpiry  config.Duration   `toml:"message_expiry"`
-	TopicAlias     *uint16           `toml:"topic_alias"`
-	UserProperties map[string]string `toml:"user_properties"`
+	ContentType          string            `toml:"content_type"`
+	ResponseTopic        string            `toml:"response_topic"`
+	MessageExpiry        config.Duration   `toml:"message_expiry"`
+	ExpiryFromMetricTime bool              `toml:"expiry_from_metric_time"`
+	TopicAlias           *uint16           `toml:"topic_alias"`
+	AutoTopicAlias       bool              `toml:"auto_topic_alias"`
+	UserProperties       map[string]string `toml:"user_properties"`
+	UserPropertyTags     []string          `toml:"user_property_tags"`
+}
+
+// MessageProperties are MQTT v5 properties of a single message extending
+// the configured publish properties
+type MessageProperties struct {
+	// UserProperties are added to the configured user properties
+	UserProperties map[string]string
+	// MessageExpiry overrides the configured message expiry if positive
+	MessageExpiry time.Duration
+}
+
+// PropertiesPublisher is implemented by clients supporting properties for
+// individual messages
+type PropertiesPublisher interface {
+	PublishWithProperties(topic string, data []byte, props *MessageProperties) error
 }
 
 type MqttConfig struct {
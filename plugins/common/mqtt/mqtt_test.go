@@ -2,8 +2,11 @@ package mqtt
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
 )
 
 // Test that default client has random ID
@@ -24,3 +27,103 @@ func TestRandomClientID(t *testing.T) {
 	options2 := client2.client.OptionsReader()
 	require.NotEqual(t, options1.ClientID(), options2.ClientID())
 }
+
+func TestTopicAliases(t *testing.T) {
+	var aliases topicAliases
+	aliases.reset(2)
+
+	alias, known, ok := aliases.lookup("a")
+	require.True(t, ok)
+	require.False(t, known)
+	require.Equal(t, uint16(1), alias)
+	aliases.announce("a")
+
+	alias, known, ok = aliases.lookup("b")
+	require.True(t, ok)
+	require.False(t, known)
+	require.Equal(t, uint16(2), alias)
+	aliases.announce("b")
+
+	// Known topics reuse their alias
+	alias, known, ok = aliases.lookup("a")
+	require.True(t, ok)
+	require.True(t, known)
+	require.Equal(t, uint16(1), alias)
+
+	// No alias is available after reaching the maximum
+	_, _, ok = aliases.lookup("c")
+	require.False(t, ok)
+
+	// Aliases are not kept across connections
+	aliases.reset(0)
+	_, _, ok = aliases.lookup("a")
+	require.False(t, ok)
+}
+
+func TestTopicAliasesFailedPublish(t *testing.T) {
+	var aliases topicAliases
+	aliases.reset(2)
+
+	// The first publish fails so the alias is not announced
+	alias, known, ok := aliases.lookup("a")
+	require.True(t, ok)
+	require.False(t, known)
+	require.Equal(t, uint16(1), alias)
+
+	// The topic must be sent again with the same alias
+	alias, known, ok = aliases.lookup("a")
+	require.True(t, ok)
+	require.False(t, known)
+	require.Equal(t, uint16(1), alias)
+	aliases.announce("a")
+
+	alias, known, ok = aliases.lookup("a")
+	require.True(t, ok)
+	require.True(t, known)
+	require.Equal(t, uint16(1), alias)
+
+	// Announcements are not kept across connections
+	aliases.reset(2)
+	_, known, ok = aliases.lookup("a")
+	require.True(t, ok)
+	require.False(t, known)
+}
+
+func TestAutoTopicAliasConflict(t *testing.T) {
+	cfg := &MqttConfig{
+		Servers: []string{"tcp://localhost:1883"},
+		PublishPropertiesV5: &PublishProperties{
+			TopicAlias:     new(uint16),
+			AutoTopicAlias: true,
+		},
+	}
+	_, err := NewMQTTv5Client(cfg)
+	require.ErrorContains(t, err, "topic_alias and auto_topic_alias cannot be used together")
+}
+
+func TestMessageProperties(t *testing.T) {
+	cfg := &MqttConfig{
+		Servers: []string{"tcp://localhost:1883"},
+		PublishPropertiesV5: &PublishProperties{
+			ContentType:    "text/plain",
+			MessageExpiry:  config.Duration(time.Hour),
+			UserProperties: map[string]string{"source": "telegraf"},
+		},
+	}
+	client, err := NewMQTTv5Client(cfg)
+	require.NoError(t, err)
+
+	props := client.messageProperties(&MessageProperties{
+		UserProperties: map[string]string{"site": "a", "host": "h1"},
+		MessageExpiry:  10 * time.Minute,
+	})
+	require.Equal(t, "text/plain", props.ContentType)
+	require.Equal(t, uint32(600), *props.MessageExpiry)
+	require.Equal(t, "telegraf", props.User.Get("source"))
+	require.Equal(t, "h1", props.User.Get("host"))
+	require.Equal(t, "a", props.User.Get("site"))
+
+	// The configured properties must not be modified
+	require.Equal(t, uint32(3600), *client.properties.MessageExpiry)
+	require.Len(t, client.properties.User, 1)
+}
@@ -2,8 +2,12 @@ package mqtt
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"net/url"
+	"slices"
+	"sync"
 	"time"
 
 	mqttv5auto "github.com/eclipse/paho.golang/autopaho"
@@ -25,6 +29,7 @@ type mqttv5Client struct {
 	retain      bool
 	clientTrace bool
 	properties  *mqttv5.PublishProperties
+	aliases     *topicAliases
 }
 
 func NewMQTTv5Client(cfg *MqttConfig) (*mqttv5Client, error) {
@@ -77,7 +82,23 @@ func NewMQTTv5Client(cfg *MqttConfig) (*mqttv5Client, error) {
 	// Build the v5 specific publish properties if they are present in the config.
 	// These should not change during the lifecycle of the client.
 	var properties *mqttv5.PublishProperties
+	var aliases *topicAliases
 	if cfg.PublishPropertiesV5 != nil {
+		if cfg.PublishPropertiesV5.AutoTopicAlias {
+			if cfg.PublishPropertiesV5.TopicAlias != nil {
+				return nil, errors.New("topic_alias and auto_topic_alias cannot be used together")
+			}
+			aliases = &topicAliases{}
+			opts.OnConnectionUp = func(_ *mqttv5auto.ConnectionManager, connack *mqttv5.Connack) {
+				// Aliases are only valid for a single network connection
+				var maximum uint16
+				if connack.Properties != nil && connack.Properties.TopicAliasMaximum != nil {
+					maximum = *connack.Properties.TopicAliasMaximum
+				}
+				aliases.reset(maximum)
+			}
+		}
+
 		properties = &mqttv5.PublishProperties{
 			ContentType:   cfg.PublishPropertiesV5.ContentType,
 			ResponseTopic: cfg.PublishPropertiesV5.ResponseTopic,
@@ -103,6 +124,7 @@ func NewMQTTv5Client(cfg *MqttConfig) (*mqttv5Client, error) {
 		qos:         cfg.QoS,
 		retain:      cfg.Retain,
 		properties:  properties,
+		aliases:     aliases,
 		clientTrace: cfg.ClientTrace,
 	}, nil
 }
@@ -136,6 +158,29 @@ func (m *mqttv5Client) Connect() (bool, error) {
 }
 
 func (m *mqttv5Client) Publish(topic string, body []byte) error {
+	return m.PublishWithProperties(topic, body, nil)
+}
+
+func (m *mqttv5Client) PublishWithProperties(topic string, body []byte, props *MessageProperties) error {
+	properties := m.properties
+	if props != nil || m.aliases != nil {
+		properties = m.messageProperties(props)
+	}
+
+	// Send the topic until the alias was successfully announced to the
+	// broker, afterwards the alias is sufficient
+	var announce bool
+	if m.aliases != nil {
+		if alias, known, ok := m.aliases.lookup(topic); ok {
+			properties.TopicAlias = &alias
+			if known {
+				topic = ""
+			} else {
+				announce = true
+			}
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
 	defer cancel()
 
@@ -144,10 +189,37 @@ func (m *mqttv5Client) Publish(topic string, body []byte) error {
 		QoS:        byte(m.qos),
 		Retain:     m.retain,
 		Payload:    body,
-		Properties: m.properties,
+		Properties: properties,
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	if announce {
+		m.aliases.announce(topic)
+	}
+	return nil
+}
+
+// messageProperties merges the configured publish properties with the ones
+// of the message
+func (m *mqttv5Client) messageProperties(props *MessageProperties) *mqttv5.PublishProperties {
+	properties := &mqttv5.PublishProperties{}
+	if m.properties != nil {
+		*properties = *m.properties
+		properties.User = slices.Clone(m.properties.User)
+	}
+	if props == nil {
+		return properties
+	}
+
+	if expirySeconds := uint32(props.MessageExpiry.Seconds()); expirySeconds > 0 {
+		properties.MessageExpiry = &expirySeconds
+	}
+	for _, k := range slices.Sorted(maps.Keys(props.UserProperties)) {
+		properties.User.Add(k, props.UserProperties[k])
+	}
+	return properties
 }
 
 func (*mqttv5Client) SubscribeMultiple(filters map[string]byte, callback paho.MessageHandler) error {
@@ -163,3 +235,49 @@ func (*mqttv5Client) AddRoute(topic string, callback paho.MessageHandler) {
 func (m *mqttv5Client) Close() error {
 	return m.client.Disconnect(context.Background())
 }
+
+// topicAliases assigns topic aliases to topics up to the maximum number of
+// aliases accepted by the broker for the current connection
+type topicAliases struct {
+	maximum   uint16
+	aliases   map[string]uint16
+	announced map[string]bool
+	sync.Mutex
+}
+
+func (a *topicAliases) reset(maximum uint16) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.maximum = maximum
+	a.aliases = make(map[string]uint16)
+	a.announced = make(map[string]bool)
+}
+
+// lookup returns the alias for the given topic and if the alias was already
+// announced to the broker. No alias is available if the maximum is reached.
+func (a *topicAliases) lookup(topic string) (alias uint16, known, ok bool) {
+	a.Lock()
+	defer a.Unlock()
+
+	if alias, found := a.aliases[topic]; found {
+		return alias, a.announced[topic], true
+	}
+	if len(a.aliases) >= int(a.maximum) {
+		return 0, false, false
+	}
+	alias = uint16(len(a.aliases) + 1)
+	a.aliases[topic] = alias
+	return alias, false, true
+}
+
+// announce marks the alias of the given topic as known to the broker after
+// the topic was successfully published together with the alias
+func (a *topicAliases) announce(topic string) {
+	a.Lock()
+	defer a.Unlock()
+
+	if _, found := a.aliases[topic]; found {
+		a.announced[topic] = true
+	}
+}
@@ -16,6 +16,8 @@ import (
 var m sync.Mutex
 var once sync.Once
 var cache = make(map[string]bool)
+var loadedFolders = make(map[string]bool)
+var loadedModules = make(map[string]bool)
 
 type MibLoader interface {
 	// appendPath takes the path of a directory
@@ -50,43 +52,87 @@ func LoadMibsFromPath(paths []string, log telegraf.Logger, loader MibLoader) err
 		return err
 	}
 	for _, path := range folders {
+		loadFolder(path, log, loader, false)
+	}
+	return nil
+}
+
+// LoadNewMibsFromPath loads all modules in the given paths that were not loaded
+// before, e.g. because the files were added after startup, and returns the
+// number of newly loaded modules. Modified modules are not reloaded.
+func LoadNewMibsFromPath(paths []string, log telegraf.Logger, loader MibLoader) (int, error) {
+	once.Do(gosmi.Init)
+
+	var count int
+	for _, mibPath := range paths {
+		folders, err := walkFolders(mibPath, log)
+		if err != nil {
+			return count, err
+		}
+		for _, path := range folders {
+			count += loadFolder(path, log, loader, true)
+		}
+	}
+	return count, nil
+}
+
+// loadFolder loads the modules in the given folder and returns the number of
+// loaded modules. If onlyNew is set, modules loaded before are skipped.
+func loadFolder(path string, log telegraf.Logger, loader MibLoader, onlyNew bool) int {
+	m.Lock()
+	known := loadedFolders[path]
+	loadedFolders[path] = true
+	m.Unlock()
+	if !onlyNew || !known {
 		loader.appendPath(path)
-		modules, err := os.ReadDir(path)
+	}
+
+	modules, err := os.ReadDir(path)
+	if err != nil {
+		log.Warnf("Can't read directory %v", modules)
+		return 0
+	}
+
+	var count int
+	for _, entry := range modules {
+		info, err := entry.Info()
 		if err != nil {
-			log.Warnf("Can't read directory %v", modules)
+			log.Warnf("Couldn't get info for %v: %v", entry.Name(), err)
 			continue
 		}
-
-		for _, entry := range modules {
-			info, err := entry.Info()
+		filename := filepath.Join(path, info.Name())
+		m.Lock()
+		loaded := loadedModules[filename]
+		m.Unlock()
+		if onlyNew && loaded {
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := filepath.EvalSymlinks(filename)
 			if err != nil {
-				log.Warnf("Couldn't get info for %v: %v", entry.Name(), err)
+				log.Warnf("Couldn't evaluate symbolic links for %v: %v", filename, err)
 				continue
 			}
-			if info.Mode()&os.ModeSymlink != 0 {
-				symlink := filepath.Join(path, info.Name())
-				target, err := filepath.EvalSymlinks(symlink)
-				if err != nil {
-					log.Warnf("Couldn't evaluate symbolic links for %v: %v", symlink, err)
-					continue
-				}
-				// replace symlink's info with the target's info
-				info, err = os.Lstat(target)
-				if err != nil {
-					log.Warnf("Couldn't stat target %v: %v", target, err)
-					continue
-				}
+			// replace symlink's info with the target's info
+			info, err = os.Lstat(target)
+			if err != nil {
+				log.Warnf("Couldn't stat target %v: %v", target, err)
+				continue
 			}
-			if info.Mode().IsRegular() {
-				err := loader.loadModule(info.Name())
-				if err != nil {
-					log.Warnf("Couldn't load module %v: %v", info.Name(), err)
-					continue
-				}
+		}
+		if info.Mode().IsRegular() {
+			err := loader.loadModule(info.Name())
+			if err != nil {
+				log.Warnf("Couldn't load module %v: %v", info.Name(), err)
+				continue
 			}
+			m.Lock()
+			loadedModules[filename] = true
+			m.Unlock()
+			count++
 		}
 	}
-	return nil
+	return count
 }
 
 // should walk the paths given and find all folders
@@ -104,37 +150,48 @@ func walkPaths(paths []string, log telegraf.Logger) ([]string, error) {
 			continue
 		}
 
-		err := filepath.Walk(mibPath, func(path string, info os.FileInfo, err error) error {
-			if info == nil {
-				log.Warnf("No mibs found")
-				if os.IsNotExist(err) {
-					log.Warnf("MIB path doesn't exist: %q", mibPath)
-				} else if err != nil {
-					return err
-				}
-				return nil
+		found, err := walkFolders(mibPath, log)
+		folders = append(folders, found...)
+		if err != nil {
+			return folders, err
+		}
+	}
+	return folders, nil
+}
+
+// walkFolders returns the given path and all folders below it
+func walkFolders(mibPath string, log telegraf.Logger) ([]string, error) {
+	folders := make([]string, 0)
+	err := filepath.Walk(mibPath, func(path string, info os.FileInfo, err error) error {
+		if info == nil {
+			log.Warnf("No mibs found")
+			if os.IsNotExist(err) {
+				log.Warnf("MIB path doesn't exist: %q", mibPath)
+			} else if err != nil {
+				return err
 			}
+			return nil
+		}
 
-			if info.Mode()&os.ModeSymlink != 0 {
-				target, err := filepath.EvalSymlinks(path)
-				if err != nil {
-					log.Warnf("Couldn't evaluate symbolic links for %v: %v", path, err)
-				}
-				info, err = os.Lstat(target)
-				if err != nil {
-					log.Warnf("Couldn't stat target %v: %v", target, err)
-				}
-				path = target
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				log.Warnf("Couldn't evaluate symbolic links for %v: %v", path, err)
 			}
-			if info.IsDir() {
-				folders = append(folders, path)
+			info, err = os.Lstat(target)
+			if err != nil {
+				log.Warnf("Couldn't stat target %v: %v", target, err)
 			}
-
-			return nil
-		})
-		if err != nil {
-			return folders, fmt.Errorf("couldn't walk path %q: %w", mibPath, err)
+			path = target
+		}
+		if info.IsDir() {
+			folders = append(folders, path)
 		}
+
+		return nil
+	})
+	if err != nil {
+		return folders, fmt.Errorf("couldn't walk path %q: %w", mibPath, err)
 	}
 	return folders, nil
 }
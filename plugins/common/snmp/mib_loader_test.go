@@ -1,6 +1,7 @@
 package snmp
 
 import (
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -85,3 +86,28 @@ func BenchmarkMibLoading(b *testing.B) {
 		require.NoError(b, LoadMibsFromPath(path, log, &GosmiMibLoader{}))
 	}
 }
+
+func TestLoadNewMibsFromPath(t *testing.T) {
+	path := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(path, "first"), nil, 0600))
+
+	loader := TestingMibLoader{}
+	count, err := LoadNewMibsFromPath([]string{path}, testutil.Logger{}, &loader)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, []string{path}, loader.folders)
+	require.Equal(t, []string{"first"}, loader.files)
+
+	// Nothing changed so nothing should be loaded
+	count, err = LoadNewMibsFromPath([]string{path}, testutil.Logger{}, &loader)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	// Only the added module should be loaded
+	require.NoError(t, os.WriteFile(filepath.Join(path, "second"), nil, 0600))
+	count, err = LoadNewMibsFromPath([]string{path}, testutil.Logger{}, &loader)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, []string{path}, loader.folders)
+	require.Equal(t, []string{"first", "second"}, loader.files)
+}
@@ -218,6 +218,10 @@ func TrapLookup(oid string) (e MibEntry, err error) {
 		return e, fmt.Errorf("could not convert OID %s: %w", oid, err)
 	}
 
+	// Modules might be loaded concurrently when hot-loading MIBs
+	m.Lock()
+	defer m.Unlock()
+
 	// Get node name
 	var node gosmi.SmiNode
 	if node, err = gosmi.GetNodeByOID(givenOid); err != nil {
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.envoy
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/envoy" // register plugin
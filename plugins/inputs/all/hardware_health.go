@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.hardware_health
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/hardware_health" // register plugin
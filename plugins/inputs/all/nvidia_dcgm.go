@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.nvidia_dcgm
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/nvidia_dcgm" // register plugin
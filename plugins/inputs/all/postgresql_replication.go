@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.postgresql_replication
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/postgresql_replication" // register plugin
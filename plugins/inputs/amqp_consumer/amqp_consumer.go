@@ -3,10 +3,15 @@ package amqp_consumer
 
 import (
 	"context"
+	"crypto/sha256"
 	_ "embed"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"maps"
 	"math/rand"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,6 +21,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/ackwal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -51,12 +57,14 @@ type AMQPConsumer struct {
 	ContentEncoding        string                 `toml:"content_encoding"`
 	MaxDecompressionSize   config.Size            `toml:"max_decompression_size"`
 	Timeout                config.Duration        `toml:"timeout"`
+	TrackingWAL            string                 `toml:"tracking_wal"`
 	Log                    telegraf.Logger        `toml:"-"`
 	tls.ClientConfig
 
 	deliveries map[telegraf.TrackingID]amqp.Delivery
 
 	parser  telegraf.Parser
+	wal     *ackwal.WAL
 	conn    *amqp.Connection
 	wg      *sync.WaitGroup
 	cancel  context.CancelFunc
@@ -129,8 +137,16 @@ func (a *AMQPConsumer) Start(acc telegraf.Accumulator) error {
 		return err
 	}
 
+	if a.TrackingWAL != "" {
+		a.wal, err = ackwal.Open(a.TrackingWAL, 2*a.MaxUndeliveredMessages)
+		if err != nil {
+			return fmt.Errorf("opening tracking wal failed: %w", err)
+		}
+	}
+
 	msgs, err := a.connect(amqpConf)
 	if err != nil {
+		a.closeWAL()
 		return err
 	}
 
@@ -178,6 +194,8 @@ func (*AMQPConsumer) Gather(_ telegraf.Accumulator) error {
 }
 
 func (a *AMQPConsumer) Stop() {
+	defer a.closeWAL()
+
 	// We did not connect successfully so there is nothing to do here.
 	if a.conn == nil || a.conn.IsClosed() {
 		return
@@ -191,6 +209,16 @@ func (a *AMQPConsumer) Stop() {
 	}
 }
 
+func (a *AMQPConsumer) closeWAL() {
+	if a.wal == nil {
+		return
+	}
+	if err := a.wal.Close(); err != nil {
+		a.Log.Errorf("Closing tracking wal failed: %v", err)
+	}
+	a.wal = nil
+}
+
 func (a *AMQPConsumer) createConfig() (*amqp.Config, error) {
 	// make new tls config
 	tlsCfg, err := a.ClientConfig.TLSConfig()
@@ -410,7 +438,7 @@ func (a *AMQPConsumer) process(ctx context.Context, msgs <-chan amqp.Delivery, a
 		case <-ctx.Done():
 			return
 		case track := <-acc.Delivered():
-			if a.onDelivery(track) {
+			for range a.onDeliveries(acc, track) {
 				<-sem
 			}
 		case sem <- empty{}:
@@ -418,14 +446,20 @@ func (a *AMQPConsumer) process(ctx context.Context, msgs <-chan amqp.Delivery, a
 			case <-ctx.Done():
 				return
 			case track := <-acc.Delivered():
-				if a.onDelivery(track) {
-					<-sem
+				if n := a.onDeliveries(acc, track); n > 0 {
 					<-sem
+					for range n {
+						<-sem
+					}
 				}
 			case d, ok := <-msgs:
 				if !ok {
 					return
 				}
+				if a.skipDelivered(d) {
+					<-sem
+					continue
+				}
 				err := a.onMessage(acc, d)
 				if err != nil {
 					acc.AddError(err)
@@ -468,6 +502,57 @@ func (a *AMQPConsumer) onMessage(acc telegraf.TrackingAccumulator, d amqp.Delive
 	return nil
 }
 
+// skipDelivered acknowledges messages delivered before a restart or reconnect
+// but not acknowledged to the broker in time
+func (a *AMQPConsumer) skipDelivered(d amqp.Delivery) bool {
+	if a.wal == nil || !d.Redelivered || !a.wal.Seen(walKey(d)) {
+		return false
+	}
+
+	a.Log.Debugf("Skipping already delivered message: %d", d.DeliveryTag)
+	if err := d.Ack(false); err != nil {
+		a.Log.Errorf("Unable to ack already delivered message: %d: %v", d.DeliveryTag, err)
+		a.conn.Close()
+	}
+	return true
+}
+
+// onDeliveries handles the given delivery together with all further deliveries
+// already available, so the delivered messages are recorded in the tracking
+// wal at once. It returns the number of messages handled.
+func (a *AMQPConsumer) onDeliveries(acc telegraf.TrackingAccumulator, track telegraf.DeliveryInfo) int {
+	tracks := []telegraf.DeliveryInfo{track}
+collect:
+	for {
+		select {
+		case track := <-acc.Delivered():
+			tracks = append(tracks, track)
+		default:
+			break collect
+		}
+	}
+
+	if a.wal != nil {
+		keys := make([]string, 0, len(tracks))
+		for _, track := range tracks {
+			if delivery, ok := a.deliveries[track.ID()]; ok && track.Delivered() {
+				keys = append(keys, walKey(delivery))
+			}
+		}
+		if err := a.wal.Delivered(keys...); err != nil {
+			a.Log.Errorf("Recording deliveries in tracking wal failed: %v", err)
+		}
+	}
+
+	var handled int
+	for _, track := range tracks {
+		if a.onDelivery(track) {
+			handled++
+		}
+	}
+	return handled
+}
+
 func (a *AMQPConsumer) onDelivery(track telegraf.DeliveryInfo) bool {
 	delivery, ok := a.deliveries[track.ID()]
 	if !ok {
@@ -493,6 +578,31 @@ func (a *AMQPConsumer) onDelivery(track telegraf.DeliveryInfo) bool {
 	return true
 }
 
+// walKey identifies the message in the tracking wal using the message ID
+// property or, if unset, a hash of the message including the properties set
+// by the publisher. The delivery tag cannot be used as it is only valid for
+// the channel and changes when the message is re-delivered on a new channel.
+func walKey(d amqp.Delivery) string {
+	if d.MessageId != "" {
+		return d.MessageId
+	}
+	h := sha256.New()
+	for _, s := range []string{d.Exchange, d.RoutingKey, d.CorrelationId, d.AppId, d.Type, d.UserId} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	if !d.Timestamp.IsZero() {
+		h.Write(strconv.AppendInt(nil, d.Timestamp.UnixNano(), 10))
+	}
+	h.Write([]byte{0})
+	for _, k := range slices.Sorted(maps.Keys(d.Headers)) {
+		fmt.Fprintf(h, "%s=%v", k, d.Headers[k])
+		h.Write([]byte{0})
+	}
+	h.Write(d.Body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func init() {
 	inputs.Add("amqp_consumer", func() telegraf.Input {
 		return &AMQPConsumer{Timeout: config.Duration(30 * time.Second)}
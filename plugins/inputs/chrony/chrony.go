@@ -35,6 +35,7 @@ type Chrony struct {
 	SocketGroup string          `toml:"socket_group"`
 	SocketPerms string          `toml:"socket_perms"`
 	Metrics     []string        `toml:"metrics"`
+	MaxAge      config.Duration `toml:"max_age"`
 	Log         telegraf.Logger `toml:"-"`
 
 	conn   net.Conn
@@ -92,6 +93,10 @@ func (c *Chrony) Init() error {
 		}
 	}
 
+	if c.MaxAge < 0 {
+		return errors.New("max_age must not be negative")
+	}
+
 	if c.SocketGroup == "" {
 		c.SocketGroup = "chrony"
 	}
@@ -300,6 +305,14 @@ func (c *Chrony) gatherTracking(acc telegraf.Accumulator) error {
 		"skew":            resp.SkewPPM,
 		"update_interval": resp.LastUpdateInterval,
 	}
+
+	// Raise the alarm if the clock was not updated for too long, e.g. due to
+	// all sources becoming unreachable, or is not synchronized at all
+	if c.MaxAge > 0 {
+		age := time.Since(resp.RefTime).Seconds()
+		fields["update_age"] = age
+		fields["stale"] = resp.LeapStatus == 3 || age > time.Duration(c.MaxAge).Seconds()
+	}
 	acc.AddFields("chrony", fields, tags)
 
 	return nil
@@ -463,6 +476,9 @@ func (c *Chrony) gatherSources(acc telegraf.Accumulator) error {
 			"latest_measurement":       sourceData.LatestMeas,
 			"latest_measurement_error": sourceData.LatestMeasErr,
 		}
+		if c.MaxAge > 0 {
+			fields["stale"] = float64(sourceData.SinceSample) > time.Duration(c.MaxAge).Seconds()
+		}
 		acc.AddFields("chrony_sources", fields, tags)
 	}
 	return nil
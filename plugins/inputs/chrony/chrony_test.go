@@ -17,6 +17,7 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -648,6 +649,88 @@ func TestGatherSourceStats(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, actual, options...)
 }
 
+func TestGatherFreshness(t *testing.T) {
+	// Setup a mock server with the last clock update ten minutes ago
+	server := Server{
+		TrackingInfo: &fbchrony.Tracking{
+			RefID:              0xA29FC87B,
+			IPAddr:             net.ParseIP("192.168.1.22"),
+			Stratum:            3,
+			LeapStatus:         0,
+			RefTime:            time.Now().Truncate(time.Second).Add(-10 * time.Minute),
+			LastUpdateInterval: 64.0,
+		},
+		SourcesInfo: []source{
+			{
+				name: "ntp1.my.org",
+				data: &fbchrony.SourceData{
+					IPAddr:       net.IPv4(192, 168, 0, 1),
+					Poll:         6,
+					Stratum:      2,
+					State:        fbchrony.SourceStateSync,
+					Mode:         fbchrony.SourceModePeer,
+					Reachability: 255,
+					SinceSample:  32,
+				},
+			},
+			{
+				name: "ntp2.my.org",
+				data: &fbchrony.SourceData{
+					IPAddr:       net.IPv4(192, 168, 0, 2),
+					Poll:         6,
+					Stratum:      2,
+					State:        fbchrony.SourceStateOutlier,
+					Mode:         fbchrony.SourceModePeer,
+					Reachability: 0,
+					SinceSample:  4000,
+				},
+			},
+		},
+	}
+	addr, err := server.Listen(t)
+	require.NoError(t, err)
+	defer server.Shutdown()
+
+	// Setup the plugin
+	plugin := &Chrony{
+		Server:  "udp://" + addr,
+		Metrics: []string{"tracking", "sources"},
+		MaxAge:  config.Duration(5 * time.Minute),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Start the plugin, do a gather and stop everything
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	plugin.Stop()
+	server.Shutdown()
+
+	// Only check the freshness fields
+	stale := make(map[string]interface{})
+	for _, m := range acc.GetTelegrafMetrics() {
+		v, found := m.GetField("stale")
+		require.True(t, found, "no stale field in %q", m.Name())
+		if m.Name() == "chrony" {
+			stale["tracking"] = v
+			age, found := m.GetField("update_age")
+			require.True(t, found)
+			require.InDelta(t, 600.0, age, 5.0)
+			continue
+		}
+		peer, _ := m.GetTag("peer")
+		stale[peer] = v
+	}
+	expected := map[string]interface{}{
+		"tracking":    true,
+		"ntp1.my.org": false,
+		"ntp2.my.org": true,
+	}
+	require.Equal(t, expected, stale)
+}
+
 func TestIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/djherbis/times"
+	"golang.org/x/exp/mmap"
 	"golang.org/x/sync/semaphore"
 
 	"github.com/influxdata/telegraf"
@@ -139,7 +140,7 @@ func (monitor *DirectoryMonitor) Init() error {
 		monitor.fileRegexesToIgnore = append(monitor.fileRegexesToIgnore, regex)
 	}
 
-	if err := choice.Check(monitor.ParseMethod, []string{"line-by-line", "at-once"}); err != nil {
+	if err := choice.Check(monitor.ParseMethod, []string{"line-by-line", "at-once", "memory-mapped"}); err != nil {
 		return fmt.Errorf("config option parse_method: %w", err)
 	}
 
@@ -299,16 +300,24 @@ func (monitor *DirectoryMonitor) read(filePath string) {
 }
 
 func (monitor *DirectoryMonitor) ingestFile(filePath string) error {
-	file, err := os.Open(filePath)
+	parser, err := monitor.parserFunc()
 	if err != nil {
-		return err
+		return fmt.Errorf("creating parser: %w", err)
 	}
-	defer file.Close()
 
-	parser, err := monitor.parserFunc()
+	// Gzipped files cannot be memory-mapped so fall back to reading them at
+	// once, the same applies to parsers not supporting random access.
+	if monitor.ParseMethod == "memory-mapped" {
+		if p, ok := parser.(telegraf.ReaderAtParser); ok && filepath.Ext(filePath) != ".gz" {
+			return monitor.parseMemoryMapped(p, filePath)
+		}
+	}
+
+	file, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Errorf("creating parser: %w", err)
+		return err
 	}
+	defer file.Close()
 
 	// Handle gzipped files.
 	var reader io.Reader
@@ -329,7 +338,7 @@ func (monitor *DirectoryMonitor) parseFile(parser telegraf.Parser, reader io.Rea
 
 	// Decide on how to split the file
 	switch monitor.ParseMethod {
-	case "at-once":
+	case "at-once", "memory-mapped":
 		return monitor.parseAtOnce(parser, reader, fileName)
 	case "line-by-line":
 		splitter = bufio.ScanLines
@@ -368,8 +377,28 @@ func (monitor *DirectoryMonitor) parseAtOnce(parser telegraf.Parser, reader io.R
 	return monitor.sendMetrics(metrics)
 }
 
-func (monitor *DirectoryMonitor) parseMetrics(parser telegraf.Parser, line []byte, fileName string) (metrics []telegraf.Metric, err error) {
-	metrics, err = parser.Parse(line)
+func (monitor *DirectoryMonitor) parseMemoryMapped(parser telegraf.ReaderAtParser, filePath string) error {
+	file, err := mmap.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	metrics, err := parser.ParseReaderAt(file, int64(file.Len()))
+	metrics, err = monitor.processMetrics(metrics, err, filePath)
+	if err != nil {
+		return err
+	}
+
+	return monitor.sendMetrics(metrics)
+}
+
+func (monitor *DirectoryMonitor) parseMetrics(parser telegraf.Parser, line []byte, fileName string) ([]telegraf.Metric, error) {
+	metrics, err := parser.Parse(line)
+	return monitor.processMetrics(metrics, err, fileName)
+}
+
+func (monitor *DirectoryMonitor) processMetrics(metrics []telegraf.Metric, err error, fileName string) ([]telegraf.Metric, error) {
 	if err != nil {
 		if errors.Is(err, parsers.ErrEOF) {
 			return nil, nil
@@ -12,8 +12,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -534,6 +536,61 @@ func TestParseCompleteFile(t *testing.T) {
 	testutil.RequireMetricEqual(t, testutil.TestMetric(100.1), acc.GetTelegrafMetrics()[0], testutil.IgnoreTime())
 }
 
+func TestParseMemoryMapped(t *testing.T) {
+	acc := testutil.Accumulator{}
+
+	// Establish process directory and finished directory.
+	finishedDirectory := t.TempDir()
+	processDirectory := t.TempDir()
+
+	// Init plugin.
+	r := DirectoryMonitor{
+		Directory:          processDirectory,
+		FinishedDirectory:  finishedDirectory,
+		MaxBufferedMetrics: defaultMaxBufferedMetrics,
+		FileQueueSize:      defaultFileQueueSize,
+		ParseMethod:        "memory-mapped",
+		FileTag:            "filename",
+	}
+	require.NoError(t, r.Init())
+	r.Log = testutil.Logger{}
+
+	r.SetParserFunc(func() (telegraf.Parser, error) {
+		parser := &influx.Parser{}
+		err := parser.Init()
+		return parser, err
+	})
+
+	// Write the file to process into the 'process' directory.
+	data := "test,tag1=value1 value=100.1 1700000000000000000\ntest,tag1=value2 value=42i 1700000000000000000\n"
+	require.NoError(t, os.WriteFile(filepath.Join(processDirectory, "test.influx"), []byte(data), 0600))
+
+	require.NoError(t, r.Start(&acc))
+	require.NoError(t, r.Gather(&acc))
+	acc.Wait(2)
+	r.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"tag1": "value1", "filename": "test.influx"},
+			map[string]interface{}{"value": 100.1},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"test",
+			map[string]string{"tag1": "value2", "filename": "test.influx"},
+			map[string]interface{}{"value": int64(42)},
+			time.Unix(1700000000, 0),
+		),
+	}
+	require.NoError(t, acc.FirstError())
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// The file must have been moved to the finished directory
+	require.FileExists(t, filepath.Join(finishedDirectory, "test.influx"))
+}
+
 func TestParseSubdirectories(t *testing.T) {
 	acc := testutil.Accumulator{}
 
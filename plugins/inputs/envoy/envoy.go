@@ -0,0 +1,303 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package envoy
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Built-in tag extraction rules applied after the user-defined ones, modeled
+// after the default tag extraction of Envoy
+var defaultTagRules = []tagRule{
+	{Tag: "cluster", Regex: `^cluster\.((.+?)\.)`},
+	{Tag: "listener", Regex: `^listener\.(((?:[_.[:digit:]]*|[_\[\]aAbBcCdDeEfF[:digit:]]*))\.)`},
+	{Tag: "stat_prefix", Regex: `^http\.((.*?)\.)`},
+}
+
+type Envoy struct {
+	URLs            []string        `toml:"urls"`
+	StatInclude     []string        `toml:"stat_include"`
+	StatExclude     []string        `toml:"stat_exclude"`
+	UsedOnly        bool            `toml:"used_only"`
+	HistogramValues string          `toml:"histogram_values"`
+	TagExtraction   []tagRule       `toml:"tag_extraction"`
+	Log             telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client *http.Client
+	filter filter.Filter
+	rules  []compiledTagRule
+}
+
+type tagRule struct {
+	Tag   string `toml:"tag"`
+	Regex string `toml:"regex"`
+}
+
+type compiledTagRule struct {
+	tag string
+	re  *regexp.Regexp
+}
+
+// Response of the stats endpoint in JSON format
+type statsResponse struct {
+	Stats []stat `json:"stats"`
+}
+
+type stat struct {
+	Name       string      `json:"name"`
+	Value      interface{} `json:"value"`
+	Histograms *histograms `json:"histograms"`
+}
+
+type histograms struct {
+	SupportedQuantiles []float64 `json:"supported_quantiles"`
+	ComputedQuantiles  []struct {
+		Name   string `json:"name"`
+		Values []struct {
+			Interval   *float64 `json:"interval"`
+			Cumulative *float64 `json:"cumulative"`
+		} `json:"values"`
+	} `json:"computed_quantiles"`
+}
+
+func (*Envoy) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Envoy) Init() error {
+	if len(e.URLs) == 0 {
+		e.URLs = []string{"http://localhost:9901/stats"}
+	}
+
+	switch e.HistogramValues {
+	case "":
+		e.HistogramValues = "cumulative"
+	case "cumulative", "interval":
+	default:
+		return fmt.Errorf("invalid histogram values %q", e.HistogramValues)
+	}
+
+	f, err := filter.NewIncludeExcludeFilter(e.StatInclude, e.StatExclude)
+	if err != nil {
+		return fmt.Errorf("creating stat filter failed: %w", err)
+	}
+	e.filter = f
+
+	e.rules = make([]compiledTagRule, 0, len(e.TagExtraction)+len(defaultTagRules))
+	for _, rule := range slices.Concat(e.TagExtraction, defaultTagRules) {
+		if rule.Tag == "" {
+			return errors.New("tag extraction rule without tag name")
+		}
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return fmt.Errorf("compiling regex of tag %q failed: %w", rule.Tag, err)
+		}
+		if re.NumSubexp() < 1 {
+			return fmt.Errorf("regex of tag %q requires at least one capture group", rule.Tag)
+		}
+		e.rules = append(e.rules, compiledTagRule{tag: rule.Tag, re: re})
+	}
+
+	client, err := e.HTTPClientConfig.CreateClient(context.Background(), e.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	e.client = client
+
+	return nil
+}
+
+func (e *Envoy) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range e.URLs {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			if err := e.gatherURL(acc, u); err != nil {
+				acc.AddError(fmt.Errorf("gathering %q failed: %w", u, err))
+			}
+		}(u)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (e *Envoy) gatherURL(acc telegraf.Accumulator, address string) error {
+	addr, err := url.Parse(address)
+	if err != nil {
+		return fmt.Errorf("parsing address failed: %w", err)
+	}
+	query := addr.Query()
+	query.Set("format", "json")
+	if e.UsedOnly {
+		query.Set("usedonly", "")
+	}
+	addr.RawQuery = query.Encode()
+
+	resp, err := e.client.Get(addr.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status %s", resp.Status)
+	}
+
+	var response statsResponse
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&response); err != nil {
+		return fmt.Errorf("decoding response failed: %w", err)
+	}
+
+	source := *addr
+	source.User = nil
+	source.RawQuery = ""
+
+	e.process(acc, &response, source.String(), time.Now())
+	return nil
+}
+
+func (e *Envoy) process(acc telegraf.Accumulator, response *statsResponse, source string, timestamp time.Time) {
+	grouper := metric.NewSeriesGrouper()
+	for _, s := range response.Stats {
+		if s.Histograms != nil {
+			e.processHistograms(grouper, s.Histograms, source, timestamp)
+			continue
+		}
+		if !e.filter.Match(s.Name) {
+			continue
+		}
+
+		var value interface{}
+		switch v := s.Value.(type) {
+		case json.Number:
+			if iv, err := v.Int64(); err == nil {
+				value = iv
+			} else if fv, err := v.Float64(); err == nil {
+				value = fv
+			} else {
+				e.Log.Debugf("Skipping stat %q with invalid value %q", s.Name, v)
+				continue
+			}
+		case string:
+			// Text readouts
+			value = v
+		default:
+			continue
+		}
+		add(grouper, e.extract(s.Name), "", value, source, timestamp)
+	}
+
+	for _, m := range grouper.Metrics() {
+		acc.AddMetric(m)
+	}
+}
+
+func (e *Envoy) processHistograms(grouper *metric.SeriesGrouper, h *histograms, source string, timestamp time.Time) {
+	suffixes := make([]string, 0, len(h.SupportedQuantiles))
+	for _, q := range h.SupportedQuantiles {
+		suffixes = append(suffixes, "_p"+strings.ReplaceAll(strconv.FormatFloat(q, 'f', -1, 64), ".", "_"))
+	}
+
+	for _, computed := range h.ComputedQuantiles {
+		if !e.filter.Match(computed.Name) {
+			continue
+		}
+		name := e.extract(computed.Name)
+		for i, v := range computed.Values {
+			if i >= len(suffixes) {
+				break
+			}
+			value := v.Cumulative
+			if e.HistogramValues == "interval" {
+				value = v.Interval
+			}
+			// Envoy reports null for histograms without samples
+			if value == nil {
+				continue
+			}
+			add(grouper, name, suffixes[i], *value, source, timestamp)
+		}
+	}
+}
+
+// extractedName is the remaining stat name after extracting the tags
+type extractedName struct {
+	name string
+	tags map[string]string
+}
+
+// extract applies the tag extraction rules to the stat name. The first
+// capture group of a matching regex is removed from the name and the second
+// capture group, or the first if there is no second one, becomes the tag
+// value. Each tag is only extracted once so user-defined rules override the
+// built-in ones.
+func (e *Envoy) extract(name string) extractedName {
+	tags := make(map[string]string)
+	for _, rule := range e.rules {
+		if _, found := tags[rule.tag]; found {
+			continue
+		}
+		loc := rule.re.FindStringSubmatchIndex(name)
+		if loc == nil || loc[2] < 0 {
+			continue
+		}
+		value := name[loc[2]:loc[3]]
+		if len(loc) > 5 && loc[4] >= 0 {
+			value = name[loc[4]:loc[5]]
+		}
+		tags[rule.tag] = value
+		name = name[:loc[2]] + name[loc[3]:]
+	}
+	return extractedName{name: name, tags: tags}
+}
+
+// add adds the value to the metric of the stat's category, i.e. the first
+// component of the stat name, using the rest of the name as field name
+func add(grouper *metric.SeriesGrouper, n extractedName, suffix string, value interface{}, source string, timestamp time.Time) {
+	measurement := "envoy"
+	field := n.name
+	if category, remainder, found := strings.Cut(n.name, "."); found {
+		measurement = "envoy_" + category
+		field = remainder
+	}
+	field = strings.ReplaceAll(field, ".", "_") + suffix
+
+	tags := make(map[string]string, len(n.tags)+1)
+	for k, v := range n.tags {
+		tags[k] = v
+	}
+	tags["url"] = source
+
+	grouper.Add(measurement, tags, timestamp, field, value)
+}
+
+func init() {
+	inputs.Add("envoy", func() telegraf.Input {
+		return &Envoy{}
+	})
+}
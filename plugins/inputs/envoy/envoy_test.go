@@ -0,0 +1,260 @@
+package envoy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Envoy
+		expected string
+	}{
+		{
+			name:     "invalid histogram values",
+			plugin:   &Envoy{HistogramValues: "all"},
+			expected: `invalid histogram values "all"`,
+		},
+		{
+			name:     "rule without tag",
+			plugin:   &Envoy{TagExtraction: []tagRule{{Regex: `^foo\.((.*?)\.)`}}},
+			expected: "tag extraction rule without tag name",
+		},
+		{
+			name:     "invalid regex",
+			plugin:   &Envoy{TagExtraction: []tagRule{{Tag: "foo", Regex: `^foo\.((.*?\.)`}}},
+			expected: `compiling regex of tag "foo" failed`,
+		},
+		{
+			name:     "regex without group",
+			plugin:   &Envoy{TagExtraction: []tagRule{{Tag: "foo", Regex: `^foo\.`}}},
+			expected: `regex of tag "foo" requires at least one capture group`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	response, err := os.ReadFile("testdata/stats.json")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stats" || r.URL.Query().Get("format") != "json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(response); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	source := server.URL + "/stats"
+	plugin := &Envoy{
+		URLs: []string{source},
+		Log:  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"envoy_cluster",
+			map[string]string{"url": source, "cluster": "backend"},
+			map[string]interface{}{
+				"circuit_breakers_default_cx_open": int64(0),
+				"circuit_breakers_high_rq_open":    int64(1),
+				"membership_healthy":               int64(3),
+				"upstream_cx_active":               int64(12),
+				"upstream_rq_2xx":                  int64(4711),
+				"upstream_rq_5xx":                  int64(3),
+				"upstream_rq_time_p0":              float64(0.5),
+				"upstream_rq_time_p50":             float64(4.5),
+				"upstream_rq_time_p99":             float64(55),
+				"upstream_rq_time_p99_9":           float64(120),
+				"upstream_rq_time_p100":            float64(250),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"envoy_cluster",
+			map[string]string{"url": source, "cluster": "auth"},
+			map[string]interface{}{
+				"upstream_rq_2xx":        int64(42),
+				"upstream_rq_time_p0":    float64(1),
+				"upstream_rq_time_p50":   float64(2),
+				"upstream_rq_time_p99":   float64(3),
+				"upstream_rq_time_p99_9": float64(4),
+				"upstream_rq_time_p100":  float64(5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"envoy_cluster_manager",
+			map[string]string{"url": source},
+			map[string]interface{}{"active_clusters": int64(2)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"envoy_http",
+			map[string]string{"url": source, "stat_prefix": "ingress_http"},
+			map[string]interface{}{"downstream_rq_2xx": int64(4700)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"envoy_listener",
+			map[string]string{"url": source, "listener": "0.0.0.0_8080"},
+			map[string]interface{}{
+				"downstream_cx_active": int64(17),
+				"downstream_cx_total":  int64(1234),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"envoy_server",
+			map[string]string{"url": source},
+			map[string]interface{}{
+				"live":    int64(1),
+				"uptime":  int64(3600),
+				"version": "1.31.0",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherFilterAndInterval(t *testing.T) {
+	response, err := os.ReadFile("testdata/stats.json")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, found := r.URL.Query()["usedonly"]; !found {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if _, err := w.Write(response); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	source := server.URL + "/stats"
+	plugin := &Envoy{
+		URLs:            []string{source},
+		UsedOnly:        true,
+		StatInclude:     []string{"cluster.*.upstream_rq_*"},
+		StatExclude:     []string{"*_5xx"},
+		HistogramValues: "interval",
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"envoy_cluster",
+			map[string]string{"url": source, "cluster": "backend"},
+			map[string]interface{}{
+				"upstream_rq_2xx":        int64(4711),
+				"upstream_rq_time_p0":    float64(1),
+				"upstream_rq_time_p50":   float64(5.05),
+				"upstream_rq_time_p99":   float64(49.5),
+				"upstream_rq_time_p99_9": float64(99),
+				"upstream_rq_time_p100":  float64(100),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"envoy_cluster",
+			map[string]string{"url": source, "cluster": "auth"},
+			map[string]interface{}{"upstream_rq_2xx": int64(42)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestTagExtraction(t *testing.T) {
+	plugin := &Envoy{
+		TagExtraction: []tagRule{
+			{Tag: "cluster", Regex: `^cluster\.((outbound\|\d+\|\|[\w.-]+)\.)`},
+			{Tag: "priority", Regex: `^cluster\.[^.]+\.circuit_breakers\.((\w+)\.)`},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	tests := []struct {
+		name         string
+		expectedName string
+		expectedTags map[string]string
+	}{
+		{
+			name:         "cluster.outbound|80||reviews.default.svc.cluster.local.upstream_rq_2xx",
+			expectedName: "cluster.upstream_rq_2xx",
+			expectedTags: map[string]string{"cluster": "outbound|80||reviews.default.svc.cluster.local"},
+		},
+		{
+			name:         "cluster.backend.circuit_breakers.high.rq_open",
+			expectedName: "cluster.circuit_breakers.rq_open",
+			expectedTags: map[string]string{"cluster": "backend", "priority": "high"},
+		},
+		{
+			name:         "listener.[__]_443.downstream_cx_total",
+			expectedName: "listener.downstream_cx_total",
+			expectedTags: map[string]string{"listener": "[__]_443"},
+		},
+		{
+			name:         "server.live",
+			expectedName: "server.live",
+			expectedTags: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := plugin.extract(tt.name)
+			require.Equal(t, tt.expectedName, actual.name)
+			require.Equal(t, tt.expectedTags, actual.tags)
+		})
+	}
+}
+
+func TestGatherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	plugin := &Envoy{
+		URLs: []string{server.URL + "/stats"},
+		Log:  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "received status 503 Service Unavailable")
+}
@@ -2,7 +2,9 @@
 package file
 
 import (
+	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,6 +12,7 @@ import (
 	"sync"
 
 	"github.com/dimchansky/utfbom"
+	"golang.org/x/exp/mmap"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
@@ -28,11 +31,14 @@ type File struct {
 	FileTag           string          `toml:"file_tag"`
 	FilePathTag       string          `toml:"file_path_tag"`
 	CharacterEncoding string          `toml:"character_encoding"`
+	ContentEncoding   string          `toml:"content_encoding"`
+	MemoryMap         bool            `toml:"memory_map"`
 	Log               telegraf.Logger `toml:"-"`
 
-	parserFunc telegraf.ParserFunc
-	filenames  []string
-	decoder    *encoding.Decoder
+	parserFunc     telegraf.ParserFunc
+	filenames      []string
+	decoder        *encoding.Decoder
+	contentDecoder *internal.StreamDecoder
 }
 
 func (*File) SampleConfig() string {
@@ -40,7 +46,20 @@ func (*File) SampleConfig() string {
 }
 
 func (f *File) Init() error {
+	if f.MemoryMap && f.CharacterEncoding != "" && f.CharacterEncoding != "none" {
+		return errors.New("memory_map requires an empty or \"none\" character_encoding")
+	}
+
+	if f.MemoryMap && f.ContentEncoding != "" && f.ContentEncoding != "identity" {
+		return errors.New("memory_map requires an empty or \"identity\" content_encoding")
+	}
+
 	var err error
+	f.contentDecoder, err = internal.NewStreamDecoder(f.ContentEncoding)
+	if err != nil {
+		return fmt.Errorf("invalid content_encoding %q: %w", f.ContentEncoding, err)
+	}
+
 	f.decoder, err = encoding.NewDecoder(f.CharacterEncoding)
 	return err
 }
@@ -57,7 +76,12 @@ func (f *File) Gather(acc telegraf.Accumulator) error {
 	for _, k := range f.filenames {
 		metrics, err := f.readMetric(k)
 		if err != nil {
-			return err
+			if len(metrics) == 0 {
+				return err
+			}
+			// Parsers skipping invalid data return the valid metrics
+			// together with the errors, so keep the metrics
+			acc.AddError(err)
 		}
 
 		for _, m := range metrics {
@@ -94,32 +118,74 @@ func (f *File) refreshFilePaths() error {
 }
 
 func (f *File) readMetric(filename string) ([]telegraf.Metric, error) {
+	parser, err := f.parserFunc()
+	if err != nil {
+		return nil, fmt.Errorf("could not instantiate parser: %w", err)
+	}
+
+	var metrics []telegraf.Metric
+	if p, ok := parser.(telegraf.ReaderAtParser); ok && f.MemoryMap {
+		metrics, err = readMemoryMapped(filename, p)
+	} else {
+		metrics, err = f.read(filename, parser)
+	}
+	if err != nil {
+		return metrics, err
+	}
+
+	if len(metrics) == 0 {
+		once.Do(func() {
+			f.Log.Debug(internal.NoMetricsCreatedMsg)
+		})
+	}
+	return metrics, nil
+}
+
+func (f *File) read(filename string, parser telegraf.Parser) ([]telegraf.Metric, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	r, _ := utfbom.Skip(f.decoder.Reader(file))
-	fileContents, err := io.ReadAll(r)
+	cr, err := f.contentDecoder.NewReader(file)
 	if err != nil {
-		return nil, fmt.Errorf("could not read %q: %w", filename, err)
+		return nil, fmt.Errorf("could not decompress %q: %w", filename, err)
 	}
-	parser, err := f.parserFunc()
+	defer cr.Close()
+
+	r, _ := utfbom.Skip(f.decoder.Reader(cr))
+	fileContents, err := io.ReadAll(r)
 	if err != nil {
-		return nil, fmt.Errorf("could not instantiate parser: %w", err)
+		return nil, fmt.Errorf("could not read %q: %w", filename, err)
 	}
 	metrics, err := parser.Parse(fileContents)
 	if err != nil {
 		return metrics, fmt.Errorf("could not parse %q: %w", filename, err)
 	}
+	return metrics, nil
+}
 
-	if len(metrics) == 0 {
-		once.Do(func() {
-			f.Log.Debug(internal.NoMetricsCreatedMsg)
-		})
+func readMemoryMapped(filename string, parser telegraf.ReaderAtParser) ([]telegraf.Metric, error) {
+	file, err := mmap.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Skip a leading UTF-8 byte order mark
+	var offset int64
+	bom := make([]byte, 3)
+	if n, _ := file.ReadAt(bom, 0); n == len(bom) && bytes.Equal(bom, []byte{0xef, 0xbb, 0xbf}) {
+		offset = int64(len(bom))
+	}
+
+	size := int64(file.Len()) - offset
+	metrics, err := parser.ParseReaderAt(io.NewSectionReader(file, offset, size), size)
+	if err != nil {
+		return metrics, fmt.Errorf("could not parse %q: %w", filename, err)
 	}
-	return metrics, err
+	return metrics, nil
 }
 
 func init() {
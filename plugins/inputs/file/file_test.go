@@ -15,9 +15,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/compress"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
 	"github.com/influxdata/telegraf/plugins/parsers/grok"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -114,6 +116,130 @@ func TestGrokParser(t *testing.T) {
 	require.Len(t, acc.Metrics, 2)
 }
 
+func TestMemoryMap(t *testing.T) {
+	// Write the data with a leading UTF-8 byte order mark
+	filename := filepath.Join(t.TempDir(), "metrics.influx")
+	data := "\xef\xbb\xbfcpu,host=a value=42 1700000000000000000\ncpu,host=b value=\"multi\nline\" 1700000000000000000\n"
+	require.NoError(t, os.WriteFile(filename, []byte(data), 0600))
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 42.0}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": "multi\nline"}, time.Unix(1700000000, 0)),
+	}
+
+	r := File{
+		Files:     []string{filename},
+		MemoryMap: true,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, r.Init())
+
+	r.SetParserFunc(func() (telegraf.Parser, error) {
+		p := &influx.Parser{}
+		err := p.Init()
+		return p, err
+	})
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.Gather(&acc))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestPermissive(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "metrics.influx")
+	data := "cpu value=42 1700000000000000000\ncpu value=invalid\ncpu value=43 1700000000000000000\n"
+	require.NoError(t, os.WriteFile(filename, []byte(data), 0600))
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 43.0}, time.Unix(1700000000, 0)),
+	}
+
+	for _, mmap := range []bool{false, true} {
+		r := File{
+			Files:     []string{filename},
+			MemoryMap: mmap,
+			Log:       testutil.Logger{},
+		}
+		require.NoError(t, r.Init())
+		r.SetParserFunc(func() (telegraf.Parser, error) {
+			p := &influx.Parser{Permissive: true}
+			err := p.Init()
+			return p, err
+		})
+
+		// The valid metrics are kept and the invalid line is reported
+		var acc testutil.Accumulator
+		require.NoError(t, r.Gather(&acc))
+		testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+		require.Len(t, acc.Errors, 1)
+		require.ErrorContains(t, acc.Errors[0], "expected field at 2:11")
+	}
+}
+
+func TestMemoryMapInvalidEncoding(t *testing.T) {
+	r := File{
+		Files:             []string{"metrics.influx"},
+		CharacterEncoding: "utf-16le",
+		MemoryMap:         true,
+		Log:               testutil.Logger{},
+	}
+	require.ErrorContains(t, r.Init(), "memory_map requires")
+}
+
+func TestContentEncoding(t *testing.T) {
+	data := []byte("cpu,host=a value=42 1700000000000000000\n")
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 42.0}, time.Unix(1700000000, 0)),
+	}
+
+	for _, encoding := range []string{"gzip", "zlib", "zstd", "lz4", "snappy"} {
+		t.Run(encoding, func(t *testing.T) {
+			encoder, err := compress.NewEncoder(encoding, compress.DefaultLevel)
+			require.NoError(t, err)
+			encoded, err := encoder.Encode(data)
+			require.NoError(t, err)
+
+			filename := filepath.Join(t.TempDir(), "metrics.influx")
+			require.NoError(t, os.WriteFile(filename, encoded, 0600))
+
+			r := File{
+				Files:           []string{filename},
+				ContentEncoding: encoding,
+				Log:             testutil.Logger{},
+			}
+			require.NoError(t, r.Init())
+
+			r.SetParserFunc(func() (telegraf.Parser, error) {
+				p := &influx.Parser{}
+				err := p.Init()
+				return p, err
+			})
+
+			var acc testutil.Accumulator
+			require.NoError(t, r.Gather(&acc))
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestContentEncodingInvalid(t *testing.T) {
+	r := File{
+		Files:           []string{"metrics.influx"},
+		ContentEncoding: "brotli",
+		Log:             testutil.Logger{},
+	}
+	require.ErrorContains(t, r.Init(), `invalid content_encoding "brotli"`)
+
+	r = File{
+		Files:           []string{"metrics.influx"},
+		ContentEncoding: "gzip",
+		MemoryMap:       true,
+		Log:             testutil.Logger{},
+	}
+	require.ErrorContains(t, r.Init(), "memory_map requires")
+}
+
 func TestCharacterEncoding(t *testing.T) {
 	expected := []telegraf.Metric{
 		metric.New("file",
@@ -0,0 +1,234 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package hardware_health
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const measurement = "hardware_health"
+
+// Health codes reported for the normalized health states
+const (
+	healthOK = iota
+	healthWarning
+	healthCritical
+)
+
+type HardwareHealth struct {
+	Address       string          `toml:"address"`
+	Username      config.Secret   `toml:"username"`
+	Password      config.Secret   `toml:"password"`
+	Include       []string        `toml:"include"`
+	CacheTTL      config.Duration `toml:"cache_ttl"`
+	IPMIFallback  bool            `toml:"ipmi_fallback"`
+	IPMIToolPath  string          `toml:"ipmitool_path"`
+	IPMIInterface string          `toml:"ipmi_interface"`
+	UseSudo       bool            `toml:"use_sudo"`
+	Timeout       config.Duration `toml:"timeout"`
+	Log           telegraf.Logger `toml:"-"`
+	tls.ClientConfig
+
+	include map[string]bool
+	baseURL *url.URL
+	host    string
+	client  *http.Client
+	tree    *resourceTree
+
+	// run executes ipmitool with the given arguments
+	run func(args ...string) ([]byte, error)
+}
+
+// reading is a normalized sensor reading of either source
+type reading struct {
+	component string
+	sensor    string
+	name      string
+	vendor    string
+	health    string
+	state     string
+	value     *float64
+}
+
+func (*HardwareHealth) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *HardwareHealth) Init() error {
+	if h.Address == "" && !h.IPMIFallback {
+		return errors.New("either address or ipmi_fallback must be set")
+	}
+
+	if len(h.Include) == 0 {
+		h.Include = []string{"temperature", "fan", "psu", "drive"}
+	}
+	h.include = make(map[string]bool, len(h.Include))
+	for _, component := range h.Include {
+		switch component {
+		case "temperature", "fan", "psu", "drive":
+		default:
+			return fmt.Errorf("unknown component %q", component)
+		}
+		h.include[component] = true
+	}
+
+	if h.Address != "" {
+		u, err := url.Parse(h.Address)
+		if err != nil {
+			return fmt.Errorf("parsing address failed: %w", err)
+		}
+		h.baseURL = u
+		h.host = u.Hostname()
+
+		tlsCfg, err := h.ClientConfig.TLSConfig()
+		if err != nil {
+			return err
+		}
+		h.client = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsCfg,
+				Proxy:           http.ProxyFromEnvironment,
+			},
+			Timeout: time.Duration(h.Timeout),
+		}
+	}
+
+	if h.IPMIInterface != "" && h.host == "" {
+		return errors.New("ipmi_interface requires an address")
+	}
+	h.run = h.runIPMITool
+
+	return nil
+}
+
+func (h *HardwareHealth) Gather(acc telegraf.Accumulator) error {
+	if h.Address != "" {
+		readings, err := h.gatherRedfish()
+		if err == nil {
+			h.addReadings(acc, "redfish", readings)
+			return nil
+		}
+		if !h.IPMIFallback {
+			return err
+		}
+		h.Log.Warnf("Querying Redfish failed, falling back to IPMI: %v", err)
+	}
+
+	readings, err := h.gatherIPMI()
+	if err != nil {
+		return err
+	}
+	h.addReadings(acc, "ipmi", readings)
+	return nil
+}
+
+func (h *HardwareHealth) addReadings(acc telegraf.Accumulator, source string, readings []reading) {
+	for _, r := range readings {
+		if !h.include[r.component] {
+			continue
+		}
+		tags := map[string]string{
+			"source":    source,
+			"component": r.component,
+			"sensor":    r.sensor,
+			"name":      r.name,
+		}
+		if h.host != "" {
+			tags["address"] = h.host
+		}
+		if r.vendor != "" {
+			tags["vendor"] = r.vendor
+		}
+		fields := map[string]interface{}{
+			"health": r.health,
+		}
+		if code, ok := healthCode(r.health); ok {
+			fields["health_code"] = code
+		}
+		if r.state != "" {
+			fields["state"] = r.state
+		}
+		if r.value != nil {
+			fields["reading"] = *r.value
+		}
+		acc.AddFields(measurement, fields, tags)
+	}
+}
+
+func (h *HardwareHealth) runIPMITool(args ...string) ([]byte, error) {
+	name := h.IPMIToolPath
+	if name == "" {
+		path, err := exec.LookPath("ipmitool")
+		if err != nil {
+			return nil, fmt.Errorf("looking up ipmitool failed: %w", err)
+		}
+		name = path
+	}
+
+	if h.IPMIInterface != "" {
+		username, err := h.Username.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting username failed: %w", err)
+		}
+		user := username.String()
+		username.Destroy()
+
+		password, err := h.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password failed: %w", err)
+		}
+		pass := password.String()
+		password.Destroy()
+
+		args = append([]string{"-I", h.IPMIInterface, "-H", h.host, "-U", user, "-P", pass}, args...)
+	}
+
+	if h.UseSudo {
+		args = append([]string{name}, args...)
+		name = "sudo"
+	}
+
+	cmd := exec.Command(name, args...)
+	out, err := internal.CombinedOutputTimeout(cmd, time.Duration(h.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("running ipmitool failed: %w: %s", err, string(out))
+	}
+	return out, nil
+}
+
+// healthCode converts the health state to a numeric code
+func healthCode(health string) (int64, bool) {
+	switch health {
+	case "OK":
+		return healthOK, true
+	case "Warning":
+		return healthWarning, true
+	case "Critical":
+		return healthCritical, true
+	}
+	return 0, false
+}
+
+func init() {
+	inputs.Add("hardware_health", func() telegraf.Input {
+		return &HardwareHealth{
+			CacheTTL:     config.Duration(time.Hour),
+			IPMIFallback: true,
+			Timeout:      config.Duration(20 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,334 @@
+package hardware_health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var redfishResources = map[string]string{
+	"/redfish/v1/Chassis": `{"Members": [{"@odata.id": "/redfish/v1/Chassis/System.Embedded.1"}]}`,
+	"/redfish/v1/Chassis/System.Embedded.1": `{
+		"Manufacturer": "Dell Inc.",
+		"Thermal": {"@odata.id": "/redfish/v1/Chassis/System.Embedded.1/Thermal"},
+		"Power": {"@odata.id": "/redfish/v1/Chassis/System.Embedded.1/Power"}
+	}`,
+	"/redfish/v1/Chassis/System.Embedded.1/Thermal": `{
+		"Temperatures": [
+			{"Name": "CPU1 Temp", "ReadingCelsius": 40, "Status": {"State": "Enabled", "Health": "OK"}},
+			{"Name": "System Board Inlet Temp", "ReadingCelsius": 22, "Status": {"State": "Enabled", "Health": "OK"}},
+			{"Name": "CPU2 Temp", "Status": {"State": "Absent"}}
+		],
+		"Fans": [
+			{"FanName": "System Board Fan1A", "Reading": 6240, "Status": {"State": "Enabled", "Health": "Warning"}}
+		]
+	}`,
+	"/redfish/v1/Chassis/System.Embedded.1/Power": `{
+		"PowerSupplies": [
+			{"Name": "PS1 Status", "PowerOutputWatts": 180.5, "Status": {"State": "Enabled", "Health": "OK"}},
+			{"Name": "PS2 Status", "Status": {"State": "Enabled", "Health": "Critical"}}
+		]
+	}`,
+	"/redfish/v1/Systems":                           `{"Members": [{"@odata.id": "/redfish/v1/Systems/System.Embedded.1"}]}`,
+	"/redfish/v1/Systems/System.Embedded.1":         `{"Manufacturer": "Dell Inc.", "Storage": {"@odata.id": "/redfish/v1/Systems/System.Embedded.1/Storage"}}`,
+	"/redfish/v1/Systems/System.Embedded.1/Storage": `{"Members": [{"@odata.id": "/redfish/v1/Systems/System.Embedded.1/Storage/RAID.1"}]}`,
+	"/redfish/v1/Systems/System.Embedded.1/Storage/RAID.1": `{
+		"Drives": [{"@odata.id": "/redfish/v1/Systems/System.Embedded.1/Storage/Drives/Disk.Bay.0"}]
+	}`,
+	"/redfish/v1/Systems/System.Embedded.1/Storage/Drives/Disk.Bay.0": `{"Id": "Disk.Bay.0", "Name": "Physical Disk 0:1:0", "Status": {"State": "Enabled", "Health": "OK"}}`,
+}
+
+type redfishServer struct {
+	*httptest.Server
+
+	sync.Mutex
+	requests map[string]int
+	fail     bool
+}
+
+func newRedfishServer(t *testing.T) *redfishServer {
+	s := &redfishServer{requests: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		defer s.Unlock()
+
+		if user, pass, ok := r.BasicAuth(); !ok || user != "test" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, found := redfishResources[r.URL.Path]
+		if !found || s.fail {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.requests[r.URL.Path]++
+		if _, err := w.Write([]byte(body)); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestRedfish(t *testing.T) {
+	server := newRedfishServer(t)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	plugin := &HardwareHealth{
+		Address:  server.URL,
+		Username: config.NewSecret([]byte("test")),
+		Password: config.NewSecret([]byte("secret")),
+		CacheTTL: config.Duration(time.Hour),
+		Timeout:  config.Duration(5 * time.Second),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	tags := func(component, sensor, name string) map[string]string {
+		return map[string]string{
+			"source":    "redfish",
+			"address":   u.Hostname(),
+			"vendor":    "dell",
+			"component": component,
+			"sensor":    sensor,
+			"name":      name,
+		}
+	}
+	expected := []telegraf.Metric{
+		metric.New("hardware_health",
+			tags("temperature", "cpu1", "CPU1 Temp"),
+			map[string]interface{}{"health": "OK", "health_code": int64(0), "state": "Enabled", "reading": 40.0},
+			time.Unix(0, 0),
+		),
+		metric.New("hardware_health",
+			tags("temperature", "inlet", "System Board Inlet Temp"),
+			map[string]interface{}{"health": "OK", "health_code": int64(0), "state": "Enabled", "reading": 22.0},
+			time.Unix(0, 0),
+		),
+		metric.New("hardware_health",
+			tags("fan", "fan1a", "System Board Fan1A"),
+			map[string]interface{}{"health": "Warning", "health_code": int64(1), "state": "Enabled", "reading": 6240.0},
+			time.Unix(0, 0),
+		),
+		metric.New("hardware_health",
+			tags("psu", "psu1", "PS1 Status"),
+			map[string]interface{}{"health": "OK", "health_code": int64(0), "state": "Enabled", "reading": 180.5},
+			time.Unix(0, 0),
+		),
+		metric.New("hardware_health",
+			tags("psu", "psu2", "PS2 Status"),
+			map[string]interface{}{"health": "Critical", "health_code": int64(2), "state": "Enabled"},
+			time.Unix(0, 0),
+		),
+		metric.New("hardware_health",
+			tags("drive", "physical_drive0_1_0", "Physical Disk 0:1:0"),
+			map[string]interface{}{"health": "OK", "health_code": int64(0), "state": "Enabled"},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// The resource tree must be cached and only the sensor resources must be
+	// queried again
+	require.NoError(t, plugin.Gather(&acc))
+	server.Lock()
+	defer server.Unlock()
+	require.Equal(t, 1, server.requests["/redfish/v1/Chassis"])
+	require.Equal(t, 1, server.requests["/redfish/v1/Systems"])
+	require.Equal(t, 2, server.requests["/redfish/v1/Chassis/System.Embedded.1/Thermal"])
+	require.Equal(t, 2, server.requests["/redfish/v1/Systems/System.Embedded.1/Storage/Drives/Disk.Bay.0"])
+}
+
+func TestRedfishInclude(t *testing.T) {
+	server := newRedfishServer(t)
+
+	plugin := &HardwareHealth{
+		Address:  server.URL,
+		Username: config.NewSecret([]byte("test")),
+		Password: config.NewSecret([]byte("secret")),
+		Include:  []string{"psu"},
+		CacheTTL: config.Duration(time.Hour),
+		Timeout:  config.Duration(5 * time.Second),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+
+	// Resources of excluded components must not be queried
+	server.Lock()
+	defer server.Unlock()
+	require.Zero(t, server.requests["/redfish/v1/Chassis/System.Embedded.1/Thermal"])
+	require.Zero(t, server.requests["/redfish/v1/Systems"])
+}
+
+func TestIPMIFallback(t *testing.T) {
+	outputs := map[string]string{
+		"Temperature": `CPU1 Temp        | 0Eh | ok  |  3.1 | 35 degrees C
+Inlet Temp       | 04h | ok  |  7.1 | 22 degrees C
+CPU2 Temp        | 0Fh | ns  |  3.2 | No Reading
+`,
+		"Fan": `Fan1A            | 30h | ok  |  7.1 | 6240 RPM
+Fan2A            | 31h | cr  |  7.1 | 720 RPM
+`,
+		"Power Supply": `PS1 Status       | 25h | ok  | 10.1 | Presence detected
+PS2 Status       | 26h | ok  | 10.2 | Presence detected, Failure detected
+`,
+		"Drive Slot / Bay": `Drive 0          | 80h | ok  | 26.0 | Drive Present
+`,
+	}
+
+	server := newRedfishServer(t)
+	server.fail = true
+
+	plugin := &HardwareHealth{
+		Address:      server.URL,
+		Username:     config.NewSecret([]byte("test")),
+		Password:     config.NewSecret([]byte("secret")),
+		CacheTTL:     config.Duration(time.Hour),
+		IPMIFallback: true,
+		Timeout:      config.Duration(5 * time.Second),
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.run = func(args ...string) ([]byte, error) {
+		require.Len(t, args, 3)
+		out, found := outputs[args[2]]
+		if !found {
+			return nil, errors.New("unknown sensor type")
+		}
+		return []byte(out), nil
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	tags := func(component, sensor, name string) map[string]string {
+		return map[string]string{
+			"source":    "ipmi",
+			"address":   u.Hostname(),
+			"component": component,
+			"sensor":    sensor,
+			"name":      name,
+		}
+	}
+	expected := []telegraf.Metric{
+		metric.New("hardware_health",
+			tags("temperature", "cpu1", "CPU1 Temp"),
+			map[string]interface{}{"health": "OK", "health_code": int64(0), "state": "Enabled", "reading": 35.0},
+			time.Unix(0, 0),
+		),
+		metric.New("hardware_health",
+			tags("temperature", "inlet", "Inlet Temp"),
+			map[string]interface{}{"health": "OK", "health_code": int64(0), "state": "Enabled", "reading": 22.0},
+			time.Unix(0, 0),
+		),
+		metric.New("hardware_health",
+			tags("fan", "fan1a", "Fan1A"),
+			map[string]interface{}{"health": "OK", "health_code": int64(0), "state": "Enabled", "reading": 6240.0},
+			time.Unix(0, 0),
+		),
+		metric.New("hardware_health",
+			tags("fan", "fan2a", "Fan2A"),
+			map[string]interface{}{"health": "Critical", "health_code": int64(2), "state": "Enabled", "reading": 720.0},
+			time.Unix(0, 0),
+		),
+		metric.New("hardware_health",
+			tags("psu", "psu1", "PS1 Status"),
+			map[string]interface{}{"health": "OK", "health_code": int64(0), "state": "Enabled"},
+			time.Unix(0, 0),
+		),
+		metric.New("hardware_health",
+			tags("psu", "psu2", "PS2 Status"),
+			map[string]interface{}{"health": "Critical", "health_code": int64(2), "state": "Enabled"},
+			time.Unix(0, 0),
+		),
+		metric.New("hardware_health",
+			tags("drive", "drive0", "Drive 0"),
+			map[string]interface{}{"health": "OK", "health_code": int64(0), "state": "Enabled"},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestRedfishErrorWithoutFallback(t *testing.T) {
+	server := newRedfishServer(t)
+	server.fail = true
+
+	plugin := &HardwareHealth{
+		Address:  server.URL,
+		Username: config.NewSecret([]byte("test")),
+		Password: config.NewSecret([]byte("secret")),
+		CacheTTL: config.Duration(time.Hour),
+		Timeout:  config.Duration(5 * time.Second),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Gather(&acc), "discovering resources failed")
+}
+
+func TestNormalizeSensor(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		// Dell
+		{"CPU1 Temp", "cpu1"},
+		{"System Board Inlet Temp", "inlet"},
+		{"System Board Exhaust Temp", "exhaust"},
+		{"System Board Fan1A", "fan1a"},
+		{"PS1 Status", "psu1"},
+		// HPE
+		{"02-CPU 1", "cpu1"},
+		{"01-Inlet Ambient", "inlet"},
+		{"Fan 1", "fan1"},
+		{"Power Supply 2", "psu2"},
+		// Lenovo
+		{"CPU 1 Temp", "cpu1"},
+		{"Ambient Temp", "inlet"},
+		{"Fan 1 Front Tach", "fan1_front"},
+		{"PSU1", "psu1"},
+		// Fallback to the cleaned name
+		{"Temp", "temp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, normalizeSensor(tt.name))
+		})
+	}
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &HardwareHealth{}
+	require.ErrorContains(t, plugin.Init(), "either address or ipmi_fallback must be set")
+
+	plugin = &HardwareHealth{IPMIFallback: true, Include: []string{"memory"}}
+	require.ErrorContains(t, plugin.Init(), `unknown component "memory"`)
+
+	plugin = &HardwareHealth{IPMIFallback: true, IPMIInterface: "lanplus"}
+	require.ErrorContains(t, plugin.Init(), "ipmi_interface requires an address")
+}
@@ -0,0 +1,98 @@
+package hardware_health
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IPMI sensor types of the components
+var ipmiSensorTypes = []struct {
+	component  string
+	sensorType string
+}{
+	{"temperature", "Temperature"},
+	{"fan", "Fan"},
+	{"psu", "Power Supply"},
+	{"drive", "Drive Slot / Bay"},
+}
+
+var ipmiReadingRe = regexp.MustCompile(`^(-?\d+(?:\.\d+)?)\s+(?:degrees C|RPM|Watts|percent)`)
+
+func (h *HardwareHealth) gatherIPMI() ([]reading, error) {
+	var readings []reading
+	for _, t := range ipmiSensorTypes {
+		if !h.include[t.component] {
+			continue
+		}
+		out, err := h.run("sdr", "type", t.sensorType)
+		if err != nil {
+			return nil, fmt.Errorf("querying %s sensors failed: %w", t.component, err)
+		}
+		readings = append(readings, parseIPMI(t.component, out)...)
+	}
+	return readings, nil
+}
+
+// parseIPMI parses the output of "ipmitool sdr type" in the form
+//
+//	CPU1 Temp        | 0Eh | ok  |  3.1 | 35 degrees C
+//	PS1 Status       | 25h | ok  | 10.1 | Presence detected
+//
+// skipping sensors without reading, e.g. empty slots
+func parseIPMI(component string, out []byte) []reading {
+	var readings []reading
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		parts := strings.Split(scanner.Text(), "|")
+		if len(parts) != 5 {
+			continue
+		}
+		name := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[4])
+
+		health, found := ipmiHealth(strings.TrimSpace(parts[2]), value)
+		if !found {
+			continue
+		}
+
+		r := reading{
+			component: component,
+			sensor:    normalizeSensor(name),
+			name:      name,
+			health:    health,
+			state:     "Enabled",
+		}
+		if match := ipmiReadingRe.FindStringSubmatch(value); match != nil {
+			if v, err := strconv.ParseFloat(match[1], 64); err == nil {
+				r.value = &v
+			}
+		}
+		readings = append(readings, r)
+	}
+	return readings
+}
+
+// ipmiHealth maps the status of the sensor to the Redfish health states
+// taking the event states of discrete sensors into account
+func ipmiHealth(status, value string) (string, bool) {
+	switch status {
+	case "ns":
+		return "", false
+	case "ok":
+		v := strings.ToLower(value)
+		switch {
+		case strings.Contains(v, "predictive failure"):
+			return "Warning", true
+		case strings.Contains(v, "failure"), strings.Contains(v, "fault"), strings.Contains(v, "lost"):
+			return "Critical", true
+		}
+		return "OK", true
+	case "nc", "lnc", "unc":
+		return "Warning", true
+	}
+	return "Critical", true
+}
@@ -0,0 +1,60 @@
+package hardware_health
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Rules to normalize the vendor specific sensor names, e.g. "CPU1 Temp"
+// (Dell), "02-CPU 1" (HPE) and "CPU 1 Temp" (Lenovo) all become "cpu1"
+var (
+	indexPrefixRe = regexp.MustCompile(`^\d+-`)
+	noiseRe       = regexp.MustCompile(`\b(?:system board|temperature|temp|status|tach|sensor|reading)\b`)
+	invalidRe     = regexp.MustCompile(`[^a-z0-9]+`)
+	synonyms      = []struct {
+		re   *regexp.Regexp
+		repl string
+	}{
+		{regexp.MustCompile(`\b(?:cpu|proc|processor)\s*(\d+)`), "cpu$1"},
+		{regexp.MustCompile(`\b(?:power supply|psu|ps)\s*(\d+)`), "psu$1"},
+		{regexp.MustCompile(`\bfan\s*(\d+\w*)`), "fan$1"},
+		{regexp.MustCompile(`\b(?:drive|disk|hdd|ssd)\s*(\d+)`), "drive$1"},
+		{regexp.MustCompile(`\b(?:inlet ambient|ambient|inlet)\b`), "inlet"},
+		{regexp.MustCompile(`\b(?:exhaust|outlet)\b`), "exhaust"},
+	}
+)
+
+// normalizeSensor converts the sensor name into a vendor independent form
+func normalizeSensor(name string) string {
+	s := strings.ToLower(name)
+	s = indexPrefixRe.ReplaceAllString(s, "")
+	s = noiseRe.ReplaceAllString(s, "")
+	for _, syn := range synonyms {
+		s = syn.re.ReplaceAllString(s, syn.repl)
+	}
+	s = strings.Trim(invalidRe.ReplaceAllString(s, "_"), "_")
+	if s == "" {
+		// Do not strip the name completely
+		return strings.Trim(invalidRe.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	}
+	return s
+}
+
+// normalizeVendor converts the manufacturer reported by the BMC to a short
+// vendor name
+func normalizeVendor(manufacturer string) string {
+	m := strings.ToLower(strings.TrimSpace(manufacturer))
+	switch {
+	case m == "":
+		return ""
+	case strings.Contains(m, "dell"):
+		return "dell"
+	case m == "hp", strings.Contains(m, "hpe"), strings.Contains(m, "hewlett"):
+		return "hpe"
+	case strings.Contains(m, "lenovo"):
+		return "lenovo"
+	case strings.Contains(m, "supermicro"):
+		return "supermicro"
+	}
+	return strings.Fields(m)[0]
+}
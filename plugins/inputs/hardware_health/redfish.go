@@ -0,0 +1,306 @@
+package hardware_health
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// resourceTree contains the sensor resources discovered on the BMC
+type resourceTree struct {
+	discovered time.Time
+	chassis    []chassisResources
+	drives     []driveResource
+}
+
+type chassisResources struct {
+	vendor  string
+	thermal string
+	power   string
+}
+
+type driveResource struct {
+	vendor string
+	ref    string
+}
+
+type link struct {
+	Ref string `json:"@odata.id"`
+}
+
+type collection struct {
+	Members []link
+}
+
+type status struct {
+	State  string
+	Health string
+}
+
+type chassis struct {
+	Manufacturer string
+	Thermal      link
+	Power        link
+}
+
+type system struct {
+	Manufacturer string
+	Storage      link
+}
+
+type storage struct {
+	Drives []link
+}
+
+type thermal struct {
+	Temperatures []struct {
+		Name           string
+		ReadingCelsius *float64
+		Status         status
+	}
+	Fans []struct {
+		Name    string
+		FanName string
+		Reading *float64
+		Status  status
+	}
+}
+
+type power struct {
+	PowerSupplies []struct {
+		Name                 string
+		PowerOutputWatts     *float64
+		LastPowerOutputWatts *float64
+		Status               status
+	}
+}
+
+type drive struct {
+	ID     string `json:"Id"`
+	Name   string
+	Status status
+}
+
+func (h *HardwareHealth) gatherRedfish() ([]reading, error) {
+	if h.tree == nil || time.Since(h.tree.discovered) > time.Duration(h.CacheTTL) {
+		tree, err := h.discover()
+		if err != nil {
+			return nil, fmt.Errorf("discovering resources failed: %w", err)
+		}
+		h.tree = tree
+	}
+
+	readings, err := h.readResources()
+	if err != nil {
+		// Resources might have changed e.g. due to a firmware update, so
+		// rediscover the tree with the next gather
+		h.tree = nil
+		return nil, err
+	}
+	return readings, nil
+}
+
+// discover walks the Redfish resource tree and collects the resources
+// containing sensor information
+func (h *HardwareHealth) discover() (*resourceTree, error) {
+	tree := &resourceTree{discovered: time.Now()}
+
+	var chassisList collection
+	if err := h.get("/redfish/v1/Chassis", &chassisList); err != nil {
+		return nil, err
+	}
+	for _, member := range chassisList.Members {
+		var c chassis
+		if err := h.get(member.Ref, &c); err != nil {
+			return nil, err
+		}
+		tree.chassis = append(tree.chassis, chassisResources{
+			vendor:  normalizeVendor(c.Manufacturer),
+			thermal: c.Thermal.Ref,
+			power:   c.Power.Ref,
+		})
+	}
+
+	if !h.include["drive"] {
+		return tree, nil
+	}
+
+	var systems collection
+	if err := h.get("/redfish/v1/Systems", &systems); err != nil {
+		return nil, err
+	}
+	for _, member := range systems.Members {
+		var s system
+		if err := h.get(member.Ref, &s); err != nil {
+			return nil, err
+		}
+		if s.Storage.Ref == "" {
+			continue
+		}
+
+		var controllers collection
+		if err := h.get(s.Storage.Ref, &controllers); err != nil {
+			return nil, err
+		}
+		for _, controller := range controllers.Members {
+			var st storage
+			if err := h.get(controller.Ref, &st); err != nil {
+				return nil, err
+			}
+			for _, d := range st.Drives {
+				tree.drives = append(tree.drives, driveResource{vendor: normalizeVendor(s.Manufacturer), ref: d.Ref})
+			}
+		}
+	}
+
+	return tree, nil
+}
+
+// readResources queries the cached sensor resources
+func (h *HardwareHealth) readResources() ([]reading, error) {
+	var readings []reading
+	for _, c := range h.tree.chassis {
+		if c.thermal != "" && (h.include["temperature"] || h.include["fan"]) {
+			var t thermal
+			if err := h.get(c.thermal, &t); err != nil {
+				return nil, err
+			}
+			for _, s := range t.Temperatures {
+				if s.Status.State == "Absent" {
+					continue
+				}
+				readings = append(readings, reading{
+					component: "temperature",
+					sensor:    normalizeSensor(s.Name),
+					name:      s.Name,
+					vendor:    c.vendor,
+					health:    s.Status.Health,
+					state:     s.Status.State,
+					value:     s.ReadingCelsius,
+				})
+			}
+			for _, s := range t.Fans {
+				if s.Status.State == "Absent" {
+					continue
+				}
+				// Older schema versions use "FanName" instead of "Name"
+				name := s.Name
+				if name == "" {
+					name = s.FanName
+				}
+				readings = append(readings, reading{
+					component: "fan",
+					sensor:    normalizeSensor(name),
+					name:      name,
+					vendor:    c.vendor,
+					health:    s.Status.Health,
+					state:     s.Status.State,
+					value:     s.Reading,
+				})
+			}
+		}
+
+		if c.power != "" && h.include["psu"] {
+			var p power
+			if err := h.get(c.power, &p); err != nil {
+				return nil, err
+			}
+			for i, s := range p.PowerSupplies {
+				if s.Status.State == "Absent" {
+					continue
+				}
+				// Some vendors do not name the power supplies, use their
+				// position instead
+				name := s.Name
+				if name == "" {
+					name = fmt.Sprintf("PSU %d", i+1)
+				}
+				value := s.PowerOutputWatts
+				if value == nil {
+					value = s.LastPowerOutputWatts
+				}
+				readings = append(readings, reading{
+					component: "psu",
+					sensor:    normalizeSensor(name),
+					name:      name,
+					vendor:    c.vendor,
+					health:    s.Status.Health,
+					state:     s.Status.State,
+					value:     value,
+				})
+			}
+		}
+	}
+
+	for _, d := range h.tree.drives {
+		var dr drive
+		if err := h.get(d.ref, &dr); err != nil {
+			return nil, err
+		}
+		if dr.Status.State == "Absent" {
+			continue
+		}
+		name := dr.Name
+		if name == "" {
+			name = dr.ID
+		}
+		readings = append(readings, reading{
+			component: "drive",
+			sensor:    normalizeSensor(name),
+			name:      name,
+			vendor:    d.vendor,
+			health:    dr.Status.Health,
+			state:     dr.Status.State,
+		})
+	}
+
+	return readings, nil
+}
+
+func (h *HardwareHealth) get(ref string, payload interface{}) error {
+	loc := h.baseURL.ResolveReference(&url.URL{Path: ref})
+	req, err := http.NewRequest("GET", loc.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	username, err := h.Username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	user := username.String()
+	username.Destroy()
+
+	password, err := h.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	pass := password.String()
+	password.Destroy()
+
+	req.SetBasicAuth(user, pass)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("OData-Version", "4.0")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status code %d (%s) for %q", resp.StatusCode, http.StatusText(resp.StatusCode), loc.String())
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, payload); err != nil {
+		return fmt.Errorf("parsing response of %q failed: %w", loc.String(), err)
+	}
+	return nil
+}
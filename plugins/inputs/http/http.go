@@ -3,6 +3,7 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
 	"errors"
@@ -45,8 +46,9 @@ type HTTP struct {
 
 	common_http.HTTPClientConfig
 
-	client     *http.Client
-	parserFunc telegraf.ParserFunc
+	client      *http.Client
+	parserFunc  telegraf.ParserFunc
+	requestBody []byte
 }
 
 func (*HTTP) SampleConfig() string {
@@ -59,6 +61,19 @@ func (h *HTTP) Init() error {
 		return errors.New("either use 'token_file' or 'token' not both")
 	}
 
+	// Encode the request body once as it does not change between requests
+	if h.Body != "" {
+		encoder, err := internal.NewContentEncoder(h.ContentEncoding)
+		if err != nil {
+			return fmt.Errorf("creating encoder for %q failed: %w", h.ContentEncoding, err)
+		}
+		body, err := encoder.Encode([]byte(h.Body))
+		if err != nil {
+			return fmt.Errorf("encoding body failed: %w", err)
+		}
+		h.requestBody = body
+	}
+
 	// Create the client
 	ctx := context.Background()
 	client, err := h.HTTPClientConfig.CreateClient(ctx, h.Log)
@@ -115,18 +130,15 @@ func (h *HTTP) Stop() {
 //
 //	error: Any error that may have occurred
 func (h *HTTP) gatherURL(acc telegraf.Accumulator, url string) error {
-	body := makeRequestBodyReader(h.ContentEncoding, h.Body)
-	if body != nil {
-		defer body.Close()
+	var body io.Reader
+	if h.requestBody != nil {
+		body = bytes.NewReader(h.requestBody)
 	}
 
 	request, err := http.NewRequest(h.Method, url, body)
 	if err != nil {
 		return err
 	}
-	if body != nil && h.ContentEncoding != "gzip" {
-		request.ContentLength = int64(len(h.Body))
-	}
 
 	if !h.Token.Empty() {
 		token, err := h.Token.Get()
@@ -145,8 +157,8 @@ func (h *HTTP) gatherURL(acc telegraf.Accumulator, url string) error {
 		request.Header.Set("Authorization", bearer)
 	}
 
-	if h.ContentEncoding == "gzip" {
-		request.Header.Set("Content-Encoding", "gzip")
+	if h.ContentEncoding != "" && h.ContentEncoding != "identity" {
+		request.Header.Set("Content-Encoding", h.ContentEncoding)
 	}
 
 	for k, v := range h.Headers {
@@ -243,19 +255,6 @@ func (h *HTTP) setRequestAuth(request *http.Request) error {
 	return nil
 }
 
-func makeRequestBodyReader(contentEncoding, body string) io.ReadCloser {
-	if body == "" {
-		return nil
-	}
-
-	reader := strings.NewReader(body)
-	if contentEncoding == "gzip" {
-		return internal.CompressWithGzip(reader)
-	}
-
-	return io.NopCloser(reader)
-}
-
 func init() {
 	inputs.Add("http", func() telegraf.Input {
 		return &HTTP{
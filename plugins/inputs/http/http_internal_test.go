@@ -1,35 +1,34 @@
 package http
 
 import (
+	"bytes"
 	"compress/gzip"
 	"io"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/internal/compress"
 )
 
-func TestMakeRequestBodyReaderEmptyBody(t *testing.T) {
-	body := makeRequestBodyReader("", "")
-	require.Nil(t, body)
+func TestInitEmptyBody(t *testing.T) {
+	h := &HTTP{ContentEncoding: "gzip"}
+	require.NoError(t, h.Init())
+	require.Nil(t, h.requestBody)
 }
 
-func TestMakeRequestBodyReaderNoEncoding(t *testing.T) {
-	body := makeRequestBodyReader("", "payload")
-	require.NotNil(t, body)
-	t.Cleanup(func() { _ = body.Close() })
-
-	actual, err := io.ReadAll(body)
-	require.NoError(t, err)
-	require.Equal(t, []byte("payload"), actual)
+func TestInitBodyNoEncoding(t *testing.T) {
+	h := &HTTP{Body: "payload"}
+	require.NoError(t, h.Init())
+	require.Equal(t, []byte("payload"), h.requestBody)
 }
 
-func TestMakeRequestBodyReaderGzip(t *testing.T) {
-	body := makeRequestBodyReader("gzip", "payload")
-	require.NotNil(t, body)
-	t.Cleanup(func() { _ = body.Close() })
+func TestInitBodyGzip(t *testing.T) {
+	h := &HTTP{Body: "payload", ContentEncoding: "gzip"}
+	require.NoError(t, h.Init())
 
-	reader, err := gzip.NewReader(body)
+	reader, err := gzip.NewReader(bytes.NewReader(h.requestBody))
 	require.NoError(t, err)
 	t.Cleanup(func() { _ = reader.Close() })
 
@@ -38,12 +37,33 @@ func TestMakeRequestBodyReaderGzip(t *testing.T) {
 	require.Equal(t, []byte("payload"), actual)
 }
 
+func TestInitBodyCodecs(t *testing.T) {
+	for _, encoding := range []string{"zlib", "zstd", "lz4", "snappy"} {
+		t.Run(encoding, func(t *testing.T) {
+			h := &HTTP{Body: "payload", ContentEncoding: encoding}
+			require.NoError(t, h.Init())
+
+			decoder, err := compress.NewDecoder(encoding, 1024)
+			require.NoError(t, err)
+			actual, err := decoder.Decode(h.requestBody)
+			require.NoError(t, err)
+			require.Equal(t, []byte("payload"), actual)
+		})
+	}
+}
+
+func TestInitInvalidContentEncoding(t *testing.T) {
+	h := &HTTP{Body: "payload", ContentEncoding: "brotli"}
+	require.ErrorContains(t, h.Init(), `creating encoder for "brotli" failed`)
+}
+
 func TestGatherURLEarlyFailureWithGzipBody(t *testing.T) {
 	h := &HTTP{
 		Method:          "BAD METHOD",
 		Body:            "payload",
 		ContentEncoding: "gzip",
 	}
+	require.NoError(t, h.Init())
 
 	done := make(chan error, 1)
 	go func() {
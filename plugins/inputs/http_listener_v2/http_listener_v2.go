@@ -272,13 +272,18 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 	}
 
 	metrics, err := h.Parse(bytes)
-	if err != nil {
+	if err != nil && len(metrics) == 0 {
 		h.Log.Debugf("Parse error: %s", err.Error())
 		if err := badRequest(res); err != nil {
 			h.Log.Debugf("error in bad-request: %v", err)
 		}
 		return
 	}
+	if err != nil {
+		// Parsers skipping invalid data return the valid metrics together
+		// with the errors, so keep the metrics and report the errors
+		h.acc.AddError(fmt.Errorf("partially parsed request: %w", err))
+	}
 
 	if len(metrics) == 0 {
 		once.Do(func() {
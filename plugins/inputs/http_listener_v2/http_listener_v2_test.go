@@ -559,6 +559,34 @@ func TestWriteHTTPInvalid(t *testing.T) {
 	require.EqualValues(t, 400, resp.StatusCode)
 }
 
+func TestWriteHTTPPermissive(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	parser := &influx.Parser{Permissive: true}
+	require.NoError(t, parser.Init())
+	listener.Parser = parser
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// The valid metrics are kept and the invalid line is reported
+	resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), "", bytes.NewBufferString(testMsg+badMsg))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, 204, resp.StatusCode)
+
+	acc.Wait(1)
+	acc.AssertContainsTaggedFields(t, "cpu_load_short",
+		map[string]interface{}{"value": float64(12)},
+		map[string]string{"host": "server01"},
+	)
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "partially parsed request")
+	require.ErrorContains(t, acc.Errors[0], "at 2:")
+}
+
 func TestWriteHTTPEmpty(t *testing.T) {
 	listener, err := newTestHTTPListenerV2()
 	require.NoError(t, err)
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -17,8 +18,10 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/ackwal"
 	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
@@ -30,6 +33,7 @@ const (
 	defaultMaxUndeliveredMessages = 1000
 	defaultMaxProcessingTime      = config.Duration(100 * time.Millisecond)
 	defaultConsumerGroup          = "telegraf_metrics_consumers"
+	defaultMaxDecompressionSize   = 500 * 1024 * 1024
 	reconnectDelay                = 5 * time.Second
 )
 
@@ -38,6 +42,8 @@ type KafkaConsumer struct {
 	Version                              string          `toml:"kafka_version"`
 	ConsumerGroup                        string          `toml:"consumer_group"`
 	MaxMessageLen                        int             `toml:"max_message_len"`
+	ContentEncoding                      string          `toml:"content_encoding"`
+	MaxDecompressionSize                 config.Size     `toml:"max_decompression_size"`
 	MaxUndeliveredMessages               int             `toml:"max_undelivered_messages"`
 	MaxProcessingTime                    config.Duration `toml:"max_processing_time"`
 	Offset                               string          `toml:"offset"`
@@ -53,6 +59,7 @@ type KafkaConsumer struct {
 	ConsumerFetchMaxWait                 config.Duration `toml:"consumer_fetch_max_wait"`
 	ConnectionStrategy                   string          `toml:"connection_strategy" deprecated:"1.33.0;1.40.0;use 'startup_error_behavior' instead"`
 	ResolveCanonicalBootstrapServersOnly bool            `toml:"resolve_canonical_bootstrap_servers_only"`
+	TrackingWAL                          string          `toml:"tracking_wal"`
 	Log                                  telegraf.Logger `toml:"-"`
 	kafka.ReadConfig
 
@@ -65,10 +72,13 @@ type KafkaConsumer struct {
 	allWantedTopics []string
 	fingerprint     string
 
-	parser    telegraf.Parser
-	topicLock sync.Mutex
-	wg        sync.WaitGroup
-	cancel    context.CancelFunc
+	decoder     internal.ContentDecoder
+	parser      telegraf.Parser
+	bytesParsed selfstat.Stat
+	wal         *ackwal.WAL
+	topicLock   sync.Mutex
+	wg          sync.WaitGroup
+	cancel      context.CancelFunc
 }
 
 // consumerGroupHandler is a sarama.ConsumerGroupHandler implementation.
@@ -79,11 +89,13 @@ type consumerGroupHandler struct {
 	msgHeaderToMetricName string
 	timestampSource       string
 
-	acc    telegraf.TrackingAccumulator
-	sem    semaphore
-	parser telegraf.Parser
-	wg     sync.WaitGroup
-	cancel context.CancelFunc
+	acc     telegraf.TrackingAccumulator
+	sem     semaphore
+	decoder internal.ContentDecoder
+	parser  telegraf.Parser
+	wal     *ackwal.WAL
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
 
 	mu          sync.Mutex
 	undelivered map[telegraf.TrackingID]message
@@ -143,6 +155,19 @@ func (k *KafkaConsumer) Init() error {
 		return fmt.Errorf("invalid timestamp source %q", k.TimestampSource)
 	}
 
+	if k.MaxDecompressionSize == 0 {
+		k.MaxDecompressionSize = defaultMaxDecompressionSize
+	}
+	// Messages do not carry their encoding so it cannot be detected
+	if k.ContentEncoding == "auto" {
+		return errors.New(`content_encoding "auto" is not supported`)
+	}
+	decoder, err := internal.NewContentDecoder(k.ContentEncoding, internal.WithMaxDecompressionSize(int64(k.MaxDecompressionSize)))
+	if err != nil {
+		return fmt.Errorf("creating decoder failed: %w", err)
+	}
+	k.decoder = decoder
+
 	cfg := sarama.NewConfig()
 
 	// Kafka version 0.10.2.0 is required for consumer groups.
@@ -233,6 +258,15 @@ func (k *KafkaConsumer) SetParser(parser telegraf.Parser) {
 func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 	var err error
 
+	// Account for the size of the consumed data resulting in metrics if the
+	// parser supports it
+	if p, ok := k.parser.(telegraf.SizeAccountingParser); ok {
+		k.bytesParsed = selfstat.Register("kafka_consumer", "bytes_parsed", map[string]string{"consumer_group": k.ConsumerGroup})
+		p.SetSizeCallback(func(_ []telegraf.Metric, size int) {
+			k.bytesParsed.Incr(int64(size))
+		})
+	}
+
 	// If TopicRegexps is set, add matches to Topics
 	if len(k.TopicRegexps) > 0 {
 		if err := k.refreshTopics(); err != nil {
@@ -240,12 +274,24 @@ func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 		}
 	}
 
+	if k.TrackingWAL != "" {
+		w, err := ackwal.Open(k.TrackingWAL, 2*k.MaxUndeliveredMessages)
+		if err != nil {
+			return fmt.Errorf("opening tracking wal failed: %w", err)
+		}
+		k.wal = w
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	k.cancel = cancel
 
 	if k.ConnectionStrategy != "defer" {
 		err = k.create()
 		if err != nil {
+			if k.wal != nil {
+				k.wal.Close()
+				k.wal = nil
+			}
 			return &internal.StartupError{
 				Err:   fmt.Errorf("create consumer: %w", err),
 				Retry: errors.Is(err, sarama.ErrOutOfBrokers),
@@ -273,6 +319,7 @@ func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 		for ctx.Err() == nil {
 			handler := newConsumerGroupHandler(acc, k.MaxUndeliveredMessages, k.parser, k.Log)
 			handler.maxMessageLen = k.MaxMessageLen
+			handler.decoder = k.decoder
 			handler.topicTag = k.TopicTag
 			handler.msgHeaderToMetricName = k.MsgHeaderAsMetricName
 			// if message headers list specified, put it as map to handler
@@ -286,6 +333,7 @@ func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 			}
 			handler.msgHeadersToTags = msgHeadersMap
 			handler.timestampSource = k.TimestampSource
+			handler.wal = k.wal
 
 			// We need to copy allWantedTopics; the Consume() is
 			// long-running and we can easily deadlock if our
@@ -323,6 +371,12 @@ func (k *KafkaConsumer) Stop() {
 
 	k.cancel()
 	k.wg.Wait()
+
+	if k.wal != nil {
+		if err := k.wal.Close(); err != nil {
+			k.Log.Errorf("Closing tracking wal failed: %v", err)
+		}
+	}
 }
 
 func (k *KafkaConsumer) compileTopicRegexps() error {
@@ -489,15 +543,48 @@ func (h *consumerGroupHandler) run(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case track := <-h.acc.Delivered():
-			h.onDelivery(track)
+			h.onDeliveries(track)
 		}
 	}
 }
 
-func (h *consumerGroupHandler) onDelivery(track telegraf.DeliveryInfo) {
+// onDeliveries handles the given delivery together with all further deliveries
+// already available, so the delivered messages are recorded in the tracking
+// wal at once.
+func (h *consumerGroupHandler) onDeliveries(track telegraf.DeliveryInfo) {
+	tracks := []telegraf.DeliveryInfo{track}
+collect:
+	for {
+		select {
+		case track := <-h.acc.Delivered():
+			tracks = append(tracks, track)
+		default:
+			break collect
+		}
+	}
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	if h.wal != nil {
+		keys := make([]string, 0, len(tracks))
+		for _, track := range tracks {
+			if msg, ok := h.undelivered[track.ID()]; ok && track.Delivered() {
+				keys = append(keys, walKey(msg.message))
+			}
+		}
+		if err := h.wal.Delivered(keys...); err != nil {
+			h.log.Errorf("Recording deliveries in tracking wal failed: %v", err)
+		}
+	}
+
+	for _, track := range tracks {
+		h.onDelivery(track)
+	}
+}
+
+// onDelivery marks the message of the given delivery, the lock must be held
+func (h *consumerGroupHandler) onDelivery(track telegraf.DeliveryInfo) {
 	msg, ok := h.undelivered[track.ID()]
 	if !ok {
 		h.log.Errorf("Could not mark message delivered: %d", track.ID())
@@ -528,6 +615,14 @@ func (h *consumerGroupHandler) release() {
 
 // handle processes a message and if successful saves it to be acknowledged after delivery.
 func (h *consumerGroupHandler) handle(session sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage) error {
+	// Skip messages delivered before a restart but not committed in time
+	if h.wal != nil && h.wal.Seen(walKey(msg)) {
+		h.log.Debugf("Skipping already delivered message at offset %d of %s/%d", msg.Offset, msg.Topic, msg.Partition)
+		session.MarkMessage(msg, "")
+		h.release()
+		return nil
+	}
+
 	if h.maxMessageLen != 0 && len(msg.Value) > h.maxMessageLen {
 		session.MarkMessage(msg, "")
 		h.release()
@@ -535,7 +630,18 @@ func (h *consumerGroupHandler) handle(session sarama.ConsumerGroupSession, msg *
 			len(msg.Value), h.maxMessageLen)
 	}
 
-	metrics, err := h.parser.Parse(msg.Value)
+	payload := msg.Value
+	if h.decoder != nil {
+		decoded, err := h.decoder.Decode(payload)
+		if err != nil {
+			session.MarkMessage(msg, "")
+			h.release()
+			return fmt.Errorf("decoding message failed: %w", err)
+		}
+		payload = decoded
+	}
+
+	metrics, err := h.parser.Parse(payload)
 	if err != nil {
 		session.MarkMessage(msg, "")
 		h.release()
@@ -594,6 +700,11 @@ func (h *consumerGroupHandler) handle(session sarama.ConsumerGroupSession, msg *
 	return nil
 }
 
+// walKey identifies the message in the tracking wal
+func walKey(msg *sarama.ConsumerMessage) string {
+	return msg.Topic + "/" + strconv.Itoa(int(msg.Partition)) + "/" + strconv.FormatInt(msg.Offset, 10)
+}
+
 func init() {
 	inputs.Add("kafka_consumer", func() telegraf.Input {
 		return &KafkaConsumer{}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/common/ackwal"
 	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -224,6 +226,22 @@ func TestInit(t *testing.T) {
 				require.Equal(t, 250*time.Millisecond, plugin.config.Consumer.MaxWaitTime)
 			},
 		},
+		{
+			name: "invalid content_encoding",
+			plugin: &KafkaConsumer{
+				ContentEncoding: "brotli",
+				Log:             testutil.Logger{},
+			},
+			initError: true,
+		},
+		{
+			name: "auto content_encoding",
+			plugin: &KafkaConsumer{
+				ContentEncoding: "auto",
+				Log:             testutil.Logger{},
+			},
+			initError: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -257,6 +275,58 @@ func TestStartStop(t *testing.T) {
 	plugin.Stop()
 }
 
+func TestBytesParsed(t *testing.T) {
+	influxParser := &influx.Parser{Permissive: true}
+	require.NoError(t, influxParser.Init())
+	valueParser := models.NewRunningParser(
+		&value.Parser{MetricName: "cpu", DataType: "int"},
+		&models.ParserConfig{DataFormat: "value"},
+	)
+	require.NoError(t, valueParser.Init())
+
+	tests := []struct {
+		name     string
+		parser   telegraf.Parser
+		input    string
+		expected int64
+	}{
+		{
+			name:     "size accounting parser",
+			parser:   influxParser,
+			input:    "cpu value=42\ninvalid line\nmem value=1\n",
+			expected: 38,
+		},
+		{
+			name:     "running parser",
+			parser:   valueParser,
+			input:    "42",
+			expected: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cg := &fakeConsumerGroup{errors: make(chan error)}
+			plugin := &KafkaConsumer{
+				ConsumerGroup:   "TestBytesParsed " + tt.name,
+				consumerCreator: &fakeCreator{consumerGroup: cg},
+				Log:             testutil.Logger{},
+			}
+			plugin.SetParser(tt.parser)
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			defer plugin.Stop()
+
+			require.NotNil(t, plugin.bytesParsed)
+			_, err := plugin.parser.Parse([]byte(tt.input))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, plugin.bytesParsed.Get())
+		})
+	}
+}
+
 type FakeConsumerGroupSession struct {
 	ctx context.Context
 }
@@ -498,6 +568,104 @@ func TestConsumerGroupHandlerHandle(t *testing.T) {
 	}
 }
 
+func TestConsumerGroupHandlerHandleEncoded(t *testing.T) {
+	for _, encoding := range []string{"gzip", "zstd", "lz4", "snappy"} {
+		t.Run(encoding, func(t *testing.T) {
+			encoder, err := internal.NewContentEncoder(encoding)
+			require.NoError(t, err)
+			payload, err := encoder.Encode([]byte("42"))
+			require.NoError(t, err)
+
+			decoder, err := internal.NewContentDecoder(encoding, internal.WithMaxDecompressionSize(1024))
+			require.NoError(t, err)
+
+			acc := &testutil.Accumulator{}
+			parser := value.Parser{
+				MetricName: "cpu",
+				DataType:   "int",
+			}
+			require.NoError(t, parser.Init())
+			cg := newConsumerGroupHandler(acc, 1, &parser, testutil.Logger{})
+			cg.decoder = decoder
+
+			session := &FakeConsumerGroupSession{ctx: t.Context()}
+			require.NoError(t, cg.reserve(t.Context()))
+			require.NoError(t, cg.handle(session, &sarama.ConsumerMessage{Topic: "telegraf", Value: payload}))
+
+			expected := []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+			}
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestConsumerGroupHandlerHandleDecodeError(t *testing.T) {
+	decoder, err := internal.NewContentDecoder("gzip", internal.WithMaxDecompressionSize(1024))
+	require.NoError(t, err)
+
+	acc := &testutil.Accumulator{}
+	parser := value.Parser{
+		MetricName: "cpu",
+		DataType:   "int",
+	}
+	require.NoError(t, parser.Init())
+	cg := newConsumerGroupHandler(acc, 1, &parser, testutil.Logger{})
+	cg.decoder = decoder
+
+	session := &FakeConsumerGroupSession{ctx: t.Context()}
+	require.NoError(t, cg.reserve(t.Context()))
+	err = cg.handle(session, &sarama.ConsumerMessage{Topic: "telegraf", Value: []byte("42")})
+	require.ErrorContains(t, err, "decoding message failed")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestConsumerGroupHandlerTrackingWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acks")
+
+	// Simulate a message delivered to the outputs in a previous run with the
+	// offset not being committed before the restart
+	w, err := ackwal.Open(path, 10)
+	require.NoError(t, err)
+	require.NoError(t, w.Delivered("telegraf/0/1"))
+	require.NoError(t, w.Close())
+
+	w, err = ackwal.Open(path, 10)
+	require.NoError(t, err)
+	defer w.Close()
+
+	acc := &testutil.Accumulator{}
+	parser := value.Parser{
+		MetricName: "cpu",
+		DataType:   "int",
+	}
+	require.NoError(t, parser.Init())
+	cg := newConsumerGroupHandler(acc, 2, &parser, testutil.Logger{})
+	cg.wal = w
+
+	session := &FakeConsumerGroupSession{ctx: t.Context()}
+
+	// The already delivered message must be skipped
+	require.NoError(t, cg.reserve(t.Context()))
+	require.NoError(t, cg.handle(session, &sarama.ConsumerMessage{Topic: "telegraf", Offset: 1, Value: []byte("23")}))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	require.NoError(t, cg.reserve(t.Context()))
+	require.NoError(t, cg.handle(session, &sarama.ConsumerMessage{Topic: "telegraf", Offset: 2, Value: []byte("42")}))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"value": 42,
+			},
+			time.Now(),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
 func TestExponentialBackoff(t *testing.T) {
 	var err error
 
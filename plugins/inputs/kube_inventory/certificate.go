@@ -19,7 +19,7 @@ func collectSecrets(ctx context.Context, acc telegraf.Accumulator, ki *Kubernete
 		return
 	}
 	for _, i := range list.Items {
-		gatherCertificates(i, acc)
+		gatherCertificates(i, ki.withLabels(acc, "secrets", i.Labels))
 	}
 }
 
@@ -2,7 +2,10 @@ package kube_inventory
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -10,9 +13,13 @@ import (
 	discoveryv1 "k8s.io/api/discovery/v1"
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/common/tls"
@@ -21,20 +28,60 @@ import (
 type client struct {
 	namespace string
 	timeout   time.Duration
+	chunkSize int64
+	dynamic   dynamic.Interface
 	*kubernetes.Clientset
 }
 
-func newClient(baseURL, namespace, bearerTokenFile string, timeout time.Duration, tlsConfig tls.ClientConfig) (*client, error) {
-	var clientConfig *rest.Config
-	var err error
+// pagedList is implemented by all list types returned by the API
+type pagedList interface {
+	GetContinue() string
+	SetContinue(c string)
+}
+
+// listPages lists the resources in chunks of the configured size using the
+// continue token of the previous response and merges the items of all pages.
+// The timeout applies to each page request.
+func listPages[L pagedList](
+	ctx context.Context,
+	c *client,
+	opts metav1.ListOptions,
+	list func(context.Context, metav1.ListOptions) (L, error),
+	merge func(dst, src L),
+) (L, error) {
+	opts.Limit = c.chunkSize
 
-	if baseURL == "" {
-		clientConfig, err = rest.InClusterConfig()
+	var result L
+	for page := 0; ; page++ {
+		pageCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		l, err := list(pageCtx, opts)
+		cancel()
 		if err != nil {
-			return nil, err
+			return result, err
+		}
+
+		if page == 0 {
+			result = l
+		} else {
+			merge(result, l)
+		}
+
+		opts.Continue = l.GetContinue()
+		if opts.Continue == "" {
+			result.SetContinue("")
+			return result, nil
 		}
-	} else {
-		clientConfig = &rest.Config{
+	}
+}
+
+// newRestConfig determines the configuration to access the Kubernetes API.
+// An explicit URL takes precedence over a kubeconfig file. Without both,
+// the in-cluster configuration is used when running inside a cluster and the
+// default kubeconfig locations are used otherwise, so the same settings work
+// inside and outside of the cluster.
+func newRestConfig(baseURL, kubeconfig, kubeContext, bearerTokenFile string, tlsConfig tls.ClientConfig) (*rest.Config, error) {
+	if baseURL != "" {
+		clientConfig := &rest.Config{
 			TLSClientConfig: rest.TLSClientConfig{
 				ServerName: tlsConfig.ServerName,
 				Insecure:   tlsConfig.InsecureSkipVerify,
@@ -49,16 +96,49 @@ func newClient(baseURL, namespace, bearerTokenFile string, timeout time.Duration
 		if bearerTokenFile != "" {
 			clientConfig.BearerTokenFile = bearerTokenFile
 		}
+		return clientConfig, nil
 	}
 
+	if kubeconfig == "" {
+		clientConfig, err := rest.InClusterConfig()
+		if err == nil {
+			return clientConfig, nil
+		}
+		if !errors.Is(err, rest.ErrNotInCluster) {
+			return nil, fmt.Errorf("loading in-cluster config failed: %w", err)
+		}
+	}
+
+	// Use the given kubeconfig or fall back to the default locations
+	// including the KUBECONFIG environment variable
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	clientConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig failed: %w", err)
+	}
+	return clientConfig, nil
+}
+
+func newClient(clientConfig *rest.Config, namespace string, timeout time.Duration, chunkSize int64) (*client, error) {
 	c, err := kubernetes.NewForConfig(clientConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	d, err := dynamic.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &client{
 		Clientset: c,
+		dynamic:   d,
 		timeout:   timeout,
+		chunkSize: chunkSize,
 		namespace: namespace,
 	}, nil
 }
@@ -80,85 +160,108 @@ func newHTTPClient(tlsConfig tls.ClientConfig, bearerTokenFile string, responseT
 }
 
 func (c *client) getDaemonSets(ctx context.Context) (*appsv1.DaemonSetList, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-	return c.AppsV1().DaemonSets(c.namespace).List(ctx, metav1.ListOptions{})
+	return listPages(ctx, c, metav1.ListOptions{}, c.AppsV1().DaemonSets(c.namespace).List,
+		func(dst, src *appsv1.DaemonSetList) { dst.Items = append(dst.Items, src.Items...) })
 }
 
 func (c *client) getDeployments(ctx context.Context) (*appsv1.DeploymentList, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-	return c.AppsV1().Deployments(c.namespace).List(ctx, metav1.ListOptions{})
+	return listPages(ctx, c, metav1.ListOptions{}, c.AppsV1().Deployments(c.namespace).List,
+		func(dst, src *appsv1.DeploymentList) { dst.Items = append(dst.Items, src.Items...) })
 }
 
 func (c *client) getEndpoints(ctx context.Context) (*discoveryv1.EndpointSliceList, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-	return c.DiscoveryV1().EndpointSlices(c.namespace).List(ctx, metav1.ListOptions{})
+	return listPages(ctx, c, metav1.ListOptions{}, c.DiscoveryV1().EndpointSlices(c.namespace).List,
+		func(dst, src *discoveryv1.EndpointSliceList) { dst.Items = append(dst.Items, src.Items...) })
 }
 
 func (c *client) getIngress(ctx context.Context) (*netv1.IngressList, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-	return c.NetworkingV1().Ingresses(c.namespace).List(ctx, metav1.ListOptions{})
+	return listPages(ctx, c, metav1.ListOptions{}, c.NetworkingV1().Ingresses(c.namespace).List,
+		func(dst, src *netv1.IngressList) { dst.Items = append(dst.Items, src.Items...) })
 }
 
 func (c *client) getNodes(ctx context.Context, name string) (*corev1.NodeList, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
 	var fieldSelector string
 	if name != "" {
 		fieldSelector = "metadata.name=" + name
 	}
-	return c.CoreV1().Nodes().List(ctx, metav1.ListOptions{FieldSelector: fieldSelector})
+	return listPages(ctx, c, metav1.ListOptions{FieldSelector: fieldSelector}, c.CoreV1().Nodes().List,
+		func(dst, src *corev1.NodeList) { dst.Items = append(dst.Items, src.Items...) })
+}
+
+func (c *client) getNamespaces(ctx context.Context) (*corev1.NamespaceList, error) {
+	// Namespaces are cluster scoped, so restrict the list to the configured one
+	var fieldSelector string
+	if c.namespace != "" {
+		fieldSelector = "metadata.name=" + c.namespace
+	}
+	return listPages(ctx, c, metav1.ListOptions{FieldSelector: fieldSelector}, c.CoreV1().Namespaces().List,
+		func(dst, src *corev1.NamespaceList) { dst.Items = append(dst.Items, src.Items...) })
 }
 
 func (c *client) getPersistentVolumes(ctx context.Context) (*corev1.PersistentVolumeList, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-	return c.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	return listPages(ctx, c, metav1.ListOptions{}, c.CoreV1().PersistentVolumes().List,
+		func(dst, src *corev1.PersistentVolumeList) { dst.Items = append(dst.Items, src.Items...) })
 }
 
 func (c *client) getPersistentVolumeClaims(ctx context.Context) (*corev1.PersistentVolumeClaimList, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-	return c.CoreV1().PersistentVolumeClaims(c.namespace).List(ctx, metav1.ListOptions{})
+	return listPages(ctx, c, metav1.ListOptions{}, c.CoreV1().PersistentVolumeClaims(c.namespace).List,
+		func(dst, src *corev1.PersistentVolumeClaimList) { dst.Items = append(dst.Items, src.Items...) })
 }
 
 func (c *client) getPods(ctx context.Context, nodeName string) (*corev1.PodList, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-
 	var fieldSelector string
 	if nodeName != "" {
 		fieldSelector = "spec.nodeName=" + nodeName
 	}
-	return c.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{FieldSelector: fieldSelector})
+	return listPages(ctx, c, metav1.ListOptions{FieldSelector: fieldSelector}, c.CoreV1().Pods(c.namespace).List,
+		func(dst, src *corev1.PodList) { dst.Items = append(dst.Items, src.Items...) })
 }
 
 func (c *client) getServices(ctx context.Context) (*corev1.ServiceList, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-	return c.CoreV1().Services(c.namespace).List(ctx, metav1.ListOptions{})
+	return listPages(ctx, c, metav1.ListOptions{}, c.CoreV1().Services(c.namespace).List,
+		func(dst, src *corev1.ServiceList) { dst.Items = append(dst.Items, src.Items...) })
 }
 
 func (c *client) getStatefulSets(ctx context.Context) (*appsv1.StatefulSetList, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-	return c.AppsV1().StatefulSets(c.namespace).List(ctx, metav1.ListOptions{})
+	return listPages(ctx, c, metav1.ListOptions{}, c.AppsV1().StatefulSets(c.namespace).List,
+		func(dst, src *appsv1.StatefulSetList) { dst.Items = append(dst.Items, src.Items...) })
 }
 
 func (c *client) getResourceQuotas(ctx context.Context) (*corev1.ResourceQuotaList, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-	return c.CoreV1().ResourceQuotas(c.namespace).List(ctx, metav1.ListOptions{})
+	return listPages(ctx, c, metav1.ListOptions{}, c.CoreV1().ResourceQuotas(c.namespace).List,
+		func(dst, src *corev1.ResourceQuotaList) { dst.Items = append(dst.Items, src.Items...) })
 }
 
 func (c *client) getTLSSecrets(ctx context.Context) (*corev1.SecretList, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
 	labelSelector := metav1.LabelSelector{MatchLabels: map[string]string{"type": "kubernetes.io/tls"}}
-	return c.CoreV1().Secrets(c.namespace).List(ctx, metav1.ListOptions{
+	opts := metav1.ListOptions{
 		FieldSelector: labels.Set(labelSelector.MatchLabels).String(),
-	})
+	}
+	return listPages(ctx, c, opts, c.CoreV1().Secrets(c.namespace).List,
+		func(dst, src *corev1.SecretList) { dst.Items = append(dst.Items, src.Items...) })
+}
+
+// getResourceForKind looks up the resource of the given kind in the given
+// group version and returns whether the resource is namespaced.
+func (c *client) getResourceForKind(gv schema.GroupVersion, kind string) (schema.GroupVersionResource, bool, error) {
+	list, err := c.Discovery().ServerResourcesForGroupVersion(gv.String())
+	if err != nil {
+		return schema.GroupVersionResource{}, false, err
+	}
+	for _, r := range list.APIResources {
+		// Skip subresources like "certificates/status"
+		if r.Kind == kind && !strings.Contains(r.Name, "/") {
+			return gv.WithResource(r.Name), r.Namespaced, nil
+		}
+	}
+	return schema.GroupVersionResource{}, false, fmt.Errorf("kind %q not found in %q", kind, gv.String())
+}
+
+func (c *client) getCustomResources(ctx context.Context, gvr schema.GroupVersionResource, namespaced bool) (*unstructured.UnstructuredList, error) {
+	list := c.dynamic.Resource(gvr).List
+	if namespaced {
+		list = c.dynamic.Resource(gvr).Namespace(c.namespace).List
+	}
+	return listPages(ctx, c, metav1.ListOptions{}, list,
+		func(dst, src *unstructured.UnstructuredList) { dst.Items = append(dst.Items, src.Items...) })
 }
@@ -1,6 +1,10 @@
 package kube_inventory
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -14,9 +18,157 @@ type mockHandler struct {
 }
 
 func TestNewClient(t *testing.T) {
-	_, err := newClient("https://127.0.0.1:443/", "default", "", time.Second, tls.ClientConfig{})
+	restConfig, err := newRestConfig("https://127.0.0.1:443/", "", "", "", tls.ClientConfig{})
+	require.NoError(t, err)
+	_, err = newClient(restConfig, "default", time.Second, 500)
 	require.NoErrorf(t, err, "Failed to create new client: %v", err)
 
-	_, err = newClient("https://127.0.0.1:443/", "default", "nonexistantFile", time.Second, tls.ClientConfig{})
+	restConfig, err = newRestConfig("https://127.0.0.1:443/", "", "", "nonexistantFile", tls.ClientConfig{})
+	require.NoError(t, err)
+	_, err = newClient(restConfig, "default", time.Second, 500)
 	require.Errorf(t, err, "Failed to read token file \"file\": open file: no such file or directory: %v", err)
 }
+
+const testKubeconfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com:6443
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+current-context: dev
+`
+
+func TestNewRestConfigKubeconfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(testKubeconfig), 0600))
+
+	tests := []struct {
+		name     string
+		context  string
+		env      bool
+		expected string
+	}{
+		{
+			name:     "current context",
+			expected: "https://dev.example.com:6443",
+		},
+		{
+			name:     "explicit context",
+			context:  "prod",
+			expected: "https://prod.example.com:6443",
+		},
+		{
+			name:     "default location",
+			context:  "prod",
+			env:      true,
+			expected: "https://prod.example.com:6443",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Make sure we are not detected as running inside a cluster
+			t.Setenv("KUBERNETES_SERVICE_HOST", "")
+			t.Setenv("KUBERNETES_SERVICE_PORT", "")
+
+			path := kubeconfig
+			if tt.env {
+				t.Setenv("KUBECONFIG", kubeconfig)
+				path = ""
+			}
+
+			restConfig, err := newRestConfig("", path, tt.context, defaultServiceAccountPath, tls.ClientConfig{})
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, restConfig.Host)
+			require.Equal(t, "secret", restConfig.BearerToken)
+			require.Empty(t, restConfig.BearerTokenFile)
+		})
+	}
+}
+
+func TestNewRestConfigInvalidContext(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(testKubeconfig), 0600))
+
+	_, err := newRestConfig("", kubeconfig, "staging", "", tls.ClientConfig{})
+	require.ErrorContains(t, err, "staging")
+}
+
+func TestListPagination(t *testing.T) {
+	pages := map[string]string{
+		"":      `{"kind":"PodList","apiVersion":"v1","metadata":{"continue":"page2"},"items":[{"metadata":{"name":"pod1"}},{"metadata":{"name":"pod2"}}]}`,
+		"page2": `{"kind":"PodList","apiVersion":"v1","metadata":{"continue":"page3"},"items":[{"metadata":{"name":"pod3"}},{"metadata":{"name":"pod4"}}]}`,
+		"page3": `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"pod5"}}]}`,
+	}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api/v1/namespaces/default/pods" || r.URL.Query().Get("limit") != "2" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		page, found := pages[r.URL.Query().Get("continue")]
+		if !found {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(page)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	restConfig, err := newRestConfig(server.URL, "", "", "", tls.ClientConfig{})
+	require.NoError(t, err)
+	c, err := newClient(restConfig, "default", time.Second, 2)
+	require.NoError(t, err)
+
+	list, err := c.getPods(t.Context(), "")
+	require.NoError(t, err)
+	require.Equal(t, 3, requests)
+	require.Empty(t, list.Continue)
+
+	names := make([]string, 0, len(list.Items))
+	for _, p := range list.Items {
+		names = append(names, p.Name)
+	}
+	require.Equal(t, []string{"pod1", "pod2", "pod3", "pod4", "pod5"}, names)
+}
+
+// newForbiddenClient returns a client for a server denying access to all
+// resources
+func newForbiddenClient(t *testing.T) *client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		status := `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`
+		if _, err := w.Write([]byte(status)); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	restConfig, err := newRestConfig(server.URL, "", "", "", tls.ClientConfig{})
+	require.NoError(t, err)
+	c, err := newClient(restConfig, "default", time.Second, 0)
+	require.NoError(t, err)
+	return c
+}
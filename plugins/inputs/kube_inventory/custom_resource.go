@@ -0,0 +1,152 @@
+package kube_inventory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/influxdata/telegraf"
+)
+
+// customResource describes a custom resource to collect via the dynamic
+// client with the fields and tags extracted using JSONPath expressions
+type customResource struct {
+	Group       string            `toml:"group"`
+	Version     string            `toml:"version"`
+	Kind        string            `toml:"kind"`
+	Measurement string            `toml:"measurement"`
+	Fields      map[string]string `toml:"fields"`
+	Tags        map[string]string `toml:"tags"`
+
+	fields map[string]*jsonpath.JSONPath
+	tags   map[string]*jsonpath.JSONPath
+
+	// Resource resolved from the kind on first collection
+	resource   *schema.GroupVersionResource
+	namespaced bool
+}
+
+func (cr *customResource) init() error {
+	if cr.Version == "" {
+		return errors.New("version required")
+	}
+	if cr.Kind == "" {
+		return errors.New("kind required")
+	}
+	if len(cr.Fields) == 0 {
+		return errors.New("at least one field required")
+	}
+	if cr.Measurement == "" {
+		cr.Measurement = "kubernetes_" + strings.ToLower(cr.Kind)
+	}
+
+	var err error
+	if cr.fields, err = compileJSONPaths(cr.Fields); err != nil {
+		return fmt.Errorf("fields: %w", err)
+	}
+	if cr.tags, err = compileJSONPaths(cr.Tags); err != nil {
+		return fmt.Errorf("tags: %w", err)
+	}
+	return nil
+}
+
+func compileJSONPaths(expressions map[string]string) (map[string]*jsonpath.JSONPath, error) {
+	paths := make(map[string]*jsonpath.JSONPath, len(expressions))
+	for name, expr := range expressions {
+		p := jsonpath.New(name).AllowMissingKeys(true)
+		if err := p.Parse(expr); err != nil {
+			return nil, fmt.Errorf("parsing expression %q of %q failed: %w", expr, name, err)
+		}
+		paths[name] = p
+	}
+	return paths, nil
+}
+
+func collectCustomResource(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesInventory, cr *customResource) {
+	if cr.resource == nil {
+		gv := schema.GroupVersion{Group: cr.Group, Version: cr.Version}
+		gvr, namespaced, err := ki.client.getResourceForKind(gv, cr.Kind)
+		if err != nil {
+			acc.AddError(fmt.Errorf("resolving custom resource %q failed: %w", cr.Kind, err))
+			return
+		}
+		cr.resource = &gvr
+		cr.namespaced = namespaced
+	}
+
+	list, err := ki.client.getCustomResources(ctx, *cr.resource, cr.namespaced)
+	if err != nil {
+		acc.AddError(err)
+		return
+	}
+	for i := range list.Items {
+		ki.gatherCustomResource(cr, &list.Items[i], acc)
+	}
+}
+
+func (ki *KubernetesInventory) gatherCustomResource(cr *customResource, obj *unstructured.Unstructured, acc telegraf.Accumulator) {
+	fields := make(map[string]interface{}, len(cr.fields))
+	for name, p := range cr.fields {
+		if v, found := ki.extractValue(p, obj.Object); found {
+			fields[name] = v
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	tags := map[string]string{
+		"name": obj.GetName(),
+	}
+	if namespace := obj.GetNamespace(); namespace != "" {
+		tags["namespace"] = namespace
+	}
+	for name, p := range cr.tags {
+		if v, found := ki.extractValue(p, obj.Object); found {
+			tags[name] = fmt.Sprint(v)
+		}
+	}
+
+	acc.AddFields(cr.Measurement, fields, tags)
+}
+
+// extractValue returns the first value matched by the given JSONPath
+// expression. Objects and lists are serialized to JSON.
+func (ki *KubernetesInventory) extractValue(p *jsonpath.JSONPath, data map[string]interface{}) (interface{}, bool) {
+	results, err := p.FindResults(data)
+	if err != nil {
+		ki.Log.Debugf("Evaluating JSONPath failed: %v", err)
+		return nil, false
+	}
+	for _, result := range results {
+		for _, rv := range result {
+			if !rv.IsValid() || !rv.CanInterface() {
+				continue
+			}
+			switch v := rv.Interface().(type) {
+			case nil:
+				continue
+			case string, bool, int64, float64:
+				return v, true
+			default:
+				if kind := reflect.ValueOf(v).Kind(); kind == reflect.Map || kind == reflect.Slice {
+					buf, err := json.Marshal(v)
+					if err != nil {
+						ki.Log.Debugf("Serializing value failed: %v", err)
+						return nil, false
+					}
+					return string(buf), true
+				}
+				return fmt.Sprint(v), true
+			}
+		}
+	}
+	return nil, false
+}
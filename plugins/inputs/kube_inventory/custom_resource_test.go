@@ -0,0 +1,115 @@
+package kube_inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestCustomResource(t *testing.T) {
+	cr := &customResource{
+		Group:   "cert-manager.io",
+		Version: "v1",
+		Kind:    "Certificate",
+		Fields: map[string]string{
+			"ready":     `{.status.conditions[?(@.type=="Ready")].status}`,
+			"revision":  `{.status.revision}`,
+			"dns_names": `{.spec.dnsNames}`,
+			"missing":   `{.status.missing}`,
+		},
+		Tags: map[string]string{
+			"issuer": `{.spec.issuerRef.name}`,
+		},
+	}
+	require.NoError(t, cr.init())
+	require.Equal(t, "kubernetes_certificate", cr.Measurement)
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata": map[string]interface{}{
+			"name":      "example-com",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"dnsNames":  []interface{}{"example.com", "www.example.com"},
+			"issuerRef": map[string]interface{}{"name": "letsencrypt"},
+		},
+		"status": map[string]interface{}{
+			"revision": int64(3),
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Issuing", "status": "False"},
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			},
+		},
+	}}
+
+	// Objects without any of the fields are skipped
+	empty := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "empty"},
+	}}
+
+	ki := &KubernetesInventory{Log: testutil.Logger{}}
+	acc := new(testutil.Accumulator)
+	ki.gatherCustomResource(cr, obj, acc)
+	ki.gatherCustomResource(cr, empty, acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"kubernetes_certificate",
+			map[string]string{
+				"name":      "example-com",
+				"namespace": "default",
+				"issuer":    "letsencrypt",
+			},
+			map[string]interface{}{
+				"ready":     "True",
+				"revision":  int64(3),
+				"dns_names": `["example.com","www.example.com"]`,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestCustomResourceInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource *customResource
+		expected string
+	}{
+		{
+			name:     "missing version",
+			resource: &customResource{Kind: "Certificate", Fields: map[string]string{"a": "{.a}"}},
+			expected: "version required",
+		},
+		{
+			name:     "missing kind",
+			resource: &customResource{Version: "v1", Fields: map[string]string{"a": "{.a}"}},
+			expected: "kind required",
+		},
+		{
+			name:     "missing fields",
+			resource: &customResource{Version: "v1", Kind: "Certificate"},
+			expected: "at least one field required",
+		},
+		{
+			name:     "invalid expression",
+			resource: &customResource{Version: "v1", Kind: "Certificate", Fields: map[string]string{"a": "{.a"}},
+			expected: `fields: parsing expression "{.a" of "a" failed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.resource.init(), tt.expected)
+		})
+	}
+}
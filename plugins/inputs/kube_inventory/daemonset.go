@@ -15,7 +15,7 @@ func collectDaemonSets(ctx context.Context, acc telegraf.Accumulator, ki *Kubern
 		return
 	}
 	for i := range list.Items {
-		ki.gatherDaemonSet(&list.Items[i], acc)
+		ki.gatherDaemonSet(&list.Items[i], ki.withLabels(acc, "daemonsets", list.Items[i].Labels))
 	}
 }
 
@@ -15,7 +15,7 @@ func collectDeployments(ctx context.Context, acc telegraf.Accumulator, ki *Kuber
 		return
 	}
 	for i := range list.Items {
-		ki.gatherDeployment(&list.Items[i], acc)
+		ki.gatherDeployment(&list.Items[i], ki.withLabels(acc, "deployments", list.Items[i].Labels))
 	}
 }
 
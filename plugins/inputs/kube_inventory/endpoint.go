@@ -16,7 +16,7 @@ func collectEndpoints(ctx context.Context, acc telegraf.Accumulator, ki *Kuberne
 		return
 	}
 	for _, i := range list.Items {
-		gatherEndpoint(i, acc)
+		gatherEndpoint(i, ki.withLabels(acc, "endpoints", i.Labels))
 	}
 }
 
@@ -0,0 +1,166 @@
+package kube_inventory
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Resources of the Gateway API collected via the dynamic client as the API is
+// not part of the core Kubernetes API
+var (
+	gatewayResource = schema.GroupVersionResource{
+		Group:    "gateway.networking.k8s.io",
+		Version:  "v1",
+		Resource: "gateways",
+	}
+	httpRouteResource = schema.GroupVersionResource{
+		Group:    "gateway.networking.k8s.io",
+		Version:  "v1",
+		Resource: "httproutes",
+	}
+)
+
+func collectGateways(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesInventory) {
+	list, err := ki.client.getCustomResources(ctx, gatewayResource, true)
+	if err != nil {
+		// The Gateway API is optional so do not fail on clusters without it
+		if apierrors.IsNotFound(err) {
+			ki.Log.Debugf("Gateway API not available: %v", err)
+			return
+		}
+		if ki.isForbidden("gateways", err) {
+			return
+		}
+		acc.AddError(err)
+		return
+	}
+	for i := range list.Items {
+		gatherGateway(&list.Items[i], ki.withLabels(acc, "gateways", list.Items[i].GetLabels()))
+	}
+}
+
+func collectHTTPRoutes(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesInventory) {
+	list, err := ki.client.getCustomResources(ctx, httpRouteResource, true)
+	if err != nil {
+		// The Gateway API is optional so do not fail on clusters without it
+		if apierrors.IsNotFound(err) {
+			ki.Log.Debugf("Gateway API not available: %v", err)
+			return
+		}
+		if ki.isForbidden("httproutes", err) {
+			return
+		}
+		acc.AddError(err)
+		return
+	}
+	for i := range list.Items {
+		gatherHTTPRoute(&list.Items[i], ki.withLabels(acc, "httproutes", list.Items[i].GetLabels()))
+	}
+}
+
+func gatherGateway(g *unstructured.Unstructured, acc telegraf.Accumulator) {
+	creationTS := g.GetCreationTimestamp()
+	if creationTS.IsZero() {
+		return
+	}
+
+	listeners, _, _ := unstructured.NestedSlice(g.Object, "spec", "listeners")
+	addresses, _, _ := unstructured.NestedSlice(g.Object, "status", "addresses")
+	conditions, _, _ := unstructured.NestedSlice(g.Object, "status", "conditions")
+
+	// Sum up the routes attached to the individual listeners
+	var attachedRoutes int64
+	statusListeners, _, _ := unstructured.NestedSlice(g.Object, "status", "listeners")
+	for _, l := range statusListeners {
+		if listener, ok := l.(map[string]interface{}); ok {
+			n, _, _ := unstructured.NestedInt64(listener, "attachedRoutes")
+			attachedRoutes += n
+		}
+	}
+
+	fields := map[string]interface{}{
+		"created":         creationTS.UnixNano(),
+		"generation":      g.GetGeneration(),
+		"listeners":       int64(len(listeners)),
+		"addresses":       int64(len(addresses)),
+		"attached_routes": attachedRoutes,
+		"accepted":        conditionTrue(conditions, "Accepted"),
+		"programmed":      conditionTrue(conditions, "Programmed"),
+	}
+
+	tags := map[string]string{
+		"gateway_name": g.GetName(),
+		"namespace":    g.GetNamespace(),
+	}
+	if class, found, _ := unstructured.NestedString(g.Object, "spec", "gatewayClassName"); found {
+		tags["gateway_class"] = class
+	}
+
+	acc.AddFields(gatewayMeasurement, fields, tags)
+}
+
+func gatherHTTPRoute(r *unstructured.Unstructured, acc telegraf.Accumulator) {
+	creationTS := r.GetCreationTimestamp()
+	if creationTS.IsZero() {
+		return
+	}
+
+	hostnames, _, _ := unstructured.NestedSlice(r.Object, "spec", "hostnames")
+	rules, _, _ := unstructured.NestedSlice(r.Object, "spec", "rules")
+	parentRefs, _, _ := unstructured.NestedSlice(r.Object, "spec", "parentRefs")
+
+	// Count the parents, i.e. gateways, that accepted the route and
+	// resolved all of its references
+	var accepted, resolved int64
+	parents, _, _ := unstructured.NestedSlice(r.Object, "status", "parents")
+	for _, p := range parents {
+		parent, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditions, _, _ := unstructured.NestedSlice(parent, "conditions")
+		if conditionTrue(conditions, "Accepted") {
+			accepted++
+		}
+		if conditionTrue(conditions, "ResolvedRefs") {
+			resolved++
+		}
+	}
+
+	fields := map[string]interface{}{
+		"created":               creationTS.UnixNano(),
+		"generation":            r.GetGeneration(),
+		"hostnames":             int64(len(hostnames)),
+		"rules":                 int64(len(rules)),
+		"parents":               int64(len(parentRefs)),
+		"parents_accepted":      accepted,
+		"parents_resolved_refs": resolved,
+	}
+
+	tags := map[string]string{
+		"route_name": r.GetName(),
+		"namespace":  r.GetNamespace(),
+	}
+
+	acc.AddFields(httpRouteMeasurement, fields, tags)
+}
+
+// conditionTrue returns true if the condition of the given type has the
+// status "True"
+func conditionTrue(conditions []interface{}, conditionType string) bool {
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == conditionType {
+			return condition["status"] == "True"
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package kube_inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGateway(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	gateway := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata": map[string]interface{}{
+			"name":              "public",
+			"namespace":         "default",
+			"generation":        int64(2),
+			"creationTimestamp": created.Format(time.RFC3339),
+		},
+		"spec": map[string]interface{}{
+			"gatewayClassName": "istio",
+			"listeners": []interface{}{
+				map[string]interface{}{"name": "http", "port": int64(80), "protocol": "HTTP"},
+				map[string]interface{}{"name": "https", "port": int64(443), "protocol": "HTTPS"},
+			},
+		},
+		"status": map[string]interface{}{
+			"addresses": []interface{}{
+				map[string]interface{}{"type": "IPAddress", "value": "10.0.0.1"},
+			},
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Accepted", "status": "True"},
+				map[string]interface{}{"type": "Programmed", "status": "False"},
+			},
+			"listeners": []interface{}{
+				map[string]interface{}{"name": "http", "attachedRoutes": int64(1)},
+				map[string]interface{}{"name": "https", "attachedRoutes": int64(2)},
+			},
+		},
+	}}
+
+	// Objects without creation timestamp are skipped
+	empty := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "empty"},
+	}}
+
+	acc := new(testutil.Accumulator)
+	gatherGateway(gateway, acc)
+	gatherGateway(empty, acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"kubernetes_gateway",
+			map[string]string{
+				"gateway_name":  "public",
+				"gateway_class": "istio",
+				"namespace":     "default",
+			},
+			map[string]interface{}{
+				"created":         created.UnixNano(),
+				"generation":      int64(2),
+				"listeners":       int64(2),
+				"addresses":       int64(1),
+				"attached_routes": int64(3),
+				"accepted":        true,
+				"programmed":      false,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestHTTPRoute(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata": map[string]interface{}{
+			"name":              "shop",
+			"namespace":         "default",
+			"generation":        int64(1),
+			"creationTimestamp": created.Format(time.RFC3339),
+		},
+		"spec": map[string]interface{}{
+			"hostnames": []interface{}{"shop.example.com"},
+			"parentRefs": []interface{}{
+				map[string]interface{}{"name": "public"},
+				map[string]interface{}{"name": "internal"},
+			},
+			"rules": []interface{}{
+				map[string]interface{}{"backendRefs": []interface{}{map[string]interface{}{"name": "shop", "port": int64(8080)}}},
+				map[string]interface{}{"backendRefs": []interface{}{map[string]interface{}{"name": "missing", "port": int64(8080)}}},
+			},
+		},
+		"status": map[string]interface{}{
+			"parents": []interface{}{
+				map[string]interface{}{
+					"parentRef": map[string]interface{}{"name": "public"},
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Accepted", "status": "True"},
+						map[string]interface{}{"type": "ResolvedRefs", "status": "False"},
+					},
+				},
+				map[string]interface{}{
+					"parentRef": map[string]interface{}{"name": "internal"},
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Accepted", "status": "False"},
+					},
+				},
+			},
+		},
+	}}
+
+	acc := new(testutil.Accumulator)
+	gatherHTTPRoute(route, acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"kubernetes_httproute",
+			map[string]string{
+				"route_name": "shop",
+				"namespace":  "default",
+			},
+			map[string]interface{}{
+				"created":               created.UnixNano(),
+				"generation":            int64(1),
+				"hostnames":             int64(1),
+				"rules":                 int64(2),
+				"parents":               int64(2),
+				"parents_accepted":      int64(1),
+				"parents_resolved_refs": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatewayForbidden(t *testing.T) {
+	logger := &testutil.CaptureLogger{}
+	ki := &KubernetesInventory{
+		client: newForbiddenClient(t),
+		Log:    logger,
+	}
+
+	// Missing permissions are only reported once per resource
+	var acc testutil.Accumulator
+	for range 2 {
+		collectGateways(t.Context(), &acc, ki)
+		collectHTTPRoutes(t.Context(), &acc, ki)
+	}
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Len(t, logger.Warnings(), 2)
+}
@@ -15,7 +15,7 @@ func collectIngress(ctx context.Context, acc telegraf.Accumulator, ki *Kubernete
 		return
 	}
 	for _, i := range list.Items {
-		gatherIngress(i, acc)
+		gatherIngress(i, ki.withLabels(acc, "ingress", i.Labels))
 	}
 }
 
@@ -28,6 +28,7 @@ func gatherIngress(i netv1.Ingress, acc telegraf.Accumulator) {
 	fields := map[string]interface{}{
 		"created":    i.GetCreationTimestamp().UnixNano(),
 		"generation": i.Generation,
+		"rules":      int64(len(i.Spec.Rules)),
 	}
 
 	tags := map[string]string{
@@ -35,6 +36,14 @@ func gatherIngress(i netv1.Ingress, acc telegraf.Accumulator) {
 		"namespace":    i.Namespace,
 	}
 
+	// Fall back to the deprecated annotation used before the class field
+	// was introduced
+	if i.Spec.IngressClassName != nil {
+		tags["ingress_class"] = *i.Spec.IngressClassName
+	} else if class, found := i.Annotations["kubernetes.io/ingress.class"]; found {
+		tags["ingress_class"] = class
+	}
+
 	for _, ingress := range i.Status.LoadBalancer.Ingress {
 		tags["hostname"] = ingress.Hostname
 		tags["ip"] = ingress.IP
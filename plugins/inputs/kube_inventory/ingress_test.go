@@ -16,6 +16,7 @@ import (
 func TestIngress(t *testing.T) {
 	now := time.Now()
 	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 1, 36, 0, now.Location())
+	class := "nginx"
 
 	tests := []struct {
 		name     string
@@ -50,6 +51,7 @@ func TestIngress(t *testing.T) {
 									},
 								},
 								Spec: netv1.IngressSpec{
+									IngressClassName: &class,
 									Rules: []netv1.IngressRule{
 										{
 											Host: "ui.internal",
@@ -89,6 +91,7 @@ func TestIngress(t *testing.T) {
 					"kubernetes_ingress",
 					map[string]string{
 						"ingress_name":         "ui-lb",
+						"ingress_class":        "nginx",
 						"namespace":            "ns1",
 						"ip":                   "1.0.0.127",
 						"hostname":             "chron-1",
@@ -100,6 +103,7 @@ func TestIngress(t *testing.T) {
 						"tls":                  false,
 						"backend_service_port": int32(8080),
 						"generation":           int64(12),
+						"rules":                int64(1),
 						"created":              now.UnixNano(),
 					},
 					time.Unix(0, 0),
@@ -208,6 +212,7 @@ func TestIngress(t *testing.T) {
 					map[string]interface{}{
 						"tls":        false,
 						"generation": int64(12),
+						"rules":      int64(1),
 						"created":    now.UnixNano(),
 					},
 					time.Unix(0, 0),
@@ -5,12 +5,14 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"sync"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/influxdata/telegraf"
@@ -27,7 +29,10 @@ var availableCollectors = map[string]func(ctx context.Context, acc telegraf.Accu
 	"daemonsets":             collectDaemonSets,
 	"deployments":            collectDeployments,
 	"endpoints":              collectEndpoints,
+	"gateways":               collectGateways,
+	"httproutes":             collectHTTPRoutes,
 	"ingress":                collectIngress,
+	"namespaces":             collectNamespaces,
 	"nodes":                  collectNodes,
 	"pods":                   collectPods,
 	"services":               collectServices,
@@ -42,8 +47,12 @@ const (
 	daemonSetMeasurement             = "kubernetes_daemonset"
 	deploymentMeasurement            = "kubernetes_deployment"
 	endpointMeasurement              = "kubernetes_endpoint"
+	gatewayMeasurement               = "kubernetes_gateway"
+	httpRouteMeasurement             = "kubernetes_httproute"
 	ingressMeasurement               = "kubernetes_ingress"
 	nodeMeasurement                  = "kubernetes_node"
+	nodeTaintMeasurement             = "kubernetes_node_taint"
+	nodeResourceMeasurement          = "kubernetes_node_resource"
 	persistentVolumeMeasurement      = "kubernetes_persistentvolume"
 	persistentVolumeClaimMeasurement = "kubernetes_persistentvolumeclaim"
 	podContainerMeasurement          = "kubernetes_pod_container"
@@ -59,23 +68,44 @@ type KubernetesInventory struct {
 	URL             string          `toml:"url"`
 	KubeletURL      string          `toml:"url_kubelet"`
 	BearerToken     string          `toml:"bearer_token"`
+	Kubeconfig      string          `toml:"kubeconfig"`
+	Context         string          `toml:"context"`
 	Namespace       string          `toml:"namespace"`
 	ResponseTimeout config.Duration `toml:"response_timeout"` // Timeout specified as a string - 3s, 1m, 1h
 	ResourceExclude []string        `toml:"resource_exclude"`
 	ResourceInclude []string        `toml:"resource_include"`
 	MaxConfigMapAge config.Duration `toml:"max_config_map_age"`
+	ChunkSize       int64           `toml:"chunk_size"`
 
 	SelectorInclude []string `toml:"selector_include"`
 	SelectorExclude []string `toml:"selector_exclude"`
 
-	NodeName string          `toml:"node_name"`
-	Log      telegraf.Logger `toml:"-"`
+	Rollups     []string `toml:"rollups"`
+	NodeDetails []string `toml:"node_details"`
+
+	Resources       map[string]*resourceSelection `toml:"resource"`
+	CustomResources []*customResource             `toml:"custom_resource"`
+
+	NodeName string `toml:"node_name"`
+
+	LeaderElection *leaderElection `toml:"leader_election"`
+
+	Log telegraf.Logger `toml:"-"`
 
 	tls.ClientConfig
 	client     *client
 	httpClient *http.Client
 
-	selectorFilter filter.Filter
+	selectorFilter  filter.Filter
+	rollupCluster   bool
+	rollupNamespace bool
+	nodeTaints      bool
+	nodeResources   bool
+	nodeTopology    bool
+
+	// Resources skipped due to missing permissions
+	forbidden     map[string]bool
+	forbiddenLock sync.Mutex
 }
 
 func (*KubernetesInventory) SampleConfig() string {
@@ -88,9 +118,51 @@ func (ki *KubernetesInventory) Init() error {
 		ki.BearerToken = defaultServiceAccountPath
 	}
 
-	var err error
-	ki.client, err = newClient(ki.URL, ki.Namespace, ki.BearerToken, time.Duration(ki.ResponseTimeout), ki.ClientConfig)
+	for name, selection := range ki.Resources {
+		if _, found := availableCollectors[name]; !found {
+			return fmt.Errorf("invalid resource %q in field selection", name)
+		}
+		if err := selection.init(); err != nil {
+			return fmt.Errorf("field selection of resource %q: %w", name, err)
+		}
+	}
+
+	for i, cr := range ki.CustomResources {
+		if err := cr.init(); err != nil {
+			return fmt.Errorf("custom resource %d: %w", i+1, err)
+		}
+	}
+
+	if ki.ChunkSize < 0 {
+		return errors.New("chunk size must not be negative")
+	}
+
+	for _, rollup := range ki.Rollups {
+		switch rollup {
+		case "cluster":
+			ki.rollupCluster = true
+		case "namespace":
+			ki.rollupNamespace = true
+		default:
+			return fmt.Errorf("invalid rollup %q", rollup)
+		}
+	}
+
+	if err := ki.initNodeDetails(); err != nil {
+		return err
+	}
+
+	if ki.LeaderElection != nil {
+		if err := ki.LeaderElection.init(ki.NodeName, ki.Log); err != nil {
+			return fmt.Errorf("leader election: %w", err)
+		}
+	}
 
+	restConfig, err := newRestConfig(ki.URL, ki.Kubeconfig, ki.Context, ki.BearerToken, ki.ClientConfig)
+	if err != nil {
+		return err
+	}
+	ki.client, err = newClient(restConfig, ki.Namespace, time.Duration(ki.ResponseTimeout), ki.ChunkSize)
 	if err != nil {
 		return err
 	}
@@ -108,7 +180,20 @@ func (ki *KubernetesInventory) Init() error {
 	return nil
 }
 
+func (ki *KubernetesInventory) Start(telegraf.Accumulator) error {
+	if ki.LeaderElection == nil {
+		return nil
+	}
+	return ki.LeaderElection.start(ki.client.Clientset)
+}
+
 func (ki *KubernetesInventory) Gather(acc telegraf.Accumulator) (err error) {
+	// Only the elected leader collects the resources
+	if ki.LeaderElection != nil && !ki.LeaderElection.isLeader() {
+		ki.Log.Debug("Not the leader, skipping collection")
+		return nil
+	}
+
 	resourceFilter, err := filter.NewIncludeExcludeFilter(ki.ResourceInclude, ki.ResourceExclude)
 	if err != nil {
 		return err
@@ -124,19 +209,58 @@ func (ki *KubernetesInventory) Gather(acc telegraf.Accumulator) (err error) {
 
 	for collector, f := range availableCollectors {
 		if resourceFilter.Match(collector) {
+			a := acc
+			if selection, found := ki.Resources[collector]; found && selection.fieldFilter != nil {
+				a = &selectionAccumulator{Accumulator: acc, filter: selection.fieldFilter}
+			}
+
 			wg.Add(1)
 			go func(f func(ctx context.Context, acc telegraf.Accumulator, k *KubernetesInventory)) {
 				defer wg.Done()
-				f(ctx, acc, ki)
+				f(ctx, a, ki)
 			}(f)
 		}
 	}
 
+	for _, cr := range ki.CustomResources {
+		wg.Add(1)
+		go func(cr *customResource) {
+			defer wg.Done()
+			collectCustomResource(ctx, acc, ki, cr)
+		}(cr)
+	}
+
 	wg.Wait()
 
 	return nil
 }
 
+// isForbidden checks if the error is caused by missing permissions to list
+// the given resource. As permissions for resources enabled by default are
+// often not granted on purpose, a warning is only logged once per resource.
+func (ki *KubernetesInventory) isForbidden(resource string, err error) bool {
+	if !apierrors.IsForbidden(err) {
+		return false
+	}
+
+	ki.forbiddenLock.Lock()
+	defer ki.forbiddenLock.Unlock()
+	if !ki.forbidden[resource] {
+		ki.Log.Warnf("Skipping %q due to missing permissions: %v", resource, err)
+		if ki.forbidden == nil {
+			ki.forbidden = make(map[string]bool)
+		}
+		ki.forbidden[resource] = true
+	}
+	return true
+}
+
+func (ki *KubernetesInventory) Stop() {
+	if ki.LeaderElection != nil {
+		ki.LeaderElection.stop()
+	}
+}
+
 func atoi(s string) int64 {
 	i, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
@@ -198,6 +322,7 @@ func init() {
 	inputs.Add("kube_inventory", func() telegraf.Input {
 		return &KubernetesInventory{
 			ResponseTimeout: config.Duration(time.Second * 5),
+			ChunkSize:       500,
 			Namespace:       "default",
 			SelectorInclude: make([]string, 0),
 			SelectorExclude: []string{"*"},
@@ -0,0 +1,165 @@
+package kube_inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+const defaultServiceAccountNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// leaderElection elects a single instance of the plugin to collect the
+// resources using a Kubernetes Lease, e.g. when running as a DaemonSet
+type leaderElection struct {
+	LeaseName      string          `toml:"lease_name"`
+	LeaseNamespace string          `toml:"lease_namespace"`
+	Identity       string          `toml:"identity"`
+	LeaseDuration  config.Duration `toml:"lease_duration"`
+	RenewDeadline  config.Duration `toml:"renew_deadline"`
+	RetryPeriod    config.Duration `toml:"retry_period"`
+
+	log     telegraf.Logger
+	leading atomic.Bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func (le *leaderElection) init(nodeName string, log telegraf.Logger) error {
+	le.log = log
+
+	if le.LeaseName == "" {
+		le.LeaseName = "telegraf-kube-inventory"
+	}
+
+	// Use the namespace of the POD if running inside the cluster
+	if le.LeaseNamespace == "" {
+		le.LeaseNamespace = "default"
+		if buf, err := os.ReadFile(defaultServiceAccountNamespacePath); err == nil {
+			if ns := strings.TrimSpace(string(buf)); ns != "" {
+				le.LeaseNamespace = ns
+			}
+		}
+	}
+
+	// The identity must be unique for each instance, the hostname is the
+	// POD name when running inside the cluster
+	if le.Identity == "" {
+		le.Identity = nodeName
+	}
+	if le.Identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("determining identity failed: %w", err)
+		}
+		le.Identity = hostname
+	}
+
+	if le.LeaseDuration == 0 {
+		le.LeaseDuration = config.Duration(15 * time.Second)
+	}
+	if le.RenewDeadline == 0 {
+		le.RenewDeadline = config.Duration(10 * time.Second)
+	}
+	if le.RetryPeriod == 0 {
+		le.RetryPeriod = config.Duration(2 * time.Second)
+	}
+	if le.RetryPeriod < 0 {
+		return errors.New("retry period must be positive")
+	}
+	if le.RenewDeadline <= le.RetryPeriod {
+		return errors.New("renew deadline must be greater than the retry period")
+	}
+	if le.LeaseDuration <= le.RenewDeadline {
+		return errors.New("lease duration must be greater than the renew deadline")
+	}
+
+	return nil
+}
+
+// start participates in the election until stopped
+func (le *leaderElection) start(clientset kubernetes.Interface) error {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      le.LeaseName,
+			Namespace: le.LeaseNamespace,
+		},
+		Client:     clientset.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: le.Identity},
+	}
+
+	cfg := leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   time.Duration(le.LeaseDuration),
+		RenewDeadline:   time.Duration(le.RenewDeadline),
+		RetryPeriod:     time.Duration(le.RetryPeriod),
+		ReleaseOnCancel: true,
+		Name:            le.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				le.log.Infof("Acquired lease %s/%s, collecting resources", le.LeaseNamespace, le.LeaseName)
+				le.leading.Store(true)
+			},
+			OnStoppedLeading: func() {
+				if le.leading.Swap(false) {
+					le.log.Infof("Lost lease %s/%s, stopped collecting resources", le.LeaseNamespace, le.LeaseName)
+				}
+			},
+			OnNewLeader: func(identity string) {
+				if identity != le.Identity {
+					le.log.Debugf("Instance %q is the leader of lease %s/%s", identity, le.LeaseNamespace, le.LeaseName)
+				}
+			},
+		},
+	}
+
+	// Validate the config upfront to report errors on startup
+	if _, err := leaderelection.NewLeaderElector(cfg); err != nil {
+		return fmt.Errorf("creating leader elector failed: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	le.cancel = cancel
+
+	le.wg.Add(1)
+	go func() {
+		defer le.wg.Done()
+
+		// Running the election returns when losing the lease, so keep
+		// participating to be able to become the leader again later
+		for ctx.Err() == nil {
+			elector, err := leaderelection.NewLeaderElector(cfg)
+			if err != nil {
+				le.log.Errorf("Creating leader elector failed: %v", err)
+				return
+			}
+			elector.Run(ctx)
+		}
+	}()
+
+	return nil
+}
+
+// stop leaves the election releasing the lease if held
+func (le *leaderElection) stop() {
+	if le.cancel != nil {
+		le.cancel()
+	}
+	le.wg.Wait()
+}
+
+func (le *leaderElection) isLeader() bool {
+	return le.leading.Load()
+}
@@ -0,0 +1,93 @@
+package kube_inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestLeaderElectionInit(t *testing.T) {
+	le := &leaderElection{}
+	require.NoError(t, le.init("node-1", testutil.Logger{}))
+	require.Equal(t, "telegraf-kube-inventory", le.LeaseName)
+	require.NotEmpty(t, le.LeaseNamespace)
+	require.Equal(t, "node-1", le.Identity)
+	require.Equal(t, config.Duration(15*time.Second), le.LeaseDuration)
+	require.Equal(t, config.Duration(10*time.Second), le.RenewDeadline)
+	require.Equal(t, config.Duration(2*time.Second), le.RetryPeriod)
+
+	le = &leaderElection{
+		LeaseDuration: config.Duration(5 * time.Second),
+		RenewDeadline: config.Duration(10 * time.Second),
+	}
+	require.ErrorContains(t, le.init("node-1", testutil.Logger{}), "lease duration must be greater than the renew deadline")
+
+	le = &leaderElection{
+		RenewDeadline: config.Duration(time.Second),
+		RetryPeriod:   config.Duration(2 * time.Second),
+	}
+	require.ErrorContains(t, le.init("node-1", testutil.Logger{}), "renew deadline must be greater than the retry period")
+}
+
+func TestLeaderElection(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	electors := make([]*leaderElection, 0, 3)
+	for _, identity := range []string{"node-1", "node-2", "node-3"} {
+		le := &leaderElection{
+			LeaseNamespace: "telegraf",
+			Identity:       identity,
+			LeaseDuration:  config.Duration(time.Second),
+			RenewDeadline:  config.Duration(500 * time.Millisecond),
+			RetryPeriod:    config.Duration(100 * time.Millisecond),
+		}
+		require.NoError(t, le.init("", testutil.Logger{}))
+		require.NoError(t, le.start(clientset))
+		electors = append(electors, le)
+	}
+	defer func() {
+		for _, le := range electors {
+			le.stop()
+		}
+	}()
+
+	leaders := func() []*leaderElection {
+		var result []*leaderElection
+		for _, le := range electors {
+			if le.isLeader() {
+				result = append(result, le)
+			}
+		}
+		return result
+	}
+
+	// Exactly one instance is elected
+	require.Eventually(t, func() bool {
+		return len(leaders()) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	leader := leaders()[0]
+
+	// Another instance takes over after the leader stopped
+	leader.stop()
+	require.False(t, leader.isLeader())
+	require.Eventually(t, func() bool {
+		l := leaders()
+		return len(l) == 1 && l[0] != leader
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestGatherNotLeader(t *testing.T) {
+	ki := &KubernetesInventory{
+		LeaderElection: &leaderElection{},
+		Log:            testutil.Logger{},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, ki.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
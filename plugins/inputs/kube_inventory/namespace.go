@@ -0,0 +1,99 @@
+package kube_inventory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/influxdata/telegraf"
+)
+
+var quotaResourceReplacer = strings.NewReplacer(".", "_", "/", "_")
+
+func collectNamespaces(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesInventory) {
+	list, err := ki.client.getNamespaces(ctx)
+	if err != nil {
+		// Namespaces are cluster scoped and thus require a cluster role
+		if !ki.isForbidden("namespaces", err) {
+			acc.AddError(err)
+		}
+		return
+	}
+
+	// Missing quotas only affect the utilization fields, so still report the
+	// namespaces themselves
+	quotas := make(map[string][]corev1.ResourceQuota)
+	if rqs, err := ki.client.getResourceQuotas(ctx); err != nil {
+		acc.AddError(err)
+	} else {
+		for _, rq := range rqs.Items {
+			quotas[rq.Namespace] = append(quotas[rq.Namespace], rq)
+		}
+	}
+
+	now := time.Now()
+	for i := range list.Items {
+		ns := &list.Items[i]
+		gatherNamespace(ns, quotas[ns.Name], now, ki.withLabels(acc, "namespaces", ns.Labels))
+	}
+}
+
+func gatherNamespace(ns *corev1.Namespace, quotas []corev1.ResourceQuota, now time.Time, acc telegraf.Accumulator) {
+	phaseType := 2
+	switch ns.Status.Phase {
+	case corev1.NamespaceActive:
+		phaseType = 0
+	case corev1.NamespaceTerminating:
+		phaseType = 1
+	}
+
+	fields := map[string]interface{}{
+		"phase_type": phaseType,
+		"quotas":     len(quotas),
+	}
+	tags := map[string]string{
+		"namespace": ns.Name,
+		"phase":     string(ns.Status.Phase),
+	}
+
+	creationTS := ns.GetCreationTimestamp()
+	if !creationTS.IsZero() {
+		fields["created"] = creationTS.UnixNano()
+		fields["age"] = int64(now.Sub(creationTS.Time).Seconds())
+	}
+
+	for name, percent := range quotaUtilization(quotas) {
+		fields["quota_"+name+"_used_percent"] = percent
+	}
+
+	acc.AddFields(namespaceMeasurement, fields, tags)
+}
+
+// quotaUtilization returns the used percentage of the hard limit for each
+// resource constrained by the given quotas. If multiple quotas constrain the
+// same resource, the most utilized one is reported as it is the one limiting
+// the namespace first.
+func quotaUtilization(quotas []corev1.ResourceQuota) map[string]float64 {
+	utilization := make(map[string]float64)
+	for _, rq := range quotas {
+		for resourceName, hard := range rq.Status.Hard {
+			limit := hard.AsApproximateFloat64()
+			if limit <= 0 {
+				continue
+			}
+			var used float64
+			if val, found := rq.Status.Used[resourceName]; found {
+				used = val.AsApproximateFloat64()
+			}
+
+			name := quotaResourceReplacer.Replace(string(resourceName))
+			percent := used / limit * 100
+			if current, found := utilization[name]; !found || percent > current {
+				utilization[name] = percent
+			}
+		}
+	}
+	return utilization
+}
@@ -0,0 +1,194 @@
+package kube_inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestNamespace(t *testing.T) {
+	now := time.Now()
+	created := now.Add(-2 * time.Hour)
+
+	tests := []struct {
+		name      string
+		namespace *corev1.Namespace
+		quotas    []corev1.ResourceQuota
+		expected  telegraf.Metric
+	}{
+		{
+			name: "no quotas",
+			namespace: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "ns1",
+					CreationTimestamp: metav1.Time{Time: created},
+				},
+				Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+			},
+			expected: metric.New(
+				namespaceMeasurement,
+				map[string]string{
+					"namespace": "ns1",
+					"phase":     "Active",
+				},
+				map[string]interface{}{
+					"phase_type": 0,
+					"quotas":     0,
+					"created":    created.UnixNano(),
+					"age":        int64(7200),
+				},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "terminating without timestamp",
+			namespace: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "ns2"},
+				Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+			},
+			expected: metric.New(
+				namespaceMeasurement,
+				map[string]string{
+					"namespace": "ns2",
+					"phase":     "Terminating",
+				},
+				map[string]interface{}{
+					"phase_type": 1,
+					"quotas":     0,
+				},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "quotas",
+			namespace: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "ns3",
+					CreationTimestamp: metav1.Time{Time: created},
+				},
+				Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+			},
+			quotas: []corev1.ResourceQuota{
+				{
+					Status: corev1.ResourceQuotaStatus{
+						Hard: corev1.ResourceList{
+							"requests.cpu":    resource.MustParse("4"),
+							"requests.memory": resource.MustParse("8Gi"),
+							"pods":            resource.MustParse("10"),
+						},
+						Used: corev1.ResourceList{
+							"requests.cpu":    resource.MustParse("500m"),
+							"requests.memory": resource.MustParse("2Gi"),
+							"pods":            resource.MustParse("2"),
+						},
+					},
+				},
+				{
+					Status: corev1.ResourceQuotaStatus{
+						Hard: corev1.ResourceList{
+							"pods":                   resource.MustParse("4"),
+							"count/deployments.apps": resource.MustParse("5"),
+							"services.loadbalancers": resource.MustParse("0"),
+						},
+						Used: corev1.ResourceList{
+							"pods":                   resource.MustParse("3"),
+							"services.loadbalancers": resource.MustParse("0"),
+						},
+					},
+				},
+			},
+			expected: metric.New(
+				namespaceMeasurement,
+				map[string]string{
+					"namespace": "ns3",
+					"phase":     "Active",
+				},
+				map[string]interface{}{
+					"phase_type":                         0,
+					"quotas":                             2,
+					"created":                            created.UnixNano(),
+					"age":                                int64(7200),
+					"quota_requests_cpu_used_percent":    12.5,
+					"quota_requests_memory_used_percent": 25.0,
+					"quota_pods_used_percent":            75.0,
+					"quota_count_deployments_apps_used_percent": 0.0,
+				},
+				time.Unix(0, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var acc testutil.Accumulator
+			gatherNamespace(tt.namespace, tt.quotas, now, &acc)
+			require.NoError(t, acc.FirstError())
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestNamespaceLabels(t *testing.T) {
+	ki := &KubernetesInventory{
+		Resources: map[string]*resourceSelection{
+			"namespaces": {LabelsAsTags: []string{"tenant"}},
+		},
+	}
+	require.NoError(t, ki.Resources["namespaces"].init())
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "team-a",
+			Labels: map[string]string{
+				"tenant":                      "a",
+				"kubernetes.io/metadata.name": "team-a",
+			},
+		},
+		Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+	}
+
+	var acc testutil.Accumulator
+	gatherNamespace(ns, nil, time.Now(), ki.withLabels(&acc, "namespaces", ns.Labels))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			namespaceMeasurement,
+			map[string]string{
+				"namespace":    "team-a",
+				"phase":        "Active",
+				"label_tenant": "a",
+			},
+			map[string]interface{}{
+				"phase_type": 0,
+				"quotas":     0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestNamespaceForbidden(t *testing.T) {
+	logger := &testutil.CaptureLogger{}
+	ki := &KubernetesInventory{
+		client: newForbiddenClient(t),
+		Log:    logger,
+	}
+
+	// Missing permissions are only reported once
+	var acc testutil.Accumulator
+	for range 2 {
+		collectNamespaces(t.Context(), &acc, ki)
+	}
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Len(t, logger.Warnings(), 1)
+}
@@ -2,12 +2,30 @@ package kube_inventory
 
 import (
 	"context"
+	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/influxdata/telegraf"
 )
 
+// initNodeDetails enables the configured additional node details
+func (ki *KubernetesInventory) initNodeDetails() error {
+	for _, detail := range ki.NodeDetails {
+		switch detail {
+		case "taints":
+			ki.nodeTaints = true
+		case "resources":
+			ki.nodeResources = true
+		case "topology":
+			ki.nodeTopology = true
+		default:
+			return fmt.Errorf("invalid node detail %q", detail)
+		}
+	}
+	return nil
+}
+
 func collectNodes(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesInventory) {
 	list, err := ki.client.getNodes(ctx, ki.NodeName)
 	if err != nil {
@@ -18,8 +36,9 @@ func collectNodes(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesI
 	gatherNodeCount(len(list.Items), acc)
 
 	for i := range list.Items {
-		ki.gatherNode(&list.Items[i], acc)
+		ki.gatherNode(&list.Items[i], ki.withLabels(acc, "nodes", list.Items[i].Labels))
 	}
+	ki.rollupNodes(list.Items, acc)
 }
 
 func gatherNodeCount(count int, acc telegraf.Accumulator) {
@@ -36,6 +55,11 @@ func (ki *KubernetesInventory) gatherNode(n *corev1.Node, acc telegraf.Accumulat
 		"cluster_namespace": n.Annotations["cluster.x-k8s.io/cluster-namespace"],
 		"version":           n.Status.NodeInfo.KubeletVersion,
 	}
+	if ki.nodeTopology {
+		for k, v := range nodeTopology(n.Labels) {
+			tags[k] = v
+		}
+	}
 
 	for resourceName, val := range n.Status.Capacity {
 		switch resourceName {
@@ -95,5 +119,81 @@ func (ki *KubernetesInventory) gatherNode(n *corev1.Node, acc telegraf.Accumulat
 	}
 	fields["spec_unschedulable"] = unschedulable
 
+	if ki.nodeTaints {
+		fields["spec_taints"] = len(n.Spec.Taints)
+		ki.gatherNodeTaints(n, tags, acc)
+	}
+	if ki.nodeResources {
+		ki.gatherNodeResources(n, tags, acc)
+	}
+
 	acc.AddFields(nodeMeasurement, fields, tags)
 }
+
+// Labels of the topology tags with the deprecated labels used as fallback
+var nodeTopologyLabels = map[string][]string{
+	"zone":          {"topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone"},
+	"region":        {"topology.kubernetes.io/region", "failure-domain.beta.kubernetes.io/region"},
+	"instance_type": {"node.kubernetes.io/instance-type", "beta.kubernetes.io/instance-type"},
+}
+
+// nodeTopology returns the topology tags of a node found in its labels
+func nodeTopology(labels map[string]string) map[string]string {
+	tags := make(map[string]string, len(nodeTopologyLabels))
+	for tag, keys := range nodeTopologyLabels {
+		for _, key := range keys {
+			if v, found := labels[key]; found && v != "" {
+				tags[tag] = v
+				break
+			}
+		}
+	}
+	return tags
+}
+
+// gatherNodeTaints adds a metric for each taint of the node
+func (*KubernetesInventory) gatherNodeTaints(n *corev1.Node, nodeTags map[string]string, acc telegraf.Accumulator) {
+	for _, taint := range n.Spec.Taints {
+		tags := map[string]string{
+			"key":    taint.Key,
+			"effect": string(taint.Effect),
+		}
+		if taint.Value != "" {
+			tags["value"] = taint.Value
+		}
+		for k, v := range nodeTags {
+			tags[k] = v
+		}
+		fields := map[string]interface{}{"spec_taint": 1}
+		if taint.TimeAdded != nil {
+			fields["time_added"] = taint.TimeAdded.UnixNano()
+		}
+		acc.AddFields(nodeTaintMeasurement, fields, tags)
+	}
+}
+
+// gatherNodeResources adds a metric with the capacity and allocatable amount
+// for each resource of the node other than CPU, memory and pods, e.g. GPUs,
+// hugepages or ephemeral storage
+func (ki *KubernetesInventory) gatherNodeResources(n *corev1.Node, nodeTags map[string]string, acc telegraf.Accumulator) {
+	for resourceName, val := range n.Status.Capacity {
+		switch resourceName {
+		case corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourcePods:
+			continue
+		}
+
+		capacity := ki.convertQuantity(val.String(), 1)
+		fields := map[string]interface{}{"capacity": capacity}
+		if v, found := n.Status.Allocatable[resourceName]; found {
+			allocatable := ki.convertQuantity(v.String(), 1)
+			fields["allocatable"] = allocatable
+			fields["reserved"] = capacity - allocatable
+		}
+
+		tags := map[string]string{"resource": string(resourceName)}
+		for k, v := range nodeTags {
+			tags[k] = v
+		}
+		acc.AddFields(nodeResourceMeasurement, fields, tags)
+	}
+}
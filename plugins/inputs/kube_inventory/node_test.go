@@ -180,3 +180,115 @@ func TestNode(t *testing.T) {
 		testutil.RequireMetricsEqual(t, acc.GetTelegrafMetrics(), v.output, testutil.IgnoreTime())
 	}
 }
+
+func TestNodeDetails(t *testing.T) {
+	added := metav1.Unix(1700000000, 0)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+			Labels: map[string]string{
+				"topology.kubernetes.io/zone":            "us-east-1c",
+				"failure-domain.beta.kubernetes.io/zone": "us-east-1a",
+				"topology.kubernetes.io/region":          "us-east-1",
+				"beta.kubernetes.io/instance-type":       "p3.8xlarge",
+			},
+		},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{
+				{Key: "nvidia.com/gpu", Value: "present", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "node.kubernetes.io/unreachable", Effect: corev1.TaintEffectNoExecute, TimeAdded: &added},
+			},
+		},
+		Status: corev1.NodeStatus{
+			NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.30.1"},
+			Capacity: corev1.ResourceList{
+				"cpu":            resource.MustParse("32"),
+				"memory":         resource.MustParse("244Gi"),
+				"pods":           resource.MustParse("110"),
+				"nvidia.com/gpu": resource.MustParse("4"),
+				"hugepages-2Mi":  resource.MustParse("1Gi"),
+			},
+			Allocatable: corev1.ResourceList{
+				"cpu":            resource.MustParse("31"),
+				"memory":         resource.MustParse("240Gi"),
+				"pods":           resource.MustParse("110"),
+				"nvidia.com/gpu": resource.MustParse("4"),
+				"hugepages-2Mi":  resource.MustParse("512Mi"),
+			},
+		},
+	}
+
+	plugin := &KubernetesInventory{
+		NodeDetails: []string{"taints", "resources", "topology"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.initNodeDetails())
+
+	var acc testutil.Accumulator
+	plugin.gatherNode(node, &acc)
+
+	nodeTags := map[string]string{
+		"node_name":         "node1",
+		"cluster_namespace": "",
+		"version":           "v1.30.1",
+		"zone":              "us-east-1c",
+		"region":            "us-east-1",
+		"instance_type":     "p3.8xlarge",
+	}
+	withTags := func(tags map[string]string) map[string]string {
+		for k, v := range nodeTags {
+			tags[k] = v
+		}
+		return tags
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			nodeTaintMeasurement,
+			withTags(map[string]string{"key": "nvidia.com/gpu", "value": "present", "effect": "NoSchedule"}),
+			map[string]interface{}{"spec_taint": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			nodeTaintMeasurement,
+			withTags(map[string]string{"key": "node.kubernetes.io/unreachable", "effect": "NoExecute"}),
+			map[string]interface{}{"spec_taint": int64(1), "time_added": added.UnixNano()},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			nodeResourceMeasurement,
+			withTags(map[string]string{"resource": "hugepages-2Mi"}),
+			map[string]interface{}{"capacity": int64(1073741824), "allocatable": int64(536870912), "reserved": int64(536870912)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			nodeResourceMeasurement,
+			withTags(map[string]string{"resource": "nvidia.com/gpu"}),
+			map[string]interface{}{"capacity": int64(4), "allocatable": int64(4), "reserved": int64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			nodeMeasurement,
+			nodeTags,
+			map[string]interface{}{
+				"capacity_cpu_cores":         int64(32),
+				"capacity_millicpu_cores":    int64(32000),
+				"capacity_memory_bytes":      int64(261993005056),
+				"capacity_pods":              int64(110),
+				"allocatable_cpu_cores":      int64(31),
+				"allocatable_millicpu_cores": int64(31000),
+				"allocatable_memory_bytes":   int64(257698037760),
+				"allocatable_pods":           int64(110),
+				"spec_unschedulable":         int64(0),
+				"spec_taints":                int64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestNodeDetailsInvalid(t *testing.T) {
+	plugin := &KubernetesInventory{NodeDetails: []string{"taints", "foo"}}
+	require.ErrorContains(t, plugin.initNodeDetails(), `invalid node detail "foo"`)
+}
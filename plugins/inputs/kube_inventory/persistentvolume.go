@@ -16,7 +16,7 @@ func collectPersistentVolumes(ctx context.Context, acc telegraf.Accumulator, ki
 		return
 	}
 	for i := range list.Items {
-		gatherPersistentVolume(&list.Items[i], acc)
+		gatherPersistentVolume(&list.Items[i], ki.withLabels(acc, "persistentvolumes", list.Items[i].Labels))
 	}
 }
 
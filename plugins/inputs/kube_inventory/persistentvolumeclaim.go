@@ -16,7 +16,7 @@ func collectPersistentVolumeClaims(ctx context.Context, acc telegraf.Accumulator
 		return
 	}
 	for _, pvc := range list.Items {
-		ki.gatherPersistentVolumeClaim(pvc, acc)
+		ki.gatherPersistentVolumeClaim(pvc, ki.withLabels(acc, "persistentvolumeclaims", pvc.Labels))
 	}
 }
 
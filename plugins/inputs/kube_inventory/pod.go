@@ -25,8 +25,9 @@ func collectPods(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesIn
 		return
 	}
 	for i := range listRef.Items {
-		ki.gatherPod(&listRef.Items[i], acc)
+		ki.gatherPod(&listRef.Items[i], ki.withLabels(acc, "pods", listRef.Items[i].Labels))
 	}
+	ki.rollupPods(listRef.Items, acc)
 }
 
 func (ki *KubernetesInventory) gatherPod(p *corev1.Pod, acc telegraf.Accumulator) {
@@ -87,6 +88,12 @@ func (ki *KubernetesInventory) gatherPodContainer(p *corev1.Pod, cs corev1.Conta
 		fields["state_reason"] = stateReason
 	}
 
+	if last := cs.LastTerminationState.Terminated; last != nil {
+		fields["last_terminated_reason"] = last.Reason
+		fields["last_terminated_exit_code"] = last.ExitCode
+		fields["last_terminated_oom_killed"] = last.Reason == "OOMKilled"
+	}
+
 	phaseReason := p.Status.Reason
 	if phaseReason != "" {
 		fields["phase_reason"] = phaseReason
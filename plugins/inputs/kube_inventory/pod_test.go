@@ -152,6 +152,13 @@ func TestPod(t *testing.T) {
 													StartedAt: metav1.Time{Time: started},
 												},
 											},
+											LastTerminationState: corev1.ContainerState{
+												Terminated: &corev1.ContainerStateTerminated{
+													StartedAt: metav1.Time{Time: created},
+													ExitCode:  137,
+													Reason:    "OOMKilled",
+												},
+											},
 											Ready:        true,
 											RestartCount: 3,
 											Image:        "image1",
@@ -280,6 +287,9 @@ func TestPod(t *testing.T) {
 					map[string]interface{}{
 						"restarts_total":                   int32(3),
 						"state_code":                       0,
+						"last_terminated_reason":           "OOMKilled",
+						"last_terminated_exit_code":        int32(137),
+						"last_terminated_oom_killed":       true,
 						"resource_requests_millicpu_units": int64(100),
 						"resource_limits_millicpu_units":   int64(100),
 					},
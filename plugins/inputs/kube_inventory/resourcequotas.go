@@ -16,7 +16,7 @@ func collectResourceQuotas(ctx context.Context, acc telegraf.Accumulator, ki *Ku
 		return
 	}
 	for _, i := range list.Items {
-		ki.gatherResourceQuota(i, acc)
+		ki.gatherResourceQuota(i, ki.withLabels(acc, "resourcequotas", i.Labels))
 	}
 }
 
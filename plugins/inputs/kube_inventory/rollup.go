@@ -0,0 +1,135 @@
+package kube_inventory
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+const (
+	clusterMeasurement   = "kubernetes_cluster"
+	namespaceMeasurement = "kubernetes_namespace"
+)
+
+var podPhases = []corev1.PodPhase{
+	corev1.PodPending,
+	corev1.PodRunning,
+	corev1.PodSucceeded,
+	corev1.PodFailed,
+	corev1.PodUnknown,
+}
+
+// newPodRollup returns the pod rollup counters with the known counters
+// initialized to be able to report zero values
+func newPodRollup() map[string]int64 {
+	counters := map[string]int64{
+		"pods_total":                       0,
+		"resource_requests_millicpu_units": 0,
+		"resource_requests_memory_bytes":   0,
+		"resource_limits_millicpu_units":   0,
+		"resource_limits_memory_bytes":     0,
+	}
+	for _, phase := range podPhases {
+		counters["pods_"+strings.ToLower(string(phase))] = 0
+	}
+	return counters
+}
+
+// rollupFields converts the rollup counters to metric fields
+func rollupFields(counters map[string]int64) map[string]interface{} {
+	fields := make(map[string]interface{}, len(counters))
+	for k, v := range counters {
+		fields[k] = v
+	}
+	return fields
+}
+
+// rollupPods emits the aggregated pod statistics for the cluster and/or each
+// namespace depending on the configured rollups
+func (ki *KubernetesInventory) rollupPods(pods []corev1.Pod, acc telegraf.Accumulator) {
+	if !ki.rollupCluster && !ki.rollupNamespace {
+		return
+	}
+
+	cluster := newPodRollup()
+	namespaces := make(map[string]map[string]int64)
+	for i := range pods {
+		p := &pods[i]
+
+		ns, found := namespaces[p.Namespace]
+		if !found {
+			ns = newPodRollup()
+			namespaces[p.Namespace] = ns
+		}
+
+		counts := map[string]int64{"pods_total": 1}
+		if p.Status.Phase != "" {
+			counts["pods_"+strings.ToLower(string(p.Status.Phase))] = 1
+		}
+
+		// Only account for resources of pods that are not yet finished
+		if p.Status.Phase != corev1.PodSucceeded && p.Status.Phase != corev1.PodFailed {
+			for _, c := range p.Spec.Containers {
+				if val, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
+					counts["resource_requests_millicpu_units"] += ki.convertQuantity(val.String(), 1000)
+				}
+				if val, ok := c.Resources.Requests[corev1.ResourceMemory]; ok {
+					counts["resource_requests_memory_bytes"] += ki.convertQuantity(val.String(), 1)
+				}
+				if val, ok := c.Resources.Limits[corev1.ResourceCPU]; ok {
+					counts["resource_limits_millicpu_units"] += ki.convertQuantity(val.String(), 1000)
+				}
+				if val, ok := c.Resources.Limits[corev1.ResourceMemory]; ok {
+					counts["resource_limits_memory_bytes"] += ki.convertQuantity(val.String(), 1)
+				}
+			}
+		}
+
+		// Pods in phases not known in advance are counted as well
+		for k, v := range counts {
+			cluster[k] += v
+			ns[k] += v
+		}
+	}
+
+	if ki.rollupCluster {
+		acc.AddFields(clusterMeasurement, rollupFields(cluster), map[string]string{})
+	}
+	if ki.rollupNamespace {
+		for name, counters := range namespaces {
+			acc.AddFields(namespaceMeasurement, rollupFields(counters), map[string]string{"namespace": name})
+		}
+	}
+}
+
+// rollupNodes emits the number of nodes in total and per condition for the
+// cluster if enabled
+func (ki *KubernetesInventory) rollupNodes(nodes []corev1.Node, acc telegraf.Accumulator) {
+	if !ki.rollupCluster {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"nodes_total":         int64(len(nodes)),
+		"nodes_unschedulable": int64(0),
+		"nodes_ready":         int64(0),
+	}
+	for i := range nodes {
+		n := &nodes[i]
+		if n.Spec.Unschedulable {
+			fields["nodes_unschedulable"] = fields["nodes_unschedulable"].(int64) + 1
+		}
+		for _, c := range n.Status.Conditions {
+			key := "nodes_" + internal.SnakeCase(string(c.Type))
+			count, _ := fields[key].(int64)
+			if c.Status == corev1.ConditionTrue {
+				count++
+			}
+			fields[key] = count
+		}
+	}
+	acc.AddFields(clusterMeasurement, fields, map[string]string{})
+}
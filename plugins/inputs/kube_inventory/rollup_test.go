@@ -0,0 +1,211 @@
+package kube_inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestRollupInvalid(t *testing.T) {
+	plugin := &KubernetesInventory{
+		Rollups: []string{"cluster", "deployment"},
+		Log:     testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid rollup "deployment"`)
+}
+
+func TestRollupPods(t *testing.T) {
+	container := func(cpu, mem string) corev1.Container {
+		return corev1.Container{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					"cpu":    resource.MustParse(cpu),
+					"memory": resource.MustParse(mem),
+				},
+				Limits: corev1.ResourceList{
+					"cpu":    resource.MustParse("1"),
+					"memory": resource.MustParse("1Gi"),
+				},
+			},
+		}
+	}
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{container("100m", "128Mi"), container("200m", "128Mi")}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{container("500m", "1Gi")}},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tools", Name: "c"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{container("250m", "256Mi")}},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			clusterMeasurement,
+			map[string]string{},
+			map[string]interface{}{
+				"pods_total":                       int64(3),
+				"pods_pending":                     int64(1),
+				"pods_running":                     int64(1),
+				"pods_succeeded":                   int64(1),
+				"pods_failed":                      int64(0),
+				"pods_unknown":                     int64(0),
+				"resource_requests_millicpu_units": int64(550),
+				"resource_requests_memory_bytes":   int64(512 * 1024 * 1024),
+				"resource_limits_millicpu_units":   int64(3000),
+				"resource_limits_memory_bytes":     int64(3 * 1024 * 1024 * 1024),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			namespaceMeasurement,
+			map[string]string{"namespace": "default"},
+			map[string]interface{}{
+				"pods_total":                       int64(2),
+				"pods_pending":                     int64(0),
+				"pods_running":                     int64(1),
+				"pods_succeeded":                   int64(1),
+				"pods_failed":                      int64(0),
+				"pods_unknown":                     int64(0),
+				"resource_requests_millicpu_units": int64(300),
+				"resource_requests_memory_bytes":   int64(256 * 1024 * 1024),
+				"resource_limits_millicpu_units":   int64(2000),
+				"resource_limits_memory_bytes":     int64(2 * 1024 * 1024 * 1024),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			namespaceMeasurement,
+			map[string]string{"namespace": "tools"},
+			map[string]interface{}{
+				"pods_total":                       int64(1),
+				"pods_pending":                     int64(1),
+				"pods_running":                     int64(0),
+				"pods_succeeded":                   int64(0),
+				"pods_failed":                      int64(0),
+				"pods_unknown":                     int64(0),
+				"resource_requests_millicpu_units": int64(250),
+				"resource_requests_memory_bytes":   int64(256 * 1024 * 1024),
+				"resource_limits_millicpu_units":   int64(1000),
+				"resource_limits_memory_bytes":     int64(1024 * 1024 * 1024),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	plugin := &KubernetesInventory{
+		rollupCluster:   true,
+		rollupNamespace: true,
+		Log:             testutil.Logger{},
+	}
+
+	var acc testutil.Accumulator
+	plugin.rollupPods(pods, &acc)
+	require.NoError(t, acc.FirstError())
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestRollupPodsUnknownPhase(t *testing.T) {
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"},
+			Status:     corev1.PodStatus{Phase: corev1.PodPhase("Evicted")},
+		},
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			clusterMeasurement,
+			map[string]string{},
+			map[string]interface{}{
+				"pods_total":                       int64(1),
+				"pods_pending":                     int64(0),
+				"pods_running":                     int64(0),
+				"pods_succeeded":                   int64(0),
+				"pods_failed":                      int64(0),
+				"pods_unknown":                     int64(0),
+				"pods_evicted":                     int64(1),
+				"resource_requests_millicpu_units": int64(0),
+				"resource_requests_memory_bytes":   int64(0),
+				"resource_limits_millicpu_units":   int64(0),
+				"resource_limits_memory_bytes":     int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	plugin := &KubernetesInventory{
+		rollupCluster: true,
+		Log:           testutil.Logger{},
+	}
+
+	var acc testutil.Accumulator
+	plugin.rollupPods(pods, &acc)
+	require.NoError(t, acc.FirstError())
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestRollupNodes(t *testing.T) {
+	nodes := []corev1.Node{
+		{
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+					{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+				},
+			},
+		},
+		{
+			Spec: corev1.NodeSpec{Unschedulable: true},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionUnknown},
+					{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue},
+				},
+			},
+		},
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			clusterMeasurement,
+			map[string]string{},
+			map[string]interface{}{
+				"nodes_total":           int64(2),
+				"nodes_unschedulable":   int64(1),
+				"nodes_ready":           int64(1),
+				"nodes_memory_pressure": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	plugin := &KubernetesInventory{rollupCluster: true, Log: testutil.Logger{}}
+
+	var acc testutil.Accumulator
+	plugin.rollupNodes(nodes, &acc)
+	require.NoError(t, acc.FirstError())
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Namespace rollups do not cover nodes
+	plugin = &KubernetesInventory{rollupNamespace: true, Log: testutil.Logger{}}
+	acc.ClearMetrics()
+	plugin.rollupNodes(nodes, &acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,119 @@
+package kube_inventory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
+// resourceSelection holds the fields and labels to collect for a resource
+type resourceSelection struct {
+	FieldsInclude []string `toml:"fields_include"`
+	FieldsExclude []string `toml:"fields_exclude"`
+	LabelsAsTags  []string `toml:"labels_as_tags"`
+
+	fieldFilter filter.Filter
+	labelFilter filter.Filter
+}
+
+func (s *resourceSelection) init() error {
+	var err error
+	if len(s.FieldsInclude) > 0 || len(s.FieldsExclude) > 0 {
+		s.fieldFilter, err = filter.NewIncludeExcludeFilter(s.FieldsInclude, s.FieldsExclude)
+		if err != nil {
+			return fmt.Errorf("creating field filter failed: %w", err)
+		}
+	}
+
+	s.labelFilter, err = filter.Compile(s.LabelsAsTags)
+	if err != nil {
+		return fmt.Errorf("creating label filter failed: %w", err)
+	}
+	return nil
+}
+
+// withLabels returns an accumulator adding the labels of a resource object
+// selected by the labels_as_tags setting as "label_<key>" tags
+func (ki *KubernetesInventory) withLabels(acc telegraf.Accumulator, resource string, labels map[string]string) telegraf.Accumulator {
+	selection, found := ki.Resources[resource]
+	if !found || selection.labelFilter == nil {
+		return acc
+	}
+
+	tags := make(map[string]string)
+	for key, val := range labels {
+		if selection.labelFilter.Match(key) {
+			tags["label_"+key] = val
+		}
+	}
+	if len(tags) == 0 {
+		return acc
+	}
+	return &labelAccumulator{Accumulator: acc, tags: tags}
+}
+
+// labelAccumulator adds the given tags to all metrics
+type labelAccumulator struct {
+	telegraf.Accumulator
+	tags map[string]string
+}
+
+func (a *labelAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.Accumulator.AddFields(measurement, fields, a.apply(tags), t...)
+}
+
+func (a *labelAccumulator) AddGauge(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.Accumulator.AddGauge(measurement, fields, a.apply(tags), t...)
+}
+
+func (a *labelAccumulator) AddCounter(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.Accumulator.AddCounter(measurement, fields, a.apply(tags), t...)
+}
+
+func (a *labelAccumulator) apply(tags map[string]string) map[string]string {
+	if tags == nil {
+		tags = make(map[string]string, len(a.tags))
+	}
+	for key, val := range a.tags {
+		if _, found := tags[key]; !found {
+			tags[key] = val
+		}
+	}
+	return tags
+}
+
+// selectionAccumulator drops the fields not selected for a resource and
+// skips metrics without any remaining field
+type selectionAccumulator struct {
+	telegraf.Accumulator
+	filter filter.Filter
+}
+
+func (a *selectionAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	if a.apply(fields) {
+		a.Accumulator.AddFields(measurement, fields, tags, t...)
+	}
+}
+
+func (a *selectionAccumulator) AddGauge(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	if a.apply(fields) {
+		a.Accumulator.AddGauge(measurement, fields, tags, t...)
+	}
+}
+
+func (a *selectionAccumulator) AddCounter(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	if a.apply(fields) {
+		a.Accumulator.AddCounter(measurement, fields, tags, t...)
+	}
+}
+
+func (a *selectionAccumulator) apply(fields map[string]interface{}) bool {
+	for key := range fields {
+		if !a.filter.Match(key) {
+			delete(fields, key)
+		}
+	}
+	return len(fields) > 0
+}
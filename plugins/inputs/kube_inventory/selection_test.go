@@ -0,0 +1,122 @@
+package kube_inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestResourceSelectionInvalidResource(t *testing.T) {
+	ki := &KubernetesInventory{
+		Resources: map[string]*resourceSelection{
+			"foo": {FieldsInclude: []string{"created"}},
+		},
+	}
+	require.ErrorContains(t, ki.Init(), `invalid resource "foo" in field selection`)
+}
+
+func TestResourceSelection(t *testing.T) {
+	now := time.Now()
+	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 1, 36, 0, now.Location())
+
+	deployment := &v1.Deployment{
+		Status: v1.DeploymentStatus{
+			AvailableReplicas:   1,
+			UnavailableReplicas: 4,
+		},
+		Spec: v1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{},
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "deploy1",
+			Labels: map[string]string{
+				"app":               "web",
+				"app.kubernetes.io": "frontend",
+				"pod-template-hash": "5d8f9c7b4",
+			},
+			CreationTimestamp: metav1.Time{Time: now},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		selection *resourceSelection
+		expected  []telegraf.Metric
+	}{
+		{
+			name:      "no selection",
+			selection: &resourceSelection{},
+			expected: []telegraf.Metric{
+				metric.New(
+					"kubernetes_deployment",
+					map[string]string{
+						"namespace":       "ns1",
+						"deployment_name": "deploy1",
+					},
+					map[string]interface{}{
+						"replicas_available":   int32(1),
+						"replicas_unavailable": int32(4),
+						"created":              now.UnixNano(),
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "fields and labels",
+			selection: &resourceSelection{
+				FieldsExclude: []string{"created"},
+				LabelsAsTags:  []string{"app*"},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"kubernetes_deployment",
+					map[string]string{
+						"namespace":               "ns1",
+						"deployment_name":         "deploy1",
+						"label_app":               "web",
+						"label_app.kubernetes.io": "frontend",
+					},
+					map[string]interface{}{
+						"replicas_available":   int32(1),
+						"replicas_unavailable": int32(4),
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "no remaining fields",
+			selection: &resourceSelection{
+				FieldsInclude: []string{"foo"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.selection.init())
+			ki := &KubernetesInventory{
+				Resources: map[string]*resourceSelection{"deployments": tt.selection},
+			}
+			require.NoError(t, ki.createSelectorFilters())
+
+			acc := new(testutil.Accumulator)
+			var a telegraf.Accumulator = acc
+			if tt.selection.fieldFilter != nil {
+				a = &selectionAccumulator{Accumulator: acc, filter: tt.selection.fieldFilter}
+			}
+			ki.gatherDeployment(deployment, ki.withLabels(a, "deployments", deployment.Labels))
+
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
@@ -15,7 +15,7 @@ func collectServices(ctx context.Context, acc telegraf.Accumulator, ki *Kubernet
 		return
 	}
 	for i := range list.Items {
-		ki.gatherService(&list.Items[i], acc)
+		ki.gatherService(&list.Items[i], ki.withLabels(acc, "services", list.Items[i].Labels))
 	}
 }
 
@@ -15,7 +15,7 @@ func collectStatefulSets(ctx context.Context, acc telegraf.Accumulator, ki *Kube
 		return
 	}
 	for i := range list.Items {
-		ki.gatherStatefulSet(&list.Items[i], acc)
+		ki.gatherStatefulSet(&list.Items[i], ki.withLabels(acc, "statefulsets", list.Items[i].Labels))
 	}
 }
 
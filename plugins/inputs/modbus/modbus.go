@@ -69,6 +69,7 @@ type Modbus struct {
 type workarounds struct {
 	AfterConnectPause          config.Duration `toml:"pause_after_connect"`
 	PollPause                  config.Duration `toml:"pause_between_requests"`
+	SlavePause                 config.Duration `toml:"pause_between_slaves"`
 	CloseAfterGather           bool            `toml:"close_connection_after_gather"`
 	ReadCoilsStartingAtZero    bool            `toml:"read_coils_starting_at_zero"`
 	StringRegisterLocation     string          `toml:"string_register_location"`
@@ -236,9 +237,15 @@ func (m *Modbus) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	var nextSlave time.Time
 	for slaveID, requests := range m.requests {
+		// Some (serial) devices require a pause when switching slaves...
+		time.Sleep(time.Until(nextSlave))
+
 		m.Log.Debugf("Reading slave %d for %s...", slaveID, m.Controller)
-		if err := m.readSlaveData(slaveID, requests); err != nil {
+		err := m.readSlaveData(slaveID, requests)
+		nextSlave = time.Now().Add(time.Duration(m.Workarounds.SlavePause))
+		if err != nil {
 			acc.AddError(fmt.Errorf("slave %d on controller %q: %w", slaveID, m.Controller, err))
 			var mbErr *mb.Error
 			if !errors.As(err, &mbErr) || mbErr.ExceptionCode != mb.ExceptionCodeServerDeviceBusy {
@@ -742,3 +742,47 @@ func TestWorkaroundsStringRegisterLocationInvalid(t *testing.T) {
 	}
 	require.ErrorContains(t, plugin.Init(), `invalid 'string_register_location'`)
 }
+
+func TestWorkaroundsPauseBetweenSlaves(t *testing.T) {
+	serv := mbserver.NewServer()
+	require.NoError(t, serv.ListenTCP("localhost:1502"))
+	defer serv.Close()
+
+	// Record the time of each request sent to the device
+	var requests []time.Time
+	serv.RegisterFunctionHandler(1,
+		func(*mbserver.Server, mbserver.Framer) ([]byte, *mbserver.Exception) {
+			requests = append(requests, time.Now())
+			return []byte{1, 0}, &mbserver.Success
+		})
+
+	plugin := &Modbus{
+		Name:              "Test",
+		Controller:        "tcp://localhost:1502",
+		ConfigurationType: "request",
+		Log:               testutil.Logger{Quiet: true},
+		Workarounds:       workarounds{SlavePause: config.Duration(100 * time.Millisecond)},
+	}
+	plugin.Requests = []requestDefinition{
+		{
+			SlaveID:      1,
+			RegisterType: "coil",
+			Fields:       []requestFieldDefinition{{Name: "coil-0", Address: uint16(0)}},
+		},
+		{
+			SlaveID:      2,
+			RegisterType: "coil",
+			Fields:       []requestFieldDefinition{{Name: "coil-0", Address: uint16(0)}},
+		},
+	}
+	require.NoError(t, plugin.Init())
+	require.Len(t, plugin.requests, 2)
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+
+	require.Len(t, requests, 2)
+	require.GreaterOrEqual(t, requests[1].Sub(requests[0]), 100*time.Millisecond)
+}
@@ -0,0 +1,363 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package nvidia_dcgm
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// dcgmField describes a metric field with its DCGM field identifier and, if
+// available, the corresponding nvidia-smi query property. The field type is
+// fixed to avoid type conflicts between gathers.
+type dcgmField struct {
+	name    string
+	id      int
+	query   string
+	isFloat bool
+}
+
+var availableFields = []dcgmField{
+	{name: "sm_clock_mhz", id: 100, query: "clocks.sm"},
+	{name: "memory_clock_mhz", id: 101, query: "clocks.mem"},
+	{name: "temperature_gpu", id: 150, query: "temperature.gpu"},
+	{name: "power_draw_watts", id: 155, query: "power.draw", isFloat: true},
+	{name: "utilization_gpu", id: 203, query: "utilization.gpu"},
+	{name: "utilization_memory", id: 204, query: "utilization.memory"},
+	{name: "memory_total_mib", id: 250, query: "memory.total"},
+	{name: "memory_free_mib", id: 251, query: "memory.free"},
+	{name: "memory_used_mib", id: 252, query: "memory.used"},
+	{name: "ecc_sbe_volatile_total", id: 310, query: "ecc.errors.corrected.volatile.total"},
+	{name: "ecc_dbe_volatile_total", id: 311, query: "ecc.errors.uncorrected.volatile.total"},
+	{name: "ecc_sbe_aggregate_total", id: 312, query: "ecc.errors.corrected.aggregate.total"},
+	{name: "ecc_dbe_aggregate_total", id: 313, query: "ecc.errors.uncorrected.aggregate.total"},
+	{name: "nvlink_bandwidth_total", id: 449},
+	{name: "graphics_engine_active", id: 1001, isFloat: true},
+	{name: "sm_active", id: 1002, isFloat: true},
+	{name: "sm_occupancy", id: 1003, isFloat: true},
+	{name: "tensor_active", id: 1004, isFloat: true},
+	{name: "dram_active", id: 1005, isFloat: true},
+	{name: "pcie_tx_bytes", id: 1009},
+	{name: "pcie_rx_bytes", id: 1010},
+	{name: "nvlink_tx_bytes", id: 1011},
+	{name: "nvlink_rx_bytes", id: 1012},
+}
+
+// Entity types reported by dcgmi for GPUs and MIG instances
+var entityTypes = map[string]string{
+	"GPU":    "gpu",
+	"GPU-I":  "gpu_instance",
+	"GPU-CI": "compute_instance",
+}
+
+type NvidiaDCGM struct {
+	BinPath    string          `toml:"bin_path"`
+	SMIBinPath string          `toml:"smi_bin_path"`
+	HostEngine string          `toml:"host_engine"`
+	EntityIDs  []string        `toml:"entity_ids"`
+	Fields     []string        `toml:"fields"`
+	Processes  bool            `toml:"processes"`
+	Timeout    config.Duration `toml:"timeout"`
+	Log        telegraf.Logger `toml:"-"`
+
+	fields       []dcgmField
+	useSMI       bool
+	smiAvailable bool
+	dcgmFailed   bool
+	dcgmArgs     []string
+	smiArgs      []string
+}
+
+func (*NvidiaDCGM) SampleConfig() string {
+	return sampleConfig
+}
+
+func (n *NvidiaDCGM) Init() error {
+	if len(n.Fields) == 0 {
+		n.fields = availableFields
+	} else {
+		for _, name := range n.Fields {
+			idx := -1
+			for i, f := range availableFields {
+				if f.name == name {
+					idx = i
+					break
+				}
+			}
+			if idx < 0 {
+				return fmt.Errorf("unknown field %q", name)
+			}
+			n.fields = append(n.fields, availableFields[idx])
+		}
+	}
+
+	ids := make([]string, 0, len(n.fields))
+	queries := []string{"index", "uuid"}
+	for _, f := range n.fields {
+		ids = append(ids, strconv.Itoa(f.id))
+		if f.query != "" {
+			queries = append(queries, f.query)
+		}
+	}
+
+	n.dcgmArgs = []string{"dmon", "-c", "1", "-e", strings.Join(ids, ",")}
+	if len(n.EntityIDs) > 0 {
+		n.dcgmArgs = append(n.dcgmArgs, "-i", strings.Join(n.EntityIDs, ","))
+	}
+	if n.HostEngine != "" {
+		n.dcgmArgs = append(n.dcgmArgs, "--host", n.HostEngine)
+	}
+	n.smiArgs = []string{"--query-gpu=" + strings.Join(queries, ","), "--format=csv,noheader,nounits"}
+
+	return nil
+}
+
+func (n *NvidiaDCGM) Start(telegraf.Accumulator) error {
+	var errDCGM error
+	n.BinPath, errDCGM = locate(n.BinPath, "dcgmi")
+
+	var errSMI error
+	n.SMIBinPath, errSMI = locate(n.SMIBinPath, "nvidia-smi")
+
+	if errDCGM != nil {
+		if errSMI != nil {
+			return &internal.StartupError{Err: errors.Join(errDCGM, errSMI)}
+		}
+		n.Log.Infof("DCGM not available (%v), falling back to nvidia-smi with reduced set of fields", errDCGM)
+		n.useSMI = true
+	}
+	n.smiAvailable = errSMI == nil
+
+	if n.Processes && errSMI != nil {
+		return &internal.StartupError{Err: fmt.Errorf("collecting processes requires nvidia-smi: %w", errSMI)}
+	}
+
+	return nil
+}
+
+func (*NvidiaDCGM) Stop() {}
+
+func (n *NvidiaDCGM) Gather(acc telegraf.Accumulator) error {
+	if err := n.gatherGPUs(acc); err != nil {
+		return err
+	}
+
+	if n.Processes {
+		data, err := n.run(n.SMIBinPath, "--query-compute-apps=gpu_uuid,pid,process_name,used_memory", "--format=csv,noheader,nounits")
+		if err != nil {
+			return err
+		}
+		if err := parseProcesses(acc, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// gatherGPUs queries the GPU metrics using DCGM and falls back to nvidia-smi
+// if DCGM is not installed or the host engine cannot be queried
+func (n *NvidiaDCGM) gatherGPUs(acc telegraf.Accumulator) error {
+	if !n.useSMI {
+		data, err := n.run(n.BinPath, n.dcgmArgs...)
+		if err == nil {
+			if n.dcgmFailed {
+				n.Log.Info("Querying DCGM succeeded again, stopping nvidia-smi fallback")
+				n.dcgmFailed = false
+			}
+			return n.parseDCGM(acc, data)
+		}
+		if !n.smiAvailable {
+			return err
+		}
+		if !n.dcgmFailed {
+			n.Log.Warnf("%v, falling back to nvidia-smi with reduced set of fields", err)
+			n.dcgmFailed = true
+		}
+	}
+
+	data, err := n.run(n.SMIBinPath, n.smiArgs...)
+	if err != nil {
+		return err
+	}
+	return n.parseSMI(acc, data)
+}
+
+func (n *NvidiaDCGM) run(bin string, args ...string) ([]byte, error) {
+	data, err := internal.CombinedOutputTimeout(exec.Command(bin, args...), time.Duration(n.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("calling %q failed: %w", bin, err)
+	}
+	return data, nil
+}
+
+// parseDCGM parses the output of "dcgmi dmon" containing one line per entity
+// with the entity type, the entity ID and the values of the requested fields
+// in the requested order, e.g.
+//
+//	#Entity   SMCLK  GPUTL
+//	ID
+//	GPU 0     1410   42
+//	GPU-I 1   N/A    12
+func (n *NvidiaDCGM) parseDCGM(acc telegraf.Accumulator, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 2 || strings.HasPrefix(parts[0], "#") {
+			continue
+		}
+		entity, found := entityTypes[parts[0]]
+		if !found {
+			// Skip the ID header line and error messages
+			if !strings.EqualFold(parts[0], "id") {
+				n.Log.Debugf("Ignoring line %q", scanner.Text())
+			}
+			continue
+		}
+		values := parts[2:]
+		if len(values) != len(n.fields) {
+			return fmt.Errorf("expected %d values but got %d for %s %s", len(n.fields), len(values), parts[0], parts[1])
+		}
+
+		tags := map[string]string{
+			"entity":    entity,
+			"entity_id": parts[1],
+		}
+		fields := make(map[string]interface{}, len(values))
+		for i, v := range values {
+			if value, ok := parseValue(v, n.fields[i].isFloat); ok {
+				fields[n.fields[i].name] = value
+			}
+		}
+		if len(fields) > 0 {
+			acc.AddFields("nvidia_dcgm", fields, tags)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseSMI parses the CSV output of the nvidia-smi GPU query, the first two
+// columns being the GPU index and UUID followed by the requested fields
+// supported by nvidia-smi
+func (n *NvidiaDCGM) parseSMI(acc telegraf.Accumulator, data []byte) error {
+	queried := make([]dcgmField, 0, len(n.fields))
+	for _, f := range n.fields {
+		if f.query != "" {
+			queried = append(queried, f)
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("parsing nvidia-smi output failed: %w", err)
+	}
+
+	for _, record := range records {
+		if len(record) != len(queried)+2 {
+			return fmt.Errorf("expected %d columns but got %d", len(queried)+2, len(record))
+		}
+
+		tags := map[string]string{
+			"entity":    "gpu",
+			"entity_id": record[0],
+			"uuid":      record[1],
+		}
+		fields := make(map[string]interface{}, len(queried))
+		for i, v := range record[2:] {
+			if value, ok := parseValue(v, queried[i].isFloat); ok {
+				fields[queried[i].name] = value
+			}
+		}
+		if len(fields) > 0 {
+			acc.AddFields("nvidia_dcgm", fields, tags)
+		}
+	}
+	return nil
+}
+
+// parseProcesses parses the CSV output of the nvidia-smi compute application
+// query with the GPU UUID, process ID, name and used memory in MiB
+func parseProcesses(acc telegraf.Accumulator, data []byte) error {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = 4
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("parsing nvidia-smi process output failed: %w", err)
+	}
+
+	for _, record := range records {
+		tags := map[string]string{
+			"uuid":         record[0],
+			"pid":          record[1],
+			"process_name": record[2],
+		}
+		fields := make(map[string]interface{}, 1)
+		if value, ok := parseValue(record[3], false); ok {
+			fields["memory_used_mib"] = value
+		}
+		if len(fields) > 0 {
+			acc.AddFields("nvidia_dcgm_process", fields, tags)
+		}
+	}
+	return nil
+}
+
+// parseValue converts the given value to a float or an integer depending on
+// the field type, unsupported values such as "N/A" or "[Not Supported]" are
+// skipped
+func parseValue(v string, isFloat bool) (interface{}, bool) {
+	v = strings.TrimSpace(v)
+	if !isFloat {
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, true
+		}
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, false
+	}
+	if isFloat {
+		return f, true
+	}
+	return int64(math.Round(f)), true
+}
+
+// locate returns the given path if it exists or looks up the binary in PATH
+func locate(path, name string) (string, error) {
+	if path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return exec.LookPath(name)
+}
+
+func init() {
+	inputs.Add("nvidia_dcgm", func() telegraf.Input {
+		return &NvidiaDCGM{
+			BinPath:    "/usr/bin/dcgmi",
+			SMIBinPath: "/usr/bin/nvidia-smi",
+			Timeout:    config.Duration(5 * time.Second),
+		}
+	})
+}
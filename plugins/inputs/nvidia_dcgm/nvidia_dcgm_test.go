@@ -0,0 +1,232 @@
+package nvidia_dcgm
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitUnknownField(t *testing.T) {
+	plugin := &NvidiaDCGM{Fields: []string{"foo"}}
+	require.ErrorContains(t, plugin.Init(), `unknown field "foo"`)
+}
+
+func TestInitArguments(t *testing.T) {
+	plugin := &NvidiaDCGM{
+		Fields:     []string{"sm_clock_mhz", "utilization_gpu", "nvlink_tx_bytes"},
+		EntityIDs:  []string{"0", "i:1"},
+		HostEngine: "10.0.0.1",
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []string{"dmon", "-c", "1", "-e", "100,203,1011", "-i", "0,i:1", "--host", "10.0.0.1"}, plugin.dcgmArgs)
+	require.Equal(t, []string{"--query-gpu=index,uuid,clocks.sm,utilization.gpu", "--format=csv,noheader,nounits"}, plugin.smiArgs)
+}
+
+func TestParseDCGM(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "dmon.txt"))
+	require.NoError(t, err)
+
+	plugin := &NvidiaDCGM{
+		Fields: []string{"sm_clock_mhz", "utilization_gpu", "memory_used_mib", "ecc_sbe_volatile_total", "nvlink_tx_bytes"},
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.parseDCGM(&acc, data))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nvidia_dcgm",
+			map[string]string{"entity": "gpu", "entity_id": "1"},
+			map[string]interface{}{
+				"sm_clock_mhz":           int64(1410),
+				"utilization_gpu":        int64(87),
+				"memory_used_mib":        int64(40536),
+				"ecc_sbe_volatile_total": int64(0),
+				"nvlink_tx_bytes":        int64(123456789),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nvidia_dcgm",
+			map[string]string{"entity": "gpu", "entity_id": "0"},
+			map[string]interface{}{
+				"sm_clock_mhz":           int64(1410),
+				"utilization_gpu":        int64(42),
+				"memory_used_mib":        int64(10240),
+				"ecc_sbe_volatile_total": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nvidia_dcgm",
+			map[string]string{"entity": "gpu_instance", "entity_id": "3"},
+			map[string]interface{}{
+				"utilization_gpu": int64(12),
+				"memory_used_mib": int64(5120),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestParseDCGMFieldMismatch(t *testing.T) {
+	plugin := &NvidiaDCGM{
+		Fields: []string{"sm_clock_mhz"},
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.parseDCGM(&acc, []byte("GPU 0 1410 42\n")), "expected 1 values but got 2")
+}
+
+func TestParseDCGMFixedTypes(t *testing.T) {
+	plugin := &NvidiaDCGM{
+		Fields: []string{"temperature_gpu", "power_draw_watts", "sm_active"},
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.parseDCGM(&acc, []byte("GPU 0 45 300 1\nGPU 1 45.6 299.5 0.5\n")))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nvidia_dcgm",
+			map[string]string{"entity": "gpu", "entity_id": "0"},
+			map[string]interface{}{
+				"temperature_gpu":  int64(45),
+				"power_draw_watts": float64(300),
+				"sm_active":        float64(1),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nvidia_dcgm",
+			map[string]string{"entity": "gpu", "entity_id": "1"},
+			map[string]interface{}{
+				"temperature_gpu":  int64(46),
+				"power_draw_watts": float64(299.5),
+				"sm_active":        float64(0.5),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherFallbackOnDCGMFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows due to shell scripts")
+	}
+
+	// Simulate an installed DCGM without a reachable host engine
+	dir := t.TempDir()
+	dcgmi := filepath.Join(dir, "dcgmi")
+	require.NoError(t, os.WriteFile(dcgmi, []byte("#!/bin/sh\necho 'Error: unable to establish a connection to the host engine'\nexit 1\n"), 0o700))
+	testdata, err := filepath.Abs(filepath.Join("testdata", "query-gpu.csv"))
+	require.NoError(t, err)
+	smi := filepath.Join(dir, "nvidia-smi")
+	require.NoError(t, os.WriteFile(smi, []byte("#!/bin/sh\ncat "+testdata+"\n"), 0o700))
+
+	plugin := &NvidiaDCGM{
+		BinPath:    dcgmi,
+		SMIBinPath: smi,
+		Fields:     []string{"sm_clock_mhz", "utilization_gpu", "memory_used_mib", "ecc_sbe_volatile_total"},
+		Timeout:    config.Duration(5 * time.Second),
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.Contains(t, m.Tags(), "uuid")
+	}
+}
+
+func TestParseSMI(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "query-gpu.csv"))
+	require.NoError(t, err)
+
+	// Fields not supported by nvidia-smi are not queried
+	plugin := &NvidiaDCGM{
+		Fields: []string{"sm_clock_mhz", "utilization_gpu", "sm_active", "memory_used_mib", "ecc_sbe_volatile_total"},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.parseSMI(&acc, data))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nvidia_dcgm",
+			map[string]string{"entity": "gpu", "entity_id": "0", "uuid": "GPU-d5a7b1a0-1b2c-3d4e-5f60-718293a4b5c6"},
+			map[string]interface{}{
+				"sm_clock_mhz":           int64(1410),
+				"utilization_gpu":        int64(42),
+				"memory_used_mib":        int64(10240),
+				"ecc_sbe_volatile_total": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nvidia_dcgm",
+			map[string]string{"entity": "gpu", "entity_id": "1", "uuid": "GPU-0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0"},
+			map[string]interface{}{
+				"sm_clock_mhz":    int64(1410),
+				"utilization_gpu": int64(87),
+				"memory_used_mib": int64(40536),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestParseProcesses(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "query-compute-apps.csv"))
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	require.NoError(t, parseProcesses(&acc, data))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nvidia_dcgm_process",
+			map[string]string{
+				"uuid":         "GPU-d5a7b1a0-1b2c-3d4e-5f60-718293a4b5c6",
+				"pid":          "4242",
+				"process_name": "/usr/bin/python3",
+			},
+			map[string]interface{}{"memory_used_mib": int64(9800)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nvidia_dcgm_process",
+			map[string]string{
+				"uuid":         "GPU-0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0",
+				"pid":          "4711",
+				"process_name": "/opt/app/trainer",
+			},
+			map[string]interface{}{"memory_used_mib": int64(40000)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
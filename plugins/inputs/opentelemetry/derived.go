@@ -0,0 +1,256 @@
+package opentelemetry
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	spanDurationMetric = "span_duration_seconds"
+	logLevelMetric     = "log_records"
+)
+
+var availableDerivedMetrics = []string{"span_duration", "log_level"}
+
+var defaultSpanDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// durationSeries holds the histogram of span durations for one set of tags
+type durationSeries struct {
+	tags   map[string]string
+	counts []uint64 // per bucket with the last bucket being +Inf
+	count  uint64
+	sum    float64
+}
+
+// levelSeries holds the number of log records for one set of tags
+type levelSeries struct {
+	tags  map[string]string
+	count uint64
+}
+
+// derivedMetrics accumulates metrics derived from the received spans and log
+// records. All values are cumulative since the start of the plugin.
+type derivedMetrics struct {
+	schema       string
+	spanDuration bool
+	spanDims     []string
+	buckets      []float64
+	logLevel     bool
+	logDims      []string
+	spans        map[string]*durationSeries
+	logs         map[string]*levelSeries
+	sync.Mutex
+}
+
+func (d *derivedMetrics) addTraces(traces ptrace.Traces) {
+	if !d.spanDuration {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	resourceSpans := traces.ResourceSpans()
+	for i := 0; i < resourceSpans.Len(); i++ {
+		rs := resourceSpans.At(i)
+		resourceAttrs := rs.Resource().Attributes()
+		scopeSpans := rs.ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			spans := scopeSpans.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				start, end := span.StartTimestamp(), span.EndTimestamp()
+				if start == 0 || end < start {
+					continue
+				}
+
+				tags := make(map[string]string, len(d.spanDims))
+				for _, dim := range d.spanDims {
+					if v, found := spanDimension(span, dim, span.Attributes(), resourceAttrs); found {
+						tags[dim] = v
+					}
+				}
+				d.observeSpan(tags, end.AsTime().Sub(start.AsTime()).Seconds())
+			}
+		}
+	}
+}
+
+func (d *derivedMetrics) observeSpan(tags map[string]string, duration float64) {
+	key := seriesKey(d.spanDims, tags)
+	series, found := d.spans[key]
+	if !found {
+		series = &durationSeries{
+			tags:   tags,
+			counts: make([]uint64, len(d.buckets)+1),
+		}
+		d.spans[key] = series
+	}
+
+	// The bucket index is the first upper bound not smaller than the duration
+	// with the +Inf bucket catching all larger values
+	series.counts[sort.SearchFloat64s(d.buckets, duration)]++
+	series.count++
+	series.sum += duration
+}
+
+func (d *derivedMetrics) addLogs(logs plog.Logs) {
+	if !d.logLevel {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	resourceLogs := logs.ResourceLogs()
+	for i := 0; i < resourceLogs.Len(); i++ {
+		rl := resourceLogs.At(i)
+		resourceAttrs := rl.Resource().Attributes()
+		scopeLogs := rl.ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			records := scopeLogs.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				record := records.At(k)
+
+				tags := make(map[string]string, len(d.logDims)+1)
+				for _, dim := range d.logDims {
+					if v, found := attribute(dim, record.Attributes(), resourceAttrs); found {
+						tags[dim] = v
+					}
+				}
+				tags["level"] = logLevel(record)
+
+				key := seriesKey(d.logDims, tags) + "\x00" + tags["level"]
+				series, found := d.logs[key]
+				if !found {
+					series = &levelSeries{tags: tags}
+					d.logs[key] = series
+				}
+				series.count++
+			}
+		}
+	}
+}
+
+// emit adds the current state of all series to the accumulator using the
+// configured metrics schema
+func (d *derivedMetrics) emit(acc telegraf.Accumulator, ts time.Time) {
+	d.Lock()
+	defer d.Unlock()
+
+	for _, series := range d.spans {
+		d.emitDuration(acc, series, ts)
+	}
+
+	for _, series := range d.logs {
+		switch d.schema {
+		case "prometheus-v1":
+			acc.AddCounter(logLevelMetric, map[string]interface{}{"counter": float64(series.count)}, series.tags, ts)
+		case "prometheus-v2":
+			acc.AddCounter("prometheus", map[string]interface{}{logLevelMetric: float64(series.count)}, series.tags, ts)
+		}
+	}
+}
+
+func (d *derivedMetrics) emitDuration(acc telegraf.Accumulator, series *durationSeries, ts time.Time) {
+	switch d.schema {
+	case "prometheus-v1":
+		fields := map[string]interface{}{
+			"count": float64(series.count),
+			"sum":   series.sum,
+		}
+		var cumulative uint64
+		for i, bound := range d.buckets {
+			cumulative += series.counts[i]
+			fields[strconv.FormatFloat(bound, 'f', -1, 64)] = float64(cumulative)
+		}
+		fields["+Inf"] = float64(series.count)
+		acc.AddHistogram(spanDurationMetric, fields, series.tags, ts)
+	case "prometheus-v2":
+		var cumulative uint64
+		for i := range series.counts {
+			cumulative += series.counts[i]
+			le := "+Inf"
+			if i < len(d.buckets) {
+				le = strconv.FormatFloat(d.buckets[i], 'f', -1, 64)
+			}
+			tags := make(map[string]string, len(series.tags)+1)
+			for k, v := range series.tags {
+				tags[k] = v
+			}
+			tags["le"] = le
+			acc.AddHistogram("prometheus", map[string]interface{}{spanDurationMetric + "_bucket": float64(cumulative)}, tags, ts)
+		}
+		fields := map[string]interface{}{
+			spanDurationMetric + "_count": float64(series.count),
+			spanDurationMetric + "_sum":   series.sum,
+		}
+		acc.AddHistogram("prometheus", fields, series.tags, ts)
+	}
+}
+
+// spanDimension resolves the given dimension for the span where "span.name",
+// "span.kind" and "status.code" refer to the span properties and all other
+// dimensions to the span or resource attributes
+func spanDimension(span ptrace.Span, dim string, attrs ...pcommon.Map) (string, bool) {
+	switch dim {
+	case "span.name":
+		return span.Name(), true
+	case "span.kind":
+		return span.Kind().String(), true
+	case "status.code":
+		return span.Status().Code().String(), true
+	}
+	return attribute(dim, attrs...)
+}
+
+// attribute returns the value of the first attribute map containing the key
+func attribute(key string, attrs ...pcommon.Map) (string, bool) {
+	for _, m := range attrs {
+		if v, found := m.Get(key); found {
+			return v.AsString(), true
+		}
+	}
+	return "", false
+}
+
+// logLevel maps the severity of the log record to a level name falling back
+// to the severity text if no severity number is set
+func logLevel(record plog.LogRecord) string {
+	switch n := record.SeverityNumber(); {
+	case n >= plog.SeverityNumberFatal:
+		return "fatal"
+	case n >= plog.SeverityNumberError:
+		return "error"
+	case n >= plog.SeverityNumberWarn:
+		return "warn"
+	case n >= plog.SeverityNumberInfo:
+		return "info"
+	case n >= plog.SeverityNumberDebug:
+		return "debug"
+	case n >= plog.SeverityNumberTrace:
+		return "trace"
+	}
+
+	if text := record.SeverityText(); text != "" {
+		return strings.ToLower(text)
+	}
+	return "unspecified"
+}
+
+func seriesKey(dims []string, tags map[string]string) string {
+	values := make([]string, 0, len(dims))
+	for _, dim := range dims {
+		values = append(values, tags[dim])
+	}
+	return strings.Join(values, "\x00")
+}
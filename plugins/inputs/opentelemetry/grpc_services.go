@@ -14,11 +14,12 @@ import (
 type traceService struct {
 	ptraceotlp.UnimplementedGRPCServer
 	exporter *otel2influx.OtelTracesToLineProtocol
+	derived  *derivedMetrics
 }
 
 var _ ptraceotlp.GRPCServer = (*traceService)(nil)
 
-func newTraceService(logger common.Logger, writer *writeToAccumulator, spanDimensions []string) (*traceService, error) {
+func newTraceService(logger common.Logger, writer *writeToAccumulator, spanDimensions []string, derived *derivedMetrics) (*traceService, error) {
 	expConfig := otel2influx.DefaultOtelTracesToLineProtocolConfig()
 	expConfig.Logger = logger
 	expConfig.Writer = writer
@@ -29,11 +30,15 @@ func newTraceService(logger common.Logger, writer *writeToAccumulator, spanDimen
 	}
 	return &traceService{
 		exporter: exp,
+		derived:  derived,
 	}, nil
 }
 
 // Export processes and exports the trace data received in the request.
 func (s *traceService) Export(ctx context.Context, req ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error) {
+	if s.derived != nil {
+		s.derived.addTraces(req.Traces())
+	}
 	err := s.exporter.WriteTraces(ctx, req.Traces())
 	return ptraceotlp.NewExportResponse(), err
 }
@@ -78,11 +83,12 @@ func (s *metricsService) Export(ctx context.Context, req pmetricotlp.ExportReque
 type logsService struct {
 	plogotlp.UnimplementedGRPCServer
 	converter *otel2influx.OtelLogsToLineProtocol
+	derived   *derivedMetrics
 }
 
 var _ plogotlp.GRPCServer = (*logsService)(nil)
 
-func newLogsService(logger common.Logger, writer *writeToAccumulator, logRecordDimensions []string) (*logsService, error) {
+func newLogsService(logger common.Logger, writer *writeToAccumulator, logRecordDimensions []string, derived *derivedMetrics) (*logsService, error) {
 	expConfig := otel2influx.DefaultOtelLogsToLineProtocolConfig()
 	expConfig.Logger = logger
 	expConfig.Writer = writer
@@ -93,11 +99,15 @@ func newLogsService(logger common.Logger, writer *writeToAccumulator, logRecordD
 	}
 	return &logsService{
 		converter: exp,
+		derived:   derived,
 	}, nil
 }
 
 // Export processes and exports the logs data received in the request.
 func (s *logsService) Export(ctx context.Context, req plogotlp.ExportRequest) (plogotlp.ExportResponse, error) {
+	if s.derived != nil {
+		s.derived.addLogs(req.Logs())
+	}
 	err := s.converter.WriteLogs(ctx, req.Logs())
 	return plogotlp.NewExportResponse(), err
 }
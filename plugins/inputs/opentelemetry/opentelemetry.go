@@ -3,6 +3,7 @@ package opentelemetry
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
@@ -26,18 +27,23 @@ import (
 var sampleConfig string
 
 type OpenTelemetry struct {
-	ServiceAddress      string          `toml:"service_address"`
-	SpanDimensions      []string        `toml:"span_dimensions"`
-	LogRecordDimensions []string        `toml:"log_record_dimensions"`
-	ProfileDimensions   []string        `toml:"profile_dimensions"`
-	MetricsSchema       string          `toml:"metrics_schema"`
-	MaxMsgSize          config.Size     `toml:"max_msg_size"`
-	Timeout             config.Duration `toml:"timeout"`
-	Log                 telegraf.Logger `toml:"-"`
+	ServiceAddress      string            `toml:"service_address"`
+	SpanDimensions      []string          `toml:"span_dimensions"`
+	LogRecordDimensions []string          `toml:"log_record_dimensions"`
+	ProfileDimensions   []string          `toml:"profile_dimensions"`
+	MetricsSchema       string            `toml:"metrics_schema"`
+	DerivedMetrics      []string          `toml:"derived_metrics"`
+	SpanMetricDims      []string          `toml:"span_metric_dimensions"`
+	SpanDurationBuckets []config.Duration `toml:"span_duration_buckets"`
+	LogMetricDims       []string          `toml:"log_metric_dimensions"`
+	MaxMsgSize          config.Size       `toml:"max_msg_size"`
+	Timeout             config.Duration   `toml:"timeout"`
+	Log                 telegraf.Logger   `toml:"-"`
 	tls.ServerConfig
 
 	listener   net.Listener // overridden in tests
 	grpcServer *grpc.Server
+	derived    *derivedMetrics
 
 	wg sync.WaitGroup
 }
@@ -58,6 +64,47 @@ func (o *OpenTelemetry) Init() error {
 		return fmt.Errorf("invalid metric schema %q", o.MetricsSchema)
 	}
 
+	return o.initDerivedMetrics()
+}
+
+func (o *OpenTelemetry) initDerivedMetrics() error {
+	if len(o.DerivedMetrics) == 0 {
+		return nil
+	}
+
+	d := &derivedMetrics{
+		schema:   o.MetricsSchema,
+		spanDims: o.SpanMetricDims,
+		logDims:  o.LogMetricDims,
+		spans:    make(map[string]*durationSeries),
+		logs:     make(map[string]*levelSeries),
+	}
+	for _, m := range o.DerivedMetrics {
+		switch m {
+		case "span_duration":
+			d.spanDuration = true
+		case "log_level":
+			d.logLevel = true
+		default:
+			return fmt.Errorf("invalid derived metric %q, available are %v", m, availableDerivedMetrics)
+		}
+	}
+
+	if len(o.SpanDurationBuckets) == 0 {
+		d.buckets = defaultSpanDurationBuckets
+	}
+	for i, b := range o.SpanDurationBuckets {
+		bound := time.Duration(b).Seconds()
+		if bound <= 0 {
+			return fmt.Errorf("span duration bucket %s must be positive", time.Duration(b))
+		}
+		if i > 0 && bound <= d.buckets[i-1] {
+			return errors.New("span duration buckets must be in increasing order")
+		}
+		d.buckets = append(d.buckets, bound)
+	}
+	o.derived = d
+
 	return nil
 }
 
@@ -79,7 +126,7 @@ func (o *OpenTelemetry) Start(acc telegraf.Accumulator) error {
 	influxWriter := &writeToAccumulator{acc}
 	o.grpcServer = grpc.NewServer(grpcOptions...)
 
-	traceSvc, err := newTraceService(logger, influxWriter, o.SpanDimensions)
+	traceSvc, err := newTraceService(logger, influxWriter, o.SpanDimensions, o.derived)
 	if err != nil {
 		return err
 	}
@@ -91,7 +138,7 @@ func (o *OpenTelemetry) Start(acc telegraf.Accumulator) error {
 	}
 	pmetricotlp.RegisterGRPCServer(o.grpcServer, metricsSvc)
 
-	logsSvc, err := newLogsService(logger, influxWriter, o.LogRecordDimensions)
+	logsSvc, err := newLogsService(logger, influxWriter, o.LogRecordDimensions, o.derived)
 	if err != nil {
 		return err
 	}
@@ -119,7 +166,10 @@ func (o *OpenTelemetry) Start(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func (*OpenTelemetry) Gather(telegraf.Accumulator) error {
+func (o *OpenTelemetry) Gather(acc telegraf.Accumulator) error {
+	if o.derived != nil {
+		o.derived.emit(acc, time.Now())
+	}
 	return nil
 }
 
@@ -137,6 +187,8 @@ func init() {
 		return &OpenTelemetry{
 			SpanDimensions:      otel2influx.DefaultOtelTracesToLineProtocolConfig().SpanDimensions,
 			LogRecordDimensions: otel2influx.DefaultOtelLogsToLineProtocolConfig().LogRecordDimensions,
+			SpanMetricDims:      []string{"service.name", "span.name"},
+			LogMetricDims:       []string{"service.name"},
 			Timeout:             config.Duration(5 * time.Second),
 		}
 	})
@@ -12,8 +12,12 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/influxdata/influxdb-observability/otel2influx"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
@@ -255,3 +259,154 @@ func TestCases(t *testing.T) {
 		})
 	}
 }
+
+func TestInitDerivedMetricsInvalid(t *testing.T) {
+	plugin := &OpenTelemetry{DerivedMetrics: []string{"foo"}}
+	require.ErrorContains(t, plugin.Init(), `invalid derived metric "foo"`)
+
+	plugin = &OpenTelemetry{
+		DerivedMetrics:      []string{"span_duration"},
+		SpanDurationBuckets: []config.Duration{config.Duration(time.Second), config.Duration(time.Millisecond)},
+	}
+	require.ErrorContains(t, plugin.Init(), "span duration buckets must be in increasing order")
+}
+
+func TestDerivedSpanDuration(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	traces := ptrace.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "checkout")
+	spans := rs.ScopeSpans().AppendEmpty().Spans()
+	for _, d := range []time.Duration{3 * time.Millisecond, 20 * time.Millisecond, 2 * time.Second} {
+		span := spans.AppendEmpty()
+		span.SetName("GET /cart")
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+		span.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(d)))
+	}
+	// Spans without start time are ignored
+	spans.AppendEmpty().SetName("GET /cart")
+
+	tags := map[string]string{
+		"service.name": "checkout",
+		"span.name":    "GET /cart",
+	}
+
+	tests := []struct {
+		name     string
+		schema   string
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "prometheus-v1",
+			schema: "prometheus-v1",
+			expected: []telegraf.Metric{
+				metric.New(
+					"span_duration_seconds",
+					tags,
+					map[string]interface{}{
+						"0.01":  float64(1),
+						"0.1":   float64(2),
+						"+Inf":  float64(3),
+						"count": float64(3),
+						"sum":   2.023,
+					},
+					start,
+					telegraf.Histogram,
+				),
+			},
+		},
+		{
+			name:   "prometheus-v2",
+			schema: "prometheus-v2",
+			expected: []telegraf.Metric{
+				metric.New(
+					"prometheus",
+					map[string]string{"service.name": "checkout", "span.name": "GET /cart", "le": "0.01"},
+					map[string]interface{}{"span_duration_seconds_bucket": float64(1)},
+					start,
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					map[string]string{"service.name": "checkout", "span.name": "GET /cart", "le": "0.1"},
+					map[string]interface{}{"span_duration_seconds_bucket": float64(2)},
+					start,
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					map[string]string{"service.name": "checkout", "span.name": "GET /cart", "le": "+Inf"},
+					map[string]interface{}{"span_duration_seconds_bucket": float64(3)},
+					start,
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					tags,
+					map[string]interface{}{
+						"span_duration_seconds_count": float64(3),
+						"span_duration_seconds_sum":   2.023,
+					},
+					start,
+					telegraf.Histogram,
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &OpenTelemetry{
+				MetricsSchema:       tt.schema,
+				DerivedMetrics:      []string{"span_duration"},
+				SpanMetricDims:      []string{"service.name", "span.name"},
+				SpanDurationBuckets: []config.Duration{config.Duration(10 * time.Millisecond), config.Duration(100 * time.Millisecond)},
+			}
+			require.NoError(t, plugin.Init())
+
+			plugin.derived.addTraces(traces)
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(),
+				testutil.IgnoreTime(), testutil.SortMetrics(), cmpopts.EquateApprox(0, 1e-9))
+		})
+	}
+}
+
+func TestDerivedLogLevel(t *testing.T) {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "checkout")
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().SetSeverityNumber(plog.SeverityNumberInfo)
+	records.AppendEmpty().SetSeverityNumber(plog.SeverityNumberInfo2)
+	records.AppendEmpty().SetSeverityNumber(plog.SeverityNumberError)
+	records.AppendEmpty().SetSeverityText("NOTICE")
+	records.AppendEmpty()
+
+	plugin := &OpenTelemetry{
+		MetricsSchema:  "prometheus-v1",
+		DerivedMetrics: []string{"log_level"},
+		LogMetricDims:  []string{"service.name"},
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.derived.addLogs(logs)
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := make([]telegraf.Metric, 0, 4)
+	for level, count := range map[string]float64{"info": 2, "error": 1, "notice": 1, "unspecified": 1} {
+		expected = append(expected, metric.New(
+			"log_records",
+			map[string]string{"service.name": "checkout", "level": level},
+			map[string]interface{}{"counter": count},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		))
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
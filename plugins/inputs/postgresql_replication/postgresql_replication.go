@@ -0,0 +1,330 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package postgresql_replication
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/postgresql"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var availableCollectors = []string{"slots", "wal", "vacuum", "transactions"}
+
+// Current WAL position on the primary and the last received position on
+// standby servers where pg_current_wal_lsn() is not available
+const currentLSN = `CASE WHEN pg_is_in_recovery()
+	THEN COALESCE(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn())
+	ELSE pg_current_wal_lsn() END`
+
+type PostgresqlReplication struct {
+	Collect                  []string        `toml:"collect"`
+	LongTransactionThreshold config.Duration `toml:"long_transaction_threshold"`
+	Timeout                  config.Duration `toml:"timeout"`
+	PreparedStatements       bool            `toml:"prepared_statements"`
+	Log                      telegraf.Logger `toml:"-"`
+	postgresql.Config
+
+	service *postgresql.Service
+	version int
+
+	// WAL position and time of the previous gather for computing the rate
+	lastLSN    int64
+	lastGather time.Time
+}
+
+func (*PostgresqlReplication) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PostgresqlReplication) Init() error {
+	if len(p.Collect) == 0 {
+		p.Collect = availableCollectors
+	}
+	for _, c := range p.Collect {
+		if !slices.Contains(availableCollectors, c) {
+			return fmt.Errorf("invalid collector %q", c)
+		}
+	}
+	if p.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	if p.LongTransactionThreshold < 0 {
+		return errors.New("long_transaction_threshold must not be negative")
+	}
+
+	p.IsPgBouncer = !p.PreparedStatements
+
+	service, err := p.Config.CreateService()
+	if err != nil {
+		return err
+	}
+	p.service = service
+
+	return nil
+}
+
+func (p *PostgresqlReplication) Start(telegraf.Accumulator) error {
+	return p.service.Start()
+}
+
+func (p *PostgresqlReplication) Gather(acc telegraf.Accumulator) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(p.Timeout))
+	defer cancel()
+
+	// Determine the server version on first use as the connection is
+	// established lazily
+	if p.version == 0 {
+		err := p.service.DB.QueryRowContext(ctx, "SELECT current_setting('server_version_num')::int").Scan(&p.version)
+		if err != nil {
+			return fmt.Errorf("querying server version failed: %w", err)
+		}
+		if p.version < 100000 {
+			return fmt.Errorf("unsupported server version %d, at least PostgreSQL 10 is required", p.version)
+		}
+	}
+
+	for _, c := range p.Collect {
+		var err error
+		switch c {
+		case "slots":
+			err = p.gatherSlots(ctx, acc)
+		case "wal":
+			err = p.gatherWAL(ctx, acc)
+		case "vacuum":
+			err = p.gatherVacuum(ctx, acc)
+		case "transactions":
+			err = p.gatherTransactions(ctx, acc)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("collecting %s failed: %w", c, err))
+		}
+	}
+
+	return nil
+}
+
+func (p *PostgresqlReplication) Stop() {
+	p.service.Stop()
+}
+
+func (p *PostgresqlReplication) gatherSlots(ctx context.Context, acc telegraf.Accumulator) error {
+	// The WAL status and safe size are available since PostgreSQL 13
+	walColumns := "NULL::text, NULL::bigint"
+	if p.version >= 130000 {
+		walColumns = "wal_status, safe_wal_size"
+	}
+
+	query := `SELECT slot_name, slot_type, COALESCE(plugin, ''), COALESCE(database, ''), active,
+		pg_wal_lsn_diff(` + currentLSN + `, restart_lsn)::bigint,
+		pg_wal_lsn_diff(` + currentLSN + `, confirmed_flush_lsn)::bigint,
+		` + walColumns + `
+		FROM pg_replication_slots`
+
+	rows, err := p.service.DB.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, slotType, plugin, database string
+		var active bool
+		var retained, confirmedLag, safeSize sql.NullInt64
+		var walStatus sql.NullString
+		if err := rows.Scan(&name, &slotType, &plugin, &database, &active, &retained, &confirmedLag, &walStatus, &safeSize); err != nil {
+			return err
+		}
+
+		tags := map[string]string{
+			"server":    p.service.SanitizedAddress,
+			"slot_name": name,
+			"slot_type": slotType,
+		}
+		if plugin != "" {
+			tags["plugin"] = plugin
+		}
+		if database != "" {
+			tags["db"] = database
+		}
+
+		fields := map[string]interface{}{"active": active}
+		if retained.Valid {
+			fields["retained_bytes"] = retained.Int64
+		}
+		if confirmedLag.Valid {
+			fields["confirmed_flush_lag_bytes"] = confirmedLag.Int64
+		}
+		if walStatus.Valid {
+			fields["wal_status"] = walStatus.String
+		}
+		if safeSize.Valid {
+			fields["safe_wal_size"] = safeSize.Int64
+		}
+		acc.AddFields("postgresql_replication_slot", fields, tags)
+	}
+
+	return rows.Err()
+}
+
+func (p *PostgresqlReplication) gatherWAL(ctx context.Context, acc telegraf.Accumulator) error {
+	query := `SELECT pg_is_in_recovery(),
+		pg_wal_lsn_diff(` + currentLSN + `, '0/0')::bigint,
+		CASE WHEN pg_is_in_recovery()
+			THEN pg_wal_lsn_diff(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn())::bigint END,
+		CASE WHEN pg_is_in_recovery()
+			THEN EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())::float8 END`
+
+	var inRecovery bool
+	var lsn sql.NullInt64
+	var replayLag sql.NullInt64
+	var replayDelay sql.NullFloat64
+	if err := p.service.DB.QueryRowContext(ctx, query).Scan(&inRecovery, &lsn, &replayLag, &replayDelay); err != nil {
+		return err
+	}
+	now := time.Now()
+
+	fields := map[string]interface{}{"in_recovery": inRecovery}
+	if lsn.Valid {
+		fields["lsn"] = lsn.Int64
+
+		// Skip the rate if the position moved backwards, e.g. after a failover
+		if rate, ok := walRate(p.lastLSN, lsn.Int64, p.lastGather, now); ok {
+			fields["bytes_per_second"] = rate
+		}
+		p.lastLSN = lsn.Int64
+		p.lastGather = now
+	}
+	if replayLag.Valid {
+		fields["replay_lag_bytes"] = replayLag.Int64
+	}
+	if replayDelay.Valid {
+		fields["replay_delay"] = replayDelay.Float64
+	}
+
+	acc.AddFields("postgresql_replication_wal", fields, map[string]string{"server": p.service.SanitizedAddress}, now)
+
+	return nil
+}
+
+func (p *PostgresqlReplication) gatherVacuum(ctx context.Context, acc telegraf.Accumulator) error {
+	query := `SELECT v.pid, v.datname, v.relid::regclass::text, v.phase,
+		v.heap_blks_total, v.heap_blks_scanned, v.heap_blks_vacuumed, v.index_vacuum_count,
+		COALESCE(EXTRACT(EPOCH FROM now() - a.query_start), 0)::float8
+		FROM pg_stat_progress_vacuum v
+		LEFT JOIN pg_stat_activity a ON a.pid = v.pid`
+
+	rows, err := p.service.DB.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pid, total, scanned, vacuumed, indexVacuums int64
+		var database, table, phase string
+		var duration float64
+		if err := rows.Scan(&pid, &database, &table, &phase, &total, &scanned, &vacuumed, &indexVacuums, &duration); err != nil {
+			return err
+		}
+
+		tags := map[string]string{
+			"server": p.service.SanitizedAddress,
+			"db":     database,
+			"table":  table,
+		}
+		fields := map[string]interface{}{
+			"pid":                pid,
+			"phase":              phase,
+			"heap_blks_total":    total,
+			"heap_blks_scanned":  scanned,
+			"heap_blks_vacuumed": vacuumed,
+			"index_vacuum_count": indexVacuums,
+			"duration":           duration,
+		}
+		if total > 0 {
+			fields["progress"] = float64(scanned) / float64(total) * 100
+		}
+		acc.AddFields("postgresql_replication_vacuum", fields, tags)
+	}
+
+	return rows.Err()
+}
+
+func (p *PostgresqlReplication) gatherTransactions(ctx context.Context, acc telegraf.Accumulator) error {
+	query := `SELECT datname,
+		COALESCE(max(EXTRACT(EPOCH FROM now() - xact_start)), 0)::float8,
+		count(*) FILTER (WHERE EXTRACT(EPOCH FROM now() - xact_start) > $1),
+		count(*) FILTER (WHERE state LIKE 'idle in transaction%'),
+		COALESCE(max(age(backend_xmin)), 0)::bigint
+		FROM pg_stat_activity
+		WHERE xact_start IS NOT NULL AND datname IS NOT NULL AND pid <> pg_backend_pid()
+		GROUP BY datname`
+
+	threshold := time.Duration(p.LongTransactionThreshold).Seconds()
+	rows, err := p.service.DB.QueryContext(ctx, query, threshold)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var database string
+		var oldest float64
+		var long, idle, xminAge int64
+		if err := rows.Scan(&database, &oldest, &long, &idle, &xminAge); err != nil {
+			return err
+		}
+
+		tags := map[string]string{
+			"server": p.service.SanitizedAddress,
+			"db":     database,
+		}
+		fields := map[string]interface{}{
+			"oldest_xact_age":     oldest,
+			"long_running":        long,
+			"idle_in_transaction": idle,
+			"oldest_xmin_age":     xminAge,
+		}
+		acc.AddFields("postgresql_replication_transactions", fields, tags)
+	}
+
+	return rows.Err()
+}
+
+// walRate computes the WAL generation rate in bytes per second between two
+// gathers. Returns false if no rate can be computed.
+func walRate(lastLSN, lsn int64, lastGather, now time.Time) (float64, bool) {
+	if lastGather.IsZero() || lsn < lastLSN {
+		return 0, false
+	}
+	elapsed := now.Sub(lastGather).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+	return float64(lsn-lastLSN) / elapsed, true
+}
+
+func init() {
+	inputs.Add("postgresql_replication", func() telegraf.Input {
+		return &PostgresqlReplication{
+			Config: postgresql.Config{
+				MaxIdle: 1,
+				MaxOpen: 1,
+			},
+			LongTransactionThreshold: config.Duration(5 * time.Minute),
+			Timeout:                  config.Duration(5 * time.Second),
+			PreparedStatements:       true,
+		}
+	})
+}
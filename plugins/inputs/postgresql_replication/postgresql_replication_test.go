@@ -0,0 +1,107 @@
+package postgresql_replication
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/postgresql"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const servicePort = "5432"
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &PostgresqlReplication{
+		Collect: []string{"slots", "foo"},
+		Timeout: config.Duration(time.Second),
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid collector "foo"`)
+
+	plugin = &PostgresqlReplication{}
+	require.ErrorContains(t, plugin.Init(), "timeout must be positive")
+
+	plugin = &PostgresqlReplication{
+		Timeout:                  config.Duration(time.Second),
+		LongTransactionThreshold: config.Duration(-time.Second),
+	}
+	require.ErrorContains(t, plugin.Init(), "long_transaction_threshold must not be negative")
+}
+
+func TestWALRate(t *testing.T) {
+	now := time.Now()
+
+	// No previous gather
+	_, ok := walRate(0, 1000, time.Time{}, now)
+	require.False(t, ok)
+
+	rate, ok := walRate(1000, 3000, now.Add(-2*time.Second), now)
+	require.True(t, ok)
+	require.InDelta(t, 1000.0, rate, 1e-9)
+
+	// Position moved backwards
+	_, ok = walRate(3000, 1000, now.Add(-2*time.Second), now)
+	require.False(t, ok)
+}
+
+func TestGatherIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	container := testutil.Container{
+		Image:        "postgres:alpine",
+		ExposedPorts: []string{servicePort},
+		Env: map[string]string{
+			"POSTGRES_HOST_AUTH_METHOD": "trust",
+		},
+		Cmd: []string{"postgres", "-c", "wal_level=logical"},
+		WaitingFor: wait.ForAll(
+			wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+			wait.ForListeningPort(servicePort),
+		),
+	}
+	require.NoError(t, container.Start(), "failed to start container")
+	defer container.Terminate()
+
+	addr := fmt.Sprintf(
+		"host=%s port=%s user=postgres sslmode=disable",
+		container.Address,
+		container.Ports[servicePort],
+	)
+
+	plugin := &PostgresqlReplication{
+		Config: postgresql.Config{
+			Address: config.NewSecret([]byte(addr)),
+			MaxIdle: 1,
+			MaxOpen: 1,
+		},
+		LongTransactionThreshold: config.Duration(5 * time.Minute),
+		Timeout:                  config.Duration(5 * time.Second),
+		PreparedStatements:       true,
+		Log:                      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Create a logical replication slot to be reported
+	_, err := plugin.service.DB.Exec("SELECT pg_create_logical_replication_slot('telegraf', 'pgoutput')")
+	require.NoError(t, err)
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	require.True(t, acc.HasTag("postgresql_replication_slot", "slot_name"))
+	require.True(t, acc.HasInt64Field("postgresql_replication_slot", "retained_bytes"))
+	require.True(t, acc.HasInt64Field("postgresql_replication_slot", "confirmed_flush_lag_bytes"))
+	require.True(t, acc.HasInt64Field("postgresql_replication_wal", "lsn"))
+	require.True(t, acc.HasFloatField("postgresql_replication_wal", "bytes_per_second"))
+}
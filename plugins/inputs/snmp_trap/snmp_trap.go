@@ -2,6 +2,7 @@
 package snmp_trap
 
 import (
+	"context"
 	_ "embed"
 	"encoding/hex"
 	"errors"
@@ -10,6 +11,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -32,6 +34,9 @@ type SnmpTrap struct {
 	Version        string          `toml:"version"`
 	Path           []string        `toml:"path"`
 
+	MibReloadInterval config.Duration `toml:"mib_reload_interval"`
+	Fields            []varbindField  `toml:"field"`
+
 	// Settings for version 3 security
 	SecLevel     string        `toml:"sec_level"`
 	SecName      config.Secret `toml:"sec_name"`
@@ -45,10 +50,39 @@ type SnmpTrap struct {
 
 	acc      telegraf.Accumulator
 	listener *gosnmp.TrapListener
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
 
 	transl translator
 }
 
+// varbindField describes how to map a variable binding to a field or tag
+type varbindField struct {
+	Oid        string `toml:"oid"`
+	Name       string `toml:"name"`
+	IsTag      bool   `toml:"is_tag"`
+	Conversion string `toml:"conversion"`
+
+	converter *snmp.Field
+}
+
+// matches checks if the rule applies to the variable binding with the given
+// numeric OID and translated MIB entry. The rule's OID can either be a
+// numeric OID, a name or a name qualified with the MIB. Instance indices of
+// the variable binding are ignored.
+func (f *varbindField) matches(oid string, e snmp.MibEntry) bool {
+	var name string
+	switch {
+	case strings.HasPrefix(f.Oid, "."):
+		name = oid
+	case strings.Contains(f.Oid, "::"):
+		name = e.MibName + "::" + e.OidText
+	default:
+		name = e.OidText
+	}
+	return name == f.Oid || strings.HasPrefix(name, f.Oid+".")
+}
+
 type translator interface {
 	lookup(oid string) (snmp.MibEntry, error)
 }
@@ -71,6 +105,29 @@ func (s *SnmpTrap) Init() error {
 		s.Path = []string{"/usr/share/snmp/mibs"}
 	}
 
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		if f.Oid == "" {
+			return errors.New("field without oid")
+		}
+		switch f.Conversion {
+		case "", "float", "int", "hwaddr", "ipaddr":
+		default:
+			var d int
+			if _, err := fmt.Sscanf(f.Conversion, "float(%d)", &d); err != nil {
+				return fmt.Errorf("invalid conversion %q for field %q", f.Conversion, f.Oid)
+			}
+		}
+		if f.Conversion != "" {
+			f.converter = &snmp.Field{Name: f.Name, Conversion: f.Conversion}
+		}
+	}
+
+	if s.MibReloadInterval > 0 && s.Translator != "gosmi" {
+		s.Log.Warn("Reloading MIBs is only supported with the gosmi translator, ignoring 'mib_reload_interval'")
+		s.MibReloadInterval = 0
+	}
+
 	// Check input parameters
 	switch s.Translator {
 	case "gosmi":
@@ -226,14 +283,49 @@ func (s *SnmpTrap) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("listening failed: %w", err)
 	}
 
+	if s.MibReloadInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.cancel = cancel
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.reloadMibs(ctx)
+		}()
+	}
+
 	return nil
 }
 
+// reloadMibs periodically loads MIB files added to the configured paths
+func (s *SnmpTrap) reloadMibs(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.MibReloadInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := snmp.LoadNewMibsFromPath(s.Path, s.Log, &snmp.GosmiMibLoader{})
+			if err != nil {
+				s.Log.Errorf("Loading new MIBs failed: %v", err)
+			}
+			if count > 0 {
+				s.Log.Infof("Loaded %d new MIB module(s)", count)
+			}
+		}
+	}
+}
+
 func (*SnmpTrap) Gather(telegraf.Accumulator) error {
 	return nil
 }
 
 func (s *SnmpTrap) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
 	s.listener.Close()
 }
 
@@ -339,7 +431,7 @@ func (s *SnmpTrap) handler(packet *gosnmp.SnmpPacket, addr *net.UDPAddr) {
 			return
 		}
 
-		fields[e.OidText] = value
+		s.addVarbind(v, e, value, fields, tags)
 	}
 
 	if packet.Version == gosnmp.Version3 {
@@ -359,6 +451,46 @@ func (s *SnmpTrap) handler(packet *gosnmp.SnmpPacket, addr *net.UDPAddr) {
 	s.acc.AddFields("snmp_trap", fields, tags, tm)
 }
 
+// addVarbind adds the value of the variable binding to the fields or tags
+// according to the first matching field rule, or as field named after the
+// OID if no rule matches
+func (s *SnmpTrap) addVarbind(v gosnmp.SnmpPDU, e snmp.MibEntry, value interface{}, fields map[string]interface{}, tags map[string]string) {
+	var rule *varbindField
+	for i := range s.Fields {
+		if s.Fields[i].matches(v.Name, e) {
+			rule = &s.Fields[i]
+			break
+		}
+	}
+	if rule == nil {
+		fields[e.OidText] = value
+		return
+	}
+
+	name := e.OidText
+	if rule.Name != "" {
+		name = rule.Name
+	}
+
+	if rule.converter != nil {
+		converted, err := rule.converter.Convert(v)
+		if err != nil {
+			s.Log.Errorf("Converting value of OID %s failed: %v", v.Name, err)
+			return
+		}
+		value = converted
+	}
+
+	if rule.IsTag {
+		if b, ok := value.([]byte); ok {
+			value = string(b)
+		}
+		tags[name] = fmt.Sprint(value)
+		return
+	}
+	fields[name] = value
+}
+
 func init() {
 	inputs.Add("snmp_trap", func() telegraf.Input {
 		return &SnmpTrap{
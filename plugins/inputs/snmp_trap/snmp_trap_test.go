@@ -3,6 +3,7 @@ package snmp_trap
 import (
 	"errors"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"testing"
@@ -327,6 +328,76 @@ func TestReceiveTrapV2c(t *testing.T) {
 	}
 }
 
+func TestVarbindFields(t *testing.T) {
+	translator := &testTranslator{entries: []entry{
+		{oid: ".1.3.6.1.6.3.1.1.4.1.0", e: snmp.MibEntry{MibName: "SNMPv2-MIB", OidText: "snmpTrapOID.0"}},
+		{oid: ".1.3.6.1.6.3.1.1.5.3", e: snmp.MibEntry{MibName: "IF-MIB", OidText: "linkDown"}},
+		{oid: ".1.3.6.1.2.1.2.2.1.1.3", e: snmp.MibEntry{MibName: "IF-MIB", OidText: "ifIndex.3"}},
+		{oid: ".1.3.6.1.2.1.2.2.1.2.3", e: snmp.MibEntry{MibName: "IF-MIB", OidText: "ifDescr.3"}},
+		{oid: ".1.3.6.1.2.1.2.2.1.5.3", e: snmp.MibEntry{MibName: "IF-MIB", OidText: "ifSpeed.3"}},
+		{oid: ".1.3.6.1.2.1.2.2.1.8.3", e: snmp.MibEntry{MibName: "IF-MIB", OidText: "ifOperStatus.3"}},
+	}}
+
+	plugin := &SnmpTrap{
+		Version: "2c",
+		Fields: []varbindField{
+			{Oid: "IF-MIB::ifIndex", Name: "if_index", IsTag: true},
+			{Oid: ".1.3.6.1.2.1.2.2.1.2", Name: "interface", IsTag: true},
+			{Oid: "ifSpeed", Name: "speed_kbps", Conversion: "float(3)"},
+		},
+		Log:    testutil.Logger{},
+		transl: translator,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	plugin.acc = &acc
+
+	packet := &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version2c,
+		Community: "public",
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+			{Name: ".1.3.6.1.2.1.2.2.1.1.3", Type: gosnmp.Integer, Value: 3},
+			{Name: ".1.3.6.1.2.1.2.2.1.2.3", Type: gosnmp.OctetString, Value: []byte("eth0")},
+			{Name: ".1.3.6.1.2.1.2.2.1.5.3", Type: gosnmp.Gauge32, Value: uint(1000)},
+			{Name: ".1.3.6.1.2.1.2.2.1.8.3", Type: gosnmp.Integer, Value: 2},
+		},
+	}
+	plugin.handler(packet, &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"snmp_trap",
+			map[string]string{
+				"oid":       ".1.3.6.1.6.3.1.1.5.3",
+				"name":      "linkDown",
+				"mib":       "IF-MIB",
+				"version":   "2c",
+				"source":    "127.0.0.1",
+				"community": "public",
+				"if_index":  "3",
+				"interface": "eth0",
+			},
+			map[string]interface{}{
+				"speed_kbps":     float64(1),
+				"ifOperStatus.3": 2,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestVarbindFieldsInvalidConversion(t *testing.T) {
+	plugin := &SnmpTrap{
+		Fields: []varbindField{{Oid: "ifDescr", Conversion: "displayhint"}},
+		Log:    testutil.Logger{},
+		transl: &testTranslator{},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid conversion")
+}
+
 func TestReceiveTrapV3(t *testing.T) {
 	now := uint32(123123123)
 
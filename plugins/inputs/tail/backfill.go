@@ -0,0 +1,81 @@
+package tail
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// backfillLimiter paces the processing of historic data to the configured
+// rate shared across all files of the plugin instance.
+type backfillLimiter struct {
+	rate  int64
+	bytes bool
+	next  time.Time
+	sync.Mutex
+}
+
+// reserve reserves the given amount of lines or bytes and returns the time to
+// wait before processing them to stay within the rate limit.
+func (l *backfillLimiter) reserve(now time.Time, n int64) time.Duration {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(n * int64(time.Second) / l.rate))
+	return delay
+}
+
+// backfill keeps track of reading the data already present in a file when
+// starting to tail it, i.e. the data between the initial offset (the saved
+// offset when resuming) and the size of the file at the time of opening.
+type backfill struct {
+	remaining int64
+	read      selfstat.Stat
+	tags      map[string]string
+}
+
+func newBackfill(filename string, offset, size int64) *backfill {
+	if offset >= size {
+		return nil
+	}
+
+	tags := map[string]string{"path": filename}
+	selfstat.Register("tail", "backfill_bytes_total", tags).Set(size - offset)
+	read := selfstat.Register("tail", "backfill_bytes_read", tags)
+	read.Set(0)
+
+	return &backfill{
+		remaining: size - offset,
+		read:      read,
+		tags:      tags,
+	}
+}
+
+// active returns true if there is historic data left to read. It is safe to
+// call the function on a nil instance.
+func (b *backfill) active() bool {
+	return b != nil && b.remaining > 0
+}
+
+// consume marks the given number of bytes as read
+func (b *backfill) consume(n int64) {
+	n = min(n, b.remaining)
+	b.remaining -= n
+	b.read.Incr(n)
+}
+
+// close removes the statistics of the backfill from the registry. It is safe
+// to call the function multiple times and on a nil instance.
+func (b *backfill) close() {
+	if b == nil || b.tags == nil {
+		return
+	}
+	selfstat.Unregister("tail", "backfill_bytes_total", b.tags)
+	selfstat.Unregister("tail", "backfill_bytes_read", b.tags)
+	b.tags = nil
+}
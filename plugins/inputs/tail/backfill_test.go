@@ -0,0 +1,133 @@
+package tail
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestBackfillInit(t *testing.T) {
+	plugin := newTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.BackfillRateLimit = 10
+	plugin.BackfillRateUnit = "foo"
+	require.ErrorContains(t, plugin.Init(), "invalid 'backfill_rate_limit_unit' setting")
+
+	plugin = newTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.BackfillRateLimit = 10
+	plugin.Pipe = true
+	require.ErrorContains(t, plugin.Init(), "not supported for pipes")
+
+	plugin = newTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.BackfillRateLimit = 10
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "lines", plugin.BackfillRateUnit)
+	require.NotNil(t, plugin.limiter)
+}
+
+func TestBackfillLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	limiter := &backfillLimiter{rate: 10}
+
+	// The first request is not delayed, subsequent ones are paced
+	require.Zero(t, limiter.reserve(now, 1))
+	require.Equal(t, 100*time.Millisecond, limiter.reserve(now, 1))
+	require.Equal(t, 200*time.Millisecond, limiter.reserve(now, 5))
+	require.Equal(t, 500*time.Millisecond, limiter.reserve(now.Add(200*time.Millisecond), 1))
+
+	// After being idle the limiter does not allow bursts
+	later := now.Add(time.Minute)
+	require.Zero(t, limiter.reserve(later, 1))
+	require.Equal(t, 100*time.Millisecond, limiter.reserve(later, 1))
+}
+
+func TestBackfillProgress(t *testing.T) {
+	require.Nil(t, newBackfill("foo", 100, 100))
+	require.False(t, (*backfill)(nil).active())
+
+	bf := newBackfill("TestBackfillProgress", 10, 100)
+	require.True(t, bf.active())
+	bf.consume(60)
+	require.True(t, bf.active())
+	require.Equal(t, int64(60), bf.read.Get())
+	bf.consume(60)
+	require.False(t, bf.active())
+	require.Equal(t, int64(90), bf.read.Get())
+
+	// Closing removes the statistics and is idempotent
+	require.True(t, hasBackfillStats("TestBackfillProgress"))
+	bf.close()
+	require.False(t, hasBackfillStats("TestBackfillProgress"))
+	bf.close()
+	(*backfill)(nil).close()
+}
+
+func hasBackfillStats(path string) bool {
+	for _, m := range selfstat.Metrics() {
+		if m.Name() != "internal_tail" {
+			continue
+		}
+		if v, found := m.GetTag("path"); found && v == path {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBackfillRateLimit(t *testing.T) {
+	content := strings.Repeat("cpu usage_idle=100\n", 5)
+	tmpfile := filepath.Join(t.TempDir(), "input.txt")
+	require.NoError(t, os.WriteFile(tmpfile, []byte(content), 0600))
+
+	plugin := newTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.InitialReadOffset = "beginning"
+	plugin.BackfillRateLimit = 20
+	plugin.Files = []string{tmpfile}
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	start := time.Now()
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Five lines at 20 lines per second require at least 200ms
+	acc.Wait(5)
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	// The statistics are removed once the backfill is finished
+	require.False(t, hasBackfillStats(tmpfile))
+}
+
+func TestBackfillStatsRemovedOnStop(t *testing.T) {
+	content := strings.Repeat("cpu usage_idle=100\n", 5)
+	tmpfile := filepath.Join(t.TempDir(), "input.txt")
+	require.NoError(t, os.WriteFile(tmpfile, []byte(content), 0600))
+
+	plugin := newTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.InitialReadOffset = "beginning"
+	plugin.BackfillRateLimit = 1
+	plugin.Files = []string{tmpfile}
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	acc.Wait(1)
+	require.True(t, hasBackfillStats(tmpfile))
+
+	// Stopping in the middle of the backfill must not leave stale statistics
+	plugin.Stop()
+	require.False(t, hasBackfillStats(tmpfile))
+}
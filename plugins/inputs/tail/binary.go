@@ -0,0 +1,46 @@
+//go:build !solaris
+
+package tail
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/influxdata/telegraf/plugins/common/encoding"
+)
+
+// Number of bytes at the beginning of a file inspected for binary content,
+// equivalent to the heuristic used by git
+const binarySniffSize = 8000
+
+// isBinaryFile checks if the file appears to contain non-text data, e.g. a
+// core dump accidentally matched by a glob pattern. A file is considered
+// binary if its beginning contains a NUL byte after decoding it using the
+// configured character encoding.
+func (t *Tail) isBinaryFile(filename string) (bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, binarySniffSize)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, err
+	}
+
+	// Use a separate decoder as the decoder of the plugin is used by the
+	// readers of the tailed files
+	dec, err := encoding.NewDecoder(t.CharacterEncoding)
+	if err != nil {
+		return false, err
+	}
+	decoded, err := dec.Bytes(buf[:n])
+	if err != nil {
+		return false, err
+	}
+	return bytes.IndexByte(decoded, 0) >= 0, nil
+}
@@ -0,0 +1,104 @@
+package tail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestIsBinaryFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  []byte
+		encoding string
+		expected bool
+	}{
+		{
+			name:     "text",
+			file:     filepath.Join("testdata", "cpu-utf-8.influx"),
+			expected: false,
+		},
+		{
+			name:     "utf-16 without encoding",
+			file:     filepath.Join("testdata", "cpu-utf-16le.influx"),
+			expected: true,
+		},
+		{
+			name:     "utf-16 with encoding",
+			file:     filepath.Join("testdata", "cpu-utf-16le.influx"),
+			encoding: "utf-16le",
+			expected: false,
+		},
+		{
+			name:     "binary",
+			content:  []byte{0x7f, 'E', 'L', 'F', 0x02, 0x01, 0x01, 0x00, 0x00},
+			expected: true,
+		},
+		{
+			name:     "empty",
+			content:  []byte{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := tt.file
+			if file == "" {
+				file = filepath.Join(t.TempDir(), "input")
+				require.NoError(t, os.WriteFile(file, tt.content, 0600))
+			}
+
+			plugin := newTestTail()
+			plugin.Log = testutil.Logger{}
+			plugin.CharacterEncoding = tt.encoding
+			require.NoError(t, plugin.Init())
+
+			binary, err := plugin.isBinaryFile(file)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, binary)
+		})
+	}
+}
+
+func TestSkipBinaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	textFile := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(textFile, []byte("cpu usage_idle=100\n"), 0600))
+	binaryFile := filepath.Join(dir, "core.log")
+	require.NoError(t, os.WriteFile(binaryFile, []byte("cpu usage_idle=50\n\x00\x00\x00\n"), 0600))
+
+	logger := &testutil.CaptureLogger{}
+	plugin := newTestTail()
+	plugin.Log = logger
+	plugin.InitialReadOffset = "beginning"
+	plugin.Files = []string{filepath.Join(dir, "*.log")}
+	plugin.SkipBinaryFiles = true
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	acc.Wait(1)
+
+	// Gathering again must not repeat the warning
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"path": textFile}, map[string]interface{}{"usage_idle": float64(100)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	warnings := logger.Warnings()
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], binaryFile)
+}
@@ -0,0 +1,106 @@
+package tail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// containerDecoder strips the framing added by container runtimes to each
+// line of a container log and reassembles messages split over multiple lines.
+type containerDecoder struct {
+	format  string
+	partial strings.Builder
+}
+
+type dockerLogEntry struct {
+	Log    string `json:"log"`
+	Stream string `json:"stream"`
+	Time   string `json:"time"`
+}
+
+func newContainerDecoder(format string) (*containerDecoder, error) {
+	switch format {
+	case "":
+		return nil, nil
+	case "cri", "docker":
+	default:
+		return nil, fmt.Errorf("invalid 'container_format' setting %q", format)
+	}
+	return &containerDecoder{format: format}, nil
+}
+
+// process decodes the given line and returns the contained message. If the
+// line only holds a part of a message the returned flag is false and the
+// message is returned with the line completing it.
+func (d *containerDecoder) process(line string) (string, bool, error) {
+	var msg string
+	var complete bool
+	var err error
+	switch d.format {
+	case "cri":
+		msg, complete, err = decodeCRI(line)
+	case "docker":
+		msg, complete, err = decodeDocker(line)
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	if !complete {
+		d.partial.WriteString(msg)
+		return "", false, nil
+	}
+	if d.partial.Len() > 0 {
+		d.partial.WriteString(msg)
+		msg = d.partial.String()
+		d.partial.Reset()
+	}
+	return msg, true, nil
+}
+
+// decodeCRI decodes a line in the CRI log format used by e.g. containerd and
+// CRI-O of the form "<timestamp> <stream> <tags> <message>" where the first
+// tag is either "F" for a full line or "P" for a partial one.
+func decodeCRI(line string) (string, bool, error) {
+	parts := strings.SplitN(line, " ", 4)
+	if len(parts) < 3 {
+		return "", false, errors.New("missing CRI log prefix")
+	}
+	switch parts[1] {
+	case "stdout", "stderr":
+	default:
+		return "", false, fmt.Errorf("invalid CRI log stream %q", parts[1])
+	}
+
+	var msg string
+	if len(parts) == 4 {
+		msg = parts[3]
+	}
+
+	tag, _, _ := strings.Cut(parts[2], ":")
+	switch tag {
+	case "F":
+		return msg, true, nil
+	case "P":
+		return msg, false, nil
+	}
+	return "", false, fmt.Errorf("invalid CRI log tag %q", parts[2])
+}
+
+// decodeDocker decodes a line written by the json-file logging driver of
+// docker. Lines exceeding the driver's buffer are split into multiple entries
+// where only the last one is terminated by a newline.
+func decodeDocker(line string) (string, bool, error) {
+	var entry dockerLogEntry
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return "", false, fmt.Errorf("decoding docker log entry failed: %w", err)
+	}
+
+	msg, complete := strings.CutSuffix(entry.Log, "\n")
+	if complete {
+		msg = strings.TrimSuffix(msg, "\r")
+	}
+	return msg, complete, nil
+}
@@ -0,0 +1,138 @@
+package tail
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerDecoderInvalidFormat(t *testing.T) {
+	_, err := newContainerDecoder("foo")
+	require.ErrorContains(t, err, `invalid 'container_format' setting "foo"`)
+
+	d, err := newContainerDecoder("")
+	require.NoError(t, err)
+	require.Nil(t, d)
+}
+
+func TestContainerDecoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		input    []string
+		expected []string
+	}{
+		{
+			name:   "cri",
+			format: "cri",
+			input: []string{
+				`2024-01-15T10:00:00.123456789Z stdout F 127.0.0.1 - - "GET / HTTP/1.1" 200`,
+				`2024-01-15T10:00:01.123456789Z stderr F error: something failed`,
+				`2024-01-15T10:00:02.123456789Z stdout F `,
+			},
+			expected: []string{
+				`127.0.0.1 - - "GET / HTTP/1.1" 200`,
+				`error: something failed`,
+				``,
+			},
+		},
+		{
+			name:   "cri partial",
+			format: "cri",
+			input: []string{
+				`2024-01-15T10:00:00.123456789Z stdout P first `,
+				`2024-01-15T10:00:00.123456789Z stdout P second `,
+				`2024-01-15T10:00:00.123456789Z stdout F third`,
+				`2024-01-15T10:00:01.123456789Z stdout F:extra single`,
+			},
+			expected: []string{
+				`first second third`,
+				`single`,
+			},
+		},
+		{
+			name:   "docker",
+			format: "docker",
+			input: []string{
+				`{"log":"127.0.0.1 - - \"GET / HTTP/1.1\" 200\n","stream":"stdout","time":"2024-01-15T10:00:00.123456789Z"}`,
+				`{"log":"windows line\r\n","stream":"stderr","time":"2024-01-15T10:00:01.123456789Z"}`,
+			},
+			expected: []string{
+				`127.0.0.1 - - "GET / HTTP/1.1" 200`,
+				`windows line`,
+			},
+		},
+		{
+			name:   "docker partial",
+			format: "docker",
+			input: []string{
+				`{"log":"first ","stream":"stdout","time":"2024-01-15T10:00:00.123456789Z"}`,
+				`{"log":"second\n","stream":"stdout","time":"2024-01-15T10:00:00.123456789Z"}`,
+			},
+			expected: []string{
+				`first second`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newContainerDecoder(tt.format)
+			require.NoError(t, err)
+
+			actual := make([]string, 0, len(tt.expected))
+			for _, line := range tt.input {
+				msg, complete, err := d.process(line)
+				require.NoError(t, err)
+				if complete {
+					actual = append(actual, msg)
+				}
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestContainerDecoderMalformed(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		input    string
+		expected string
+	}{
+		{
+			name:     "cri missing prefix",
+			format:   "cri",
+			input:    "plain log line",
+			expected: "invalid CRI log stream",
+		},
+		{
+			name:     "cri too short",
+			format:   "cri",
+			input:    "foo",
+			expected: "missing CRI log prefix",
+		},
+		{
+			name:     "cri invalid tag",
+			format:   "cri",
+			input:    "2024-01-15T10:00:00.123456789Z stdout X message",
+			expected: `invalid CRI log tag "X"`,
+		},
+		{
+			name:     "docker no json",
+			format:   "docker",
+			input:    "plain log line",
+			expected: "decoding docker log entry failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newContainerDecoder(tt.format)
+			require.NoError(t, err)
+
+			_, _, err = d.process(tt.input)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,334 @@
+//go:build !solaris
+
+package tail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/dimchansky/utfbom"
+	"github.com/influxdata/tail"
+
+	"github.com/influxdata/telegraf"
+)
+
+// offsetTracker determines the offset of a file up to which all lines were
+// processed and the metrics derived from them were delivered. It relies on
+// each line received from the tailer corresponding to the raw bytes of the
+// line followed by a newline.
+type offsetTracker struct {
+	filename string
+
+	sync.Mutex
+
+	// Position of the file at which the tailer started reading after
+	// (re)opening the file, negative if no reopen is pending
+	reset int64
+
+	// End of the last line received
+	position int64
+
+	// Start of the data held back e.g. by the multiline buffer, negative if
+	// no data is held back
+	held int64
+
+	// End of the last line processed without being held back
+	handledPos int64
+
+	// Metric groups in the order of the lines they were derived from
+	pending []*pendingGroup
+
+	// Offset up to which all metrics were delivered
+	committed int64
+
+	// Set if metrics of the file were not delivered
+	stalled bool
+}
+
+// pendingGroup is a group of tracking metrics derived from the lines up to
+// the given end
+type pendingGroup struct {
+	tracker   *offsetTracker
+	end       int64
+	resolved  bool
+	delivered bool
+}
+
+func newOffsetTracker(filename string) *offsetTracker {
+	return &offsetTracker{filename: filename, reset: -1, held: -1}
+}
+
+// opened records the position of the file when the tailer opens the reader
+// for the initial read or after reopening a rotated or truncated file
+func (o *offsetTracker) opened(rd io.Reader) {
+	if o == nil {
+		return
+	}
+
+	seeker, ok := rd.(io.Seeker)
+	if !ok {
+		return
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return
+	}
+
+	o.Lock()
+	o.reset = start
+	o.Unlock()
+}
+
+// skipped accounts for the byte order mark removed after opening the file
+func (o *offsetTracker) skipped(enc utfbom.Encoding) {
+	if o == nil {
+		return
+	}
+
+	var size int64
+	switch enc {
+	case utfbom.UTF8:
+		size = 3
+	case utfbom.UTF16BigEndian, utfbom.UTF16LittleEndian:
+		size = 2
+	case utfbom.UTF32BigEndian, utfbom.UTF32LittleEndian:
+		size = 4
+	default:
+		return
+	}
+
+	o.Lock()
+	if o.reset >= 0 {
+		o.reset += size
+	}
+	o.Unlock()
+}
+
+// read accounts for a line of the given length received from the tailer and
+// returns the start of the line
+func (o *offsetTracker) read(length int) int64 {
+	if o == nil {
+		return 0
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	// Lines of a previously opened file still in flight cannot be related
+	// to the reopened file anymore, so start over
+	if o.reset >= 0 {
+		o.position = o.reset
+		o.handledPos = o.reset
+		o.committed = o.reset
+		o.held = -1
+		o.pending = nil
+		o.stalled = false
+		o.reset = -1
+	}
+
+	start := o.position
+	o.position += int64(length) + 1
+	return start
+}
+
+// hold marks the data starting at the given position as held back
+func (o *offsetTracker) hold(start int64) {
+	if o == nil {
+		return
+	}
+
+	o.Lock()
+	o.held = start
+	o.Unlock()
+}
+
+// release marks the data held back as processed
+func (o *offsetTracker) release() {
+	if o == nil {
+		return
+	}
+
+	o.Lock()
+	o.held = -1
+	o.Unlock()
+}
+
+// handled marks all lines received as processed except for the held back
+// data, e.g. for lines dropped or failing to parse
+func (o *offsetTracker) handled() {
+	if o == nil {
+		return
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	o.handledPos = o.end()
+	if len(o.pending) == 0 {
+		o.committed = o.handledPos
+	}
+}
+
+// emit registers a metric group derived from all lines received except for
+// the held back data
+func (o *offsetTracker) emit() *pendingGroup {
+	if o == nil {
+		return nil
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	o.handledPos = o.end()
+	g := &pendingGroup{tracker: o, end: o.handledPos}
+	o.pending = append(o.pending, g)
+	return g
+}
+
+// end returns the position up to which the received lines are processed
+func (o *offsetTracker) end() int64 {
+	if o.held >= 0 {
+		return o.held
+	}
+	return o.position
+}
+
+// resolve records the delivery outcome of the group and advances the
+// committed offset over all groups delivered in order. It returns true if
+// the advancing stopped at undelivered metrics for the first time.
+func (o *offsetTracker) resolve(g *pendingGroup, delivered bool) bool {
+	o.Lock()
+	defer o.Unlock()
+
+	g.resolved = true
+	g.delivered = delivered
+
+	// Groups of undelivered metrics stay in the queue to keep the offset in
+	// front of their lines until restarting
+	for len(o.pending) > 0 && o.pending[0].resolved && o.pending[0].delivered {
+		o.committed = o.pending[0].end
+		o.pending = o.pending[1:]
+	}
+	if len(o.pending) == 0 {
+		o.committed = o.handledPos
+		return false
+	}
+
+	if o.pending[0].resolved && !o.stalled {
+		o.stalled = true
+		return true
+	}
+	return false
+}
+
+// offset returns the offset up to which all metrics were delivered
+func (o *offsetTracker) offset() int64 {
+	o.Lock()
+	defer o.Unlock()
+	return o.committed
+}
+
+// addMetrics adds the metric group derived from the lines of the tracked
+// file to the accumulator
+func (t *Tail) addMetrics(metrics []telegraf.Metric, offsets *offsetTracker) {
+	if offsets == nil {
+		t.acc.AddTrackingMetricGroup(metrics)
+		return
+	}
+
+	// Hold the lock while adding as empty groups are delivered immediately
+	t.undeliveredMutex.Lock()
+	defer t.undeliveredMutex.Unlock()
+	id := t.acc.AddTrackingMetricGroup(metrics)
+	t.undelivered[id] = offsets.emit()
+}
+
+// onDelivery advances the offset of the file the delivered metrics were
+// derived from
+func (t *Tail) onDelivery(info telegraf.DeliveryInfo) {
+	t.undeliveredMutex.Lock()
+	g, found := t.undelivered[info.ID()]
+	delete(t.undelivered, info.ID())
+	t.undeliveredMutex.Unlock()
+	if !found {
+		return
+	}
+
+	if g.tracker.resolve(g, info.Delivered()) {
+		t.Log.Warnf("Metrics read from %q were not delivered, keeping offset %d to read them again after restarting",
+			g.tracker.filename, g.tracker.offset())
+	}
+}
+
+// offsetOf returns the offset to continue reading the file of the tailer at
+// when resuming
+func (t *Tail) offsetOf(tailer *tail.Tail) (int64, error) {
+	if offsets, found := t.trackers[tailer.Filename]; found {
+		return offsets.offset(), nil
+	}
+	offset, err := tailer.Tell()
+	if err != nil {
+		return 0, err
+	}
+	if limiter, found := t.limiters[tailer.Filename]; found {
+		return limiter.offset(offset)
+	}
+	return offset, nil
+}
+
+// readOffsets restores the offsets written to the offsets file during the
+// previous run
+func (t *Tail) readOffsets() error {
+	data, err := os.ReadFile(t.OffsetsFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("reading offsets file failed: %w", err)
+	}
+
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("decoding offsets file %q failed: %w", t.OffsetsFile, err)
+	}
+	for k, v := range st.Offsets {
+		t.offsets[k] = v
+	}
+	for k, v := range st.Files {
+		t.identities[k] = v
+	}
+	return nil
+}
+
+// recordOffsets records the delivered offsets of all tailed files
+func (t *Tail) recordOffsets() {
+	t.tailersMutex.RLock()
+	defer t.tailersMutex.RUnlock()
+
+	for filename, offsets := range t.trackers {
+		t.offsets[filename] = offsets.offset()
+		t.recordIdentity(filename)
+	}
+}
+
+// writeOffsets atomically replaces the offsets file with the recorded
+// offsets
+func (t *Tail) writeOffsets() error {
+	data, err := json.Marshal(state{Offsets: t.offsets, Files: t.identities})
+	if err != nil {
+		return err
+	}
+
+	tmp := t.OffsetsFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return fmt.Errorf("writing offsets file failed: %w", err)
+	}
+	if err := os.Rename(tmp, t.OffsetsFile); err != nil {
+		return fmt.Errorf("replacing offsets file failed: %w", err)
+	}
+	return nil
+}
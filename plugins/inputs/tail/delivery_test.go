@@ -0,0 +1,168 @@
+//go:build !solaris
+
+package tail
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dimchansky/utfbom"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	o := newOffsetTracker("test.log")
+	o.opened(strings.NewReader(""))
+
+	// Metrics of the first line are pending while the second line fails to
+	// parse and the third line is held back by the multiline buffer
+	require.Equal(t, int64(0), o.read(9))
+	first := o.emit()
+	require.Equal(t, int64(10), o.read(4))
+	o.handled()
+	start := o.read(4)
+	require.Equal(t, int64(15), start)
+	o.hold(start)
+	second := o.emit()
+	require.Equal(t, int64(0), o.offset())
+
+	// Delivering out of order advances the offset only once all previous
+	// metrics are delivered
+	require.False(t, o.resolve(second, true))
+	require.Equal(t, int64(0), o.offset())
+	require.False(t, o.resolve(first, true))
+	require.Equal(t, int64(15), o.offset())
+
+	o.release()
+	o.handled()
+	require.Equal(t, int64(20), o.offset())
+
+	// Undelivered metrics keep the offset in front of their line
+	o.read(9)
+	third := o.emit()
+	o.read(9)
+	fourth := o.emit()
+	require.False(t, o.resolve(fourth, true))
+	require.True(t, o.resolve(third, false))
+	require.Equal(t, int64(20), o.offset())
+	o.read(9)
+	o.handled()
+	require.Equal(t, int64(20), o.offset())
+
+	// Reopening the file starts over at its beginning skipping the BOM
+	o.opened(strings.NewReader(""))
+	o.skipped(utfbom.UTF8)
+	require.Equal(t, int64(3), o.read(4))
+	o.handled()
+	require.Equal(t, int64(8), o.offset())
+}
+
+func TestOffsetCommitDelivery(t *testing.T) {
+	lines := []string{
+		"metric,tag=value foo=1i 1730478201000000000\n",
+		"metric,tag=value foo=2i 1730478211000000000\n",
+		"metric,tag=value foo=3i 1730478221000000000\n",
+	}
+	content := []byte(strings.Join(lines, ""))
+
+	dir := t.TempDir()
+	inputFilename := filepath.Join(dir, "input.influx")
+	require.NoError(t, os.WriteFile(inputFilename, content, 0600))
+	offsetsFilename := filepath.Join(dir, "offsets.json")
+
+	plugin := &Tail{
+		Files:               []string{inputFilename},
+		InitialReadOffset:   "beginning",
+		MaxUndeliveredLines: 1000,
+		OffsetCommit:        "delivery",
+		OffsetsFile:         offsetsFilename,
+		offsets:             make(map[string]int64),
+		Log:                 testutil.Logger{},
+	}
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(lines))
+	}, time.Second, 10*time.Millisecond)
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, len(lines))
+
+	offset := func() int64 {
+		plugin.tailersMutex.RLock()
+		defer plugin.tailersMutex.RUnlock()
+		return plugin.trackers[inputFilename].offset()
+	}
+
+	// The offset only covers the lines with all metrics delivered in order
+	require.Equal(t, int64(0), offset())
+	metrics[0].Accept()
+	metrics[2].Accept()
+	require.Eventually(t, func() bool {
+		return offset() == int64(len(lines[0]))
+	}, time.Second, 10*time.Millisecond)
+	metrics[1].Accept()
+	require.Eventually(t, func() bool {
+		return offset() == int64(len(content))
+	}, time.Second, 10*time.Millisecond)
+
+	// The offsets file is written on gather
+	require.NoError(t, plugin.Gather(&acc))
+	buf, err := os.ReadFile(offsetsFilename)
+	require.NoError(t, err)
+	var written state
+	require.NoError(t, json.Unmarshal(buf, &written))
+	require.Equal(t, map[string]int64{inputFilename: int64(len(content))}, written.Offsets)
+
+	plugin.Stop()
+	actual, ok := plugin.GetState().(state)
+	require.True(t, ok)
+	require.Equal(t, map[string]int64{inputFilename: int64(len(content))}, actual.Offsets)
+}
+
+func TestOffsetCommitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Tail
+		expected string
+	}{
+		{
+			name:     "invalid mode",
+			plugin:   &Tail{OffsetCommit: "foo"},
+			expected: `invalid 'offset_commit' setting "foo"`,
+		},
+		{
+			name:     "pipe",
+			plugin:   &Tail{OffsetCommit: "delivery", Pipe: true},
+			expected: "offset_commit 'delivery' is not supported for pipes",
+		},
+		{
+			name:     "character encoding",
+			plugin:   &Tail{OffsetCommit: "delivery", CharacterEncoding: "utf-16le"},
+			expected: "offset_commit 'delivery' is not supported with character_encoding",
+		},
+		{
+			name:     "offsets file without delivery",
+			plugin:   &Tail{OffsetsFile: "offsets.json"},
+			expected: "offsets_file requires offset_commit 'delivery'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.MaxUndeliveredLines = 1000
+			tt.plugin.Log = testutil.Logger{}
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
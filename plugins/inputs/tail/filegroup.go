@@ -0,0 +1,99 @@
+//go:build !solaris
+
+package tail
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// fileGroup is a set of files sharing the same tags, timezone and patterns,
+// allowing to handle different log sources in a single plugin instance
+type fileGroup struct {
+	Files    []string          `toml:"files"`
+	Tags     map[string]string `toml:"tags"`
+	Timezone string            `toml:"timezone"`
+	Patterns []string          `toml:"patterns"`
+
+	location *time.Location
+}
+
+// timezoneParser is implemented by parsers interpreting timestamps without
+// zone information in a configurable timezone
+type timezoneParser interface {
+	SetTimezone(loc *time.Location)
+}
+
+// patternParser is implemented by parsers matching lines against a
+// configurable set of patterns
+type patternParser interface {
+	SetPatterns(patterns []string) error
+}
+
+func (g *fileGroup) init() error {
+	if len(g.Files) == 0 {
+		return errors.New("no files specified")
+	}
+	if g.Timezone != "" {
+		loc, err := time.LoadLocation(g.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", g.Timezone, err)
+		}
+		g.location = loc
+	}
+	return nil
+}
+
+// newParser creates a parser for the files of the group
+func (g *fileGroup) newParser(fn telegraf.ParserFunc) (telegraf.Parser, error) {
+	parser, err := fn()
+	if err != nil || (g.location == nil && len(g.Patterns) == 0) {
+		return parser, err
+	}
+
+	var p telegraf.Parser = parser
+	if unwrapped, ok := parser.(*models.RunningParser); ok {
+		p = unwrapped.Parser
+	}
+	if g.location != nil {
+		tp, ok := p.(timezoneParser)
+		if !ok {
+			return nil, errors.New("setting the timezone is not supported by the parser")
+		}
+		tp.SetTimezone(g.location)
+	}
+	if len(g.Patterns) > 0 {
+		pp, ok := p.(patternParser)
+		if !ok {
+			return nil, errors.New("setting patterns is not supported by the parser")
+		}
+		if err := pp.SetPatterns(g.Patterns); err != nil {
+			return nil, fmt.Errorf("setting patterns failed: %w", err)
+		}
+	}
+	return parser, nil
+}
+
+// addTags adds the tags of the group to the metrics without overriding
+// existing tags
+func (g *fileGroup) addTags(metrics []telegraf.Metric) {
+	for _, m := range metrics {
+		for k, v := range g.Tags {
+			if !m.HasTag(k) {
+				m.AddTag(k, v)
+			}
+		}
+	}
+}
+
+// groupOf returns the group the given file was matched by
+func (t *Tail) groupOf(file string) *fileGroup {
+	if g, found := t.groupByFile[file]; found {
+		return g
+	}
+	return &fileGroup{}
+}
@@ -0,0 +1,192 @@
+//go:build !solaris
+
+package tail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/grok"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestFileGroups(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.log")
+	api := filepath.Join(dir, "api.log")
+	legacy := filepath.Join(dir, "legacy.log")
+	for _, fn := range []string{plain, api, legacy} {
+		require.NoError(t, os.WriteFile(fn, []byte("2024-01-01 12:00:00 42\n"), 0600))
+	}
+
+	plugin := newTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.InitialReadOffset = "beginning"
+	plugin.Files = []string{plain}
+	plugin.FileGroups = []*fileGroup{
+		{
+			Files: []string{api},
+			Tags:  map[string]string{"service": "api"},
+		},
+		{
+			// The plain file is already part of the files without group
+			Files:    []string{legacy, plain},
+			Tags:     map[string]string{"service": "legacy"},
+			Timezone: "America/New_York",
+		},
+	}
+	plugin.SetParserFunc(func() (telegraf.Parser, error) {
+		parser := &grok.Parser{
+			Measurement: "log",
+			Patterns:    []string{`%{TIMESTAMP_ISO8601:timestamp:ts-"2006-01-02 15:04:05"} %{NUMBER:value:int}`},
+			Log:         testutil.Logger{},
+		}
+		err := parser.Init()
+		return parser, err
+	})
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 3
+	}, 3*time.Second, 100*time.Millisecond)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"log",
+			map[string]string{"path": plain},
+			map[string]interface{}{"value": int64(42)},
+			time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		),
+		metric.New(
+			"log",
+			map[string]string{"path": api, "service": "api"},
+			map[string]interface{}{"value": int64(42)},
+			time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		),
+		metric.New(
+			"log",
+			map[string]string{"path": legacy, "service": "legacy"},
+			map[string]interface{}{"value": int64(42)},
+			time.Date(2024, 1, 1, 17, 0, 0, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestFileGroupsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    *fileGroup
+		expected string
+	}{
+		{
+			name:     "no files",
+			group:    &fileGroup{Tags: map[string]string{"service": "api"}},
+			expected: "file group 1: no files specified",
+		},
+		{
+			name:     "invalid timezone",
+			group:    &fileGroup{Files: []string{"test.log"}, Timezone: "Mars/Olympus_Mons"},
+			expected: `file group 1: invalid timezone "Mars/Olympus_Mons"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newTestTail()
+			plugin.Log = testutil.Logger{}
+			plugin.FileGroups = []*fileGroup{tt.group}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestFileGroupUnsupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    *fileGroup
+		expected string
+	}{
+		{
+			name:     "timezone",
+			group:    &fileGroup{Files: []string{"test.log"}, Timezone: "UTC"},
+			expected: "file group 1: setting the timezone is not supported by the parser",
+		},
+		{
+			name:     "patterns",
+			group:    &fileGroup{Files: []string{"test.log"}, Patterns: []string{"%{NUMBER:value:int}"}},
+			expected: "file group 1: setting patterns is not supported by the parser",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newTestTail()
+			plugin.Log = testutil.Logger{}
+			plugin.FileGroups = []*fileGroup{tt.group}
+			plugin.SetParserFunc(newInfluxParser)
+			require.EqualError(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestFileGroupPatterns(t *testing.T) {
+	dir := t.TempDir()
+	access := filepath.Join(dir, "access.log")
+	worker := filepath.Join(dir, "worker.log")
+	require.NoError(t, os.WriteFile(access, []byte("2024-01-01 12:00:00 42\n"), 0600))
+	require.NoError(t, os.WriteFile(worker, []byte("2024-01-01 12:00:00 WARN disk full\n"), 0600))
+
+	plugin := newTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.InitialReadOffset = "beginning"
+	plugin.Files = []string{access}
+	plugin.FileGroups = []*fileGroup{
+		{
+			Files:    []string{worker},
+			Patterns: []string{`%{TIMESTAMP_ISO8601:timestamp:ts-"2006-01-02 15:04:05"} %{LOGLEVEL:level:tag} %{GREEDYDATA:message}`},
+		},
+	}
+	plugin.SetParserFunc(func() (telegraf.Parser, error) {
+		parser := &grok.Parser{
+			Measurement: "log",
+			Patterns:    []string{`%{TIMESTAMP_ISO8601:timestamp:ts-"2006-01-02 15:04:05"} %{NUMBER:value:int}`},
+			Log:         testutil.Logger{},
+		}
+		err := parser.Init()
+		return parser, err
+	})
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 2
+	}, 3*time.Second, 100*time.Millisecond)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"log",
+			map[string]string{"path": access},
+			map[string]interface{}{"value": int64(42)},
+			time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		),
+		metric.New(
+			"log",
+			map[string]string{"path": worker, "level": "WARN"},
+			map[string]interface{}{"message": "disk full"},
+			time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
@@ -0,0 +1,16 @@
+//go:build !windows && !solaris
+
+package tail
+
+import (
+	"os"
+	"syscall"
+)
+
+// inode returns the inode number of the file or zero if unavailable
+func inode(stat os.FileInfo) uint64 {
+	if sys, ok := stat.Sys().(*syscall.Stat_t); ok {
+		return uint64(sys.Ino) //nolint:unconvert // required on some platforms
+	}
+	return 0
+}
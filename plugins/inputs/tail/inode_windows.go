@@ -0,0 +1,11 @@
+//go:build windows
+
+package tail
+
+import "os"
+
+// inode returns zero as inode numbers are not available on Windows, files
+// are identified by their fingerprint only
+func inode(os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,207 @@
+package tail
+
+import (
+	"io"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Size of the read buffer of the tailer limiting the amount of data passed
+// through but not yet consumed by the tailer
+const tailerBufferSize = 4096
+
+// lineLimitReader passes through at most limit+1 bytes of each line of the
+// underlying reader and discards the remainder of longer lines while reading.
+// This avoids buffering huge lines completely while the additional byte
+// allows detecting that a line was cut. Only complete lines are passed
+// through to prevent the tailer from seeking back in the file for
+// incomplete lines. The discarded bytes are tracked to map the offsets
+// reported by the tailer back to the file.
+type lineLimitReader struct {
+	limit int
+	chunk []byte
+
+	sync.Mutex
+	r    io.Reader
+	file io.Seeker
+
+	// Data not yet passed through with the number of complete bytes and the
+	// length of the incomplete line at the end
+	buf      []byte
+	complete int
+	length   int
+
+	// Number of bytes passed through and the bytes discarded after the
+	// given amount of data kept
+	passed    int64
+	discarded []discardedBytes
+}
+
+// discardedBytes is the number of bytes discarded after the given amount of
+// data was kept
+type discardedBytes struct {
+	pos int64
+	n   int64
+}
+
+func newLineLimitReader(limit int) *lineLimitReader {
+	return &lineLimitReader{limit: limit + 1, chunk: make([]byte, tailerBufferSize)}
+}
+
+// open starts reading the given reader after (re)opening the file. The file
+// is used to determine the offsets and might be nil.
+func (l *lineLimitReader) open(r, file io.Reader) io.Reader {
+	l.Lock()
+	defer l.Unlock()
+
+	l.r = r
+	l.file, _ = file.(io.Seeker)
+	l.buf = l.buf[:0]
+	l.complete = 0
+	l.length = 0
+	l.passed = 0
+	l.discarded = nil
+	return l
+}
+
+func (l *lineLimitReader) Read(p []byte) (int, error) {
+	for {
+		l.Lock()
+		if l.complete > 0 {
+			n := copy(p, l.buf[:l.complete])
+			l.buf = append(l.buf[:0], l.buf[n:]...)
+			l.complete -= n
+			l.passed += int64(n)
+			l.Unlock()
+			return n, nil
+		}
+		l.Unlock()
+
+		// Incomplete lines are held back, so errors such as the end of the
+		// file are only reported if there are no complete lines
+		n, err := l.r.Read(l.chunk)
+		l.process(l.chunk[:n])
+		if err != nil {
+			l.Lock()
+			complete := l.complete
+			l.Unlock()
+			if complete == 0 {
+				return 0, err
+			}
+		}
+	}
+}
+
+// process adds the given data to the buffer discarding the bytes exceeding
+// the limit
+func (l *lineLimitReader) process(data []byte) {
+	l.Lock()
+	defer l.Unlock()
+
+	for _, c := range data {
+		switch {
+		case c == '\n':
+			l.buf = append(l.buf, c)
+			l.complete = len(l.buf)
+			l.length = 0
+		case l.length >= l.limit:
+			pos := l.passed + int64(len(l.buf))
+			if last := len(l.discarded) - 1; last >= 0 && l.discarded[last].pos == pos {
+				l.discarded[last].n++
+			} else {
+				l.discarded = append(l.discarded, discardedBytes{pos: pos, n: 1})
+			}
+		default:
+			l.buf = append(l.buf, c)
+			l.length++
+		}
+	}
+
+	// Bytes discarded before the data buffered by the tailer are irrelevant
+	// for determining offsets
+	var i int
+	for i < len(l.discarded) && l.discarded[i].pos <= l.passed-tailerBufferSize {
+		i++
+	}
+	l.discarded = l.discarded[i:]
+}
+
+// offset maps the offset reported by the tailer to the offset in the file by
+// accounting for the bytes held back and discarded
+func (l *lineLimitReader) offset(tell int64) (int64, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.file == nil {
+		return tell, nil
+	}
+	pos, err := l.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	// The tailer reports the position of the file minus the data passed
+	// through but not yet consumed
+	consumed := l.passed - (pos - tell)
+	offset := tell - int64(len(l.buf))
+	for _, d := range l.discarded {
+		if d.pos > consumed {
+			offset -= d.n
+		}
+	}
+	return offset, nil
+}
+
+// lineLimit handles lines exceeding the maximum length of a file
+type lineLimit struct {
+	max       int
+	drop      bool
+	truncated selfstat.Stat
+	dropped   selfstat.Stat
+	tags      map[string]string
+}
+
+func newLineLimit(filename string, maxBytes int, drop bool) *lineLimit {
+	tags := map[string]string{"path": filename}
+	return &lineLimit{
+		max:       maxBytes,
+		drop:      drop,
+		truncated: selfstat.Register("tail", "lines_truncated", tags),
+		dropped:   selfstat.Register("tail", "lines_dropped", tags),
+		tags:      tags,
+	}
+}
+
+// close removes the statistics of the file once it is no longer tailed. It
+// is safe to call the function on a nil instance.
+func (l *lineLimit) close() {
+	if l == nil || l.tags == nil {
+		return
+	}
+	selfstat.Unregister("tail", "lines_truncated", l.tags)
+	selfstat.Unregister("tail", "lines_dropped", l.tags)
+	l.tags = nil
+}
+
+// apply truncates the given line to the maximum length without splitting
+// multi-byte characters. It returns false if the line should be dropped. It
+// is safe to call the function on a nil instance.
+func (l *lineLimit) apply(text string) (string, bool) {
+	if l == nil || len(text) <= l.max {
+		return text, true
+	}
+
+	if l.drop {
+		l.dropped.Incr(1)
+		return "", false
+	}
+	l.truncated.Incr(1)
+
+	n := l.max
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n], true
+}
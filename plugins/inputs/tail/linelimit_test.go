@@ -0,0 +1,155 @@
+package tail
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestLineLimitReader(t *testing.T) {
+	input := "short\n" + strings.Repeat("x", 100) + "\n\nabcdefgh\n"
+	expected := "short\nxxxxxxx\n\nabcdefg\n"
+
+	r := newLineLimitReader(6).open(strings.NewReader(input), nil)
+	buf, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(buf))
+
+	// Reads returning only discarded bytes must not be reported as empty
+	r = newLineLimitReader(6).open(iotest.OneByteReader(strings.NewReader(input)), nil)
+	buf, err = io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(buf))
+
+	// Incomplete lines are held back
+	r = newLineLimitReader(6).open(strings.NewReader("short\nincomplete"), nil)
+	buf, err = io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "short\n", string(buf))
+}
+
+func TestLineLimitReaderOffset(t *testing.T) {
+	input := "short\n" + strings.Repeat("x", 100) + "\nabc\n" + strings.Repeat("y", 50)
+	file := strings.NewReader(input)
+	limiter := newLineLimitReader(6)
+	r := bufio.NewReader(limiter.open(file, file))
+
+	// Mimic the tailer reporting the offset of the data not yet consumed
+	tell := func() int64 {
+		pos, err := file.Seek(0, io.SeekCurrent)
+		require.NoError(t, err)
+		return pos - int64(r.Buffered())
+	}
+
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "short\n", line)
+	offset, err := limiter.offset(tell())
+	require.NoError(t, err)
+	require.Equal(t, int64(6), offset)
+
+	line, err = r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "xxxxxxx\n", line)
+	offset, err = limiter.offset(tell())
+	require.NoError(t, err)
+	require.Equal(t, int64(107), offset)
+
+	line, err = r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "abc\n", line)
+	offset, err = limiter.offset(tell())
+	require.NoError(t, err)
+	require.Equal(t, int64(111), offset)
+
+	// The incomplete line is not passed through and must be read again
+	_, err = r.ReadString('\n')
+	require.ErrorIs(t, err, io.EOF)
+	offset, err = limiter.offset(tell())
+	require.NoError(t, err)
+	require.Equal(t, int64(111), offset)
+}
+
+func TestLineLimitApply(t *testing.T) {
+	text, keep := (*lineLimit)(nil).apply("foobar")
+	require.True(t, keep)
+	require.Equal(t, "foobar", text)
+
+	limit := newLineLimit("TestLineLimitApply", 5, false)
+	text, keep = limit.apply("short")
+	require.True(t, keep)
+	require.Equal(t, "short", text)
+	text, keep = limit.apply("foobar")
+	require.True(t, keep)
+	require.Equal(t, "fooba", text)
+
+	// Multi-byte characters are not split
+	text, keep = limit.apply("abcdäöü")
+	require.True(t, keep)
+	require.Equal(t, "abcd", text)
+	require.Equal(t, int64(2), limit.truncated.Get())
+
+	limit = newLineLimit("TestLineLimitApplyDrop", 5, true)
+	_, keep = limit.apply("foobar")
+	require.False(t, keep)
+	require.Equal(t, int64(1), limit.dropped.Get())
+	require.Zero(t, limit.truncated.Get())
+
+	// The statistics are removed when closing
+	limit.close()
+	for _, m := range selfstat.Metrics() {
+		require.NotEqual(t, "TestLineLimitApplyDrop", m.Tags()["path"])
+	}
+}
+
+func TestMaxLineInit(t *testing.T) {
+	plugin := newTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.MaxLineAction = "foo"
+	require.ErrorContains(t, plugin.Init(), "invalid 'max_line_action' setting")
+
+	plugin = newTestTail()
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "truncate", plugin.MaxLineAction)
+}
+
+func TestMaxLineBytesDrop(t *testing.T) {
+	content := "cpu usage_idle=100\n" +
+		"cpu,host=" + strings.Repeat("x", 1000) + " usage_idle=50\n" +
+		"cpu usage_idle=42\n"
+	tmpfile := filepath.Join(t.TempDir(), "input.txt")
+	require.NoError(t, os.WriteFile(tmpfile, []byte(content), 0600))
+
+	plugin := newTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.InitialReadOffset = "beginning"
+	plugin.Files = []string{tmpfile}
+	plugin.MaxLineBytes = 100
+	plugin.MaxLineAction = "drop"
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	acc.Wait(2)
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"path": tmpfile}, map[string]interface{}{"usage_idle": float64(100)}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"path": tmpfile}, map[string]interface{}{"usage_idle": float64(42)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -0,0 +1,210 @@
+//go:build !solaris
+
+package tail
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/tail"
+)
+
+// Number of bytes at the start of a file used to fingerprint its content
+const fingerprintSize = 1024
+
+// fileIdentity identifies the file a saved offset belongs to, allowing to
+// detect rotations happening while Telegraf was not running
+type fileIdentity struct {
+	// Path of the file with all symbolic links resolved
+	Path        string `json:"path"`
+	Inode       uint64 `json:"inode,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+	// Number of bytes used for the fingerprint
+	Length int `json:"length"`
+}
+
+// state is the persisted state of the plugin
+type state struct {
+	Offsets map[string]int64         `json:"offsets"`
+	Files   map[string]*fileIdentity `json:"files,omitempty"`
+}
+
+// UnmarshalJSON decodes the state also accepting the former format of a
+// plain path to offset map
+func (s *state) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if v, found := raw["offsets"]; !found || !bytes.HasPrefix(bytes.TrimSpace(v), []byte("{")) {
+		return json.Unmarshal(data, &s.Offsets)
+	}
+
+	type stateAlias state
+	return json.Unmarshal(data, (*stateAlias)(s))
+}
+
+// identify fingerprints the file at the given path following symbolic links
+func identify(path string) (*fileIdentity, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, length, stat, err := fingerprint(resolved, fingerprintSize)
+	if err != nil {
+		return nil, err
+	}
+	return &fileIdentity{
+		Path:        resolved,
+		Inode:       inode(stat),
+		Fingerprint: fingerprint,
+		Length:      length,
+	}, nil
+}
+
+// fingerprint hashes the first size bytes of the given file
+func fingerprint(path string, size int) (string, int, os.FileInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	buf := make([]byte, size)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", 0, nil, err
+	}
+	sum := sha256.Sum256(buf[:n])
+	return hex.EncodeToString(sum[:]), n, stat, nil
+}
+
+// contains checks if the file at the given path is the identified file and
+// holds at least the given number of bytes
+func (id *fileIdentity) contains(path string, offset int64) (bool, os.FileInfo) {
+	fp, n, stat, err := fingerprint(path, id.Length)
+	if err != nil || n != id.Length || fp != id.Fingerprint || stat.Size() < offset {
+		return false, nil
+	}
+	return true, stat
+}
+
+// findRotated searches for the identified file after it was moved away by
+// a rotation. This covers the previous target of a switched symbolic link,
+// files renamed in the same directory and copies created by copytruncate.
+// Candidates with the same inode are preferred over copies.
+func (id *fileIdentity) findRotated(offset int64) string {
+	candidates := []string{id.Path}
+	if entries, err := os.ReadDir(filepath.Dir(id.Path)); err == nil {
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				candidates = append(candidates, filepath.Join(filepath.Dir(id.Path), entry.Name()))
+			}
+		}
+	}
+
+	var copied string
+	for _, candidate := range candidates {
+		ok, stat := id.contains(candidate, offset)
+		if !ok {
+			continue
+		}
+		if id.Inode == 0 || inode(stat) == id.Inode {
+			return candidate
+		}
+		if copied == "" {
+			copied = candidate
+		}
+	}
+	return copied
+}
+
+// recordIdentity stores the identity of the file for detecting rotations on
+// restart
+func (t *Tail) recordIdentity(file string) {
+	id, err := identify(file)
+	if err != nil {
+		t.Log.Debugf("Could not identify %q: %v", file, err)
+		delete(t.identities, file)
+		return
+	}
+	t.identities[file] = id
+}
+
+// savedSeekInfo returns the position to continue reading the given file at
+// if it is the file the offset was saved for. Otherwise the file was
+// rotated and the remainder of the previous file is read before starting
+// at the beginning of the new one.
+func (t *Tail) savedSeekInfo(file string, offset int64) *tail.SeekInfo {
+	id, found := t.identities[file]
+	if !found {
+		// State without identity information, trust the offset
+		return &tail.SeekInfo{Whence: 0, Offset: offset}
+	}
+
+	// Files recreated after renaming the previous one get a new inode while
+	// copytruncate keeps the inode but replaces the content
+	resolved, err := filepath.EvalSymlinks(file)
+	if err == nil && resolved == id.Path {
+		if ok, stat := id.contains(file, offset); ok && (id.Inode == 0 || inode(stat) == id.Inode) {
+			return &tail.SeekInfo{Whence: 0, Offset: offset}
+		}
+	}
+
+	// The file was rotated, make sure we neither use the offset nor the
+	// identity again for this file
+	delete(t.offsets, file)
+	delete(t.identities, file)
+
+	if rotated := id.findRotated(offset); rotated != "" {
+		t.Log.Debugf("File %q was rotated, reading remainder of %q from offset %d", file, rotated, offset)
+		t.drain(rotated, offset, t.groupOf(file))
+	} else {
+		t.Log.Debugf("File %q was rotated and the previous file is gone", file)
+	}
+	return &tail.SeekInfo{Whence: 0, Offset: 0}
+}
+
+// drain reads the given file from the offset to its end without following
+func (t *Tail) drain(file string, offset int64, group *fileGroup) {
+	parser, err := group.newParser(t.parserFunc)
+	if err != nil {
+		t.Log.Errorf("Creating parser: %v", err)
+		return
+	}
+
+	tailer, err := tail.TailFile(file,
+		tail.Config{
+			Location:  &tail.SeekInfo{Whence: 0, Offset: offset},
+			MustExist: true,
+			Logger:    tail.DiscardingLogger,
+			OpenReaderFunc: func(rd io.Reader) io.Reader {
+				return t.decoder.Reader(rd)
+			},
+		})
+	if err != nil {
+		t.Log.Errorf("Reading remainder of rotated file %q failed: %v", file, err)
+		return
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.receiver(parser, tailer, nil, group, nil, nil)
+		if err := tailer.Err(); err != nil {
+			t.Log.Errorf("Reading remainder of rotated file %q failed: %v", file, err)
+		}
+		tailer.Cleanup()
+	}()
+}
@@ -0,0 +1,178 @@
+//go:build !solaris
+
+package tail
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestRotationDetection(t *testing.T) {
+	tests := []struct {
+		name    string
+		symlink bool
+		rotate  func(t *testing.T, dir, file string)
+	}{
+		{
+			name:   "unrotated",
+			rotate: func(*testing.T, string, string) {},
+		},
+		{
+			name: "copytruncate",
+			rotate: func(t *testing.T, _, file string) {
+				content, err := os.ReadFile(file)
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(file+".1", content, 0600))
+				require.NoError(t, os.Truncate(file, 0))
+			},
+		},
+		{
+			name: "rename and create",
+			rotate: func(t *testing.T, _, file string) {
+				require.NoError(t, os.Rename(file, file+".1"))
+				require.NoError(t, os.WriteFile(file, nil, 0600))
+			},
+		},
+		{
+			name:    "symlink switch",
+			symlink: true,
+			rotate: func(t *testing.T, dir, file string) {
+				target := filepath.Join(dir, "b.log")
+				require.NoError(t, os.WriteFile(target, nil, 0600))
+				require.NoError(t, os.Remove(file))
+				require.NoError(t, os.Symlink(target, file))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.symlink && runtime.GOOS == "windows" {
+				t.Skip("Skipping test on Windows due to symlink permissions")
+			}
+
+			dir := t.TempDir()
+			file := filepath.Join(dir, "current.log")
+			if tt.symlink {
+				target := filepath.Join(dir, "a.log")
+				require.NoError(t, os.WriteFile(target, nil, 0600))
+				require.NoError(t, os.Symlink(target, file))
+			}
+			appendLine(t, file, "test value=1i 1730478201000000000\n")
+			appendLine(t, file, "test value=2i 1730478202000000000\n")
+
+			// Read the existing lines and persist the state as the agent
+			// does on shutdown
+			actual, st := runTail(t, file, nil, 2)
+			require.Len(t, actual, 2)
+			buf, err := json.Marshal(st)
+			require.NoError(t, err)
+
+			// Simulate lines written and the file being rotated while Telegraf
+			// is stopped
+			appendLine(t, file, "test value=3i 1730478203000000000\n")
+			tt.rotate(t, dir, file)
+			appendLine(t, file, "test value=4i 1730478204000000000\n")
+
+			// Restart from the persisted state, all lines after the offset
+			// must be read exactly once
+			var restored state
+			require.NoError(t, json.Unmarshal(buf, &restored))
+			actual, _ = runTail(t, file, restored, 2)
+
+			expected := []telegraf.Metric{
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(3)}, time.Unix(1730478203, 0)),
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(4)}, time.Unix(1730478204, 0)),
+			}
+			testutil.RequireMetricsEqual(t, expected, actual, testutil.SortMetrics())
+		})
+	}
+}
+
+func TestRotationRotatedFileGone(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "current.log")
+	appendLine(t, file, "test value=1i 1730478201000000000\n")
+	appendLine(t, file, "test value=2i 1730478202000000000\n")
+
+	_, st := runTail(t, file, nil, 2)
+
+	// Replace the file by a longer one not sharing the content
+	require.NoError(t, os.Remove(file))
+	appendLine(t, file, "test value=30i 1730478203000000000\n")
+	appendLine(t, file, "test value=40i 1730478204000000000\n")
+	appendLine(t, file, "test value=50i 1730478205000000000\n")
+
+	// All lines of the new file must be read instead of continuing at the
+	// offset of the removed one
+	actual, _ := runTail(t, file, st, 3)
+	require.Len(t, actual, 3)
+}
+
+func TestStateLegacyFormat(t *testing.T) {
+	var st state
+	require.NoError(t, json.Unmarshal([]byte(`{"/var/log/test.log": 42}`), &st))
+	require.Equal(t, map[string]int64{"/var/log/test.log": 42}, st.Offsets)
+	require.Empty(t, st.Files)
+
+	expected := state{
+		Offsets: map[string]int64{"/var/log/test.log": 42},
+		Files: map[string]*fileIdentity{
+			"/var/log/test.log": {Path: "/var/log/test.log", Inode: 7, Fingerprint: "abc", Length: 3},
+		},
+	}
+	buf, err := json.Marshal(expected)
+	require.NoError(t, err)
+	st = state{}
+	require.NoError(t, json.Unmarshal(buf, &st))
+	require.Equal(t, expected, st)
+}
+
+func appendLine(t *testing.T, file, line string) {
+	t.Helper()
+
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(line)
+	require.NoError(t, err)
+}
+
+// runTail runs the plugin on the given file with the given state until the
+// expected number of metrics is received and returns the metrics and state
+func runTail(t *testing.T, file string, st interface{}, n int) ([]telegraf.Metric, state) {
+	t.Helper()
+
+	plugin := &Tail{
+		Files:               []string{file},
+		InitialReadOffset:   "saved-or-beginning",
+		MaxUndeliveredLines: 1000,
+		PathTag:             "",
+		Log:                 testutil.Logger{},
+	}
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+	if st != nil {
+		require.NoError(t, plugin.SetState(st))
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	require.Eventuallyf(t, func() bool {
+		return acc.NMetrics() >= uint64(n)
+	}, 3*time.Second, 50*time.Millisecond, "Expected %d metrics found %d", n, acc.NMetrics())
+	plugin.Stop()
+
+	return acc.GetTelegrafMetrics(), plugin.GetState().(state)
+}
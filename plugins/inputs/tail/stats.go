@@ -0,0 +1,137 @@
+//go:build !solaris
+
+package tail
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/influxdata/tail"
+
+	"github.com/influxdata/telegraf"
+)
+
+// fileStats holds the processing statistics of a tailed file
+type fileStats struct {
+	mu sync.Mutex
+
+	// Number of lines processed and the time of the last parsed line
+	lines     uint64
+	lastParse time.Time
+
+	// Number of rotations detected and the file seen at the previous gather
+	rotations uint64
+	inode     uint64
+	size      int64
+
+	// Lines processed and time of the previous gather for computing the rate
+	lastLines  uint64
+	lastGather time.Time
+}
+
+// processed records a processed line and whether it was parsed successfully
+func (s *fileStats) processed(parsed bool) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lines++
+	if parsed {
+		s.lastParse = time.Now()
+	}
+}
+
+// fileStatsFor returns the statistics of the given file creating them if
+// necessary. Returns nil if collecting statistics is disabled.
+func (t *Tail) fileStatsFor(file string) *fileStats {
+	if !t.FileStats {
+		return nil
+	}
+
+	t.statsMutex.Lock()
+	defer t.statsMutex.Unlock()
+
+	stats, found := t.stats[file]
+	if !found {
+		stats = &fileStats{}
+		if stat, err := os.Stat(file); err == nil {
+			stats.inode = inode(stat)
+			stats.size = stat.Size()
+		}
+		t.stats[file] = stats
+	}
+	return stats
+}
+
+// gatherFileStats adds a metric with the processing statistics for each file
+// currently tailed
+func (t *Tail) gatherFileStats(acc telegraf.Accumulator) {
+	t.tailersMutex.RLock()
+	tailers := make(map[string]*tail.Tail, len(t.tailers))
+	for file, tailer := range t.tailers {
+		tailers[file] = tailer
+	}
+	t.tailersMutex.RUnlock()
+
+	now := time.Now()
+	for file, tailer := range tailers {
+		stats := t.fileStatsFor(file)
+		fields := stats.update(file, now)
+
+		if !t.Pipe {
+			if offset, err := tailer.Tell(); err == nil {
+				if stat, err := os.Stat(file); err == nil {
+					fields["bytes_behind"] = max(stat.Size()-offset, 0)
+				}
+			}
+		}
+
+		acc.AddFields("tail_file", fields, map[string]string{"path": file}, now)
+	}
+
+	// Remove the statistics of files not tailed anymore
+	t.statsMutex.Lock()
+	for file := range t.stats {
+		if _, found := tailers[file]; !found {
+			delete(t.stats, file)
+		}
+	}
+	t.statsMutex.Unlock()
+}
+
+// update detects rotations of the given file since the last gather and
+// returns the fields of the statistics
+func (s *fileStats) update(file string, now time.Time) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A file replaced by a new one or truncated counts as rotated
+	if stat, err := os.Stat(file); err == nil {
+		if ino := inode(stat); ino != s.inode || stat.Size() < s.size {
+			s.rotations++
+		}
+		s.inode = inode(stat)
+		s.size = stat.Size()
+	}
+
+	fields := map[string]interface{}{
+		"lines":     s.lines,
+		"rotations": s.rotations,
+	}
+	if !s.lastGather.IsZero() {
+		if elapsed := now.Sub(s.lastGather).Seconds(); elapsed > 0 {
+			fields["lines_per_second"] = float64(s.lines-s.lastLines) / elapsed
+		}
+	}
+	if !s.lastParse.IsZero() {
+		fields["last_parse_time"] = s.lastParse.UnixNano()
+	}
+	s.lastLines = s.lines
+	s.lastGather = now
+
+	return fields
+}
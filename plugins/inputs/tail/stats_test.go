@@ -0,0 +1,110 @@
+//go:build !solaris
+
+package tail
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestFileStatsUpdate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.log")
+	require.NoError(t, os.WriteFile(file, []byte("0123456789\n"), 0600))
+
+	plugin := &Tail{FileStats: true, stats: make(map[string]*fileStats)}
+	stats := plugin.fileStatsFor(file)
+	require.Same(t, stats, plugin.fileStatsFor(file))
+
+	stats.processed(true)
+	stats.processed(true)
+	stats.processed(false)
+
+	now := time.Now()
+	fields := stats.update(file, now)
+	require.Equal(t, uint64(3), fields["lines"])
+	require.Equal(t, uint64(0), fields["rotations"])
+	require.Contains(t, fields, "last_parse_time")
+	require.NotContains(t, fields, "lines_per_second")
+
+	// Truncating the file counts as rotation
+	require.NoError(t, os.WriteFile(file, []byte("01234\n"), 0600))
+	stats.processed(true)
+	stats.processed(true)
+	fields = stats.update(file, now.Add(2*time.Second))
+	require.Equal(t, uint64(5), fields["lines"])
+	require.Equal(t, uint64(1), fields["rotations"])
+	require.InDelta(t, 1.0, fields["lines_per_second"], testutil.DefaultDelta)
+
+	// Growing files are not rotated
+	require.NoError(t, os.WriteFile(file, []byte("0123456789\n0123456789\n"), 0600))
+	fields = stats.update(file, now.Add(3*time.Second))
+	require.Equal(t, uint64(1), fields["rotations"])
+	require.InDelta(t, 0.0, fields["lines_per_second"], testutil.DefaultDelta)
+
+	// Replacing the file counts as rotation if inodes are available
+	if runtime.GOOS == "windows" {
+		return
+	}
+	require.NoError(t, os.Rename(file, file+".1"))
+	require.NoError(t, os.WriteFile(file, []byte("0123456789\n0123456789\n0123456789\n"), 0600))
+	fields = stats.update(file, now.Add(4*time.Second))
+	require.Equal(t, uint64(2), fields["rotations"])
+}
+
+func TestFileStatsGather(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.log")
+	require.NoError(t, os.WriteFile(file, []byte("cpu value=1\ncpu value=\ncpu value=3\n"), 0600))
+
+	plugin := newTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.InitialReadOffset = "beginning"
+	plugin.Files = []string{file}
+	plugin.FileStats = true
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Wait for all lines to be processed
+	require.Eventually(t, func() bool {
+		acc.ClearMetrics()
+		if err := plugin.Gather(&acc); err != nil {
+			return false
+		}
+		for _, m := range acc.GetTelegrafMetrics() {
+			if m.Name() != "tail_file" {
+				continue
+			}
+			lines, _ := m.GetField("lines")
+			behind, _ := m.GetField("bytes_behind")
+			return lines == uint64(3) && behind == int64(0)
+		}
+		return false
+	}, 3*time.Second, 50*time.Millisecond)
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+
+	var found bool
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() != "tail_file" {
+			continue
+		}
+		found = true
+		require.Equal(t, map[string]string{"path": file}, m.Tags())
+		require.True(t, m.HasField("last_parse_time"))
+		require.True(t, m.HasField("lines_per_second"))
+		rotations, _ := m.GetField("rotations")
+		require.Equal(t, uint64(0), rotations)
+	}
+	require.True(t, found)
+}
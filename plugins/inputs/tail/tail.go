@@ -20,6 +20,7 @@ import (
 	"github.com/pborman/ansi"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/globpath"
 	"github.com/influxdata/telegraf/plugins/common/encoding"
@@ -34,6 +35,7 @@ var (
 	once sync.Once
 
 	offsets      = make(map[string]int64)
+	identities   = make(map[string]*fileIdentity)
 	offsetsMutex = new(sync.Mutex)
 )
 
@@ -44,8 +46,20 @@ type Tail struct {
 	Pipe                bool     `toml:"pipe"`
 	WatchMethod         string   `toml:"watch_method"`
 	MaxUndeliveredLines int      `toml:"max_undelivered_lines"`
+	OffsetCommit        string   `toml:"offset_commit"`
+	OffsetsFile         string   `toml:"offsets_file"`
 	CharacterEncoding   string   `toml:"character_encoding"`
 	PathTag             string   `toml:"path_tag"`
+	ContainerFormat     string   `toml:"container_format"`
+	BackfillRateLimit   int64    `toml:"backfill_rate_limit"`
+	BackfillRateUnit    string   `toml:"backfill_rate_limit_unit"`
+	FileStats           bool     `toml:"file_stats"`
+
+	MaxLineBytes    config.Size `toml:"max_line_bytes"`
+	MaxLineAction   string      `toml:"max_line_action"`
+	SkipBinaryFiles bool        `toml:"skip_binary_files"`
+
+	FileGroups []*fileGroup `toml:"file_group"`
 
 	Filters      []string `toml:"filters"`
 	filterColors bool
@@ -54,11 +68,22 @@ type Tail struct {
 	tailers      map[string]*tail.Tail
 	tailersMutex sync.RWMutex
 	offsets      map[string]int64
+	identities   map[string]*fileIdentity
 	parserFunc   telegraf.ParserFunc
+	groupByFile  map[string]*fileGroup
 	wg           sync.WaitGroup
 
 	acc telegraf.TrackingAccumulator
 
+	// Offsets of the lines with delivered metrics per tailed file and the
+	// metric groups not yet delivered
+	trackers         map[string]*offsetTracker
+	undelivered      map[telegraf.TrackingID]*pendingGroup
+	undeliveredMutex sync.Mutex
+
+	// Readers limiting the line length per tailed file
+	limiters map[string]*lineLimitReader
+
 	MultilineConfig multilineConfig `toml:"multiline"`
 	multiline       *multiline
 
@@ -66,8 +91,13 @@ type Tail struct {
 	cancel  context.CancelFunc
 	sem     semaphore
 	decoder *encoding.Decoder
+	limiter *backfillLimiter
 
 	nomatch map[string]bool
+	binary  map[string]bool
+
+	stats      map[string]*fileStats
+	statsMutex sync.Mutex
 }
 
 type empty struct{}
@@ -105,6 +135,78 @@ func (t *Tail) Init() error {
 	}
 	t.sem = make(semaphore, t.MaxUndeliveredLines)
 
+	// Delivery based offsets rely on the lines matching the raw bytes of the
+	// file
+	switch t.OffsetCommit {
+	case "":
+		t.OffsetCommit = "read"
+	case "read":
+	case "delivery":
+		switch {
+		case t.Pipe:
+			return errors.New("offset_commit 'delivery' is not supported for pipes")
+		case t.CharacterEncoding != "":
+			return errors.New("offset_commit 'delivery' is not supported with character_encoding")
+		case t.MaxLineBytes > 0:
+			return errors.New("offset_commit 'delivery' is not supported with max_line_bytes")
+		case t.ContainerFormat != "":
+			return errors.New("offset_commit 'delivery' is not supported with container_format")
+		}
+	default:
+		return fmt.Errorf("invalid 'offset_commit' setting %q", t.OffsetCommit)
+	}
+	if t.OffsetsFile != "" && t.OffsetCommit != "delivery" {
+		return errors.New("offsets_file requires offset_commit 'delivery'")
+	}
+
+	if _, err := newContainerDecoder(t.ContainerFormat); err != nil {
+		return err
+	}
+
+	if t.BackfillRateLimit < 0 {
+		return errors.New("backfill_rate_limit must not be negative")
+	}
+	switch t.BackfillRateUnit {
+	case "", "lines":
+		t.BackfillRateUnit = "lines"
+	case "bytes":
+	default:
+		return fmt.Errorf("invalid 'backfill_rate_limit_unit' setting %q", t.BackfillRateUnit)
+	}
+	if t.BackfillRateLimit > 0 {
+		if t.Pipe {
+			return errors.New("backfill_rate_limit is not supported for pipes")
+		}
+		t.limiter = &backfillLimiter{
+			rate:  t.BackfillRateLimit,
+			bytes: t.BackfillRateUnit == "bytes",
+		}
+	}
+
+	if t.MaxLineBytes < 0 {
+		return errors.New("max_line_bytes must not be negative")
+	}
+	switch t.MaxLineAction {
+	case "":
+		t.MaxLineAction = "truncate"
+	case "truncate", "drop":
+	default:
+		return fmt.Errorf("invalid 'max_line_action' setting %q", t.MaxLineAction)
+	}
+
+	for i, group := range t.FileGroups {
+		if err := group.init(); err != nil {
+			return fmt.Errorf("file group %d: %w", i+1, err)
+		}
+		// Check the parser supports the settings of the group to fail early
+		// instead of when starting to tail the files
+		if t.parserFunc != nil {
+			if _, err := group.newParser(t.parserFunc); err != nil {
+				return fmt.Errorf("file group %d: %w", i+1, err)
+			}
+		}
+	}
+
 	for _, filter := range t.Filters {
 		if filter == "ansi_color" {
 			t.filterColors = true
@@ -113,6 +215,7 @@ func (t *Tail) Init() error {
 
 	// init offsets
 	t.offsets = make(map[string]int64)
+	t.identities = make(map[string]*fileIdentity)
 
 	dec, err := encoding.NewDecoder(t.CharacterEncoding)
 	if err != nil {
@@ -124,6 +227,11 @@ func (t *Tail) Init() error {
 	// matching files to warn about potential permission issues only once.
 	t.nomatch = make(map[string]bool)
 
+	// Keep track of the files skipped due to binary content to warn only once
+	t.binary = make(map[string]bool)
+
+	t.stats = make(map[string]*fileStats)
+
 	return nil
 }
 
@@ -139,7 +247,8 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 			select {
 			case <-t.ctx.Done():
 				return
-			case <-t.acc.Delivered():
+			case info := <-t.acc.Delivered():
+				t.onDelivery(info)
 				<-t.sem
 			}
 		}
@@ -153,6 +262,16 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 	}
 
 	t.tailers = make(map[string]*tail.Tail)
+	t.trackers = make(map[string]*offsetTracker)
+	t.limiters = make(map[string]*lineLimitReader)
+	t.undelivered = make(map[telegraf.TrackingID]*pendingGroup)
+
+	// The offsets file is more recent than the state in case of a crash
+	if t.OffsetsFile != "" {
+		if err := t.readOffsets(); err != nil {
+			return err
+		}
+	}
 
 	err = t.tailNewFiles()
 	if err != nil {
@@ -162,6 +281,7 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 	// assumption that once Start is called, all parallel plugins have already been initialized
 	offsetsMutex.Lock()
 	offsets = make(map[string]int64)
+	identities = make(map[string]*fileIdentity)
 	offsetsMutex.Unlock()
 
 	return err
@@ -182,13 +302,13 @@ func (t *Tail) getSeekInfo(file string) (*tail.SeekInfo, error) {
 	case "", "saved-or-end":
 		if offset, ok := t.offsets[file]; ok {
 			t.Log.Debugf("Using offset %d for %q", offset, file)
-			return &tail.SeekInfo{Whence: 0, Offset: offset}, nil
+			return t.savedSeekInfo(file, offset), nil
 		}
 		return &tail.SeekInfo{Whence: 2, Offset: 0}, nil
 	case "saved-or-beginning":
 		if offset, ok := t.offsets[file]; ok {
 			t.Log.Debugf("Using offset %d for %q", offset, file)
-			return &tail.SeekInfo{Whence: 0, Offset: offset}, nil
+			return t.savedSeekInfo(file, offset), nil
 		}
 		return &tail.SeekInfo{Whence: 0, Offset: 0}, nil
 	default:
@@ -197,22 +317,44 @@ func (t *Tail) getSeekInfo(file string) (*tail.SeekInfo, error) {
 }
 
 func (t *Tail) GetState() interface{} {
-	return t.offsets
+	return state{Offsets: t.offsets, Files: t.identities}
 }
 
-func (t *Tail) SetState(state interface{}) error {
-	offsetsState, ok := state.(map[string]int64)
-	if !ok {
-		return errors.New("state has to be of type 'map[string]int64'")
-	}
-	for k, v := range offsetsState {
-		t.offsets[k] = v
+func (t *Tail) SetState(s interface{}) error {
+	switch st := s.(type) {
+	case state:
+		for k, v := range st.Offsets {
+			t.offsets[k] = v
+		}
+		for k, v := range st.Files {
+			t.identities[k] = v
+		}
+	case map[string]int64:
+		for k, v := range st {
+			t.offsets[k] = v
+		}
+	default:
+		return fmt.Errorf("invalid state type %T", s)
 	}
 	return nil
 }
 
-func (t *Tail) Gather(_ telegraf.Accumulator) error {
-	return t.tailNewFiles()
+func (t *Tail) Gather(acc telegraf.Accumulator) error {
+	if err := t.tailNewFiles(); err != nil {
+		return err
+	}
+
+	if t.FileStats {
+		t.gatherFileStats(acc)
+	}
+
+	if t.OffsetsFile != "" {
+		t.recordOffsets()
+		if err := t.writeOffsets(); err != nil {
+			acc.AddError(err)
+		}
+	}
+	return nil
 }
 
 func (t *Tail) Stop() {
@@ -222,10 +364,11 @@ func (t *Tail) Stop() {
 	for filename, tailer := range t.tailers {
 		if !t.Pipe {
 			// store offset for resume
-			offset, err := tailer.Tell()
+			offset, err := t.offsetOf(tailer)
 			if err == nil {
 				t.Log.Debugf("Recording offset %d for %q", offset, tailer.Filename)
 				t.offsets[tailer.Filename] = offset
+				t.recordIdentity(tailer.Filename)
 			} else {
 				t.Log.Errorf("Recording offset for %q: %v", tailer.Filename, err)
 			}
@@ -237,16 +380,27 @@ func (t *Tail) Stop() {
 
 		// Explicitly delete the tailer from the map to avoid memory leaks
 		delete(t.tailers, filename)
+		delete(t.trackers, filename)
+		delete(t.limiters, filename)
 	}
 
 	t.cancel()
 	t.wg.Wait()
 
+	if t.OffsetsFile != "" {
+		if err := t.writeOffsets(); err != nil {
+			t.Log.Errorf("Persisting offsets: %v", err)
+		}
+	}
+
 	// persist offsets
 	offsetsMutex.Lock()
 	for k, v := range t.offsets {
 		offsets[k] = v
 	}
+	for k, v := range t.identities {
+		identities[k] = v
+	}
 	offsetsMutex.Unlock()
 }
 
@@ -256,11 +410,29 @@ func (t *Tail) tailNewFiles() error {
 		poll = true
 	}
 
-	// Track files that we're currently processing
+	// Track files that we're currently processing and the group matching them
 	currentFiles := make(map[string]bool)
+	t.groupByFile = make(map[string]*fileGroup)
+
+	// Files not belonging to any group are handled as a group without any
+	// settings. Files matched by multiple groups belong to the first group.
+	groups := append([]*fileGroup{{Files: t.Files}}, t.FileGroups...)
 
 	// Create a "tailer" for each file
-	for _, filepath := range t.Files {
+	for _, group := range groups {
+		if err := t.tailGroupFiles(group, poll, currentFiles); err != nil {
+			return err
+		}
+	}
+
+	// Clean up tailers for files that are no longer being monitored
+	return t.cleanupUnusedTailers(currentFiles)
+}
+
+// tailGroupFiles creates a tailer for each file of the group not tailed yet
+// and adds the files to the currently processed ones
+func (t *Tail) tailGroupFiles(group *fileGroup, poll bool, currentFiles map[string]bool) error {
+	for _, filepath := range group.Files {
 		g, err := globpath.Compile(filepath)
 		if err != nil {
 			t.Log.Errorf("Glob %q failed to compile: %v", filepath, err)
@@ -302,8 +474,13 @@ func (t *Tail) tailNewFiles() error {
 		}
 
 		for _, file := range matches {
+			if currentFiles[file] {
+				continue
+			}
+
 			// Mark this file as currently being processed
 			currentFiles[file] = true
+			t.groupByFile[file] = group
 
 			// Check if we're already tailing this file
 			t.tailersMutex.RLock()
@@ -315,11 +492,36 @@ func (t *Tail) tailNewFiles() error {
 				continue
 			}
 
+			// Reading the file to detect binary content would consume the
+			// data of pipes
+			if t.SkipBinaryFiles && !t.Pipe {
+				binary, err := t.isBinaryFile(file)
+				if err != nil {
+					t.Log.Debugf("Cannot check %q for binary content: %v", file, err)
+				} else if binary {
+					if !t.binary[file] {
+						t.Log.Warnf("Skipping %q as it appears to contain binary data", file)
+						t.binary[file] = true
+					}
+					continue
+				}
+				delete(t.binary, file)
+			}
+
 			seek, err := t.getSeekInfo(file)
 			if err != nil {
 				return err
 			}
 
+			var offsets *offsetTracker
+			if t.OffsetCommit == "delivery" {
+				offsets = newOffsetTracker(file)
+			}
+			var limiter *lineLimitReader
+			if t.MaxLineBytes > 0 {
+				limiter = newLineLimitReader(int(t.MaxLineBytes))
+			}
+
 			tailer, err := tail.TailFile(file,
 				tail.Config{
 					ReOpen:    true,
@@ -330,7 +532,12 @@ func (t *Tail) tailNewFiles() error {
 					Pipe:      t.Pipe,
 					Logger:    tail.DiscardingLogger,
 					OpenReaderFunc: func(rd io.Reader) io.Reader {
-						r, _ := utfbom.Skip(t.decoder.Reader(rd))
+						offsets.opened(rd)
+						r, enc := utfbom.Skip(t.decoder.Reader(rd))
+						offsets.skipped(enc)
+						if limiter != nil {
+							return limiter.open(r, rd)
+						}
 						return r
 					},
 				})
@@ -342,11 +549,18 @@ func (t *Tail) tailNewFiles() error {
 
 			t.Log.Debugf("Tail added for %q", file)
 
-			parser, err := t.parserFunc()
+			// Throttle reading the data already present in the file
+			var bf *backfill
+			if t.limiter != nil {
+				bf = t.newBackfill(tailer.Filename, seek)
+			}
+
+			parser, err := group.newParser(t.parserFunc)
 			if err != nil {
-				t.Log.Errorf("Creating parser: %v", err)
+				t.Log.Errorf("Creating parser for %q: %v", file, err)
 				continue
 			}
+			stats := t.fileStatsFor(tailer.Filename)
 
 			// create a goroutine for each "tailer"
 			t.wg.Add(1)
@@ -354,11 +568,17 @@ func (t *Tail) tailNewFiles() error {
 			// Store the tailer in the map before starting the goroutine
 			t.tailersMutex.Lock()
 			t.tailers[tailer.Filename] = tailer
+			if offsets != nil {
+				t.trackers[tailer.Filename] = offsets
+			}
+			if limiter != nil {
+				t.limiters[tailer.Filename] = limiter
+			}
 			t.tailersMutex.Unlock()
 
 			go func(tl *tail.Tail) {
 				defer t.wg.Done()
-				t.receiver(parser, tl)
+				t.receiver(parser, tl, bf, group, stats, offsets)
 
 				t.Log.Debugf("Tail removed for %q", tl.Filename)
 
@@ -367,6 +587,8 @@ func (t *Tail) tailNewFiles() error {
 						t.Log.Errorf("Deleting tailer for %q due to: %v", tl.Filename, err)
 						t.tailersMutex.Lock()
 						delete(t.tailers, tl.Filename)
+						delete(t.trackers, tl.Filename)
+						delete(t.limiters, tl.Filename)
 						t.tailersMutex.Unlock()
 					} else {
 						t.Log.Errorf("Tailing %q: %v", tl.Filename, err)
@@ -376,8 +598,7 @@ func (t *Tail) tailNewFiles() error {
 		}
 	}
 
-	// Clean up tailers for files that are no longer being monitored
-	return t.cleanupUnusedTailers(currentFiles)
+	return nil
 }
 
 // cleanupUnusedTailers stops and removes tailers for files that are no longer being monitored.
@@ -400,10 +621,11 @@ func (t *Tail) cleanupUnusedTailers(currentFiles map[string]bool) error {
 
 			// Now it's safe to get and save the offset since the tailer is stopped
 			if !t.Pipe {
-				offset, err := tailer.Tell()
+				offset, err := t.offsetOf(tailer)
 				if err == nil {
 					t.Log.Debugf("Recording offset %d for %q", offset, tailer.Filename)
 					t.offsets[tailer.Filename] = offset
+					t.recordIdentity(tailer.Filename)
 				} else {
 					// This can happen if the file was already removed or closed
 					t.Log.Debugf("Could not get offset for %q: %v", tailer.Filename, err)
@@ -412,6 +634,8 @@ func (t *Tail) cleanupUnusedTailers(currentFiles map[string]bool) error {
 
 			// Remove from our map
 			delete(t.tailers, file)
+			delete(t.trackers, file)
+			delete(t.limiters, file)
 		}
 	}
 
@@ -431,7 +655,12 @@ func parseLine(parser telegraf.Parser, line string) ([]telegraf.Metric, error) {
 
 // receiver is launched as a goroutine to continuously watch a tailed logfile
 // for changes, parse any incoming messages, and add to the accumulator.
-func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail) {
+// If given, the offsets of the lines are tracked for the metrics delivered.
+func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail, bf *backfill, group *fileGroup, stats *fileStats, offsets *offsetTracker) {
+	// The statistics of an unfinished backfill are stale once the file is no
+	// longer tailed
+	defer bf.close()
+
 	// holds the individual lines of multi-line log entries.
 	var buffer bytes.Buffer
 
@@ -445,6 +674,15 @@ func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail) {
 		timeout = timer.C
 	}
 
+	// The container format was checked on Init so there cannot be an error
+	container, _ := newContainerDecoder(t.ContainerFormat)
+
+	var limit *lineLimit
+	if t.MaxLineBytes > 0 {
+		limit = newLineLimit(tailer.Filename, int(t.MaxLineBytes), t.MaxLineAction == "drop")
+		defer limit.close()
+	}
+
 	channelOpen := true
 	tailerOpen := true
 	var line *tail.Line
@@ -468,18 +706,60 @@ func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail) {
 
 		var text string
 
+		if line != nil && bf.active() {
+			if !t.throttle(tailer, bf, line) {
+				return
+			}
+		}
+
 		if line != nil {
+			var start int64
+			if line.Err == nil {
+				start = offsets.read(len(line.Text))
+			}
+
 			// Fix up files with Windows line endings.
 			text = strings.TrimRight(line.Text, "\r")
 
+			var keep bool
+			if text, keep = limit.apply(text); !keep {
+				t.Log.Debugf("Dropping line in %q exceeding %d bytes", tailer.Filename, t.MaxLineBytes)
+				offsets.handled()
+				continue
+			}
+
+			// Strip the container runtime framing and join split messages
+			if container != nil && line.Err == nil {
+				msg, complete, err := container.process(text)
+				if err != nil {
+					t.Log.Errorf("Malformed container log line in %q: [%q]: %v", tailer.Filename, text, err)
+					continue
+				}
+				if !complete {
+					continue
+				}
+				text = msg
+			}
+
 			if t.multiline.isEnabled() {
-				if text = t.multiline.processLine(text, &buffer); text == "" {
+				buffered := buffer.Len() > 0
+				text = t.multiline.processLine(text, &buffer)
+				switch {
+				case buffer.Len() == 0:
+					offsets.release()
+				case !buffered || text != "":
+					// The buffer starts with the current line
+					offsets.hold(start)
+				}
+				if text == "" {
 					continue
 				}
 			}
 		}
 		if line == nil || !channelOpen || !tailerOpen {
-			if text += flush(&buffer); text == "" {
+			text += flush(&buffer)
+			offsets.release()
+			if text == "" {
 				if !channelOpen {
 					return
 				}
@@ -502,9 +782,11 @@ func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail) {
 		}
 
 		metrics, err := parseLine(parser, text)
+		stats.processed(err == nil)
 		if err != nil {
 			t.Log.Errorf("Malformed log line in %q: [%q]: %v",
 				tailer.Filename, text, err)
+			offsets.handled()
 			continue
 		}
 		if len(metrics) == 0 {
@@ -517,11 +799,12 @@ func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail) {
 				metric.AddTag(t.PathTag, tailer.Filename)
 			}
 		}
+		group.addTags(metrics)
 
 		// try writing out metric first without blocking
 		select {
 		case t.sem <- empty{}:
-			t.acc.AddTrackingMetricGroup(metrics)
+			t.addMetrics(metrics, offsets)
 			if t.ctx.Err() != nil {
 				return // exit!
 			}
@@ -541,22 +824,75 @@ func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail) {
 		// full delivery channel and panic with "channel is full" (#19073).
 		case <-tailer.Dying():
 		case t.sem <- empty{}:
-			t.acc.AddTrackingMetricGroup(metrics)
+			t.addMetrics(metrics, offsets)
 		}
 	}
 }
 
+// newBackfill determines the amount of data present in the file between the
+// initial read position and the end of the file
+func (t *Tail) newBackfill(filename string, seek *tail.SeekInfo) *backfill {
+	if seek == nil || seek.Whence != io.SeekStart {
+		return nil
+	}
+	stat, err := os.Stat(filename)
+	if err != nil {
+		t.Log.Debugf("Cannot determine size of %q for backfilling: %v", filename, err)
+		return nil
+	}
+	bf := newBackfill(filename, seek.Offset, stat.Size())
+	if bf != nil {
+		t.Log.Debugf("Backfilling %d bytes of %q", bf.remaining, filename)
+	}
+	return bf
+}
+
+// throttle delays processing the given line of historic data according to
+// the backfill rate limit. It returns false if the plugin is stopping.
+func (t *Tail) throttle(tailer *tail.Tail, bf *backfill, line *tail.Line) bool {
+	// Account for the line ending removed by the tailer
+	size := int64(len(line.Text)) + 1
+
+	n := int64(1)
+	if t.limiter.bytes {
+		n = size
+	}
+	if delay := t.limiter.reserve(time.Now(), n); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-t.ctx.Done():
+			return false
+		case <-tailer.Dying():
+		case <-timer.C:
+		}
+	}
+
+	bf.consume(size)
+	if !bf.active() {
+		t.Log.Debugf("Finished backfilling %q", tailer.Filename)
+		bf.close()
+	}
+	return true
+}
+
 func newTail() *Tail {
 	offsetsMutex.Lock()
 	offsetsCopy := make(map[string]int64, len(offsets))
 	for k, v := range offsets {
 		offsetsCopy[k] = v
 	}
+	identitiesCopy := make(map[string]*fileIdentity, len(identities))
+	for k, v := range identities {
+		identitiesCopy[k] = v
+	}
 	offsetsMutex.Unlock()
 
 	return &Tail{
 		MaxUndeliveredLines: 1000,
+		SkipBinaryFiles:     true,
 		offsets:             offsetsCopy,
+		identities:          identitiesCopy,
 		PathTag:             "path",
 	}
 }
@@ -772,7 +772,7 @@ func TestStatePersistence(t *testing.T) {
 	require.NoError(t, os.WriteFile(inputFilename, content, 0600))
 
 	// Define the metrics and state to skip the first metric
-	state := map[string]int64{inputFilename: int64(len(lines[0]))}
+	savedState := map[string]int64{inputFilename: int64(len(lines[0]))}
 	expectedState := map[string]int64{inputFilename: int64(len(content))}
 	expected := []telegraf.Metric{
 		metric.New("metric",
@@ -800,7 +800,7 @@ func TestStatePersistence(t *testing.T) {
 
 	// Setup the "persisted" state
 	var pi telegraf.StatefulPlugin = plugin
-	require.NoError(t, pi.SetState(state))
+	require.NoError(t, pi.SetState(savedState))
 	require.Len(t, plugin.offsets, 1)
 
 	// Run the plugin
@@ -823,9 +823,10 @@ func TestStatePersistence(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, actual, options...)
 
 	// Check getting the persisted state
-	actualState, ok := pi.GetState().(map[string]int64)
-	require.True(t, ok, "state is not a map[string]int64")
-	require.Equal(t, expectedState, actualState)
+	actualState, ok := pi.GetState().(state)
+	require.True(t, ok, "state is not of type 'state'")
+	require.Equal(t, expectedState, actualState.Offsets)
+	require.Contains(t, actualState.Files, inputFilename)
 }
 
 func TestGetSeekInfo(t *testing.T) {
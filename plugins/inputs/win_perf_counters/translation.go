@@ -0,0 +1,84 @@
+//go:build windows
+
+package win_perf_counters
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	englishNamesKey   = `SOFTWARE\Microsoft\Windows NT\CurrentVersion\Perflib\009`
+	localizedNamesKey = `SOFTWARE\Microsoft\Windows NT\CurrentVersion\Perflib\CurrentLanguage`
+)
+
+// counterNameTranslator maps localized object and counter names to their
+// English counterparts. Windows identifies the names by an index which is
+// identical for all languages, so the translation goes from the localized
+// name to the index and from there to the English name.
+type counterNameTranslator struct {
+	english map[string]string
+}
+
+func newCounterNameTranslator() (*counterNameTranslator, error) {
+	english, err := readCounterNames(englishNamesKey)
+	if err != nil {
+		return nil, fmt.Errorf("reading English names failed: %w", err)
+	}
+	localized, err := readCounterNames(localizedNamesKey)
+	if err != nil {
+		return nil, fmt.Errorf("reading localized names failed: %w", err)
+	}
+
+	t := &counterNameTranslator{english: make(map[string]string, len(localized))}
+	for index, name := range localized {
+		en, found := english[index]
+		if !found {
+			continue
+		}
+		// Keep the first translation for ambiguous localized names
+		if _, exists := t.english[name]; !exists {
+			t.english[name] = en
+		}
+	}
+	return t, nil
+}
+
+// translate returns the English name for the given localized name or the
+// name itself if there is no translation.
+func (t *counterNameTranslator) translate(name string) string {
+	if t == nil {
+		return name
+	}
+	if en, found := t.english[name]; found {
+		return en
+	}
+	return name
+}
+
+// readCounterNames reads the index to name table stored in the "Counter"
+// value of the given registry key as alternating index and name entries.
+func readCounterNames(path string) (map[int]string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	entries, _, err := key.GetStringsValue("Counter")
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string, len(entries)/2)
+	for i := 0; i+1 < len(entries); i += 2 {
+		index, err := strconv.Atoi(entries[i])
+		if err != nil {
+			continue
+		}
+		names[index] = entries[i+1]
+	}
+	return names, nil
+}
@@ -44,6 +44,7 @@ type WinPerfCounters struct {
 	lastRefreshed time.Time
 	queryCreator  performanceQueryCreator
 	hostCounters  map[string]*hostCountersInfo
+	translator    *counterNameTranslator
 	// cached os.Hostname()
 	cachedHostname string
 }
@@ -58,6 +59,7 @@ type perfObject struct {
 	FailOnMissing bool     `toml:"FailOnMissing"`
 	IncludeTotal  bool     `toml:"IncludeTotal"`
 	UseRawValues  bool     `toml:"UseRawValues"`
+	ValueType     string   `toml:"ValueType"`
 }
 
 type hostCountersInfo struct {
@@ -103,32 +105,18 @@ func (m *WinPerfCounters) Init() error {
 		return fmt.Errorf("maximum buffer size should be smaller than %d", uint32(math.MaxUint32))
 	}
 
-	if m.UseWildcardsExpansion && !m.LocalizeWildcardsExpansion {
-		// Counters must not have wildcards with this option
-		found := false
-		wildcards := []string{"*", "?"}
-
-		for _, object := range m.Object {
-			for _, wildcard := range wildcards {
-				if strings.Contains(object.ObjectName, wildcard) {
-					found = true
-					m.Log.Errorf("Object: %s, contains wildcard %s", object.ObjectName, wildcard)
-				}
-			}
-			for _, counter := range object.Counters {
-				for _, wildcard := range wildcards {
-					if strings.Contains(counter, wildcard) {
-						found = true
-						m.Log.Errorf("Object: %s, counter: %s contains wildcard %s", object.ObjectName, counter, wildcard)
-					}
-				}
+	for _, object := range m.Object {
+		switch object.ValueType {
+		case "", "raw":
+		case "formatted", "both":
+			if object.UseRawValues {
+				return fmt.Errorf("object %q: UseRawValues conflicts with ValueType %q", object.ObjectName, object.ValueType)
 			}
-		}
-
-		if found {
-			return errors.New("wildcards can't be used with LocalizeWildcardsExpansion=false")
+		default:
+			return fmt.Errorf("object %q: invalid ValueType %q", object.ObjectName, object.ValueType)
 		}
 	}
+
 	return nil
 }
 
@@ -358,7 +346,17 @@ func (m *WinPerfCounters) addItem(counterPath, computer, objectName, instance, c
 				// should return English metrics, but
 				// expandWildCardPath returns localized counters. Undo
 				// that by using the original object and counter
-				// names, along with the expanded instance.
+				// names, along with the expanded instance. Names
+				// expanded from wildcards are translated back to
+				// English.
+				englishObjectName := origObjectName
+				if hasWildcard(origObjectName) {
+					englishObjectName = m.translator.translate(objectName)
+				}
+				englishCounterName := origCounterName
+				if hasWildcard(origCounterName) {
+					englishCounterName = m.translator.translate(counterName)
+				}
 
 				var newInstance string
 				if instance == "" {
@@ -366,7 +364,7 @@ func (m *WinPerfCounters) addItem(counterPath, computer, objectName, instance, c
 				} else {
 					newInstance = instance
 				}
-				counterPath = formatPath(computer, origObjectName, newInstance, origCounterName)
+				counterPath = formatPath(computer, englishObjectName, newInstance, englishCounterName)
 				counterHandle, err = hostCounter.query.addEnglishCounterToQuery(counterPath)
 				if err != nil {
 					return err
@@ -375,8 +373,8 @@ func (m *WinPerfCounters) addItem(counterPath, computer, objectName, instance, c
 					counterHandle,
 					counterPath,
 					computer,
-					origObjectName, instance,
-					origCounterName,
+					englishObjectName, instance,
+					englishCounterName,
 					measurement,
 					includeTotal,
 					useRawValue,
@@ -443,6 +441,24 @@ func formatPath(computer, objectName, instance, counter string) string {
 	return path
 }
 
+func hasWildcard(name string) bool {
+	return strings.ContainsAny(name, "*?")
+}
+
+// rawValueModes returns whether to gather raw values, formatted values or
+// both for the counters of the object.
+func (o *perfObject) rawValueModes() []bool {
+	switch o.ValueType {
+	case "raw":
+		return []bool{true}
+	case "formatted":
+		return []bool{false}
+	case "both":
+		return []bool{false, true}
+	}
+	return []bool{o.UseRawValues}
+}
+
 func (m *WinPerfCounters) parseConfig() error {
 	var counterPath string
 
@@ -455,6 +471,17 @@ func (m *WinPerfCounters) parseConfig() error {
 		return err
 	}
 
+	// Load the name tables once for translating the localized names of
+	// expanded wildcards
+	if m.UseWildcardsExpansion && !m.LocalizeWildcardsExpansion && m.translator == nil {
+		translator, err := newCounterNameTranslator()
+		if err != nil {
+			m.Log.Warnf("Cannot translate localized counter names: %v", err)
+			translator = &counterNameTranslator{}
+		}
+		m.translator = translator
+	}
+
 	for _, PerfObject := range m.Object {
 		computers := PerfObject.Sources
 		if len(computers) == 0 {
@@ -473,14 +500,16 @@ func (m *WinPerfCounters) parseConfig() error {
 					objectName := PerfObject.ObjectName
 					counterPath = formatPath(computer, objectName, instance, counter)
 
-					err := m.addItem(counterPath, computer, objectName, instance, counter,
-						PerfObject.Measurement, PerfObject.IncludeTotal, PerfObject.UseRawValues)
-					if err != nil {
-						if PerfObject.FailOnMissing || PerfObject.WarnOnMissing {
-							m.Log.Errorf("Invalid counterPath %q: %s", counterPath, err.Error())
-						}
-						if PerfObject.FailOnMissing {
-							return err
+					for _, useRawValue := range PerfObject.rawValueModes() {
+						err := m.addItem(counterPath, computer, objectName, instance, counter,
+							PerfObject.Measurement, PerfObject.IncludeTotal, useRawValue)
+						if err != nil {
+							if PerfObject.FailOnMissing || PerfObject.WarnOnMissing {
+								m.Log.Errorf("Invalid counterPath %q: %s", counterPath, err.Error())
+							}
+							if PerfObject.FailOnMissing {
+								return err
+							}
 						}
 					}
 				}
@@ -2020,21 +2020,117 @@ func TestUTF16ToStringArray(t *testing.T) {
 	require.Equal(t, czechStrings, stringArrayWithCzechChars, "Not equal czech arrays")
 }
 
-func TestNoWildcards(t *testing.T) {
+func TestWildcardsWithoutLocalization(t *testing.T) {
 	m := WinPerfCounters{
 		Object:                     createPerfObject("", "measurement", "object", []string{"instance"}, []string{"counter*"}, false, false, false),
 		UseWildcardsExpansion:      true,
 		LocalizeWildcardsExpansion: false,
+		MaxBufferSize:              defaultMaxBufferSize,
 		Log:                        testutil.Logger{},
 	}
-	require.Error(t, m.Init())
+	require.NoError(t, m.Init())
 	m = WinPerfCounters{
 		Object:                     createPerfObject("", "measurement", "object?", []string{"instance"}, []string{"counter"}, false, false, false),
 		UseWildcardsExpansion:      true,
 		LocalizeWildcardsExpansion: false,
+		MaxBufferSize:              defaultMaxBufferSize,
+		Log:                        testutil.Logger{},
+	}
+	require.NoError(t, m.Init())
+}
+
+func TestParseConfigTranslateWildcards(t *testing.T) {
+	perfObjects := createPerfObject("", "m", "O", []string{"I"}, []string{"*"}, true, false, false)
+	localized := []string{"\\O(I)\\Z\u00e4hler1", "\\O(I)\\Z\u00e4hler2"}
+	counterPaths := append([]string{"\\O(I)\\*", "\\O(I)\\C1", "\\O(I)\\C2"}, localized...)
+	m := WinPerfCounters{
 		Log:                        testutil.Logger{},
+		UseWildcardsExpansion:      true,
+		LocalizeWildcardsExpansion: false,
+		Object:                     perfObjects,
+		queryCreator: &fakePerformanceQueryCreator{
+			fakeQueries: map[string]*fakePerformanceQuery{"localhost": {
+				counters: createCounterMap(counterPaths, []float64{0, 1.1, 1.2, 0, 0}, []uint32{0, 0, 0, 0, 0}),
+				expandPaths: map[string][]string{
+					"\\O(I)\\*": localized,
+				},
+				vistaAndNewer: true,
+			},
+			},
+		},
+		translator: &counterNameTranslator{
+			english: map[string]string{
+				"Z\u00e4hler1": "C1",
+				"Z\u00e4hler2": "C2",
+			},
+		},
+	}
+	require.NoError(t, m.parseConfig())
+
+	counters, ok := m.hostCounters["localhost"]
+	require.True(t, ok)
+	require.Len(t, counters.counters, 2)
+	require.Equal(t, "\\O(I)\\C1", counters.counters[0].counterPath)
+	require.Equal(t, "C1", counters.counters[0].counter)
+	require.Equal(t, "\\O(I)\\C2", counters.counters[1].counterPath)
+	require.Equal(t, "C2", counters.counters[1].counter)
+	require.NoError(t, m.cleanQueries())
+}
+
+func TestValueTypeInvalid(t *testing.T) {
+	m := WinPerfCounters{
+		Object:        createPerfObject("", "measurement", "object", []string{"instance"}, []string{"counter"}, false, false, false),
+		MaxBufferSize: defaultMaxBufferSize,
+		Log:           testutil.Logger{},
 	}
-	require.Error(t, m.Init())
+	m.Object[0].ValueType = "cooked"
+	require.ErrorContains(t, m.Init(), `invalid ValueType "cooked"`)
+
+	m.Object[0].ValueType = "both"
+	m.Object[0].UseRawValues = true
+	require.ErrorContains(t, m.Init(), "UseRawValues conflicts")
+}
+
+func TestGatherBothValueTypes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping long taking test in short mode")
+	}
+	measurement := "m"
+	perfObjects := createPerfObject("", measurement, "O", []string{"I"}, []string{"C"}, true, false, false)
+	perfObjects[0].ValueType = "both"
+	cp1 := "\\O(I)\\C"
+	m := WinPerfCounters{
+		Log:    testutil.Logger{},
+		Object: perfObjects,
+		queryCreator: &fakePerformanceQueryCreator{
+			fakeQueries: map[string]*fakePerformanceQuery{"localhost": {
+				counters: createCounterMap([]string{cp1}, []float64{3.3}, []uint32{0}),
+				expandPaths: map[string][]string{
+					cp1: {cp1},
+				},
+				vistaAndNewer: true,
+			},
+			},
+		},
+	}
+	var acc testutil.Accumulator
+	require.NoError(t, m.Gather(&acc))
+
+	counters, ok := m.hostCounters["localhost"]
+	require.True(t, ok)
+	require.Len(t, counters.counters, 2)
+
+	fields := map[string]interface{}{
+		"C":     3.3,
+		"C_Raw": int64(3),
+	}
+	tags := map[string]string{
+		"instance":   "I",
+		"objectname": "O",
+		"source":     hostname(),
+	}
+	acc.AssertContainsTaggedFields(t, measurement, fields, tags)
+	require.NoError(t, m.cleanQueries())
 }
 
 func TestLocalizeWildcardsExpansion(t *testing.T) {
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.questdb
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/questdb" // register plugin
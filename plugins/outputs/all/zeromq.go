@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.zeromq
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/zeromq" // register plugin
@@ -19,8 +19,10 @@ import (
 var sampleConfig string
 
 type AzureDataExplorer struct {
-	Log telegraf.Logger `toml:"-"`
+	TableMapping map[string]string `toml:"table_mapping"`
+	Log          telegraf.Logger   `toml:"-"`
 	common_adx.Config
+	common_adx.AuthConfig
 
 	serializer telegraf.Serializer
 	client     *common_adx.Client
@@ -44,7 +46,7 @@ func (adx *AzureDataExplorer) Init() error {
 
 func (adx *AzureDataExplorer) Connect() error {
 	var err error
-	if adx.client, err = adx.Config.NewClient("Kusto.Telegraf", adx.Log); err != nil {
+	if adx.client, err = adx.Config.NewClient("Kusto.Telegraf", &adx.AuthConfig, adx.Log); err != nil {
 		return fmt.Errorf("creating new client failed: %w", err)
 	}
 	return nil
@@ -65,7 +67,7 @@ func (adx *AzureDataExplorer) writeTablePerMetric(metrics []telegraf.Metric) err
 	tableMetricGroups := make(map[string][]byte)
 	// Group metrics by name and serialize them
 	for _, m := range metrics {
-		tableName := m.Name()
+		tableName := adx.tableName(m.Name())
 		metricInBytes, err := adx.serializer.Serialize(m)
 		if err != nil {
 			return err
@@ -88,6 +90,15 @@ func (adx *AzureDataExplorer) writeTablePerMetric(metrics []telegraf.Metric) err
 	return nil
 }
 
+// tableName returns the table for the given metric name when using the
+// "TablePerMetric" grouping
+func (adx *AzureDataExplorer) tableName(name string) string {
+	if table, found := adx.TableMapping[name]; found {
+		return table
+	}
+	return name
+}
+
 func (adx *AzureDataExplorer) writeSingleTable(metrics []telegraf.Metric) error {
 	// serialise each metric in metrics - store in byte[]
 	metricsArray := make([]byte, 0)
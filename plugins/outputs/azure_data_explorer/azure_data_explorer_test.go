@@ -28,3 +28,11 @@ func TestConnectBlankEndpointData(t *testing.T) {
 	}
 	require.ErrorContains(t, plugin.Connect(), "endpoint configuration cannot be empty")
 }
+
+func TestTableMapping(t *testing.T) {
+	plugin := AzureDataExplorer{
+		TableMapping: map[string]string{"cpu": "CpuMetrics"},
+	}
+	require.Equal(t, "CpuMetrics", plugin.tableName("cpu"))
+	require.Equal(t, "mem", plugin.tableName("mem"))
+}
@@ -8,43 +8,117 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"net/http"
+	"os"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"cloud.google.com/go/bigquery"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	common_gcp "github.com/influxdata/telegraf/plugins/common/gcp"
 	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
 var sampleConfig string
 
-const timeStampFieldName = "timestamp"
+const (
+	timeStampFieldName = "timestamp"
 
-var defaultTimeout = config.Duration(5 * time.Second)
+	metadataIngestTime      = "ingest_time"
+	metadataAgentHost       = "agent_host"
+	metadataTelegrafVersion = "telegraf_version"
+
+	defaultMaxConcurrentInserts = 4
+	defaultMaxRowsPerInsert     = 500
+	defaultMaxRetries           = 3
+)
+
+var (
+	defaultTimeout       = config.Duration(5 * time.Second)
+	defaultRetryInterval = config.Duration(time.Second)
+)
+
+// Restrictions of label keys and values, see
+// https://cloud.google.com/bigquery/docs/labels-intro#requirements
+var (
+	labelKeyRe   = regexp.MustCompile(`^\p{Ll}[\p{Ll}\p{Lo}\p{N}_-]{0,62}$`)
+	labelValueRe = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{N}_-]{0,63}$`)
+)
+
+// Resource name of a Cloud KMS key used for encrypting created tables
+var kmsKeyNameRe = regexp.MustCompile(`^projects/[^/]+/locations/[^/]+/keyRings/[^/]+/cryptoKeys/[^/]+$`)
+
+const maxLabels = 64
 
 type BigQuery struct {
-	CredentialsFile string `toml:"credentials_file"`
-	Project         string `toml:"project"`
-	Dataset         string `toml:"dataset"`
+	CredentialsFile       string `toml:"credentials_file"`
+	Project               string `toml:"project"`
+	Dataset               string `toml:"dataset"`
+	Endpoint              string `toml:"endpoint"`
+	DisableAuthentication bool   `toml:"disable_authentication"`
 
 	Timeout         config.Duration `toml:"timeout"`
 	ReplaceHyphenTo string          `toml:"replace_hyphen_to"`
 	CompactTable    string          `toml:"compact_table"`
+	CompactInclude  []string        `toml:"compact_include"`
+	CompactExclude  []string        `toml:"compact_exclude"`
+	NarrowTable     string          `toml:"narrow_table"`
+	TimestampColumn string          `toml:"timestamp_column"`
+	MetadataColumns []string        `toml:"metadata_columns"`
+
+	DeadLetterSuffix string `toml:"dead_letter_suffix"`
+
+	CreateTables bool              `toml:"create_tables"`
+	KMSKeyName   string            `toml:"kms_key_name"`
+	Labels       map[string]string `toml:"labels"`
+
+	MaxConcurrentInserts int `toml:"max_concurrent_inserts"`
+	MaxRowsPerInsert     int `toml:"max_rows_per_insert"`
+
+	MaxRetries    int             `toml:"max_retries"`
+	RetryInterval config.Duration `toml:"retry_interval"`
 
 	Log telegraf.Logger `toml:"-"`
 
-	client *bigquery.Client
+	client        *bigquery.Client
+	hostname      string
+	compactFilter filter.Filter
 
 	warnedOnHyphens map[string]bool
+
+	stats     map[string]*tableStats
+	statsLock sync.Mutex
+
+	// Tables already created or labeled
+	prepared     map[string]bool
+	preparedLock sync.Mutex
+
+	// Context to abort waiting for retries on close
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// tableStats holds the internal statistics of inserts into a table
+type tableStats struct {
+	insertTime   selfstat.Stat
+	inserts      selfstat.Stat
+	insertErrors selfstat.Stat
+	rowsSent     selfstat.Stat
+	rowsFailed   selfstat.Stat
+	retries      selfstat.Stat
 }
 
 func (*BigQuery) SampleConfig() string {
@@ -53,6 +127,10 @@ func (*BigQuery) SampleConfig() string {
 
 func (b *BigQuery) Init() error {
 	if b.Project == "" {
+		// Detecting the project requires credentials
+		if b.DisableAuthentication {
+			return errors.New(`"project" is required if authentication is disabled`)
+		}
 		b.Project = bigquery.DetectProjectID
 	}
 
@@ -60,7 +138,81 @@ func (b *BigQuery) Init() error {
 		return errors.New(`"dataset" is required`)
 	}
 
+	if b.MaxConcurrentInserts < 0 {
+		return errors.New(`"max_concurrent_inserts" must not be negative`)
+	}
+	if b.MaxConcurrentInserts == 0 {
+		b.MaxConcurrentInserts = defaultMaxConcurrentInserts
+	}
+	if b.MaxRowsPerInsert < 0 {
+		return errors.New(`"max_rows_per_insert" must not be negative`)
+	}
+	if b.MaxRowsPerInsert == 0 {
+		b.MaxRowsPerInsert = defaultMaxRowsPerInsert
+	}
+	if b.MaxRetries < 0 {
+		return errors.New(`"max_retries" must not be negative`)
+	}
+
+	if b.CompactTable != "" && b.NarrowTable != "" {
+		return errors.New(`"compact_table" and "narrow_table" are mutually exclusive`)
+	}
+	if b.CompactTable == "" && (len(b.CompactInclude) > 0 || len(b.CompactExclude) > 0) {
+		return errors.New(`"compact_include" and "compact_exclude" require "compact_table"`)
+	}
+	f, err := filter.NewIncludeExcludeFilter(b.CompactInclude, b.CompactExclude)
+	if err != nil {
+		return fmt.Errorf("creating compact table filter failed: %w", err)
+	}
+	b.compactFilter = f
+
+	if b.TimestampColumn == "" {
+		b.TimestampColumn = timeStampFieldName
+	}
+	seen := make(map[string]bool, len(b.MetadataColumns))
+	for _, column := range b.MetadataColumns {
+		switch column {
+		case metadataIngestTime, metadataTelegrafVersion:
+		case metadataAgentHost:
+			hostname, err := os.Hostname()
+			if err != nil {
+				return fmt.Errorf("getting hostname failed: %w", err)
+			}
+			b.hostname = hostname
+		default:
+			return fmt.Errorf("invalid metadata column %q", column)
+		}
+		if seen[column] || column == b.TimestampColumn {
+			return fmt.Errorf("duplicate column %q", column)
+		}
+		seen[column] = true
+	}
+
+	if b.KMSKeyName != "" {
+		if !b.CreateTables {
+			return errors.New(`"kms_key_name" requires "create_tables"`)
+		}
+		if !kmsKeyNameRe.MatchString(b.KMSKeyName) {
+			return fmt.Errorf("invalid KMS key name %q", b.KMSKeyName)
+		}
+	}
+
+	if len(b.Labels) > maxLabels {
+		return fmt.Errorf("number of labels exceeds the maximum of %d", maxLabels)
+	}
+	for k, v := range b.Labels {
+		if !labelKeyRe.MatchString(k) {
+			return fmt.Errorf("invalid label key %q", k)
+		}
+		if !labelValueRe.MatchString(v) {
+			return fmt.Errorf("invalid value %q for label %q", v, k)
+		}
+	}
+
 	b.warnedOnHyphens = make(map[string]bool)
+	b.stats = make(map[string]*tableStats)
+	b.prepared = make(map[string]bool)
+	b.ctx, b.cancel = context.WithCancel(context.Background())
 
 	return nil
 }
@@ -72,33 +224,170 @@ func (b *BigQuery) Connect() error {
 		}
 	}
 
-	if b.CompactTable != "" {
-		ctx := context.Background()
-		ctx, cancel := context.WithTimeout(ctx, time.Duration(b.Timeout))
-		defer cancel()
+	var kind, tableName string
+	var schema bigquery.Schema
+	switch {
+	case b.CompactTable != "":
+		kind, tableName, schema = "compact", b.CompactTable, b.compactSchema()
+	case b.NarrowTable != "":
+		kind, tableName, schema = "narrow", b.NarrowTable, b.narrowSchema()
+	default:
+		return nil
+	}
 
-		// Check if the compact table exists
-		_, err := b.client.Dataset(b.Dataset).Table(b.CompactTable).Metadata(ctx)
-		if err != nil {
-			return fmt.Errorf("compact table: %w", err)
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(b.Timeout))
+	defer cancel()
+
+	// Check if the table exists or create it
+	var err error
+	if b.CreateTables || len(b.Labels) > 0 {
+		err = b.prepareTable(ctx, tableName, schema)
+	} else {
+		_, err = b.client.Dataset(b.Dataset).Table(tableName).Metadata(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("%s table: %w", kind, err)
+	}
+	return nil
+}
+
+// isSingleTable returns true if the given table is the compact or narrow table
+// receiving all metrics using a fixed schema
+func (b *BigQuery) isSingleTable(tableName string) bool {
+	return tableName == b.CompactTable || tableName == b.NarrowTable
+}
+
+// prepareTable creates the table with the given schema if it does not exist
+// and creating tables is enabled, and attaches the configured labels to the
+// table. Created tables are encrypted with the configured KMS key. Tables are
+// only prepared once. The lock is not held during the requests, concurrent
+// preparations are resolved when claiming the table.
+func (b *BigQuery) prepareTable(ctx context.Context, tableName string, schema bigquery.Schema) error {
+	if !b.CreateTables && len(b.Labels) == 0 {
+		return nil
+	}
+
+	b.preparedLock.Lock()
+	prepared := b.prepared[tableName]
+	b.preparedLock.Unlock()
+	if prepared {
+		return nil
+	}
+
+	table := b.client.Dataset(b.Dataset).Table(tableName)
+	meta, err := table.Metadata(ctx)
+	if err != nil {
+		if !isHTTPError(err, http.StatusNotFound) || !b.CreateTables {
+			return err
 		}
+		create := &bigquery.TableMetadata{
+			Schema: schema,
+			Labels: b.Labels,
+		}
+		if b.KMSKeyName != "" {
+			create.EncryptionConfig = &bigquery.EncryptionConfig{KMSKeyName: b.KMSKeyName}
+		}
+		err := table.Create(ctx, create)
+		if err != nil && !isHTTPError(err, http.StatusConflict) {
+			return fmt.Errorf("creating table %q failed: %w", tableName, err)
+		}
+		if err == nil {
+			b.Log.Infof("Created table %q", tableName)
+			b.claimTable(tableName)
+			return nil
+		}
+
+		// The table was created concurrently, so update its labels
+		if meta, err = table.Metadata(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Another writer prepared the table in the meantime
+	if !b.claimTable(tableName) {
+		return nil
 	}
+
+	if b.KMSKeyName != "" && (meta.EncryptionConfig == nil || meta.EncryptionConfig.KMSKeyName != b.KMSKeyName) {
+		b.Log.Warnf("Existing table %q is not encrypted with the configured KMS key", tableName)
+	}
+
+	var update bigquery.TableMetadataToUpdate
+	var changed bool
+	for k, v := range b.Labels {
+		if current, found := meta.Labels[k]; !found || current != v {
+			update.SetLabel(k, v)
+			changed = true
+		}
+	}
+	// The table is claimed already, so updating the labels is not retried on
+	// failure to avoid failing repeatedly e.g. due to missing permissions
+	if changed {
+		if _, err := table.Update(ctx, update, meta.ETag); err != nil {
+			return fmt.Errorf("updating labels of table %q failed: %w", tableName, err)
+		}
+	}
+
 	return nil
 }
 
-func (b *BigQuery) setUpDefaultClient() error {
-	var credentialsOption option.ClientOption
+// claimTable marks the table as prepared and returns false if it was already
+// prepared before
+func (b *BigQuery) claimTable(tableName string) bool {
+	b.preparedLock.Lock()
+	defer b.preparedLock.Unlock()
 
+	if b.prepared[tableName] {
+		return false
+	}
+	b.prepared[tableName] = true
+	return true
+}
+
+func isHTTPError(err error, code int) bool {
+	var gerr *googleapi.Error
+	return errors.As(err, &gerr) && gerr.Code == code
+}
+
+// rowsSchema returns the union of the schemas of the given rows
+func rowsSchema(rows []bigquery.ValueSaver) bigquery.Schema {
+	var schema bigquery.Schema
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		vs, ok := row.(*bigquery.ValuesSaver)
+		if !ok {
+			continue
+		}
+		for _, field := range vs.Schema {
+			if seen[field.Name] {
+				continue
+			}
+			seen[field.Name] = true
+			schema = append(schema, field)
+		}
+	}
+	return schema.Relax()
+}
+
+func (b *BigQuery) setUpDefaultClient() error {
 	// https://cloud.google.com/go/docs/reference/cloud.google.com/go/0.94.1#hdr-Timeouts_and_Cancellation
 	// Do not attempt to add timeout to this context for the bigquery client.
 	ctx := context.Background()
 
-	if b.CredentialsFile != "" {
+	options := []option.ClientOption{option.WithUserAgent(internal.ProductToken())}
+	if b.Endpoint != "" {
+		options = append(options, option.WithEndpoint(b.Endpoint))
+	}
+
+	if b.DisableAuthentication {
+		options = append(options, option.WithoutAuthentication())
+	} else if b.CredentialsFile != "" {
 		credType, err := common_gcp.ParseCredentialType(b.CredentialsFile)
 		if err != nil {
 			return fmt.Errorf("unable to parse credential file type: %w", err)
 		}
-		credentialsOption = option.WithAuthCredentialsFile(option.CredentialsType(credType), b.CredentialsFile)
+		options = append(options, option.WithAuthCredentialsFile(option.CredentialsType(credType), b.CredentialsFile))
 	} else {
 		creds, err := google.FindDefaultCredentials(ctx, bigquery.Scope)
 		if err != nil {
@@ -106,75 +395,300 @@ func (b *BigQuery) setUpDefaultClient() error {
 				"unable to find Google Cloud Platform Application Default Credentials: %w. "+
 					"Either set ADC or provide CredentialsFile config", err)
 		}
-		credentialsOption = option.WithCredentials(creds)
+		options = append(options, option.WithCredentials(creds))
 	}
 
-	client, err := bigquery.NewClient(ctx, b.Project,
-		credentialsOption,
-		option.WithUserAgent(internal.ProductToken()),
-	)
+	client, err := bigquery.NewClient(ctx, b.Project, options...)
 	b.client = client
 	return err
 }
 
+// insertJob is a batch of rows inserted into a table with a single request
+type insertJob struct {
+	metric  string
+	table   string
+	rows    []bigquery.ValueSaver
+	indices []int // indices of the metrics of the rows
+}
+
+// insertResult is the outcome of an insert job
+type insertResult struct {
+	keep []int // positions of the rows to keep for the next flush
+	err  error
+}
+
+// metricRows are the rows of a metric name and the indices of their metrics
+type metricRows struct {
+	rows    []bigquery.ValueSaver
+	indices []int
+}
+
 // Write the metrics to Google Cloud BigQuery.
 func (b *BigQuery) Write(metrics []telegraf.Metric) error {
-	if b.CompactTable != "" {
-		return b.writeCompact(metrics)
+	if b.NarrowTable != "" {
+		return b.writeNarrow(metrics)
 	}
 
-	groupedMetrics := groupByMetricName(metrics)
+	// Split the metrics into those written to the compact table and those
+	// written to per-measurement tables
+	var compact, regular []int
+	for i, m := range metrics {
+		if b.isCompact(m.Name()) {
+			compact = append(compact, i)
+		} else {
+			regular = append(regular, i)
+		}
+	}
 
-	var wg sync.WaitGroup
+	now := time.Now()
+	jobs, invalid := b.compactInsertJobs(metrics, compact, now)
 
-	for k, v := range groupedMetrics {
-		wg.Add(1)
-		go func(k string, v []bigquery.ValueSaver) {
-			defer wg.Done()
-			b.insertToTable(k, v)
-		}(k, v)
+	// Resolve the table names here to avoid concurrent access to the
+	// hyphen-warning cache in the workers
+	groupedMetrics := b.groupByMetricName(metrics, regular, now)
+	for name, group := range groupedMetrics {
+		jobs = b.appendInsertJobs(jobs, name, b.metricToTable(name), group.rows, group.indices)
+	}
+	if len(jobs) == 0 {
+		return nil
 	}
 
-	wg.Wait()
+	results := b.insert(jobs)
+	for i, result := range results {
+		if result.err != nil && jobs[i].metric != "" {
+			b.Log.Errorf("inserting metric %q failed: %v", jobs[i].metric, result.err)
+		}
+	}
 
-	return nil
+	return writeError(jobs, results, invalid)
 }
 
-func (b *BigQuery) writeCompact(metrics []telegraf.Metric) error {
-	ctx := context.Background()
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(b.Timeout))
-	defer cancel()
-
-	// Always returns an instance, even if table doesn't exist (anymore).
-	inserter := b.client.Dataset(b.Dataset).Table(b.CompactTable).Inserter()
+// isCompact returns true if the metric with the given name is written to the
+// compact table
+func (b *BigQuery) isCompact(metricName string) bool {
+	return b.CompactTable != "" && b.compactFilter.Match(metricName)
+}
 
-	var compactValues []*bigquery.ValuesSaver
-	for _, m := range metrics {
-		valueSaver, err := b.newCompactValuesSaver(m)
+// compactInsertJobs returns the insert jobs of the metrics at the given
+// indices for the compact table and the indices of the metrics that cannot
+// be written as compact value.
+func (b *BigQuery) compactInsertJobs(metrics []telegraf.Metric, indices []int, now time.Time) ([]insertJob, []int) {
+	compactValues := make([]bigquery.ValueSaver, 0, len(indices))
+	valid := make([]int, 0, len(indices))
+	var invalid []int
+	for _, i := range indices {
+		valueSaver, err := b.newCompactValuesSaver(metrics[i], now)
 		if err != nil {
 			b.Log.Warnf("could not prepare metric as compact value: %v", err)
+			invalid = append(invalid, i)
 		} else {
 			compactValues = append(compactValues, valueSaver)
+			valid = append(valid, i)
 		}
 	}
-	return inserter.Put(ctx, compactValues)
+
+	return b.appendInsertJobs(nil, "", b.CompactTable, compactValues, valid), invalid
 }
 
-func groupByMetricName(metrics []telegraf.Metric) map[string][]bigquery.ValueSaver {
-	groupedMetrics := make(map[string][]bigquery.ValueSaver)
+func (b *BigQuery) writeNarrow(metrics []telegraf.Metric) error {
+	now := time.Now()
+	rows := make([]bigquery.ValueSaver, 0, len(metrics))
+	indices := make([]int, 0, len(metrics))
+	var invalid []int
+	for i, m := range metrics {
+		valueSavers, err := b.newNarrowValuesSavers(m, now)
+		if err != nil {
+			b.Log.Warnf("could not prepare metric as narrow values: %v", err)
+			invalid = append(invalid, i)
+			continue
+		}
+		for _, valueSaver := range valueSavers {
+			rows = append(rows, valueSaver)
+			indices = append(indices, i)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	jobs := b.appendInsertJobs(nil, "", b.NarrowTable, rows, indices)
+	return writeError(jobs, b.insert(jobs), invalid)
+}
 
-	for _, m := range metrics {
-		bqm := newValuesSaver(m)
-		groupedMetrics[m.Name()] = append(groupedMetrics[m.Name()], bqm)
+// writeError returns the error reporting the metrics of the rows to keep for
+// the next flush to the agent. All other metrics are accepted, except for the
+// given invalid metrics being rejected. Metrics written as multiple rows are
+// kept if any of their rows is kept. Returns nil if no row is kept.
+func writeError(jobs []insertJob, results []insertResult, invalid []int) error {
+	var errs []error
+	kept := make(map[int]bool)
+	for i, job := range jobs {
+		if len(results[i].keep) == 0 {
+			continue
+		}
+		errs = append(errs, results[i].err)
+		for _, pos := range results[i].keep {
+			kept[job.indices[pos]] = true
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	var accept []int
+	seen := make(map[int]bool)
+	for _, job := range jobs {
+		for _, idx := range job.indices {
+			if kept[idx] || seen[idx] {
+				continue
+			}
+			seen[idx] = true
+			accept = append(accept, idx)
+		}
+	}
+
+	return &internal.PartialWriteError{
+		Err:           errors.Join(errs...),
+		MetricsAccept: accept,
+		MetricsReject: invalid,
+	}
+}
+
+// appendInsertJobs splits the given rows into batches of at most
+// max_rows_per_insert rows and appends the resulting jobs.
+func (b *BigQuery) appendInsertJobs(jobs []insertJob, metricName, tableName string, rows []bigquery.ValueSaver, indices []int) []insertJob {
+	for len(rows) > 0 {
+		n := min(len(rows), b.MaxRowsPerInsert)
+		jobs = append(jobs, insertJob{
+			metric:  metricName,
+			table:   tableName,
+			rows:    rows[:n],
+			indices: indices[:n],
+		})
+		rows = rows[n:]
+		indices = indices[n:]
+	}
+	return jobs
+}
+
+// insert executes the given jobs using at most max_concurrent_inserts
+// parallel requests and returns the result of each job at the job's index.
+func (b *BigQuery) insert(jobs []insertJob) []insertResult {
+	results := make([]insertResult, len(jobs))
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range min(b.MaxConcurrentInserts, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i].keep, results[i].err = b.insertWithRetry(b.ctx, jobs[i].table, jobs[i].rows, 0, 0)
+			}
+		}()
+	}
+
+	for i := range jobs {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	return results
+}
+
+// insertWithRetry inserts the rows into the table. If the insert fails due to
+// the request size or quota limits, the rows are split in half and each half
+// is retried with exponential backoff. The positions of the rows to keep for
+// the next flush, shifted by the given offset, are returned together with the
+// error. Rows of the compact or narrow table failing for other reasons are
+// kept as well, the same applies to rows not retried due to the given context
+// being canceled.
+func (b *BigQuery) insertWithRetry(ctx context.Context, tableName string, rows []bigquery.ValueSaver, offset, attempt int) ([]int, error) {
+	err := b.insertToTable(tableName, rows)
+	if err == nil {
+		return nil, nil
+	}
+
+	retryable := isRetryable(err)
+	if !retryable && !b.isSingleTable(tableName) {
+		return nil, err
+	}
+	keep := make([]int, 0, len(rows))
+	for i := range rows {
+		keep = append(keep, offset+i)
+	}
+	if !retryable || attempt >= b.MaxRetries {
+		return keep, err
+	}
+
+	delay := time.Duration(b.RetryInterval) << attempt
+	b.Log.Debugf("Inserting %d rows into table %q failed, retrying in %s: %v", len(rows), tableName, delay, err)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return keep, fmt.Errorf("retrying aborted: %w", err)
+	case <-timer.C:
+	}
+	b.tableStats(tableName).retries.Incr(1)
+
+	if len(rows) == 1 {
+		return b.insertWithRetry(ctx, tableName, rows, offset, attempt+1)
+	}
+	half := len(rows) / 2
+	keepFirst, errFirst := b.insertWithRetry(ctx, tableName, rows[:half], offset, attempt+1)
+	keepSecond, errSecond := b.insertWithRetry(ctx, tableName, rows[half:], offset+half, attempt+1)
+	return append(keepFirst, keepSecond...), errors.Join(errFirst, errSecond)
+}
+
+// isRetryable returns true if the insert failed due to the request size or
+// quota limits
+func isRetryable(err error) bool {
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		return false
+	}
+
+	switch gerr.Code {
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return true
+	case http.StatusBadRequest:
+		// Requests exceeding the payload limit are rejected as invalid
+		return strings.Contains(gerr.Message, "payload size exceeds")
+	case http.StatusForbidden:
+		for _, item := range gerr.Errors {
+			if item.Reason == "quotaExceeded" || item.Reason == "rateLimitExceeded" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// groupByMetricName groups the metrics at the given indices by their name
+func (b *BigQuery) groupByMetricName(metrics []telegraf.Metric, indices []int, now time.Time) map[string]*metricRows {
+	groupedMetrics := make(map[string]*metricRows)
+
+	for _, i := range indices {
+		m := metrics[i]
+		group, found := groupedMetrics[m.Name()]
+		if !found {
+			group = &metricRows{}
+			groupedMetrics[m.Name()] = group
+		}
+		group.rows = append(group.rows, b.newValuesSaver(m, now))
+		group.indices = append(group.indices, i)
 	}
 
 	return groupedMetrics
 }
 
-func newValuesSaver(m telegraf.Metric) *bigquery.ValuesSaver {
-	s := bigquery.Schema{timeStampFieldSchema()}
+func (b *BigQuery) newValuesSaver(m telegraf.Metric, now time.Time) *bigquery.ValuesSaver {
+	s := bigquery.Schema{timeStampFieldSchema(b.TimestampColumn)}
 	r := []bigquery.Value{m.Time()}
 
+	s, r = b.metadataSchemaAndValues(now, s, r)
 	s, r = tagsSchemaAndValues(m, s, r)
 	s, r = valuesSchemaAndValues(m, s, r)
 
@@ -184,7 +698,7 @@ func newValuesSaver(m telegraf.Metric) *bigquery.ValuesSaver {
 	}
 }
 
-func (b *BigQuery) newCompactValuesSaver(m telegraf.Metric) (*bigquery.ValuesSaver, error) {
+func (b *BigQuery) newCompactValuesSaver(m telegraf.Metric, now time.Time) (*bigquery.ValuesSaver, error) {
 	tags, err := json.Marshal(m.Tags())
 	if err != nil {
 		return nil, fmt.Errorf("serializing tags: %w", err)
@@ -206,25 +720,109 @@ func (b *BigQuery) newCompactValuesSaver(m telegraf.Metric) (*bigquery.ValuesSav
 		return nil, fmt.Errorf("serializing fields: %w", err)
 	}
 
-	return &bigquery.ValuesSaver{
-		Schema: bigquery.Schema{
-			timeStampFieldSchema(),
-			newStringFieldSchema("name"),
-			newJSONFieldSchema("tags"),
-			newJSONFieldSchema("fields"),
-		},
-		Row: []bigquery.Value{
-			m.Time(),
-			m.Name(),
-			string(tags),
-			string(fields),
-		},
-	}, nil
-}
-
-func timeStampFieldSchema() *bigquery.FieldSchema {
+	r := []bigquery.Value{m.Time()}
+	_, r = b.metadataSchemaAndValues(now, nil, r)
+	r = append(r, m.Name(), string(tags), string(fields))
+
+	return &bigquery.ValuesSaver{Schema: b.compactSchema(), Row: r}, nil
+}
+
+// compactSchema returns the schema of the compact table
+func (b *BigQuery) compactSchema() bigquery.Schema {
+	s := bigquery.Schema{timeStampFieldSchema(b.TimestampColumn)}
+	s, _ = b.metadataSchemaAndValues(time.Time{}, s, nil)
+	return append(s,
+		newStringFieldSchema("name"),
+		newJSONFieldSchema("tags"),
+		newJSONFieldSchema("fields"),
+	)
+}
+
+// newNarrowValuesSavers returns one row for each field of the metric. The
+// value is stored in the column matching its type while the other value
+// columns are left empty.
+func (b *BigQuery) newNarrowValuesSavers(m telegraf.Metric, now time.Time) ([]bigquery.ValueSaver, error) {
+	tags, err := json.Marshal(m.Tags())
+	if err != nil {
+		return nil, fmt.Errorf("serializing tags: %w", err)
+	}
+
+	schema := b.narrowSchema()
+	rows := make([]bigquery.ValueSaver, 0, len(m.FieldList()))
+	for _, field := range m.FieldList() {
+		var valueFloat, valueInt, valueString bigquery.Value
+		switch v := field.Value.(type) {
+		case float64:
+			valueFloat = v
+		case int64:
+			valueInt = v
+		case uint64:
+			if v > math.MaxInt64 {
+				valueFloat = float64(v)
+			} else {
+				valueInt = int64(v)
+			}
+		case bool:
+			if v {
+				valueInt = int64(1)
+			} else {
+				valueInt = int64(0)
+			}
+		case string:
+			valueString = v
+		default:
+			valueString = fmt.Sprintf("%v", v)
+		}
+
+		r := []bigquery.Value{m.Time()}
+		_, r = b.metadataSchemaAndValues(now, nil, r)
+		r = append(r, m.Name(), field.Key, valueFloat, valueInt, valueString, string(tags))
+		rows = append(rows, &bigquery.ValuesSaver{Schema: schema, Row: r})
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("metric %q has no fields", m.Name())
+	}
+
+	return rows, nil
+}
+
+// narrowSchema returns the schema of the narrow table
+func (b *BigQuery) narrowSchema() bigquery.Schema {
+	s := bigquery.Schema{timeStampFieldSchema(b.TimestampColumn)}
+	s, _ = b.metadataSchemaAndValues(time.Time{}, s, nil)
+	return append(s,
+		newStringFieldSchema("metric"),
+		newStringFieldSchema("field"),
+		&bigquery.FieldSchema{Name: "value_float", Type: bigquery.FloatFieldType},
+		&bigquery.FieldSchema{Name: "value_int", Type: bigquery.IntegerFieldType},
+		newStringFieldSchema("value_string"),
+		newJSONFieldSchema("tags"),
+	)
+}
+
+// metadataSchemaAndValues appends the configured metadata columns using the
+// given time as ingestion time
+func (b *BigQuery) metadataSchemaAndValues(now time.Time, s bigquery.Schema, r []bigquery.Value) ([]*bigquery.FieldSchema, []bigquery.Value) {
+	for _, column := range b.MetadataColumns {
+		switch column {
+		case metadataIngestTime:
+			s = append(s, timeStampFieldSchema(column))
+			r = append(r, now)
+		case metadataAgentHost:
+			s = append(s, newStringFieldSchema(column))
+			r = append(r, b.hostname)
+		case metadataTelegrafVersion:
+			s = append(s, newStringFieldSchema(column))
+			r = append(r, internal.Version)
+		}
+	}
+
+	return s, r
+}
+
+func timeStampFieldSchema(name string) *bigquery.FieldSchema {
 	return &bigquery.FieldSchema{
-		Name: timeStampFieldName,
+		Name: name,
 		Type: bigquery.TimestampFieldType,
 	}
 }
@@ -281,18 +879,176 @@ func valueToBqType(v interface{}) bigquery.FieldType {
 	}
 }
 
-func (b *BigQuery) insertToTable(metricName string, metrics []bigquery.ValueSaver) {
+func (b *BigQuery) insertToTable(tableName string, rows []bigquery.ValueSaver) error {
 	ctx := context.Background()
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(b.Timeout))
 	defer cancel()
 
-	tableName := b.metricToTable(metricName)
-	table := b.client.Dataset(b.Dataset).Table(tableName)
-	inserter := table.Inserter()
+	if !b.isSingleTable(tableName) {
+		if err := b.prepareTable(ctx, tableName, rowsSchema(rows)); err != nil {
+			b.Log.Errorf("Preparing table %q failed: %v", tableName, err)
+		}
+	}
+
+	// Always returns an instance, even if table doesn't exist (anymore).
+	inserter := b.client.Dataset(b.Dataset).Table(tableName).Inserter()
+	// Without skipping, BigQuery rejects the valid rows of a request along
+	// with the invalid ones, so they would end up in the dead-letter table
+	inserter.SkipInvalidRows = b.DeadLetterSuffix != ""
+
+	stats := b.tableStats(tableName)
+	start := time.Now()
+	err := inserter.Put(ctx, rows)
+	stats.insertTime.Incr(time.Since(start).Nanoseconds())
+	stats.inserts.Incr(1)
+	stats.rowsSent.Incr(int64(len(rows)))
+	if err != nil {
+		stats.insertErrors.Incr(1)
+
+		// Partial failures report an error for each rejected row while all
+		// rows failed for any other error
+		var rowErrs bigquery.PutMultiError
+		if errors.As(err, &rowErrs) {
+			stats.rowsFailed.Incr(int64(len(rowErrs)))
+			if b.DeadLetterSuffix != "" {
+				return b.quarantine(tableName, rows, rowErrs)
+			}
+		} else {
+			stats.rowsFailed.Incr(int64(len(rows)))
+		}
+	}
+
+	return err
+}
+
+// quarantine writes the rows rejected by BigQuery to the dead-letter table of
+// the given table together with the error and the row's JSON payload. Valid
+// rows not written due to the rejected rows are sent to the table again.
+func (b *BigQuery) quarantine(tableName string, rows []bigquery.ValueSaver, rowErrs bigquery.PutMultiError) error {
+	now := time.Now()
+	deadLetters := make([]bigquery.ValueSaver, 0, len(rowErrs))
+	var stopped []bigquery.ValueSaver
+	for _, rowErr := range rowErrs {
+		if rowErr.RowIndex < 0 || rowErr.RowIndex >= len(rows) {
+			continue
+		}
+		// Valid rows not written due to other rows being invalid must not
+		// be quarantined
+		if onlyStopped(rowErr.Errors) {
+			stopped = append(stopped, rows[rowErr.RowIndex])
+			continue
+		}
+		payload, err := rowPayload(rows[rowErr.RowIndex])
+		if err != nil {
+			b.Log.Warnf("Serializing payload of row rejected by table %q failed: %v", tableName, err)
+		}
+		deadLetters = append(deadLetters, &bigquery.ValuesSaver{
+			Schema: bigquery.Schema{
+				timeStampFieldSchema(timeStampFieldName),
+				newStringFieldSchema("table"),
+				newStringFieldSchema("error"),
+				newJSONFieldSchema("payload"),
+			},
+			Row: []bigquery.Value{now, tableName, rowErr.Errors.Error(), payload},
+		})
+	}
+	if len(deadLetters) == 0 {
+		return rowErrs
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(b.Timeout))
+	defer cancel()
+
+	deadLetterTable := tableName + b.DeadLetterSuffix
+	inserter := b.client.Dataset(b.Dataset).Table(deadLetterTable).Inserter()
+
+	stats := b.tableStats(deadLetterTable)
+	start := time.Now()
+	err := inserter.Put(ctx, deadLetters)
+	stats.insertTime.Incr(time.Since(start).Nanoseconds())
+	stats.inserts.Incr(1)
+	stats.rowsSent.Incr(int64(len(deadLetters)))
+	if err != nil {
+		stats.insertErrors.Incr(1)
+		stats.rowsFailed.Incr(int64(len(deadLetters)))
+		return fmt.Errorf("writing %d rejected rows to dead-letter table %q failed: %w (original error: %w)",
+			len(deadLetters), deadLetterTable, err, rowErrs)
+	}
+	b.Log.Warnf("Wrote %d rows rejected by table %q to dead-letter table %q", len(deadLetters), tableName, deadLetterTable)
 
-	if err := inserter.Put(ctx, metrics); err != nil {
-		b.Log.Errorf("inserting metric %q failed: %v", metricName, err)
+	// The rejected rows are removed from the request, so sending the
+	// remaining rows again terminates
+	if len(stopped) > 0 {
+		b.Log.Debugf("Sending %d rows stopped by rejected rows to table %q again", len(stopped), tableName)
+		if err := b.insertToTable(tableName, stopped); err != nil {
+			return fmt.Errorf("sending %d rows stopped by rejected rows failed: %w", len(stopped), err)
+		}
+	}
+
+	return nil
+}
+
+// onlyStopped returns true if the row was not rejected for itself but only
+// because of the other rows of the request
+func onlyStopped(errs bigquery.MultiError) bool {
+	if len(errs) == 0 {
+		return false
+	}
+	for _, err := range errs {
+		var bqErr *bigquery.Error
+		if !errors.As(err, &bqErr) || bqErr.Reason != "stopped" {
+			return false
+		}
 	}
+	return true
+}
+
+// rowPayload serializes the given row to JSON replacing the float values not
+// supported by JSON with their string representation
+func rowPayload(row bigquery.ValueSaver) (string, error) {
+	values, _, err := row.Save()
+	if err != nil {
+		return "", err
+	}
+	for k, v := range values {
+		if fv, ok := v.(float64); ok && (math.IsNaN(fv) || math.IsInf(fv, 0)) {
+			values[k] = strconv.FormatFloat(fv, 'f', -1, 64)
+		}
+	}
+	payload, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
+}
+
+// tableStats returns the internal statistics for the given table, registering
+// them on first use.
+func (b *BigQuery) tableStats(tableName string) *tableStats {
+	b.statsLock.Lock()
+	defer b.statsLock.Unlock()
+
+	if stats, found := b.stats[tableName]; found {
+		return stats
+	}
+
+	tags := map[string]string{
+		"project": b.client.Project(),
+		"dataset": b.Dataset,
+		"table":   tableName,
+	}
+	stats := &tableStats{
+		insertTime:   selfstat.RegisterTiming("bigquery", "insert_time_ns", tags),
+		inserts:      selfstat.Register("bigquery", "inserts", tags),
+		insertErrors: selfstat.Register("bigquery", "insert_errors", tags),
+		rowsSent:     selfstat.Register("bigquery", "rows_sent", tags),
+		rowsFailed:   selfstat.Register("bigquery", "rows_failed", tags),
+		retries:      selfstat.Register("bigquery", "retries", tags),
+	}
+	b.stats[tableName] = stats
+
+	return stats
 }
 
 func (b *BigQuery) metricToTable(metricName string) string {
@@ -312,14 +1068,19 @@ func (b *BigQuery) metricToTable(metricName string) string {
 
 // Close will terminate the session to the backend, returning error if an issue arises.
 func (b *BigQuery) Close() error {
+	b.cancel()
 	return b.client.Close()
 }
 
 func init() {
 	outputs.Add("bigquery", func() telegraf.Output {
 		return &BigQuery{
-			Timeout:         defaultTimeout,
-			ReplaceHyphenTo: "_",
+			Timeout:              defaultTimeout,
+			ReplaceHyphenTo:      "_",
+			MaxConcurrentInserts: defaultMaxConcurrentInserts,
+			MaxRowsPerInsert:     defaultMaxRowsPerInsert,
+			MaxRetries:           defaultMaxRetries,
+			RetryInterval:        defaultRetryInterval,
 		}
 	})
 }
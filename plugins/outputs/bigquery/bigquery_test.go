@@ -1,19 +1,29 @@
 package bigquery
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"cloud.google.com/go/bigquery"
 	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/wait"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 	"google.golang.org/api/option/internaloption"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -40,6 +50,64 @@ func TestInit(t *testing.T) {
 			errorString: `"dataset" is required`,
 			plugin:      &BigQuery{},
 		},
+		{
+			name:        "project is not set without authentication",
+			errorString: `"project" is required if authentication is disabled`,
+			plugin: &BigQuery{
+				Dataset:               "test-dataset",
+				DisableAuthentication: true,
+			},
+		},
+		{
+			name:        "invalid label key",
+			errorString: `invalid label key "Team"`,
+			plugin: &BigQuery{
+				Dataset: "test-dataset",
+				Labels:  map[string]string{"Team": "infra"},
+			},
+		},
+		{
+			name:        "invalid label value",
+			errorString: `invalid value "cost center" for label "cost_center"`,
+			plugin: &BigQuery{
+				Dataset: "test-dataset",
+				Labels:  map[string]string{"cost_center": "cost center"},
+			},
+		},
+		{
+			name:        "compact and narrow table",
+			errorString: `"compact_table" and "narrow_table" are mutually exclusive`,
+			plugin: &BigQuery{
+				Dataset:      "test-dataset",
+				CompactTable: "compact",
+				NarrowTable:  "narrow",
+			},
+		},
+		{
+			name:        "compact filter without compact table",
+			errorString: `"compact_include" and "compact_exclude" require "compact_table"`,
+			plugin: &BigQuery{
+				Dataset:        "test-dataset",
+				CompactInclude: []string{"cpu"},
+			},
+		},
+		{
+			name:        "KMS key without creating tables",
+			errorString: `"kms_key_name" requires "create_tables"`,
+			plugin: &BigQuery{
+				Dataset:    "test-dataset",
+				KMSKeyName: "projects/p/locations/eu/keyRings/r/cryptoKeys/k",
+			},
+		},
+		{
+			name:        "invalid KMS key",
+			errorString: `invalid KMS key name "projects/p/keyRings/r/cryptoKeys/k"`,
+			plugin: &BigQuery{
+				Dataset:      "test-dataset",
+				CreateTables: true,
+				KMSKeyName:   "projects/p/keyRings/r/cryptoKeys/k",
+			},
+		},
 		{
 			name: "valid config",
 			plugin: &BigQuery{
@@ -179,6 +247,132 @@ func TestWrite(t *testing.T) {
 	require.InDelta(t, mockMetrics[0].Fields()["value"], row.Value, testutil.DefaultDelta)
 }
 
+func TestWriteBatching(t *testing.T) {
+	var mu sync.Mutex
+	var active, maxActive int
+	requests := make(map[string]int)
+	rows := make(map[string]int)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+
+		var body struct {
+			Rows []json.RawMessage `json:"rows"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		requests[r.URL.Path]++
+		rows[r.URL.Path] += len(body.Rows)
+		mu.Unlock()
+
+		if _, err := w.Write([]byte(successfulResponse)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:              "test-project",
+		Dataset:              "test-dataset",
+		Timeout:              defaultTimeout,
+		MaxConcurrentInserts: 2,
+		MaxRowsPerInsert:     2,
+		Log:                  testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+
+	metrics := make([]telegraf.Metric, 0, 8)
+	for i := range 5 {
+		metrics = append(metrics, testutil.TestMetric(i, "cpu"))
+	}
+	for i := range 3 {
+		metrics = append(metrics, testutil.TestMetric(i, "mem"))
+	}
+	require.NoError(t, b.Write(metrics))
+
+	cpuPath := "/projects/test-project/datasets/test-dataset/tables/cpu/insertAll"
+	memPath := "/projects/test-project/datasets/test-dataset/tables/mem/insertAll"
+	require.Equal(t, map[string]int{cpuPath: 3, memPath: 2}, requests)
+	require.Equal(t, map[string]int{cpuPath: 5, memPath: 3}, rows)
+	require.LessOrEqual(t, maxActive, 2)
+}
+
+func TestWriteStats(t *testing.T) {
+	// Use a separate dataset as the statistics are registered globally
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response string
+		switch r.URL.Path {
+		case "/projects/test-project/datasets/stats-dataset/tables/cpu/insertAll":
+			// Reject the first row of each request
+			response = `{"kind": "bigquery#tableDataInsertAllResponse", "insertErrors": [{"index": 0, "errors": [{"reason": "invalid"}]}]}`
+		case "/projects/test-project/datasets/stats-dataset/tables/mem/insertAll":
+			response = successfulResponse
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:          "test-project",
+		Dataset:          "stats-dataset",
+		Timeout:          defaultTimeout,
+		MaxRowsPerInsert: 2,
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+
+	metrics := make([]telegraf.Metric, 0, 6)
+	for i := range 3 {
+		metrics = append(metrics, testutil.TestMetric(i, "cpu"))
+	}
+	for i := range 2 {
+		metrics = append(metrics, testutil.TestMetric(i, "mem"))
+	}
+	metrics = append(metrics, testutil.TestMetric(0, "disk"))
+	require.NoError(t, b.Write(metrics))
+
+	expected := map[string]map[string]int64{
+		"cpu":  {"inserts": 2, "insert_errors": 2, "rows_sent": 3, "rows_failed": 2},
+		"mem":  {"inserts": 1, "insert_errors": 0, "rows_sent": 2, "rows_failed": 0},
+		"disk": {"inserts": 1, "insert_errors": 1, "rows_sent": 1, "rows_failed": 1},
+	}
+	for table, fields := range expected {
+		stats := b.stats[table]
+		require.NotNil(t, stats, table)
+		actual := map[string]int64{
+			"inserts":       stats.inserts.Get(),
+			"insert_errors": stats.insertErrors.Get(),
+			"rows_sent":     stats.rowsSent.Get(),
+			"rows_failed":   stats.rowsFailed.Get(),
+		}
+		require.Equal(t, fields, actual, table)
+		require.Equal(t, "test-project", stats.inserts.Tags()["project"])
+	}
+}
+
 func TestWriteCompact(t *testing.T) {
 	srv := localBigQueryServer(t)
 	defer srv.Close()
@@ -215,6 +409,248 @@ func TestWriteCompact(t *testing.T) {
 	require.NoError(t, b.Close())
 }
 
+func TestWriteCompactFilter(t *testing.T) {
+	var mu sync.Mutex
+	names := make(map[string][]string)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Rows []struct {
+				JSON map[string]interface{} `json:"json"`
+			} `json:"rows"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+
+		mu.Lock()
+		for _, row := range body.Rows {
+			name, _ := row.JSON["name"].(string)
+			names[r.URL.Path] = append(names[r.URL.Path], name)
+		}
+		mu.Unlock()
+
+		if _, err := w.Write([]byte(successfulResponse)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:        "test-project",
+		Dataset:        "test-dataset",
+		Timeout:        defaultTimeout,
+		CompactTable:   "compact",
+		CompactInclude: []string{"docker*"},
+		CompactExclude: []string{"docker_container_blkio"},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+
+	metrics := []telegraf.Metric{
+		testutil.TestMetric(1, "docker"),
+		testutil.TestMetric(2, "cpu"),
+		testutil.TestMetric(3, "docker_container_mem"),
+		testutil.TestMetric(4, "docker_container_blkio"),
+	}
+	require.NoError(t, b.Write(metrics))
+
+	// Rows of per-measurement tables have no name column
+	require.Equal(t, map[string][]string{
+		"/projects/test-project/datasets/test-dataset/tables/compact/insertAll":                {"docker", "docker_container_mem"},
+		"/projects/test-project/datasets/test-dataset/tables/cpu/insertAll":                    {""},
+		"/projects/test-project/datasets/test-dataset/tables/docker_container_blkio/insertAll": {""},
+	}, names)
+}
+
+func TestWriteNarrow(t *testing.T) {
+	srv := localBigQueryServer(t)
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:     "test-project",
+		Dataset:     "test-dataset",
+		Timeout:     defaultTimeout,
+		NarrowTable: "test-metrics",
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+
+	m := metric.New(
+		"test1",
+		map[string]string{"tag1": "value1"},
+		map[string]interface{}{
+			"float":  1.5,
+			"int":    int64(-2),
+			"uint":   uint64(3),
+			"bool":   true,
+			"string": "foo",
+		},
+		time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, b.Write([]telegraf.Metric{m}))
+
+	var rows []map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(receivedBody["rows"], &rows))
+	require.Len(t, rows, 5)
+
+	expected := map[string]struct {
+		column string
+		value  interface{}
+	}{
+		"float":  {"value_float", 1.5},
+		"int":    {"value_int", -2.0},
+		"uint":   {"value_int", 3.0},
+		"bool":   {"value_int", 1.0},
+		"string": {"value_string", "foo"},
+	}
+	for _, raw := range rows {
+		var row map[string]interface{}
+		require.NoError(t, json.Unmarshal(raw["json"], &row))
+		require.Equal(t, "2009-11-10T23:00:00Z", row["timestamp"])
+		require.Equal(t, "test1", row["metric"])
+		require.Equal(t, `{"tag1":"value1"}`, row["tags"])
+
+		field, ok := row["field"].(string)
+		require.True(t, ok)
+		e, found := expected[field]
+		require.True(t, found, field)
+		for _, column := range []string{"value_float", "value_int", "value_string"} {
+			if column == e.column {
+				require.EqualValues(t, e.value, row[column], field)
+			} else {
+				require.Nil(t, row[column], field)
+			}
+		}
+	}
+
+	require.NoError(t, b.Close())
+}
+
+func TestWriteNarrowKeepMetricOfFailedRow(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/projects/test-project/datasets/test-dataset/tables/narrow/insertAll" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if bytes.Contains(body, []byte(`"field":"d"`)) {
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(`{"error": {"code": 400, "message": "invalid"}}`)); err != nil {
+				t.Error(err)
+			}
+			return
+		}
+		if _, err := w.Write([]byte(successfulResponse)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:          "test-project",
+		Dataset:          "test-dataset",
+		Timeout:          defaultTimeout,
+		NarrowTable:      "narrow",
+		MaxRowsPerInsert: 2,
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+
+	// The rows of the second metric are split across two inserts with the
+	// second one failing
+	metrics := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"a": 1.0}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"b": 2.0, "c": 3.0}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"d": 4.0}, time.Unix(0, 0)),
+	}
+	err := b.Write(metrics)
+
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Equal(t, []int{0}, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+}
+
+func TestWriteMetadataColumns(t *testing.T) {
+	srv := localBigQueryServer(t)
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:         "test-project",
+		Dataset:         "test-dataset",
+		Timeout:         defaultTimeout,
+		TimestampColumn: "time",
+		MetadataColumns: []string{"ingest_time", "agent_host", "telegraf_version"},
+	}
+
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+
+	require.NoError(t, b.Write(testutil.MockMetrics()))
+
+	var rows []map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(receivedBody["rows"], &rows))
+	require.Len(t, rows, 1)
+
+	var row map[string]interface{}
+	require.NoError(t, json.Unmarshal(rows[0]["json"], &row))
+	require.NotContains(t, row, "timestamp")
+	require.Equal(t, "2009-11-10T23:00:00Z", row["time"])
+	require.Contains(t, row, "ingest_time")
+	require.Equal(t, b.hostname, row["agent_host"])
+	require.Equal(t, internal.Version, row["telegraf_version"])
+	require.Equal(t, "value1", row["tag1"])
+
+	require.NoError(t, b.Close())
+}
+
+func TestInitMetadataColumnsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *BigQuery
+		expected string
+	}{
+		{
+			name:     "unknown column",
+			plugin:   &BigQuery{Dataset: "test-dataset", MetadataColumns: []string{"foo"}},
+			expected: `invalid metadata column "foo"`,
+		},
+		{
+			name:     "duplicate column",
+			plugin:   &BigQuery{Dataset: "test-dataset", MetadataColumns: []string{"agent_host", "agent_host"}},
+			expected: `duplicate column "agent_host"`,
+		},
+		{
+			name: "timestamp column collision",
+			plugin: &BigQuery{
+				Dataset:         "test-dataset",
+				TimestampColumn: "ingest_time",
+				MetadataColumns: []string{"ingest_time"},
+			},
+			expected: `duplicate column "ingest_time"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
 func TestAutoDetect(t *testing.T) {
 	srv := localBigQueryServer(t)
 	defer srv.Close()
@@ -231,6 +667,85 @@ func TestAutoDetect(t *testing.T) {
 	require.NoError(t, b.Close())
 }
 
+func TestEndpoint(t *testing.T) {
+	srv := localBigQueryServer(t)
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:               "test-project",
+		Dataset:               "test-dataset",
+		Endpoint:              srv.URL,
+		DisableAuthentication: true,
+		Timeout:               defaultTimeout,
+		Log:                   testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.Connect())
+
+	receivedBody = nil
+	require.NoError(t, b.Write(testutil.MockMetrics()))
+	require.Contains(t, receivedBody, "rows")
+	require.NoError(t, b.Close())
+}
+
+func TestEmulatorIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	container := testutil.Container{
+		Image:        "ghcr.io/goccy/bigquery-emulator:0.6.6",
+		ExposedPorts: []string{"9050"},
+		Cmd:          []string{"--project=test-project", "--dataset=test-dataset"},
+		WaitingFor:   wait.ForListeningPort("9050"),
+	}
+	require.NoError(t, container.Start(), "failed to start container")
+	defer container.Terminate()
+
+	plugin := &BigQuery{
+		Project:               "test-project",
+		Dataset:               "test-dataset",
+		Endpoint:              "http://" + container.Address + ":" + container.Ports["9050"],
+		DisableAuthentication: true,
+		Timeout:               defaultTimeout,
+		Log:                   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Create the table for the metrics
+	ctx := context.Background()
+	schema := bigquery.Schema{
+		{Name: "timestamp", Type: bigquery.TimestampFieldType},
+		{Name: "tag1", Type: bigquery.StringFieldType},
+		{Name: "value", Type: bigquery.FloatFieldType},
+	}
+	table := plugin.client.Dataset("test-dataset").Table("test1")
+	require.NoError(t, table.Create(ctx, &bigquery.TableMetadata{Schema: schema}))
+
+	metrics := []telegraf.Metric{
+		metric.New("test1", map[string]string{"tag1": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0)),
+		metric.New("test1", map[string]string{"tag1": "b"}, map[string]interface{}{"value": 2.0}, time.Unix(1700000001, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// Read back the inserted rows
+	it := table.Read(ctx)
+	actual := make(map[string]float64)
+	for {
+		var row []bigquery.Value
+		err := it.Next(&row)
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		require.NoError(t, err)
+		require.Len(t, row, 3)
+		actual[row[1].(string)] = row[2].(float64)
+	}
+	require.Equal(t, map[string]float64{"a": 1.0, "b": 2.0}, actual)
+}
+
 func (b *BigQuery) setUpTestClient(endpointURL string) error {
 	noAuth := option.WithoutAuthentication()
 	endpoint := option.WithEndpoint(endpointURL)
@@ -303,3 +818,584 @@ func localBigQueryServer(t *testing.T) *httptest.Server {
 
 	return srv
 }
+
+func TestWriteDeadLetter(t *testing.T) {
+	var deadLetterBody map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response string
+		switch r.URL.Path {
+		case "/projects/test-project/datasets/deadletter-dataset/tables/cpu/insertAll":
+			// Reject the second row
+			response = `{"kind": "bigquery#tableDataInsertAllResponse", "insertErrors": [{"index": 1, "errors": [{"reason": "invalid", "message": "no such field"}]}]}`
+		case "/projects/test-project/datasets/deadletter-dataset/tables/cpu_errors/insertAll":
+			if err := json.NewDecoder(r.Body).Decode(&deadLetterBody); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			response = successfulResponse
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:          "test-project",
+		Dataset:          "deadletter-dataset",
+		Timeout:          defaultTimeout,
+		DeadLetterSuffix: "_errors",
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0).UTC()),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": "invalid"}, time.Unix(0, 0).UTC()),
+	}
+	require.NoError(t, b.Write(metrics))
+
+	var rows []map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(deadLetterBody["rows"], &rows))
+	require.Len(t, rows, 1)
+
+	var row map[string]interface{}
+	require.NoError(t, json.Unmarshal(rows[0]["json"], &row))
+	require.Equal(t, "cpu", row["table"])
+	require.Contains(t, row["error"], "no such field")
+	require.Contains(t, row, "timestamp")
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(row["payload"].(string)), &payload))
+	require.Equal(t, map[string]interface{}{
+		"timestamp": "1970-01-01T00:00:00Z",
+		"host":      "b",
+		"value":     "invalid",
+	}, payload)
+
+	require.Equal(t, int64(1), b.stats["cpu"].rowsFailed.Get())
+	require.Equal(t, int64(1), b.stats["cpu_errors"].rowsSent.Get())
+}
+
+func TestWriteDeadLetterMixedBatch(t *testing.T) {
+	var written []string
+	var deadLetterBody map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response string
+		switch r.URL.Path {
+		case "/projects/test-project/datasets/deadletter-dataset/tables/cpu/insertAll":
+			var body struct {
+				SkipInvalidRows bool `json:"skipInvalidRows"`
+				Rows            []struct {
+					JSON map[string]interface{} `json:"json"`
+				} `json:"rows"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			// Mimic BigQuery stopping the valid rows unless skipping the
+			// invalid ones
+			if !body.SkipInvalidRows {
+				response = `{"kind": "bigquery#tableDataInsertAllResponse", "insertErrors": [` +
+					`{"index": 0, "errors": [{"reason": "stopped"}]},` +
+					`{"index": 1, "errors": [{"reason": "invalid", "message": "no such field"}]},` +
+					`{"index": 2, "errors": [{"reason": "stopped"}]}]}`
+				break
+			}
+			for i, row := range body.Rows {
+				if i != 1 {
+					written = append(written, row.JSON["host"].(string))
+				}
+			}
+			response = `{"kind": "bigquery#tableDataInsertAllResponse", "insertErrors": [{"index": 1, "errors": [{"reason": "invalid", "message": "no such field"}]}]}`
+		case "/projects/test-project/datasets/deadletter-dataset/tables/cpu_errors/insertAll":
+			if err := json.NewDecoder(r.Body).Decode(&deadLetterBody); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			response = successfulResponse
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:          "test-project",
+		Dataset:          "deadletter-dataset",
+		Timeout:          defaultTimeout,
+		DeadLetterSuffix: "_errors",
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0).UTC()),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": "invalid"}, time.Unix(0, 0).UTC()),
+		metric.New("cpu", map[string]string{"host": "c"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0).UTC()),
+	}
+	require.NoError(t, b.Write(metrics))
+
+	// The valid rows land in the main table and only the invalid one is
+	// quarantined
+	require.Equal(t, []string{"a", "c"}, written)
+	var rows []map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(deadLetterBody["rows"], &rows))
+	require.Len(t, rows, 1)
+	require.Equal(t, int64(1), b.stats["cpu"].rowsFailed.Get())
+}
+
+func TestWriteDeadLetterResendStopped(t *testing.T) {
+	var requests int
+	var written []string
+	var deadLetterBody map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response string
+		switch r.URL.Path {
+		case "/projects/test-project/datasets/deadletter-dataset/tables/cpu/insertAll":
+			var body struct {
+				Rows []struct {
+					JSON map[string]interface{} `json:"json"`
+				} `json:"rows"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			// Stop the valid rows of the first request despite skipping the
+			// invalid ones and accept all rows sent again
+			requests++
+			if requests == 1 {
+				response = `{"kind": "bigquery#tableDataInsertAllResponse", "insertErrors": [` +
+					`{"index": 0, "errors": [{"reason": "stopped"}]},` +
+					`{"index": 1, "errors": [{"reason": "invalid", "message": "no such field"}]},` +
+					`{"index": 2, "errors": [{"reason": "stopped"}]}]}`
+				break
+			}
+			for _, row := range body.Rows {
+				written = append(written, row.JSON["host"].(string))
+			}
+			response = successfulResponse
+		case "/projects/test-project/datasets/deadletter-dataset/tables/cpu_errors/insertAll":
+			if err := json.NewDecoder(r.Body).Decode(&deadLetterBody); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			response = successfulResponse
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:          "test-project",
+		Dataset:          "deadletter-dataset",
+		Timeout:          defaultTimeout,
+		DeadLetterSuffix: "_errors",
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0).UTC()),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": "invalid"}, time.Unix(0, 0).UTC()),
+		metric.New("cpu", map[string]string{"host": "c"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0).UTC()),
+	}
+	require.NoError(t, b.Write(metrics))
+
+	// The stopped rows are sent again while only the invalid one is
+	// quarantined
+	require.Equal(t, 2, requests)
+	require.Equal(t, []string{"a", "c"}, written)
+	var rows []map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(deadLetterBody["rows"], &rows))
+	require.Len(t, rows, 1)
+}
+
+func TestWriteCreateTablesWithLabels(t *testing.T) {
+	var mu sync.Mutex
+	var created, updated map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var response string
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/test-project/datasets/labels-dataset/tables/cpu":
+			w.WriteHeader(http.StatusNotFound)
+			response = `{"error": {"code": 404, "message": "Not found: Table cpu"}}`
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/test-project/datasets/labels-dataset/tables":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			response = `{"tableReference": {"projectId": "test-project", "datasetId": "labels-dataset", "tableId": "cpu"}}`
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/test-project/datasets/labels-dataset/tables/mem":
+			response = `{"etag": "abc", "labels": {"team": "infra", "cost_center": "old"}}`
+		case r.Method == http.MethodPatch && r.URL.Path == "/projects/test-project/datasets/labels-dataset/tables/mem":
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			response = `{}`
+		case r.URL.Path == "/projects/test-project/datasets/labels-dataset/tables/cpu/insertAll",
+			r.URL.Path == "/projects/test-project/datasets/labels-dataset/tables/mem/insertAll":
+			response = successfulResponse
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:      "test-project",
+		Dataset:      "labels-dataset",
+		Timeout:      defaultTimeout,
+		CreateTables: true,
+		Labels:       map[string]string{"team": "infra", "cost_center": "cc-42"},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"count": int64(1)}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"free": int64(1)}, time.Unix(0, 0)),
+	}
+	require.NoError(t, b.Write(metrics))
+	// The tables are only prepared once
+	require.NoError(t, b.Write(metrics))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The missing table is created using the schema of all rows
+	require.Equal(t, map[string]interface{}{"team": "infra", "cost_center": "cc-42"}, created["labels"])
+	schema, ok := created["schema"].(map[string]interface{})
+	require.True(t, ok)
+	columns := make(map[string]string)
+	for _, f := range schema["fields"].([]interface{}) {
+		field := f.(map[string]interface{})
+		columns[field["name"].(string)] = field["type"].(string)
+		require.NotEqual(t, "REQUIRED", field["mode"])
+	}
+	require.Equal(t, map[string]string{
+		"timestamp": "TIMESTAMP",
+		"host":      "STRING",
+		"value":     "FLOAT",
+		"count":     "INTEGER",
+	}, columns)
+
+	// Only the changed labels of the existing table are updated
+	require.Equal(t, map[string]interface{}{"cost_center": "cc-42"}, updated["labels"])
+}
+
+func TestWriteCreateTablesConflict(t *testing.T) {
+	var mu sync.Mutex
+	var lookups int
+	var updated map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var response string
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/test-project/datasets/conflict-dataset/tables/cpu":
+			lookups++
+			if lookups == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				response = `{"error": {"code": 404, "message": "Not found: Table cpu"}}`
+				break
+			}
+			response = `{"etag": "abc", "labels": {"team": "other"}}`
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/test-project/datasets/conflict-dataset/tables":
+			// Another writer created the table in the meantime
+			w.WriteHeader(http.StatusConflict)
+			response = `{"error": {"code": 409, "message": "Already Exists: Table cpu"}}`
+		case r.Method == http.MethodPatch && r.URL.Path == "/projects/test-project/datasets/conflict-dataset/tables/cpu":
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			response = `{}`
+		case r.URL.Path == "/projects/test-project/datasets/conflict-dataset/tables/cpu/insertAll":
+			response = successfulResponse
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	logger := &testutil.CaptureLogger{}
+	b := &BigQuery{
+		Project:      "test-project",
+		Dataset:      "conflict-dataset",
+		Timeout:      defaultTimeout,
+		CreateTables: true,
+		KMSKeyName:   "projects/test-project/locations/eu/keyRings/telegraf/cryptoKeys/metrics",
+		Labels:       map[string]string{"team": "infra"},
+		Log:          logger,
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, b.Write(metrics))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The concurrently created table is looked up again and its labels updated
+	require.Equal(t, 2, lookups)
+	require.Equal(t, map[string]interface{}{"team": "infra"}, updated["labels"])
+	require.Equal(t, []string{
+		`W! [] Existing table "cpu" is not encrypted with the configured KMS key`,
+	}, logger.Warnings())
+}
+
+func TestWriteCreateTablesWithKMSKey(t *testing.T) {
+	const key = "projects/test-project/locations/eu/keyRings/telegraf/cryptoKeys/metrics"
+
+	var mu sync.Mutex
+	var created map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var response string
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/test-project/datasets/kms-dataset/tables/cpu":
+			w.WriteHeader(http.StatusNotFound)
+			response = `{"error": {"code": 404, "message": "Not found: Table cpu"}}`
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/test-project/datasets/kms-dataset/tables":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			response = `{"tableReference": {"projectId": "test-project", "datasetId": "kms-dataset", "tableId": "cpu"}}`
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/test-project/datasets/kms-dataset/tables/mem":
+			response = `{"etag": "abc"}`
+		case r.URL.Path == "/projects/test-project/datasets/kms-dataset/tables/cpu/insertAll",
+			r.URL.Path == "/projects/test-project/datasets/kms-dataset/tables/mem/insertAll":
+			response = successfulResponse
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	logger := &testutil.CaptureLogger{}
+	b := &BigQuery{
+		Project:      "test-project",
+		Dataset:      "kms-dataset",
+		Timeout:      defaultTimeout,
+		CreateTables: true,
+		KMSKeyName:   key,
+		Log:          logger,
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"free": int64(1)}, time.Unix(0, 0)),
+	}
+	require.NoError(t, b.Write(metrics))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The missing table is created with the key
+	require.Equal(t, map[string]interface{}{"kmsKeyName": key}, created["encryptionConfiguration"])
+
+	// Existing tables not using the key are reported
+	require.Equal(t, []string{
+		`W! [] Existing table "mem" is not encrypted with the configured KMS key`,
+	}, logger.Warnings())
+}
+
+func TestWriteSplitOnRequestTooLarge(t *testing.T) {
+	var mu sync.Mutex
+	var requests []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Rows []json.RawMessage `json:"rows"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+
+		mu.Lock()
+		requests = append(requests, len(body.Rows))
+		mu.Unlock()
+
+		// Only accept requests with at most two rows
+		if len(body.Rows) > 2 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			if _, err := w.Write([]byte(`{"error": {"code": 413, "message": "request too large"}}`)); err != nil {
+				t.Error(err)
+			}
+			return
+		}
+		if _, err := w.Write([]byte(successfulResponse)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:       "test-project",
+		Dataset:       "test-dataset",
+		Timeout:       defaultTimeout,
+		MaxRetries:    3,
+		RetryInterval: config.Duration(time.Millisecond),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+
+	metrics := make([]telegraf.Metric, 0, 5)
+	for i := range 5 {
+		metrics = append(metrics, testutil.TestMetric(i, "cpu"))
+	}
+	require.NoError(t, b.Write(metrics))
+
+	// The batch is split into halves until the requests are accepted
+	require.Equal(t, []int{5, 2, 3, 1, 2}, requests)
+	require.Equal(t, int64(2), b.tableStats("cpu").retries.Get())
+}
+
+func TestWriteRetryAbortedOnClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		if _, err := w.Write([]byte(`{"error": {"code": 429, "message": "quota exceeded"}}`)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:       "test-project",
+		Dataset:       "test-dataset",
+		Timeout:       defaultTimeout,
+		CompactTable:  "compact",
+		MaxRetries:    3,
+		RetryInterval: config.Duration(time.Hour),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- b.Write([]telegraf.Metric{testutil.TestMetric(1, "cpu")})
+	}()
+
+	// Closing the plugin must not wait for the retry interval to pass
+	require.Eventually(t, func() bool {
+		return b.tableStats("compact").inserts.Get() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, b.Close())
+	select {
+	case err := <-errs:
+		require.ErrorContains(t, err, "retrying aborted")
+		require.Zero(t, b.tableStats("compact").retries.Get())
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "write did not return after closing the plugin")
+	}
+}
+
+func TestWriteKeepPersistentFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects/test-project/datasets/test-dataset/tables/cpu/insertAll":
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			if _, err := w.Write([]byte(`{"error": {"code": 413, "message": "request too large"}}`)); err != nil {
+				t.Error(err)
+			}
+		case "/projects/test-project/datasets/test-dataset/tables/mem/insertAll":
+			if _, err := w.Write([]byte(successfulResponse)); err != nil {
+				t.Error(err)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	b := &BigQuery{
+		Project:       "test-project",
+		Dataset:       "test-dataset",
+		Timeout:       defaultTimeout,
+		MaxRetries:    2,
+		RetryInterval: config.Duration(time.Millisecond),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, b.Init())
+	require.NoError(t, b.setUpTestClient(srv.URL))
+	require.NoError(t, b.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.TestMetric(0, "cpu"),
+		testutil.TestMetric(1, "mem"),
+		testutil.TestMetric(2, "cpu"),
+		testutil.TestMetric(3, "disk"),
+	}
+	err := b.Write(metrics)
+
+	// The metrics of the persistently failing table are kept in the buffer
+	// while the others are accepted, including the ones of the missing table
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.ElementsMatch(t, []int{1, 3}, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+}
@@ -30,6 +30,7 @@ var sampleConfig string
 
 type Elasticsearch struct {
 	AuthBearerToken     config.Secret          `toml:"auth_bearer_token"`
+	DataStream          bool                   `toml:"data_stream"`
 	DefaultPipeline     string                 `toml:"default_pipeline"`
 	DefaultTagValue     string                 `toml:"default_tag_value"`
 	EnableGzip          bool                   `toml:"enable_gzip"`
@@ -41,6 +42,7 @@ type Elasticsearch struct {
 	HealthCheckTimeout  config.Duration        `toml:"health_check_timeout"`
 	IndexName           string                 `toml:"index_name"`
 	IndexTemplate       map[string]interface{} `toml:"template_index_settings"`
+	ILMPolicy           string                 `toml:"ilm_policy"`
 	ManageTemplate      bool                   `toml:"manage_template"`
 	OverwriteTemplate   bool                   `toml:"overwrite_template"`
 	UseOpTypeCreate     bool                   `toml:"use_optype_create"`
@@ -126,6 +128,63 @@ const telegrafTemplate = `
 	}
 }`
 
+const telegrafDataStreamTemplate = `
+{
+	"index_patterns" : [ "{{.TemplatePattern}}" ],
+	"data_stream": {},
+	"priority": 200,
+	"template": {
+		"settings": {
+			"index": {{.IndexTemplate}}
+		},
+		"mappings" : {
+			"properties" : {
+				"@timestamp" : { "type" : "date" },
+				"measurement_name" : { "type" : "keyword", "time_series_dimension": true }
+			},
+			"dynamic_templates": [
+				{
+					"tags": {
+						"match_mapping_type": "string",
+						"path_match": "tag.*",
+						"mapping": {
+							"ignore_above": 512,
+							"type": "keyword",
+							"time_series_dimension": true
+						}
+					}
+				},
+				{
+					"metrics_long": {
+						"match_mapping_type": "long",
+						"mapping": {
+							"type": "float",
+							"index": false
+						}
+					}
+				},
+				{
+					"metrics_double": {
+						"match_mapping_type": "double",
+						"mapping": {
+							"type": "float",
+							"index": false
+						}
+					}
+				},
+				{
+					"text_fields": {
+						"match": "*",
+						"mapping": {
+							"norms": false
+						}
+					}
+				}
+			]
+		}
+	}
+}`
+
 const defaultTemplateIndexSettings = `
 {
 	"refresh_interval": "10s",
@@ -149,6 +208,10 @@ func (a *Elasticsearch) Connect() error {
 		return errors.New("elasticsearch urls or index_name is not defined")
 	}
 
+	if a.DataStream && strings.Contains(a.IndexName, "%") {
+		return errors.New("date specifiers in index_name are not supported for data streams")
+	}
+
 	// Determine if we should process NaN and inf values
 	switch a.FloatHandling {
 	case "", "none":
@@ -230,11 +293,20 @@ func (a *Elasticsearch) Connect() error {
 
 	a.Log.Infof("Elasticsearch version: %q", esVersion)
 
+	if a.DataStream && majorReleaseNumber < 8 {
+		return fmt.Errorf("data streams require elasticsearch 8 or later but found %s", esVersion)
+	}
+
 	a.Client = client
 	a.majorReleaseNumber = majorReleaseNumber
 
 	if a.ManageTemplate {
-		err := a.manageTemplate(ctx)
+		var err error
+		if a.DataStream {
+			err = a.manageDataStreamTemplate(ctx)
+		} else {
+			err = a.manageTemplate(ctx)
+		}
 		if err != nil {
 			return err
 		}
@@ -307,6 +379,10 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 		// index name has to be re-evaluated each time for telegraf
 		// to send the metric to the correct time-based index
 		indexName := a.GetIndexName(a.IndexName, metric.Time(), a.tagKeys, metric.Tags())
+		if a.DataStream {
+			// Data stream names must be lowercase
+			indexName = strings.ToLower(indexName)
+		}
 
 		// Handle NaN and inf field-values
 		fields := make(map[string]interface{})
@@ -336,7 +412,8 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 
 		br := elastic.NewBulkIndexRequest().Index(indexName).Doc(m)
 
-		if a.UseOpTypeCreate {
+		// Data streams only accept the "create" OpType
+		if a.UseOpTypeCreate || a.DataStream {
 			br.OpType("create")
 		}
 
@@ -396,18 +473,9 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 		return fmt.Errorf("elasticsearch template check failed, template name: %s, error: %w", a.TemplateName, errExists)
 	}
 
-	templatePattern := a.IndexName
-
-	if strings.Contains(templatePattern, "%") {
-		templatePattern = templatePattern[0:strings.Index(templatePattern, "%")]
-	}
-
-	if strings.Contains(templatePattern, "{{") {
-		templatePattern = templatePattern[0:strings.Index(templatePattern, "{{")]
-	}
-
-	if templatePattern == "" {
-		return errors.New("template cannot be created for dynamic index names without an index prefix")
+	templatePattern, err := a.templatePattern()
+	if err != nil {
+		return err
 	}
 
 	if (a.OverwriteTemplate) || (!templateExists) || (templatePattern != "") {
@@ -428,6 +496,110 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 	return nil
 }
 
+// manageDataStreamTemplate installs a composable index template creating
+// time series data streams (TSDS) for the configured data stream names.
+func (a *Elasticsearch) manageDataStreamTemplate(ctx context.Context) error {
+	if a.TemplateName == "" {
+		return errors.New("elasticsearch template_name configuration not defined")
+	}
+
+	path := "/_index_template/" + url.PathEscape(a.TemplateName)
+	res, err := a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method:       "HEAD",
+		Path:         path,
+		IgnoreErrors: []int{http.StatusNotFound},
+	})
+	if err != nil {
+		return fmt.Errorf("elasticsearch template check failed, template name: %s, error: %w", a.TemplateName, err)
+	}
+	if res.StatusCode == http.StatusOK && !a.OverwriteTemplate {
+		a.Log.Debug("Found existing Elasticsearch data stream template. Skipping template management")
+		return nil
+	}
+
+	templatePattern, err := a.templatePattern()
+	if err != nil {
+		return err
+	}
+	data, err := a.createDataStreamTemplate(templatePattern)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Body:   data.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("elasticsearch failed to create data stream template %s: %w", a.TemplateName, err)
+	}
+
+	a.Log.Debugf("Data stream template %s created or updated", a.TemplateName)
+	return nil
+}
+
+// templatePattern returns the static prefix of the index name used to match
+// the indices or data streams of the template.
+func (a *Elasticsearch) templatePattern() (string, error) {
+	templatePattern := a.IndexName
+
+	if strings.Contains(templatePattern, "%") {
+		templatePattern = templatePattern[0:strings.Index(templatePattern, "%")]
+	}
+
+	if strings.Contains(templatePattern, "{{") {
+		templatePattern = templatePattern[0:strings.Index(templatePattern, "{{")]
+	}
+
+	if templatePattern == "" {
+		return "", errors.New("template cannot be created for dynamic index names without an index prefix")
+	}
+	return templatePattern, nil
+}
+
+func (a *Elasticsearch) createDataStreamTemplate(templatePattern string) (*bytes.Buffer, error) {
+	settings := a.IndexTemplate
+	if settings == nil {
+		if err := json.Unmarshal([]byte(defaultTemplateIndexSettings), &settings); err != nil {
+			return nil, err
+		}
+	} else {
+		// Do not modify the configured settings
+		custom := make(map[string]interface{}, len(settings))
+		for k, v := range settings {
+			custom[k] = v
+		}
+		settings = custom
+	}
+
+	// Use the metric name and tags as dimensions of the time series
+	settings["mode"] = "time_series"
+	settings["routing_path"] = []string{"measurement_name", "tag.*"}
+	if a.ILMPolicy != "" {
+		settings["lifecycle.name"] = a.ILMPolicy
+	}
+
+	indexTemplate, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch failed to create index settings for template %s: %w", a.TemplateName, err)
+	}
+
+	tp := templatePart{
+		TemplatePattern: templatePattern + "*",
+		Version:         a.majorReleaseNumber,
+		IndexTemplate:   string(indexTemplate),
+	}
+
+	t := template.Must(template.New("template").Parse(telegrafDataStreamTemplate))
+	var tmpl bytes.Buffer
+
+	if err := t.Execute(&tmpl, tp); err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
 func (a *Elasticsearch) createNewTemplate(templatePattern string) (*bytes.Buffer, error) {
 	var indexTemplate string
 	if a.IndexTemplate != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
 	"net/http/httptest"
@@ -972,6 +973,117 @@ func TestProcessHeaders(t *testing.T) {
 	}
 }
 
+func TestDataStreamTemplate(t *testing.T) {
+	e := &Elasticsearch{
+		TemplateName: "test",
+		IndexName:    "metrics-telegraf-{{env}}",
+		ILMPolicy:    "metrics-30d",
+		IndexTemplate: map[string]interface{}{
+			"refresh_interval": "20s",
+		},
+		Log: testutil.Logger{},
+	}
+	buf, err := e.createDataStreamTemplate("metrics-telegraf-")
+	require.NoError(t, err)
+
+	var jsonData esDataStreamTemplate
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonData))
+	require.Equal(t, []string{"metrics-telegraf-*"}, jsonData.IndexPatterns)
+	require.NotNil(t, jsonData.DataStream)
+
+	index := jsonData.Template.Settings.Index
+	require.Equal(t, "20s", index["refresh_interval"])
+	require.Equal(t, "time_series", index["mode"])
+	require.Equal(t, []interface{}{"measurement_name", "tag.*"}, index["routing_path"])
+	require.Equal(t, "metrics-30d", index["lifecycle.name"])
+
+	// The configured settings must not be modified
+	require.Len(t, e.IndexTemplate, 1)
+}
+
+func TestDataStreamWrite(t *testing.T) {
+	var templateBody, bulkBody []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/_index_template/telegraf" && r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/_index_template/telegraf" && r.Method == http.MethodPut:
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			templateBody = body
+			if _, err := w.Write([]byte(`{"acknowledged": true}`)); err != nil {
+				t.Error(err)
+			}
+		case r.URL.Path == "/_bulk":
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			bulkBody = body
+			if _, err := w.Write([]byte("{}")); err != nil {
+				t.Error(err)
+			}
+		default:
+			if _, err := w.Write([]byte(`{"version": {"number": "8.11.0"}}`)); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+			}
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:           []string{"http://" + ts.Listener.Addr().String()},
+		IndexName:      "metrics-telegraf-{{env}}",
+		Timeout:        config.Duration(time.Second * 5),
+		DataStream:     true,
+		ManageTemplate: true,
+		TemplateName:   "telegraf",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, e.Connect())
+	require.Contains(t, string(templateBody), `"data_stream"`)
+
+	m := testutil.TestMetric(1.0)
+	m.AddTag("env", "Prod")
+	require.NoError(t, e.Write([]telegraf.Metric{m}))
+	require.Contains(t, string(bulkBody), `{"create":{"_index":"metrics-telegraf-prod"}}`)
+}
+
+func TestDataStreamInvalidConfig(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if _, err := w.Write([]byte(`{"version": {"number": "7.17.0"}}`)); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:       []string{"http://" + ts.Listener.Addr().String()},
+		IndexName:  "metrics-telegraf-%Y.%m.%d",
+		Timeout:    config.Duration(time.Second * 5),
+		DataStream: true,
+		Log:        testutil.Logger{},
+	}
+	require.ErrorContains(t, e.Connect(), "date specifiers")
+
+	e.IndexName = "metrics-telegraf-default"
+	require.ErrorContains(t, e.Connect(), "require elasticsearch 8 or later")
+}
+
+type esDataStreamTemplate struct {
+	IndexPatterns []string               `json:"index_patterns"`
+	DataStream    map[string]interface{} `json:"data_stream"`
+	Template      esTemplate             `json:"template"`
+}
+
 type esTemplate struct {
 	Settings esSettings `json:"settings"`
 }
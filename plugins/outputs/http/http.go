@@ -58,6 +58,7 @@ type HTTP struct {
 
 	client     *http.Client
 	serializer telegraf.Serializer
+	encoder    internal.ContentEncoder
 
 	awsCfg *aws.Config
 	common_aws.CredentialConfig
@@ -91,6 +92,12 @@ func (h *HTTP) Connect() error {
 		return fmt.Errorf("invalid method [%s] %s", h.URL, h.Method)
 	}
 
+	encoder, err := internal.NewContentEncoder(h.ContentEncoding)
+	if err != nil {
+		return fmt.Errorf("creating encoder for %q failed: %w", h.ContentEncoding, err)
+	}
+	h.encoder = encoder
+
 	ctx := context.Background()
 	client, err := h.HTTPClientConfig.CreateClient(ctx, h.Log)
 	if err != nil {
@@ -134,14 +141,11 @@ func (h *HTTP) Write(metrics []telegraf.Metric) error {
 }
 
 func (h *HTTP) writeMetric(reqBody []byte) error {
-	var reqBodyBuffer io.Reader = bytes.NewBuffer(reqBody)
-
-	var err error
-	if h.ContentEncoding == "gzip" {
-		rc := internal.CompressWithGzip(reqBodyBuffer)
-		defer rc.Close()
-		reqBodyBuffer = rc
+	reqBody, err := h.encoder.Encode(reqBody)
+	if err != nil {
+		return fmt.Errorf("encoding body failed: %w", err)
 	}
+	var reqBodyBuffer io.Reader = bytes.NewBuffer(reqBody)
 
 	var payloadHash *string
 	if h.awsCfg != nil {
@@ -206,8 +210,8 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 
 	req.Header.Set("User-Agent", internal.ProductToken())
 	req.Header.Set("Content-Type", defaultContentType)
-	if h.ContentEncoding == "gzip" {
-		req.Header.Set("Content-Encoding", "gzip")
+	if h.ContentEncoding != "" && h.ContentEncoding != "identity" {
+		req.Header.Set("Content-Encoding", h.ContentEncoding)
 	}
 
 	for k, v := range h.Headers {
@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/compress"
 	"github.com/influxdata/telegraf/metric"
 	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
 	common_http "github.com/influxdata/telegraf/plugins/common/http"
@@ -421,6 +422,59 @@ func TestContentEncodingGzip(t *testing.T) {
 	}
 }
 
+func TestContentEncodingCodecs(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	for _, encoding := range []string{"zlib", "zstd", "lz4", "snappy"} {
+		t.Run(encoding, func(t *testing.T) {
+			ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if actual := r.Header.Get("Content-Encoding"); actual != encoding {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Errorf("Not equal, expected: %q, actual: %q", encoding, actual)
+					return
+				}
+
+				decoder, err := compress.NewDecoder(encoding, 1024*1024)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				payload, err := decoder.Decode(body)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				if !strings.Contains(string(payload), "cpu value=42") {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Errorf("'payload' should contain %q", "cpu value=42")
+					return
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			plugin := &HTTP{
+				URL:             "http://" + ts.Listener.Addr().String(),
+				ContentEncoding: encoding,
+			}
+			serializer := &influx.Serializer{}
+			require.NoError(t, serializer.Init())
+			plugin.SetSerializer(serializer)
+			require.NoError(t, plugin.Connect())
+			require.NoError(t, plugin.Write([]telegraf.Metric{getMetric()}))
+		})
+	}
+}
+
 func TestBasicAuth(t *testing.T) {
 	ts := httptest.NewServer(http.NotFoundHandler())
 	defer ts.Close()
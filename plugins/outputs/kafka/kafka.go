@@ -35,6 +35,7 @@ type Kafka struct {
 	RoutingTag        string            `toml:"routing_tag"`
 	RoutingKey        string            `toml:"routing_key"`
 	ProducerTimestamp string            `toml:"producer_timestamp"`
+	ContentEncoding   string            `toml:"content_encoding"`
 	MetricNameHeader  string            `toml:"metric_name_header" deprecated:"1.39.0;1.45.0;please use 'headers' instead"`
 	Headers           map[string]string `toml:"headers"`
 	Log               telegraf.Logger   `toml:"-"`
@@ -45,6 +46,7 @@ type Kafka struct {
 	producerFunc func(addrs []string, config *sarama.Config) (sarama.SyncProducer, error)
 	producer     sarama.SyncProducer
 	headerTmpl   map[string]*template.Template
+	encoder      internal.ContentEncoder
 
 	serializer telegraf.Serializer
 }
@@ -107,6 +109,12 @@ func (k *Kafka) Init() error {
 		return fmt.Errorf("unknown producer_timestamp option: %s", k.ProducerTimestamp)
 	}
 
+	encoder, err := internal.NewContentEncoder(k.ContentEncoding)
+	if err != nil {
+		return fmt.Errorf("creating encoder failed: %w", err)
+	}
+	k.encoder = encoder
+
 	// Setup header templates
 	k.headerTmpl = make(map[string]*template.Template, len(k.Headers))
 	for name, expr := range k.Headers {
@@ -147,6 +155,12 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 			continue
 		}
 
+		buf, err = k.encoder.Encode(buf)
+		if err != nil {
+			k.Log.Errorf("Could not encode metric: %v", err)
+			continue
+		}
+
 		m := &sarama.ProducerMessage{
 			Topic:   topic,
 			Value:   sarama.ByteEncoder(buf),
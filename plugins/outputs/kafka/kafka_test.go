@@ -10,6 +10,7 @@ import (
 	kafkacontainer "github.com/testcontainers/testcontainers-go/modules/kafka"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -367,6 +368,54 @@ func TestHeaders(t *testing.T) {
 	}
 }
 
+func TestContentEncoding(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+
+	for _, encoding := range []string{"gzip", "zstd", "lz4", "snappy"} {
+		t.Run(encoding, func(t *testing.T) {
+			s := &influx.Serializer{}
+			require.NoError(t, s.Init())
+
+			plugin := &Kafka{
+				Brokers:         []string{"127.0.0.1"},
+				Topic:           "telegraf",
+				ContentEncoding: encoding,
+				Log:             testutil.Logger{},
+				producerFunc:    newMockProducer,
+			}
+			plugin.SetSerializer(s)
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+			require.NoError(t, plugin.Write(input))
+
+			producer, ok := plugin.producer.(*mockProducer)
+			require.True(t, ok, "invalid producer type")
+
+			producer.Lock()
+			message := producer.sent[0]
+			producer.Unlock()
+
+			payload, err := message.Value.Encode()
+			require.NoError(t, err)
+			decoder, err := internal.NewContentDecoder(encoding, internal.WithMaxDecompressionSize(1024))
+			require.NoError(t, err)
+			actual, err := decoder.Decode(payload)
+			require.NoError(t, err)
+			require.Equal(t, "cpu value=42 0\n", string(actual))
+		})
+	}
+}
+
+func TestInvalidContentEncoding(t *testing.T) {
+	plugin := &Kafka{
+		ContentEncoding: "brotli",
+		Log:             testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid value for content_encoding")
+}
+
 type mockProducer struct {
 	sent []*sarama.ProducerMessage
 	sarama.SyncProducer
@@ -2,11 +2,14 @@
 package mqtt
 
 import (
+	"cmp"
 	// Blank import to support go:embed compile directive
 	_ "embed"
 	"errors"
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"text/template"
@@ -16,6 +19,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/mqtt"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -30,19 +34,27 @@ var hostnameRe = regexp.MustCompile(`({{.*\B)\.Hostname(\b[^}]*}})`)
 type message struct {
 	topic   string
 	payload []byte
+	props   *mqtt.MessageProperties
+}
+
+type topicTemplate struct {
+	filter   filter.Filter
+	template *template.Template
 }
 
 type MQTT struct {
-	Topic           string          `toml:"topic"`
-	Layout          string          `toml:"layout"`
-	HomieDeviceName string          `toml:"homie_device_name"`
-	HomieNodeID     string          `toml:"homie_node_id"`
-	Log             telegraf.Logger `toml:"-"`
+	Topic           string            `toml:"topic"`
+	TopicTemplates  map[string]string `toml:"topic_templates"`
+	Layout          string            `toml:"layout"`
+	HomieDeviceName string            `toml:"homie_device_name"`
+	HomieNodeID     string            `toml:"homie_node_id"`
+	Log             telegraf.Logger   `toml:"-"`
 	mqtt.MqttConfig
 
 	client     mqtt.Client
 	serializer telegraf.Serializer
 	template   *template.Template
+	templates  []topicTemplate
 
 	homieDeviceNameGenerator *template.Template
 	homieNodeIDGenerator     *template.Template
@@ -67,20 +79,41 @@ func (m *MQTT) Init() error {
 		return fmt.Errorf("qos value must be 0, 1, or 2: %d", m.QoS)
 	}
 
-	// Prepare the topic
-	topic := hostnameRe.ReplaceAllString(m.Topic, `$1.Tag "host"$2`)
-	topic = pluginNameRe.ReplaceAllString(topic, `$1.Name$2`)
-
-	tmpl, err := template.New("topic_name").Funcs(sprig.TxtFuncMap()).Parse(topic)
+	// Prepare the topics
+	tmpl, err := compileTopic(m.Topic)
 	if err != nil {
-		return fmt.Errorf("creating topic template failed: %w", err)
+		return err
 	}
-	for _, p := range strings.Split(topic, "/") {
-		if strings.ContainsAny(p, "#+") {
-			return fmt.Errorf("found forbidden character %s in the topic name %s", p, topic)
+	m.template = tmpl
+
+	// Check the templates for specific metrics in a defined order with
+	// exact names taking precedence over patterns
+	names := slices.Collect(maps.Keys(m.TopicTemplates))
+	isPattern := func(name string) int {
+		if strings.ContainsAny(name, "*?[") {
+			return 1
 		}
+		return 0
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Or(cmp.Compare(isPattern(a), isPattern(b)), strings.Compare(a, b))
+	})
+	m.templates = make([]topicTemplate, 0, len(names))
+	for _, name := range names {
+		f, err := filter.Compile([]string{name})
+		if err != nil {
+			return fmt.Errorf("creating filter for topic template %q failed: %w", name, err)
+		}
+		tmpl, err := compileTopic(m.TopicTemplates[name])
+		if err != nil {
+			return fmt.Errorf("topic template %q: %w", name, err)
+		}
+		m.templates = append(m.templates, topicTemplate{filter: f, template: tmpl})
+	}
+
+	if props := m.PublishPropertiesV5; props != nil && props.ExpiryFromMetricTime && props.MessageExpiry <= 0 {
+		return errors.New("expiry_from_metric_time requires a message_expiry")
 	}
-	m.template = tmpl
 
 	switch m.Layout {
 	case "":
@@ -173,8 +206,15 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 		return fmt.Errorf("unknown layout %q", m.Layout)
 	}
 
+	publisher, supportsProperties := m.client.(mqtt.PropertiesPublisher)
 	for _, msg := range topicMessages {
-		if err := m.client.Publish(msg.topic, msg.payload); err != nil {
+		var err error
+		if msg.props != nil && supportsProperties {
+			err = publisher.PublishWithProperties(msg.topic, msg.payload, msg.props)
+		} else {
+			err = m.client.Publish(msg.topic, msg.payload)
+		}
+		if err != nil {
 			// We do receive a timeout error if the remote broker is down,
 			// so let's retry the metrics in this case and drop them otherwise.
 			if errors.Is(err, internal.ErrTimeout) {
@@ -197,13 +237,18 @@ func (m *MQTT) collectNonBatch(metrics []telegraf.Metric) []message {
 			continue
 		}
 
+		props, valid := m.messageProperties(metric)
+		if !valid {
+			continue
+		}
+
 		buf, err := m.serializer.Serialize(metric)
 		if err != nil {
 			m.Log.Warnf("Could not serialize metric for topic %q: %v", topic, err)
 			m.Log.Debugf("metric was: %v", metric)
 			continue
 		}
-		collection = append(collection, message{topic, buf})
+		collection = append(collection, message{topic, buf, props})
 	}
 
 	return collection
@@ -223,12 +268,17 @@ func (m *MQTT) collectBatch(metrics []telegraf.Metric) []message {
 
 	collection := make([]message, 0, len(metricsCollection))
 	for topic, ms := range metricsCollection {
+		props, valid := m.messageProperties(ms...)
+		if !valid {
+			continue
+		}
+
 		buf, err := m.serializer.SerializeBatch(ms)
 		if err != nil {
 			m.Log.Warnf("Could not serialize metric batch for topic %q: %v", topic, err)
 			continue
 		}
-		collection = append(collection, message{topic, buf})
+		collection = append(collection, message{topic, buf, props})
 	}
 	return collection
 }
@@ -243,6 +293,11 @@ func (m *MQTT) collectField(metrics []telegraf.Metric) []message {
 			continue
 		}
 
+		props, valid := m.messageProperties(metric)
+		if !valid {
+			continue
+		}
+
 		for n, v := range metric.Fields() {
 			buf, err := internal.ToString(v)
 			if err != nil {
@@ -250,7 +305,7 @@ func (m *MQTT) collectField(metrics []telegraf.Metric) []message {
 				m.Log.Debugf("metric was: %v", metric)
 				continue
 			}
-			collection = append(collection, message{topic + "/" + n, []byte(buf)})
+			collection = append(collection, message{topic + "/" + n, []byte(buf), props})
 		}
 	}
 
@@ -279,9 +334,9 @@ func (m *MQTT) collectHomieV4(metrics []telegraf.Metric) []message {
 		for _, tag := range metric.TagList() {
 			propID := normalizeID(tag.Key)
 			collection = append(collection,
-				message{path + "/" + propID, []byte(tag.Value)},
-				message{path + "/" + propID + "/$name", []byte(tag.Key)},
-				message{path + "/" + propID + "/$datatype", []byte("string")},
+				message{topic: path + "/" + propID, payload: []byte(tag.Value)},
+				message{topic: path + "/" + propID + "/$name", payload: []byte(tag.Key)},
+				message{topic: path + "/" + propID + "/$datatype", payload: []byte("string")},
 			)
 		}
 
@@ -294,9 +349,9 @@ func (m *MQTT) collectHomieV4(metrics []telegraf.Metric) []message {
 			}
 			propID := normalizeID(field.Key)
 			collection = append(collection,
-				message{path + "/" + propID, []byte(v)},
-				message{path + "/" + propID + "/$name", []byte(field.Key)},
-				message{path + "/" + propID + "/$datatype", []byte(dt)},
+				message{topic: path + "/" + propID, payload: []byte(v)},
+				message{topic: path + "/" + propID + "/$name", payload: []byte(field.Key)},
+				message{topic: path + "/" + propID + "/$datatype", payload: []byte(dt)},
 			)
 		}
 	}
@@ -304,9 +359,62 @@ func (m *MQTT) collectHomieV4(metrics []telegraf.Metric) []message {
 	return collection
 }
 
+// messageProperties determines the MQTT v5 properties of a message
+// containing the given metrics. Messages of metrics already expired must not
+// be sent.
+func (m *MQTT) messageProperties(metrics ...telegraf.Metric) (*mqtt.MessageProperties, bool) {
+	cfg := m.PublishPropertiesV5
+	if cfg == nil || (len(cfg.UserPropertyTags) == 0 && !cfg.ExpiryFromMetricTime) {
+		return nil, true
+	}
+
+	props := &mqtt.MessageProperties{}
+	if len(cfg.UserPropertyTags) > 0 {
+		// Only use tag values shared by all metrics of the message
+		props.UserProperties = make(map[string]string, len(cfg.UserPropertyTags))
+		for _, key := range cfg.UserPropertyTags {
+			value, found := metrics[0].GetTag(key)
+			for _, metric := range metrics[1:] {
+				if v, ok := metric.GetTag(key); !ok || v != value {
+					found = false
+					break
+				}
+			}
+			if found {
+				props.UserProperties[key] = value
+			}
+		}
+	}
+
+	if cfg.ExpiryFromMetricTime {
+		// Use the oldest metric to determine the remaining validity
+		oldest := metrics[0].Time()
+		for _, metric := range metrics[1:] {
+			if metric.Time().Before(oldest) {
+				oldest = metric.Time()
+			}
+		}
+		props.MessageExpiry = time.Duration(cfg.MessageExpiry) - time.Since(oldest)
+		if props.MessageExpiry < time.Second {
+			m.Log.Debugf("Dropping expired message of metric %q at %v", metrics[0].Name(), oldest)
+			return nil, false
+		}
+	}
+
+	return props, true
+}
+
 func (m *MQTT) generateTopic(metric telegraf.Metric) (string, error) {
+	tmpl := m.template
+	for _, t := range m.templates {
+		if t.filter.Match(metric.Name()) {
+			tmpl = t.template
+			break
+		}
+	}
+
 	var b strings.Builder
-	err := m.template.Execute(&b, metric)
+	err := tmpl.Execute(&b, metric)
 	if err != nil {
 		return "", err
 	}
@@ -328,6 +436,23 @@ func (m *MQTT) generateTopic(metric telegraf.Metric) (string, error) {
 	return topic, nil
 }
 
+// compileTopic creates the template for the given topic
+func compileTopic(topic string) (*template.Template, error) {
+	topic = hostnameRe.ReplaceAllString(topic, `$1.Tag "host"$2`)
+	topic = pluginNameRe.ReplaceAllString(topic, `$1.Name$2`)
+
+	tmpl, err := template.New("topic_name").Funcs(sprig.TxtFuncMap()).Parse(topic)
+	if err != nil {
+		return nil, fmt.Errorf("creating topic template failed: %w", err)
+	}
+	for _, p := range strings.Split(topic, "/") {
+		if strings.ContainsAny(p, "#+") {
+			return nil, fmt.Errorf("found forbidden character %s in the topic name %s", p, topic)
+		}
+	}
+	return tmpl, nil
+}
+
 func init() {
 	outputs.Add("mqtt", func() telegraf.Output {
 		return &MQTT{
@@ -1,8 +1,10 @@
 package mqtt
 
 import (
+	"cmp"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -252,6 +254,18 @@ func TestMQTTv5Properties(t *testing.T) {
 			name:       "user properties set",
 			properties: &mqtt.PublishProperties{UserProperties: map[string]string{"key": "value"}},
 		},
+		{
+			name:       "automatic topic alias set",
+			properties: &mqtt.PublishProperties{AutoTopicAlias: true},
+		},
+		{
+			name: "message properties set",
+			properties: &mqtt.PublishProperties{
+				MessageExpiry:        config.Duration(10 * time.Minute),
+				ExpiryFromMetricTime: true,
+				UserPropertyTags:     []string{"tag1"},
+			},
+		},
 	}
 
 	topic := "testv3"
@@ -260,11 +274,12 @@ func TestMQTTv5Properties(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			plugin := &MQTT{
 				MqttConfig: mqtt.MqttConfig{
-					Servers:       []string{url},
-					Protocol:      "5",
-					KeepAlive:     30,
-					Timeout:       config.Duration(5 * time.Second),
-					AutoReconnect: true,
+					Servers:             []string{url},
+					Protocol:            "5",
+					KeepAlive:           30,
+					Timeout:             config.Duration(5 * time.Second),
+					AutoReconnect:       true,
+					PublishPropertiesV5: tt.properties,
 				},
 				Topic: topic,
 				Log:   testutil.Logger{Name: "mqttv5-integration-test"},
@@ -508,7 +523,7 @@ func TestIntegrationMQTTLayoutField(t *testing.T) {
 	onMessage := func(_ paho.Client, msg paho.Message) {
 		mtx.Lock()
 		defer mtx.Unlock()
-		received = append(received, message{msg.Topic(), msg.Payload()})
+		received = append(received, message{topic: msg.Topic(), payload: msg.Payload()})
 	}
 
 	// Add routing for the messages
@@ -625,7 +640,7 @@ func TestIntegrationMQTTLayoutHomieV4(t *testing.T) {
 	onMessage := func(_ paho.Client, msg paho.Message) {
 		mtx.Lock()
 		defer mtx.Unlock()
-		received = append(received, message{msg.Topic(), msg.Payload()})
+		received = append(received, message{topic: msg.Topic(), payload: msg.Payload()})
 	}
 
 	// Add routing for the messages
@@ -936,3 +951,154 @@ func TestGenerateTopicName(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateTopicNameTemplates(t *testing.T) {
+	plugin := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers: []string{"tcp://localhost:1883"},
+		},
+		Topic: `telegraf/{{ .Tag "host" }}/{{ .Name }}`,
+		TopicTemplates: map[string]string{
+			"cpu*":  `devices/{{ .Tag "host" }}/cpus/{{ .Tag "cpu" }}`,
+			"cpu":   `devices/{{ .Tag "host" }}/cpu`,
+			"disk?": "invalid/#",
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `topic template "disk?"`)
+
+	delete(plugin.TopicTemplates, "disk?")
+	require.NoError(t, plugin.Init())
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"cpu", "devices/hostname/cpu"},
+		{"cpu_total", "devices/hostname/cpus/cpu0"},
+		{"mem", "telegraf/hostname/mem"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metric.New(
+				tt.name,
+				map[string]string{"cpu": "cpu0", "host": "hostname"},
+				map[string]interface{}{"value": 123},
+				time.Unix(0, 0),
+			)
+			actual, err := plugin.generateTopic(m)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestMessageProperties(t *testing.T) {
+	now := time.Now()
+	metrics := []telegraf.Metric{
+		metric.New("test", map[string]string{"site": "a", "host": "h1"}, map[string]interface{}{"value": 1}, now.Add(-5*time.Minute)),
+		metric.New("test", map[string]string{"site": "a", "host": "h2"}, map[string]interface{}{"value": 2}, now.Add(-2*time.Minute)),
+		metric.New("test", map[string]string{"site": "b", "host": "h3"}, map[string]interface{}{"value": 3}, now.Add(-time.Hour)),
+	}
+
+	tests := []struct {
+		name     string
+		layout   string
+		topic    string
+		expected []*mqtt.MessageProperties
+	}{
+		{
+			name:   "non-batch",
+			layout: "non-batch",
+			topic:  `test/{{ .Tag "host" }}`,
+			expected: []*mqtt.MessageProperties{
+				{UserProperties: map[string]string{"site": "a", "host": "h1"}, MessageExpiry: 5 * time.Minute},
+				{UserProperties: map[string]string{"site": "a", "host": "h2"}, MessageExpiry: 8 * time.Minute},
+			},
+		},
+		{
+			name:   "batch",
+			layout: "batch",
+			topic:  `test/{{ .Tag "site" }}`,
+			expected: []*mqtt.MessageProperties{
+				{UserProperties: map[string]string{"site": "a"}, MessageExpiry: 5 * time.Minute},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serializer := &serializers_influx.Serializer{}
+			require.NoError(t, serializer.Init())
+
+			plugin := &MQTT{
+				MqttConfig: mqtt.MqttConfig{
+					Servers:  []string{"tcp://localhost:1883"},
+					Protocol: "5",
+					PublishPropertiesV5: &mqtt.PublishProperties{
+						MessageExpiry:        config.Duration(10 * time.Minute),
+						ExpiryFromMetricTime: true,
+						UserPropertyTags:     []string{"site", "host"},
+					},
+				},
+				Topic:  tt.topic,
+				Layout: tt.layout,
+				Log:    testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			plugin.SetSerializer(serializer)
+
+			client := &mockClient{}
+			plugin.client = client
+			require.NoError(t, plugin.Write(metrics))
+
+			// The third metric is expired and must be dropped
+			slices.SortFunc(client.props, func(a, b *mqtt.MessageProperties) int {
+				return cmp.Compare(a.UserProperties["host"], b.UserProperties["host"])
+			})
+			require.Len(t, client.props, len(tt.expected))
+			for i, expected := range tt.expected {
+				require.Equal(t, expected.UserProperties, client.props[i].UserProperties)
+				require.InDelta(t, expected.MessageExpiry.Seconds(), client.props[i].MessageExpiry.Seconds(), 5)
+			}
+		})
+	}
+}
+
+func TestExpiryFromMetricTimeInvalid(t *testing.T) {
+	plugin := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers:             []string{"tcp://localhost:1883"},
+			PublishPropertiesV5: &mqtt.PublishProperties{ExpiryFromMetricTime: true},
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "expiry_from_metric_time requires a message_expiry")
+}
+
+type mockClient struct {
+	props []*mqtt.MessageProperties
+}
+
+func (*mockClient) Connect() (bool, error) {
+	return false, nil
+}
+
+func (*mockClient) Publish(string, []byte) error {
+	return nil
+}
+
+func (c *mockClient) PublishWithProperties(_ string, _ []byte, props *mqtt.MessageProperties) error {
+	c.props = append(c.props, props)
+	return nil
+}
+
+func (*mockClient) SubscribeMultiple(map[string]byte, paho.MessageHandler) error {
+	return nil
+}
+
+func (*mockClient) AddRoute(string, paho.MessageHandler) {}
+
+func (*mockClient) Close() error {
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"os"
 	"slices"
 	"strings"
@@ -19,6 +20,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -86,6 +88,7 @@ type StreamConfig struct {
 	AsyncPublish          bool                              `toml:"async_publish"`
 	AsyncAckTimeout       *config.Duration                  `toml:"async_ack_timeout"`
 	DisableStreamCreation bool                              `toml:"disable_stream_creation"`
+	Deduplicate           bool                              `toml:"deduplicate"`
 }
 
 func (*NATS) SampleConfig() string {
@@ -325,32 +328,62 @@ func (n *NATS) Close() error {
 	return nil
 }
 
-func (n *NATS) publishMessage(sub string, buf []byte) (jetstream.PubAckFuture, error) {
+func (n *NATS) publishMessage(sub string, buf []byte, metrics ...telegraf.Metric) (jetstream.PubAckFuture, error) {
 	if n.Jetstream != nil {
+		opts := []jetstream.PublishOpt{jetstream.WithExpectStream(n.Jetstream.Name)}
+		if n.Jetstream.Deduplicate {
+			opts = append(opts, jetstream.WithMsgID(messageID(metrics)))
+		}
 		if n.Jetstream.AsyncPublish {
-			paf, err := n.jetstreamClient.PublishAsync(sub, buf, jetstream.WithExpectStream(n.Jetstream.Name))
+			paf, err := n.jetstreamClient.PublishAsync(sub, buf, opts...)
 			return paf, err
 		}
-		_, err := n.jetstreamClient.Publish(context.Background(), sub, buf, jetstream.WithExpectStream(n.Jetstream.Name))
+		_, err := n.jetstreamClient.Publish(context.Background(), sub, buf, opts...)
 		return nil, err
 	}
 	err := n.conn.Publish(sub, buf)
 	return nil, err
 }
 
-func (n *NATS) Write(metrics []telegraf.Metric) error {
-	if len(metrics) == 0 {
-		return nil
+// messageID returns an identifier for the given metrics used by the server to
+// detect duplicate messages within the stream's duplicate window, e.g. when
+// a batch is resent after a failed acknowledgement. The identifier is derived
+// from the series, fields and timestamp of the metrics, as metrics of the same
+// series and timestamp might carry different fields.
+func messageID(metrics []telegraf.Metric) string {
+	if len(metrics) == 1 {
+		return fmt.Sprintf("%016x-%d", metricHash(metrics[0]), metrics[0].Time().UnixNano())
 	}
 
-	msgCount := len(metrics)
-	if n.UseBatchFormat {
-		msgCount = 1
+	h := fnv.New64a()
+	for _, m := range metrics {
+		fmt.Fprintf(h, "%016x-%d\n", metricHash(m), m.Time().UnixNano())
 	}
+	return fmt.Sprintf("%016x-%d", h.Sum64(), len(metrics))
+}
 
-	var pafs []jetstream.PubAckFuture
-	if n.Jetstream != nil && n.Jetstream.AsyncPublish {
-		pafs = make([]jetstream.PubAckFuture, msgCount)
+// metricHash returns a hash of the series and the sorted fields of the metric
+func metricHash(m telegraf.Metric) uint64 {
+	fields := m.FieldList()
+	keys := make([]string, 0, len(fields))
+	values := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+		values[f.Key] = f.Value
+	}
+	slices.Sort(keys)
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%016x\n", m.HashID())
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%T:%v\n", k, values[k], values[k])
+	}
+	return h.Sum64()
+}
+
+func (n *NATS) Write(metrics []telegraf.Metric) error {
+	if len(metrics) == 0 {
+		return nil
 	}
 
 	if n.UseBatchFormat {
@@ -359,62 +392,122 @@ func (n *NATS) Write(metrics []telegraf.Metric) error {
 			n.Log.Debugf("Could not serialize batch of metrics: %v", err)
 			return nil
 		}
-		paf, err := n.publishMessage(n.Subject, buf)
+		paf, err := n.publishMessage(n.Subject, buf, metrics...)
 		if err != nil {
 			return fmt.Errorf("failed to send NATS message to subject %q: %w", n.Subject, err)
 		}
-		if n.Jetstream != nil && n.Jetstream.AsyncPublish {
-			pafs[0] = paf
+		if paf != nil {
+			return n.waitForAcks(map[int]jetstream.PubAckFuture{0: paf}, nil)
 		}
-	} else {
-		var subject bytes.Buffer
-		for i, raw := range metrics {
-			m := raw
-			if wm, ok := m.(telegraf.UnwrappableMetric); ok {
-				m = wm.Unwrap()
-			}
-			subject.Reset()
-			if err := n.tplSubject.Execute(&subject, m); err != nil {
-				return fmt.Errorf("failed to execute subject template: %w", err)
-			}
-			sub := subject.String()
-			if strings.Contains(sub, "..") || strings.HasSuffix(sub, ".") {
-				n.Log.Errorf("invalid subject %q for metric %v", sub, m)
-				continue
-			}
+		return nil
+	}
 
-			buf, err := n.serializer.Serialize(m)
-			if err != nil {
-				n.Log.Debugf("Could not serialize metric: %v", err)
-				continue
-			}
+	// Keep track of the delivery state of each metric to only retry the metrics
+	// that were not acknowledged by the server
+	accepted := make([]int, 0, len(metrics))
+	var rejected []int
+	var pafs map[int]jetstream.PubAckFuture
+	if n.Jetstream != nil && n.Jetstream.AsyncPublish {
+		pafs = make(map[int]jetstream.PubAckFuture, len(metrics))
+	}
 
-			paf, err := n.publishMessage(sub, buf)
-			if err != nil {
-				return fmt.Errorf("failed to send NATS message: %w", err)
-			}
+	var publishErr error
+	var subject bytes.Buffer
+	for i, raw := range metrics {
+		m := raw
+		if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+			m = wm.Unwrap()
+		}
+		subject.Reset()
+		if err := n.tplSubject.Execute(&subject, m); err != nil {
+			return fmt.Errorf("failed to execute subject template: %w", err)
+		}
+		sub := subject.String()
+		if strings.Contains(sub, "..") || strings.HasSuffix(sub, ".") {
+			n.Log.Errorf("invalid subject %q for metric %v", sub, m)
+			rejected = append(rejected, i)
+			continue
+		}
 
-			if n.Jetstream != nil && n.Jetstream.AsyncPublish {
-				pafs[i] = paf
-			}
+		buf, err := n.serializer.Serialize(m)
+		if err != nil {
+			n.Log.Debugf("Could not serialize metric: %v", err)
+			rejected = append(rejected, i)
+			continue
+		}
+
+		paf, err := n.publishMessage(sub, buf, m)
+		if err != nil {
+			publishErr = fmt.Errorf("failed to send NATS message: %w", err)
+			break
+		}
+
+		if paf != nil {
+			pafs[i] = paf
+		} else {
+			accepted = append(accepted, i)
 		}
 	}
 
-	if pafs != nil {
-		// Check Ack from async publish
-		select {
-		case <-n.jetstreamClient.PublishAsyncComplete():
-			for i := range pafs {
-				select {
-				case <-pafs[i].Ok():
-					continue
-				case err := <-pafs[i].Err():
-					return fmt.Errorf("publish acknowledgement is an error: %w (retrying)", err)
+	var ackErr error
+	if len(pafs) > 0 {
+		ackErr = n.waitForAcks(pafs, &accepted)
+	}
+
+	if err := errors.Join(publishErr, ackErr); err != nil {
+		// Metrics neither accepted nor rejected are kept for retrying
+		return &internal.PartialWriteError{
+			Err:           err,
+			MetricsAccept: accepted,
+			MetricsReject: rejected,
+		}
+	}
+	return nil
+}
+
+// waitForAcks waits for the acknowledgements of asynchronously published
+// messages and adds the indices of the acknowledged messages to accepted
+func (n *NATS) waitForAcks(pafs map[int]jetstream.PubAckFuture, accepted *[]int) error {
+	timeout := time.After(time.Duration(*n.Jetstream.AsyncAckTimeout))
+
+	var errs []error
+	var pending int
+	var timedOut bool
+	for i, paf := range pafs {
+		if !timedOut {
+			select {
+			case <-paf.Ok():
+				if accepted != nil {
+					*accepted = append(*accepted, i)
 				}
+				continue
+			case err := <-paf.Err():
+				errs = append(errs, err)
+				continue
+			case <-timeout:
+				// Do not wait for the remaining acknowledgements anymore
+				timedOut = true
 			}
-		case <-time.After(time.Duration(*n.Jetstream.AsyncAckTimeout)):
-			return fmt.Errorf("waiting for acknowledgement timed out, %d messages pending", n.jetstreamClient.PublishAsyncPending())
 		}
+
+		// Collect the acknowledgements already received without blocking
+		select {
+		case <-paf.Ok():
+			if accepted != nil {
+				*accepted = append(*accepted, i)
+			}
+		case err := <-paf.Err():
+			errs = append(errs, err)
+		default:
+			pending++
+		}
+	}
+
+	if pending > 0 {
+		errs = append(errs, fmt.Errorf("waiting for acknowledgement timed out, %d messages pending", pending))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("publish acknowledgement is an error: %w (retrying)", errors.Join(errs...))
 	}
 	return nil
 }
@@ -2,6 +2,7 @@ package nats
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"testing"
@@ -419,3 +420,77 @@ func createStream(t *testing.T, server string, cfg *nats.StreamConfig) {
 	_, err = js.AddStream(cfg)
 	require.NoError(t, err)
 }
+
+func TestMessageID(t *testing.T) {
+	m1 := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0))
+	m2 := metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0))
+
+	// Metrics of the same series and timestamp must differ in their fields
+	same := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0))
+	require.Equal(t, messageID([]telegraf.Metric{m1}), messageID([]telegraf.Metric{same}))
+	other := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 2.0}, time.Unix(1700000000, 0))
+	require.NotEqual(t, messageID([]telegraf.Metric{m1}), messageID([]telegraf.Metric{other}))
+	split := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"idle": 1.0}, time.Unix(1700000000, 0))
+	require.NotEqual(t, messageID([]telegraf.Metric{m1}), messageID([]telegraf.Metric{split}))
+	require.NotEqual(t, messageID([]telegraf.Metric{m1}), messageID([]telegraf.Metric{m2}))
+
+	// The field order does not matter
+	ab := metric.New("cpu", map[string]string{}, map[string]interface{}{"a": 1.0}, time.Unix(1700000000, 0))
+	ab.AddField("b", int64(2))
+	ba := metric.New("cpu", map[string]string{}, map[string]interface{}{"b": int64(2)}, time.Unix(1700000000, 0))
+	ba.AddField("a", 1.0)
+	require.Equal(t, messageID([]telegraf.Metric{ab}), messageID([]telegraf.Metric{ba}))
+
+	later := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000001, 0))
+	require.NotEqual(t, messageID([]telegraf.Metric{m1}), messageID([]telegraf.Metric{later}))
+
+	// Batches depend on all metrics and their order
+	require.Equal(t, messageID([]telegraf.Metric{m1, m2}), messageID([]telegraf.Metric{m1, m2}))
+	require.NotEqual(t, messageID([]telegraf.Metric{m1, m2}), messageID([]telegraf.Metric{m2, m1}))
+	require.NotEqual(t, messageID([]telegraf.Metric{m1, m2}), messageID([]telegraf.Metric{m1}))
+}
+
+func TestWaitForAcksTimeout(t *testing.T) {
+	timeout := config.Duration(100 * time.Millisecond)
+	plugin := &NATS{Jetstream: &StreamConfig{AsyncAckTimeout: &timeout}}
+
+	// The first message is never acknowledged, the others are acknowledged or
+	// rejected before the timeout occurs
+	pafs := map[int]jetstream.PubAckFuture{
+		0: newFakePubAckFuture(),
+		1: newFakePubAckFuture(),
+		2: newFakePubAckFuture(),
+	}
+	pafs[1].(*fakePubAckFuture).ok <- &jetstream.PubAck{}
+	pafs[2].(*fakePubAckFuture).err <- errors.New("rejected")
+
+	var accepted []int
+	err := plugin.waitForAcks(pafs, &accepted)
+	require.ErrorContains(t, err, "rejected")
+	require.ErrorContains(t, err, "1 messages pending")
+	require.Equal(t, []int{1}, accepted)
+}
+
+type fakePubAckFuture struct {
+	ok  chan *jetstream.PubAck
+	err chan error
+}
+
+func newFakePubAckFuture() *fakePubAckFuture {
+	return &fakePubAckFuture{
+		ok:  make(chan *jetstream.PubAck, 1),
+		err: make(chan error, 1),
+	}
+}
+
+func (f *fakePubAckFuture) Ok() <-chan *jetstream.PubAck {
+	return f.ok
+}
+
+func (f *fakePubAckFuture) Err() <-chan error {
+	return f.err
+}
+
+func (*fakePubAckFuture) Msg() *nats.Msg {
+	return nil
+}
@@ -0,0 +1,221 @@
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchutil"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// document is a metric serialized for bulk indexing
+type document struct {
+	metric   int
+	index    string
+	id       string
+	pipeline string
+	body     []byte
+}
+
+// bulkFailure holds the reason for a document failing to be indexed
+type bulkFailure struct {
+	status int
+	err    error
+}
+
+// retryable checks if indexing the document might succeed when retrying
+// immediately, i.e. if the cluster was temporarily overloaded
+func (f *bulkFailure) retryable() bool {
+	switch f.status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// rejected checks if the document itself was refused by the cluster, e.g.
+// due to a mapping conflict, so retrying will never succeed
+func (f *bulkFailure) rejected() bool {
+	return f.status >= 400 && f.status < 500 && !f.retryable()
+}
+
+// writeDocuments indexes the documents retrying those failing due to an
+// overloaded cluster. Documents rejected by the cluster are reported and
+// dropped while documents still failing are kept for the next write.
+func (o *Opensearch) writeDocuments(docs []document) error {
+	pending := docs
+	var rejected []int
+	var rejectErrs []error
+	var lastErr error
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			delay := time.Duration(o.RetryInterval) * time.Duration(1<<(attempt-1))
+			o.Log.Debugf("Retrying %d documents in %s", len(pending), delay)
+			time.Sleep(delay)
+		}
+
+		failures, err := o.bulk(pending)
+		if err != nil {
+			lastErr = err
+			break
+		}
+
+		var retry []document
+		var transportFailure bool
+		for _, doc := range pending {
+			f, found := failures[doc.metric]
+			if !found {
+				continue
+			}
+			if f.rejected() {
+				o.Log.Errorf("Document for index %q rejected with status %d: %v", doc.index, f.status, f.err)
+				rejected = append(rejected, doc.metric)
+				rejectErrs = append(rejectErrs, f.err)
+				continue
+			}
+			if !f.retryable() {
+				transportFailure = true
+			}
+			lastErr = f.err
+			retry = append(retry, doc)
+		}
+		pending = retry
+
+		// Requests failing as a whole are not retried immediately but the
+		// documents are kept for the next write
+		if transportFailure || attempt >= o.MaxRetries {
+			break
+		}
+	}
+
+	if len(pending) == 0 && len(rejected) == 0 {
+		o.Log.Debugf("Successfully indexed [%d] documents", len(docs))
+		return nil
+	}
+
+	kept := make(map[int]bool, len(pending)+len(rejected))
+	for _, doc := range pending {
+		kept[doc.metric] = true
+	}
+	for _, idx := range rejected {
+		kept[idx] = true
+	}
+	accept := make([]int, 0, len(docs)-len(kept))
+	for _, doc := range docs {
+		if !kept[doc.metric] {
+			accept = append(accept, doc.metric)
+		}
+	}
+
+	var err error
+	if len(pending) > 0 {
+		err = fmt.Errorf("failed to index [%d] documents: %w", len(pending), lastErr)
+		if len(accept) == 0 && len(rejected) == 0 {
+			return err
+		}
+	} else {
+		err = fmt.Errorf("[%d] documents rejected", len(rejected))
+	}
+
+	return &internal.PartialWriteError{
+		Err:                 err,
+		MetricsAccept:       accept,
+		MetricsReject:       rejected,
+		MetricsRejectErrors: rejectErrs,
+	}
+}
+
+// bulk sends the documents using one bulk indexer per pipeline and returns
+// the failures of the documents by metric index
+func (o *Opensearch) bulk(docs []document) (map[int]*bulkFailure, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
+	defer cancel()
+
+	var mu sync.Mutex
+	failures := make(map[int]*bulkFailure)
+	succeeded := make(map[int]bool)
+	flushFailed := make(map[string]error)
+
+	// BulkIndexer supports pipeline at config level so separate indexer
+	// instance for each unique pipeline
+	indexers := make(map[string]opensearchutil.BulkIndexer)
+	defer func() {
+		for _, indexer := range indexers {
+			indexer.Close(ctx) //nolint:errcheck // best effort cleanup after an error
+		}
+	}()
+
+	for _, doc := range docs {
+		indexer, found := indexers[doc.pipeline]
+		if !found {
+			pipeline := doc.pipeline
+			onError := func(_ context.Context, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				flushFailed[pipeline] = err
+			}
+			var err error
+			indexer, err = createBulkIndexer(o, pipeline, onError)
+			if err != nil {
+				return nil, fmt.Errorf("error while instantiating OpenSearch NewBulkIndexer for pipeline %q: %w", pipeline, err)
+			}
+			indexers[pipeline] = indexer
+		}
+
+		item := opensearchutil.BulkIndexerItem{
+			Action:     "index",
+			Index:      doc.index,
+			DocumentID: doc.id,
+			Body:       bytes.NewReader(doc.body),
+			OnSuccess: func(context.Context, opensearchutil.BulkIndexerItem, opensearchutil.BulkIndexerResponseItem) {
+				mu.Lock()
+				defer mu.Unlock()
+				succeeded[doc.metric] = true
+			},
+			OnFailure: func(_ context.Context, _ opensearchutil.BulkIndexerItem, res opensearchutil.BulkIndexerResponseItem, err error) {
+				if err == nil {
+					err = fmt.Errorf("%s: %s", res.Error.Type, res.Error.Reason)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				failures[doc.metric] = &bulkFailure{status: res.Status, err: err}
+			},
+		}
+		if err := indexer.Add(ctx, item); err != nil {
+			mu.Lock()
+			failures[doc.metric] = &bulkFailure{err: fmt.Errorf("adding document failed: %w", err)}
+			mu.Unlock()
+		}
+	}
+
+	var errs []error
+	for pipeline, indexer := range indexers {
+		if err := indexer.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		delete(indexers, pipeline)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("error sending bulk request to OpenSearch: %w", errors.Join(errs...))
+	}
+
+	// Documents of failed bulk requests are not reported individually, so
+	// consider all documents without a result as failed
+	for _, doc := range docs {
+		err, found := flushFailed[doc.pipeline]
+		if !found || succeeded[doc.metric] {
+			continue
+		}
+		if _, found := failures[doc.metric]; !found {
+			failures[doc.metric] = &bulkFailure{err: err}
+		}
+	}
+
+	return failures, nil
+}
@@ -19,11 +19,13 @@ import (
 	"github.com/opensearch-project/opensearch-go/v2"
 	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
 	"github.com/opensearch-project/opensearch-go/v2/opensearchutil"
+	requestsigner "github.com/opensearch-project/opensearch-go/v2/signer/awsv2"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/choice"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -50,13 +52,16 @@ type Opensearch struct {
 	HealthCheckInterval config.Duration `toml:"health_check_interval"`
 	HealthCheckTimeout  config.Duration `toml:"health_check_timeout"`
 	URLs                []string        `toml:"urls"`
+	RolloverAlias       string          `toml:"rollover_alias"`
+	MaxRetries          int             `toml:"max_retries"`
+	RetryInterval       config.Duration `toml:"retry_interval"`
+	AwsService          string          `toml:"aws_service"`
 	Log                 telegraf.Logger `toml:"-"`
 	tls.ClientConfig
+	common_aws.CredentialConfig
 
 	indexTmpl    *template.Template
 	pipelineTmpl *template.Template
-	onSucc       func(context.Context, opensearchutil.BulkIndexerItem, opensearchutil.BulkIndexerResponseItem)
-	onFail       func(context.Context, opensearchutil.BulkIndexerItem, opensearchutil.BulkIndexerResponseItem, error)
 	osClient     *opensearch.Client
 }
 
@@ -65,6 +70,7 @@ var indexTemplate string
 
 type templatePart struct {
 	TemplatePattern string
+	RolloverAlias   string
 }
 
 func (*Opensearch) SampleConfig() string {
@@ -72,8 +78,28 @@ func (*Opensearch) SampleConfig() string {
 }
 
 func (o *Opensearch) Init() error {
-	if len(o.URLs) == 0 || o.IndexName == "" {
-		return errors.New("opensearch urls or index_name is not defined")
+	if len(o.URLs) == 0 || (o.IndexName == "" && o.RolloverAlias == "") {
+		return errors.New("opensearch urls or index_name or rollover_alias is not defined")
+	}
+	if o.IndexName != "" && o.RolloverAlias != "" {
+		return errors.New("index_name and rollover_alias are mutually exclusive")
+	}
+	if strings.Contains(o.RolloverAlias, "{{") {
+		return errors.New("rollover_alias must not be a template")
+	}
+
+	if o.MaxRetries < 0 {
+		return errors.New("max_retries must not be negative")
+	}
+
+	switch o.AwsService {
+	case "":
+	case "es", "aoss":
+		if !o.Username.Empty() || !o.Password.Empty() || !o.AuthBearerToken.Empty() {
+			return errors.New("aws_service cannot be used together with username, password or auth_bearer_token")
+		}
+	default:
+		return fmt.Errorf("invalid aws_service %q", o.AwsService)
 	}
 
 	// Determine if we should process NaN and inf values
@@ -98,22 +124,11 @@ func (o *Opensearch) Init() error {
 	}
 	o.pipelineTmpl = pipelineTmpl
 
-	o.onSucc = func(_ context.Context, _ opensearchutil.BulkIndexerItem, res opensearchutil.BulkIndexerResponseItem) {
-		o.Log.Debugf("Indexed to OpenSearch with status- [%d] Result- %s DocumentID- %s ", res.Status, res.Result, res.DocumentID)
-	}
-	o.onFail = func(_ context.Context, _ opensearchutil.BulkIndexerItem, res opensearchutil.BulkIndexerResponseItem, err error) {
-		if err != nil {
-			o.Log.Errorf("error while OpenSearch bulkIndexing: %v", err)
-		} else {
-			o.Log.Errorf("error while OpenSearch bulkIndexing: %s: %s", res.Error.Type, res.Error.Reason)
-		}
-	}
-
 	if o.TemplateName == "" {
 		return errors.New("template_name configuration not defined")
 	}
 
-	if o.ManageTemplate {
+	if o.ManageTemplate && o.RolloverAlias == "" {
 		prefix := o.IndexName
 		if idx := strings.Index(prefix, "{{"); idx >= 0 {
 			prefix = prefix[:idx]
@@ -132,6 +147,7 @@ func init() {
 			Timeout:             config.Duration(time.Second * 5),
 			HealthCheckInterval: config.Duration(time.Second * 10),
 			HealthCheckTimeout:  config.Duration(time.Second * 1),
+			RetryInterval:       config.Duration(time.Second),
 		}
 	})
 }
@@ -140,12 +156,11 @@ func (o *Opensearch) Connect() error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
 	defer cancel()
 
-	err := o.newClient()
-	if err != nil {
-		o.Log.Errorf("error creating OpenSearch client: %v", err)
+	if err := o.newClient(); err != nil {
+		return fmt.Errorf("creating OpenSearch client failed: %w", err)
 	}
 
-	_, err = o.osClient.Ping(o.osClient.Ping.WithContext(ctx))
+	_, err := o.osClient.Ping(o.osClient.Ping.WithContext(ctx))
 	if err != nil {
 		return &internal.StartupError{
 			Err:   fmt.Errorf("unable to ping OpenSearch server: %w", err),
@@ -163,6 +178,15 @@ func (o *Opensearch) Connect() error {
 		}
 	}
 
+	if o.RolloverAlias != "" {
+		if err := o.bootstrapRolloverAlias(ctx); err != nil {
+			return &internal.StartupError{
+				Err:   err,
+				Retry: true,
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -209,6 +233,19 @@ func (o *Opensearch) newClient() error {
 	}
 	clientConfig.Header = header
 
+	// Sign the requests for Amazon OpenSearch Service
+	if o.AwsService != "" {
+		awsConfig, err := o.CredentialConfig.Credentials()
+		if err != nil {
+			return fmt.Errorf("getting AWS credentials failed: %w", err)
+		}
+		signer, err := requestsigner.NewSignerWithService(awsConfig, o.AwsService)
+		if err != nil {
+			return fmt.Errorf("creating request signer failed: %w", err)
+		}
+		clientConfig.Signer = signer
+	}
+
 	client, err := opensearch.NewClient(clientConfig)
 	o.osClient = client
 
@@ -228,23 +265,19 @@ func getPointID(m telegraf.Metric) string {
 }
 
 func (o *Opensearch) Write(metrics []telegraf.Metric) error {
-	// get indexers based on unique pipeline values
-	indexers := getTargetIndexers(metrics, o)
-	if len(indexers) == 0 {
-		return errors.New("failed to instantiate OpenSearch bulkindexer")
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
-	defer cancel()
-
-	for _, metric := range metrics {
+	docs := make([]document, 0, len(metrics))
+	for i, metric := range metrics {
 		var name = metric.Name()
 
 		// index name has to be re-evaluated each time for telegraf
 		// to send the metric to the correct time-based index
-		indexName, err := o.GetIndexName(metric)
-		if err != nil {
-			return fmt.Errorf("generating indexname failed: %w", err)
+		indexName := o.RolloverAlias
+		if indexName == "" {
+			var err error
+			indexName, err = o.GetIndexName(metric)
+			if err != nil {
+				return fmt.Errorf("generating indexname failed: %w", err)
+			}
 		}
 
 		// Handle NaN and inf field-values
@@ -278,15 +311,13 @@ func (o *Opensearch) Write(metrics []telegraf.Metric) error {
 			return fmt.Errorf("failed to marshal body: %w", err)
 		}
 
-		bulkIndxrItem := opensearchutil.BulkIndexerItem{
-			Action:    "index",
-			Index:     indexName,
-			Body:      strings.NewReader(string(body)),
-			OnSuccess: o.onSucc,
-			OnFailure: o.onFail,
+		doc := document{
+			metric: i,
+			index:  indexName,
+			body:   body,
 		}
 		if o.ForceDocumentID {
-			bulkIndxrItem.DocumentID = getPointID(metric)
+			doc.id = getPointID(metric)
 		}
 
 		if o.UsePipeline != "" {
@@ -294,79 +325,21 @@ func (o *Opensearch) Write(metrics []telegraf.Metric) error {
 			if err != nil {
 				return fmt.Errorf("failed to evaluate pipeline name: %w", err)
 			}
-
-			if pipelineName != "" {
-				if indexers[pipelineName] != nil {
-					if err := indexers[pipelineName].Add(ctx, bulkIndxrItem); err != nil {
-						o.Log.Errorf("error adding metric entry to OpenSearch bulkIndexer: %v for pipeline %s", err, pipelineName)
-					}
-					continue
-				}
-			}
+			doc.pipeline = pipelineName
 		}
 
-		if err := indexers["default"].Add(ctx, bulkIndxrItem); err != nil {
-			o.Log.Errorf("error adding metric entry to OpenSearch default bulkIndexer: %v", err)
-		}
+		docs = append(docs, doc)
 	}
 
-	for _, bulkIndxr := range indexers {
-		if err := bulkIndxr.Close(ctx); err != nil {
-			return fmt.Errorf("error sending bulk request to OpenSearch: %w", err)
-		}
-
-		// Report the indexer statistics
-		stats := bulkIndxr.Stats()
-		if stats.NumFailed > 0 {
-			return fmt.Errorf("failed to index [%d] documents", stats.NumFailed)
-		}
-
-		o.Log.Debugf("Successfully indexed [%d] documents", stats.NumAdded)
-	}
-
-	return nil
+	return o.writeDocuments(docs)
 }
 
-// BulkIndexer supports pipeline at config level so separate indexer instance for each unique pipeline
-func getTargetIndexers(metrics []telegraf.Metric, osInst *Opensearch) map[string]opensearchutil.BulkIndexer {
-	var indexers = make(map[string]opensearchutil.BulkIndexer)
-
-	if osInst.UsePipeline != "" {
-		for _, metric := range metrics {
-			pipelineName, err := osInst.getPipelineName(metric)
-			if err != nil {
-				osInst.Log.Errorf("error while evaluating pipeline name: %v for pipeline %s", err, pipelineName)
-			}
-
-			if pipelineName != "" {
-				// BulkIndexer supports pipeline at config level not metric level
-				if _, ok := indexers[pipelineName]; ok {
-					continue
-				}
-				bulkIndxr, err := createBulkIndexer(osInst, pipelineName)
-				if err != nil {
-					osInst.Log.Errorf("error while instantiating OpenSearch NewBulkIndexer: %v for pipeline: %s", err, pipelineName)
-				} else {
-					indexers[pipelineName] = bulkIndxr
-				}
-			}
-		}
-	}
-
-	bulkIndxr, err := createBulkIndexer(osInst, "")
-	if err != nil {
-		osInst.Log.Errorf("error while instantiating OpenSearch NewBulkIndexer: %v for default pipeline", err)
-	} else {
-		indexers["default"] = bulkIndxr
-	}
-	return indexers
-}
-
-func createBulkIndexer(osInst *Opensearch, pipelineName string) (opensearchutil.BulkIndexer, error) {
+func createBulkIndexer(osInst *Opensearch, pipelineName string, onError func(context.Context, error)) (opensearchutil.BulkIndexer, error) {
 	var bulkIndexerConfig = opensearchutil.BulkIndexerConfig{
 		Client:     osInst.osClient,
 		NumWorkers: 4,    // The number of worker goroutines (default: number of CPUs)
 		FlushBytes: 5e+6, // The flush threshold in bytes (default: 5M)
+		OnError:    onError,
 	}
 	if pipelineName != "" {
 		bulkIndexerConfig.Pipeline = pipelineName
@@ -423,6 +396,9 @@ func (o *Opensearch) manageTemplate(ctx context.Context) error {
 
 	templateExists := resp.Body != http.NoBody
 	templatePattern := o.IndexName
+	if o.RolloverAlias != "" {
+		templatePattern = o.RolloverAlias + "-"
+	}
 
 	if strings.Contains(templatePattern, "{{") {
 		templatePattern = templatePattern[0:strings.Index(templatePattern, "{{")]
@@ -435,6 +411,7 @@ func (o *Opensearch) manageTemplate(ctx context.Context) error {
 	if o.OverwriteTemplate || !templateExists || templatePattern != "" {
 		tp := templatePart{
 			TemplatePattern: templatePattern + "*",
+			RolloverAlias:   o.RolloverAlias,
 		}
 
 		t := template.Must(template.New("template").Parse(indexTemplate))
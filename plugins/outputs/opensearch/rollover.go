@@ -0,0 +1,61 @@
+package opensearch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+)
+
+// bootstrapRolloverAlias creates the initial index with the rollover alias
+// as write index unless the alias already exists
+func (o *Opensearch) bootstrapRolloverAlias(ctx context.Context) error {
+	existsReq := opensearchapi.IndicesExistsAliasRequest{
+		Name: []string{o.RolloverAlias},
+	}
+	resp, err := existsReq.Do(ctx, o.osClient.Transport)
+	if err != nil {
+		return fmt.Errorf("checking rollover alias %q failed: %w", o.RolloverAlias, err)
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		o.Log.Debugf("Found existing rollover alias %q", o.RolloverAlias)
+		return nil
+	case http.StatusNotFound:
+	default:
+		return fmt.Errorf("checking rollover alias %q failed: %s", o.RolloverAlias, resp.Status())
+	}
+
+	index := o.RolloverAlias + "-000001"
+	body := fmt.Sprintf(`{"aliases":{%q:{"is_write_index":true}}}`, o.RolloverAlias)
+	createReq := opensearchapi.IndicesCreateRequest{
+		Index: index,
+		Body:  strings.NewReader(body),
+	}
+	resp, err = createReq.Do(ctx, o.osClient.Transport)
+	if err != nil {
+		return fmt.Errorf("creating initial index %q failed: %w", index, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		msg, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("creating initial index %q failed: %s", index, resp.Status())
+		}
+
+		// Another instance might have created the index in the meantime
+		if strings.Contains(string(msg), "resource_already_exists_exception") {
+			return nil
+		}
+		return fmt.Errorf("creating initial index %q failed: %s: %s", index, resp.Status(), msg)
+	}
+
+	o.Log.Infof("Created index %q with rollover alias %q", index, o.RolloverAlias)
+	return nil
+}
@@ -49,6 +49,7 @@ type Postgresql struct {
 	AddColumnTemplates         []*sqltemplate.Template `toml:"add_column_templates"`
 	TagTableCreateTemplates    []*sqltemplate.Template `toml:"tag_table_create_templates"`
 	TagTableAddColumnTemplates []*sqltemplate.Template `toml:"tag_table_add_column_templates"`
+	TimescaleDB                *TimescaleDB            `toml:"timescaledb"`
 	Uint64Type                 string                  `toml:"uint64_type"`
 	RetryMaxBackoff            config.Duration         `toml:"retry_max_backoff"`
 	TagCacheSize               int                     `toml:"tag_cache_size"`
@@ -108,6 +109,15 @@ func (p *Postgresql) Init() error {
 	p.fieldsJSONColumn = utils.Column{Name: "fields", Type: PgJSONb, Role: utils.FieldColType}
 	p.tagsJSONColumn = utils.Column{Name: "tags", Type: PgJSONb, Role: utils.TagColType}
 
+	// Manage new metric tables as hypertables if requested
+	if p.TimescaleDB != nil {
+		tmpls, err := p.TimescaleDB.templates(p)
+		if err != nil {
+			return err
+		}
+		p.CreateTemplates = append(p.CreateTemplates, tmpls...)
+	}
+
 	connectionSecret, err := p.Connection.Get()
 	if err != nil {
 		return fmt.Errorf("getting address failed: %w", err)
@@ -0,0 +1,93 @@
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/sqltemplate"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/utils"
+)
+
+// TimescaleDB contains the settings for managing metric tables as TimescaleDB hypertables.
+type TimescaleDB struct {
+	ChunkTimeInterval config.Duration `toml:"chunk_time_interval"`
+	CompressAfter     config.Duration `toml:"compress_after"`
+	CompressSegmentBy []string        `toml:"compress_segmentby"`
+	DropAfter         config.Duration `toml:"drop_after"`
+}
+
+// templates returns the statements to execute after creating a new metric table to convert it into a hypertable
+// and to set up the compression and retention policies.
+func (ts *TimescaleDB) templates(p *Postgresql) ([]*sqltemplate.Template, error) {
+	if ts.ChunkTimeInterval == 0 {
+		ts.ChunkTimeInterval = config.Duration(7 * 24 * time.Hour)
+	}
+	if ts.ChunkTimeInterval < 0 {
+		return nil, errors.New("invalid timescaledb chunk_time_interval")
+	}
+	if ts.CompressAfter < 0 {
+		return nil, errors.New("invalid timescaledb compress_after")
+	}
+	if ts.DropAfter < 0 {
+		return nil, errors.New("invalid timescaledb drop_after")
+	}
+	if len(ts.CompressSegmentBy) > 0 && ts.CompressAfter == 0 {
+		return nil, errors.New("timescaledb compress_segmentby requires compress_after to be set")
+	}
+
+	timeColumn := utils.QuoteLiteral(p.TimestampColumnName)
+	stmts := []string{
+		fmt.Sprintf(
+			`SELECT create_hypertable({{ .table|quoteLiteral }}, %s, chunk_time_interval => %s, if_not_exists => true)`,
+			timeColumn, interval(ts.ChunkTimeInterval),
+		),
+	}
+
+	if ts.CompressAfter > 0 {
+		var segmentBy string
+		switch {
+		case len(ts.CompressSegmentBy) > 0:
+			idents := make([]string, 0, len(ts.CompressSegmentBy))
+			for _, name := range ts.CompressSegmentBy {
+				idents = append(idents, utils.QuoteIdentifier(name))
+			}
+			segmentBy = ", timescaledb.compress_segmentby = " + utils.QuoteLiteral(strings.Join(idents, ","))
+		case p.TagsAsForeignKeys:
+			segmentBy = ", timescaledb.compress_segmentby = 'tag_id'"
+		case !p.TagsAsJsonb:
+			// Segment by all tag columns present at table creation
+			segmentBy = `{{ if .columns.Tags }}, timescaledb.compress_segmentby = ` +
+				`{{ .columns.Tags.Identifiers|join ","|quoteLiteral }}{{ end }}`
+		}
+		orderBy := utils.QuoteLiteral(utils.QuoteIdentifier(p.TimestampColumnName) + " DESC")
+
+		stmts = append(stmts,
+			fmt.Sprintf(`ALTER TABLE {{ .table }} SET (timescaledb.compress, timescaledb.compress_orderby = %s%s)`, orderBy, segmentBy),
+			fmt.Sprintf(`SELECT add_compression_policy({{ .table|quoteLiteral }}, %s, if_not_exists => true)`, interval(ts.CompressAfter)),
+		)
+	}
+
+	if ts.DropAfter > 0 {
+		stmts = append(stmts,
+			fmt.Sprintf(`SELECT add_retention_policy({{ .table|quoteLiteral }}, %s, if_not_exists => true)`, interval(ts.DropAfter)),
+		)
+	}
+
+	tmpls := make([]*sqltemplate.Template, 0, len(stmts))
+	for _, stmt := range stmts {
+		tmpl := &sqltemplate.Template{}
+		if err := tmpl.UnmarshalText([]byte(stmt)); err != nil {
+			return nil, fmt.Errorf("parsing timescaledb template: %w", err)
+		}
+		tmpls = append(tmpls, tmpl)
+	}
+	return tmpls, nil
+}
+
+// interval converts the given duration into a Postgres interval literal with microsecond precision.
+func interval(d config.Duration) string {
+	return fmt.Sprintf("INTERVAL '%d microseconds'", time.Duration(d).Microseconds())
+}
@@ -0,0 +1,102 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/sqltemplate"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/utils"
+)
+
+func TestTimescaleDBTemplates(t *testing.T) {
+	columns := []utils.Column{
+		{Name: "time", Type: PgTimestampWithoutTimeZone, Role: utils.TimeColType},
+		{Name: "host", Type: PgText, Role: utils.TagColType},
+		{Name: "region", Type: PgText, Role: utils.TagColType},
+		{Name: "value", Type: PgDoublePrecision, Role: utils.FieldColType},
+	}
+
+	tests := []struct {
+		name     string
+		settings TimescaleDB
+		fkeys    bool
+		expected []string
+	}{
+		{
+			name: "defaults",
+			expected: []string{
+				`CREATE TABLE "public"."cpu" ("time" timestamp without time zone, "host" text, "region" text, "value" double precision)`,
+				`SELECT create_hypertable('"public"."cpu"', 'time', chunk_time_interval => INTERVAL '604800000000 microseconds', if_not_exists => true)`,
+			},
+		},
+		{
+			name: "compression and retention",
+			settings: TimescaleDB{
+				ChunkTimeInterval: config.Duration(24 * time.Hour),
+				CompressAfter:     config.Duration(2 * 24 * time.Hour),
+				DropAfter:         config.Duration(30 * 24 * time.Hour),
+			},
+			expected: []string{
+				`CREATE TABLE "public"."cpu" ("time" timestamp without time zone, "host" text, "region" text, "value" double precision)`,
+				`SELECT create_hypertable('"public"."cpu"', 'time', chunk_time_interval => INTERVAL '86400000000 microseconds', if_not_exists => true)`,
+				`ALTER TABLE "public"."cpu" SET (timescaledb.compress, timescaledb.compress_orderby = '"time" DESC', ` +
+					`timescaledb.compress_segmentby = '"host","region"')`,
+				`SELECT add_compression_policy('"public"."cpu"', INTERVAL '172800000000 microseconds', if_not_exists => true)`,
+				`SELECT add_retention_policy('"public"."cpu"', INTERVAL '2592000000000 microseconds', if_not_exists => true)`,
+			},
+		},
+		{
+			name:     "foreign keys",
+			settings: TimescaleDB{CompressAfter: config.Duration(time.Hour)},
+			fkeys:    true,
+			expected: []string{
+				`CREATE TABLE "public"."cpu" ("time" timestamp without time zone, "host" text, "region" text, "value" double precision)`,
+				`SELECT create_hypertable('"public"."cpu"', 'time', chunk_time_interval => INTERVAL '604800000000 microseconds', if_not_exists => true)`,
+				`ALTER TABLE "public"."cpu" SET (timescaledb.compress, timescaledb.compress_orderby = '"time" DESC', ` +
+					`timescaledb.compress_segmentby = 'tag_id')`,
+				`SELECT add_compression_policy('"public"."cpu"', INTERVAL '3600000000 microseconds', if_not_exists => true)`,
+			},
+		},
+		{
+			name: "explicit segments",
+			settings: TimescaleDB{
+				CompressAfter:     config.Duration(time.Hour),
+				CompressSegmentBy: []string{"host"},
+			},
+			expected: []string{
+				`CREATE TABLE "public"."cpu" ("time" timestamp without time zone, "host" text, "region" text, "value" double precision)`,
+				`SELECT create_hypertable('"public"."cpu"', 'time', chunk_time_interval => INTERVAL '604800000000 microseconds', if_not_exists => true)`,
+				`ALTER TABLE "public"."cpu" SET (timescaledb.compress, timescaledb.compress_orderby = '"time" DESC', ` +
+					`timescaledb.compress_segmentby = '"host"')`,
+				`SELECT add_compression_policy('"public"."cpu"', INTERVAL '3600000000 microseconds', if_not_exists => true)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPostgresql()
+			p.TagsAsForeignKeys = tt.fkeys
+			p.TimescaleDB = &tt.settings
+			require.NoError(t, p.Init())
+
+			table := sqltemplate.NewTable(p.Schema, "cpu", nil)
+			actual := make([]string, 0, len(p.CreateTemplates))
+			for _, tmpl := range p.CreateTemplates {
+				sql, err := tmpl.Render(table, columns, table, nil)
+				require.NoError(t, err)
+				actual = append(actual, string(sql))
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestTimescaleDBInvalidSettings(t *testing.T) {
+	p := newPostgresql()
+	p.TimescaleDB = &TimescaleDB{CompressSegmentBy: []string{"host"}}
+	require.ErrorContains(t, p.Init(), "requires compress_after")
+}
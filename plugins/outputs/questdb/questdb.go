@@ -0,0 +1,369 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package questdb
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type QuestDB struct {
+	URL             string          `toml:"url"`
+	Username        config.Secret   `toml:"username"`
+	Password        config.Secret   `toml:"password"`
+	Token           config.Secret   `toml:"token"`
+	TableTag        string          `toml:"table_tag"`
+	ExcludeTableTag bool            `toml:"exclude_table_tag"`
+	SymbolFields    []string        `toml:"symbol_fields"`
+	StringTags      []string        `toml:"string_tags"`
+	AutoColumnTypes bool            `toml:"auto_column_types"`
+	MaxLag          config.Duration `toml:"o3_max_lag"`
+	Log             telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client       *http.Client
+	cancel       context.CancelFunc
+	serializer   *influx.Serializer
+	symbolFields filter.Filter
+	stringTags   filter.Filter
+
+	// Column type decisions and tables with configured lag per table
+	tables map[string]columnTypes
+	lagged map[string]bool
+	sync.Mutex
+}
+
+// writeResponse is the error response of the QuestDB ILP endpoint
+type writeResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+}
+
+func (*QuestDB) SampleConfig() string {
+	return sampleConfig
+}
+
+func (q *QuestDB) Init() error {
+	if q.URL == "" {
+		q.URL = "http://localhost:9000"
+	}
+	if !q.Token.Empty() && (!q.Username.Empty() || !q.Password.Empty()) {
+		return errors.New("token cannot be used together with username and password")
+	}
+	if q.MaxLag < 0 {
+		return errors.New("o3_max_lag must not be negative")
+	}
+
+	var err error
+	q.symbolFields, err = filter.Compile(q.SymbolFields)
+	if err != nil {
+		return fmt.Errorf("creating symbol field filter failed: %w", err)
+	}
+	q.stringTags, err = filter.Compile(q.StringTags)
+	if err != nil {
+		return fmt.Errorf("creating string tag filter failed: %w", err)
+	}
+
+	q.serializer = &influx.Serializer{SortFields: true}
+	if err := q.serializer.Init(); err != nil {
+		return fmt.Errorf("initializing serializer failed: %w", err)
+	}
+
+	q.tables = make(map[string]columnTypes)
+	q.lagged = make(map[string]bool)
+
+	return nil
+}
+
+func (q *QuestDB) Connect() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	client, err := q.HTTPClientConfig.CreateClient(ctx, q.Log)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	q.client = client
+	q.cancel = cancel
+
+	return nil
+}
+
+func (q *QuestDB) Close() error {
+	if q.cancel != nil {
+		q.cancel()
+	}
+	if q.client != nil {
+		q.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (q *QuestDB) Write(metrics []telegraf.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(q.Timeout))
+	defer cancel()
+
+	q.Lock()
+	defer q.Unlock()
+
+	// Sort the metrics by time to reduce out-of-order ingestion on the server
+	indices := make([]int, 0, len(metrics))
+	for i := range metrics {
+		indices = append(indices, i)
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return metrics[indices[i]].Time().Before(metrics[indices[j]].Time())
+	})
+
+	var buf bytes.Buffer
+	accepted := make([]int, 0, len(metrics))
+	var rejected []int
+	var rejectErrors []error
+	tables := make(map[string]bool)
+	for _, idx := range indices {
+		m, err := q.convert(ctx, metrics[idx])
+		if err != nil {
+			return err
+		}
+		line, err := q.serializer.Serialize(m)
+		if err != nil {
+			q.Log.Errorf("Could not serialize metric: %v", err)
+			rejected = append(rejected, idx)
+			rejectErrors = append(rejectErrors, err)
+			continue
+		}
+		buf.Write(line)
+		accepted = append(accepted, idx)
+		tables[m.Name()] = true
+	}
+
+	if buf.Len() > 0 {
+		if err := q.send(ctx, &buf); err != nil {
+			var werr *writeError
+			if !errors.As(err, &werr) {
+				return err
+			}
+
+			// The request was rejected by the server, retrying will not help
+			q.Log.Errorf("Dropping %d metrics: %v", len(accepted), err)
+			for _, idx := range accepted {
+				rejected = append(rejected, idx)
+				rejectErrors = append(rejectErrors, err)
+			}
+			accepted = nil
+			tables = nil
+		}
+	}
+
+	// Configure the out-of-order lag after the tables are created
+	if q.MaxLag > 0 {
+		for table := range tables {
+			if q.lagged[table] {
+				continue
+			}
+			if err := q.setMaxLag(ctx, table); err != nil {
+				q.Log.Errorf("Setting out-of-order lag failed: %v", err)
+				continue
+			}
+			q.lagged[table] = true
+		}
+	}
+
+	if len(rejected) == 0 {
+		return nil
+	}
+	return &internal.PartialWriteError{
+		Err:                 fmt.Errorf("%d metrics rejected", len(rejected)),
+		MetricsAccept:       accepted,
+		MetricsReject:       rejected,
+		MetricsRejectErrors: rejectErrors,
+	}
+}
+
+// convert returns a metric with the table as name and the tags and fields
+// arranged such that symbol columns are written as tags and string columns
+// as fields
+func (q *QuestDB) convert(ctx context.Context, in telegraf.Metric) (telegraf.Metric, error) {
+	table := in.Name()
+	if q.TableTag != "" {
+		if v, found := in.GetTag(q.TableTag); found && v != "" {
+			table = v
+		}
+	}
+
+	types, err := q.columnTypes(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(in.TagList()))
+	fields := make(map[string]interface{}, len(in.FieldList()))
+	for _, tag := range in.TagList() {
+		if q.ExcludeTableTag && tag.Key == q.TableTag {
+			continue
+		}
+		symbol, found := types[tag.Key]
+		if !found {
+			symbol = !q.stringTags.Match(tag.Key)
+			types[tag.Key] = symbol
+		}
+		if symbol {
+			tags[tag.Key] = tag.Value
+		} else {
+			fields[tag.Key] = tag.Value
+		}
+	}
+	for _, field := range in.FieldList() {
+		v, ok := field.Value.(string)
+		if !ok {
+			fields[field.Key] = field.Value
+			continue
+		}
+		symbol, found := types[field.Key]
+		if !found {
+			symbol = q.symbolFields.Match(field.Key)
+			types[field.Key] = symbol
+		}
+		if symbol {
+			tags[field.Key] = v
+		} else {
+			fields[field.Key] = v
+		}
+	}
+
+	return metric.New(table, tags, fields, in.Time(), in.Type()), nil
+}
+
+// columnTypes returns the cached column type decisions for the given table,
+// querying the existing table schema on first use if enabled
+func (q *QuestDB) columnTypes(ctx context.Context, table string) (columnTypes, error) {
+	if types, found := q.tables[table]; found {
+		return types, nil
+	}
+
+	types := make(columnTypes)
+	if q.AutoColumnTypes {
+		var err error
+		types, err = q.queryColumnTypes(ctx, table)
+		if err != nil {
+			return nil, fmt.Errorf("determining column types failed: %w", err)
+		}
+	}
+	q.tables[table] = types
+
+	return types, nil
+}
+
+// writeError is returned if the server rejected the written data
+type writeError struct {
+	status int
+	msg    string
+}
+
+func (e *writeError) Error() string {
+	return fmt.Sprintf("questdb returned status %d: %s", e.status, e.msg)
+}
+
+func (q *QuestDB) send(ctx context.Context, body io.Reader) error {
+	u := strings.TrimSuffix(q.URL, "/") + "/write?precision=n"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", internal.ProductToken())
+	if err := q.setAuthorization(req); err != nil {
+		return err
+	}
+
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write to QuestDB: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	msg, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("questdb returned status %d (failed to read response body: %w)", resp.StatusCode, err)
+	}
+	var wr writeResponse
+	if json.Unmarshal(msg, &wr) == nil && wr.Message != "" {
+		msg = []byte(fmt.Sprintf("%s (line %d)", wr.Message, wr.Line))
+	}
+
+	// Client errors except for throttling and authentication issues are caused
+	// by invalid data and cannot be fixed by retrying
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return &writeError{status: resp.StatusCode, msg: string(msg)}
+	}
+	return fmt.Errorf("questdb returned status %d: %s", resp.StatusCode, string(msg))
+}
+
+func (q *QuestDB) setAuthorization(req *http.Request) error {
+	if !q.Token.Empty() {
+		token, err := q.Token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.String())
+		token.Destroy()
+		return nil
+	}
+
+	if q.Username.Empty() && q.Password.Empty() {
+		return nil
+	}
+	username, err := q.Username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+	password, err := q.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	defer password.Destroy()
+	req.SetBasicAuth(username.String(), password.String())
+
+	return nil
+}
+
+func init() {
+	outputs.Add("questdb", func() telegraf.Output {
+		return &QuestDB{
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
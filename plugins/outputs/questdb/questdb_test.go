@@ -0,0 +1,203 @@
+package questdb
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &QuestDB{
+		Username: config.NewSecret([]byte("admin")),
+		Token:    config.NewSecret([]byte("secret")),
+	}
+	require.ErrorContains(t, plugin.Init(), "token cannot be used together with username and password")
+
+	plugin = &QuestDB{MaxLag: config.Duration(-time.Second)}
+	require.ErrorContains(t, plugin.Init(), "o3_max_lag must not be negative")
+}
+
+func TestWrite(t *testing.T) {
+	var mu sync.Mutex
+	var written, queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/write":
+			if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "quest" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			written = append(written, strings.Split(strings.TrimSpace(string(body)), "\n")...)
+			w.WriteHeader(http.StatusNoContent)
+		case "/exec":
+			query := r.URL.Query().Get("query")
+			queries = append(queries, query)
+			switch query {
+			case "SHOW COLUMNS FROM 'cpu'":
+				_, _ = w.Write([]byte(`{
+					"query": "SHOW COLUMNS FROM 'cpu'",
+					"columns": [{"name": "column", "type": "STRING"}, {"name": "type", "type": "STRING"}],
+					"dataset": [["host", "VARCHAR"], ["state", "SYMBOL"], ["usage", "DOUBLE"], ["timestamp", "TIMESTAMP"]],
+					"count": 4
+				}`))
+			case "SHOW COLUMNS FROM 'mem'":
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"query": "SHOW COLUMNS FROM 'mem'", "error": "table does not exist [table=mem]", "position": 18}`))
+			default:
+				_, _ = w.Write([]byte(`{"ddl": "OK"}`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &QuestDB{
+		URL:             server.URL,
+		Username:        config.NewSecret([]byte("admin")),
+		Password:        config.NewSecret([]byte("quest")),
+		SymbolFields:    []string{"state", "kind"},
+		StringTags:      []string{"id"},
+		AutoColumnTypes: true,
+		MaxLag:          config.Duration(10 * time.Second),
+		Log:             testutil.Logger{},
+	}
+	plugin.Timeout = config.Duration(5 * time.Second)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("mem",
+			map[string]string{"host": "server01", "id": "a1b2"},
+			map[string]interface{}{"used": int64(42), "kind": "ram"},
+			time.Unix(2, 0),
+		),
+		metric.New("cpu",
+			map[string]string{"host": "server01"},
+			map[string]interface{}{"usage": 12.5, "state": "busy"},
+			time.Unix(1, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// Metrics are sorted by time, the existing "host" column in the "cpu"
+	// table is a string column and therefore must be written as field
+	expected := []string{
+		`cpu,state=busy host="server01",usage=12.5 1000000000`,
+		`mem,host=server01,kind=ram id="a1b2",used=42i 2000000000`,
+	}
+	mu.Lock()
+	require.Equal(t, expected, written)
+	require.ElementsMatch(t, []string{
+		"SHOW COLUMNS FROM 'cpu'",
+		"SHOW COLUMNS FROM 'mem'",
+		`ALTER TABLE "cpu" SET PARAM o3MaxLag = 10000ms`,
+		`ALTER TABLE "mem" SET PARAM o3MaxLag = 10000ms`,
+	}, queries)
+	written, queries = nil, nil
+	mu.Unlock()
+
+	// Column types and lag settings are cached per table
+	require.NoError(t, plugin.Write(metrics))
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, expected, written)
+	require.Empty(t, queries)
+}
+
+func TestSetMaxLagEscaping(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("query"))
+		_, _ = w.Write([]byte(`{"ddl": "OK"}`))
+	}))
+	defer server.Close()
+
+	plugin := &QuestDB{
+		URL:    server.URL,
+		MaxLag: config.Duration(time.Second),
+		Log:    testutil.Logger{},
+	}
+	plugin.Timeout = config.Duration(5 * time.Second)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.setMaxLag(t.Context(), `cpu" SET PARAM maxUncommittedRows = 1; --`))
+	require.Equal(t, []string{
+		`ALTER TABLE "cpu"" SET PARAM maxUncommittedRows = 1; --" SET PARAM o3MaxLag = 1000ms`,
+	}, queries)
+}
+
+func TestWriteRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code": "invalid", "message": "failed to parse line protocol", "line": 1, "errorId": "1"}`))
+	}))
+	defer server.Close()
+
+	plugin := &QuestDB{
+		URL: server.URL,
+		Log: testutil.Logger{},
+	}
+	plugin.Timeout = config.Duration(5 * time.Second)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(1, 0)),
+	}
+	err := plugin.Write(metrics)
+	var werr *internal.PartialWriteError
+	require.ErrorAs(t, err, &werr)
+	require.Empty(t, werr.MetricsAccept)
+	require.ElementsMatch(t, []int{0, 1}, werr.MetricsReject)
+	require.ErrorContains(t, werr.MetricsRejectErrors[0], "failed to parse line protocol (line 1)")
+}
+
+func TestWriteRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	plugin := &QuestDB{
+		URL: server.URL,
+		Log: testutil.Logger{},
+	}
+	plugin.Timeout = config.Duration(5 * time.Second)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+	}
+	err := plugin.Write(metrics)
+	require.ErrorContains(t, err, "questdb returned status 503")
+	var werr *internal.PartialWriteError
+	require.NotErrorAs(t, err, &werr)
+}
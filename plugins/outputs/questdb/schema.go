@@ -0,0 +1,117 @@
+package questdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// columnTypes holds the decision whether a column of a table is written as
+// symbol (true) or as string (false)
+type columnTypes map[string]bool
+
+// execResponse is the response of the QuestDB REST query endpoint
+type execResponse struct {
+	Columns []struct {
+		Name string `json:"name"`
+	} `json:"columns"`
+	Dataset [][]interface{} `json:"dataset"`
+	Error   string          `json:"error"`
+}
+
+// exec runs the given SQL query against the REST endpoint of QuestDB and
+// returns the HTTP status code alongside the decoded response
+func (q *QuestDB) exec(ctx context.Context, query string) (int, *execResponse, error) {
+	u := strings.TrimSuffix(q.URL, "/") + "/exec?query=" + url.QueryEscape(query)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("creating request failed: %w", err)
+	}
+	if err := q.setAuthorization(req); err != nil {
+		return 0, nil, err
+	}
+
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("executing query failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("reading response failed: %w", err)
+	}
+
+	var result execResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("decoding response %q failed: %w", string(body), err)
+	}
+	return resp.StatusCode, &result, nil
+}
+
+// queryColumnTypes returns the symbol and string columns of an existing table.
+// An empty set is returned if the table does not exist yet.
+func (q *QuestDB) queryColumnTypes(ctx context.Context, table string) (columnTypes, error) {
+	status, resp, err := q.exec(ctx, "SHOW COLUMNS FROM '"+strings.ReplaceAll(table, "'", "''")+"'")
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(columnTypes)
+	switch {
+	case status == http.StatusBadRequest && strings.Contains(resp.Error, "does not exist"):
+		return types, nil
+	case status != http.StatusOK:
+		return nil, fmt.Errorf("querying columns of table %q returned status %d: %s", table, status, resp.Error)
+	}
+
+	nameIdx, typeIdx := -1, -1
+	for i, c := range resp.Columns {
+		switch c.Name {
+		case "column":
+			nameIdx = i
+		case "type":
+			typeIdx = i
+		}
+	}
+	if nameIdx < 0 || typeIdx < 0 {
+		return nil, fmt.Errorf("unexpected column description for table %q", table)
+	}
+
+	for _, row := range resp.Dataset {
+		if len(row) <= nameIdx || len(row) <= typeIdx {
+			continue
+		}
+		name, ok := row[nameIdx].(string)
+		if !ok {
+			continue
+		}
+		switch row[typeIdx] {
+		case "SYMBOL":
+			types[name] = true
+		case "STRING", "VARCHAR":
+			types[name] = false
+		}
+	}
+	return types, nil
+}
+
+// setMaxLag configures the maximum out-of-order lag for the given table
+func (q *QuestDB) setMaxLag(ctx context.Context, table string) error {
+	// The table name originates from the metric, so escape it as identifier
+	identifier := `"` + strings.ReplaceAll(table, `"`, `""`) + `"`
+	query := fmt.Sprintf(`ALTER TABLE %s SET PARAM o3MaxLag = %dms`, identifier, time.Duration(q.MaxLag).Milliseconds())
+	status, resp, err := q.exec(ctx, query)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("setting out-of-order lag for table %q returned status %d: %s", table, status, resp.Error)
+	}
+	return nil
+}
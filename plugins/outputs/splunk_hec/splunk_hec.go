@@ -0,0 +1,343 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package splunk_hec
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	eventEndpoint = "/services/collector/event"
+	ackEndpoint   = "/services/collector/ack"
+)
+
+type SplunkHEC struct {
+	URL             string          `toml:"url"`
+	Token           config.Secret   `toml:"token"`
+	Index           string          `toml:"index"`
+	Source          string          `toml:"source"`
+	SourceType      string          `toml:"sourcetype"`
+	IndexTag        string          `toml:"index_tag"`
+	SourceTag       string          `toml:"source_tag"`
+	SourceTypeTag   string          `toml:"sourcetype_tag"`
+	HostTag         string          `toml:"host_tag"`
+	MultiMetric     bool            `toml:"multi_metric"`
+	UseAck          bool            `toml:"use_ack"`
+	Channel         string          `toml:"channel"`
+	AckPollInterval config.Duration `toml:"ack_poll_interval"`
+	AckTimeout      config.Duration `toml:"ack_timeout"`
+	GZipRequest     bool            `toml:"gzip_request"`
+	Timeout         config.Duration `toml:"timeout"`
+	Log             telegraf.Logger `toml:"-"`
+	tls.ClientConfig
+
+	eventURL string
+	ackURL   string
+	client   *http.Client
+}
+
+// event is a single metric event in the HEC format
+type event struct {
+	Time       float64                `json:"time"`
+	Event      string                 `json:"event"`
+	Host       string                 `json:"host,omitempty"`
+	Index      string                 `json:"index,omitempty"`
+	Source     string                 `json:"source,omitempty"`
+	SourceType string                 `json:"sourcetype,omitempty"`
+	Fields     map[string]interface{} `json:"fields"`
+}
+
+// response is the reply of the collector to an event request
+type response struct {
+	Text  string `json:"text"`
+	Code  int    `json:"code"`
+	AckID *int64 `json:"ackId"`
+}
+
+func (*SplunkHEC) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SplunkHEC) Init() error {
+	if s.URL == "" {
+		return errors.New("url required")
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("parsing url failed: %w", err)
+	}
+	if s.Token.Empty() {
+		return errors.New("token required")
+	}
+	if s.UseAck {
+		if s.AckPollInterval <= 0 {
+			return errors.New("ack_poll_interval must be positive")
+		}
+		if s.AckTimeout < s.AckPollInterval {
+			return errors.New("ack_timeout must not be smaller than ack_poll_interval")
+		}
+	}
+	if s.Channel == "" {
+		s.Channel = uuid.NewString()
+	}
+
+	base := strings.TrimSuffix(u.String(), "/")
+	s.eventURL = base + eventEndpoint
+	s.ackURL = base + ackEndpoint + "?channel=" + url.QueryEscape(s.Channel)
+
+	return nil
+}
+
+func (s *SplunkHEC) Connect() error {
+	tlsCfg, err := s.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("creating TLS config failed: %w", err)
+	}
+
+	s.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsCfg,
+		},
+		Timeout: time.Duration(s.Timeout),
+	}
+
+	return nil
+}
+
+func (s *SplunkHEC) Close() error {
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (s *SplunkHEC) Write(metrics []telegraf.Metric) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, m := range metrics {
+		for _, e := range s.createEvents(m) {
+			if err := encoder.Encode(e); err != nil {
+				return fmt.Errorf("encoding event failed: %w", err)
+			}
+		}
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+
+	var resp response
+	if err := s.post(s.eventURL, &body, &resp); err != nil {
+		return err
+	}
+	if !s.UseAck {
+		return nil
+	}
+	if resp.AckID == nil {
+		return errors.New("no acknowledgement ID received, check that indexer acknowledgement is enabled for the token")
+	}
+
+	return s.waitForAck(*resp.AckID)
+}
+
+// createEvents converts the metric to events applying the routing tags
+func (s *SplunkHEC) createEvents(m telegraf.Metric) []*event {
+	common := event{
+		Time:       float64(m.Time().UnixNano()) / float64(time.Second),
+		Event:      "metric",
+		Index:      s.Index,
+		Source:     s.Source,
+		SourceType: s.SourceType,
+	}
+
+	dimensions := make(map[string]interface{}, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		switch tag.Key {
+		case s.IndexTag:
+			common.Index = tag.Value
+		case s.SourceTag:
+			common.Source = tag.Value
+		case s.SourceTypeTag:
+			common.SourceType = tag.Value
+		case s.HostTag:
+			common.Host = tag.Value
+		default:
+			dimensions[tag.Key] = tag.Value
+		}
+	}
+
+	if s.MultiMetric {
+		e := common
+		e.Fields = make(map[string]interface{}, len(dimensions)+len(m.FieldList()))
+		for k, v := range dimensions {
+			e.Fields[k] = v
+		}
+		for _, field := range m.FieldList() {
+			if v, ok := convertValue(field.Value); ok {
+				e.Fields["metric_name:"+m.Name()+"."+field.Key] = v
+			}
+		}
+		if len(e.Fields) == len(dimensions) {
+			return nil
+		}
+		return []*event{&e}
+	}
+
+	events := make([]*event, 0, len(m.FieldList()))
+	for _, field := range m.FieldList() {
+		v, ok := convertValue(field.Value)
+		if !ok {
+			s.Log.Debugf("Skipping non-numeric or non-finite field %q of metric %q", field.Key, m.Name())
+			continue
+		}
+		e := common
+		e.Fields = make(map[string]interface{}, len(dimensions)+2)
+		for k, v := range dimensions {
+			e.Fields[k] = v
+		}
+		e.Fields["metric_name"] = m.Name() + "." + field.Key
+		e.Fields["_value"] = v
+		events = append(events, &e)
+	}
+	return events
+}
+
+// waitForAck polls the acknowledgement status of the given ID until the
+// events are indexed or the timeout is reached
+func (s *SplunkHEC) waitForAck(id int64) error {
+	ticker := time.NewTicker(time.Duration(s.AckPollInterval))
+	defer ticker.Stop()
+	deadline := time.Now().Add(time.Duration(s.AckTimeout))
+
+	request := map[string][]int64{"acks": {id}}
+	key := strconv.FormatInt(id, 10)
+	for {
+		body, err := json.Marshal(request)
+		if err != nil {
+			return fmt.Errorf("encoding acknowledgement request failed: %w", err)
+		}
+
+		var resp struct {
+			Acks map[string]bool `json:"acks"`
+		}
+		if err := s.post(s.ackURL, bytes.NewReader(body), &resp); err != nil {
+			return fmt.Errorf("polling acknowledgement failed: %w", err)
+		}
+		if resp.Acks[key] {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("acknowledgement %d not received within %s", id, time.Duration(s.AckTimeout))
+		}
+		<-ticker.C
+	}
+}
+
+// post sends the body to the given URL and decodes the response
+func (s *SplunkHEC) post(u string, body io.Reader, result interface{}) error {
+	if s.GZipRequest {
+		rc := internal.CompressWithGzip(body)
+		defer rc.Close()
+		body = rc
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return fmt.Errorf("creating request failed: %w", err)
+	}
+
+	token, err := s.Token.Get()
+	if err != nil {
+		return fmt.Errorf("getting token failed: %w", err)
+	}
+	req.Header.Set("Authorization", "Splunk "+token.String())
+	token.Destroy()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", internal.ProductToken())
+	req.Header.Set("X-Splunk-Request-Channel", s.Channel)
+	if s.GZipRequest {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response failed: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var r response
+		if err := json.Unmarshal(buf, &r); err == nil && r.Text != "" {
+			return fmt.Errorf("received status %d: %s (code %d)", resp.StatusCode, r.Text, r.Code)
+		}
+		return fmt.Errorf("received status %d: %s", resp.StatusCode, string(buf))
+	}
+
+	if err := json.Unmarshal(buf, result); err != nil {
+		return fmt.Errorf("decoding response failed: %w", err)
+	}
+	return nil
+}
+
+// convertValue returns the value in a representation accepted by the
+// metrics index; non-numeric values cannot be stored and non-finite floats
+// cannot be encoded as JSON
+func convertValue(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case string:
+		return nil, false
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return v, true
+}
+
+func init() {
+	outputs.Add("splunk_hec", func() telegraf.Output {
+		return &SplunkHEC{
+			Source:          "telegraf",
+			IndexTag:        "splunk_index",
+			SourceTag:       "splunk_source",
+			SourceTypeTag:   "splunk_sourcetype",
+			HostTag:         "host",
+			AckPollInterval: config.Duration(time.Second),
+			AckTimeout:      config.Duration(30 * time.Second),
+			Timeout:         config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,348 @@
+package splunk_hec
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *SplunkHEC
+		expected string
+	}{
+		{
+			name:     "no url",
+			plugin:   &SplunkHEC{Token: config.NewSecret([]byte("token"))},
+			expected: "url required",
+		},
+		{
+			name:     "no token",
+			plugin:   &SplunkHEC{URL: "http://localhost:8088"},
+			expected: "token required",
+		},
+		{
+			name: "ack timeout too small",
+			plugin: &SplunkHEC{
+				URL:             "http://localhost:8088",
+				Token:           config.NewSecret([]byte("token")),
+				UseAck:          true,
+				AckPollInterval: config.Duration(time.Second),
+				AckTimeout:      config.Duration(time.Millisecond),
+			},
+			expected: "ack_timeout must not be smaller than ack_poll_interval",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != eventEndpoint {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Splunk secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		decoder := json.NewDecoder(r.Body)
+		for decoder.More() {
+			var e map[string]interface{}
+			if err := decoder.Decode(&e); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			received = append(received, e)
+		}
+		_, _ = w.Write([]byte(`{"text":"Success","code":0}`))
+	}))
+	defer server.Close()
+
+	plugin := &SplunkHEC{
+		URL:           server.URL,
+		Token:         config.NewSecret([]byte("secret")),
+		Index:         "metrics",
+		Source:        "telegraf",
+		IndexTag:      "splunk_index",
+		SourceTag:     "splunk_source",
+		SourceTypeTag: "splunk_sourcetype",
+		HostTag:       "host",
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 99.5, "state": "ok"},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "b", "splunk_index": "infra", "splunk_sourcetype": "memory"},
+			map[string]interface{}{"swapped": true},
+			time.Unix(1700000001, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	expected := []map[string]interface{}{
+		{
+			"time":   float64(1700000000),
+			"event":  "metric",
+			"host":   "a",
+			"index":  "metrics",
+			"source": "telegraf",
+			"fields": map[string]interface{}{
+				"cpu":         "cpu0",
+				"metric_name": "cpu.usage_idle",
+				"_value":      99.5,
+			},
+		},
+		{
+			"time":       float64(1700000001),
+			"event":      "metric",
+			"host":       "b",
+			"index":      "infra",
+			"source":     "telegraf",
+			"sourcetype": "memory",
+			"fields": map[string]interface{}{
+				"metric_name": "mem.swapped",
+				"_value":      float64(1),
+			},
+		},
+	}
+	require.Equal(t, expected, received)
+}
+
+func TestWriteMultiMetric(t *testing.T) {
+	plugin := &SplunkHEC{Source: "telegraf", HostTag: "host", MultiMetric: true}
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "a", "cpu": "cpu0"},
+		map[string]interface{}{"usage_idle": 99.5, "usage_user": 0.5, "state": "ok"},
+		time.Unix(0, 500*int64(time.Millisecond)),
+	)
+	expected := []*event{
+		{
+			Time:   0.5,
+			Event:  "metric",
+			Host:   "a",
+			Source: "telegraf",
+			Fields: map[string]interface{}{
+				"cpu":                        "cpu0",
+				"metric_name:cpu.usage_idle": 99.5,
+				"metric_name:cpu.usage_user": 0.5,
+			},
+		},
+	}
+	require.Equal(t, expected, plugin.createEvents(m))
+}
+
+func TestWriteNonFinite(t *testing.T) {
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decoder := json.NewDecoder(r.Body)
+		for decoder.More() {
+			var e map[string]interface{}
+			if err := decoder.Decode(&e); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			received = append(received, e)
+		}
+		_, _ = w.Write([]byte(`{"text":"Success","code":0}`))
+	}))
+	defer server.Close()
+
+	plugin := &SplunkHEC{
+		URL:     server.URL,
+		Token:   config.NewSecret([]byte("secret")),
+		Source:  "telegraf",
+		HostTag: "host",
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": math.NaN(), "usage_user": 0.5},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"disk",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"free": math.Inf(1), "used": math.Inf(-1)},
+			time.Unix(1700000000, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	expected := []map[string]interface{}{
+		{
+			"time":   float64(1700000000),
+			"event":  "metric",
+			"host":   "a",
+			"source": "telegraf",
+			"fields": map[string]interface{}{
+				"metric_name": "cpu.usage_user",
+				"_value":      0.5,
+			},
+		},
+	}
+	require.Equal(t, expected, received)
+
+	// Multi-metric events must skip the values as well
+	plugin.MultiMetric = true
+	require.Equal(t, []*event{
+		{
+			Time:   1700000000,
+			Event:  "metric",
+			Host:   "a",
+			Source: "telegraf",
+			Fields: map[string]interface{}{"metric_name:cpu.usage_user": 0.5},
+		},
+	}, plugin.createEvents(metrics[0]))
+	require.Empty(t, plugin.createEvents(metrics[1]))
+}
+
+func TestWriteAck(t *testing.T) {
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Splunk-Request-Channel") != "test-channel" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"text":"Data channel is missing","code":10}`))
+			return
+		}
+		switch r.URL.Path {
+		case eventEndpoint:
+			_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":42}`))
+		case ackEndpoint:
+			var req struct {
+				Acks []int64 `json:"acks"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Acks) != 1 || req.Acks[0] != 42 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			// Acknowledge on the second poll only
+			if polls.Add(1) < 2 {
+				_, _ = w.Write([]byte(`{"acks":{"42":false}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"acks":{"42":true}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &SplunkHEC{
+		URL:             server.URL,
+		Token:           config.NewSecret([]byte("secret")),
+		UseAck:          true,
+		Channel:         "test-channel",
+		AckPollInterval: config.Duration(10 * time.Millisecond),
+		AckTimeout:      config.Duration(time.Second),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write(testutil.MockMetrics()))
+	require.Equal(t, int32(2), polls.Load())
+}
+
+func TestWriteAckTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case eventEndpoint:
+			_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":1}`))
+		case ackEndpoint:
+			_, _ = w.Write([]byte(`{"acks":{"1":false}}`))
+		}
+	}))
+	defer server.Close()
+
+	plugin := &SplunkHEC{
+		URL:             server.URL,
+		Token:           config.NewSecret([]byte("secret")),
+		UseAck:          true,
+		AckPollInterval: config.Duration(10 * time.Millisecond),
+		AckTimeout:      config.Duration(50 * time.Millisecond),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.ErrorContains(t, plugin.Write(testutil.MockMetrics()), "acknowledgement 1 not received")
+}
+
+func TestWriteAckDisabledOnServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"text":"Success","code":0}`))
+	}))
+	defer server.Close()
+
+	plugin := &SplunkHEC{
+		URL:             server.URL,
+		Token:           config.NewSecret([]byte("secret")),
+		UseAck:          true,
+		AckPollInterval: config.Duration(10 * time.Millisecond),
+		AckTimeout:      config.Duration(50 * time.Millisecond),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.ErrorContains(t, plugin.Write(testutil.MockMetrics()), "no acknowledgement ID received")
+}
+
+func TestWriteError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"text":"Invalid token","code":4}`))
+	}))
+	defer server.Close()
+
+	plugin := &SplunkHEC{
+		URL:   server.URL,
+		Token: config.NewSecret([]byte("secret")),
+		Log:   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.ErrorContains(t, plugin.Write(testutil.MockMetrics()), "received status 403: Invalid token (code 4)")
+}
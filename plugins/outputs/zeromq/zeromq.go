@@ -0,0 +1,239 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package zeromq
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-zeromq/zmq4"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type ZeroMQ struct {
+	Endpoints  []string        `toml:"endpoints"`
+	Mode       string          `toml:"mode"`
+	SocketType string          `toml:"socket_type"`
+	Topic      string          `toml:"topic"`
+	Timeout    config.Duration `toml:"timeout"`
+	Log        telegraf.Logger `toml:"-"`
+
+	serializer telegraf.Serializer
+	topic      *template.Template
+	socket     zmq4.Socket
+	cancel     context.CancelFunc
+}
+
+func (*ZeroMQ) SampleConfig() string {
+	return sampleConfig
+}
+
+func (z *ZeroMQ) Init() error {
+	if len(z.Endpoints) == 0 {
+		return errors.New("no endpoints specified")
+	}
+
+	switch z.Mode {
+	case "":
+		z.Mode = "bind"
+	case "bind", "connect":
+	default:
+		return fmt.Errorf("invalid mode %q", z.Mode)
+	}
+	for _, endpoint := range z.Endpoints {
+		if err := checkEndpoint(endpoint, z.Mode == "bind"); err != nil {
+			return err
+		}
+	}
+
+	switch z.SocketType {
+	case "":
+		z.SocketType = "pub"
+	case "pub", "push":
+	default:
+		return fmt.Errorf("invalid socket type %q", z.SocketType)
+	}
+
+	if z.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+
+	if z.Topic != "" {
+		tmpl, err := template.New("topic").Parse(z.Topic)
+		if err != nil {
+			return fmt.Errorf("parsing topic template failed: %w", err)
+		}
+		z.topic = tmpl
+	}
+
+	return nil
+}
+
+func (z *ZeroMQ) SetSerializer(serializer telegraf.Serializer) {
+	z.serializer = serializer
+}
+
+func (z *ZeroMQ) Connect() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	z.cancel = cancel
+
+	// Connections to consumers bound to the endpoints are re-established by
+	// the socket if they are lost
+	timeout := time.Duration(z.Timeout)
+	options := []zmq4.Option{
+		zmq4.WithTimeout(timeout),
+		zmq4.WithDialerTimeout(timeout),
+		zmq4.WithAutomaticReconnect(true),
+	}
+	switch z.SocketType {
+	case "pub":
+		z.socket = zmq4.NewPub(ctx, options...)
+	case "push":
+		z.socket = zmq4.NewPush(ctx, options...)
+	}
+
+	for _, endpoint := range z.Endpoints {
+		if z.Mode == "connect" {
+			if err := z.socket.Dial(endpoint); err != nil {
+				z.Close()
+				return fmt.Errorf("connecting to %q failed: %w", endpoint, err)
+			}
+			z.Log.Debugf("Connected to %q", endpoint)
+			continue
+		}
+		if err := z.socket.Listen(endpoint); err != nil {
+			z.Close()
+			return fmt.Errorf("binding to %q failed: %w", endpoint, err)
+		}
+	}
+
+	return nil
+}
+
+func (z *ZeroMQ) Close() error {
+	var err error
+	if z.socket != nil {
+		err = z.socket.Close()
+		z.socket = nil
+	}
+	if z.cancel != nil {
+		z.cancel()
+	}
+	return err
+}
+
+func (z *ZeroMQ) Write(metrics []telegraf.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	// Group the metrics by topic keeping the order of the topics
+	var topics []string
+	batches := make(map[string][]int)
+	var rejected []int
+	var buf bytes.Buffer
+	for i, m := range metrics {
+		var topic string
+		if z.topic != nil {
+			if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+				m = wm.Unwrap()
+			}
+			buf.Reset()
+			if err := z.topic.Execute(&buf, m); err != nil {
+				z.Log.Errorf("Executing topic template for metric %q failed: %v", m.Name(), err)
+				rejected = append(rejected, i)
+				continue
+			}
+			topic = buf.String()
+		}
+		if _, found := batches[topic]; !found {
+			topics = append(topics, topic)
+		}
+		batches[topic] = append(batches[topic], i)
+	}
+
+	accepted := make([]int, 0, len(metrics))
+	var sendErr error
+	for _, topic := range topics {
+		indices := batches[topic]
+		batch := make([]telegraf.Metric, 0, len(indices))
+		for _, i := range indices {
+			batch = append(batch, metrics[i])
+		}
+		payload, err := z.serializer.SerializeBatch(batch)
+		if err != nil {
+			z.Log.Errorf("Serializing batch for topic %q failed: %v", topic, err)
+			rejected = append(rejected, indices...)
+			continue
+		}
+
+		// Messages of PUB sockets are dropped for topics without subscribers
+		if z.topic != nil {
+			err = z.socket.SendMulti(zmq4.NewMsgFrom([]byte(topic), payload))
+		} else {
+			err = z.socket.Send(zmq4.NewMsg(payload))
+		}
+		if err != nil {
+			sendErr = fmt.Errorf("sending batch for topic %q failed: %w", topic, err)
+			break
+		}
+		accepted = append(accepted, indices...)
+	}
+
+	if sendErr == nil && len(rejected) == 0 {
+		return nil
+	}
+	if sendErr == nil {
+		sendErr = fmt.Errorf("rejected %d metrics", len(rejected))
+	}
+	return &internal.PartialWriteError{
+		Err:           sendErr,
+		MetricsAccept: accepted,
+		MetricsReject: rejected,
+	}
+}
+
+// checkEndpoint validates the endpoint in ZeroMQ notation. The "*" wildcard
+// interface is supported for binding only.
+func checkEndpoint(endpoint string, bind bool) error {
+	transport, address, found := strings.Cut(endpoint, "://")
+	if !found || address == "" {
+		return fmt.Errorf("invalid endpoint %q", endpoint)
+	}
+
+	switch transport {
+	case "tcp":
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+		}
+		if host == "*" && !bind {
+			return fmt.Errorf("wildcard interface in endpoint %q is only supported for binding", endpoint)
+		}
+		return nil
+	case "ipc":
+		return nil
+	}
+	return fmt.Errorf("unsupported transport %q in endpoint %q", transport, endpoint)
+}
+
+func init() {
+	outputs.Add("zeromq", func() telegraf.Output {
+		return &ZeroMQ{
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
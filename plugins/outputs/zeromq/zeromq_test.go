@@ -0,0 +1,183 @@
+package zeromq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-zeromq/zmq4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *ZeroMQ
+		expected string
+	}{
+		{
+			name:     "no endpoints",
+			plugin:   &ZeroMQ{},
+			expected: "no endpoints specified",
+		},
+		{
+			name:     "invalid transport",
+			plugin:   &ZeroMQ{Endpoints: []string{"udp://127.0.0.1:5556"}},
+			expected: `unsupported transport "udp"`,
+		},
+		{
+			name:     "wildcard when connecting",
+			plugin:   &ZeroMQ{Endpoints: []string{"tcp://*:5556"}, Mode: "connect"},
+			expected: "only supported for binding",
+		},
+		{
+			name:     "invalid mode",
+			plugin:   &ZeroMQ{Endpoints: []string{"tcp://*:5556"}, Mode: "foo"},
+			expected: `invalid mode "foo"`,
+		},
+		{
+			name:     "invalid socket type",
+			plugin:   &ZeroMQ{Endpoints: []string{"tcp://*:5556"}, SocketType: "req"},
+			expected: `invalid socket type "req"`,
+		},
+		{
+			name: "invalid topic",
+			plugin: &ZeroMQ{
+				Endpoints: []string{"tcp://*:5556"},
+				Topic:     "{{ .Name ",
+				Timeout:   config.Duration(time.Second),
+			},
+			expected: "parsing topic template failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPublish(t *testing.T) {
+	plugin := &ZeroMQ{
+		Endpoints: []string{"tcp://127.0.0.1:0"},
+		Topic:     `{{ .Name }}.{{ .Tag "host" }}`,
+		Timeout:   config.Duration(time.Second),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Connect a subscriber for the CPU metrics only
+	sub := zmq4.NewSub(t.Context())
+	defer sub.Close()
+	require.NoError(t, sub.Dial("tcp://"+plugin.socket.Addr().String()))
+	require.NoError(t, sub.SetOption(zmq4.OptionSubscribe, "cpu."))
+	messages := receive(sub)
+
+	// Messages published before the subscription reached the plugin are
+	// dropped, so wait for a probe message to pass
+	probe := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "probe"}, map[string]interface{}{"value": 0}, time.Unix(0, 0)),
+	}
+	require.Eventually(t, func() bool {
+		require.NoError(t, plugin.Write(probe))
+		select {
+		case <-messages:
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 4}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// The metrics with the same topic are received in one message and the
+	// topic without subscription is dropped
+	var actual [][][]byte
+	for len(actual) < 2 {
+		select {
+		case frames := <-messages:
+			// Skip late probe messages
+			if string(frames[0]) != "cpu.probe" {
+				actual = append(actual, frames)
+			}
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timeout waiting for messages")
+		}
+	}
+	require.Equal(t, [][][]byte{
+		{
+			[]byte("cpu.a"),
+			[]byte("cpu,host=a value=1i 0\ncpu,host=a value=3i 0\n"),
+		},
+		{
+			[]byte("cpu.b"),
+			[]byte("cpu,host=b value=4i 0\n"),
+		},
+	}, actual)
+}
+
+func TestPush(t *testing.T) {
+	// Bind a PULL consumer the plugin connects to
+	pull := zmq4.NewPull(t.Context())
+	defer pull.Close()
+	require.NoError(t, pull.Listen("tcp://127.0.0.1:0"))
+
+	plugin := &ZeroMQ{
+		Endpoints:  []string{"tcp://" + pull.Addr().String()},
+		Mode:       "connect",
+		SocketType: "push",
+		Timeout:    config.Duration(time.Second),
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	select {
+	case frames := <-receive(pull):
+		require.Equal(t, [][]byte{[]byte("cpu value=1i 0\nmem value=2i 0\n")}, frames)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for message")
+	}
+}
+
+// receive forwards the frames of the messages received by the socket
+func receive(s zmq4.Socket) <-chan [][]byte {
+	messages := make(chan [][]byte, 10)
+	go func() {
+		for {
+			msg, err := s.Recv()
+			if err != nil {
+				return
+			}
+			messages <- msg.Frames
+		}
+	}()
+	return messages
+}
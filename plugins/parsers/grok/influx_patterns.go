@@ -40,4 +40,48 @@ HTTPD_ERRORLOG %{HTTPD20_ERRORLOG}|%{HTTPD24_ERRORLOG}
 
 # DATA spanning multiple lines
 MULTILINEDATA (.|\n)*
+
+##
+## NGINX
+##
+
+# nginx access logs using the default "combined" log format are covered by
+# the COMBINED_LOG_FORMAT pattern above.
+# nginx error logs, e.g.
+#   2016/06/04 12:41:45 [error] 1234#5678: *99 open() failed (2: No such file or directory)
+NGINX_ERROR_DATE %{YEAR}/%{MONTHNUM}/%{MONTHDAY} %{TIME}
+NGINX_ERROR_LOG %{NGINX_ERROR_DATE:timestamp:ts-"2006/01/02 15:04:05"} \[%{LOGLEVEL:level:tag}\] %{POSINT:pid:int}#%{NUMBER:tid:int}: (?:\*%{NUMBER:connection_id:int} )?%{GREEDYDATA:message}
+
+##
+## HAPROXY
+##
+
+# Optional syslog prefix as found in log files written by the syslog daemon
+HAPROXY_SYSLOG_PREFIX %{SYSLOGTIMESTAMP} %{SYSLOGHOST} %{PROG}(?:\[%{POSINT}\])?:
+HAPROXY_DATE %{MONTHDAY}/%{MONTH}/%{YEAR}:%{TIME}
+# HTTP logs as produced by "option httplog"
+HAPROXY_HTTP_LOG (?:%{HAPROXY_SYSLOG_PREFIX} )?%{IP:client_ip}:%{INT:client_port:int} \[%{HAPROXY_DATE:accept_date:ts-"02/Jan/2006:15:04:05.000"}\] %{NOTSPACE:frontend:tag} %{NOTSPACE:backend:tag}/%{NOTSPACE:server:tag} %{INT:time_request:int}/%{INT:time_queue:int}/%{INT:time_backend_connect:int}/%{INT:time_backend_response:int}/\+?%{INT:time_duration:int} %{INT:http_status_code:tag} \+?%{INT:bytes_read:int} %{NOTSPACE} %{NOTSPACE} %{NOTSPACE:termination_state} %{INT:actconn:int}/%{INT:feconn:int}/%{INT:beconn:int}/%{INT:srvconn:int}/\+?%{INT:retries:int} %{INT:srv_queue:int}/%{INT:backend_queue:int}(?: \{%{DATA}\})* "%{DATA:request}"
+# TCP logs as produced by "option tcplog"
+HAPROXY_TCP_LOG (?:%{HAPROXY_SYSLOG_PREFIX} )?%{IP:client_ip}:%{INT:client_port:int} \[%{HAPROXY_DATE:accept_date:ts-"02/Jan/2006:15:04:05.000"}\] %{NOTSPACE:frontend:tag} %{NOTSPACE:backend:tag}/%{NOTSPACE:server:tag} %{INT:time_queue:int}/%{INT:time_backend_connect:int}/\+?%{INT:time_duration:int} \+?%{INT:bytes_read:int} %{NOTSPACE:termination_state} %{INT:actconn:int}/%{INT:feconn:int}/%{INT:beconn:int}/%{INT:srvconn:int}/\+?%{INT:retries:int} %{INT:srv_queue:int}/%{INT:backend_queue:int}
+
+##
+## POSTFIX
+##
+
+POSTFIX_QUEUEID (?:[0-9A-F]{6,}|[0-9a-zA-Z]{12,}|NOQUEUE)
+POSTFIX_PREFIX %{SYSLOGTIMESTAMP:timestamp:ts-syslog} %{SYSLOGHOST:hostname} postfix(?:/%{NOTSPACE:component:tag})?\[%{POSINT:pid:int}\]:
+# Any postfix log line, e.g.
+#   Jun  4 12:41:45 mail postfix/smtpd[1234]: 4BC0A1A2B3: client=unknown[192.168.1.1]
+POSTFIX_LOG %{POSTFIX_PREFIX} (?:%{POSTFIX_QUEUEID:queue_id}: )?%{GREEDYDATA:message}
+# Delivery status lines, e.g.
+#   Jun  4 12:41:45 mail postfix/smtp[1234]: 4BC0A1A2B3: to=<user@example.com>, relay=mx.example.com[192.168.1.2]:25, delay=0.52, delays=0.1/0/0.2/0.22, dsn=2.0.0, status=sent (250 OK)
+POSTFIX_DELIVERY_LOG %{POSTFIX_PREFIX} %{POSTFIX_QUEUEID:queue_id}: to=<%{DATA:to}>,(?: orig_to=<%{DATA:orig_to}>,)? relay=%{NOTSPACE:relay}, (?:conn_use=%{INT:conn_use:int}, )?delay=%{NUMBER:delay:float}, (?:delays=%{NOTSPACE:delays}, )?(?:dsn=%{NOTSPACE:dsn}, )?status=%{WORD:status:tag} %{GREEDYDATA:message}
+
+##
+## JOURNALD
+##
+
+# Output of "journalctl -o short-iso", e.g.
+#   2016-06-04T12:41:45+01:00 myhost sshd[1234]: Accepted publickey for user
+JOURNALD_LOG %{TIMESTAMP_ISO8601:timestamp:ts-rfc3339} %{SYSLOGHOST:hostname} %{PROG:program:tag}(?:\[%{POSINT:pid:int}\])?: %{GREEDYDATA:message}
 `
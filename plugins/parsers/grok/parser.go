@@ -14,6 +14,7 @@ import (
 	"github.com/vjeantet/grok"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers"
@@ -48,6 +49,8 @@ const (
 	String           = "string"
 	Duration         = "duration"
 	Drop             = "drop"
+	Bool             = "bool"
+	DurationSeconds  = "duration_seconds"
 	Epoch            = "EPOCH"
 	EpochMilli       = "EPOCH_MILLI"
 	EpochNano        = "EPOCH_NANO"
@@ -55,6 +58,17 @@ const (
 	GenericTimestamp = "GENERIC_TIMESTAMP"
 )
 
+// fieldTypes maps the types available for the grok_types option to the
+// corresponding modifier
+var fieldTypes = map[string]string{
+	"string":    String,
+	"int":       Int,
+	"float":     Float,
+	"bool":      Bool,
+	"duration":  DurationSeconds,
+	"timestamp": GenericTimestamp,
+}
+
 var (
 	// matches named captures that contain a modifier.
 	//   ie,
@@ -76,18 +90,30 @@ type Parser struct {
 	Multiline          bool              `toml:"grok_multiline"`
 	Timezone           string            `toml:"grok_timezone"`
 	UniqueTimestamp    string            `toml:"grok_unique_timestamp"`
+	Types              map[string]string `toml:"grok_types"`
+	ReloadInterval     config.Duration   `toml:"grok_custom_pattern_files_reload_interval"`
 	Measurement        string            `toml:"-"`
 	DefaultTags        map[string]string `toml:"-"`
 	Log                telegraf.Logger   `toml:"-"`
 
 	loc            *time.Location
 	typeMap        map[string]map[string]string
+	fieldTypeMap   map[string]string
 	tsMap          map[string]map[string]string
 	patternsMap    map[string]string
 	foundTSLayouts []string
 	g              *grok.Grok
 	tsModder       *tsModder
 	timeFunc       func() time.Time
+
+	// State of the custom pattern files for detecting changes
+	patternFiles map[string]patternFileState
+	lastCheck    time.Time
+}
+
+type patternFileState struct {
+	modTime time.Time
+	size    int64
 }
 
 func (p *Parser) Init() error {
@@ -103,16 +129,14 @@ func (p *Parser) Init() error {
 		p.Timezone = "UTC"
 	}
 
-	p.typeMap = make(map[string]map[string]string)
-	p.tsMap = make(map[string]map[string]string)
-	p.patternsMap = make(map[string]string)
-	p.tsModder = &tsModder{}
-
-	g, err := grok.NewWithConfig(&grok.Config{NamedCapturesOnly: true})
-	if err != nil {
-		return err
+	p.fieldTypeMap = make(map[string]string, len(p.Types))
+	for field, typ := range p.Types {
+		modifier, found := fieldTypes[typ]
+		if !found {
+			return fmt.Errorf("invalid type %q for field %q", typ, field)
+		}
+		p.fieldTypeMap[field] = modifier
 	}
-	p.g = g
 
 	// Give Patterns fake names so that they can be treated as named
 	// "custom patterns"
@@ -134,33 +158,138 @@ func (p *Parser) Init() error {
 	// Combine user-supplied CustomPatterns with DEFAULT_PATTERNS and parse
 	// them together as the same type of pattern.
 	p.CustomPatterns = DefaultPatterns + p.CustomPatterns
-	if len(p.CustomPatterns) != 0 {
-		scanner := bufio.NewScanner(strings.NewReader(p.CustomPatterns))
-		p.addCustomPatterns(scanner)
+
+	p.tsModder = &tsModder{}
+	p.lastCheck = time.Now()
+
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		p.Log.Warnf("Improper timezone supplied (%s), setting loc to UTC", p.Timezone)
+		loc = time.UTC
+	}
+	p.loc = loc
+
+	if p.timeFunc == nil {
+		p.timeFunc = time.Now
+	}
+
+	return p.compilePatterns()
+}
+
+// SetTimezone sets the timezone for timestamps without zone information,
+// overriding the configured timezone
+func (p *Parser) SetTimezone(loc *time.Location) {
+	p.loc = loc
+	p.Timezone = loc.String()
+}
+
+// SetPatterns replaces the configured patterns and named patterns by the
+// given patterns, keeping the custom patterns, and recompiles the patterns
+func (p *Parser) SetPatterns(patterns []string) error {
+	var custom strings.Builder
+	named := make([]string, 0, len(patterns))
+	for i, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		name := fmt.Sprintf("GROK_INTERNAL_OVERRIDE_PATTERN_%d", i)
+		custom.WriteString("\n" + name + " " + pattern + "\n")
+		named = append(named, "%{"+name+"}")
+	}
+	if len(named) == 0 {
+		return errors.New("pattern required")
+	}
+
+	p.Patterns = patterns
+	p.NamedPatterns = named
+	p.CustomPatterns += custom.String()
+	return p.compilePatterns()
+}
+
+// compilePatterns compiles the built-in and custom patterns including the
+// ones contained in the custom pattern files
+func (p *Parser) compilePatterns() error {
+	g, err := grok.NewWithConfig(&grok.Config{NamedCapturesOnly: true})
+	if err != nil {
+		return err
 	}
+	p.g = g
+	p.typeMap = make(map[string]map[string]string)
+	p.tsMap = make(map[string]map[string]string)
+	p.patternsMap = make(map[string]string)
+	p.patternFiles = make(map[string]patternFileState, len(p.CustomPatternFiles))
+
+	scanner := bufio.NewScanner(strings.NewReader(p.CustomPatterns))
+	p.addCustomPatterns(scanner)
 
 	// Parse any custom pattern files supplied.
 	for _, filename := range p.CustomPatternFiles {
-		file, fileErr := os.Open(filename)
-		if fileErr != nil {
-			return fileErr
+		if err := p.addCustomPatternFile(filename); err != nil {
+			return err
 		}
+	}
+
+	return p.compileCustomPatterns()
+}
 
-		scanner := bufio.NewScanner(bufio.NewReader(file))
-		p.addCustomPatterns(scanner)
+func (p *Parser) addCustomPatternFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
 
-	p.loc, err = time.LoadLocation(p.Timezone)
+	stat, err := file.Stat()
 	if err != nil {
-		p.Log.Warnf("Improper timezone supplied (%s), setting loc to UTC", p.Timezone)
-		p.loc = time.UTC
+		return err
 	}
+	p.patternFiles[filename] = patternFileState{modTime: stat.ModTime(), size: stat.Size()}
 
-	if p.timeFunc == nil {
-		p.timeFunc = time.Now
+	scanner := bufio.NewScanner(bufio.NewReader(file))
+	p.addCustomPatterns(scanner)
+	return scanner.Err()
+}
+
+// reloadPatterns recompiles the patterns if any of the custom pattern files
+// changed since the last check. The previous patterns are kept if compiling
+// the changed patterns fails.
+func (p *Parser) reloadPatterns() {
+	if p.ReloadInterval <= 0 || len(p.CustomPatternFiles) == 0 {
+		return
 	}
+	now := time.Now()
+	if now.Sub(p.lastCheck) < time.Duration(p.ReloadInterval) {
+		return
+	}
+	p.lastCheck = now
 
-	return p.compileCustomPatterns()
+	var changed bool
+	for _, filename := range p.CustomPatternFiles {
+		stat, err := os.Stat(filename)
+		if err != nil {
+			p.Log.Warnf("Checking custom pattern file %q failed: %v", filename, err)
+			return
+		}
+		state := p.patternFiles[filename]
+		if !stat.ModTime().Equal(state.modTime) || stat.Size() != state.size {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return
+	}
+
+	// Keep the state of the changed files on errors to not retry compiling
+	// the patterns until the files change again
+	g, typeMap, tsMap, patternsMap := p.g, p.typeMap, p.tsMap, p.patternsMap
+	if err := p.compilePatterns(); err != nil {
+		p.Log.Errorf("Reloading custom patterns failed, keeping previous patterns: %v", err)
+		p.g, p.typeMap, p.tsMap, p.patternsMap = g, typeMap, tsMap, patternsMap
+		return
+	}
+	p.Log.Infof("Reloaded custom patterns from %s", strings.Join(p.CustomPatternFiles, ", "))
 }
 
 func (p *Parser) SetTimeFunc(fn func() time.Time) {
@@ -174,6 +303,8 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	var values map[string]string
 	// the matching pattern string
 	var patternName string
+
+	p.reloadPatterns()
 	for _, pattern := range p.NamedPatterns {
 		if values, err = p.g.Parse(pattern, line); err != nil {
 			return nil, err
@@ -217,6 +348,10 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 				}
 			}
 		}
+		// if we didn't find a modifier, apply the configured type
+		if t == "" {
+			t = p.fieldTypeMap[k]
+		}
 		// if we didn't find a type OR timestamp modifier, assume string
 		if t == "" {
 			t = String
@@ -246,6 +381,20 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 			} else {
 				fields[k] = int64(d)
 			}
+		case DurationSeconds:
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				p.Log.Errorf("Error parsing %s to duration: %s", v, err)
+			} else {
+				fields[k] = d.Seconds()
+			}
+		case Bool:
+			bv, err := strconv.ParseBool(v)
+			if err != nil {
+				p.Log.Errorf("Error parsing %s to bool: %s", v, err)
+			} else {
+				fields[k] = bv
+			}
 		case Tag:
 			tags[k] = v
 		case String:
@@ -3,12 +3,14 @@ package grok
 import (
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -231,6 +233,113 @@ func TestBuiltinCombinedLogFormat(t *testing.T) {
 	require.Equal(t, map[string]string{"verb": "GET", "resp_code": "200"}, m.Tags())
 }
 
+func TestBuiltinNginxErrorLog(t *testing.T) {
+	p := &Parser{
+		Patterns: []string{"%{NGINX_ERROR_LOG}"},
+	}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine(`2024/03/01 10:15:32 [error] 1234#1234: *5678 open() "/usr/share/nginx/html/favicon.ico" failed (2: No such file or directory)`)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t,
+		map[string]interface{}{
+			"pid":           int64(1234),
+			"tid":           int64(1234),
+			"connection_id": int64(5678),
+			"message":       `open() "/usr/share/nginx/html/favicon.ico" failed (2: No such file or directory)`,
+		},
+		m.Fields())
+	require.Equal(t, map[string]string{"level": "error"}, m.Tags())
+	require.Equal(t, time.Date(2024, 3, 1, 10, 15, 32, 0, time.UTC), m.Time())
+}
+
+func TestBuiltinHAProxyHTTPLog(t *testing.T) {
+	p := &Parser{
+		Patterns: []string{"%{HAPROXY_HTTP_LOG}"},
+	}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine(`Feb  6 12:14:14 localhost haproxy[14389]: 10.0.1.2:33317 [06/Feb/2009:12:14:14.655] http-in static/srv1 10/0/30/69/109 200 2750 - - ---- 1/1/1/1/0 0/0 {1wt.eu} {} "GET /index.html HTTP/1.1"`)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t,
+		map[string]interface{}{
+			"client_ip":             "10.0.1.2",
+			"client_port":           int64(33317),
+			"time_request":          int64(10),
+			"time_queue":            int64(0),
+			"time_backend_connect":  int64(30),
+			"time_backend_response": int64(69),
+			"time_duration":         int64(109),
+			"bytes_read":            int64(2750),
+			"termination_state":     "----",
+			"actconn":               int64(1),
+			"feconn":                int64(1),
+			"beconn":                int64(1),
+			"srvconn":               int64(1),
+			"retries":               int64(0),
+			"srv_queue":             int64(0),
+			"backend_queue":         int64(0),
+			"request":               "GET /index.html HTTP/1.1",
+		},
+		m.Fields())
+	require.Equal(t,
+		map[string]string{
+			"frontend":         "http-in",
+			"backend":          "static",
+			"server":           "srv1",
+			"http_status_code": "200",
+		},
+		m.Tags())
+	require.Equal(t, time.Date(2009, 2, 6, 12, 14, 14, 655000000, time.UTC), m.Time())
+}
+
+func TestBuiltinPostfixDeliveryLog(t *testing.T) {
+	p := &Parser{
+		Patterns: []string{"%{POSTFIX_DELIVERY_LOG}"},
+	}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine(`Mar  1 10:15:32 mail postfix/smtp[12345]: 3F2A41C0123: to=<user@example.com>, relay=mx.example.com[192.0.2.1]:25, delay=0.52, delays=0.1/0.01/0.2/0.21, dsn=2.0.0, status=sent (250 2.0.0 OK)`)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t,
+		map[string]interface{}{
+			"hostname": "mail",
+			"pid":      int64(12345),
+			"queue_id": "3F2A41C0123",
+			"to":       "user@example.com",
+			"relay":    "mx.example.com[192.0.2.1]:25",
+			"delay":    float64(0.52),
+			"delays":   "0.1/0.01/0.2/0.21",
+			"dsn":      "2.0.0",
+			"message":  "(250 2.0.0 OK)",
+		},
+		m.Fields())
+	require.Equal(t, map[string]string{"component": "smtp", "status": "sent"}, m.Tags())
+}
+
+func TestBuiltinJournaldLog(t *testing.T) {
+	p := &Parser{
+		Patterns: []string{"%{JOURNALD_LOG}"},
+	}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine(`2016-06-04T12:41:45+01:00 myhost sshd[1234]: Accepted publickey for user`)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t,
+		map[string]interface{}{
+			"hostname": "myhost",
+			"pid":      int64(1234),
+			"message":  "Accepted publickey for user",
+		},
+		m.Fields())
+	require.Equal(t, map[string]string{"program": "sshd"}, m.Tags())
+	require.Equal(t, time.Date(2016, 6, 4, 11, 41, 45, 0, time.UTC), m.Time().UTC())
+}
+
 func TestCompileStringAndParse(t *testing.T) {
 	p := &Parser{
 		Patterns: []string{"%{TEST_LOG_A}"},
@@ -614,6 +723,44 @@ func TestCompileErrors(t *testing.T) {
 	require.Error(t, p.Init())
 }
 
+func TestReloadCustomPatternFiles(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "patterns")
+	require.NoError(t, os.WriteFile(filename, []byte("TEST_LOG %{WORD:name:tag} %{NUMBER:value:int}\n"), 0640))
+
+	p := &Parser{
+		Measurement:        "test",
+		Patterns:           []string{"%{TEST_LOG}"},
+		CustomPatternFiles: []string{filename},
+		ReloadInterval:     config.Duration(time.Hour),
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine("foo 42")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": int64(42)}, m.Fields())
+
+	// Changes are not picked up before the reload interval elapsed
+	require.NoError(t, os.WriteFile(filename, []byte("TEST_LOG %{WORD:name:tag} %{NUMBER:value:float}\n"), 0640))
+	m, err = p.ParseLine("foo 42")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": int64(42)}, m.Fields())
+
+	p.lastCheck = time.Time{}
+	m, err = p.ParseLine("foo 42")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": float64(42)}, m.Fields())
+	require.Equal(t, map[string]string{"name": "foo"}, m.Tags())
+
+	// Invalid patterns keep the previous ones
+	invalid := "TEST_LOG %{HTTPDATE:ts1:ts-httpd} %{HTTPDATE:ts2:ts-httpd} %{NUMBER:value:int}\n"
+	require.NoError(t, os.WriteFile(filename, []byte(invalid), 0640))
+	p.lastCheck = time.Time{}
+	m, err = p.ParseLine("foo 42")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": float64(42)}, m.Fields())
+}
+
 func TestParseErrors_MissingPattern(t *testing.T) {
 	p := &Parser{
 		Measurement: "grok",
@@ -1239,3 +1386,70 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse([]byte(benchmarkData))
 	}
 }
+
+func TestParseTypes(t *testing.T) {
+	p := &Parser{
+		Patterns: []string{
+			`\[%{HTTPDATE:ts}\] status=%{NUMBER:status} cached=%{WORD:cached} took=%{NOTSPACE:took} size=%{NUMBER:size} ratio=%{NUMBER:ratio:int}`,
+		},
+		Types: map[string]string{
+			"ts":     "timestamp",
+			"status": "int",
+			"cached": "bool",
+			"took":   "duration",
+			"size":   "float",
+			"ratio":  "float",
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine(`[09/Jun/2016:03:37:03 +0000] status=200 cached=true took=250ms size=1.5 ratio=3`)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t,
+		map[string]interface{}{
+			"status": int64(200),
+			"cached": true,
+			"took":   0.25,
+			"size":   1.5,
+			// Modifiers in the pattern take precedence
+			"ratio": int64(3),
+		},
+		m.Fields())
+	require.Equal(t, time.Unix(1465443423, 0).UTC(), m.Time().UTC())
+}
+
+func TestParseTypesInvalid(t *testing.T) {
+	p := &Parser{
+		Patterns: []string{`%{NUMBER:status}`},
+		Types:    map[string]string{"status": "integer"},
+		Log:      testutil.Logger{},
+	}
+	require.EqualError(t, p.Init(), `invalid type "integer" for field "status"`)
+}
+
+func TestSetPatterns(t *testing.T) {
+	p := &Parser{
+		Measurement:    "log",
+		Patterns:       []string{`%{NUMBER:value:int}`},
+		CustomPatterns: `LEVEL (INFO|WARN)`,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	// The custom patterns are still available
+	require.NoError(t, p.SetPatterns([]string{`%{LEVEL:level:tag} %{GREEDYDATA:message}`}))
+	m, err := p.ParseLine(`WARN disk full`)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, map[string]string{"level": "WARN"}, m.Tags())
+	require.Equal(t, map[string]interface{}{"message": "disk full"}, m.Fields())
+
+	// The previous patterns are replaced
+	m, err = p.ParseLine(`42`)
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	require.EqualError(t, p.SetPatterns([]string{" "}), "pattern required")
+}
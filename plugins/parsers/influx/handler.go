@@ -4,17 +4,24 @@ import (
 	"bytes"
 	"errors"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 )
 
+var errInvalidUTF8 = errors.New("invalid UTF-8 sequence")
+
 // MetricHandler implements the Handler interface and produces telegraf.Metric.
 type MetricHandler struct {
 	metric        telegraf.Metric
 	timeFunc      func() time.Time
 	timePrecision time.Duration
+	nullValues    map[string]bool
+	dropNulls     bool
+	utf8Mode      string
 }
 
 func NewMetricHandler() *MetricHandler {
@@ -34,6 +41,37 @@ func (h *MetricHandler) SetTimePrecision(p time.Duration) {
 	// overloaded to hold the unit of measurement of the timestamp.
 }
 
+// SetNullValues sets the string field values treated as null. Those fields
+// are removed from the metric if drop is set and kept as string otherwise.
+func (h *MetricHandler) SetNullValues(values []string, drop bool) {
+	h.nullValues = make(map[string]bool, len(values))
+	for _, v := range values {
+		h.nullValues[v] = true
+	}
+	h.dropNulls = drop
+}
+
+// SetUTF8Mode sets the handling of strings containing invalid UTF-8
+// sequences. "reject" fails the line, "replace" replaces the invalid
+// sequences by the Unicode replacement character and any other value passes
+// the strings unchanged.
+func (h *MetricHandler) SetUTF8Mode(mode string) {
+	h.utf8Mode = mode
+}
+
+// checkUTF8 applies the UTF-8 mode to the given string
+func (h *MetricHandler) checkUTF8(s string) (string, error) {
+	switch h.utf8Mode {
+	case "reject":
+		if !utf8.ValidString(s) {
+			return "", errInvalidUTF8
+		}
+	case "replace":
+		return strings.ToValidUTF8(s, string(utf8.RuneError)), nil
+	}
+	return s, nil
+}
+
 func (h *MetricHandler) SetTimeFunc(f func() time.Time) {
 	h.timeFunc = f
 }
@@ -42,6 +80,10 @@ func (h *MetricHandler) Metric() telegraf.Metric {
 	if h.metric == nil {
 		return nil
 	}
+	// All fields might have been dropped as null values
+	if len(h.metric.FieldList()) == 0 {
+		return nil
+	}
 	if h.metric.Time().IsZero() {
 		h.metric.SetTime(h.timeFunc().Truncate(h.timePrecision))
 	}
@@ -49,20 +91,32 @@ func (h *MetricHandler) Metric() telegraf.Metric {
 }
 
 func (h *MetricHandler) SetMeasurement(name []byte) error {
-	h.metric = metric.New(nameUnescape(name),
-		nil, nil, time.Time{})
+	mn, err := h.checkUTF8(nameUnescape(name))
+	if err != nil {
+		return err
+	}
+	h.metric = metric.New(mn, nil, nil, time.Time{})
 	return nil
 }
 
 func (h *MetricHandler) AddTag(key, value []byte) error {
-	tk := unescape(key)
-	tv := unescape(value)
+	tk, err := h.checkUTF8(unescape(key))
+	if err != nil {
+		return err
+	}
+	tv, err := h.checkUTF8(unescape(value))
+	if err != nil {
+		return err
+	}
 	h.metric.AddTag(tk, tv)
 	return nil
 }
 
 func (h *MetricHandler) AddInt(key, value []byte) error {
-	fk := unescape(key)
+	fk, err := h.checkUTF8(unescape(key))
+	if err != nil {
+		return err
+	}
 	fv, err := parseIntBytes(bytes.TrimSuffix(value, []byte("i")), 10, 64)
 	if err != nil {
 		var numErr *strconv.NumError
@@ -76,7 +130,10 @@ func (h *MetricHandler) AddInt(key, value []byte) error {
 }
 
 func (h *MetricHandler) AddUint(key, value []byte) error {
-	fk := unescape(key)
+	fk, err := h.checkUTF8(unescape(key))
+	if err != nil {
+		return err
+	}
 	fv, err := parseUintBytes(bytes.TrimSuffix(value, []byte("u")), 10, 64)
 	if err != nil {
 		var numErr *strconv.NumError
@@ -90,7 +147,10 @@ func (h *MetricHandler) AddUint(key, value []byte) error {
 }
 
 func (h *MetricHandler) AddFloat(key, value []byte) error {
-	fk := unescape(key)
+	fk, err := h.checkUTF8(unescape(key))
+	if err != nil {
+		return err
+	}
 	fv, err := parseFloatBytes(value, 64)
 	if err != nil {
 		var numErr *strconv.NumError
@@ -104,14 +164,26 @@ func (h *MetricHandler) AddFloat(key, value []byte) error {
 }
 
 func (h *MetricHandler) AddString(key, value []byte) error {
-	fk := unescape(key)
-	fv := stringFieldUnescape(value)
+	fk, err := h.checkUTF8(unescape(key))
+	if err != nil {
+		return err
+	}
+	fv, err := h.checkUTF8(stringFieldUnescape(value))
+	if err != nil {
+		return err
+	}
+	if h.dropNulls && h.nullValues[fv] {
+		return nil
+	}
 	h.metric.AddField(fk, fv)
 	return nil
 }
 
 func (h *MetricHandler) AddBool(key, value []byte) error {
-	fk := unescape(key)
+	fk, err := h.checkUTF8(unescape(key))
+	if err != nil {
+		return err
+	}
 	fv, err := parseBoolBytes(value)
 	if err != nil {
 		return errors.New("unparsable bool")
@@ -0,0 +1,52 @@
+package influx_upstream
+
+import (
+	"container/list"
+	"sync"
+)
+
+// internPool is a bounded pool of strings evicting the least recently used
+// entry if full. Looking up an existing entry returns the pooled string so
+// repeated values share the same backing memory instead of allocating a new
+// string for each occurrence.
+type internPool struct {
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List
+	sync.Mutex
+}
+
+func newInternPool(size int) *internPool {
+	return &internPool{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		lru:     list.New(),
+	}
+}
+
+// get returns the pooled string for the given bytes, adding it to the pool if
+// not yet present. The function is safe to call on a nil pool in which case a
+// new string is returned.
+func (p *internPool) get(b []byte) string {
+	if p == nil {
+		return string(b)
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	// The compiler optimizes the conversion in the map lookup to not allocate
+	if e, found := p.entries[string(b)]; found {
+		p.lru.MoveToFront(e)
+		return e.Value.(string)
+	}
+
+	s := string(b)
+	if p.lru.Len() >= p.size {
+		oldest := p.lru.Back()
+		p.lru.Remove(oldest)
+		delete(p.entries, oldest.Value.(string))
+	}
+	p.entries[s] = p.lru.PushFront(s)
+	return s
+}
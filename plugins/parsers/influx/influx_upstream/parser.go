@@ -104,6 +104,7 @@ func convertToParseError(input []byte, rawErr error) error {
 // parsers.Parser interface.
 type Parser struct {
 	InfluxTimestampPrecision config.Duration   `toml:"influx_timestamp_precision"`
+	InternPoolSize           int               `toml:"influx_intern_pool_size"`
 	DefaultTags              map[string]string `toml:"-"`
 	// If set to "series" a series machine will be initialized, defaults to regular machine
 	Type string `toml:"-"`
@@ -111,6 +112,7 @@ type Parser struct {
 	defaultTime  TimeFunc
 	precision    lineprotocol.Precision
 	allowPartial bool
+	pool         *internPool
 }
 
 func (p *Parser) SetTimeFunc(f TimeFunc) {
@@ -122,7 +124,7 @@ func (p *Parser) Parse(input []byte) ([]telegraf.Metric, error) {
 	decoder := lineprotocol.NewDecoderWithBytes(input)
 
 	for decoder.Next() {
-		m, err := nextMetric(decoder, p.precision, p.defaultTime, p.allowPartial)
+		m, err := nextMetric(decoder, p.precision, p.defaultTime, p.allowPartial, p.pool)
 		if err != nil {
 			return nil, convertToParseError(input, err)
 		}
@@ -195,6 +197,13 @@ func (p *Parser) Init() error {
 	p.defaultTime = time.Now
 	p.allowPartial = p.Type == "series"
 
+	if p.InternPoolSize < 0 {
+		return errors.New("influx_intern_pool_size must not be negative")
+	}
+	if p.InternPoolSize > 0 {
+		p.pool = newInternPool(p.InternPoolSize)
+	}
+
 	return nil
 }
 
@@ -212,6 +221,7 @@ type StreamParser struct {
 	decoder     *lineprotocol.Decoder
 	defaultTime TimeFunc
 	precision   lineprotocol.Precision
+	defaultTags map[string]string
 	lastError   error
 }
 
@@ -230,9 +240,19 @@ func (sp *StreamParser) SetTimeFunc(f TimeFunc) {
 	sp.defaultTime = f
 }
 
+// SetDefaultTags sets the tags added to every parsed metric unless the metric
+// already contains a tag with the same key.
+func (sp *StreamParser) SetDefaultTags(tags map[string]string) {
+	sp.defaultTags = tags
+}
+
+// SetTimePrecision sets the unit of the timestamps in the stream, e.g. as
+// given by the precision query parameter of a write request. Like for the
+// batch parser only nanoseconds, microseconds, milliseconds and seconds are
+// supported.
 func (sp *StreamParser) SetTimePrecision(u time.Duration) error {
 	switch u {
-	case time.Nanosecond:
+	case 0, time.Nanosecond:
 		sp.precision = lineprotocol.Nanosecond
 	case time.Microsecond:
 		sp.precision = lineprotocol.Microsecond
@@ -244,6 +264,8 @@ func (sp *StreamParser) SetTimePrecision(u time.Duration) error {
 		return errors.New("time precision 'm' is not supported")
 	case time.Hour:
 		return errors.New("time precision 'h' is not supported")
+	default:
+		return fmt.Errorf("invalid time precision: %d", u)
 	}
 
 	return nil
@@ -261,20 +283,32 @@ func (sp *StreamParser) Next() (telegraf.Metric, error) {
 		return nil, io.EOF
 	}
 
-	m, err := nextMetric(sp.decoder, sp.precision, sp.defaultTime, false)
+	m, err := nextMetric(sp.decoder, sp.precision, sp.defaultTime, false, nil)
 	if err != nil {
 		return nil, convertToParseError(nil, err)
 	}
 
+	for k, v := range sp.defaultTags {
+		if !m.HasTag(k) {
+			m.AddTag(k, v)
+		}
+	}
+
 	return m, nil
 }
 
-func nextMetric(decoder *lineprotocol.Decoder, precision lineprotocol.Precision, defaultTime TimeFunc, allowPartial bool) (telegraf.Metric, error) {
+func nextMetric(
+	decoder *lineprotocol.Decoder,
+	precision lineprotocol.Precision,
+	defaultTime TimeFunc,
+	allowPartial bool,
+	pool *internPool,
+) (telegraf.Metric, error) {
 	measurement, err := decoder.Measurement()
 	if err != nil {
 		return nil, err
 	}
-	m := metric.New(string(measurement), nil, nil, time.Time{})
+	m := metric.New(pool.get(measurement), nil, nil, time.Time{})
 
 	for {
 		key, value, err := decoder.NextTag()
@@ -289,7 +323,7 @@ func nextMetric(decoder *lineprotocol.Decoder, precision lineprotocol.Precision,
 			break
 		}
 
-		m.AddTag(string(key), string(value))
+		m.AddTag(pool.get(key), pool.get(value))
 	}
 
 	for {
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/influxdata/line-protocol/v2/lineprotocol"
 	"github.com/stretchr/testify/require"
@@ -674,6 +675,44 @@ func TestStreamParser(t *testing.T) {
 	}
 }
 
+func TestParserDefaultTags(t *testing.T) {
+	input := []byte("cpu,host=a value=1 0\ncpu,host=b,source=file value=2 0\n")
+	defaultTags := map[string]string{"source": "default", "region": "eu"}
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a", "source": "default", "region": "eu"},
+			map[string]interface{}{"value": 1.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "b", "source": "file", "region": "eu"},
+			map[string]interface{}{"value": 2.0},
+			time.Unix(0, 0),
+		),
+	}
+
+	parser := &Parser{DefaultTags: defaultTags}
+	require.NoError(t, parser.Init())
+	metrics, err := parser.Parse(input)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, metrics)
+
+	streamParser := NewStreamParser(bytes.NewBuffer(input))
+	streamParser.SetDefaultTags(defaultTags)
+	actual := make([]telegraf.Metric, 0, len(expected))
+	for {
+		m, err := streamParser.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		actual = append(actual, m)
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
 func TestSeriesParser(t *testing.T) {
 	var tests = []struct {
 		name     string
@@ -911,6 +950,67 @@ func TestParserErrorString(t *testing.T) {
 	}
 }
 
+func TestStreamParserTimestampPrecision(t *testing.T) {
+	var tests = []struct {
+		name      string
+		precision time.Duration
+		input     string
+		expected  time.Time
+	}{
+		{
+			name:      "nanosecond",
+			precision: time.Nanosecond,
+			input:     "cpu value=1 1234567890123123999",
+			expected:  time.Unix(0, 1234567890123123999),
+		},
+		{
+			name:      "microsecond",
+			precision: time.Microsecond,
+			input:     "cpu value=1 1234567890123123",
+			expected:  time.Unix(0, 1234567890123123000),
+		},
+		{
+			name:      "millisecond",
+			precision: time.Millisecond,
+			input:     "cpu value=1 1234567890123",
+			expected:  time.Unix(0, 1234567890123000000),
+		},
+		{
+			name:      "second",
+			precision: time.Second,
+			input:     "cpu value=1 1234567890",
+			expected:  time.Unix(1234567890, 0),
+		},
+		{
+			name:      "no timestamp",
+			precision: time.Second,
+			input:     "cpu value=1",
+			expected:  time.Unix(42, 123456789),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewStreamParser(bytes.NewBufferString(tt.input))
+			parser.SetTimeFunc(func() time.Time { return time.Unix(42, 123456789) })
+			require.NoError(t, parser.SetTimePrecision(tt.precision))
+
+			m, err := parser.Next()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, m.Time())
+
+			_, err = parser.Next()
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestStreamParserInvalidTimestampPrecision(t *testing.T) {
+	for _, precision := range []time.Duration{time.Hour, time.Minute, 2 * time.Second, 2 * time.Nanosecond} {
+		parser := NewStreamParser(bytes.NewBufferString("cpu value=1"))
+		require.Error(t, parser.SetTimePrecision(precision), precision)
+	}
+}
+
 func TestStreamParserErrorString(t *testing.T) {
 	var ptests = []struct {
 		name  string
@@ -1071,3 +1171,59 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse([]byte(benchmarkData))
 	}
 }
+
+func TestParserInternPool(t *testing.T) {
+	plugin := &Parser{InternPoolSize: 16}
+	require.NoError(t, plugin.Init())
+
+	first, err := plugin.Parse([]byte(benchmarkData))
+	require.NoError(t, err)
+	second, err := plugin.Parse([]byte(benchmarkData))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, first, second)
+
+	// Repeated measurements, tag keys and values must share the same backing
+	// memory
+	for i, m := range first {
+		require.Same(t, unsafe.StringData(m.Name()), unsafe.StringData(second[i].Name()))
+		for _, tag := range m.TagList() {
+			other, found := second[i].GetTag(tag.Key)
+			require.True(t, found)
+			require.Same(t, unsafe.StringData(tag.Value), unsafe.StringData(other))
+		}
+	}
+}
+
+func TestParserInternPoolInvalid(t *testing.T) {
+	plugin := &Parser{InternPoolSize: -1}
+	require.ErrorContains(t, plugin.Init(), "must not be negative")
+}
+
+func TestInternPoolEviction(t *testing.T) {
+	pool := newInternPool(2)
+
+	a := pool.get([]byte("a"))
+	b := pool.get([]byte("b"))
+	require.Same(t, unsafe.StringData(a), unsafe.StringData(pool.get([]byte("a"))))
+
+	// Adding a third entry evicts the least recently used one which is "b"
+	pool.get([]byte("c"))
+	require.Len(t, pool.entries, 2)
+	require.Contains(t, pool.entries, "a")
+	require.Contains(t, pool.entries, "c")
+	require.NotSame(t, unsafe.StringData(b), unsafe.StringData(pool.get([]byte("b"))))
+
+	// A nil pool returns new strings
+	var disabled *internPool
+	require.Equal(t, "foo", disabled.get([]byte("foo")))
+}
+
+func BenchmarkParsingInternPool(b *testing.B) {
+	plugin := &Parser{InternPoolSize: 1000}
+	require.NoError(b, plugin.Init())
+
+	for n := 0; n < b.N; n++ {
+		//nolint:errcheck // Benchmarking so skip the error check to avoid the unnecessary operations
+		plugin.Parse([]byte(benchmarkData))
+	}
+}
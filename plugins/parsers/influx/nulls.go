@@ -0,0 +1,134 @@
+package influx
+
+import "slices"
+
+// Sections of a line while scanning for null values
+const (
+	sectionStart = iota
+	sectionSeries
+	sectionFieldKey
+	sectionFieldValue
+	sectionTimestamp
+	sectionComment
+)
+
+// quoteNullValues rewrites unquoted field values matching one of the given
+// null values into string fields, e.g. 'value=NaN' becomes 'value="NaN"'. If
+// the empty string is a null value, fields without a value like 'value=' are
+// rewritten to 'value=""'. This allows to parse those lines and to handle the
+// null values as strings. The input is returned unchanged if nothing needs to
+// be rewritten. The offsets of the inserted quotes in the rewritten input are
+// returned as well to allow mapping positions back to the original input.
+func quoteNullValues(input []byte, nulls map[string]bool) ([]byte, []int) {
+	var out []byte
+	var inserted []int
+	last := 0 // end of the input already copied to the output
+
+	section := sectionStart
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+
+		switch section {
+		case sectionStart:
+			switch c {
+			case ' ', '\t', '\r', '\n':
+				continue
+			case '#':
+				section = sectionComment
+			default:
+				section = sectionSeries
+			}
+			if c == '\\' {
+				i++
+			}
+		case sectionSeries:
+			switch c {
+			case '\\':
+				i++
+			case ' ':
+				section = sectionFieldKey
+			case '\n':
+				section = sectionStart
+			}
+		case sectionFieldKey:
+			switch c {
+			case '\\':
+				i++
+			case '=':
+				section = sectionFieldValue
+			case ' ':
+				section = sectionTimestamp
+			case '\n':
+				section = sectionStart
+			}
+		case sectionFieldValue:
+			// Skip quoted strings which might contain any character
+			if c == '"' {
+				for i++; i < len(input) && input[i] != '"'; i++ {
+					if input[i] == '\\' {
+						i++
+					}
+				}
+				section = sectionFieldKey
+				continue
+			}
+
+			// Find the end of the unquoted value
+			end := i
+			for end < len(input) && !isValueTerminator(input[end]) {
+				end++
+			}
+			if nulls[string(input[i:end])] {
+				out = append(out, input[last:i]...)
+				inserted = append(inserted, len(out))
+				out = append(out, '"')
+				out = append(out, input[i:end]...)
+				inserted = append(inserted, len(out))
+				out = append(out, '"')
+				last = end
+			}
+
+			// Continue with the character terminating the value
+			i = end - 1
+			section = sectionFieldKey
+			if end < len(input) {
+				switch input[end] {
+				case ',':
+					i = end
+				case ' ':
+					i = end
+					section = sectionTimestamp
+				}
+			}
+		case sectionTimestamp, sectionComment:
+			if c == '\n' {
+				section = sectionStart
+			}
+		}
+	}
+
+	// The loop ends without handling the value if the input ends directly
+	// after the equal sign of the last field
+	if section == sectionFieldValue && nulls[""] {
+		out = append(out, input[last:]...)
+		inserted = append(inserted, len(out), len(out)+1)
+		out = append(out, '"', '"')
+		return out, inserted
+	}
+
+	if out == nil {
+		return input, nil
+	}
+	return append(out, input[last:]...), inserted
+}
+
+// originalOffset maps the given offset into the input rewritten by
+// quoteNullValues back to the offset into the original input
+func originalOffset(offset int, inserted []int) int {
+	n, _ := slices.BinarySearch(inserted, offset)
+	return offset - n
+}
+
+func isValueTerminator(c byte) bool {
+	return c == ',' || c == ' ' || c == '\r' || c == '\n'
+}
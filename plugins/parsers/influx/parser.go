@@ -1,6 +1,7 @@
 package influx
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -15,6 +16,9 @@ import (
 
 const (
 	maxErrorBufferSize = 1024
+
+	// Size of the chunks read when parsing from an io.ReaderAt
+	readerAtChunkSize = 1 << 20
 )
 
 var (
@@ -28,11 +32,36 @@ type ParseError struct {
 	LineNumber int
 	Column     int
 	msg        string
-	buf        string
+
+	// Text starting at the given offset of the input containing the line of
+	// the error
+	buf       string
+	bufOffset int
+}
+
+// newParseError creates an error for the given position of the input only
+// keeping the affected line
+func newParseError(input []byte, offset, lineOffset, lineNumber, column int, msg string) *ParseError {
+	end := len(input)
+	if lineOffset < end {
+		if eol := bytes.IndexByte(input[lineOffset:], '\n'); eol >= 0 {
+			end = lineOffset + eol
+		}
+	}
+	start := min(lineOffset, end)
+	return &ParseError{
+		Offset:     offset,
+		LineOffset: lineOffset,
+		LineNumber: lineNumber,
+		Column:     column,
+		msg:        msg,
+		buf:        string(input[start:end]),
+		bufOffset:  start,
+	}
 }
 
 func (e *ParseError) Error() string {
-	buffer := e.buf[e.LineOffset:]
+	buffer := e.buf[e.LineOffset-e.bufOffset:]
 	eol := strings.IndexAny(buffer, "\n")
 	if eol >= 0 {
 		buffer = strings.TrimSuffix(buffer[:eol], "\r")
@@ -60,11 +89,20 @@ func (e *ParseError) Error() string {
 // parsers.Parser interface.
 type Parser struct {
 	InfluxTimestampPrecision config.Duration   `toml:"influx_timestamp_precision"`
+	Permissive               bool              `toml:"influx_permissive"`
+	NullValues               []string          `toml:"influx_null_values"`
+	NullAction               string            `toml:"influx_null_action"`
+	ValidateUTF8             string            `toml:"influx_validate_utf8"`
+	Directives               bool              `toml:"influx_directives"`
 	DefaultTags              map[string]string `toml:"-"`
 	// If set to "series" a series machine will be initialized, defaults to regular machine
 	Type string `toml:"-"`
 
-	handler *MetricHandler
+	handler      *MetricHandler
+	nulls        map[string]bool
+	precision    time.Duration
+	chunkSize    int
+	sizeCallback func([]telegraf.Metric, int)
 	*machine
 	sync.Mutex
 }
@@ -77,6 +115,31 @@ func (p *Parser) Init() error {
 		p.machine = NewMachine(p.handler)
 	}
 
+	switch p.NullAction {
+	case "":
+		p.NullAction = "drop"
+	case "drop", "string":
+	default:
+		return fmt.Errorf("invalid null action %q", p.NullAction)
+	}
+	switch p.ValidateUTF8 {
+	case "":
+		p.ValidateUTF8 = "pass"
+	case "pass", "reject", "replace":
+	default:
+		return fmt.Errorf("invalid UTF-8 validation mode %q", p.ValidateUTF8)
+	}
+	p.handler.SetUTF8Mode(p.ValidateUTF8)
+
+	if len(p.NullValues) > 0 {
+		p.nulls = make(map[string]bool, len(p.NullValues))
+		for _, v := range p.NullValues {
+			p.nulls[v] = true
+		}
+		p.handler.SetNullValues(p.NullValues, p.NullAction == "drop")
+	}
+
+	p.precision = time.Nanosecond
 	timeDuration := time.Duration(p.InfluxTimestampPrecision)
 	switch timeDuration {
 	case 0:
@@ -94,6 +157,7 @@ func (p *Parser) SetTimeFunc(f func() time.Time) {
 }
 
 func (p *Parser) SetTimePrecision(u time.Duration) {
+	p.precision = u
 	p.handler.SetTimePrecision(u)
 }
 
@@ -101,12 +165,202 @@ func (p *Parser) SetDefaultTags(tags map[string]string) {
 	p.DefaultTags = tags
 }
 
+// SetSizeCallback sets a function called for each parsed metric with the
+// number of bytes consumed since the end of the previous metric, including
+// skipped comments and invalid lines.
+func (p *Parser) SetSizeCallback(fn func([]telegraf.Metric, int)) {
+	p.sizeCallback = fn
+}
+
 func (p *Parser) Parse(input []byte) ([]telegraf.Metric, error) {
 	p.Lock()
 	defer p.Unlock()
+
+	p.resetDirectives()
+	metrics, sizes, err := p.parse(input, 0)
+	p.reportSizes(metrics, sizes)
+	return metrics, err
+}
+
+// ParseReaderAt parses the data of the given reader in chunks ending at line
+// boundaries. In contrast to Parse, this avoids reading the whole data into
+// memory at once, e.g. for large memory-mapped files.
+func (p *Parser) ParseReaderAt(r io.ReaderAt, size int64) ([]telegraf.Metric, error) {
+	chunkSize := int64(p.chunkSize)
+	if chunkSize <= 0 {
+		chunkSize = readerAtChunkSize
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	// Directives apply to the remainder of the data across chunks
+	p.resetDirectives()
+
 	metrics := make([]telegraf.Metric, 0)
-	p.machine.SetData(input)
+	var errs []error
+	var offset int64
+	var lines int
+	var carry int // bytes of the previous chunks not attributed to a metric
+	for offset < size {
+		// Read a chunk ending at a line boundary and grow the chunk if a single
+		// line exceeds its size. Chunks are not reused as parsed strings might
+		// reference the underlying data.
+		n := min(chunkSize, size-offset)
+		var buf []byte
+		var m []telegraf.Metric
+		var sizes []int
+		var err error
+		for {
+			buf = make([]byte, n)
+			nr, rerr := r.ReadAt(buf, offset)
+			if rerr != nil && !errors.Is(rerr, io.EOF) {
+				return nil, fmt.Errorf("reading at offset %d failed: %w", offset, rerr)
+			}
+			if nr < len(buf) {
+				// The data ended prematurely
+				buf = buf[:nr]
+				size = offset + int64(nr)
+			}
+			final := offset+int64(len(buf)) >= size
+			if !final {
+				idx := bytes.LastIndexByte(buf, '\n')
+				if idx < 0 {
+					n = min(2*n, size-offset)
+					continue
+				}
+				buf = buf[:idx+1]
+			}
+
+			// A newline within a string field might have split the last line
+			// of the chunk, so retry with a larger chunk if parsing failed at
+			// the end of the data.
+			m, sizes, err = p.parse(buf, lines)
+			if final || !errorAtEnd(err, len(buf)) {
+				break
+			}
+			n = min(2*n, size-offset)
+		}
+
+		if err != nil && !p.Permissive {
+			return nil, err
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if p.sizeCallback != nil {
+			attributed := 0
+			for _, n := range sizes {
+				attributed += n
+			}
+			if len(sizes) > 0 {
+				sizes[0] += carry
+				carry = 0
+			}
+			carry += len(buf) - attributed
+		}
+		p.reportSizes(m, sizes)
+		metrics = append(metrics, m...)
+
+		offset += int64(len(buf))
+		lines += bytes.Count(buf, []byte{'\n'})
+	}
+
+	return metrics, errors.Join(errs...)
+}
 
+// errorAtEnd returns true if any of the given parsing errors occurred at the
+// end of data with the given length
+func errorAtEnd(err error, length int) bool {
+	if err == nil {
+		return false
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			if errorAtEnd(e, length) {
+				return true
+			}
+		}
+		return false
+	}
+	var perr *ParseError
+	return errors.As(err, &perr) && perr.Offset >= length-1
+}
+
+// parse parses the given input adding the given number of preceding lines
+// to the line number of parsing errors. If a size callback is set, the number
+// of bytes consumed for each metric is returned as well.
+func (p *Parser) parse(input []byte, lines int) ([]telegraf.Metric, []int, error) {
+	if !p.Directives {
+		return p.parseSegment(input, lines)
+	}
+
+	// Split the input at directive lines as those change the settings for
+	// the following lines
+	metrics := make([]telegraf.Metric, 0)
+	var sizes []int
+	var errs []error
+	var start, startLine, carry int
+	for offset, n := 0, 0; offset < len(input); n++ {
+		next := len(input)
+		if idx := bytes.IndexByte(input[offset:], '\n'); idx >= 0 {
+			next = offset + idx + 1
+		}
+		key, value, ok := parseDirective(input[offset:next])
+		if !ok {
+			offset = next
+			continue
+		}
+
+		m, s, err := p.parseSegment(input[start:offset], lines+startLine)
+		if err != nil && !p.Permissive {
+			return nil, nil, err
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		metrics = append(metrics, m...)
+		sizes, carry = appendSizes(sizes, s, carry, next-start)
+
+		if err := p.applyDirective(key, value); err != nil {
+			perr := newParseError(input, offset, offset, lines+n+1, 1, err.Error())
+			if !p.Permissive {
+				return nil, nil, perr
+			}
+			errs = append(errs, perr)
+		}
+		start, startLine, offset = next, n+1, next
+	}
+
+	m, s, err := p.parseSegment(input[start:], lines+startLine)
+	if err != nil && !p.Permissive {
+		return nil, nil, err
+	}
+	if err != nil {
+		errs = append(errs, err)
+	}
+	metrics = append(metrics, m...)
+	sizes, _ = appendSizes(sizes, s, carry, len(input)-start)
+
+	return metrics, sizes, errors.Join(errs...)
+}
+
+// parseSegment parses the given input without handling directives
+func (p *Parser) parseSegment(input []byte, lines int) ([]telegraf.Metric, []int, error) {
+	metrics := make([]telegraf.Metric, 0)
+
+	// Positions reported by the machine refer to the rewritten data and are
+	// mapped back to the original input for errors and sizes
+	data := input
+	var inserted []int
+	if len(p.nulls) > 0 {
+		data, inserted = quoteNullValues(input, p.nulls)
+	}
+	p.machine.SetData(data)
+
+	var sizes []int
+	var last int
+	var errs []error
 	for {
 		err := p.machine.Next()
 		if errors.Is(err, EOF) {
@@ -114,14 +368,18 @@ func (p *Parser) Parse(input []byte) ([]telegraf.Metric, error) {
 		}
 
 		if err != nil {
-			return nil, &ParseError{
-				Offset:     p.machine.Position(),
-				LineOffset: p.machine.LineOffset(),
-				LineNumber: p.machine.LineNumber(),
-				Column:     p.machine.Column(),
-				msg:        err.Error(),
-				buf:        string(input),
+			offset := originalOffset(p.machine.Position(), inserted)
+			lineOffset := originalOffset(p.machine.LineOffset(), inserted)
+			perr := newParseError(input, offset, lineOffset,
+				lines+p.machine.LineNumber(), offset-lineOffset+1, err.Error())
+			if !p.Permissive {
+				return nil, nil, perr
 			}
+
+			// The machine skips the remainder of the invalid line so we can
+			// continue with the next one
+			errs = append(errs, perr)
+			continue
 		}
 
 		metric := p.handler.Metric()
@@ -130,10 +388,84 @@ func (p *Parser) Parse(input []byte) ([]telegraf.Metric, error) {
 		}
 
 		metrics = append(metrics, metric)
+		if p.sizeCallback != nil {
+			position := originalOffset(p.machine.Position(), inserted)
+			sizes = append(sizes, position-last)
+			last = position
+		}
 	}
 
 	p.applyDefaultTags(metrics)
-	return metrics, nil
+	return metrics, sizes, errors.Join(errs...)
+}
+
+// resetDirectives restores the settings changed by directives of previously
+// parsed data
+func (p *Parser) resetDirectives() {
+	if p.Directives {
+		p.handler.SetTimePrecision(p.precision)
+	}
+}
+
+// applyDirective changes the settings for the following lines
+func (p *Parser) applyDirective(key, value string) error {
+	switch key {
+	case "precision":
+		precision, found := directivePrecisions[value]
+		if !found {
+			return fmt.Errorf("invalid precision directive %q", value)
+		}
+		p.handler.SetTimePrecision(precision)
+	}
+	return nil
+}
+
+var directivePrecisions = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"n":  time.Nanosecond,
+	"us": time.Microsecond,
+	"u":  time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// parseDirective returns the key and value of a comment line of the form
+// "# key=value", other comments are not considered to be directives.
+func parseDirective(line []byte) (key, value string, ok bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '#' {
+		return "", "", false
+	}
+	k, v, found := bytes.Cut(line[1:], []byte{'='})
+	k = bytes.TrimSpace(k)
+	if !found || len(k) == 0 || bytes.ContainsAny(k, " \t") {
+		return "", "", false
+	}
+	return strings.ToLower(string(k)), string(bytes.TrimSpace(v)), true
+}
+
+// appendSizes appends the metric sizes of a parsed segment attributing the
+// bytes not consumed by previous metrics to the first metric and returns the
+// bytes of the segment not attributed to any metric
+func appendSizes(sizes, segment []int, carry, length int) ([]int, int) {
+	for _, n := range segment {
+		length -= n
+	}
+	if len(segment) == 0 {
+		return sizes, carry + length
+	}
+	segment[0] += carry
+	return append(sizes, segment...), length
+}
+
+func (p *Parser) reportSizes(metrics []telegraf.Metric, sizes []int) {
+	if p.sizeCallback == nil {
+		return
+	}
+	for i, m := range metrics {
+		p.sizeCallback([]telegraf.Metric{m}, sizes[i])
+	}
 }
 
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
@@ -178,8 +510,12 @@ func init() {
 // StreamParser is an InfluxDB Line Protocol parser.  It is not safe for
 // concurrent use in multiple goroutines.
 type StreamParser struct {
-	machine *streamMachine
-	handler *MetricHandler
+	machine      *streamMachine
+	handler      *MetricHandler
+	sizeCallback func([]telegraf.Metric, int)
+
+	// Bytes consumed since the last metric
+	consumed int
 }
 
 func NewStreamParser(r io.Reader) *StreamParser {
@@ -194,14 +530,25 @@ func (sp *StreamParser) SetTimeFunc(f func() time.Time) {
 	sp.handler.SetTimeFunc(f)
 }
 
+// SetTimePrecision sets the unit of the timestamps in the stream, e.g. as
+// given by the precision query parameter of a write request. Metrics without
+// timestamp get the current time truncated to the given precision.
 func (sp *StreamParser) SetTimePrecision(u time.Duration) {
 	sp.handler.SetTimePrecision(u)
 }
 
+// SetSizeCallback sets a function called for each parsed metric with the
+// number of bytes consumed since the end of the previous metric, including
+// skipped comments and invalid lines.
+func (sp *StreamParser) SetSizeCallback(fn func([]telegraf.Metric, int)) {
+	sp.sizeCallback = fn
+}
+
 // Next parses the next item from the stream.  You can repeat calls to this
 // function if it returns ParseError to get the next metric or error.
 func (sp *StreamParser) Next() (telegraf.Metric, error) {
 	err := sp.machine.Next()
+	sp.consumed += sp.machine.Position()
 	if errors.Is(err, EOF) {
 		return nil, err
 	}
@@ -222,7 +569,14 @@ func (sp *StreamParser) Next() (telegraf.Metric, error) {
 		}
 	}
 
-	return sp.handler.Metric(), nil
+	m := sp.handler.Metric()
+	if m != nil {
+		if sp.sizeCallback != nil {
+			sp.sizeCallback([]telegraf.Metric{m}, sp.consumed)
+		}
+		sp.consumed = 0
+	}
+	return m, nil
 }
 
 // Position returns the current byte offset into the data.
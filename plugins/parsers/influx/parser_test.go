@@ -897,6 +897,271 @@ func TestParserErrorString(t *testing.T) {
 	}
 }
 
+func TestParserPermissive(t *testing.T) {
+	now := time.Now()
+	input := []byte("cpu value=42\ncpu value=invalid\ncpu value=43\ncpu value=9223372036854775808i\ncpu value=44")
+
+	parser := Parser{Permissive: true}
+	require.NoError(t, parser.Init())
+	parser.SetTimeFunc(func() time.Time { return now })
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 43.0}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 44.0}, now),
+	}
+
+	// The valid metrics are returned together with the errors of the skipped
+	// lines including their line numbers
+	actual, err := parser.Parse(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	var perr *ParseError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, 2, perr.LineNumber)
+
+	errs := err.(interface{ Unwrap() []error }).Unwrap()
+	require.Len(t, errs, 2)
+	require.Equal(t, `metric parse error: expected field at 2:11: "cpu value=invalid"`, errs[0].Error())
+	require.Equal(t, `metric parse error: value out of range at 4:31: "cpu value=9223372036854775808i"`, errs[1].Error())
+
+	// Valid input must not produce an error
+	actual, err = parser.Parse([]byte("cpu value=42\ncpu value=43"))
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+}
+
+func TestParseErrorKeepsLine(t *testing.T) {
+	input := []byte("cpu value=1\ncpu value=invalid\n" + strings.Repeat("cpu value=2\n", 100))
+
+	parser := Parser{}
+	require.NoError(t, parser.Init())
+	_, err := parser.Parse(input)
+	var perr *ParseError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, "cpu value=invalid", perr.buf)
+	require.Equal(t, `metric parse error: expected field at 2:11: "cpu value=invalid"`, err.Error())
+}
+
+func TestParserNullValues(t *testing.T) {
+	now := time.Now()
+	input := []byte("cpu value=,other=1\ncpu value=NaN,other=2\ncpu value=\"null\",other=3\ncpu,host=a value=4,text=\"a,b=\" 0\ncpu value=")
+
+	tests := []struct {
+		name     string
+		action   string
+		expected []telegraf.Metric
+	}{
+		{
+			name: "drop",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"other": 1.0}, now),
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"other": 2.0}, now),
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"other": 3.0}, now),
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 4.0, "text": "a,b="}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:   "string",
+			action: "string",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"value": "", "other": 1.0}, now),
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"value": "NaN", "other": 2.0}, now),
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"value": "null", "other": 3.0}, now),
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 4.0, "text": "a,b="}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"value": ""}, now),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := Parser{
+				NullValues: []string{"", "NaN", "null"},
+				NullAction: tt.action,
+			}
+			require.NoError(t, parser.Init())
+			parser.SetTimeFunc(func() time.Time { return now })
+
+			actual, err := parser.Parse(input)
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParserNullValuesDisabled(t *testing.T) {
+	parser := Parser{}
+	require.NoError(t, parser.Init())
+
+	_, err := parser.Parse([]byte("cpu value="))
+	require.Error(t, err)
+}
+
+func TestParserNullValuesErrorPosition(t *testing.T) {
+	parser := Parser{NullValues: []string{"", "NaN"}}
+	require.NoError(t, parser.Init())
+
+	// Positions refer to the original input and not to the one with quoted
+	// null values
+	_, err := parser.Parse([]byte("cpu value=NaN 1\ncpu value=,other=NaN,bad=x\n"))
+	var perr *ParseError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, 2, perr.LineNumber)
+	require.Equal(t, 16, perr.LineOffset)
+	require.Equal(t, 41, perr.Offset)
+	require.Equal(t, 26, perr.Column)
+	require.Equal(t, `metric parse error: expected field at 2:26: "cpu value=,other=NaN,bad=x"`, err.Error())
+}
+
+func TestParserNullValuesSizeCallback(t *testing.T) {
+	input := []byte("cpu value=NaN 1\ncpu value=2 2\ncpu value=")
+
+	var sizes []int
+	parser := Parser{NullValues: []string{"", "NaN"}, NullAction: "string"}
+	require.NoError(t, parser.Init())
+	parser.SetSizeCallback(func(metrics []telegraf.Metric, size int) {
+		require.Len(t, metrics, 1)
+		sizes = append(sizes, size)
+	})
+
+	metrics, err := parser.Parse(input)
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+	require.Equal(t, []int{16, 14, 10}, sizes)
+}
+
+func TestParserInvalidNullAction(t *testing.T) {
+	parser := Parser{NullAction: "foo"}
+	require.ErrorContains(t, parser.Init(), "invalid null action")
+}
+
+func TestParserReaderAt(t *testing.T) {
+	for _, tt := range ptests {
+		if tt.err != nil {
+			continue
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			// Use a small chunk size to exercise splitting and growing chunks
+			parser := Parser{chunkSize: 8}
+			require.NoError(t, parser.Init())
+			parser.SetTimeFunc(DefaultTime)
+			if tt.timeFunc != nil {
+				parser.SetTimeFunc(tt.timeFunc)
+			}
+
+			metrics, err := parser.ParseReaderAt(bytes.NewReader(tt.input), int64(len(tt.input)))
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, tt.metrics, metrics)
+		})
+	}
+}
+
+func TestParserReaderAtStringNewline(t *testing.T) {
+	now := time.Now()
+	input := []byte("cpu value=\"4\n2\"\ncpu value=1\n")
+
+	// The first chunk ends within the string field
+	parser := Parser{chunkSize: 14}
+	require.NoError(t, parser.Init())
+	parser.SetTimeFunc(func() time.Time { return now })
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": "4\n2"}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, now),
+	}
+
+	actual, err := parser.ParseReaderAt(bytes.NewReader(input), int64(len(input)))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestParserReaderAtPermissive(t *testing.T) {
+	now := time.Now()
+	input := []byte("cpu value=42\ncpu value=invalid\ncpu value=43\ncpu value=9223372036854775808i\ncpu value=44")
+
+	parser := Parser{Permissive: true, chunkSize: 16}
+	require.NoError(t, parser.Init())
+	parser.SetTimeFunc(func() time.Time { return now })
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 43.0}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 44.0}, now),
+	}
+
+	actual, err := parser.ParseReaderAt(bytes.NewReader(input), int64(len(input)))
+	testutil.RequireMetricsEqual(t, expected, actual)
+	require.ErrorContains(t, err, `metric parse error: expected field at 2:11: "cpu value=invalid"`)
+	require.ErrorContains(t, err, `metric parse error: value out of range at 4:31: "cpu value=9223372036854775808i"`)
+
+	// Only the given size is parsed
+	parser.Permissive = false
+	actual, err = parser.ParseReaderAt(bytes.NewReader(input), 13)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected[:1], actual)
+
+	// Errors abort parsing if not permissive
+	_, err = parser.ParseReaderAt(bytes.NewReader(input), int64(len(input)))
+	var perr *ParseError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, 2, perr.LineNumber)
+}
+
+func TestStreamParserTimestampPrecision(t *testing.T) {
+	var tests = []struct {
+		name      string
+		precision time.Duration
+		input     string
+		expected  time.Time
+	}{
+		{
+			name:      "nanosecond",
+			precision: time.Nanosecond,
+			input:     "cpu value=1 1234567890123123999",
+			expected:  time.Unix(0, 1234567890123123999),
+		},
+		{
+			name:      "microsecond",
+			precision: time.Microsecond,
+			input:     "cpu value=1 1234567890123123",
+			expected:  time.Unix(0, 1234567890123123000),
+		},
+		{
+			name:      "millisecond",
+			precision: time.Millisecond,
+			input:     "cpu value=1 1234567890123",
+			expected:  time.Unix(0, 1234567890123000000),
+		},
+		{
+			name:      "second",
+			precision: time.Second,
+			input:     "cpu value=1 1234567890",
+			expected:  time.Unix(1234567890, 0),
+		},
+		{
+			name:      "no timestamp",
+			precision: time.Second,
+			input:     "cpu value=1",
+			expected:  time.Unix(42, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewStreamParser(bytes.NewBufferString(tt.input))
+			parser.SetTimeFunc(func() time.Time { return time.Unix(42, 123456789) })
+			parser.SetTimePrecision(tt.precision)
+
+			m, err := parser.Next()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, m.Time())
+
+			_, err = parser.Next()
+			require.ErrorIs(t, err, EOF)
+		})
+	}
+}
+
 func TestStreamParserErrorString(t *testing.T) {
 	var ptests = []struct {
 		name  string
@@ -1057,3 +1322,225 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse([]byte(benchmarkData))
 	}
 }
+
+func TestParserSizeCallback(t *testing.T) {
+	input := []byte("cpu value=1 1\n# comment\ncpu value=22 2\ninvalid line\ncpu value=333 3\n")
+	expected := []int{14, 25, 29}
+
+	newCallback := func(sizes *[]int) func([]telegraf.Metric, int) {
+		return func(metrics []telegraf.Metric, size int) {
+			require.Len(t, metrics, 1)
+			*sizes = append(*sizes, size)
+		}
+	}
+
+	t.Run("parse", func(t *testing.T) {
+		var sizes []int
+		parser := Parser{Permissive: true}
+		require.NoError(t, parser.Init())
+		parser.SetSizeCallback(newCallback(&sizes))
+
+		metrics, err := parser.Parse(input)
+		require.Error(t, err)
+		require.Len(t, metrics, 3)
+		require.Equal(t, expected, sizes)
+	})
+
+	t.Run("reader at", func(t *testing.T) {
+		var sizes []int
+		parser := Parser{Permissive: true, chunkSize: 16}
+		require.NoError(t, parser.Init())
+		parser.SetSizeCallback(newCallback(&sizes))
+
+		metrics, err := parser.ParseReaderAt(bytes.NewReader(input), int64(len(input)))
+		require.Error(t, err)
+		require.Len(t, metrics, 3)
+		require.Equal(t, expected, sizes)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		var sizes []int
+		parser := NewStreamParser(bytes.NewReader(input))
+		parser.SetSizeCallback(newCallback(&sizes))
+
+		var count int
+		for {
+			m, err := parser.Next()
+			if errors.Is(err, EOF) {
+				break
+			}
+			if err == nil {
+				require.NotNil(t, m)
+				count++
+			}
+		}
+		require.Equal(t, 3, count)
+		require.Equal(t, expected, sizes)
+	})
+}
+
+func TestParserValidateUTF8(t *testing.T) {
+	input := []byte("cpu,host=a\xffb value=1 0\ncpu,host=a value=\"x\xc3\" 0\nc\xffpu value=2 0\ncpu,host=a value=3 0\n")
+
+	tests := []struct {
+		name     string
+		mode     string
+		expected []telegraf.Metric
+		errors   int
+	}{
+		{
+			name: "pass",
+			mode: "pass",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a\xffb"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": "x\xc3"}, time.Unix(0, 0)),
+				metric.New("c\xffpu", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name: "replace",
+			mode: "replace",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a\uFFFDb"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": "x\uFFFD"}, time.Unix(0, 0)),
+				metric.New("c\uFFFDpu", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name: "reject",
+			mode: "reject",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+			},
+			errors: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := Parser{
+				Permissive:   true,
+				ValidateUTF8: tt.mode,
+			}
+			require.NoError(t, parser.Init())
+
+			actual, err := parser.Parse(input)
+			if tt.errors > 0 {
+				require.ErrorContains(t, err, "invalid UTF-8 sequence")
+				require.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), tt.errors)
+			} else {
+				require.NoError(t, err)
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParserInvalidUTF8Mode(t *testing.T) {
+	parser := Parser{ValidateUTF8: "foo"}
+	require.ErrorContains(t, parser.Init(), "invalid UTF-8 validation mode")
+}
+
+func TestParserDirectives(t *testing.T) {
+	input := []byte(`# Exported line protocol
+# precision=s
+cpu value=1 1700000000
+# precision = ms
+cpu value=2 1700000000000
+  # precision=us
+cpu value=3 1700000000000000
+# precision=ns
+cpu value=4 1700000000000000000
+`)
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3.0}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 4.0}, time.Unix(1700000000, 0)),
+	}
+
+	t.Run("parse", func(t *testing.T) {
+		parser := Parser{Directives: true}
+		require.NoError(t, parser.Init())
+
+		// Parse twice to check the directives do not leak into the next call
+		for range 2 {
+			metrics, err := parser.Parse(input)
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, expected, metrics)
+		}
+	})
+
+	t.Run("reader at", func(t *testing.T) {
+		parser := Parser{Directives: true, chunkSize: 16}
+		require.NoError(t, parser.Init())
+
+		metrics, err := parser.ParseReaderAt(bytes.NewReader(input), int64(len(input)))
+		require.NoError(t, err)
+		testutil.RequireMetricsEqual(t, expected, metrics)
+	})
+}
+
+func TestParserDirectivesDisabled(t *testing.T) {
+	parser := Parser{InfluxTimestampPrecision: config.Duration(time.Second)}
+	require.NoError(t, parser.Init())
+
+	metrics, err := parser.Parse([]byte("# precision=ms\ncpu value=1 1700000000\n"))
+	require.NoError(t, err)
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParserDirectivesReset(t *testing.T) {
+	parser := Parser{InfluxTimestampPrecision: config.Duration(time.Second), Directives: true}
+	require.NoError(t, parser.Init())
+
+	metrics, err := parser.Parse([]byte("# precision=ms\ncpu value=1 1700000000000\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, time.Unix(1700000000, 0), metrics[0].Time())
+
+	// The configured precision applies again to data without directive
+	metrics, err = parser.Parse([]byte("cpu value=1 1700000000\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, time.Unix(1700000000, 0), metrics[0].Time())
+}
+
+func TestParserDirectivesInvalid(t *testing.T) {
+	input := []byte("cpu value=1 1\n# precision=days\ncpu value=2 2\n")
+
+	parser := Parser{Directives: true}
+	require.NoError(t, parser.Init())
+	_, err := parser.Parse(input)
+	require.EqualError(t, err, `metric parse error: invalid precision directive "days" at 2:1: "# precision=days"`)
+
+	// Skip the invalid directive in permissive mode
+	parser = Parser{Directives: true, Permissive: true}
+	require.NoError(t, parser.Init())
+	metrics, err := parser.Parse(input)
+	require.ErrorContains(t, err, "invalid precision directive")
+	require.Len(t, metrics, 2)
+}
+
+func TestParserDirectivesSizeCallback(t *testing.T) {
+	input := []byte("cpu value=1 1\n# precision=s\n# comment\ncpu value=22 2\n")
+
+	var sizes []int
+	parser := Parser{Directives: true}
+	require.NoError(t, parser.Init())
+	parser.SetSizeCallback(func(metrics []telegraf.Metric, size int) {
+		require.Len(t, metrics, 1)
+		sizes = append(sizes, size)
+	})
+
+	metrics, err := parser.Parse(input)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	require.Equal(t, []int{14, 39}, sizes)
+	require.Equal(t, time.Unix(2, 0), metrics[1].Time())
+}
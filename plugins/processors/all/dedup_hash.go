@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.dedup_hash
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/dedup_hash" // register plugin
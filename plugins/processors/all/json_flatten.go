@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.json_flatten
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/json_flatten" // register plugin
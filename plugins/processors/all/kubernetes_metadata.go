@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.kubernetes_metadata
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/kubernetes_metadata" // register plugin
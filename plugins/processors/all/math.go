@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.math
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/math" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.reorder
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/reorder" // register plugin
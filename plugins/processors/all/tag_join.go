@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.tag_join
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/tag_join" // register plugin
@@ -0,0 +1,167 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package dedup_hash
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type entry struct {
+	hash    uint64
+	expires time.Time
+}
+
+type DedupHash struct {
+	Window          config.Duration `toml:"window"`
+	IgnoreTimestamp bool            `toml:"ignore_timestamp"`
+	MaxEntries      int             `toml:"max_entries"`
+	Log             telegraf.Logger `toml:"-"`
+
+	// seen maps the content hash of a metric to the time the entry expires
+	// while queue keeps the entries in the order they were added
+	seen  map[uint64]time.Time
+	queue []entry
+	now   func() time.Time
+}
+
+func (*DedupHash) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DedupHash) Init() error {
+	if d.Window <= 0 {
+		return errors.New("window must be positive")
+	}
+	if d.MaxEntries < 0 {
+		return errors.New("max_entries must not be negative")
+	}
+
+	d.seen = make(map[uint64]time.Time)
+	if d.now == nil {
+		d.now = time.Now
+	}
+
+	return nil
+}
+
+func (d *DedupHash) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := d.now()
+	d.expire(now)
+
+	out := in[:0]
+	for _, m := range in {
+		h := d.hash(m)
+		if expires, found := d.seen[h]; found && now.Before(expires) {
+			m.Drop()
+			continue
+		}
+		d.add(h, now.Add(time.Duration(d.Window)))
+		out = append(out, m)
+	}
+
+	return out
+}
+
+// add remembers the hash of a metric until the given expiry time, evicting
+// the oldest entries if the cache is full
+func (d *DedupHash) add(h uint64, expires time.Time) {
+	d.seen[h] = expires
+	d.queue = append(d.queue, entry{hash: h, expires: expires})
+
+	for d.MaxEntries > 0 && len(d.seen) > d.MaxEntries {
+		d.pop()
+	}
+}
+
+// expire removes all entries that are expired at the given time
+func (d *DedupHash) expire(now time.Time) {
+	for len(d.queue) > 0 && !now.Before(d.queue[0].expires) {
+		d.pop()
+	}
+}
+
+// pop removes the oldest entry from the queue and, unless the hash has been
+// re-added since, from the cache
+func (d *DedupHash) pop() {
+	e := d.queue[0]
+	d.queue = d.queue[1:]
+	if expires, found := d.seen[e.hash]; found && expires.Equal(e.expires) {
+		delete(d.seen, e.hash)
+	}
+}
+
+// hash computes a hash over the name, tags, fields and, unless ignored, the
+// timestamp of the metric independent of the order of tags and fields
+func (d *DedupHash) hash(m telegraf.Metric) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(m.Name()))
+	h.Write([]byte("\n"))
+
+	// Tags are always sorted by key
+	for _, tag := range m.TagList() {
+		h.Write([]byte(tag.Key))
+		h.Write([]byte("\n"))
+		h.Write([]byte(tag.Value))
+		h.Write([]byte("\n"))
+	}
+	h.Write([]byte("\n"))
+
+	fields := slices.Clone(m.FieldList())
+	slices.SortFunc(fields, func(a, b *telegraf.Field) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	buf := make([]byte, 0, 32)
+	for _, field := range fields {
+		h.Write([]byte(field.Key))
+		h.Write([]byte("\n"))
+		h.Write(appendValue(buf[:0], field.Value))
+		h.Write([]byte("\n"))
+	}
+
+	if !d.IgnoreTimestamp {
+		h.Write(binary.LittleEndian.AppendUint64(buf[:0], uint64(m.Time().UnixNano())))
+	}
+
+	return h.Sum64()
+}
+
+// appendValue appends a type-prefixed representation of the field value so
+// that e.g. the integer 1 and the float 1.0 are considered different
+func appendValue(buf []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case int64:
+		return binary.LittleEndian.AppendUint64(append(buf, 'i'), uint64(v))
+	case uint64:
+		return binary.LittleEndian.AppendUint64(append(buf, 'u'), v)
+	case float64:
+		return binary.LittleEndian.AppendUint64(append(buf, 'f'), math.Float64bits(v))
+	case bool:
+		return strconv.AppendBool(append(buf, 'b'), v)
+	case string:
+		return append(append(buf, 's'), v...)
+	}
+	return buf
+}
+
+func init() {
+	processors.Add("dedup_hash", func() telegraf.Processor {
+		return &DedupHash{
+			Window:     config.Duration(5 * time.Minute),
+			MaxEntries: 100000,
+		}
+	})
+}
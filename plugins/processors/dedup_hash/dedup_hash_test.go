@@ -0,0 +1,192 @@
+package dedup_hash
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &DedupHash{}
+	require.ErrorContains(t, plugin.Init(), "window must be positive")
+
+	plugin = &DedupHash{Window: config.Duration(time.Minute), MaxEntries: -1}
+	require.ErrorContains(t, plugin.Init(), "max_entries must not be negative")
+}
+
+func TestApply(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name            string
+		ignoreTimestamp bool
+		input           []telegraf.Metric
+		expected        []telegraf.Metric
+	}{
+		{
+			name: "drop exact duplicate",
+			input: []telegraf.Metric{
+				metric.New("m1", map[string]string{"a": "1", "b": "2"}, map[string]interface{}{"x": 1, "y": "s"}, now),
+				metric.New("m1", map[string]string{"b": "2", "a": "1"}, map[string]interface{}{"y": "s", "x": 1}, now),
+			},
+			expected: []telegraf.Metric{
+				metric.New("m1", map[string]string{"a": "1", "b": "2"}, map[string]interface{}{"x": 1, "y": "s"}, now),
+			},
+		},
+		{
+			name: "keep different content",
+			input: []telegraf.Metric{
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1}, now),
+				metric.New("m2", map[string]string{"a": "1"}, map[string]interface{}{"x": 1}, now),
+				metric.New("m1", map[string]string{"a": "2"}, map[string]interface{}{"x": 1}, now),
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 2}, now),
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1.0}, now),
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1, "y": 1}, now),
+			},
+			expected: []telegraf.Metric{
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1}, now),
+				metric.New("m2", map[string]string{"a": "1"}, map[string]interface{}{"x": 1}, now),
+				metric.New("m1", map[string]string{"a": "2"}, map[string]interface{}{"x": 1}, now),
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 2}, now),
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1.0}, now),
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1, "y": 1}, now),
+			},
+		},
+		{
+			name: "keep different timestamp",
+			input: []telegraf.Metric{
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1}, now),
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1}, now.Add(time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1}, now),
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1}, now.Add(time.Second)),
+			},
+		},
+		{
+			name:            "ignore timestamp",
+			ignoreTimestamp: true,
+			input: []telegraf.Metric{
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1}, now),
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1}, now.Add(time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1}, now),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &DedupHash{
+				Window:          config.Duration(time.Minute),
+				IgnoreTimestamp: tt.ignoreTimestamp,
+				now:             func() time.Time { return now },
+			}
+			require.NoError(t, plugin.Init())
+
+			actual := plugin.Apply(tt.input...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestWindowExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	current := now
+
+	plugin := &DedupHash{
+		Window: config.Duration(time.Minute),
+		now:    func() time.Time { return current },
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("m1", map[string]string{"a": "1"}, map[string]interface{}{"x": 1}, now)
+	require.Len(t, plugin.Apply(m.Copy()), 1)
+
+	// Duplicate within the window
+	current = now.Add(59 * time.Second)
+	require.Empty(t, plugin.Apply(m.Copy()))
+
+	// The window is not extended by duplicates
+	current = now.Add(time.Minute)
+	require.Len(t, plugin.Apply(m.Copy()), 1)
+	require.Len(t, plugin.seen, 1)
+	require.Len(t, plugin.queue, 1)
+}
+
+func TestMaxEntries(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	plugin := &DedupHash{
+		Window:     config.Duration(time.Minute),
+		MaxEntries: 2,
+		now:        func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	m1 := metric.New("m1", map[string]string{}, map[string]interface{}{"x": 1}, now)
+	m2 := metric.New("m2", map[string]string{}, map[string]interface{}{"x": 1}, now)
+	m3 := metric.New("m3", map[string]string{}, map[string]interface{}{"x": 1}, now)
+
+	require.Len(t, plugin.Apply(m1.Copy(), m2.Copy(), m3.Copy()), 3)
+	require.Len(t, plugin.seen, 2)
+
+	// The oldest entry was evicted so the metric passes again
+	require.Len(t, plugin.Apply(m1.Copy()), 1)
+	require.Empty(t, plugin.Apply(m3.Copy()))
+}
+
+func TestTracking(t *testing.T) {
+	now := time.Now()
+
+	inputRaw := []telegraf.Metric{
+		metric.New("metric", map[string]string{"tag": "value"}, map[string]interface{}{"foo": 1}, now),
+		metric.New("metric", map[string]string{"tag": "value"}, map[string]interface{}{"foo": 1}, now),
+		metric.New("metric", map[string]string{"tag": "pass"}, map[string]interface{}{"foo": 1}, now),
+	}
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("metric", map[string]string{"tag": "value"}, map[string]interface{}{"foo": 1}, now),
+		metric.New("metric", map[string]string{"tag": "pass"}, map[string]interface{}{"foo": 1}, now),
+	}
+
+	plugin := &DedupHash{Window: config.Duration(time.Minute)}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Simulate output acknowledging delivery
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	// Check delivery
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(input))
+}
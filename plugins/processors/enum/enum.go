@@ -3,8 +3,18 @@ package enum
 
 import (
 	_ "embed"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/filter"
@@ -14,30 +24,106 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+// Maximum number of values a single range key may expand to
+const maxRangeSize = 100000
+
+const (
+	// Maximum number of unmatched values logged per interval and mapping
+	maxUnmatchedLogs     = 10
+	unmatchedLogInterval = time.Minute
+
+	// Maximum number of distinct unmatched values remembered per mapping
+	maxUnmatchedValues = 1000
+
+	// Maximum number of matched sources reported per mapping and kind
+	maxMatchedSources = 1000
+)
+
+var rangeKeyRe = regexp.MustCompile(`^(-?\d+)\.\.(-?\d+)$`)
+
+// Placeholders of the lookup key referencing the value of the mapped source
+// or of other tags and fields of the metric
+var lookupKeyRe = regexp.MustCompile(`<value>|<(tag|field):([^<>]+)>`)
+
 type Enum struct {
-	Mappings []*mapping `toml:"mapping"`
+	Mappings []*mapping      `toml:"mapping"`
+	Log      telegraf.Logger `toml:"-"`
 }
 
 type mapping struct {
-	Tag     string      `toml:"tag" deprecated:"1.35.0;1.40.0;use 'tags' instead"`
-	Field   string      `toml:"field" deprecated:"1.35.0;1.40.0;use 'fields' instead"`
-	Tags    []string    `toml:"tags"`
-	Fields  []string    `toml:"fields"`
-	Dest    string      `toml:"dest"`
-	Default interface{} `toml:"default"`
+	Tag          string            `toml:"tag" deprecated:"1.35.0;1.40.0;use 'tags' instead"`
+	Field        string            `toml:"field" deprecated:"1.35.0;1.40.0;use 'fields' instead"`
+	Tags         []string          `toml:"tags"`
+	Fields       []string          `toml:"fields"`
+	Dest         string            `toml:"dest"`
+	Default      interface{}       `toml:"default"`
+	Unmatched    string            `toml:"unmatched_action"`
+	Condition    map[string]string `toml:"condition"`
+	File         string            `toml:"mapping_file"`
+	DescDest     string            `toml:"description_dest"`
+	Preset       string            `toml:"preset"`
+	LogUnmatched bool              `toml:"log_unmatched"`
+	LookupKey    string            `toml:"lookup_key"`
 
 	fieldFilter filter.Filter
 	tagFilter   filter.Filter
+	conditions  map[string]filter.Filter
+	keyParts    []keyPart
+
+	// Descriptions of the values read from the mapping file
+	descriptions map[string]interface{}
+
+	// Sources matched so far and their destinations. To keep the processing
+	// fast, those are only recorded after the debugging information was
+	// requested for the first time.
+	debug         atomic.Bool
+	matchedLock   sync.Mutex
+	matchedFields map[string]string
+	matchedTags   map[string]string
+
+	// Distinct unmatched values logged so far and the number of values
+	// logged in the current interval
+	log            telegraf.Logger
+	unmatched      map[string]bool
+	unmatchedFull  bool
+	unmatchedCount int
+	unmatchedStart time.Time
 
 	ValueMappings map[string]interface{}
 }
 
+// keyPart is either a literal text or a placeholder of the lookup key
+type keyPart struct {
+	literal string
+	kind    string
+	name    string
+}
+
 func (*Enum) SampleConfig() string {
 	return sampleConfig
 }
 
 func (mapper *Enum) Init() error {
 	for _, mapping := range mapper.Mappings {
+		switch mapping.Unmatched {
+		case "":
+			// Keep the previous behavior of using the default if given
+			mapping.Unmatched = "keep"
+			if mapping.Default != nil {
+				mapping.Unmatched = "set-default"
+			}
+		case "set-default":
+			if mapping.Default == nil {
+				return errors.New("unmatched action 'set-default' requires a default value")
+			}
+		case "keep", "drop-field", "drop-metric":
+			if mapping.Default != nil {
+				return fmt.Errorf("default value cannot be used with unmatched action %q", mapping.Unmatched)
+			}
+		default:
+			return fmt.Errorf("invalid unmatched action %q", mapping.Unmatched)
+		}
+
 		// Handle deprecated field option
 		if mapping.Field != "" {
 			mapping.Fields = append(mapping.Fields, mapping.Field)
@@ -59,31 +145,307 @@ func (mapper *Enum) Init() error {
 			return fmt.Errorf("failed to create new tag filter: %w", err)
 		}
 		mapping.tagFilter = tagFilter
+
+		mapping.matchedFields = make(map[string]string)
+		mapping.matchedTags = make(map[string]string)
+		if mapping.LogUnmatched {
+			mapping.log = mapper.Log
+			mapping.unmatched = make(map[string]bool)
+		}
+
+		mapping.conditions = make(map[string]filter.Filter, len(mapping.Condition))
+		for k, v := range mapping.Condition {
+			f, err := filter.Compile([]string{v})
+			if err != nil {
+				return fmt.Errorf("failed to create condition filter for tag %q: %w", k, err)
+			}
+			mapping.conditions[k] = f
+		}
+
+		if mapping.LookupKey != "" {
+			parts, err := parseLookupKey(mapping.LookupKey)
+			if err != nil {
+				return fmt.Errorf("invalid lookup key %q: %w", mapping.LookupKey, err)
+			}
+			mapping.keyParts = parts
+		}
+
+		if mapping.File != "" {
+			if err := mapping.loadFile(); err != nil {
+				return fmt.Errorf("loading mapping file %q failed: %w", mapping.File, err)
+			}
+		} else if mapping.DescDest != "" {
+			return errors.New("description destination requires a mapping file")
+		}
+
+		if mapping.Preset != "" {
+			if err := mapping.applyPreset(); err != nil {
+				return err
+			}
+		}
+
+		valueMappings, err := expandRanges(mapping.ValueMappings)
+		if err != nil {
+			return fmt.Errorf("expanding value mappings failed: %w", err)
+		}
+		mapping.ValueMappings = valueMappings
+
+		descriptions, err := expandRanges(mapping.descriptions)
+		if err != nil {
+			return fmt.Errorf("expanding descriptions failed: %w", err)
+		}
+		mapping.descriptions = descriptions
 	}
 
 	return nil
 }
 
+// parseLookupKey splits the lookup key into literal texts and placeholders
+func parseLookupKey(key string) ([]keyPart, error) {
+	matches := lookupKeyRe.FindAllStringSubmatchIndex(key, -1)
+	if len(matches) == 0 {
+		return nil, errors.New("no placeholder found")
+	}
+
+	parts := make([]keyPart, 0, 2*len(matches)+1)
+	var last int
+	for _, m := range matches {
+		if m[0] > last {
+			parts = append(parts, keyPart{literal: key[last:m[0]]})
+		}
+		if m[2] < 0 {
+			parts = append(parts, keyPart{kind: "value"})
+		} else {
+			parts = append(parts, keyPart{kind: key[m[2]:m[3]], name: key[m[4]:m[5]]})
+		}
+		last = m[1]
+	}
+	if last < len(key) {
+		parts = append(parts, keyPart{literal: key[last:]})
+	}
+	return parts, nil
+}
+
+// loadFile adds the mappings of the CSV file with the columns code, value and
+// an optional description to the value mappings. Mappings given in the
+// configuration take precedence over the ones of the file. Lines starting
+// with '#' are ignored.
+func (mapping *mapping) loadFile() error {
+	f, err := os.Open(mapping.File)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	valueMappings := make(map[string]interface{}, len(mapping.ValueMappings))
+	maps.Copy(valueMappings, mapping.ValueMappings)
+	mapping.descriptions = make(map[string]interface{})
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(record) < 2 || len(record) > 3 {
+			line, _ := reader.FieldPos(0)
+			return fmt.Errorf("expected two or three columns in line %d but got %d", line, len(record))
+		}
+
+		code := record[0]
+		if _, found := valueMappings[code]; !found {
+			valueMappings[code] = parseValue(record[1])
+		}
+		if len(record) == 3 && record[2] != "" {
+			mapping.descriptions[code] = record[2]
+		}
+	}
+	mapping.ValueMappings = valueMappings
+
+	return nil
+}
+
+// applyPreset adds the mappings of the built-in preset to the value mappings.
+// Mappings given in the configuration or the mapping file take precedence
+// over the ones of the preset.
+func (mapping *mapping) applyPreset() error {
+	preset, found := presets[mapping.Preset]
+	if !found {
+		return fmt.Errorf("unknown preset %q", mapping.Preset)
+	}
+
+	valueMappings := make(map[string]interface{}, len(mapping.ValueMappings)+len(preset))
+	maps.Copy(valueMappings, preset)
+	maps.Copy(valueMappings, mapping.ValueMappings)
+	mapping.ValueMappings = valueMappings
+
+	return nil
+}
+
+// parseValue converts numeric values of the mapping file to integers or
+// floats and keeps all other values as strings
+func parseValue(value string) interface{} {
+	if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseFloat(value, 64); err == nil {
+		return v
+	}
+	return value
+}
+
+// expandRanges replaces keys of the form "<start>..<end>" by one entry for
+// each integer in the inclusive range. Explicitly listed values take
+// precedence over values generated from a range.
+func expandRanges(in map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(in))
+	generated := make(map[string]string)
+	for key, value := range in {
+		match := rangeKeyRe.FindStringSubmatch(key)
+		if match == nil {
+			out[key] = value
+			continue
+		}
+		start, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of range %q: %w", key, err)
+		}
+		end, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end of range %q: %w", key, err)
+		}
+		if start > end {
+			return nil, fmt.Errorf("start of range %q is larger than its end", key)
+		}
+		if uint64(end-start) >= maxRangeSize {
+			return nil, fmt.Errorf("range %q exceeds %d values", key, maxRangeSize)
+		}
+		for i := start; ; i++ {
+			k := strconv.FormatInt(i, 10)
+			if other, found := generated[k]; found {
+				return nil, fmt.Errorf("ranges %q and %q overlap", other, key)
+			}
+			generated[k] = key
+			if _, found := in[k]; !found {
+				out[k] = value
+			}
+			if i == end {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
 func (mapper *Enum) Apply(in ...telegraf.Metric) []telegraf.Metric {
-	for i := 0; i < len(in); i++ {
-		in[i] = mapper.applyMappings(in[i])
+	out := in[:0]
+	for _, m := range in {
+		if !mapper.applyMappings(m) {
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
 	}
-	return in
+	return out
+}
+
+type mappingInfo struct {
+	Fields        []string               `json:"fields,omitempty"`
+	Tags          []string               `json:"tags,omitempty"`
+	Dest          string                 `json:"dest,omitempty"`
+	Default       interface{}            `json:"default,omitempty"`
+	Unmatched     string                 `json:"unmatched_action"`
+	Condition     map[string]string      `json:"condition,omitempty"`
+	File          string                 `json:"mapping_file,omitempty"`
+	DescDest      string                 `json:"description_dest,omitempty"`
+	Preset        string                 `json:"preset,omitempty"`
+	LookupKey     string                 `json:"lookup_key,omitempty"`
+	ValueMappings map[string]interface{} `json:"value_mappings"`
+	MatchedFields map[string]string      `json:"matched_fields"`
+	MatchedTags   map[string]string      `json:"matched_tags"`
 }
 
-func (mapper *Enum) applyMappings(metric telegraf.Metric) telegraf.Metric {
+// DebugInfo returns the effective mapping tables including the fields and
+// tags matched with their destinations. Matched sources are recorded starting
+// with the first call of this function.
+func (mapper *Enum) DebugInfo() interface{} {
+	infos := make([]mappingInfo, 0, len(mapper.Mappings))
+	for _, mapping := range mapper.Mappings {
+		mapping.debug.Store(true)
+
+		mapping.matchedLock.Lock()
+		matchedFields := maps.Clone(mapping.matchedFields)
+		matchedTags := maps.Clone(mapping.matchedTags)
+		mapping.matchedLock.Unlock()
+
+		infos = append(infos, mappingInfo{
+			Fields:        mapping.Fields,
+			Tags:          mapping.Tags,
+			Dest:          mapping.Dest,
+			Default:       mapping.Default,
+			Unmatched:     mapping.Unmatched,
+			Condition:     mapping.Condition,
+			File:          mapping.File,
+			DescDest:      mapping.DescDest,
+			Preset:        mapping.Preset,
+			LookupKey:     mapping.LookupKey,
+			ValueMappings: mapping.ValueMappings,
+			MatchedFields: matchedFields,
+			MatchedTags:   matchedTags,
+		})
+	}
+	return infos
+}
+
+// applyMappings maps the fields and tags of the metric and returns false if
+// the metric should be dropped due to an unmatched value
+func (mapper *Enum) applyMappings(metric telegraf.Metric) bool {
 	newFields := make(map[string]interface{})
 	newTags := make(map[string]string)
+	var dropFields, dropTags []string
 
 	for _, mapping := range mapper.Mappings {
+		if !mapping.matches(metric) {
+			continue
+		}
 		if mapping.fieldFilter != nil {
-			fieldMapping(metric, mapping, newFields)
+			unmatched := fieldMapping(metric, mapping, newFields)
+			if len(unmatched) > 0 {
+				switch mapping.Unmatched {
+				case "drop-metric":
+					return false
+				case "drop-field":
+					dropFields = append(dropFields, unmatched...)
+				}
+			}
 		}
 		if mapping.tagFilter != nil {
-			tagMapping(metric, mapping, newTags)
+			unmatched := tagMapping(metric, mapping, newTags)
+			if len(unmatched) > 0 {
+				switch mapping.Unmatched {
+				case "drop-metric":
+					return false
+				case "drop-field":
+					dropTags = append(dropTags, unmatched...)
+				}
+			}
 		}
 	}
 
+	for _, k := range dropFields {
+		metric.RemoveField(k)
+	}
+
+	for _, k := range dropTags {
+		metric.RemoveTag(k)
+	}
+
 	for k, v := range newFields {
 		writeField(metric, k, v)
 	}
@@ -92,38 +454,64 @@ func (mapper *Enum) applyMappings(metric telegraf.Metric) telegraf.Metric {
 		writeTag(metric, k, v)
 	}
 
-	return metric
+	return true
 }
 
-func fieldMapping(metric telegraf.Metric, mapping *mapping, newFields map[string]interface{}) {
+// fieldMapping maps the matching fields and returns the fields with values
+// not contained in the mapping table
+func fieldMapping(metric telegraf.Metric, mapping *mapping, newFields map[string]interface{}) []string {
+	var unmatched []string
 	fields := metric.FieldList()
 	for _, f := range fields {
 		if !mapping.fieldFilter.Match(f.Key) {
 			continue
 		}
-		if adjustedValue, isString := adjustValue(f.Value).(string); isString {
-			if mappedValue, isMappedValuePresent := mapping.mapValue(adjustedValue); isMappedValuePresent {
-				newFields[mapping.getDestination(f.Key)] = mappedValue
+		mapping.recordMatch(mapping.matchedFields, f.Key)
+		adjustedValue, isString := adjustValue(f.Value).(string)
+		if !isString {
+			unmatched = append(unmatched, f.Key)
+			continue
+		}
+		key := mapping.lookupKey(metric, adjustedValue)
+		if mappedValue, isMappedValuePresent := mapping.mapValue("field", f.Key, key); isMappedValuePresent {
+			newFields[mapping.getDestination(f.Key)] = mappedValue
+			if desc, found := mapping.describe(key); found {
+				newFields[expandDestination(mapping.DescDest, f.Key)] = desc
 			}
+		} else {
+			unmatched = append(unmatched, f.Key)
 		}
 	}
+	return unmatched
 }
 
-func tagMapping(metric telegraf.Metric, mapping *mapping, newTags map[string]string) {
+// tagMapping maps the matching tags and returns the tags with values not
+// contained in the mapping table
+func tagMapping(metric telegraf.Metric, mapping *mapping, newTags map[string]string) []string {
+	var unmatched []string
 	tags := metric.TagList()
 	for _, t := range tags {
 		if !mapping.tagFilter.Match(t.Key) {
 			continue
 		}
-		if mappedValue, isMappedValuePresent := mapping.mapValue(t.Value); isMappedValuePresent {
-			switch val := mappedValue.(type) {
-			case string:
-				newTags[mapping.getDestination(t.Key)] = val
-			default:
-				newTags[mapping.getDestination(t.Key)] = fmt.Sprintf("%v", val)
-			}
+		mapping.recordMatch(mapping.matchedTags, t.Key)
+		key := mapping.lookupKey(metric, t.Value)
+		mappedValue, isMappedValuePresent := mapping.mapValue("tag", t.Key, key)
+		if !isMappedValuePresent {
+			unmatched = append(unmatched, t.Key)
+			continue
+		}
+		switch val := mappedValue.(type) {
+		case string:
+			newTags[mapping.getDestination(t.Key)] = val
+		default:
+			newTags[mapping.getDestination(t.Key)] = fmt.Sprintf("%v", val)
+		}
+		if desc, found := mapping.describe(key); found {
+			newTags[expandDestination(mapping.DescDest, t.Key)] = desc
 		}
 	}
+	return unmatched
 }
 
 func adjustValue(in interface{}) interface{} {
@@ -141,21 +529,132 @@ func adjustValue(in interface{}) interface{} {
 	}
 }
 
-func (mapping *mapping) mapValue(original string) (interface{}, bool) {
+// recordMatch adds the given source to the matched ones if debugging
+// information was requested
+func (mapping *mapping) recordMatch(matched map[string]string, source string) {
+	if !mapping.debug.Load() {
+		return
+	}
+
+	mapping.matchedLock.Lock()
+	defer mapping.matchedLock.Unlock()
+	if _, found := matched[source]; found || len(matched) >= maxMatchedSources {
+		return
+	}
+	matched[source] = mapping.getDestination(source)
+}
+
+// matches returns true if the metric's tags fulfill all conditions of the
+// mapping. Metrics missing one of the condition tags are not matched.
+func (mapping *mapping) matches(metric telegraf.Metric) bool {
+	for k, f := range mapping.conditions {
+		v, found := metric.GetTag(k)
+		if !found || !f.Match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupKey returns the key to look up in the mapping table for the given
+// value of the source. Placeholders of the configured lookup key referencing
+// missing tags or fields are replaced by an empty string.
+func (mapping *mapping) lookupKey(metric telegraf.Metric, value string) string {
+	if len(mapping.keyParts) == 0 {
+		return value
+	}
+
+	var key strings.Builder
+	for _, part := range mapping.keyParts {
+		switch part.kind {
+		case "value":
+			key.WriteString(value)
+		case "tag":
+			v, _ := metric.GetTag(part.name)
+			key.WriteString(v)
+		case "field":
+			if v, found := metric.GetField(part.name); found {
+				key.WriteString(fmt.Sprint(adjustValue(v)))
+			}
+		default:
+			key.WriteString(part.literal)
+		}
+	}
+	return key.String()
+}
+
+func (mapping *mapping) mapValue(kind, source, original string) (interface{}, bool) {
 	if mapped, found := mapping.ValueMappings[original]; found {
 		return mapped, true
 	}
-	if mapping.Default != nil {
+	if mapping.LogUnmatched {
+		mapping.logUnmatched(kind, source, original)
+	}
+	if mapping.Unmatched == "set-default" {
 		return mapping.Default, true
 	}
 	return original, false
 }
 
-func (mapping *mapping) getDestination(defaultDest string) string {
-	if mapping.Dest != "" {
-		return mapping.Dest
+// logUnmatched logs the value of the given source not contained in the
+// mapping table unless it was logged before. At most maxUnmatchedLogs values
+// are logged per interval, further values are logged when they occur again
+// in a later interval.
+func (mapping *mapping) logUnmatched(kind, source, original string) {
+	key := kind + "\x00" + source + "\x00" + original
+	if mapping.unmatchedFull || mapping.unmatched[key] {
+		return
+	}
+	if len(mapping.unmatched) >= maxUnmatchedValues {
+		mapping.log.Warnf("More than %d distinct unmatched values, stop logging", maxUnmatchedValues)
+		mapping.unmatchedFull = true
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(mapping.unmatchedStart) >= unmatchedLogInterval {
+		mapping.unmatchedStart = now
+		mapping.unmatchedCount = 0
+	}
+	if mapping.unmatchedCount >= maxUnmatchedLogs {
+		return
+	}
+	mapping.unmatchedCount++
+	mapping.unmatched[key] = true
+
+	mapping.log.Infof("No mapping for value %q of %s %q", original, kind, source)
+}
+
+// describe returns the description of the given value if a description
+// destination is configured
+func (mapping *mapping) describe(original string) (string, bool) {
+	if mapping.DescDest == "" {
+		return "", false
+	}
+	desc, found := mapping.descriptions[original]
+	if !found {
+		return "", false
+	}
+	return desc.(string), true
+}
+
+// getDestination returns the name of the tag or field to write the mapped
+// value of the given source to.
+func (mapping *mapping) getDestination(source string) string {
+	if mapping.Dest == "" {
+		return source
+	}
+	return expandDestination(mapping.Dest, source)
+}
+
+// expandDestination replaces any "{{field}}" or "{{tag}}" placeholder in the
+// destination by the source name, allowing to map multiple glob-matched
+// sources to individual destinations.
+func expandDestination(dest, source string) string {
+	if !strings.Contains(dest, "{{") {
+		return dest
 	}
-	return defaultDest
+	return strings.NewReplacer("{{field}}", source, "{{tag}}", source).Replace(dest)
 }
 
 func writeField(metric telegraf.Metric, name string, value interface{}) {
@@ -1,6 +1,7 @@
 package enum
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func createTestMetric() telegraf.Metric {
@@ -189,6 +191,37 @@ func TestWritesToDestination(t *testing.T) {
 	assertFieldValue(t, 1, "string_code", fields)
 }
 
+func TestWritesToDestinationTemplate(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{
+		{
+			Fields:        []string{"*string_value"},
+			Dest:          "{{field}}_code",
+			ValueMappings: map[string]interface{}{"test": 1},
+		},
+		{
+			Tags:          []string{"*tag"},
+			Dest:          "{{tag}}_code",
+			ValueMappings: map[string]interface{}{"tag_value": "valuable"},
+		},
+	}}
+	require.NoError(t, mapper.Init())
+
+	m := createTestMetric()
+	processed := mapper.Apply(m)[0]
+
+	fields := processed.Fields()
+	assertFieldValue(t, "test", "string_value", fields)
+	assertFieldValue(t, 1, "string_value_code", fields)
+	assertFieldValue(t, "test", "duplicate_string_value", fields)
+	assertFieldValue(t, 1, "duplicate_string_value_code", fields)
+
+	tags := processed.Tags()
+	assertTagValue(t, "tag_value", "tag", tags)
+	assertTagValue(t, "valuable", "tag_code", tags)
+	assertTagValue(t, "tag_value", "duplicate_tag", tags)
+	assertTagValue(t, "valuable", "duplicate_tag_code", tags)
+}
+
 func TestDoNotWriteToDestinationWithoutDefaultOrDefinedMapping(t *testing.T) {
 	field := "string_code"
 	mapper := Enum{Mappings: []*mapping{{
@@ -295,3 +328,581 @@ func TestTracking(t *testing.T) {
 		return delivered
 	}, time.Second, 100*time.Millisecond, "no metrics delivered")
 }
+
+func TestConditionalMapping(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{
+		{
+			Fields:        []string{"state"},
+			Dest:          "state_code",
+			Condition:     map[string]string{"plugin": "chrony", "host": "node-*"},
+			ValueMappings: map[string]interface{}{"synced": 1},
+		},
+		{
+			Fields:        []string{"state"},
+			Dest:          "state_code",
+			Condition:     map[string]string{"plugin": "ntpq"},
+			ValueMappings: map[string]interface{}{"synced": 2},
+		},
+	}}
+	require.NoError(t, mapper.Init())
+
+	tests := []struct {
+		name     string
+		tags     map[string]string
+		expected interface{}
+	}{
+		{
+			name:     "first condition",
+			tags:     map[string]string{"plugin": "chrony", "host": "node-1"},
+			expected: int64(1),
+		},
+		{
+			name:     "second condition",
+			tags:     map[string]string{"plugin": "ntpq", "host": "node-1"},
+			expected: int64(2),
+		},
+		{
+			name: "partial match",
+			tags: map[string]string{"plugin": "chrony", "host": "server"},
+		},
+		{
+			name: "missing tag",
+			tags: map[string]string{"plugin": "chrony"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := metric.New("m1", tt.tags, map[string]interface{}{"state": "synced"}, time.Now())
+			fields := mapper.Apply(input)[0].Fields()
+			if tt.expected == nil {
+				require.NotContains(t, fields, "state_code")
+				return
+			}
+			assertFieldValue(t, tt.expected, "state_code", fields)
+		})
+	}
+}
+
+func TestConditionInvalid(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{{
+		Fields:    []string{"state"},
+		Condition: map[string]string{"plugin": "[chrony"},
+	}}}
+	require.ErrorContains(t, mapper.Init(), `failed to create condition filter for tag "plugin"`)
+}
+
+func TestDebugInfo(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{{
+		Fields:        []string{"*_status"},
+		Dest:          "{{field}}_code",
+		Default:       int64(0),
+		ValueMappings: map[string]interface{}{"green": 1, "red": 3},
+	}}}
+	require.NoError(t, mapper.Init())
+
+	m := metric.New(
+		"test",
+		map[string]string{},
+		map[string]interface{}{"disk_status": "green", "net_status": "red", "value": 42},
+		time.Unix(0, 0),
+	)
+	mapper.Apply(m.Copy())
+
+	// Matched sources are only recorded after the first request
+	expected := []mappingInfo{{
+		Fields:        []string{"*_status"},
+		Dest:          "{{field}}_code",
+		Default:       int64(0),
+		Unmatched:     "set-default",
+		ValueMappings: map[string]interface{}{"green": 1, "red": 3},
+		MatchedFields: map[string]string{},
+		MatchedTags:   map[string]string{},
+	}}
+	require.Equal(t, expected, mapper.DebugInfo())
+
+	mapper.Apply(m)
+	expected[0].MatchedFields = map[string]string{"disk_status": "disk_status_code", "net_status": "net_status_code"}
+	require.Equal(t, expected, mapper.DebugInfo())
+}
+
+func TestDebugInfoBounded(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{{
+		Fields:        []string{"*"},
+		ValueMappings: map[string]interface{}{"green": 1},
+	}}}
+	require.NoError(t, mapper.Init())
+	mapper.DebugInfo()
+
+	for i := range maxMatchedSources + 10 {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{fmt.Sprintf("field%d", i): "green"}, time.Unix(0, 0))
+		mapper.Apply(m)
+	}
+
+	infos := mapper.DebugInfo().([]mappingInfo)
+	require.Len(t, infos[0].MatchedFields, maxMatchedSources)
+}
+
+func TestRangeKeys(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{{
+		Fields: []string{"code"},
+		ValueMappings: map[string]interface{}{
+			"1..5":    "low",
+			"6..10":   "high",
+			"-3..-1":  "negative",
+			"7":       "seven",
+			"unknown": "none",
+		},
+	}}}
+	require.NoError(t, mapper.Init())
+
+	tests := []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{value: int64(1), expected: "low"},
+		{value: int64(5), expected: "low"},
+		{value: uint64(6), expected: "high"},
+		{value: int64(7), expected: "seven"},
+		{value: float64(10), expected: "high"},
+		{value: int64(-2), expected: "negative"},
+		{value: "unknown", expected: "none"},
+		{value: int64(11), expected: int64(11)},
+		{value: int64(0), expected: int64(0)},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.value), func(t *testing.T) {
+			m := metric.New("test", map[string]string{}, map[string]interface{}{"code": tt.value}, time.Unix(0, 0))
+			fields := mapper.Apply(m)[0].Fields()
+			assertFieldValue(t, tt.expected, "code", fields)
+		})
+	}
+}
+
+func TestLookupKey(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{{
+		Fields:    []string{"status"},
+		LookupKey: "<tag:region>:<field:vendor>/<value>",
+		ValueMappings: map[string]interface{}{
+			"eu:acme/1": "ok",
+			"us:acme/1": "degraded",
+			"eu:/1":     "unknown vendor",
+		},
+	}}}
+	require.NoError(t, mapper.Init())
+
+	tests := []struct {
+		name     string
+		tags     map[string]string
+		fields   map[string]interface{}
+		expected interface{}
+	}{
+		{
+			name:     "eu",
+			tags:     map[string]string{"region": "eu"},
+			fields:   map[string]interface{}{"status": int64(1), "vendor": "acme"},
+			expected: "ok",
+		},
+		{
+			name:     "us",
+			tags:     map[string]string{"region": "us"},
+			fields:   map[string]interface{}{"status": int64(1), "vendor": "acme"},
+			expected: "degraded",
+		},
+		{
+			name:     "missing field",
+			tags:     map[string]string{"region": "eu"},
+			fields:   map[string]interface{}{"status": int64(1)},
+			expected: "unknown vendor",
+		},
+		{
+			name:     "unmatched",
+			tags:     map[string]string{"region": "ap"},
+			fields:   map[string]interface{}{"status": int64(1), "vendor": "acme"},
+			expected: int64(1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metric.New("test", tt.tags, tt.fields, time.Unix(0, 0))
+			fields := mapper.Apply(m)[0].Fields()
+			assertFieldValue(t, tt.expected, "status", fields)
+		})
+	}
+}
+
+func TestLookupKeyTag(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{{
+		Tags:          []string{"state"},
+		LookupKey:     "<tag:vendor>-<value>",
+		ValueMappings: map[string]interface{}{"acme-1": "up", "other-1": "down"},
+	}}}
+	require.NoError(t, mapper.Init())
+
+	m := metric.New("test", map[string]string{"state": "1", "vendor": "other"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	tags := mapper.Apply(m)[0].Tags()
+	assertTagValue(t, "down", "state", tags)
+}
+
+func TestLookupKeyInvalid(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{{
+		Fields:        []string{"status"},
+		LookupKey:     "<region>:<status>",
+		ValueMappings: map[string]interface{}{"eu:1": "ok"},
+	}}}
+	require.ErrorContains(t, mapper.Init(), `invalid lookup key "<region>:<status>": no placeholder found`)
+}
+
+func TestRangeKeysInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "reversed",
+			mappings: map[string]interface{}{"5..1": "low"},
+			expected: `start of range "5..1" is larger than its end`,
+		},
+		{
+			name:     "too large",
+			mappings: map[string]interface{}{"0..1000000": "any"},
+			expected: `range "0..1000000" exceeds 100000 values`,
+		},
+		{
+			name:     "overlap",
+			mappings: map[string]interface{}{"1..5": "low", "5..10": "high"},
+			expected: "overlap",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapper := Enum{Mappings: []*mapping{{
+				Fields:        []string{"code"},
+				ValueMappings: tt.mappings,
+			}}}
+			require.ErrorContains(t, mapper.Init(), tt.expected)
+		})
+	}
+}
+
+func TestUnmatchedAction(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{"color": "green"}, map[string]interface{}{"status": "ok", "value": 1}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{"color": "purple"}, map[string]interface{}{"status": "weird", "value": 2}, time.Unix(0, 0)),
+	}
+
+	tests := []struct {
+		name     string
+		action   string
+		def      interface{}
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "keep",
+			action: "keep",
+			expected: []telegraf.Metric{
+				metric.New("test", map[string]string{"color": "1"}, map[string]interface{}{"status": int64(0), "value": 1}, time.Unix(0, 0)),
+				metric.New("test", map[string]string{"color": "purple"}, map[string]interface{}{"status": "weird", "value": 2}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:   "drop field",
+			action: "drop-field",
+			expected: []telegraf.Metric{
+				metric.New("test", map[string]string{"color": "1"}, map[string]interface{}{"status": int64(0), "value": 1}, time.Unix(0, 0)),
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:   "drop metric",
+			action: "drop-metric",
+			expected: []telegraf.Metric{
+				metric.New("test", map[string]string{"color": "1"}, map[string]interface{}{"status": int64(0), "value": 1}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:   "set default",
+			action: "set-default",
+			def:    int64(-1),
+			expected: []telegraf.Metric{
+				metric.New("test", map[string]string{"color": "1"}, map[string]interface{}{"status": int64(0), "value": 1}, time.Unix(0, 0)),
+				metric.New("test", map[string]string{"color": "-1"}, map[string]interface{}{"status": int64(-1), "value": 2}, time.Unix(0, 0)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapper := Enum{Mappings: []*mapping{
+				{
+					Fields:        []string{"status"},
+					Default:       tt.def,
+					Unmatched:     tt.action,
+					ValueMappings: map[string]interface{}{"ok": int64(0), "failed": int64(1)},
+				},
+				{
+					Tags:          []string{"color"},
+					Default:       tt.def,
+					Unmatched:     tt.action,
+					ValueMappings: map[string]interface{}{"green": int64(1)},
+				},
+			}}
+			require.NoError(t, mapper.Init())
+
+			metrics := make([]telegraf.Metric, 0, len(input))
+			for _, m := range input {
+				metrics = append(metrics, m.Copy())
+			}
+			actual := mapper.Apply(metrics...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestUnmatchedActionDropTracking(t *testing.T) {
+	var delivered bool
+	notify := func(telegraf.DeliveryInfo) {
+		delivered = true
+	}
+	m := metric.New("test", map[string]string{}, map[string]interface{}{"status": "unknown"}, time.Unix(0, 0))
+	m, _ = metric.WithTracking(m, notify)
+
+	mapper := Enum{Mappings: []*mapping{{
+		Fields:        []string{"status"},
+		Unmatched:     "drop-metric",
+		ValueMappings: map[string]interface{}{"ok": 0},
+	}}}
+	require.NoError(t, mapper.Init())
+
+	require.Empty(t, mapper.Apply(m))
+	require.Eventually(t, func() bool {
+		return delivered
+	}, time.Second, 100*time.Millisecond, "metric not delivered")
+}
+
+func TestUnmatchedActionInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   string
+		def      interface{}
+		expected string
+	}{
+		{
+			name:     "unknown action",
+			action:   "ignore",
+			expected: `invalid unmatched action "ignore"`,
+		},
+		{
+			name:     "set default without default",
+			action:   "set-default",
+			expected: "unmatched action 'set-default' requires a default value",
+		},
+		{
+			name:     "default with other action",
+			action:   "drop-metric",
+			def:      0,
+			expected: `default value cannot be used with unmatched action "drop-metric"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapper := Enum{Mappings: []*mapping{{
+				Fields:    []string{"status"},
+				Default:   tt.def,
+				Unmatched: tt.action,
+			}}}
+			require.ErrorContains(t, mapper.Init(), tt.expected)
+		})
+	}
+}
+
+func TestMappingFile(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{{
+		Fields:        []string{"status"},
+		Tags:          []string{"state"},
+		File:          "testdata/codes.csv",
+		DescDest:      "{{field}}_description",
+		ValueMappings: map[string]interface{}{"0": "normal"},
+	}}}
+	require.NoError(t, mapper.Init())
+
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"status": int64(0)}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"status": int64(1)}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"status": int64(2)}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"status": int64(12)}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"status": int64(42)}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{"state": "1"}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{"status": "normal", "status_description": "Device is operating normally"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{"status": "warning", "status_description": "Supply voltage out of range, check PSU"},
+			time.Unix(0, 0),
+		),
+		metric.New("test", map[string]string{}, map[string]interface{}{"status": 2.5}, time.Unix(0, 0)),
+		metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{"status": "critical", "status_description": "Hardware failure"},
+			time.Unix(0, 0),
+		),
+		metric.New("test", map[string]string{}, map[string]interface{}{"status": int64(42)}, time.Unix(0, 0)),
+		metric.New(
+			"test",
+			map[string]string{"state": "warning", "state_description": "Supply voltage out of range, check PSU"},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := mapper.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestMappingFileInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		descDest string
+		expected string
+	}{
+		{
+			name:     "missing file",
+			file:     "testdata/nonexistent.csv",
+			expected: "loading mapping file",
+		},
+		{
+			name:     "description without file",
+			descDest: "description",
+			expected: "description destination requires a mapping file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapper := Enum{Mappings: []*mapping{{
+				Fields:   []string{"status"},
+				File:     tt.file,
+				DescDest: tt.descDest,
+			}}}
+			require.ErrorContains(t, mapper.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPreset(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{
+		{
+			Fields:        []string{"ifOperStatus"},
+			Preset:        "snmp_ifOperStatus",
+			ValueMappings: map[string]interface{}{"5": "sleeping"},
+		},
+		{
+			Tags:   []string{"state"},
+			Preset: "windows_service_state",
+		},
+	}}
+	require.NoError(t, mapper.Init())
+
+	input := []telegraf.Metric{
+		metric.New("interface", map[string]string{}, map[string]interface{}{"ifOperStatus": int64(1)}, time.Unix(0, 0)),
+		metric.New("interface", map[string]string{}, map[string]interface{}{"ifOperStatus": int64(5)}, time.Unix(0, 0)),
+		metric.New("interface", map[string]string{}, map[string]interface{}{"ifOperStatus": int64(42)}, time.Unix(0, 0)),
+		metric.New("win_services", map[string]string{"state": "4"}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("interface", map[string]string{}, map[string]interface{}{"ifOperStatus": "up"}, time.Unix(0, 0)),
+		metric.New("interface", map[string]string{}, map[string]interface{}{"ifOperStatus": "sleeping"}, time.Unix(0, 0)),
+		metric.New("interface", map[string]string{}, map[string]interface{}{"ifOperStatus": int64(42)}, time.Unix(0, 0)),
+		metric.New("win_services", map[string]string{"state": "running"}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+	}
+
+	actual := mapper.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestPresetInvalid(t *testing.T) {
+	mapper := Enum{Mappings: []*mapping{{
+		Fields: []string{"status"},
+		Preset: "unknown",
+	}}}
+	require.ErrorContains(t, mapper.Init(), `unknown preset "unknown"`)
+}
+
+func TestLogUnmatched(t *testing.T) {
+	logger := &testutil.CaptureLogger{}
+	mapper := Enum{
+		Mappings: []*mapping{{
+			Fields:        []string{"status"},
+			Tags:          []string{"state"},
+			LogUnmatched:  true,
+			ValueMappings: map[string]interface{}{"green": 1},
+		}},
+		Log: logger,
+	}
+	require.NoError(t, mapper.Init())
+
+	input := []telegraf.Metric{
+		metric.New("m", map[string]string{"state": "ok"}, map[string]interface{}{"status": "green"}, time.Unix(0, 0)),
+		metric.New("m", map[string]string{"state": "ok"}, map[string]interface{}{"status": "blue"}, time.Unix(0, 0)),
+		metric.New("m", map[string]string{}, map[string]interface{}{"status": "blue"}, time.Unix(0, 0)),
+	}
+	mapper.Apply(input...)
+
+	// Each distinct value is only logged once
+	var messages []string
+	for _, e := range logger.Messages() {
+		messages = append(messages, e.Text)
+	}
+	expected := []string{
+		`No mapping for value "blue" of field "status"`,
+		`No mapping for value "ok" of tag "state"`,
+	}
+	require.ElementsMatch(t, expected, messages)
+}
+
+func TestLogUnmatchedRateLimit(t *testing.T) {
+	logger := &testutil.CaptureLogger{}
+	mapper := Enum{
+		Mappings: []*mapping{{
+			Fields:       []string{"status"},
+			LogUnmatched: true,
+		}},
+		Log: logger,
+	}
+	require.NoError(t, mapper.Init())
+
+	input := make([]telegraf.Metric, 0, 2*maxUnmatchedLogs)
+	for i := range 2 * maxUnmatchedLogs {
+		input = append(input, metric.New("m", map[string]string{}, map[string]interface{}{"status": fmt.Sprintf("s%d", i)}, time.Unix(0, 0)))
+	}
+	mapper.Apply(input...)
+	require.Equal(t, maxUnmatchedLogs, logger.NMessages())
+
+	// Values not logged due to the rate limit are logged in the next interval
+	mapper.Mappings[0].unmatchedStart = mapper.Mappings[0].unmatchedStart.Add(-unmatchedLogInterval)
+	for _, m := range input {
+		mapper.Apply(m.Copy())
+	}
+	require.Equal(t, 2*maxUnmatchedLogs, logger.NMessages())
+}
+
+func TestLogUnmatchedDisabled(t *testing.T) {
+	logger := &testutil.CaptureLogger{}
+	mapper := Enum{
+		Mappings: []*mapping{{Fields: []string{"status"}}},
+		Log:      logger,
+	}
+	require.NoError(t, mapper.Init())
+
+	mapper.Apply(metric.New("m", map[string]string{}, map[string]interface{}{"status": "blue"}, time.Unix(0, 0)))
+	require.Zero(t, logger.NMessages())
+}
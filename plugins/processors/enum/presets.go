@@ -0,0 +1,38 @@
+package enum
+
+// presets contains the built-in mapping tables of well-known enumerations
+// translating the numeric codes into their names
+var presets = map[string]map[string]interface{}{
+	// Active state codes as reported by the systemd_units input in the
+	// "active_code" field, see the unit_active_state_table of systemd
+	"systemd_unit_states": {
+		"0": "active",
+		"1": "reloading",
+		"2": "inactive",
+		"3": "failed",
+		"4": "activating",
+		"5": "deactivating",
+	},
+	// Operational status of network interfaces (ifOperStatus) as defined in
+	// IF-MIB, RFC 2863
+	"snmp_ifOperStatus": {
+		"1": "up",
+		"2": "down",
+		"3": "testing",
+		"4": "unknown",
+		"5": "dormant",
+		"6": "notPresent",
+		"7": "lowerLayerDown",
+	},
+	// Service states as reported by the win_services input in the "state"
+	// field, see the SERVICE_STATUS structure of the Windows API
+	"windows_service_state": {
+		"1": "stopped",
+		"2": "start_pending",
+		"3": "stop_pending",
+		"4": "running",
+		"5": "continue_pending",
+		"6": "pause_pending",
+		"7": "paused",
+	},
+}
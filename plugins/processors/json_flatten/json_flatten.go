@@ -0,0 +1,280 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package json_flatten
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type JSONFlatten struct {
+	Mode         string          `toml:"mode"`
+	Fields       []string        `toml:"fields"`
+	Separator    string          `toml:"separator"`
+	MaxDepth     int             `toml:"max_depth"`
+	Arrays       string          `toml:"arrays"`
+	KeepOriginal bool            `toml:"keep_original"`
+	Log          telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+}
+
+func (*JSONFlatten) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *JSONFlatten) Init() error {
+	switch p.Mode {
+	case "":
+		p.Mode = "flatten"
+	case "flatten", "unflatten":
+	default:
+		return fmt.Errorf("invalid mode %q", p.Mode)
+	}
+
+	switch p.Arrays {
+	case "":
+		p.Arrays = "index"
+	case "index", "json", "drop":
+	default:
+		return fmt.Errorf("invalid arrays setting %q", p.Arrays)
+	}
+
+	if len(p.Fields) == 0 {
+		return errors.New("no fields given")
+	}
+	if p.Separator == "" {
+		p.Separator = "."
+	}
+	if p.MaxDepth < 0 {
+		return errors.New("max_depth must not be negative")
+	}
+
+	var err error
+	p.fieldFilter, err = filter.Compile(p.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+
+	return nil
+}
+
+func (p *JSONFlatten) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		if p.Mode == "unflatten" {
+			p.unflatten(m)
+		} else {
+			p.flatten(m)
+		}
+	}
+	return in
+}
+
+func (p *JSONFlatten) flatten(m telegraf.Metric) {
+	// Collect the fields first as the field list changes while flattening
+	sources := make(map[string]string)
+	for _, field := range m.FieldList() {
+		if v, ok := field.Value.(string); ok && p.fieldFilter.Match(field.Key) {
+			sources[field.Key] = v
+		}
+	}
+
+	for name, raw := range sources {
+		decoder := json.NewDecoder(strings.NewReader(raw))
+		decoder.UseNumber()
+		var obj map[string]interface{}
+		if err := decoder.Decode(&obj); err != nil {
+			p.Log.Debugf("Field %q of metric %q is not a JSON object: %v", name, m.Name(), err)
+			continue
+		}
+
+		fields := make(map[string]interface{})
+		if err := p.flattenValue(name, obj, 1, fields); err != nil {
+			p.Log.Errorf("Flattening field %q of metric %q failed: %v", name, m.Name(), err)
+			continue
+		}
+
+		if !p.KeepOriginal {
+			m.RemoveField(name)
+		}
+		for k, v := range fields {
+			m.AddField(k, v)
+		}
+	}
+}
+
+// flattenValue adds the given value at the given path and depth to the fields
+func (p *JSONFlatten) flattenValue(path string, value interface{}, depth int, fields map[string]interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if p.MaxDepth > 0 && depth > p.MaxDepth {
+			return encode(path, v, fields)
+		}
+		for k, child := range v {
+			if err := p.flattenValue(path+p.Separator+k, child, depth+1, fields); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		switch p.Arrays {
+		case "json":
+			return encode(path, v, fields)
+		case "drop":
+			return nil
+		}
+		if p.MaxDepth > 0 && depth > p.MaxDepth {
+			return encode(path, v, fields)
+		}
+		for i, child := range v {
+			if err := p.flattenValue(path+p.Separator+strconv.Itoa(i), child, depth+1, fields); err != nil {
+				return err
+			}
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			fields[path] = i
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("converting %q at %q failed: %w", v, path, err)
+		}
+		fields[path] = f
+	case string, bool:
+		fields[path] = v
+	case nil:
+		// Fields cannot be null so skip the value
+	}
+	return nil
+}
+
+func encode(path string, value interface{}, fields map[string]interface{}) error {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding value at %q failed: %w", path, err)
+	}
+	fields[path] = string(buf)
+	return nil
+}
+
+func (p *JSONFlatten) unflatten(m telegraf.Metric) {
+	// Group the flat fields by the first element of their path
+	groups := make(map[string][]*telegraf.Field)
+	for _, field := range m.FieldList() {
+		root, _, found := strings.Cut(field.Key, p.Separator)
+		if found && p.fieldFilter.Match(root) {
+			groups[root] = append(groups[root], field)
+		}
+	}
+
+	roots := make([]string, 0, len(groups))
+	for root := range groups {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	for _, root := range roots {
+		obj := make(map[string]interface{})
+		conflict := ""
+		for _, field := range groups[root] {
+			path := strings.Split(strings.TrimPrefix(field.Key, root+p.Separator), p.Separator)
+			if !insert(obj, path, field.Value) {
+				conflict = field.Key
+				break
+			}
+		}
+		if conflict != "" {
+			p.Log.Errorf("Cannot unflatten field %q of metric %q as %q conflicts with other fields", root, m.Name(), conflict)
+			continue
+		}
+
+		var value interface{} = obj
+		if p.Arrays == "index" {
+			value = toArrays(obj)
+		}
+		buf, err := json.Marshal(value)
+		if err != nil {
+			p.Log.Errorf("Encoding field %q of metric %q failed: %v", root, m.Name(), err)
+			continue
+		}
+
+		if !p.KeepOriginal {
+			for _, field := range groups[root] {
+				m.RemoveField(field.Key)
+			}
+		}
+		m.AddField(root, string(buf))
+	}
+}
+
+// insert sets the value at the given path creating intermediate objects and
+// returns false if the path conflicts with values inserted before
+func insert(obj map[string]interface{}, path []string, value interface{}) bool {
+	for _, key := range path[:len(path)-1] {
+		child, found := obj[key]
+		if !found {
+			next := make(map[string]interface{})
+			obj[key] = next
+			obj = next
+			continue
+		}
+		next, ok := child.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		obj = next
+	}
+
+	key := path[len(path)-1]
+	if _, found := obj[key]; found {
+		return false
+	}
+	obj[key] = value
+	return true
+}
+
+// toArrays recursively converts objects with the keys 0 to n-1 into arrays
+func toArrays(value interface{}) interface{} {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	for k, v := range obj {
+		obj[k] = toArrays(v)
+	}
+
+	arr := make([]interface{}, len(obj))
+	for k, v := range obj {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(obj) || strconv.Itoa(i) != k {
+			return obj
+		}
+		arr[i] = v
+	}
+	if len(arr) == 0 {
+		return obj
+	}
+	return arr
+}
+
+func init() {
+	processors.Add("json_flatten", func() telegraf.Processor {
+		return &JSONFlatten{
+			Mode:      "flatten",
+			Separator: ".",
+			Arrays:    "index",
+		}
+	})
+}
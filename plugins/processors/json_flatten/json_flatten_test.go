@@ -0,0 +1,222 @@
+package json_flatten
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestFlatten(t *testing.T) {
+	now := time.Now()
+	payload := `{"user":{"id":42,"name":"alice","admin":false},"load":0.5,"tags":["a","b"],"extra":null}`
+
+	tests := []struct {
+		name     string
+		plugin   *JSONFlatten
+		expected map[string]interface{}
+	}{
+		{
+			name:   "defaults",
+			plugin: &JSONFlatten{Fields: []string{"payload"}},
+			expected: map[string]interface{}{
+				"status":             "ok",
+				"payload.user.id":    int64(42),
+				"payload.user.name":  "alice",
+				"payload.user.admin": false,
+				"payload.load":       0.5,
+				"payload.tags.0":     "a",
+				"payload.tags.1":     "b",
+			},
+		},
+		{
+			name:   "max depth",
+			plugin: &JSONFlatten{Fields: []string{"payload"}, MaxDepth: 1},
+			expected: map[string]interface{}{
+				"status":       "ok",
+				"payload.user": `{"admin":false,"id":42,"name":"alice"}`,
+				"payload.load": 0.5,
+				"payload.tags": `["a","b"]`,
+			},
+		},
+		{
+			name:   "arrays as json",
+			plugin: &JSONFlatten{Fields: []string{"payload"}, Arrays: "json", Separator: "_"},
+			expected: map[string]interface{}{
+				"status":             "ok",
+				"payload_user_id":    int64(42),
+				"payload_user_name":  "alice",
+				"payload_user_admin": false,
+				"payload_load":       0.5,
+				"payload_tags":       `["a","b"]`,
+			},
+		},
+		{
+			name:   "drop arrays and keep original",
+			plugin: &JSONFlatten{Fields: []string{"pay*"}, Arrays: "drop", KeepOriginal: true},
+			expected: map[string]interface{}{
+				"status":             "ok",
+				"payload":            payload,
+				"payload.user.id":    int64(42),
+				"payload.user.name":  "alice",
+				"payload.user.admin": false,
+				"payload.load":       0.5,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			input := metric.New("events", map[string]string{}, map[string]interface{}{"payload": payload, "status": "ok"}, now)
+			expected := []telegraf.Metric{metric.New("events", map[string]string{}, tt.expected, now)}
+			actual := tt.plugin.Apply(input)
+			testutil.RequireMetricsEqual(t, expected, actual, testutil.SortMetrics())
+		})
+	}
+}
+
+func TestFlattenInvalid(t *testing.T) {
+	plugin := &JSONFlatten{Fields: []string{"payload"}, Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("events", map[string]string{}, map[string]interface{}{"payload": "not json"}, now),
+		metric.New("events", map[string]string{}, map[string]interface{}{"payload": `[1, 2]`}, now),
+		metric.New("events", map[string]string{}, map[string]interface{}{"payload": int64(1)}, now),
+	}
+	expected := make([]telegraf.Metric, 0, len(input))
+	for _, m := range input {
+		expected = append(expected, m.Copy())
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestUnflatten(t *testing.T) {
+	now := time.Now()
+	fields := map[string]interface{}{
+		"status":             "ok",
+		"payload.user.id":    int64(42),
+		"payload.user.name":  "alice",
+		"payload.load":       0.5,
+		"payload.tags.0":     "a",
+		"payload.tags.1":     "b",
+		"payload.slots.1":    "x",
+		"payload.slots.2":    "y",
+		"other.value":        int64(1),
+		"payloadless.value":  int64(2),
+		"payload_not_nested": true,
+	}
+
+	tests := []struct {
+		name     string
+		plugin   *JSONFlatten
+		expected map[string]interface{}
+	}{
+		{
+			name:   "arrays",
+			plugin: &JSONFlatten{Mode: "unflatten", Fields: []string{"payload"}},
+			expected: map[string]interface{}{
+				"status":             "ok",
+				"payload":            `{"load":0.5,"slots":{"1":"x","2":"y"},"tags":["a","b"],"user":{"id":42,"name":"alice"}}`,
+				"other.value":        int64(1),
+				"payloadless.value":  int64(2),
+				"payload_not_nested": true,
+			},
+		},
+		{
+			name:   "objects",
+			plugin: &JSONFlatten{Mode: "unflatten", Fields: []string{"payload", "other"}, Arrays: "json"},
+			expected: map[string]interface{}{
+				"status":             "ok",
+				"payload":            `{"load":0.5,"slots":{"1":"x","2":"y"},"tags":{"0":"a","1":"b"},"user":{"id":42,"name":"alice"}}`,
+				"other":              `{"value":1}`,
+				"payloadless.value":  int64(2),
+				"payload_not_nested": true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			input := metric.New("events", map[string]string{}, fields, now)
+			expected := []telegraf.Metric{metric.New("events", map[string]string{}, tt.expected, now)}
+			actual := tt.plugin.Apply(input)
+			testutil.RequireMetricsEqual(t, expected, actual, testutil.SortMetrics())
+		})
+	}
+}
+
+func TestUnflattenConflict(t *testing.T) {
+	plugin := &JSONFlatten{Mode: "unflatten", Fields: []string{"payload"}, Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	input := metric.New("events", map[string]string{}, map[string]interface{}{"payload.a": int64(1), "payload.a.b": int64(2)}, now)
+	expected := []telegraf.Metric{input.Copy()}
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestRoundtrip(t *testing.T) {
+	payload := `{"list":[{"id":1},{"id":2}],"meta":{"source":"api","valid":true}}`
+
+	flatten := &JSONFlatten{Fields: []string{"payload"}, Log: testutil.Logger{}}
+	require.NoError(t, flatten.Init())
+	unflatten := &JSONFlatten{Mode: "unflatten", Fields: []string{"payload"}, Log: testutil.Logger{}}
+	require.NoError(t, unflatten.Init())
+
+	input := metric.New("events", map[string]string{}, map[string]interface{}{"payload": payload}, time.Now())
+	expected := []telegraf.Metric{input.Copy()}
+
+	actual := unflatten.Apply(flatten.Apply(input)...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *JSONFlatten
+		expected string
+	}{
+		{
+			name:     "mode",
+			plugin:   &JSONFlatten{Mode: "expand", Fields: []string{"payload"}},
+			expected: `invalid mode "expand"`,
+		},
+		{
+			name:     "arrays",
+			plugin:   &JSONFlatten{Arrays: "split", Fields: []string{"payload"}},
+			expected: `invalid arrays setting "split"`,
+		},
+		{
+			name:     "no fields",
+			plugin:   &JSONFlatten{},
+			expected: "no fields given",
+		},
+		{
+			name:     "depth",
+			plugin:   &JSONFlatten{Fields: []string{"payload"}, MaxDepth: -1},
+			expected: "max_depth must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
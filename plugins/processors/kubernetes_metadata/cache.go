@@ -0,0 +1,197 @@
+package kubernetes_metadata
+
+import (
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
+// podInfo holds the metadata of a pod added to the metrics
+type podInfo struct {
+	key          string
+	name         string
+	namespace    string
+	node         string
+	workloadKind string
+	workloadName string
+	labels       map[string]string
+	containerIDs []string
+}
+
+// podCache keeps the metadata of the pods known to the informer indexed by
+// the pod's namespace and name, the pod's name and the IDs of its containers
+type podCache struct {
+	labelFilter filter.Filter
+	log         telegraf.Logger
+
+	byKey       map[string]*podInfo
+	byName      map[string]map[string]*podInfo
+	byContainer map[string]*podInfo
+	sync.RWMutex
+}
+
+func newPodCache(labelFilter filter.Filter, log telegraf.Logger) *podCache {
+	return &podCache{
+		labelFilter: labelFilter,
+		log:         log,
+		byKey:       make(map[string]*podInfo),
+		byName:      make(map[string]map[string]*podInfo),
+		byContainer: make(map[string]*podInfo),
+	}
+}
+
+// handler returns the event handler updating the cache for the pod informer
+func (c *podCache) handler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.onUpdate(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.onUpdate(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err != nil {
+				c.log.Errorf("Getting key of deleted pod failed: %v", err)
+				return
+			}
+			c.Lock()
+			defer c.Unlock()
+			c.remove(key)
+		},
+	}
+}
+
+func (c *podCache) onUpdate(obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		c.log.Errorf("Received unexpected object of type %T", obj)
+		return
+	}
+	c.update(pod)
+}
+
+// update replaces the metadata of the given pod
+func (c *podCache) update(pod *corev1.Pod) {
+	info := c.newPodInfo(pod)
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.remove(info.key)
+	c.byKey[info.key] = info
+	if c.byName[info.name] == nil {
+		c.byName[info.name] = make(map[string]*podInfo)
+	}
+	c.byName[info.name][info.key] = info
+	for _, id := range info.containerIDs {
+		c.byContainer[id] = info
+	}
+}
+
+// remove deletes the pod with the given key, the lock must be held
+func (c *podCache) remove(key string) {
+	info, found := c.byKey[key]
+	if !found {
+		return
+	}
+	delete(c.byKey, key)
+	delete(c.byName[info.name], key)
+	if len(c.byName[info.name]) == 0 {
+		delete(c.byName, info.name)
+	}
+	for _, id := range info.containerIDs {
+		if c.byContainer[id] == info {
+			delete(c.byContainer, id)
+		}
+	}
+}
+
+// lookupPod returns the pod with the given name. Without a namespace, the
+// pod is only found if the name is unique across the namespaces.
+func (c *podCache) lookupPod(namespace, name string) *podInfo {
+	c.RLock()
+	defer c.RUnlock()
+
+	if namespace != "" {
+		return c.byKey[namespace+"/"+name]
+	}
+	if pods := c.byName[name]; len(pods) == 1 {
+		for _, info := range pods {
+			return info
+		}
+	}
+	return nil
+}
+
+// lookupContainer returns the pod running the container with the given ID
+func (c *podCache) lookupContainer(id string) *podInfo {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.byContainer[containerID(id)]
+}
+
+func (c *podCache) newPodInfo(pod *corev1.Pod) *podInfo {
+	info := &podInfo{
+		key:       pod.Namespace + "/" + pod.Name,
+		name:      pod.Name,
+		namespace: pod.Namespace,
+		node:      pod.Spec.NodeName,
+		labels:    make(map[string]string),
+	}
+	info.workloadKind, info.workloadName = workload(pod)
+
+	if c.labelFilter != nil {
+		for k, v := range pod.Labels {
+			if c.labelFilter.Match(k) {
+				info.labels["label_"+k] = v
+			}
+		}
+	}
+
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.ContainerID != "" {
+			info.containerIDs = append(info.containerIDs, containerID(status.ContainerID))
+		}
+	}
+
+	return info
+}
+
+// workload determines the kind and name of the workload controlling the pod.
+// Pods of deployments are controlled by a replica set named after the
+// deployment and the hash of the pod template, so the deployment is reported
+// instead.
+func workload(pod *corev1.Pod) (kind, name string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "", ""
+	}
+
+	if owner.Kind == "ReplicaSet" {
+		suffix := "-" + pod.Labels["pod-template-hash"]
+		if suffix != "-" && strings.HasSuffix(owner.Name, suffix) {
+			return "Deployment", strings.TrimSuffix(owner.Name, suffix)
+		}
+	}
+	return owner.Kind, owner.Name
+}
+
+// containerID strips the container runtime prefix, e.g. "containerd://",
+// from the given ID
+func containerID(id string) string {
+	if _, after, found := strings.Cut(id, "://"); found {
+		return after
+	}
+	return id
+}
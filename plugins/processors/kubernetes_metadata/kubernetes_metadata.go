@@ -0,0 +1,203 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package kubernetes_metadata
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type KubernetesMetadata struct {
+	Kubeconfig     string          `toml:"kubeconfig"`
+	NodeName       string          `toml:"node_name"`
+	Namespace      string          `toml:"namespace"`
+	PodTag         string          `toml:"pod_tag"`
+	NamespaceTag   string          `toml:"namespace_tag"`
+	ContainerIDTag string          `toml:"container_id_tag"`
+	Labels         []string        `toml:"labels"`
+	Overwrite      bool            `toml:"overwrite"`
+	SyncTimeout    config.Duration `toml:"sync_timeout"`
+	Log            telegraf.Logger `toml:"-"`
+
+	client  kubernetes.Interface
+	factory informers.SharedInformerFactory
+	pods    *podCache
+	cancel  context.CancelFunc
+}
+
+func (*KubernetesMetadata) SampleConfig() string {
+	return sampleConfig
+}
+
+func (k *KubernetesMetadata) Init() error {
+	if k.PodTag == "" && k.ContainerIDTag == "" {
+		return errors.New("pod_tag or container_id_tag required")
+	}
+	if k.SyncTimeout <= 0 {
+		return errors.New("sync_timeout must be positive")
+	}
+
+	var labelFilter filter.Filter
+	if len(k.Labels) > 0 {
+		f, err := filter.Compile(k.Labels)
+		if err != nil {
+			return fmt.Errorf("creating label filter failed: %w", err)
+		}
+		labelFilter = f
+	}
+	k.pods = newPodCache(labelFilter, k.Log)
+
+	return nil
+}
+
+func (k *KubernetesMetadata) Start(telegraf.Accumulator) error {
+	if k.client == nil {
+		restConfig, err := k.restConfig()
+		if err != nil {
+			return err
+		}
+		client, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return fmt.Errorf("creating client failed: %w", err)
+		}
+		k.client = client
+	}
+
+	// Only watch the pods of the given node, e.g. the node the processor is
+	// running on when deployed as DaemonSet, to keep the cache small
+	options := []informers.SharedInformerOption{informers.WithNamespace(k.Namespace)}
+	if k.NodeName != "" {
+		selector := fields.OneTermEqualSelector("spec.nodeName", k.NodeName).String()
+		options = append(options, informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = selector
+		}))
+	}
+	k.factory = informers.NewSharedInformerFactoryWithOptions(k.client, 0, options...)
+
+	informer := k.factory.Core().V1().Pods().Informer()
+	if _, err := informer.AddEventHandler(k.pods.handler()); err != nil {
+		return fmt.Errorf("adding event handler failed: %w", err)
+	}
+
+	var ctx context.Context
+	ctx, k.cancel = context.WithCancel(context.Background())
+	k.factory.Start(ctx.Done())
+
+	// Do not block the startup if the API is unavailable, metrics are
+	// passed through unchanged until the cache is synchronized
+	syncCtx, cancel := context.WithTimeout(ctx, time.Duration(k.SyncTimeout))
+	defer cancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), informer.HasSynced) {
+		k.Log.Warnf("Pod cache not synchronized within %s, metrics are not enriched until then", k.SyncTimeout)
+	}
+
+	return nil
+}
+
+func (k *KubernetesMetadata) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	if info := k.lookup(m); info != nil {
+		k.enrich(m, info)
+	}
+	acc.AddMetric(m)
+	return nil
+}
+
+func (k *KubernetesMetadata) Stop() {
+	if k.cancel != nil {
+		k.cancel()
+	}
+	if k.factory != nil {
+		k.factory.Shutdown()
+	}
+}
+
+// lookup finds the pod of the metric by its container ID or pod name
+func (k *KubernetesMetadata) lookup(m telegraf.Metric) *podInfo {
+	if k.ContainerIDTag != "" {
+		if id, found := m.GetTag(k.ContainerIDTag); found && id != "" {
+			if info := k.pods.lookupContainer(id); info != nil {
+				return info
+			}
+		}
+	}
+
+	if k.PodTag != "" {
+		if name, found := m.GetTag(k.PodTag); found && name != "" {
+			namespace, _ := m.GetTag(k.NamespaceTag)
+			return k.pods.lookupPod(namespace, name)
+		}
+	}
+
+	return nil
+}
+
+// enrich adds the metadata of the pod as tags
+func (k *KubernetesMetadata) enrich(m telegraf.Metric, info *podInfo) {
+	tags := map[string]string{
+		k.NamespaceTag:  info.namespace,
+		"node_name":     info.node,
+		"workload_kind": info.workloadKind,
+		"workload_name": info.workloadName,
+	}
+	if k.PodTag != "" {
+		tags[k.PodTag] = info.name
+	}
+	for key, value := range info.labels {
+		tags[key] = value
+	}
+
+	for key, value := range tags {
+		if key == "" || value == "" {
+			continue
+		}
+		if k.Overwrite || !m.HasTag(key) {
+			m.AddTag(key, value)
+		}
+	}
+}
+
+// restConfig uses the given kubeconfig or the in-cluster configuration
+func (k *KubernetesMetadata) restConfig() (*rest.Config, error) {
+	if k.Kubeconfig != "" {
+		restConfig, err := clientcmd.BuildConfigFromFlags("", k.Kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("loading kubeconfig failed: %w", err)
+		}
+		return restConfig, nil
+	}
+
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading in-cluster config failed: %w", err)
+	}
+	return restConfig, nil
+}
+
+func init() {
+	processors.AddStreaming("kubernetes_metadata", func() telegraf.StreamingProcessor {
+		return &KubernetesMetadata{
+			PodTag:         "pod",
+			NamespaceTag:   "namespace",
+			ContainerIDTag: "container_id",
+			SyncTimeout:    config.Duration(30 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package kubernetes_metadata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newPod(namespace, name, node string, owner *metav1.OwnerReference, labels map[string]string, containerIDs ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{NodeName: node},
+	}
+	if owner != nil {
+		controller := true
+		owner.Controller = &controller
+		pod.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	for _, id := range containerIDs {
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{ContainerID: id})
+	}
+	return pod
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &KubernetesMetadata{SyncTimeout: config.Duration(time.Second)}
+	require.ErrorContains(t, plugin.Init(), "pod_tag or container_id_tag required")
+
+	plugin = &KubernetesMetadata{PodTag: "pod"}
+	require.ErrorContains(t, plugin.Init(), "sync_timeout must be positive")
+}
+
+func TestWorkload(t *testing.T) {
+	tests := []struct {
+		name         string
+		pod          *corev1.Pod
+		expectedKind string
+		expectedName string
+	}{
+		{
+			name: "deployment",
+			pod: newPod("prod", "shop-7d9c6b5f4-x2kq9", "node-1",
+				&metav1.OwnerReference{Kind: "ReplicaSet", Name: "shop-7d9c6b5f4"},
+				map[string]string{"pod-template-hash": "7d9c6b5f4"},
+			),
+			expectedKind: "Deployment",
+			expectedName: "shop",
+		},
+		{
+			name: "replica set",
+			pod: newPod("prod", "shop-abcde", "node-1",
+				&metav1.OwnerReference{Kind: "ReplicaSet", Name: "shop"},
+				nil,
+			),
+			expectedKind: "ReplicaSet",
+			expectedName: "shop",
+		},
+		{
+			name:         "daemon set",
+			pod:          newPod("kube-system", "proxy-x2kq9", "node-1", &metav1.OwnerReference{Kind: "DaemonSet", Name: "proxy"}, nil),
+			expectedKind: "DaemonSet",
+			expectedName: "proxy",
+		},
+		{
+			name: "bare pod",
+			pod:  newPod("default", "debug", "node-1", nil, nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, name := workload(tt.pod)
+			require.Equal(t, tt.expectedKind, kind)
+			require.Equal(t, tt.expectedName, name)
+		})
+	}
+}
+
+func TestPodCache(t *testing.T) {
+	c := newPodCache(nil, testutil.Logger{})
+	c.update(newPod("prod", "web", "node-1", nil, nil, "containerd://abc"))
+	c.update(newPod("dev", "web", "node-2", nil, nil, "containerd://def"))
+	c.update(newPod("dev", "db", "node-2", nil, nil, "docker://123"))
+
+	require.Equal(t, "node-1", c.lookupPod("prod", "web").node)
+	require.Equal(t, "node-2", c.lookupPod("", "db").node)
+	require.Equal(t, "dev", c.lookupContainer("def").namespace)
+	require.Equal(t, "dev", c.lookupContainer("containerd://def").namespace)
+
+	// Ambiguous pod name without namespace
+	require.Nil(t, c.lookupPod("", "web"))
+
+	// Containers of restarted pods are replaced
+	c.update(newPod("dev", "db", "node-2", nil, nil, "docker://456"))
+	require.Nil(t, c.lookupContainer("123"))
+	require.NotNil(t, c.lookupContainer("456"))
+
+	c.Lock()
+	c.remove("dev/web")
+	c.Unlock()
+	require.Nil(t, c.lookupContainer("def"))
+	require.Equal(t, "prod", c.lookupPod("", "web").namespace)
+}
+
+func TestEnrich(t *testing.T) {
+	pods := []*corev1.Pod{
+		newPod("prod", "shop-7d9c6b5f4-x2kq9", "node-1",
+			&metav1.OwnerReference{Kind: "ReplicaSet", Name: "shop-7d9c6b5f4"},
+			map[string]string{"pod-template-hash": "7d9c6b5f4", "app": "shop", "team": "web"},
+			"containerd://3f5a9b",
+		),
+		newPod("prod", "other", "node-2", nil, nil),
+	}
+
+	plugin := &KubernetesMetadata{
+		NodeName:       "node-1",
+		PodTag:         "pod",
+		NamespaceTag:   "namespace",
+		ContainerIDTag: "container_id",
+		Labels:         []string{"app"},
+		SyncTimeout:    config.Duration(5 * time.Second),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.client = fake.NewClientset(pods[0], pods[1])
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("docker_container_cpu", map[string]string{"container_id": "3f5a9b"}, map[string]interface{}{"usage_percent": 12.5}, now),
+		metric.New("kubernetes_pod", map[string]string{"pod": "shop-7d9c6b5f4-x2kq9", "node_name": "custom"}, map[string]interface{}{"value": 1}, now),
+		metric.New("kubernetes_pod", map[string]string{"pod": "unknown"}, map[string]interface{}{"value": 2}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3}, now),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+
+	enriched := map[string]string{
+		"namespace":     "prod",
+		"pod":           "shop-7d9c6b5f4-x2kq9",
+		"node_name":     "node-1",
+		"workload_kind": "Deployment",
+		"workload_name": "shop",
+		"label_app":     "shop",
+	}
+	withTags := func(tags map[string]string) map[string]string {
+		for k, v := range enriched {
+			if _, found := tags[k]; !found {
+				tags[k] = v
+			}
+		}
+		return tags
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("docker_container_cpu", withTags(map[string]string{"container_id": "3f5a9b"}), map[string]interface{}{"usage_percent": 12.5}, now),
+		metric.New("kubernetes_pod", withTags(map[string]string{"node_name": "custom"}), map[string]interface{}{"value": 1}, now),
+		metric.New("kubernetes_pod", map[string]string{"pod": "unknown"}, map[string]interface{}{"value": 2}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
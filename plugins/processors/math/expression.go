@@ -0,0 +1,266 @@
+package math
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	errMissingField = errors.New("missing field")
+	errDivideByZero = errors.New("division by zero")
+)
+
+// lookupFunc returns the numerical value of the given field and a flag
+// indicating if the field exists and is numeric
+type lookupFunc func(name string) (float64, bool)
+
+// node is an element of the expression tree
+type node interface {
+	eval(lookup lookupFunc) (float64, error)
+}
+
+type number float64
+
+func (n number) eval(lookupFunc) (float64, error) {
+	return float64(n), nil
+}
+
+type variable string
+
+func (v variable) eval(lookup lookupFunc) (float64, error) {
+	value, found := lookup(string(v))
+	if !found {
+		return 0, fmt.Errorf("%w %q", errMissingField, string(v))
+	}
+	return value, nil
+}
+
+type negation struct {
+	operand node
+}
+
+func (n *negation) eval(lookup lookupFunc) (float64, error) {
+	v, err := n.operand.eval(lookup)
+	return -v, err
+}
+
+type operation struct {
+	operator byte
+	left     node
+	right    node
+}
+
+func (o *operation) eval(lookup lookupFunc) (float64, error) {
+	l, err := o.left.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	r, err := o.right.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+
+	switch o.operator {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	case '/':
+		if r == 0 {
+			return 0, errDivideByZero
+		}
+		return l / r, nil
+	case '%':
+		if r == 0 {
+			return 0, errDivideByZero
+		}
+		return math.Mod(l, r), nil
+	case '^':
+		return math.Pow(l, r), nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", o.operator)
+}
+
+// expression is a parsed assignment of the form "<destination> = <term>"
+type expression struct {
+	dest string
+	root node
+}
+
+func parseExpression(s string) (*expression, error) {
+	lhs, rhs, found := strings.Cut(s, "=")
+	if !found {
+		return nil, errors.New("missing assignment")
+	}
+	dest := strings.TrimSpace(lhs)
+	if strings.HasPrefix(dest, "[") && strings.HasSuffix(dest, "]") {
+		dest = dest[1 : len(dest)-1]
+	}
+	if dest == "" {
+		return nil, errors.New("missing destination field")
+	}
+
+	p := &parser{input: rhs}
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected character %q at position %d", p.input[p.pos], p.pos)
+	}
+
+	return &expression{dest: dest, root: root}, nil
+}
+
+// parser is a recursive-descent parser for arithmetic expressions using the
+// usual operator precedence. Field names can either be given as identifiers
+// or enclosed in square brackets if they contain special characters.
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = &operation{operator: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseProduct() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &operation{operator: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.peek() == '-' {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negation{operand: operand}, nil
+	}
+	return p.parsePower()
+}
+
+func (p *parser) parsePower() (node, error) {
+	base, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+
+	// Exponentiation is right-associative
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &operation{operator: '^', left: base, right: exponent}, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, errors.New("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		n, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis at position %d", p.pos)
+		}
+		p.pos++
+		return n, nil
+	case c == '[':
+		end := strings.IndexByte(p.input[p.pos:], ']')
+		if end < 0 {
+			return nil, fmt.Errorf("missing closing bracket at position %d", p.pos)
+		}
+		name := p.input[p.pos+1 : p.pos+end]
+		if name == "" {
+			return nil, fmt.Errorf("empty field name at position %d", p.pos)
+		}
+		p.pos += end + 1
+		return variable(name), nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.input) && strings.IndexByte("0123456789.eE", p.input[p.pos]) >= 0 {
+			// Allow signed exponents
+			if (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') && p.pos+1 < len(p.input) &&
+				(p.input[p.pos+1] == '+' || p.input[p.pos+1] == '-') {
+				p.pos++
+			}
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q: %w", p.input[start:p.pos], err)
+		}
+		return number(v), nil
+	case c == '_' || unicode.IsLetter(rune(c)):
+		start := p.pos
+		for p.pos < len(p.input) {
+			r := rune(p.input[p.pos])
+			if r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+			p.pos++
+		}
+		return variable(p.input[start:p.pos]), nil
+	}
+	return nil, fmt.Errorf("unexpected character %q at position %d", c, p.pos)
+}
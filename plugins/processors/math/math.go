@@ -0,0 +1,113 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package math
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Math struct {
+	Expressions  []string        `toml:"expressions"`
+	MissingField string          `toml:"missing_field"`
+	DivideByZero string          `toml:"divide_by_zero"`
+	Log          telegraf.Logger `toml:"-"`
+
+	expressions []*expression
+}
+
+func (*Math) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Math) Init() error {
+	if len(p.Expressions) == 0 {
+		return errors.New("no expressions specified")
+	}
+
+	switch p.MissingField {
+	case "":
+		p.MissingField = "skip"
+	case "skip", "zero":
+	default:
+		return fmt.Errorf("invalid missing_field setting %q", p.MissingField)
+	}
+
+	switch p.DivideByZero {
+	case "":
+		p.DivideByZero = "skip"
+	case "skip", "zero", "error":
+	default:
+		return fmt.Errorf("invalid divide_by_zero setting %q", p.DivideByZero)
+	}
+
+	p.expressions = make([]*expression, 0, len(p.Expressions))
+	for _, s := range p.Expressions {
+		expr, err := parseExpression(s)
+		if err != nil {
+			return fmt.Errorf("parsing expression %q failed: %w", s, err)
+		}
+		p.expressions = append(p.expressions, expr)
+	}
+
+	return nil
+}
+
+func (p *Math) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		lookup := func(name string) (float64, bool) {
+			raw, found := m.GetField(name)
+			if !found {
+				return 0, p.MissingField == "zero"
+			}
+			v, err := internal.ToFloat64(raw)
+			if err != nil {
+				return 0, p.MissingField == "zero"
+			}
+			return v, true
+		}
+
+		for i, expr := range p.expressions {
+			v, err := expr.root.eval(lookup)
+			switch {
+			case err == nil:
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					p.Log.Debugf("Expression %q resulted in %v for metric %q, skipping", p.Expressions[i], v, m.Name())
+					continue
+				}
+			case errors.Is(err, errDivideByZero):
+				switch p.DivideByZero {
+				case "zero":
+					v = 0
+				case "error":
+					p.Log.Errorf("Evaluating expression %q for metric %q failed: %v", p.Expressions[i], m.Name(), err)
+					continue
+				default:
+					continue
+				}
+			case errors.Is(err, errMissingField):
+				p.Log.Tracef("Skipping expression %q for metric %q: %v", p.Expressions[i], m.Name(), err)
+				continue
+			default:
+				p.Log.Errorf("Evaluating expression %q for metric %q failed: %v", p.Expressions[i], m.Name(), err)
+				continue
+			}
+			m.AddField(expr.dest, v)
+		}
+	}
+	return in
+}
+
+func init() {
+	processors.Add("math", func() telegraf.Processor {
+		return &Math{}
+	})
+}
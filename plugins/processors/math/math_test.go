@@ -0,0 +1,281 @@
+package math
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestParseExpression(t *testing.T) {
+	fields := map[string]float64{
+		"a":          6,
+		"b":          4,
+		"usage-idle": 75,
+	}
+	lookup := func(name string) (float64, bool) {
+		v, found := fields[name]
+		return v, found
+	}
+
+	tests := []struct {
+		expression string
+		dest       string
+		expected   float64
+	}{
+		{expression: "x = a + b * 2", dest: "x", expected: 14},
+		{expression: "x = (a + b) * 2", dest: "x", expected: 20},
+		{expression: "x = a - b - 1", dest: "x", expected: 1},
+		{expression: "x = a / b * 100", dest: "x", expected: 150},
+		{expression: "x = a % b", dest: "x", expected: 2},
+		{expression: "x = 2 ^ 3 ^ 2", dest: "x", expected: 512},
+		{expression: "x = -a ^ 2", dest: "x", expected: -36},
+		{expression: "x = - -a", dest: "x", expected: 6},
+		{expression: "x = 1.5e2 + .5", dest: "x", expected: 150.5},
+		{expression: "x = 1e-2 * 100", dest: "x", expected: 1},
+		{expression: "[busy-percent] = 100 - [usage-idle]", dest: "busy-percent", expected: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			expr, err := parseExpression(tt.expression)
+			require.NoError(t, err)
+			require.Equal(t, tt.dest, expr.dest)
+			actual, err := expr.root.eval(lookup)
+			require.NoError(t, err)
+			require.InDelta(t, tt.expected, actual, 1e-9)
+		})
+	}
+}
+
+func TestParseExpressionInvalid(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{expression: "a + b", expected: "missing assignment"},
+		{expression: " = a + b", expected: "missing destination field"},
+		{expression: "x = a +", expected: "unexpected end of expression"},
+		{expression: "x = (a + b", expected: "missing closing parenthesis"},
+		{expression: "x = [a + b", expected: "missing closing bracket"},
+		{expression: "x = a b", expected: "unexpected character 'b'"},
+		{expression: "x = a $ b", expected: "unexpected character '$'"},
+		{expression: "x = 1..2", expected: "invalid number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := parseExpression(tt.expression)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Math
+		expected string
+	}{
+		{
+			name:     "no expressions",
+			plugin:   &Math{},
+			expected: "no expressions specified",
+		},
+		{
+			name:     "invalid missing field setting",
+			plugin:   &Math{Expressions: []string{"x = a"}, MissingField: "fail"},
+			expected: `invalid missing_field setting "fail"`,
+		},
+		{
+			name:     "invalid divide by zero setting",
+			plugin:   &Math{Expressions: []string{"x = a"}, DivideByZero: "nan"},
+			expected: `invalid divide_by_zero setting "nan"`,
+		},
+		{
+			name:     "invalid expression",
+			plugin:   &Math{Expressions: []string{"x = a +"}},
+			expected: `parsing expression "x = a +" failed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name         string
+		expressions  []string
+		missingField string
+		divideByZero string
+		input        telegraf.Metric
+		expected     telegraf.Metric
+	}{
+		{
+			name:        "percentage",
+			expressions: []string{"result = used / total * 100"},
+			input: metric.New("mem",
+				map[string]string{},
+				map[string]interface{}{"used": int64(4096), "total": uint64(16384)},
+				time.Unix(0, 0),
+			),
+			expected: metric.New("mem",
+				map[string]string{},
+				map[string]interface{}{"used": int64(4096), "total": uint64(16384), "result": float64(25)},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:        "chained expressions",
+			expressions: []string{"sum = a + b", "avg = sum / 2"},
+			input: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": float64(3), "b": true},
+				time.Unix(0, 0),
+			),
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": float64(3), "b": true, "sum": float64(4), "avg": float64(2)},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:        "overwrite field",
+			expressions: []string{"value = value * 8"},
+			input: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"value": "2.5"},
+				time.Unix(0, 0),
+			),
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"value": float64(20)},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:        "missing field skipped",
+			expressions: []string{"result = a + b"},
+			input: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(1)},
+				time.Unix(0, 0),
+			),
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(1)},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:        "non-numeric field skipped",
+			expressions: []string{"result = a + b"},
+			input: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(1), "b": "foo"},
+				time.Unix(0, 0),
+			),
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(1), "b": "foo"},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:         "missing field as zero",
+			expressions:  []string{"result = a + b"},
+			missingField: "zero",
+			input: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(1)},
+				time.Unix(0, 0),
+			),
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(1), "result": float64(1)},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:        "divide by zero skipped",
+			expressions: []string{"result = a / b"},
+			input: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(1), "b": int64(0)},
+				time.Unix(0, 0),
+			),
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(1), "b": int64(0)},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:         "divide by zero as zero",
+			expressions:  []string{"result = a % b"},
+			divideByZero: "zero",
+			input: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(1), "b": int64(0)},
+				time.Unix(0, 0),
+			),
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(1), "b": int64(0), "result": float64(0)},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:         "divide by zero error",
+			expressions:  []string{"result = a / b"},
+			divideByZero: "error",
+			input: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(1), "b": int64(0)},
+				time.Unix(0, 0),
+			),
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(1), "b": int64(0)},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:        "not a number skipped",
+			expressions: []string{"result = a ^ 0.5"},
+			input: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(-4)},
+				time.Unix(0, 0),
+			),
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(-4)},
+				time.Unix(0, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Math{
+				Expressions:  tt.expressions,
+				MissingField: tt.missingField,
+				DivideByZero: tt.divideByZero,
+				Log:          testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			actual := plugin.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual)
+		})
+	}
+}
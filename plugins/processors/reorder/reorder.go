@@ -0,0 +1,197 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package reorder
+
+import (
+	_ "embed"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Idle series are kept for detecting late metrics for this multiple of the
+// maximum delay after their last metric arrived
+const seriesExpiry = 10
+
+type Reorder struct {
+	MaxDelay  config.Duration `toml:"max_delay"`
+	MaxBuffer int             `toml:"max_buffer"`
+	DropLate  bool            `toml:"drop_late"`
+	Log       telegraf.Logger `toml:"-"`
+
+	acc      telegraf.Accumulator
+	series   map[uint64]*series
+	buffered int
+
+	mu     sync.Mutex
+	cancel chan struct{}
+	wg     sync.WaitGroup
+}
+
+type series struct {
+	// Buffered metrics sorted by timestamp
+	entries []entry
+	// Timestamp of the latest metric released
+	released    time.Time
+	hasReleased bool
+	// Arrival time of the latest metric
+	seen time.Time
+}
+
+type entry struct {
+	metric  telegraf.Metric
+	arrival time.Time
+}
+
+func (*Reorder) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Reorder) Init() error {
+	if r.MaxDelay <= 0 {
+		return errors.New("max_delay must be positive")
+	}
+	if r.MaxBuffer <= 0 {
+		return errors.New("max_buffer must be positive")
+	}
+	return nil
+}
+
+func (r *Reorder) Start(acc telegraf.Accumulator) error {
+	r.acc = acc
+	r.series = make(map[uint64]*series)
+	r.buffered = 0
+
+	// Check for expired metrics frequently enough to keep the additional
+	// delay small compared to the configured maximum
+	interval := max(time.Duration(r.MaxDelay)/4, time.Millisecond)
+
+	r.cancel = make(chan struct{})
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.cancel:
+				return
+			case now := <-ticker.C:
+				r.mu.Lock()
+				r.release(now, false)
+				r.mu.Unlock()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (r *Reorder) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := m.HashID()
+	s, found := r.series[id]
+	if !found {
+		s = &series{}
+		r.series[id] = s
+	}
+	s.seen = now
+
+	// Metrics older than already released ones of the same series cannot be
+	// put in order anymore
+	if s.hasReleased && m.Time().Before(s.released) {
+		if r.DropLate {
+			r.Log.Debugf("Dropping late metric %q at %v", m.Name(), m.Time())
+			m.Drop()
+			return nil
+		}
+		r.acc.AddMetric(m)
+		return nil
+	}
+
+	// Insert the metric after all metrics with the same or earlier timestamp
+	// to keep the arrival order for equal timestamps
+	idx := sort.Search(len(s.entries), func(i int) bool {
+		return s.entries[i].metric.Time().After(m.Time())
+	})
+	s.entries = append(s.entries, entry{})
+	copy(s.entries[idx+1:], s.entries[idx:])
+	s.entries[idx] = entry{metric: m, arrival: now}
+	r.buffered++
+
+	if r.buffered >= r.MaxBuffer {
+		r.release(now, true)
+	}
+
+	return nil
+}
+
+func (r *Reorder) Stop() {
+	close(r.cancel)
+	r.wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.release(time.Now(), true)
+}
+
+// release passes on all metrics waiting for longer than the maximum delay
+// together with the earlier metrics of their series, or all buffered metrics
+// if requested. The released metrics are sorted by timestamp.
+func (r *Reorder) release(now time.Time, all bool) {
+	cutoff := now.Add(-time.Duration(r.MaxDelay))
+	expiry := now.Add(-seriesExpiry * time.Duration(r.MaxDelay))
+
+	var released []telegraf.Metric
+	for id, s := range r.series {
+		// Find the latest metric in timestamp order that has expired
+		n := len(s.entries)
+		if !all {
+			for n > 0 && s.entries[n-1].arrival.After(cutoff) {
+				n--
+			}
+		}
+
+		if n > 0 {
+			for _, e := range s.entries[:n] {
+				released = append(released, e.metric)
+			}
+			s.released = s.entries[n-1].metric.Time()
+			s.hasReleased = true
+			s.entries = append(s.entries[:0], s.entries[n:]...)
+			r.buffered -= n
+		}
+
+		if len(s.entries) == 0 && s.seen.Before(expiry) {
+			delete(r.series, id)
+		}
+	}
+
+	sort.SliceStable(released, func(i, j int) bool {
+		return released[i].Time().Before(released[j].Time())
+	})
+	for _, m := range released {
+		r.acc.AddMetric(m)
+	}
+}
+
+func init() {
+	processors.AddStreaming("reorder", func() telegraf.StreamingProcessor {
+		return &Reorder{
+			MaxDelay:  config.Duration(time.Second),
+			MaxBuffer: 10000,
+		}
+	})
+}
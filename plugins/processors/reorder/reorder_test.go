@@ -0,0 +1,209 @@
+package reorder
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &Reorder{MaxBuffer: 10}
+	require.ErrorContains(t, plugin.Init(), "max_delay must be positive")
+
+	plugin = &Reorder{MaxDelay: config.Duration(time.Second)}
+	require.ErrorContains(t, plugin.Init(), "max_buffer must be positive")
+}
+
+func TestReorder(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 3}, time.Unix(3, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2}, time.Unix(2, 0)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 5}, time.Unix(5, 0)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 4}, time.Unix(4, 0)),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2}, time.Unix(2, 0)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 3}, time.Unix(3, 0)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 4}, time.Unix(4, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 5}, time.Unix(5, 0)),
+	}
+
+	plugin := &Reorder{
+		MaxDelay:  config.Duration(time.Hour),
+		MaxBuffer: 100,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Stopping the plugin must release all buffered metrics
+	plugin.Stop()
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestMaxDelay(t *testing.T) {
+	plugin := &Reorder{
+		MaxDelay:  config.Duration(100 * time.Millisecond),
+		MaxBuffer: 100,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Add(metric.New("test", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(2, 0)), &acc))
+	require.NoError(t, plugin.Add(metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1, 0)), &acc))
+
+	require.Eventuallyf(t, func() bool {
+		return acc.NMetrics() >= 2
+	}, 3*time.Second, 10*time.Millisecond, "Expected 2 metrics found %d", acc.NMetrics())
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(2, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestMaxBuffer(t *testing.T) {
+	plugin := &Reorder{
+		MaxDelay:  config.Duration(time.Hour),
+		MaxBuffer: 3,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	for _, ts := range []int64{3, 1} {
+		require.NoError(t, plugin.Add(metric.New("test", map[string]string{}, map[string]interface{}{"value": ts}, time.Unix(ts, 0)), &acc))
+	}
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Exceeding the buffer releases all metrics
+	require.NoError(t, plugin.Add(metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(2)}, time.Unix(2, 0)), &acc))
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(1)}, time.Unix(1, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(2)}, time.Unix(2, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(3)}, time.Unix(3, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestLateMetrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		dropLate bool
+		expected []telegraf.Metric
+	}{
+		{
+			name: "pass",
+			expected: []telegraf.Metric{
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(2)}, time.Unix(2, 0)),
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(1)}, time.Unix(1, 0)),
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(3)}, time.Unix(3, 0)),
+			},
+		},
+		{
+			name:     "drop",
+			dropLate: true,
+			expected: []telegraf.Metric{
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(2)}, time.Unix(2, 0)),
+				metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(3)}, time.Unix(3, 0)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Reorder{
+				MaxDelay:  config.Duration(time.Hour),
+				MaxBuffer: 1,
+				DropLate:  tt.dropLate,
+				Log:       testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			for _, ts := range []int64{2, 1, 3} {
+				require.NoError(t, plugin.Add(metric.New("test", map[string]string{}, map[string]interface{}{"value": ts}, time.Unix(ts, 0)), &acc))
+			}
+			plugin.Stop()
+
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestTracking(t *testing.T) {
+	inputRaw := []telegraf.Metric{
+		metric.New("foo", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(2, 0)),
+		metric.New("bar", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(1, 0)),
+		metric.New("baz", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(3, 0)),
+	}
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &Reorder{
+		MaxDelay:  config.Duration(time.Hour),
+		MaxBuffer: 100,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Process expected metrics and compare with resulting metrics
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, inputRaw, actual, testutil.SortMetrics())
+
+	// Simulate output acknowledging delivery
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	// Check delivery
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(input))
+}
@@ -0,0 +1,239 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package sampling
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Sampling struct {
+	Rate      float64         `toml:"rate"`
+	Threshold float64         `toml:"threshold"`
+	Window    int             `toml:"window"`
+	Fields    []string        `toml:"fields"`
+	MaxGap    config.Duration `toml:"max_gap"`
+	Expiry    config.Duration `toml:"expiry"`
+	Log       telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+	alpha       float64
+	series      map[uint64]*series
+	cleaned     time.Time
+
+	// Source of random numbers in [0, 1), replaceable for testing
+	random func() float64
+}
+
+type series struct {
+	fields map[string]*baseline
+	// Timestamp of the latest passed metric
+	passed time.Time
+	// Arrival time of the latest metric
+	seen time.Time
+}
+
+// baseline holds the exponentially weighted moving average and variance of
+// the recent values of a field
+type baseline struct {
+	count    int
+	mean     float64
+	variance float64
+}
+
+func (*Sampling) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Sampling) Init() error {
+	if s.Rate < 0 || s.Rate > 1 {
+		return fmt.Errorf("rate %v must be between zero and one", s.Rate)
+	}
+	if s.Threshold <= 0 {
+		return errors.New("threshold must be positive")
+	}
+	if s.Window < 1 {
+		return errors.New("window must be at least one")
+	}
+	if s.MaxGap < 0 {
+		return errors.New("max_gap must not be negative")
+	}
+
+	if len(s.Fields) == 0 {
+		s.Fields = []string{"*"}
+	}
+	f, err := filter.Compile(s.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	s.fieldFilter = f
+
+	// Smoothing factor of an exponential moving average with the center of
+	// mass of a simple moving average over the window
+	s.alpha = 2 / (float64(s.Window) + 1)
+	s.series = make(map[uint64]*series)
+	s.cleaned = time.Now()
+	if s.random == nil {
+		s.random = rand.Float64 //nolint:gosec // G404: not security critical
+	}
+
+	return nil
+}
+
+func (s *Sampling) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+
+	out := in[:0]
+	for _, m := range in {
+		if s.keep(m, now) {
+			out = append(out, m)
+			continue
+		}
+		m.Drop()
+	}
+	s.cleanup(now)
+
+	return out
+}
+
+// keep updates the baseline of the metric's series and decides whether to
+// pass the metric based on the deviation of its values from the baseline
+func (s *Sampling) keep(m telegraf.Metric, now time.Time) bool {
+	id := m.HashID()
+	ser, found := s.series[id]
+	if !found {
+		ser = &series{fields: make(map[string]*baseline)}
+		s.series[id] = ser
+	}
+	ser.seen = now
+
+	// Use the largest deviation of all fields to decide on the metric, so
+	// an anomaly in any of the fields is kept
+	var numeric, warmup bool
+	var deviation float64
+	for _, field := range m.FieldList() {
+		if !s.fieldFilter.Match(field.Key) {
+			continue
+		}
+		v, ok := toFloat(field.Value)
+		if !ok {
+			continue
+		}
+		numeric = true
+
+		b, found := ser.fields[field.Key]
+		if !found {
+			b = &baseline{}
+			ser.fields[field.Key] = b
+		}
+		if b.count < s.Window {
+			warmup = true
+		}
+		deviation = max(deviation, b.deviation(v))
+		b.update(v, s.alpha)
+	}
+
+	var pass bool
+	switch {
+	case !numeric, warmup:
+		pass = true
+	case s.MaxGap > 0 && m.Time().Sub(ser.passed) >= time.Duration(s.MaxGap):
+		pass = true
+	default:
+		pass = s.random() < s.rate(deviation)
+	}
+
+	if pass && m.Time().After(ser.passed) {
+		ser.passed = m.Time()
+	}
+	return pass
+}
+
+// rate computes the sampling rate for the given deviation increasing
+// linearly from the base rate to one at the threshold
+func (s *Sampling) rate(deviation float64) float64 {
+	if deviation >= s.Threshold {
+		return 1
+	}
+	return s.Rate + (1-s.Rate)*deviation/s.Threshold
+}
+
+// cleanup removes the baselines of expired series
+func (s *Sampling) cleanup(now time.Time) {
+	// Avoid iterating over all series on every call
+	if s.Expiry <= 0 || now.Sub(s.cleaned) < time.Duration(s.Expiry) {
+		return
+	}
+	s.cleaned = now
+
+	for id, ser := range s.series {
+		if now.Sub(ser.seen) >= time.Duration(s.Expiry) {
+			delete(s.series, id)
+		}
+	}
+}
+
+// deviation returns the distance of the value to the mean of the baseline
+// in standard deviations
+func (b *baseline) deviation(v float64) float64 {
+	if b.count == 0 {
+		return 0
+	}
+	diff := math.Abs(v - b.mean)
+	if b.variance == 0 {
+		// Any change of a so far constant value is a deviation
+		if diff == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return diff / math.Sqrt(b.variance)
+}
+
+func (b *baseline) update(v, alpha float64) {
+	if b.count == 0 {
+		b.mean = v
+		b.count++
+		return
+	}
+
+	diff := v - b.mean
+	incr := alpha * diff
+	b.mean += incr
+	b.variance = (1 - alpha) * (b.variance + diff*incr)
+	b.count++
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func init() {
+	processors.Add("sampling", func() telegraf.Processor {
+		return &Sampling{
+			Rate:      0.1,
+			Threshold: 3,
+			Window:    20,
+			Expiry:    config.Duration(time.Hour),
+		}
+	})
+}
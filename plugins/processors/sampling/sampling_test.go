@@ -0,0 +1,238 @@
+package sampling
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Sampling
+		expected string
+	}{
+		{
+			name:     "rate too large",
+			plugin:   &Sampling{Rate: 1.5, Threshold: 3, Window: 20},
+			expected: "must be between zero and one",
+		},
+		{
+			name:     "negative rate",
+			plugin:   &Sampling{Rate: -0.1, Threshold: 3, Window: 20},
+			expected: "must be between zero and one",
+		},
+		{
+			name:     "zero threshold",
+			plugin:   &Sampling{Rate: 0.1, Window: 20},
+			expected: "threshold must be positive",
+		},
+		{
+			name:     "zero window",
+			plugin:   &Sampling{Rate: 0.1, Threshold: 3},
+			expected: "window must be at least one",
+		},
+		{
+			name:     "negative max gap",
+			plugin:   &Sampling{Rate: 0.1, Threshold: 3, Window: 20, MaxGap: config.Duration(-time.Second)},
+			expected: "max_gap must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestRate(t *testing.T) {
+	plugin := &Sampling{Rate: 0.1, Threshold: 3, Window: 20}
+	require.NoError(t, plugin.Init())
+
+	require.InDelta(t, 0.1, plugin.rate(0), 1e-9)
+	require.InDelta(t, 0.55, plugin.rate(1.5), 1e-9)
+	require.InDelta(t, 1.0, plugin.rate(3), 1e-9)
+	require.InDelta(t, 1.0, plugin.rate(10), 1e-9)
+}
+
+func TestSampling(t *testing.T) {
+	plugin := &Sampling{
+		Rate:      0.1,
+		Threshold: 3,
+		Window:    5,
+		random:    func() float64 { return 0.5 },
+	}
+	require.NoError(t, plugin.Init())
+
+	// Pass all metrics of the warm-up
+	for i := range 5 {
+		m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 10.0}, time.Unix(int64(i), 0))
+		require.Len(t, plugin.Apply(m), 1, "metric %d", i)
+	}
+
+	// Thin out steady-state
+	for i := 5; i < 20; i++ {
+		m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 10.0}, time.Unix(int64(i), 0))
+		require.Empty(t, plugin.Apply(m), "metric %d", i)
+	}
+
+	// Keep the anomaly
+	m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 100.0}, time.Unix(20, 0))
+	require.Len(t, plugin.Apply(m), 1)
+
+	// Other series have their own baseline
+	m = metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 10.0}, time.Unix(21, 0))
+	require.Len(t, plugin.Apply(m), 1)
+}
+
+func TestSamplingDeviation(t *testing.T) {
+	plugin := &Sampling{
+		Rate:      0.1,
+		Threshold: 3,
+		Window:    4,
+		random:    func() float64 { return 0.5 },
+	}
+	require.NoError(t, plugin.Init())
+
+	// Establish a baseline with some variance
+	for i, v := range []float64{9, 11, 9, 11, 9, 11} {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": v}, time.Unix(int64(i), 0))
+		plugin.Apply(m)
+	}
+
+	// Values within the usual fluctuation are sampled at a low rate
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 10.0}, time.Unix(10, 0))
+	require.Empty(t, plugin.Apply(m))
+
+	// Values far off the baseline are kept
+	m = metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 30.0}, time.Unix(11, 0))
+	require.Len(t, plugin.Apply(m), 1)
+}
+
+func TestFields(t *testing.T) {
+	plugin := &Sampling{
+		Rate:      0,
+		Threshold: 3,
+		Window:    1,
+		Fields:    []string{"value"},
+		random:    func() float64 { return 0.5 },
+	}
+	require.NoError(t, plugin.Init())
+
+	fields := map[string]interface{}{"value": 1, "other": 1}
+	m := metric.New("cpu", map[string]string{}, fields, time.Unix(0, 0))
+	require.Len(t, plugin.Apply(m), 1)
+
+	// Changes of fields not selected do not count as deviation
+	fields = map[string]interface{}{"value": 1, "other": 100}
+	m = metric.New("cpu", map[string]string{}, fields, time.Unix(1, 0))
+	require.Empty(t, plugin.Apply(m))
+
+	// Metrics without numeric fields are always passed
+	m = metric.New("cpu", map[string]string{}, map[string]interface{}{"value": "idle"}, time.Unix(2, 0))
+	require.Len(t, plugin.Apply(m), 1)
+}
+
+func TestMaxGap(t *testing.T) {
+	plugin := &Sampling{
+		Rate:      0,
+		Threshold: 3,
+		Window:    1,
+		MaxGap:    config.Duration(5 * time.Second),
+		random:    func() float64 { return 0.5 },
+	}
+	require.NoError(t, plugin.Init())
+
+	var passed []int
+	for i := range 12 {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(int64(i), 0))
+		if len(plugin.Apply(m)) > 0 {
+			passed = append(passed, i)
+		}
+	}
+	require.Equal(t, []int{0, 5, 10}, passed)
+}
+
+func TestExpiry(t *testing.T) {
+	plugin := &Sampling{
+		Rate:      0,
+		Threshold: 3,
+		Window:    1,
+		Expiry:    config.Duration(time.Minute),
+		random:    func() float64 { return 0.5 },
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.Len(t, plugin.Apply(m), 1)
+	require.Len(t, plugin.series, 1)
+
+	// Age the series and the cleanup
+	for _, s := range plugin.series {
+		s.seen = s.seen.Add(-2 * time.Minute)
+	}
+	plugin.cleaned = plugin.cleaned.Add(-2 * time.Minute)
+
+	m = metric.New("mem", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1, 0))
+	require.Len(t, plugin.Apply(m), 1)
+	require.Len(t, plugin.series, 1)
+
+	// The expired series starts over with a new baseline
+	m = metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(2, 0))
+	require.Len(t, plugin.Apply(m), 1)
+}
+
+func TestTracking(t *testing.T) {
+	now := time.Now()
+
+	inputRaw := make([]telegraf.Metric, 0, 10)
+	for i := range 10 {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, now.Add(time.Duration(i)*time.Second))
+		inputRaw = append(inputRaw, m)
+	}
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &Sampling{
+		Rate:      0,
+		Threshold: 3,
+		Window:    2,
+		random:    func() float64 { return 0.5 },
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, inputRaw[:2], actual)
+
+	// Simulate output acknowledging delivery
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	// Check delivery
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(input))
+}
@@ -5,6 +5,8 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/filter"
@@ -21,12 +23,14 @@ type Split struct {
 }
 
 type template struct {
-	Name   string   `toml:"name"`
-	Tags   []string `toml:"tags"`
-	Fields []string `toml:"fields"`
+	Name         string   `toml:"name"`
+	Tags         []string `toml:"tags"`
+	Fields       []string `toml:"fields"`
+	FieldPattern string   `toml:"field_pattern"`
 
 	fieldFilters filter.Filter
 	tagFilters   filter.Filter
+	pattern      *regexp.Regexp
 }
 
 func (*Split) SampleConfig() string {
@@ -43,14 +47,24 @@ func (s *Split) Init() error {
 			return errors.New("metric name cannot be empty")
 		}
 
-		if len(template.Fields) == 0 {
-			return errors.New("at least one field is required for a valid metric")
+		if len(template.Fields) == 0 && template.FieldPattern == "" {
+			return errors.New("at least one field or a field pattern is required for a valid metric")
 		}
-		f, err := filter.Compile(template.Fields)
-		if err != nil {
-			return fmt.Errorf("failed to create new field filter: %w", err)
+		if len(template.Fields) != 0 {
+			f, err := filter.Compile(template.Fields)
+			if err != nil {
+				return fmt.Errorf("failed to create new field filter: %w", err)
+			}
+			s.Templates[index].fieldFilters = f
+		}
+
+		if template.FieldPattern != "" {
+			re, err := regexp.Compile(template.FieldPattern)
+			if err != nil {
+				return fmt.Errorf("failed to compile field pattern: %w", err)
+			}
+			s.Templates[index].pattern = re
 		}
-		s.Templates[index].fieldFilters = f
 
 		if len(template.Tags) != 0 {
 			f, err := filter.Compile(template.Tags)
@@ -75,6 +89,11 @@ func (s *Split) Apply(in ...telegraf.Metric) []telegraf.Metric {
 		}
 
 		for _, template := range s.Templates {
+			if template.pattern != nil {
+				newMetrics = append(newMetrics, template.splitByPattern(point)...)
+				continue
+			}
+
 			fields := make(map[string]any, len(point.FieldList()))
 			for _, field := range point.FieldList() {
 				if template.fieldFilters.Match(field.Key) {
@@ -82,21 +101,12 @@ func (s *Split) Apply(in ...telegraf.Metric) []telegraf.Metric {
 				}
 			}
 
-			tags := make(map[string]string, len(point.TagList()))
-			if len(template.Tags) != 0 {
-				for _, tag := range point.TagList() {
-					if template.tagFilters.Match(tag.Key) {
-						tags[tag.Key] = tag.Value
-					}
-				}
-			}
-
 			// metric with no fields should be skipped
 			if len(fields) == 0 {
 				continue
 			}
 
-			m := metric.New(template.Name, tags, fields, point.Time())
+			m := metric.New(template.Name, template.tags(point), fields, point.Time())
 			newMetrics = append(newMetrics, m)
 		}
 	}
@@ -104,6 +114,71 @@ func (s *Split) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	return newMetrics
 }
 
+// tags returns the tags of the metric selected by the template
+func (t *template) tags(point telegraf.Metric) map[string]string {
+	tags := make(map[string]string, len(point.TagList()))
+	if len(t.Tags) != 0 {
+		for _, tag := range point.TagList() {
+			if t.tagFilters.Match(tag.Key) {
+				tags[tag.Key] = tag.Value
+			}
+		}
+	}
+	return tags
+}
+
+// splitByPattern creates one metric for each distinct combination of tag
+// values captured by the named groups of the field pattern. The group named
+// "field" determines the name of the field in the new metric, all other named
+// groups are added as tags. Fields not matching the pattern are skipped.
+func (t *template) splitByPattern(point telegraf.Metric) []telegraf.Metric {
+	names := t.pattern.SubexpNames()
+
+	var result []telegraf.Metric
+	lookup := make(map[string]telegraf.Metric)
+	for _, field := range point.FieldList() {
+		if t.fieldFilters != nil && !t.fieldFilters.Match(field.Key) {
+			continue
+		}
+		groups := t.pattern.FindStringSubmatch(field.Key)
+		if groups == nil {
+			continue
+		}
+
+		// Collect the captured values and identify the metric they belong to
+		key := "value"
+		captured := make(map[string]string, len(names))
+		var id strings.Builder
+		for i, name := range names {
+			switch name {
+			case "":
+				// Skip the whole match and unnamed groups
+			case "field":
+				if groups[i] != "" {
+					key = groups[i]
+				}
+			default:
+				captured[name] = groups[i]
+				id.WriteString(groups[i])
+				id.WriteByte(0)
+			}
+		}
+
+		m, found := lookup[id.String()]
+		if !found {
+			m = metric.New(t.Name, t.tags(point), nil, point.Time())
+			for k, v := range captured {
+				m.AddTag(k, v)
+			}
+			lookup[id.String()] = m
+			result = append(result, m)
+		}
+		m.AddField(key, field.Value)
+	}
+
+	return result
+}
+
 func init() {
 	processors.Add("split", func() telegraf.Processor {
 		return &Split{}
@@ -0,0 +1,141 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package tag_join
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TagJoin struct {
+	SourceMeasurements []string        `toml:"source_measurements"`
+	JoinKey            string          `toml:"join_key"`
+	Tags               []string        `toml:"tags"`
+	TTL                config.Duration `toml:"ttl"`
+	Overwrite          bool            `toml:"overwrite"`
+
+	sourceFilter filter.Filter
+	tagFilter    filter.Filter
+	cache        map[string]*entry
+	nextCleanup  time.Time
+	now          func() time.Time
+}
+
+// entry holds the tags learned for a join key value
+type entry struct {
+	tags    map[string]string
+	expires time.Time
+}
+
+func (*TagJoin) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *TagJoin) Init() error {
+	if len(t.SourceMeasurements) == 0 {
+		return errors.New("no source measurements given")
+	}
+	if t.JoinKey == "" {
+		return errors.New("join key required")
+	}
+	if len(t.Tags) == 0 {
+		return errors.New("no tags given")
+	}
+	if t.TTL <= 0 {
+		return errors.New("ttl must be positive")
+	}
+
+	var err error
+	t.sourceFilter, err = filter.Compile(t.SourceMeasurements)
+	if err != nil {
+		return fmt.Errorf("creating source filter failed: %w", err)
+	}
+	t.tagFilter, err = filter.Compile(t.Tags)
+	if err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+
+	t.cache = make(map[string]*entry)
+	if t.now == nil {
+		t.now = time.Now
+	}
+
+	return nil
+}
+
+func (t *TagJoin) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := t.now()
+	t.cleanup(now)
+
+	for _, m := range in {
+		key, found := m.GetTag(t.JoinKey)
+		if !found {
+			continue
+		}
+
+		if t.sourceFilter.Match(m.Name()) {
+			t.learn(m, key, now)
+			continue
+		}
+
+		e, found := t.cache[key]
+		if !found || now.After(e.expires) {
+			continue
+		}
+		for k, v := range e.tags {
+			if t.Overwrite || !m.HasTag(k) {
+				m.AddTag(k, v)
+			}
+		}
+	}
+
+	return in
+}
+
+// learn stores the tags of the given source metric for the join key value
+func (t *TagJoin) learn(m telegraf.Metric, key string, now time.Time) {
+	tags := make(map[string]string)
+	for _, tag := range m.TagList() {
+		if tag.Key != t.JoinKey && t.tagFilter.Match(tag.Key) {
+			tags[tag.Key] = tag.Value
+		}
+	}
+
+	e, found := t.cache[key]
+	if !found {
+		e = &entry{}
+		t.cache[key] = e
+	}
+	e.tags = tags
+	e.expires = now.Add(time.Duration(t.TTL))
+}
+
+// cleanup removes expired entries at most once per TTL
+func (t *TagJoin) cleanup(now time.Time) {
+	if now.Before(t.nextCleanup) {
+		return
+	}
+	for key, e := range t.cache {
+		if now.After(e.expires) {
+			delete(t.cache, key)
+		}
+	}
+	t.nextCleanup = now.Add(time.Duration(t.TTL))
+}
+
+func init() {
+	processors.Add("tag_join", func() telegraf.Processor {
+		return &TagJoin{
+			TTL: config.Duration(10 * time.Minute),
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package tag_join
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *TagJoin
+		expected string
+	}{
+		{
+			name:     "no source",
+			plugin:   &TagJoin{JoinKey: "id", Tags: []string{"*"}, TTL: config.Duration(time.Minute)},
+			expected: "no source measurements given",
+		},
+		{
+			name:     "no join key",
+			plugin:   &TagJoin{SourceMeasurements: []string{"info"}, Tags: []string{"*"}, TTL: config.Duration(time.Minute)},
+			expected: "join key required",
+		},
+		{
+			name:     "no tags",
+			plugin:   &TagJoin{SourceMeasurements: []string{"info"}, JoinKey: "id", TTL: config.Duration(time.Minute)},
+			expected: "no tags given",
+		},
+		{
+			name:     "no ttl",
+			plugin:   &TagJoin{SourceMeasurements: []string{"info"}, JoinKey: "id", Tags: []string{"*"}},
+			expected: "ttl must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	plugin := &TagJoin{
+		SourceMeasurements: []string{"docker_container_info"},
+		JoinKey:            "container_id",
+		Tags:               []string{"image", "version"},
+		TTL:                config.Duration(time.Minute),
+		now:                func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		// Processed before the source metric so not enriched
+		metric.New("docker_container_mem", map[string]string{"container_id": "a"}, map[string]interface{}{"usage": 1}, now),
+		metric.New(
+			"docker_container_info",
+			map[string]string{"container_id": "a", "image": "nginx", "version": "1.25", "host": "h1"},
+			map[string]interface{}{"running": true},
+			now,
+		),
+		metric.New("docker_container_cpu", map[string]string{"container_id": "a"}, map[string]interface{}{"usage": 2}, now),
+		metric.New("docker_container_cpu", map[string]string{"container_id": "b"}, map[string]interface{}{"usage": 3}, now),
+		// Existing tags are kept
+		metric.New("docker_container_net", map[string]string{"container_id": "a", "version": "x"}, map[string]interface{}{"rx": 4}, now),
+	}
+	expected := []telegraf.Metric{
+		metric.New("docker_container_mem", map[string]string{"container_id": "a"}, map[string]interface{}{"usage": 1}, now),
+		metric.New(
+			"docker_container_info",
+			map[string]string{"container_id": "a", "image": "nginx", "version": "1.25", "host": "h1"},
+			map[string]interface{}{"running": true},
+			now,
+		),
+		metric.New(
+			"docker_container_cpu",
+			map[string]string{"container_id": "a", "image": "nginx", "version": "1.25"},
+			map[string]interface{}{"usage": 2},
+			now,
+		),
+		metric.New("docker_container_cpu", map[string]string{"container_id": "b"}, map[string]interface{}{"usage": 3}, now),
+		metric.New(
+			"docker_container_net",
+			map[string]string{"container_id": "a", "image": "nginx", "version": "x"},
+			map[string]interface{}{"rx": 4},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+
+	// Learned tags expire after the TTL
+	now = now.Add(2 * time.Minute)
+	m := metric.New("docker_container_cpu", map[string]string{"container_id": "a"}, map[string]interface{}{"usage": 2}, now)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{m.Copy()}, plugin.Apply(m))
+	require.Empty(t, plugin.cache)
+}
+
+func TestApplyOverwrite(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	plugin := &TagJoin{
+		SourceMeasurements: []string{"info"},
+		JoinKey:            "id",
+		Tags:               []string{"*"},
+		TTL:                config.Duration(time.Minute),
+		Overwrite:          true,
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("info", map[string]string{"id": "1", "owner": "team-a"}, map[string]interface{}{"value": 1}, now),
+		metric.New("usage", map[string]string{"id": "1", "owner": "unknown"}, map[string]interface{}{"value": 2}, now),
+	}
+	expected := []telegraf.Metric{
+		metric.New("info", map[string]string{"id": "1", "owner": "team-a"}, map[string]interface{}{"value": 1}, now),
+		metric.New("usage", map[string]string{"id": "1", "owner": "team-a"}, map[string]interface{}{"value": 2}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
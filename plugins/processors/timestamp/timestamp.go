@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/processors"
 )
@@ -16,14 +17,23 @@ import (
 var sampleConfig string
 
 type Timestamp struct {
-	Field               string `toml:"field"`
-	SourceFormat        string `toml:"source_timestamp_format"`
-	SourceTimezone      string `toml:"source_timestamp_timezone"`
-	DestinationFormat   string `toml:"destination_timestamp_format"`
-	DestinationTimezone string `toml:"destination_timestamp_timezone"`
-
+	Field               string          `toml:"field"`
+	SourceFormat        string          `toml:"source_timestamp_format"`
+	SourceFormats       []string        `toml:"source_timestamp_formats"`
+	SourceTimezone      string          `toml:"source_timestamp_timezone"`
+	Destination         string          `toml:"destination"`
+	DestinationFormat   string          `toml:"destination_timestamp_format"`
+	DestinationTimezone string          `toml:"destination_timestamp_timezone"`
+	MetricTimezone      string          `toml:"metric_timezone"`
+	Offset              config.Duration `toml:"offset"`
+	MaxFuture           config.Duration `toml:"max_future"`
+	MaxPast             config.Duration `toml:"max_past"`
+
+	formats             []string
 	sourceLocation      *time.Location
 	destinationLocation *time.Location
+	metricLocation      *time.Location
+	now                 func() time.Time
 }
 
 func (*Timestamp) SampleConfig() string {
@@ -31,26 +41,64 @@ func (*Timestamp) SampleConfig() string {
 }
 
 func (t *Timestamp) Init() error {
-	switch t.SourceFormat {
-	case "":
-		return errors.New("source_timestamp_format is required")
-	case "unix", "unix_ms", "unix_us", "unix_ns":
-	default:
-		if time.Now().Format(t.SourceFormat) == t.SourceFormat {
-			return fmt.Errorf("invalid timestamp format %q", t.SourceFormat)
+	if t.Field != "" {
+		if err := t.initField(); err != nil {
+			return err
 		}
+	} else if t.MetricTimezone == "" && t.Offset == 0 && t.MaxFuture == 0 && t.MaxPast == 0 {
+		return errors.New("either field or a metric timestamp operation is required")
 	}
 
-	switch t.DestinationFormat {
-	case "":
+	if t.MetricTimezone != "" {
+		var err error
+		t.metricLocation, err = time.LoadLocation(t.MetricTimezone)
+		if err != nil {
+			return fmt.Errorf("invalid metric_timezone %q: %w", t.MetricTimezone, err)
+		}
+	}
+
+	if t.MaxFuture < 0 {
+		return errors.New("max_future must not be negative")
+	}
+	if t.MaxPast < 0 {
+		return errors.New("max_past must not be negative")
+	}
+
+	if t.now == nil {
+		t.now = time.Now
+	}
+
+	return nil
+}
+
+func (t *Timestamp) initField() error {
+	if t.SourceFormat != "" {
+		t.formats = append(t.formats, t.SourceFormat)
+	}
+	t.formats = append(t.formats, t.SourceFormats...)
+	if len(t.formats) == 0 {
 		return errors.New("source_timestamp_format is required")
-	case "unix", "unix_ms", "unix_us", "unix_ns":
-	default:
-		if time.Now().Format(t.DestinationFormat) == t.DestinationFormat {
-			return fmt.Errorf("invalid timestamp format %q", t.DestinationFormat)
+	}
+	for _, format := range t.formats {
+		if err := checkFormat(format); err != nil {
+			return err
 		}
 	}
 
+	switch t.Destination {
+	case "", "field":
+		t.Destination = "field"
+		if t.DestinationFormat == "" {
+			return errors.New("destination_timestamp_format is required")
+		}
+		if err := checkFormat(t.DestinationFormat); err != nil {
+			return err
+		}
+	case "metric":
+	default:
+		return fmt.Errorf("invalid destination %q", t.Destination)
+	}
+
 	if t.SourceTimezone == "" {
 		t.SourceTimezone = "UTC"
 	}
@@ -67,32 +115,50 @@ func (t *Timestamp) Init() error {
 	}
 	t.destinationLocation, err = time.LoadLocation(t.DestinationTimezone)
 	if err != nil {
-		return fmt.Errorf("invalid source_timestamp_timezone %q: %w", t.DestinationTimezone, err)
+		return fmt.Errorf("invalid destination_timestamp_timezone %q: %w", t.DestinationTimezone, err)
 	}
 
 	return nil
 }
 
+func checkFormat(format string) error {
+	switch format {
+	case "unix", "unix_ms", "unix_us", "unix_ns":
+	default:
+		if time.Now().Format(format) == format {
+			return fmt.Errorf("invalid timestamp format %q", format)
+		}
+	}
+	return nil
+}
+
 func (t *Timestamp) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	for _, point := range in {
-		if field, ok := point.GetField(t.Field); ok {
-			timestamp, err := internal.ParseTimestamp(t.SourceFormat, field, t.sourceLocation)
-			if err != nil {
-				continue
-			}
+		if t.Field != "" {
+			t.convertField(point)
+		}
 
-			switch t.DestinationFormat {
-			case "unix":
-				point.AddField(t.Field, timestamp.Unix())
-			case "unix_ms":
-				point.AddField(t.Field, timestamp.UnixNano()/1000000)
-			case "unix_us":
-				point.AddField(t.Field, timestamp.UnixNano()/1000)
-			case "unix_ns":
-				point.AddField(t.Field, timestamp.UnixNano())
-			default:
-				inLocation := timestamp.In(t.destinationLocation)
-				point.AddField(t.Field, inLocation.Format(t.DestinationFormat))
+		if t.metricLocation != nil {
+			// Interpret the wall-clock of the timestamp in the given timezone
+			ts := point.Time().UTC()
+			point.SetTime(time.Date(
+				ts.Year(), ts.Month(), ts.Day(),
+				ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(),
+				t.metricLocation,
+			))
+		}
+
+		if t.Offset != 0 {
+			point.SetTime(point.Time().Add(time.Duration(t.Offset)))
+		}
+
+		if t.MaxFuture > 0 || t.MaxPast > 0 {
+			now := t.now()
+			if limit := now.Add(time.Duration(t.MaxFuture)); t.MaxFuture > 0 && point.Time().After(limit) {
+				point.SetTime(limit)
+			}
+			if limit := now.Add(-time.Duration(t.MaxPast)); t.MaxPast > 0 && point.Time().Before(limit) {
+				point.SetTime(limit)
 			}
 		}
 	}
@@ -100,6 +166,46 @@ func (t *Timestamp) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	return in
 }
 
+func (t *Timestamp) convertField(point telegraf.Metric) {
+	field, ok := point.GetField(t.Field)
+	if !ok {
+		return
+	}
+
+	// Use the first matching format
+	var timestamp time.Time
+	var err error
+	for _, format := range t.formats {
+		timestamp, err = internal.ParseTimestamp(format, field, t.sourceLocation)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	if t.Destination == "metric" {
+		point.SetTime(timestamp)
+		point.RemoveField(t.Field)
+		return
+	}
+
+	switch t.DestinationFormat {
+	case "unix":
+		point.AddField(t.Field, timestamp.Unix())
+	case "unix_ms":
+		point.AddField(t.Field, timestamp.UnixNano()/1000000)
+	case "unix_us":
+		point.AddField(t.Field, timestamp.UnixNano()/1000)
+	case "unix_ns":
+		point.AddField(t.Field, timestamp.UnixNano())
+	default:
+		inLocation := timestamp.In(t.destinationLocation)
+		point.AddField(t.Field, inLocation.Format(t.DestinationFormat))
+	}
+}
+
 func init() {
 	processors.Add("timestamp", func() telegraf.Processor {
 		return &Timestamp{}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -163,3 +164,183 @@ func TestCases(t *testing.T) {
 		})
 	}
 }
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name      string
+		timestamp Timestamp
+		expected  string
+	}{
+		{
+			name:     "no operation",
+			expected: "either field or a metric timestamp operation is required",
+		},
+		{
+			name: "missing source format",
+			timestamp: Timestamp{
+				Field:             "timestamp",
+				DestinationFormat: "unix",
+			},
+			expected: "source_timestamp_format is required",
+		},
+		{
+			name: "invalid candidate format",
+			timestamp: Timestamp{
+				Field:             "timestamp",
+				SourceFormat:      "unix",
+				SourceFormats:     []string{"foo"},
+				DestinationFormat: "unix",
+			},
+			expected: `invalid timestamp format "foo"`,
+		},
+		{
+			name: "missing destination format",
+			timestamp: Timestamp{
+				Field:        "timestamp",
+				SourceFormat: "unix",
+			},
+			expected: "destination_timestamp_format is required",
+		},
+		{
+			name: "invalid destination",
+			timestamp: Timestamp{
+				Field:        "timestamp",
+				SourceFormat: "unix",
+				Destination:  "tag",
+			},
+			expected: `invalid destination "tag"`,
+		},
+		{
+			name:      "invalid metric timezone",
+			timestamp: Timestamp{MetricTimezone: "Mars/Olympus_Mons"},
+			expected:  `invalid metric_timezone "Mars/Olympus_Mons"`,
+		},
+		{
+			name:      "negative max future",
+			timestamp: Timestamp{MaxFuture: config.Duration(-time.Minute)},
+			expected:  "max_future must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := tt.timestamp
+			require.ErrorContains(t, processor.Init(), tt.expected)
+		})
+	}
+}
+
+func TestMetricTimestamp(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		timestamp Timestamp
+		input     telegraf.Metric
+		expected  telegraf.Metric
+	}{
+		{
+			name: "field to metric with candidate formats",
+			timestamp: Timestamp{
+				Field:         "timestamp",
+				SourceFormat:  "2006-01-02T15:04:05Z07:00",
+				SourceFormats: []string{"02/Jan/2006:15:04:05 -0700", "unix"},
+				Destination:   "metric",
+			},
+			input: metric.New(
+				"test",
+				map[string]string{},
+				map[string]any{"value": int64(42), "timestamp": "04/Mar/2024:10:10:32 +0000"},
+				time.Unix(0, 0),
+			),
+			expected: metric.New(
+				"test",
+				map[string]string{},
+				map[string]any{"value": int64(42)},
+				time.Unix(1709547032, 0),
+			),
+		},
+		{
+			name: "field to metric with last candidate format",
+			timestamp: Timestamp{
+				Field:         "timestamp",
+				SourceFormat:  "2006-01-02T15:04:05Z07:00",
+				SourceFormats: []string{"02/Jan/2006:15:04:05 -0700", "unix"},
+				Destination:   "metric",
+			},
+			input: metric.New(
+				"test",
+				map[string]string{},
+				map[string]any{"value": int64(42), "timestamp": int64(1709547032)},
+				time.Unix(0, 0),
+			),
+			expected: metric.New(
+				"test",
+				map[string]string{},
+				map[string]any{"value": int64(42)},
+				time.Unix(1709547032, 0),
+			),
+		},
+		{
+			name: "field not matching any format",
+			timestamp: Timestamp{
+				Field:        "timestamp",
+				SourceFormat: "2006-01-02T15:04:05Z07:00",
+				Destination:  "metric",
+			},
+			input: metric.New(
+				"test",
+				map[string]string{},
+				map[string]any{"timestamp": "foo"},
+				time.Unix(0, 0),
+			),
+			expected: metric.New(
+				"test",
+				map[string]string{},
+				map[string]any{"timestamp": "foo"},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:      "timezone",
+			timestamp: Timestamp{MetricTimezone: "Europe/Berlin"},
+			input:     metric.New("test", map[string]string{}, map[string]any{"value": int64(42)}, time.Unix(1709547032, 0)),
+			expected:  metric.New("test", map[string]string{}, map[string]any{"value": int64(42)}, time.Unix(1709543432, 0)),
+		},
+		{
+			name:      "offset",
+			timestamp: Timestamp{Offset: config.Duration(-90 * time.Second)},
+			input:     metric.New("test", map[string]string{}, map[string]any{"value": int64(42)}, time.Unix(1709547032, 0)),
+			expected:  metric.New("test", map[string]string{}, map[string]any{"value": int64(42)}, time.Unix(1709546942, 0)),
+		},
+		{
+			name:      "clamp future",
+			timestamp: Timestamp{MaxFuture: config.Duration(5 * time.Minute)},
+			input:     metric.New("test", map[string]string{}, map[string]any{"value": int64(42)}, now.Add(time.Hour)),
+			expected:  metric.New("test", map[string]string{}, map[string]any{"value": int64(42)}, now.Add(5*time.Minute)),
+		},
+		{
+			name:      "clamp past",
+			timestamp: Timestamp{MaxFuture: config.Duration(5 * time.Minute), MaxPast: config.Duration(time.Hour)},
+			input:     metric.New("test", map[string]string{}, map[string]any{"value": int64(42)}, now.Add(-2*time.Hour)),
+			expected:  metric.New("test", map[string]string{}, map[string]any{"value": int64(42)}, now.Add(-time.Hour)),
+		},
+		{
+			name:      "within limits",
+			timestamp: Timestamp{MaxFuture: config.Duration(5 * time.Minute), MaxPast: config.Duration(time.Hour)},
+			input:     metric.New("test", map[string]string{}, map[string]any{"value": int64(42)}, now.Add(-time.Minute)),
+			expected:  metric.New("test", map[string]string{}, map[string]any{"value": int64(42)}, now.Add(-time.Minute)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := tt.timestamp
+			processor.now = func() time.Time { return now }
+			require.NoError(t, processor.Init())
+
+			output := processor.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, output)
+		})
+	}
+}
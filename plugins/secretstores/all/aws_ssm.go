@@ -0,0 +1,5 @@
+//go:build !custom || secretstores || secretstores.aws_ssm
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/secretstores/aws_ssm" // register plugin
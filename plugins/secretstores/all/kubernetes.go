@@ -0,0 +1,5 @@
+//go:build !custom || secretstores || secretstores.kubernetes
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/secretstores/kubernetes" // register plugin
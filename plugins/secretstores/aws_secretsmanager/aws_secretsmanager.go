@@ -0,0 +1,177 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package aws_secretsmanager
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type SecretsManager struct {
+	ID              string            `toml:"id"`
+	Timeout         config.Duration   `toml:"timeout"`
+	RefreshInterval config.Duration   `toml:"refresh_interval"`
+	Secrets         map[string]string `toml:"secrets"`
+	Log             telegraf.Logger   `toml:"-"`
+	common_aws.CredentialConfig
+
+	client *common_aws.JSONAPIClient
+	cache  map[string]*cachedValue
+	mu     sync.Mutex
+}
+
+type cachedValue struct {
+	value   []byte
+	fetched time.Time
+}
+
+type getSecretValueInput struct {
+	SecretID string `json:"SecretId"`
+}
+
+// The binary value is base64 encoded in the response which is taken care of
+// by the JSON decoder for byte slices
+type getSecretValueOutput struct {
+	SecretString *string `json:"SecretString"`
+	SecretBinary []byte  `json:"SecretBinary"`
+}
+
+func (*SecretsManager) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SecretsManager) Init() error {
+	if s.ID == "" {
+		return errors.New("id missing")
+	}
+	if s.RefreshInterval < 0 {
+		return errors.New("refresh interval must not be negative")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+	defer cancel()
+	if s.Region == "" {
+		region, err := common_aws.RegionFromIMDS(ctx)
+		if err != nil {
+			return fmt.Errorf("region not set and cannot be determined from instance metadata: %w", err)
+		}
+		s.Region = region
+	}
+
+	cfg, err := s.CredentialConfig.Credentials()
+	if err != nil {
+		return fmt.Errorf("loading credentials failed: %w", err)
+	}
+	s.client = common_aws.NewJSONAPIClient(cfg, "secretsmanager", "secretsmanager", s.EndpointURL, time.Duration(s.Timeout))
+	s.cache = make(map[string]*cachedValue)
+
+	return nil
+}
+
+func (s *SecretsManager) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached, found := s.cache[key]
+	if found && (s.RefreshInterval == 0 || time.Since(cached.fetched) < time.Duration(s.RefreshInterval)) {
+		return bytes.Clone(cached.value), nil
+	}
+
+	value, err := s.fetch(key)
+	if err != nil {
+		// Keep using the previous value if the secret cannot be refreshed
+		if found {
+			s.Log.Warnf("Refreshing secret %q failed, using cached value: %v", key, err)
+			return bytes.Clone(cached.value), nil
+		}
+		return nil, err
+	}
+	s.cache[key] = &cachedValue{value: value, fetched: time.Now()}
+
+	return bytes.Clone(value), nil
+}
+
+func (s *SecretsManager) List() ([]string, error) {
+	return slices.Sorted(maps.Keys(s.Secrets)), nil
+}
+
+func (*SecretsManager) Set(_, _ string) error {
+	return errors.New("secret store does not support creating secrets")
+}
+
+func (s *SecretsManager) GetResolver(key string) (telegraf.ResolveFunc, error) {
+	resolver := func() ([]byte, bool, error) {
+		v, err := s.Get(key)
+		return v, s.RefreshInterval > 0, err
+	}
+	return resolver, nil
+}
+
+// fetch reads the secret referenced by the key from Secrets Manager
+func (s *SecretsManager) fetch(key string) ([]byte, error) {
+	secretID, field := key, ""
+	if ref, found := s.Secrets[key]; found {
+		secretID, field, _ = strings.Cut(ref, "#")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+	defer cancel()
+
+	var out getSecretValueOutput
+	if err := s.client.Call(ctx, "GetSecretValue", &getSecretValueInput{SecretID: secretID}, &out); err != nil {
+		return nil, fmt.Errorf("getting secret %q failed: %w", secretID, err)
+	}
+
+	var value []byte
+	switch {
+	case out.SecretString != nil:
+		value = []byte(*out.SecretString)
+	case out.SecretBinary != nil:
+		value = out.SecretBinary
+	default:
+		return nil, fmt.Errorf("secret %q has no value", secretID)
+	}
+
+	if field == "" {
+		return value, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return nil, fmt.Errorf("secret %q is not a JSON object: %w", secretID, err)
+	}
+	raw, found := fields[field]
+	if !found {
+		return nil, fmt.Errorf("secret %q does not contain key %q", secretID, field)
+	}
+	if v, ok := raw.(string); ok {
+		return []byte(v), nil
+	}
+	return json.Marshal(raw)
+}
+
+// Register the secret store on load.
+func init() {
+	secretstores.Add("aws_secretsmanager", func(id string) telegraf.SecretStore {
+		return &SecretsManager{
+			ID:      id,
+			Timeout: config.Duration(10 * time.Second),
+		}
+	})
+}
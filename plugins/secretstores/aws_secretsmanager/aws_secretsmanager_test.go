@@ -0,0 +1,175 @@
+package aws_secretsmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newServer(t *testing.T, secrets map[string]string, requests *atomic.Int64) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if target := r.Header.Get("X-Amz-Target"); target != "secretsmanager.GetSecretValue" {
+			w.WriteHeader(http.StatusBadRequest)
+			t.Errorf("unexpected target %q", target)
+			return
+		}
+		if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256") {
+			w.WriteHeader(http.StatusForbidden)
+			t.Errorf("unexpected authorization %q", auth)
+			return
+		}
+
+		var input getSecretValueInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			t.Error(err)
+			return
+		}
+
+		var response interface{}
+		switch input.SecretID {
+		case "binary":
+			response = map[string]interface{}{"SecretBinary": []byte("binary value")}
+		default:
+			value, found := secrets[input.SecretID]
+			if !found {
+				w.WriteHeader(http.StatusBadRequest)
+				response = map[string]string{
+					"__type":  "ResourceNotFoundException",
+					"message": "Secrets Manager can't find the specified secret.",
+				}
+				break
+			}
+			response = map[string]string{"SecretString": value}
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func newPlugin(endpoint string) *SecretsManager {
+	return &SecretsManager{
+		ID:      "test",
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+		CredentialConfig: common_aws.CredentialConfig{
+			Region:      "us-east-1",
+			AccessKey:   "access",
+			SecretKey:   "secret",
+			EndpointURL: endpoint,
+		},
+	}
+}
+
+func TestSampleConfig(t *testing.T) {
+	plugin := &SecretsManager{}
+	require.NotEmpty(t, plugin.SampleConfig())
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := newPlugin("http://localhost")
+	plugin.ID = ""
+	require.ErrorContains(t, plugin.Init(), "id missing")
+
+	plugin = newPlugin("http://localhost")
+	plugin.RefreshInterval = config.Duration(-time.Second)
+	require.ErrorContains(t, plugin.Init(), "refresh interval must not be negative")
+}
+
+func TestGet(t *testing.T) {
+	secrets := map[string]string{
+		"plain":   "foo",
+		"prod/db": `{"username": "telegraf", "password": "s3cr3t", "port": 5432}`,
+	}
+	var requests atomic.Int64
+	server := newServer(t, secrets, &requests)
+	defer server.Close()
+
+	plugin := newPlugin(server.URL)
+	plugin.Secrets = map[string]string{
+		"db_user":     "prod/db#username",
+		"db_password": "prod/db#password",
+		"db_port":     "prod/db#port",
+		"db_missing":  "prod/db#missing",
+	}
+	require.NoError(t, plugin.Init())
+
+	tests := map[string]string{
+		"plain":       "foo",
+		"binary":      "binary value",
+		"db_user":     "telegraf",
+		"db_password": "s3cr3t",
+		"db_port":     "5432",
+	}
+	for key, expected := range tests {
+		actual, err := plugin.Get(key)
+		require.NoError(t, err, key)
+		require.Equal(t, expected, string(actual), key)
+	}
+
+	_, err := plugin.Get("db_missing")
+	require.ErrorContains(t, err, `does not contain key "missing"`)
+
+	_, err = plugin.Get("unknown")
+	require.ErrorContains(t, err, "ResourceNotFoundException: Secrets Manager can't find the specified secret.")
+
+	keys, err := plugin.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"db_missing", "db_password", "db_port", "db_user"}, keys)
+}
+
+func TestCaching(t *testing.T) {
+	secrets := map[string]string{"token": "first"}
+	var requests atomic.Int64
+	server := newServer(t, secrets, &requests)
+	defer server.Close()
+
+	plugin := newPlugin(server.URL)
+	require.NoError(t, plugin.Init())
+
+	resolver, err := plugin.GetResolver("token")
+	require.NoError(t, err)
+
+	// Without refresh interval the secret is static and fetched only once
+	for range 3 {
+		value, dynamic, err := resolver()
+		require.NoError(t, err)
+		require.False(t, dynamic)
+		require.Equal(t, "first", string(value))
+	}
+	require.Equal(t, int64(1), requests.Load())
+
+	// Refresh the secret once the interval passed
+	plugin.RefreshInterval = config.Duration(time.Minute)
+	secrets["token"] = "second"
+	plugin.cache["token"].fetched = time.Now().Add(-2 * time.Minute)
+
+	value, dynamic, err := resolver()
+	require.NoError(t, err)
+	require.True(t, dynamic)
+	require.Equal(t, "second", string(value))
+	require.Equal(t, int64(2), requests.Load())
+
+	// Keep the cached value if refreshing fails
+	delete(secrets, "token")
+	plugin.cache["token"].fetched = time.Now().Add(-2 * time.Minute)
+
+	value, _, err = resolver()
+	require.NoError(t, err)
+	require.Equal(t, "second", string(value))
+	require.Equal(t, int64(3), requests.Load())
+}
@@ -0,0 +1,151 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package aws_ssm
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type SSM struct {
+	ID              string            `toml:"id"`
+	Timeout         config.Duration   `toml:"timeout"`
+	RefreshInterval config.Duration   `toml:"refresh_interval"`
+	Prefix          string            `toml:"prefix"`
+	Parameters      map[string]string `toml:"parameters"`
+	Log             telegraf.Logger   `toml:"-"`
+	common_aws.CredentialConfig
+
+	client *common_aws.JSONAPIClient
+	cache  map[string]*cachedValue
+	mu     sync.Mutex
+}
+
+type cachedValue struct {
+	value   []byte
+	fetched time.Time
+}
+
+type getParameterInput struct {
+	Name           string `json:"Name"`
+	WithDecryption bool   `json:"WithDecryption"`
+}
+
+type getParameterOutput struct {
+	Parameter struct {
+		Value string `json:"Value"`
+	} `json:"Parameter"`
+}
+
+func (*SSM) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SSM) Init() error {
+	if s.ID == "" {
+		return errors.New("id missing")
+	}
+	if s.RefreshInterval < 0 {
+		return errors.New("refresh interval must not be negative")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+	defer cancel()
+	if s.Region == "" {
+		region, err := common_aws.RegionFromIMDS(ctx)
+		if err != nil {
+			return fmt.Errorf("region not set and cannot be determined from instance metadata: %w", err)
+		}
+		s.Region = region
+	}
+
+	cfg, err := s.CredentialConfig.Credentials()
+	if err != nil {
+		return fmt.Errorf("loading credentials failed: %w", err)
+	}
+	s.client = common_aws.NewJSONAPIClient(cfg, "ssm", "AmazonSSM", s.EndpointURL, time.Duration(s.Timeout))
+	s.cache = make(map[string]*cachedValue)
+
+	return nil
+}
+
+func (s *SSM) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached, found := s.cache[key]
+	if found && (s.RefreshInterval == 0 || time.Since(cached.fetched) < time.Duration(s.RefreshInterval)) {
+		return bytes.Clone(cached.value), nil
+	}
+
+	value, err := s.fetch(key)
+	if err != nil {
+		// Keep using the previous value if the parameter cannot be refreshed
+		if found {
+			s.Log.Warnf("Refreshing parameter for %q failed, using cached value: %v", key, err)
+			return bytes.Clone(cached.value), nil
+		}
+		return nil, err
+	}
+	s.cache[key] = &cachedValue{value: value, fetched: time.Now()}
+
+	return bytes.Clone(value), nil
+}
+
+func (s *SSM) List() ([]string, error) {
+	return slices.Sorted(maps.Keys(s.Parameters)), nil
+}
+
+func (*SSM) Set(_, _ string) error {
+	return errors.New("secret store does not support creating secrets")
+}
+
+func (s *SSM) GetResolver(key string) (telegraf.ResolveFunc, error) {
+	resolver := func() ([]byte, bool, error) {
+		v, err := s.Get(key)
+		return v, s.RefreshInterval > 0, err
+	}
+	return resolver, nil
+}
+
+// fetch reads the parameter referenced by the key from the Parameter Store
+func (s *SSM) fetch(key string) ([]byte, error) {
+	name, found := s.Parameters[key]
+	if !found {
+		name = s.Prefix + key
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+	defer cancel()
+
+	var out getParameterOutput
+	input := &getParameterInput{Name: name, WithDecryption: true}
+	if err := s.client.Call(ctx, "GetParameter", input, &out); err != nil {
+		return nil, fmt.Errorf("getting parameter %q failed: %w", name, err)
+	}
+	return []byte(out.Parameter.Value), nil
+}
+
+// Register the secret store on load.
+func init() {
+	secretstores.Add("aws_ssm", func(id string) telegraf.SecretStore {
+		return &SSM{
+			ID:      id,
+			Timeout: config.Duration(10 * time.Second),
+		}
+	})
+}
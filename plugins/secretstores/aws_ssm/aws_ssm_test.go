@@ -0,0 +1,144 @@
+package aws_ssm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newServer(t *testing.T, parameters map[string]string, requests *atomic.Int64) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if target := r.Header.Get("X-Amz-Target"); target != "AmazonSSM.GetParameter" {
+			w.WriteHeader(http.StatusBadRequest)
+			t.Errorf("unexpected target %q", target)
+			return
+		}
+		if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256") {
+			w.WriteHeader(http.StatusForbidden)
+			t.Errorf("unexpected authorization %q", auth)
+			return
+		}
+
+		var input getParameterInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			t.Error(err)
+			return
+		}
+		if !input.WithDecryption {
+			t.Error("parameter requested without decryption")
+		}
+
+		var response interface{}
+		value, found := parameters[input.Name]
+		if found {
+			response = map[string]interface{}{
+				"Parameter": map[string]string{"Name": input.Name, "Type": "SecureString", "Value": value},
+			}
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+			response = map[string]string{"__type": "ParameterNotFound"}
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func newPlugin(endpoint string) *SSM {
+	return &SSM{
+		ID:      "test",
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+		CredentialConfig: common_aws.CredentialConfig{
+			Region:      "us-east-1",
+			AccessKey:   "access",
+			SecretKey:   "secret",
+			EndpointURL: endpoint,
+		},
+	}
+}
+
+func TestSampleConfig(t *testing.T) {
+	plugin := &SSM{}
+	require.NotEmpty(t, plugin.SampleConfig())
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := newPlugin("http://localhost")
+	plugin.ID = ""
+	require.ErrorContains(t, plugin.Init(), "id missing")
+}
+
+func TestGet(t *testing.T) {
+	parameters := map[string]string{
+		"/telegraf/token":   "foo",
+		"/prod/db/password": "s3cr3t",
+	}
+	var requests atomic.Int64
+	server := newServer(t, parameters, &requests)
+	defer server.Close()
+
+	plugin := newPlugin(server.URL)
+	plugin.Prefix = "/telegraf/"
+	plugin.Parameters = map[string]string{"db_password": "/prod/db/password"}
+	require.NoError(t, plugin.Init())
+
+	value, err := plugin.Get("token")
+	require.NoError(t, err)
+	require.Equal(t, "foo", string(value))
+
+	value, err = plugin.Get("db_password")
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", string(value))
+
+	_, err = plugin.Get("unknown")
+	require.ErrorContains(t, err, `getting parameter "/telegraf/unknown" failed: ParameterNotFound`)
+
+	keys, err := plugin.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"db_password"}, keys)
+}
+
+func TestCaching(t *testing.T) {
+	parameters := map[string]string{"token": "first"}
+	var requests atomic.Int64
+	server := newServer(t, parameters, &requests)
+	defer server.Close()
+
+	plugin := newPlugin(server.URL)
+	plugin.RefreshInterval = config.Duration(time.Minute)
+	require.NoError(t, plugin.Init())
+
+	resolver, err := plugin.GetResolver("token")
+	require.NoError(t, err)
+
+	for range 3 {
+		value, dynamic, err := resolver()
+		require.NoError(t, err)
+		require.True(t, dynamic)
+		require.Equal(t, "first", string(value))
+	}
+	require.Equal(t, int64(1), requests.Load())
+
+	parameters["token"] = "second"
+	plugin.cache["token"].fetched = time.Now().Add(-2 * time.Minute)
+
+	value, _, err := resolver()
+	require.NoError(t, err)
+	require.Equal(t, "second", string(value))
+	require.Equal(t, int64(2), requests.Load())
+}
@@ -0,0 +1,105 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package kubernetes
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Kubernetes struct {
+	ID      string `toml:"id"`
+	Path    string `toml:"path"`
+	Dynamic bool   `toml:"dynamic"`
+}
+
+func (*Kubernetes) SampleConfig() string {
+	return sampleConfig
+}
+
+func (k *Kubernetes) Init() error {
+	if k.ID == "" {
+		return errors.New("id missing")
+	}
+	if k.Path == "" {
+		return errors.New("path missing")
+	}
+
+	var err error
+	k.Path, err = filepath.Abs(k.Path)
+	if err != nil {
+		return fmt.Errorf("cannot determine absolute path of %q: %w", k.Path, err)
+	}
+	if _, err := os.Stat(k.Path); err != nil {
+		return fmt.Errorf("accessing directory %q failed: %w", k.Path, err)
+	}
+	return nil
+}
+
+func (k *Kubernetes) Get(key string) ([]byte, error) {
+	// The kubelet keeps the actual data in hidden directories prefixed by
+	// two dots, so do not allow to access those directly
+	if strings.HasPrefix(key, "..") {
+		return nil, fmt.Errorf("invalid key %q", key)
+	}
+	secretFile, err := filepath.Abs(filepath.Join(k.Path, key))
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Dir(secretFile) != k.Path {
+		return nil, fmt.Errorf("directory traversal detected for key %q", key)
+	}
+
+	// The secret files are symlinks to the current data directory which is
+	// swapped atomically on updates, so reading the file always returns a
+	// consistent value.
+	value, err := os.ReadFile(secretFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the secret's value: %w", err)
+	}
+	return value, nil
+}
+
+func (k *Kubernetes) List() ([]string, error) {
+	entries, err := os.ReadDir(k.Path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read files under the directory: %w", err)
+	}
+	secrets := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		// Skip the kubelet's internal data directories and links
+		if strings.HasPrefix(entry.Name(), "..") || entry.IsDir() {
+			continue
+		}
+		secrets = append(secrets, entry.Name())
+	}
+	return secrets, nil
+}
+
+func (*Kubernetes) Set(_, _ string) error {
+	return errors.New("secret store does not support creating secrets")
+}
+
+func (k *Kubernetes) GetResolver(key string) (telegraf.ResolveFunc, error) {
+	resolver := func() ([]byte, bool, error) {
+		s, err := k.Get(key)
+		return s, k.Dynamic, err
+	}
+	return resolver, nil
+}
+
+// Register the secret store on load.
+func init() {
+	secretstores.Add("kubernetes", func(id string) telegraf.SecretStore {
+		return &Kubernetes{ID: id, Dynamic: true}
+	})
+}
@@ -0,0 +1,148 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createSecretVolume mimics the layout of a secret volume created by the
+// kubelet with the secret files linking to the current data directory
+func createSecretVolume(t *testing.T, secrets map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	updateSecretVolume(t, dir, "..2024_01_01_00_00_00.0", secrets)
+	for k := range secrets {
+		require.NoError(t, os.Symlink(filepath.Join("..data", k), filepath.Join(dir, k)))
+	}
+	return dir
+}
+
+// updateSecretVolume atomically swaps the data directory like the kubelet
+// does when a secret is rotated
+func updateSecretVolume(t *testing.T, dir, version string, secrets map[string]string) {
+	t.Helper()
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, version), 0750))
+	for k, v := range secrets {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, version, k), []byte(v), 0600))
+	}
+	require.NoError(t, os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+}
+
+func TestSampleConfig(t *testing.T) {
+	plugin := &Kubernetes{}
+	require.NotEmpty(t, plugin.SampleConfig())
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Kubernetes
+		expected string
+	}{
+		{
+			name:     "missing id",
+			plugin:   &Kubernetes{Path: "testdata"},
+			expected: "id missing",
+		},
+		{
+			name:     "missing path",
+			plugin:   &Kubernetes{ID: "test"},
+			expected: "path missing",
+		},
+		{
+			name:     "non-existent path",
+			plugin:   &Kubernetes{ID: "test", Path: "non/existent/path"},
+			expected: "accessing directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestListGet(t *testing.T) {
+	secrets := map[string]string{
+		"username": "john-doe",
+		"password": "SuperDuperSecret!23",
+	}
+
+	plugin := &Kubernetes{
+		ID:   "test_list_get",
+		Path: createSecretVolume(t, secrets),
+	}
+	require.NoError(t, plugin.Init())
+
+	// The kubelet's data directories must not be listed
+	keys, err := plugin.List()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"username", "password"}, keys)
+
+	for k, expected := range secrets {
+		value, err := plugin.Get(k)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(value))
+	}
+
+	_, err = plugin.Get("..data")
+	require.ErrorContains(t, err, "invalid key")
+	_, err = plugin.Get("../username")
+	require.ErrorContains(t, err, "invalid key")
+	_, err = plugin.Get("foo/../../username")
+	require.ErrorContains(t, err, "directory traversal detected")
+}
+
+func TestSetNotAvailable(t *testing.T) {
+	plugin := &Kubernetes{
+		ID:   "test_set",
+		Path: createSecretVolume(t, map[string]string{"username": "john-doe"}),
+	}
+	require.NoError(t, plugin.Init())
+	require.ErrorContains(t, plugin.Set("username", "jane-doe"), "secret store does not support creating secrets")
+}
+
+func TestResolverRotation(t *testing.T) {
+	dir := createSecretVolume(t, map[string]string{"password": "old"})
+
+	for _, dynamic := range []bool{true, false} {
+		plugin := &Kubernetes{
+			ID:      "test_rotation",
+			Path:    dir,
+			Dynamic: dynamic,
+		}
+		require.NoError(t, plugin.Init())
+
+		resolver, err := plugin.GetResolver("password")
+		require.NoError(t, err)
+		value, isDynamic, err := resolver()
+		require.NoError(t, err)
+		require.Equal(t, dynamic, isDynamic)
+		require.NotEmpty(t, value)
+	}
+
+	plugin := &Kubernetes{
+		ID:      "test_rotation",
+		Path:    dir,
+		Dynamic: true,
+	}
+	require.NoError(t, plugin.Init())
+	resolver, err := plugin.GetResolver("password")
+	require.NoError(t, err)
+
+	value, _, err := resolver()
+	require.NoError(t, err)
+	require.Equal(t, "old", string(value))
+
+	updateSecretVolume(t, dir, "..2024_02_01_00_00_00.0", map[string]string{"password": "new"})
+	value, _, err = resolver()
+	require.NoError(t, err)
+	require.Equal(t, "new", string(value))
+}
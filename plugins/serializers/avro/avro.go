@@ -0,0 +1,163 @@
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+// Magic byte of the Confluent wire format
+// (https://docs.confluent.io/platform/current/schema-registry/fundamentals/serdes-develop/index.html#wire-format)
+const magicByte = 0x00
+
+// Serializer encodes metrics as Avro records. If Schema is set, all metrics are
+// encoded using the given schema, otherwise a schema is generated for each
+// measurement and set of tags and fields. If SchemaRegistry is set, the
+// messages are prefixed with the Confluent wire format header containing the
+// ID of the schema registered for the message.
+type Serializer struct {
+	SchemaRegistry  string `toml:"avro_schema_registry"`
+	CaCertPath      string `toml:"avro_schema_registry_cert"`
+	Schema          string `toml:"avro_schema"`
+	Subject         string `toml:"avro_subject"`
+	Namespace       string `toml:"avro_namespace"`
+	Timestamp       string `toml:"avro_timestamp"`
+	TimestampFormat string `toml:"avro_timestamp_format"`
+
+	subject  *template.Template
+	fixed    *schemaCodec
+	registry *schemaRegistry
+
+	// Generated schemas by their signature
+	generated map[string]*schemaCodec
+	sync.Mutex
+}
+
+func (s *Serializer) Init() error {
+	if s.Timestamp == "" {
+		s.Timestamp = "timestamp"
+	}
+
+	switch s.TimestampFormat {
+	case "":
+		s.TimestampFormat = "unix_ns"
+	case "unix", "unix_ms", "unix_us", "unix_ns":
+		// Valid values
+	default:
+		return fmt.Errorf("invalid timestamp format %q", s.TimestampFormat)
+	}
+
+	if s.Namespace == "" {
+		s.Namespace = "telegraf"
+	}
+
+	if s.Schema != "" {
+		sc, err := newFixedSchema(s.Schema)
+		if err != nil {
+			return fmt.Errorf("invalid schema: %w", err)
+		}
+		s.fixed = sc
+	}
+	s.generated = make(map[string]*schemaCodec)
+
+	if s.SchemaRegistry != "" {
+		if s.Subject == "" {
+			s.Subject = "{{ .Name }}-value"
+		}
+		tmpl, err := template.New("subject").Funcs(sprig.TxtFuncMap()).Parse(s.Subject)
+		if err != nil {
+			return fmt.Errorf("creating subject template failed: %w", err)
+		}
+		s.subject = tmpl
+
+		registry, err := newSchemaRegistry(s.SchemaRegistry, s.CaCertPath)
+		if err != nil {
+			return err
+		}
+		s.registry = registry
+	} else if s.Subject != "" {
+		return errors.New("'avro_subject' requires a schema registry")
+	}
+
+	return nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	sc, err := s.schemaFor(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	native, err := sc.native(metric, s.Timestamp, s.TimestampFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.registry == nil {
+		return sc.codec.BinaryFromNative(nil, native)
+	}
+
+	var subject strings.Builder
+	if err := s.subject.Execute(&subject, metric); err != nil {
+		return nil, fmt.Errorf("generating subject failed: %w", err)
+	}
+	id, err := s.registry.register(subject.String(), sc.schema)
+	if err != nil {
+		return nil, fmt.Errorf("registering schema for subject %q failed: %w", subject.String(), err)
+	}
+
+	header := make([]byte, 5)
+	header[0] = magicByte
+	binary.BigEndian.PutUint32(header[1:], uint32(id))
+	return sc.codec.BinaryFromNative(header, native)
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var serialized []byte
+	for _, m := range metrics {
+		buf, err := s.Serialize(m)
+		if err != nil {
+			return nil, err
+		}
+		serialized = append(serialized, buf...)
+	}
+	return serialized, nil
+}
+
+// schemaFor returns the fixed schema or the schema generated for the metric
+func (s *Serializer) schemaFor(metric telegraf.Metric) (*schemaCodec, error) {
+	if s.fixed != nil {
+		return s.fixed, nil
+	}
+
+	signature := schemaSignature(metric)
+
+	s.Lock()
+	defer s.Unlock()
+	if sc, found := s.generated[signature]; found {
+		return sc, nil
+	}
+
+	sc, err := newGeneratedSchema(metric, s.Namespace, s.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("generating schema for %q failed: %w", metric.Name(), err)
+	}
+	s.generated[signature] = sc
+	return sc, nil
+}
+
+func init() {
+	serializers.Add("avro",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
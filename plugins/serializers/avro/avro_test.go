@@ -0,0 +1,246 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		serializer *Serializer
+		expected   string
+	}{
+		{
+			name:       "invalid timestamp format",
+			serializer: &Serializer{TimestampFormat: "rfc3339"},
+			expected:   `invalid timestamp format "rfc3339"`,
+		},
+		{
+			name:       "invalid schema",
+			serializer: &Serializer{Schema: `{"type": "foo"}`},
+			expected:   "invalid schema",
+		},
+		{
+			name:       "non-record schema",
+			serializer: &Serializer{Schema: `"string"`},
+			expected:   "invalid schema",
+		},
+		{
+			name:       "subject without registry",
+			serializer: &Serializer{Subject: "telegraf"},
+			expected:   "'avro_subject' requires a schema registry",
+		},
+		{
+			name:       "invalid subject template",
+			serializer: &Serializer{SchemaRegistry: "http://localhost:8081", Subject: "{{ .Name"},
+			expected:   "creating subject template failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.serializer.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGeneratedSchema(t *testing.T) {
+	m := metric.New(
+		"cpu-usage",
+		map[string]string{"host": "server01", "cpu": "cpu0"},
+		map[string]interface{}{
+			"idle":   float64(90.5),
+			"count":  int64(3),
+			"ok":     true,
+			"state":  "running",
+			"uptime": uint64(42),
+		},
+		time.Unix(1703018620, 0),
+	)
+
+	s := &Serializer{TimestampFormat: "unix_ms"}
+	require.NoError(t, s.Init())
+
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	sc, err := s.schemaFor(m)
+	require.NoError(t, err)
+	expectedSchema := `{"type":"record","name":"cpu_usage","namespace":"telegraf","fields":[` +
+		`{"name":"timestamp","type":"long"},` +
+		`{"name":"cpu","type":["null","string"],"default":null},` +
+		`{"name":"host","type":["null","string"],"default":null},` +
+		`{"name":"count","type":["null","long"],"default":null},` +
+		`{"name":"idle","type":["null","double"],"default":null},` +
+		`{"name":"ok","type":["null","boolean"],"default":null},` +
+		`{"name":"state","type":["null","string"],"default":null},` +
+		`{"name":"uptime","type":["null","double"],"default":null}]}`
+	require.JSONEq(t, expectedSchema, sc.schema)
+
+	native, remaining, err := sc.codec.NativeFromBinary(buf)
+	require.NoError(t, err)
+	require.Empty(t, remaining)
+
+	expected := map[string]interface{}{
+		"timestamp": int64(1703018620000),
+		"cpu":       map[string]interface{}{"string": "cpu0"},
+		"host":      map[string]interface{}{"string": "server01"},
+		"count":     map[string]interface{}{"long": int64(3)},
+		"idle":      map[string]interface{}{"double": float64(90.5)},
+		"ok":        map[string]interface{}{"boolean": true},
+		"state":     map[string]interface{}{"string": "running"},
+		"uptime":    map[string]interface{}{"double": float64(42)},
+	}
+	require.Equal(t, expected, native)
+}
+
+func TestGeneratedSchemaCache(t *testing.T) {
+	s := &Serializer{}
+	require.NoError(t, s.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": int64(2)}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"host": "b"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+	}
+	_, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+	require.Len(t, s.generated, 3)
+}
+
+func TestGeneratedSchemaDuplicateName(t *testing.T) {
+	s := &Serializer{}
+	require.NoError(t, s.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"value": "a"},
+		map[string]interface{}{"value": 1.0},
+		time.Unix(0, 0),
+	)
+	_, err := s.Serialize(m)
+	require.ErrorContains(t, err, `duplicate name "value"`)
+}
+
+func TestFixedSchema(t *testing.T) {
+	schema := `{
+		"type": "record",
+		"name": "Measurement",
+		"namespace": "com.example",
+		"fields": [
+			{"name": "ts", "type": "long"},
+			{"name": "host", "type": "string"},
+			{"name": "value", "type": "float"},
+			{"name": "count", "type": ["null", "int"]},
+			{"name": "unit", "type": "string", "default": "percent"}
+		]
+	}`
+
+	s := &Serializer{Schema: schema, Timestamp: "ts", TimestampFormat: "unix"}
+	require.NoError(t, s.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "server01", "ignored": "tag"},
+		map[string]interface{}{"value": int64(42), "ignored": "field"},
+		time.Unix(1703018620, 0),
+	)
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	codec, err := goavro.NewCodec(schema)
+	require.NoError(t, err)
+	native, _, err := codec.NativeFromBinary(buf)
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"ts":    int64(1703018620),
+		"host":  "server01",
+		"value": float32(42),
+		"count": nil,
+		"unit":  "percent",
+	}
+	require.Equal(t, expected, native)
+
+	// Missing values without default cause an error
+	m = metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	_, err = s.Serialize(m)
+	require.ErrorContains(t, err, `missing value for field "host"`)
+
+	// Values of incompatible types cause an error
+	m = metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": "high"}, time.Unix(0, 0))
+	_, err = s.Serialize(m)
+	require.ErrorContains(t, err, `field "value": cannot convert`)
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.Method != http.MethodPost || r.URL.Path != "/subjects/telegraf-cpu/versions" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/vnd.schemaregistry.v1+json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["schema"] == "" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		if _, err := w.Write([]byte(`{"id": 258}`)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	s := &Serializer{
+		SchemaRegistry: "http://user:secret@" + server.Listener.Addr().String(),
+		Subject:        "telegraf-{{ .Name }}",
+	}
+	require.NoError(t, s.Init())
+
+	m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	for range 3 {
+		buf, err := s.Serialize(m)
+		require.NoError(t, err)
+		require.Greater(t, len(buf), 5)
+		require.Equal(t, byte(0), buf[0])
+		require.Equal(t, uint32(258), binary.BigEndian.Uint32(buf[1:5]))
+
+		sc, err := s.schemaFor(m)
+		require.NoError(t, err)
+		_, remaining, err := sc.codec.NativeFromBinary(buf[5:])
+		require.NoError(t, err)
+		require.Empty(t, remaining)
+	}
+
+	// The schema ID should be cached after the first registration
+	require.Equal(t, int32(1), requests.Load())
+
+	// Errors of the registry are reported
+	m = metric.New("mem", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	_, err := s.Serialize(m)
+	require.ErrorContains(t, err, `registering schema for subject "telegraf-mem" failed: received status 404`)
+}
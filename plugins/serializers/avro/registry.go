@@ -0,0 +1,127 @@
+package avro
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+const subjectVersions = "%s/subjects/%s/versions"
+
+type schemaRegistry struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+
+	// Registered schema IDs by subject and schema
+	cache map[string]int
+	mu    sync.RWMutex
+}
+
+func newSchemaRegistry(addr, caCertPath string) (*schemaRegistry, error) {
+	var tlsCfg *tls.Config
+	if caCertPath != "" {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(caCert)
+		tlsCfg = &tls.Config{
+			RootCAs: caCertPool,
+		}
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			MaxIdleConns:    10,
+			IdleConnTimeout: 90 * time.Second,
+		},
+		Timeout: 10 * time.Second,
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry URL failed: %w", err)
+	}
+
+	var username, password string
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+		u.User = nil
+	}
+
+	return &schemaRegistry{
+		url:      u.String(),
+		username: username,
+		password: password,
+		client:   client,
+		cache:    make(map[string]int),
+	}, nil
+}
+
+// register registers the schema for the given subject and returns the schema
+// ID. The registry returns the existing ID if the schema is already known.
+func (sr *schemaRegistry) register(subject, schema string) (int, error) {
+	key := subject + "\x00" + schema
+
+	sr.mu.RLock()
+	id, found := sr.cache[key]
+	sr.mu.RUnlock()
+	if found {
+		return id, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"schema": schema})
+	if err != nil {
+		return 0, err
+	}
+
+	addr := fmt.Sprintf(subjectVersions, sr.url, url.PathEscape(subject))
+	req, err := http.NewRequest(http.MethodPost, addr, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	if sr.username != "" {
+		req.SetBasicAuth(sr.username, sr.password)
+	}
+
+	resp, err := sr.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return 0, fmt.Errorf("received status %d (%s): %s", resp.StatusCode, http.StatusText(resp.StatusCode), string(msg))
+	}
+
+	var response struct {
+		ID *int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, err
+	}
+	if response.ID == nil {
+		return 0, errors.New("malformed response from schema registry: no 'id' key")
+	}
+
+	sr.mu.Lock()
+	sr.cache[key] = *response.ID
+	sr.mu.Unlock()
+
+	return *response.ID, nil
+}
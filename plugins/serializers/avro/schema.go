@@ -0,0 +1,357 @@
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/influxdata/telegraf"
+)
+
+var invalidNameRe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Source of the value of a schema field
+const (
+	sourceAny = iota
+	sourceTime
+	sourceTag
+	sourceField
+)
+
+type schemaCodec struct {
+	schema string
+	codec  *goavro.Codec
+	fields []schemaField
+}
+
+type schemaField struct {
+	name       string
+	key        string
+	source     int
+	types      []string
+	nullable   bool
+	hasDefault bool
+}
+
+// Avro schema definitions used for parsing and generating schemas
+type recordSchema struct {
+	Type      string        `json:"type"`
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace,omitempty"`
+	Fields    []fieldSchema `json:"fields"`
+}
+
+type fieldSchema struct {
+	Name    string          `json:"name"`
+	Type    json.RawMessage `json:"type"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+// newFixedSchema parses the given record schema. The record fields are
+// filled with the timestamp, fields or tags of the same name.
+func newFixedSchema(schema string) (*schemaCodec, error) {
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var record recordSchema
+	if err := json.Unmarshal([]byte(schema), &record); err != nil {
+		return nil, err
+	}
+	if record.Type != "record" {
+		return nil, fmt.Errorf("expected a record schema but got %q", record.Type)
+	}
+
+	fields := make([]schemaField, 0, len(record.Fields))
+	for _, f := range record.Fields {
+		types, err := parseType(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.Name, err)
+		}
+		fields = append(fields, schemaField{
+			name:       f.Name,
+			key:        f.Name,
+			source:     sourceAny,
+			types:      types,
+			nullable:   slices.Contains(types, "null"),
+			hasDefault: len(f.Default) > 0,
+		})
+	}
+
+	return &schemaCodec{schema: schema, codec: codec, fields: fields}, nil
+}
+
+// parseType returns the primitive types of the given field type or the types
+// of a union
+func parseType(raw json.RawMessage) ([]string, error) {
+	var primitive string
+	if err := json.Unmarshal(raw, &primitive); err == nil {
+		return []string{primitive}, nil
+	}
+
+	var complexType struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &complexType); err == nil {
+		return []string{complexType.Type}, nil
+	}
+
+	var union []json.RawMessage
+	if err := json.Unmarshal(raw, &union); err != nil {
+		return nil, fmt.Errorf("unsupported type %s", string(raw))
+	}
+	types := make([]string, 0, len(union))
+	for _, branch := range union {
+		t, err := parseType(branch)
+		if err != nil {
+			return nil, err
+		}
+		if len(t) != 1 {
+			return nil, errors.New("nested unions are not supported")
+		}
+		types = append(types, t[0])
+	}
+	return types, nil
+}
+
+// schemaSignature identifies the schema generated for the metric
+func schemaSignature(metric telegraf.Metric) string {
+	var b strings.Builder
+	b.WriteString(metric.Name())
+	for _, tag := range metric.TagList() {
+		b.WriteString("\x00t:")
+		b.WriteString(tag.Key)
+	}
+	fields := metric.FieldList()
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		keys = append(keys, field.Key+":"+avroType(field.Value))
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		b.WriteString("\x00f:")
+		b.WriteString(key)
+	}
+	return b.String()
+}
+
+// newGeneratedSchema creates a record schema for the measurement of the
+// metric with all tags and fields being optional
+func newGeneratedSchema(metric telegraf.Metric, namespace, timestamp string) (*schemaCodec, error) {
+	record := recordSchema{
+		Type:      "record",
+		Name:      sanitizeName(metric.Name()),
+		Namespace: namespace,
+		Fields:    []fieldSchema{{Name: timestamp, Type: json.RawMessage(`"long"`)}},
+	}
+	fields := []schemaField{{name: timestamp, source: sourceTime, types: []string{"long"}}}
+	seen := map[string]bool{timestamp: true}
+
+	add := func(key, typ string, source int) error {
+		name := sanitizeName(key)
+		if seen[name] {
+			return fmt.Errorf("duplicate name %q", name)
+		}
+		seen[name] = true
+
+		record.Fields = append(record.Fields, fieldSchema{
+			Name:    name,
+			Type:    json.RawMessage(`["null","` + typ + `"]`),
+			Default: json.RawMessage("null"),
+		})
+		fields = append(fields, schemaField{
+			name:       name,
+			key:        key,
+			source:     source,
+			types:      []string{"null", typ},
+			nullable:   true,
+			hasDefault: true,
+		})
+		return nil
+	}
+
+	for _, tag := range metric.TagList() {
+		if err := add(tag.Key, "string", sourceTag); err != nil {
+			return nil, err
+		}
+	}
+	metricFields := metric.FieldList()
+	slices.SortFunc(metricFields, func(a, b *telegraf.Field) int { return strings.Compare(a.Key, b.Key) })
+	for _, field := range metricFields {
+		if err := add(field.Key, avroType(field.Value), sourceField); err != nil {
+			return nil, err
+		}
+	}
+
+	schema, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := goavro.NewCodec(string(schema))
+	if err != nil {
+		return nil, err
+	}
+	return &schemaCodec{schema: string(schema), codec: codec, fields: fields}, nil
+}
+
+// native converts the metric to the native representation of the schema
+func (sc *schemaCodec) native(metric telegraf.Metric, timestamp, timestampFormat string) (map[string]interface{}, error) {
+	record := make(map[string]interface{}, len(sc.fields))
+	for _, f := range sc.fields {
+		value, found := f.lookup(metric, timestamp, timestampFormat)
+		if !found {
+			switch {
+			case f.hasDefault:
+				// The codec uses the default value of the schema
+			case f.nullable:
+				record[f.name] = nil
+			default:
+				return nil, fmt.Errorf("missing value for field %q", f.name)
+			}
+			continue
+		}
+
+		converted, err := f.convert(value)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.name, err)
+		}
+		record[f.name] = converted
+	}
+	return record, nil
+}
+
+func (f *schemaField) lookup(metric telegraf.Metric, timestamp, timestampFormat string) (interface{}, bool) {
+	source := f.source
+	if source == sourceAny && f.key == timestamp {
+		source = sourceTime
+	}
+
+	switch source {
+	case sourceTime:
+		t := metric.Time()
+		switch timestampFormat {
+		case "unix":
+			return t.Unix(), true
+		case "unix_ms":
+			return t.UnixMilli(), true
+		case "unix_us":
+			return t.UnixMicro(), true
+		}
+		return t.UnixNano(), true
+	case sourceTag:
+		return metric.GetTag(f.key)
+	case sourceField:
+		return metric.GetField(f.key)
+	}
+
+	if v, found := metric.GetField(f.key); found {
+		return v, true
+	}
+	return metric.GetTag(f.key)
+}
+
+// convert converts the value to the first matching type of the field
+func (f *schemaField) convert(value interface{}) (interface{}, error) {
+	for _, t := range f.types {
+		if t == "null" {
+			continue
+		}
+		v, ok := convertValue(t, value)
+		if !ok {
+			continue
+		}
+		if len(f.types) > 1 {
+			return goavro.Union(t, v), nil
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("cannot convert %v (%T) to %s", value, value, strings.Join(f.types, ","))
+}
+
+func convertValue(t string, value interface{}) (interface{}, bool) {
+	switch t {
+	case "long":
+		switch v := value.(type) {
+		case int64:
+			return v, true
+		case uint64:
+			if v <= math.MaxInt64 {
+				return int64(v), true
+			}
+		}
+	case "int":
+		switch v := value.(type) {
+		case int64:
+			if v >= math.MinInt32 && v <= math.MaxInt32 {
+				return int32(v), true
+			}
+		case uint64:
+			if v <= math.MaxInt32 {
+				return int32(v), true
+			}
+		}
+	case "double":
+		switch v := value.(type) {
+		case float64:
+			return v, true
+		case int64:
+			return float64(v), true
+		case uint64:
+			return float64(v), true
+		}
+	case "float":
+		switch v := value.(type) {
+		case float64:
+			return float32(v), true
+		case int64:
+			return float32(v), true
+		case uint64:
+			return float32(v), true
+		}
+	case "boolean":
+		if v, ok := value.(bool); ok {
+			return v, true
+		}
+	case "string":
+		if v, ok := value.(string); ok {
+			return v, true
+		}
+	case "bytes":
+		if v, ok := value.(string); ok {
+			return []byte(v), true
+		}
+	}
+	return nil, false
+}
+
+// avroType returns the Avro type for the given field value
+func avroType(value interface{}) string {
+	switch value.(type) {
+	case float64:
+		return "double"
+	case int64:
+		return "long"
+	case uint64:
+		// Avro does not support unsigned values, use double to avoid overflows
+		return "double"
+	case bool:
+		return "boolean"
+	}
+	return "string"
+}
+
+// sanitizeName converts the name to a valid Avro name
+func sanitizeName(name string) string {
+	name = invalidNameRe.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
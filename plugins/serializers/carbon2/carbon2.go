@@ -64,6 +64,12 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	return batch.Bytes(), nil
 }
 
+// ConcatenatesBatches returns true as each metric is written as separate
+// carbon2 lines
+func (*Serializer) ConcatenatesBatches() bool {
+	return true
+}
+
 func (s *Serializer) createObject(metric telegraf.Metric) []byte {
 	var m bytes.Buffer
 
@@ -150,6 +150,12 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	return batch.Bytes(), nil
 }
 
+// ConcatenatesBatches returns true as the batch is built from the output of
+// Serialize for each metric
+func (*Serializer) ConcatenatesBatches() bool {
+	return true
+}
+
 // SerializeBucketName will take the given measurement name and tags and
 // produce a graphite bucket. It will use the Serializer.Template
 // to generate this, or DefaultTemplate.
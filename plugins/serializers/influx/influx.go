@@ -92,6 +92,12 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	return append(out, s.buf.Bytes()...), nil
 }
 
+// ConcatenatesBatches returns true as a batch consists of independent lines of
+// line protocol
+func (*Serializer) ConcatenatesBatches() bool {
+	return true
+}
+
 func (s *Serializer) write(w io.Writer, m telegraf.Metric) error {
 	return s.writeMetric(w, m)
 }
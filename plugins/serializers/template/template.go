@@ -14,10 +14,14 @@ import (
 type Serializer struct {
 	Template      string          `toml:"template"`
 	BatchTemplate string          `toml:"batch_template"`
+	BatchHeader   string          `toml:"batch_header"`
+	BatchFooter   string          `toml:"batch_footer"`
 	Log           telegraf.Logger `toml:"-"`
 
 	tmplMetric *template.Template
 	tmplBatch  *template.Template
+	tmplHeader *template.Template
+	tmplFooter *template.Template
 }
 
 func (s *Serializer) Init() error {
@@ -35,6 +39,18 @@ func (s *Serializer) Init() error {
 	if err != nil {
 		return fmt.Errorf("creating batch template failed: %w", err)
 	}
+	if s.BatchHeader != "" {
+		s.tmplHeader, err = template.New("batch header").Funcs(sprig.TxtFuncMap()).Parse(s.BatchHeader)
+		if err != nil {
+			return fmt.Errorf("creating batch header template failed: %w", err)
+		}
+	}
+	if s.BatchFooter != "" {
+		s.tmplFooter, err = template.New("batch footer").Funcs(sprig.TxtFuncMap()).Parse(s.BatchFooter)
+		if err != nil {
+			return fmt.Errorf("creating batch footer template failed: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -89,10 +105,22 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	}
 
 	var b bytes.Buffer
+	if s.tmplHeader != nil {
+		if err := s.tmplHeader.Execute(&b, &newMetrics); err != nil {
+			s.Log.Errorf("failed to execute batch header template: %v", err)
+			return nil, nil
+		}
+	}
 	if err := s.tmplBatch.Execute(&b, &newMetrics); err != nil {
 		s.Log.Errorf("failed to execute batch template: %v", err)
 		return nil, nil
 	}
+	if s.tmplFooter != nil {
+		if err := s.tmplFooter.Execute(&b, &newMetrics); err != nil {
+			s.Log.Errorf("failed to execute batch footer template: %v", err)
+			return nil, nil
+		}
+	}
 
 	return b.Bytes(), nil
 }
@@ -181,6 +181,41 @@ func TestSerializeBatch(t *testing.T) {
 	require.Equal(t, "0: cpu 42\n", string(singleBuf))
 }
 
+func TestSerializeBatchHeaderFooter(t *testing.T) {
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "server01"},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	metrics := []telegraf.Metric{m, m}
+	s := &Serializer{
+		Template:    `{{ .Tag "host" }} {{ .Name }}.value {{ .Field "value" }}` + "\n",
+		BatchHeader: `# batch of {{ len . }} metrics` + "\n",
+		BatchFooter: "# end\n",
+	}
+	require.NoError(t, s.Init())
+
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+	require.Equal(t, "# batch of 2 metrics\nserver01 cpu.value 42\nserver01 cpu.value 42\n# end\n", string(buf))
+
+	// Header and footer only apply to batches
+	singleBuf, err := s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, "server01 cpu.value 42\n", string(singleBuf))
+}
+
+func TestInitInvalidBatchHeaderFooter(t *testing.T) {
+	s := &Serializer{Template: "{{ .Name }}", BatchHeader: "{{ len . "}
+	require.ErrorContains(t, s.Init(), "creating batch header template failed")
+
+	s = &Serializer{Template: "{{ .Name }}", BatchFooter: "{{ end }}"}
+	require.ErrorContains(t, s.Init(), "creating batch footer template failed")
+}
+
 func TestSerializeTrackingMetric(t *testing.T) {
 	m := metric.New(
 		"cpu",
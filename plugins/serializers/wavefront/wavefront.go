@@ -48,6 +48,12 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	return out, nil
 }
 
+// ConcatenatesBatches returns true since the points of each metric do not
+// depend on the other metrics in the batch
+func (*Serializer) ConcatenatesBatches() bool {
+	return true
+}
+
 func (s *Serializer) serializeMetric(m telegraf.Metric) {
 	const metricSeparator = "."
 
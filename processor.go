@@ -39,3 +39,15 @@ type StreamingProcessor interface {
 	// accumulator.
 	Stop()
 }
+
+// FlushingProcessor is a StreamingProcessor that additionally emits metrics on
+// a timer, e.g. to release buffered or correlated metrics even if no new
+// metrics arrive.
+type FlushingProcessor interface {
+	StreamingProcessor
+
+	// Flush is called periodically by the agent according to the processor's
+	// flush interval. It is never called in parallel with Add() and is called
+	// a final time after the last metric was added, right before Stop().
+	Flush(acc Accumulator) error
+}
@@ -20,6 +20,39 @@ type Serializer interface {
 	SerializeBatch(metrics []Metric) ([]byte, error)
 }
 
+// Framing defines how the serialized metrics of a batch are framed.
+type Framing struct {
+	// Header is written once before the first metric of the batch.
+	Header []byte
+	// Footer is written once after the last metric of the batch.
+	Footer []byte
+	// Separator is written between two consecutive metrics.
+	Separator []byte
+	// LengthPrefix is the size in bytes of the big-endian length written in
+	// front of each serialized metric. Zero disables the prefix.
+	LengthPrefix int
+}
+
+// FramingSerializer is an interface for serializers handling the framing of
+// a batch on their own, e.g. to produce formats with a custom file header.
+// Serializers not implementing the interface are framed by serializing each
+// metric individually.
+type FramingSerializer interface {
+	// SerializeBatchWithFraming takes an array of telegraf metric and
+	// serializes it into a byte buffer using the given framing.
+	SerializeBatchWithFraming(metrics []Metric, framing Framing) ([]byte, error)
+}
+
+// ConcatenatingSerializer is an interface for serializers producing batches
+// which are the plain concatenation of the serialized parts of the batch.
+// Large batches of those serializers can be split into chunks serialized in
+// parallel.
+type ConcatenatingSerializer interface {
+	// ConcatenatesBatches returns true if serializing a batch is equivalent
+	// to concatenating the serialization of consecutive parts of the batch.
+	ConcatenatesBatches() bool
+}
+
 // SerializerFunc is a function to create a new instance of a serializer
 type SerializerFunc func() (Serializer, error)
 